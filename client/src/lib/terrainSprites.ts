@@ -43,6 +43,15 @@ export const TERRAIN_SPRITES: Record<string, SpriteCoord> = {
   // Desert - use n0e0s0w0 variant
   'DESERT': coord(1, 15),  // t.l0.desert_n0e0s0w0
   
+  // Beach - no dedicated sprite yet, reuse desert sand
+  'BEACH': coord(1, 15),  // t.l0.desert_n0e0s0w0
+  
+  // Savanna - no dedicated sprite yet, reuse plains
+  'SAVANNA': coord(4, 15),  // t.l0.plains_n0e0s0w0
+  
+  // Taiga - no dedicated sprite yet, reuse forest
+  'TAIGA': coord(0, 8),  // t.l0.forest_n0e0s0w0
+  
   // Tundra - use n0e0s0w0 variant
   'TUNDRA': coord(3, 15),  // t.l0.tundra_n0e0s0w0
   
@@ -52,6 +61,12 @@ export const TERRAIN_SPRITES: Record<string, SpriteCoord> = {
   // Swamp - use n0e0s0w0 variant
   'SWAMP': coord(5, 15),  // t.l0.swamp_n0e0s0w0
   
+  // Marsh - the generator's name for swamp
+  'MARSH': coord(5, 15),  // t.l0.swamp_n0e0s0w0
+  
+  // Oasis - no dedicated sprite yet, reuse grassland
+  'OASIS': coord(0, 2),  // t.l0.grassland1
+  
   // Forest - use n0e0s0w0 variant
   'FOREST': coord(0, 8),  // t.l0.forest_n0e0s0w0
   
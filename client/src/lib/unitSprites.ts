@@ -41,11 +41,11 @@ export const UNIT_SPRITES: Record<string, SpriteCoord> = {
 
 /**
  * City/Settlement sprites from cities.png
- * Using european style for consistency
+ * Camps use tropical huts; settlements that outgrow them use european style
  */
 export const CITY_SPRITES: Record<string, SpriteCoord> = {
-  // Settlement sizes (european style)
-  'nomadic_camp': coord(1, 0),     // city.european_city_0 (smallest)
+  // Settlement sizes
+  'nomadic_camp': coord(7, 0),     // city.tropical_city_0 (huts)
   'village': coord(1, 0),          // city.european_city_0
   'town': coord(1, 1),             // city.european_city_1
   'city': coord(1, 2),             // city.european_city_2
@@ -268,10 +268,13 @@ export interface Unit {
   populationCost: number;
 }
 
+// Settlements grow from camps into villages, towns and cities
+export type SettlementType = 'nomadic_camp' | 'village' | 'town' | 'city';
+
 export interface Settlement {
   settlementId: string;
   name: string;
-  type: 'nomadic_camp';
+  type: SettlementType;
   location: {
     x: number;
     y: number;
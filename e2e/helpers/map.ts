@@ -46,7 +46,7 @@ export async function verifyNoMap(page: Page): Promise<void> {
 
 /**
  * Wait for map to be generated after game starts
- * Map generation happens on first tick after game state changes to "started"
+ * Map generation happens on the engine pass after the full lobby moves to "starting"
  */
 export async function waitForMapGeneration(page: Page, delayMs: number = 2000): Promise<void> {
   await page.waitForTimeout(delayMs);
@@ -0,0 +1,160 @@
+// Command balancereport runs the standard balance scenarios over the
+// standard seeds against the current ruleset and writes a markdown and a
+// JSON report of viability, fire mastery timing and outcome distributions.
+// Each metric is compared with the previous report, read from a file or
+// from the database, and the new report is stored as the next baseline.
+// With -every it keeps running as a scheduled job.
+//
+// Usage:
+//
+//	go run ./cmd/balancereport -out reports -baseline reports/balance-20261009T030000Z.json
+//	MONGO_URI=mongodb://localhost:27017 go run ./cmd/balancereport -save -out reports -every 24h
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/balancereport"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+func main() {
+	out := flag.String("out", ".", "directory to write the markdown and JSON reports to")
+	seedCount := flag.Int("seeds", len(simulator.BalanceSeeds), "how many of the standard seeds to run")
+	baselineFile := flag.String("baseline", "", "compare with the JSON report in this file")
+	save := flag.Bool("save", false, "compare with the latest report in the database and store the new one there")
+	every := flag.Duration("every", 0, "generate a report this often, such as 24h or 168h, instead of once")
+	flag.Parse()
+
+	if *seedCount < 1 || *seedCount > len(simulator.BalanceSeeds) {
+		fmt.Fprintf(os.Stderr, "balancereport: -seeds must be between 1 and %d\n", len(simulator.BalanceSeeds))
+		os.Exit(2)
+	}
+	if *baselineFile != "" && *save {
+		fmt.Fprintln(os.Stderr, "balancereport: give at most one of -baseline or -save")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	var store repository.BalanceReportStore
+	if *save {
+		repo, err := connect(ctx)
+		if err != nil {
+			fail(err)
+		}
+		defer repo.Close(ctx)
+		store = repo
+	}
+
+	var baseline *models.BalanceReport
+	if *baselineFile != "" {
+		var err error
+		if baseline, err = readReport(*baselineFile); err != nil {
+			fail(err)
+		}
+	}
+
+	seeds := simulator.BalanceSeeds[:*seedCount]
+	for {
+		report, err := run(ctx, store, baseline, seeds, *out)
+		if err != nil {
+			fail(err)
+		}
+		if *every <= 0 {
+			return
+		}
+		baseline = report
+		time.Sleep(*every)
+	}
+}
+
+// run generates one report against the baseline, or the latest stored
+// report when there is a store, then writes it out and stores it
+func run(ctx context.Context, store repository.BalanceReportStore, baseline *models.BalanceReport, seeds []int, dir string) (*models.BalanceReport, error) {
+	if store != nil {
+		latest, err := store.GetLatestBalanceReport(ctx)
+		switch {
+		case err == nil:
+			baseline = latest
+		case !errors.Is(err, repository.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	report := balancereport.Generate(simulator.BalanceScenarios(), seeds, models.CurrentRulesVersion, baseline, time.Now())
+	if err := writeReport(dir, report); err != nil {
+		return nil, err
+	}
+	if store != nil {
+		if err := store.SaveBalanceReport(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+
+	drifts := balancereport.Drifts(report)
+	fmt.Fprintf(os.Stderr, "Wrote %s: %d scenarios, %d seeds, %d metrics drifted\n", report.ReportID, len(report.Scenarios), report.Seeds, len(drifts))
+	for _, drift := range drifts {
+		fmt.Fprintf(os.Stderr, "  %s\n", drift)
+	}
+	return report, nil
+}
+
+// writeReport saves a report as <id>.md and <id>.json in dir
+func writeReport(dir string, report *models.BalanceReport) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	base := filepath.Join(dir, report.ReportID)
+	if err := os.WriteFile(base+".json", append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(base+".md", []byte(report.Markdown), 0o644)
+}
+
+// readReport loads a JSON report written by a previous run
+func readReport(path string) (*models.BalanceReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report models.BalanceReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// connect opens the database named by MONGO_URI and DB_NAME
+func connect(ctx context.Context) (*repository.MongoRepository, error) {
+	mongoURI := os.Getenv("MONGO_URI")
+	if mongoURI == "" {
+		mongoURI = "mongodb://localhost:27017"
+	}
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		dbName = "simciv"
+	}
+	options := repository.MongoOptionsFromEnv()
+	options.MaxConnectAttempts = 1
+	return repository.NewMongoRepositoryWithOptions(ctx, mongoURI, dbName, options)
+}
+
+// fail reports an error and exits
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "balancereport: %v\n", err)
+	os.Exit(1)
+}
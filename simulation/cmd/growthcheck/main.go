@@ -0,0 +1,116 @@
+// Command growthcheck runs a settlement's growth through the engine and
+// through the standalone simulator from identical starting conditions and
+// reports how far the two populations drift apart, so the engine's
+// shortcuts can be kept in line with the full human model as both evolve.
+// Each fidelity is checked in turn; with -max-gap the command fails when a
+// fidelity strays further than allowed, for use in CI.
+//
+// Usage:
+//
+//	go run ./cmd/growthcheck -years 100 -fidelity daily,aggregated
+//	go run ./cmd/growthcheck -restart -fidelity daily -max-gap 0 -every 10
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/anicolao/simciv/simulation/pkg/engine"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+func main() {
+	seed := flag.String("seed", "growthcheck", "game seed the settlement's simulation seed derives from")
+	population := flag.Int("population", 100, "humans in the settlement at the start")
+	terrainType := flag.String("terrain", terrain.Grassland, "terrain of every tile the settlement works")
+	fidelities := flag.String("fidelity", strings.Join([]string{models.SimulationFidelityDaily, models.SimulationFidelityAggregated, models.SimulationFidelityAttention}, ","),
+		"comma-separated engine fidelities to check")
+	years := flag.Int("years", 50, "years to run")
+	restart := flag.Bool("restart", false, "restart the engine every year, resuming from saved simulations")
+	every := flag.Int("every", 0, "also print both populations every this many years")
+	maxGap := flag.Float64("max-gap", -1, "fail if any year's population gap, 0 to 1, exceeds this")
+	asJSON := flag.Bool("json", false, "write the comparisons as JSON instead of a table")
+	flag.Parse()
+
+	ctx := context.Background()
+	var comparisons []*engine.GrowthComparison
+	for _, fidelity := range strings.Split(*fidelities, ",") {
+		comparison, err := engine.CompareGrowth(ctx, engine.GrowthCheck{
+			Seed:       *seed,
+			Population: *population,
+			Terrain:    *terrainType,
+			Fidelity:   strings.TrimSpace(fidelity),
+			Years:      *years,
+			Restart:    *restart,
+		})
+		if err != nil {
+			fail(err)
+		}
+		comparisons = append(comparisons, comparison)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(comparisons, "", "  ")
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printComparisons(comparisons, *every)
+	}
+
+	if *maxGap < 0 {
+		return
+	}
+	exceeded := false
+	for _, comparison := range comparisons {
+		if comparison.MaxGap > *maxGap {
+			fmt.Fprintf(os.Stderr, "growthcheck: %s fidelity strayed %.3f from the simulator, more than %.3f\n",
+				comparison.Check.Fidelity, comparison.MaxGap, *maxGap)
+			exceeded = true
+		}
+	}
+	if exceeded {
+		os.Exit(1)
+	}
+}
+
+// printComparisons writes a summary line per fidelity and, with every
+// above 0, both populations at that interval
+func printComparisons(comparisons []*engine.GrowthComparison, every int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "fidelity\tengine\tsimulator\tmax gap\tmean gap\tfinal gap\ttech years\t")
+	for _, comparison := range comparisons {
+		last := comparison.Years[len(comparison.Years)-1]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.3f\t%.3f\t%.3f\t%d\t\n", comparison.Check.Fidelity, last.EnginePopulation, last.SimulatorPopulation,
+			comparison.MaxGap, comparison.MeanGap, comparison.FinalGap, comparison.TechnologyYears)
+	}
+	w.Flush()
+	if every <= 0 {
+		return
+	}
+
+	for _, comparison := range comparisons {
+		fmt.Printf("\n%s\n", comparison.Check.Fidelity)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "year\tengine\tsimulator\tgap\t")
+		for i, year := range comparison.Years {
+			if (i+1)%every == 0 || i == len(comparison.Years)-1 {
+				fmt.Fprintf(w, "%d\t%d\t%d\t%.3f\t\n", year.Year, year.EnginePopulation, year.SimulatorPopulation, year.Gap)
+			}
+		}
+		w.Flush()
+	}
+}
+
+// fail reports an error and exits
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "growthcheck: %v\n", err)
+	os.Exit(2)
+}
@@ -0,0 +1,157 @@
+// Command loadtest runs the simulation engine against many concurrent games
+// backed by an in-memory repository and reports tick latency percentiles and
+// repository operation counts, giving a regression baseline for scalability work.
+//
+// Usage:
+//
+//	go run ./cmd/loadtest -games 200 -players 4 -duration 30s
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/engine"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// tickSample is a single observed game tick
+type tickSample struct {
+	gameID  string
+	elapsed time.Duration
+	failed  bool
+}
+
+func main() {
+	games := flag.Int("games", 100, "number of concurrent games")
+	players := flag.Int("players", 4, "players per game")
+	duration := flag.Duration("duration", 30*time.Second, "wall-clock duration to run the engine")
+	fidelity := flag.String("fidelity", models.SimulationFidelityDaily, "settlement simulation fidelity (daily or aggregated)")
+	verbose := flag.Bool("verbose", false, "show engine log output")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	repo := repository.NewMemoryRepository()
+	now := time.Now()
+	for i := 0; i < *games; i++ {
+		playerList := make([]string, *players)
+		for p := range playerList {
+			playerList[p] = fmt.Sprintf("load-player-%d-%d", i, p)
+		}
+		repo.InsertGame(&models.Game{
+			GameID:             fmt.Sprintf("load-game-%04d", i),
+			CreatorUserID:      playerList[0],
+			MaxPlayers:         *players,
+			CurrentPlayers:     *players,
+			PlayerList:         playerList,
+			State:              "started",
+			CurrentYear:        -5000,
+			CreatedAt:          now,
+			StartedAt:          &now,
+			Seeds:              models.NewGameSeeds(fmt.Sprintf("load-seed-%d", i)),
+			SimulationFidelity: *fidelity,
+		})
+	}
+
+	var mu sync.Mutex
+	var samples []tickSample
+	gameEngine := engine.NewGameEngine(repo)
+	gameEngine.SetTickObserver(func(gameID string, elapsed time.Duration, err error) {
+		mu.Lock()
+		samples = append(samples, tickSample{gameID: gameID, elapsed: elapsed, failed: err != nil})
+		mu.Unlock()
+	})
+
+	fmt.Fprintf(os.Stdout, "Running %d games x %d players for %s (fidelity: %s)\n", *games, *players, *duration, *fidelity)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+	start := time.Now()
+	if err := gameEngine.Run(ctx); err != nil && err != context.DeadlineExceeded {
+		fmt.Fprintf(os.Stderr, "engine error: %v\n", err)
+		os.Exit(1)
+	}
+	wall := time.Since(start)
+
+	mu.Lock()
+	defer mu.Unlock()
+	printReport(os.Stdout, samples, repo.OpCounts(), *games, wall)
+}
+
+// printReport writes tick latency percentiles and repository op counts
+func printReport(w io.Writer, samples []tickSample, ops map[string]int64, games int, wall time.Duration) {
+	fmt.Fprintf(w, "\n%-32s %12s\n", "Metric", "Value")
+	fmt.Fprintf(w, "%s\n", "---------------------------------------------")
+
+	failures := 0
+	perGame := make(map[string]int)
+	latencies := make([]time.Duration, 0, len(samples))
+	for _, s := range samples {
+		latencies = append(latencies, s.elapsed)
+		perGame[s.gameID]++
+		if s.failed {
+			failures++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	fmt.Fprintf(w, "%-32s %12d\n", "Ticks processed", len(samples))
+	fmt.Fprintf(w, "%-32s %12d\n", "Tick errors", failures)
+	fmt.Fprintf(w, "%-32s %12.1f\n", "Ticks per second", float64(len(samples))/wall.Seconds())
+
+	// Each game should tick once per second of wall time
+	expectedPerGame := int(wall.Seconds())
+	behind := 0
+	for i := 0; i < games; i++ {
+		if perGame[fmt.Sprintf("load-game-%04d", i)] < expectedPerGame-1 {
+			behind++
+		}
+	}
+	fmt.Fprintf(w, "%-32s %12d\n", "Games behind schedule", behind)
+
+	if len(latencies) > 0 {
+		for _, p := range []float64{50, 90, 99} {
+			fmt.Fprintf(w, "%-32s %12s\n", fmt.Sprintf("Tick latency p%.0f", p), percentile(latencies, p).Round(time.Microsecond))
+		}
+		fmt.Fprintf(w, "%-32s %12s\n", "Tick latency max", latencies[len(latencies)-1].Round(time.Microsecond))
+	}
+
+	fmt.Fprintf(w, "\n%-32s %12s %12s\n", "Repository operation", "Count", "Per tick")
+	fmt.Fprintf(w, "%s\n", "----------------------------------------------------------")
+	names := make([]string, 0, len(ops))
+	var total int64
+	for name, n := range ops {
+		names = append(names, name)
+		total += n
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%-32s %12d %12.2f\n", name, ops[name], perTick(ops[name], len(samples)))
+	}
+	fmt.Fprintf(w, "%-32s %12d %12.2f\n", "Total", total, perTick(total, len(samples)))
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted)-1) * p / 100)
+	return sorted[idx]
+}
+
+// perTick normalizes an operation count by the number of ticks
+func perTick(count int64, ticks int) float64 {
+	if ticks == 0 {
+		return 0
+	}
+	return float64(count) / float64(ticks)
+}
@@ -0,0 +1,130 @@
+// Command savegame exports a stored game, with its map, entities and events,
+// to a single versioned file, and imports such a file into a database that
+// does not yet hold the game. Files ending in .gz are gzip compressed.
+//
+// Usage:
+//
+//	MONGO_URI=mongodb://localhost:27017 go run ./cmd/savegame -export <gameId> -out game.json.gz
+//	MONGO_URI=mongodb://other:27017 go run ./cmd/savegame -import game.json.gz
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/savegame"
+)
+
+func main() {
+	exportID := flag.String("export", "", "export this stored game")
+	out := flag.String("out", "", "file to export to (default stdout)")
+	importFile := flag.String("import", "", "import the game saved in this file")
+	flag.Parse()
+
+	if (*exportID == "") == (*importFile == "") {
+		fmt.Fprintln(os.Stderr, "savegame: give exactly one of -export or -import")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	repo, err := connect(ctx)
+	if err != nil {
+		fail(err)
+	}
+	defer repo.Close(ctx)
+
+	if *exportID != "" {
+		err = exportGame(ctx, repo, *exportID, *out)
+	} else {
+		err = importGame(ctx, repo, *importFile)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+// exportGame saves a game to path, or to stdout when path is empty
+func exportGame(ctx context.Context, repo repository.GameRepository, gameID, path string) error {
+	save, err := savegame.Export(ctx, repo, gameID)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+		if strings.HasSuffix(path, ".gz") {
+			compressed := gzip.NewWriter(file)
+			defer compressed.Close()
+			w = compressed
+		}
+	}
+	if err := savegame.Write(w, save); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported game %s at year %d: %d tiles, %d units, %d settlements, %d events\n",
+		save.Game.GameID, save.Game.CurrentYear, len(save.Tiles), len(save.Units), len(save.Settlements), len(save.Events))
+	return nil
+}
+
+// importGame loads the savegame at path into the database
+func importGame(ctx context.Context, repo repository.GameRepository, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		compressed, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer compressed.Close()
+		r = compressed
+	}
+	save, err := savegame.Read(r)
+	if err != nil {
+		return err
+	}
+	if err := savegame.Import(ctx, repo, save); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Imported game %s at year %d\n", save.Game.GameID, save.Game.CurrentYear)
+	return nil
+}
+
+// connect opens the database named by MONGO_URI and DB_NAME
+func connect(ctx context.Context) (*repository.MongoRepository, error) {
+	mongoURI := os.Getenv("MONGO_URI")
+	if mongoURI == "" {
+		mongoURI = "mongodb://localhost:27017"
+	}
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		dbName = "simciv"
+	}
+
+	options := repository.MongoOptionsFromEnv()
+	options.MaxConnectAttempts = 1
+	return repository.NewMongoRepositoryWithOptions(ctx, mongoURI, dbName, options)
+}
+
+// fail reports an error and exits
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "savegame: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,98 @@
+// Command sharecode makes and reads the share codes players swap to play
+// the same world. A code holds a map seed, the player count that sizes the
+// map, the map options and optionally a scenario. Create a game from a code
+// with POST /api/games {"shareCode": ...}, or preview its world with
+// cmd/mapexport -code.
+//
+// Usage:
+//
+//	go run ./cmd/sharecode -seed debug -players 4 -land 0.4
+//	go run ./cmd/sharecode -game <gameId>
+//	go run ./cmd/sharecode -decode AEAQK-...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/anicolao/simciv/simulation/pkg/mapgen"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+func main() {
+	seed := flag.String("seed", "", "map seed to encode")
+	players := flag.Int("players", 4, "player count, which sizes the map")
+	land := flag.Float64("land", mapgen.DefaultMapOptions().LandRatio, "target fraction of land")
+	scenario := flag.String("scenario", "", "optional scenario ID")
+	gameID := flag.String("game", "", "print the share code of this stored game's world")
+	decode := flag.String("decode", "", "print what a share code holds")
+	flag.Parse()
+
+	switch {
+	case *decode != "":
+		share, err := mapgen.ParseShareCode(*decode)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("seed:      %s\nplayers:   %d\nland:      %.3f\ntolerance: %.4f\nsea level iterations: %d\n",
+			share.Seed, share.Players, share.Options.LandRatio, share.Options.LandRatioTolerance, share.Options.SeaLevelIterations)
+		if share.Scenario != "" {
+			fmt.Printf("scenario:  %s\n", share.Scenario)
+		}
+	case *gameID != "":
+		code, err := storedShareCode(context.Background(), *gameID)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(code)
+	case *seed != "":
+		opts := mapgen.DefaultMapOptions()
+		opts.LandRatio = *land
+		code, err := mapgen.ShareCode{Seed: *seed, Players: *players, Options: opts, Scenario: *scenario}.Encode()
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(code)
+	default:
+		fmt.Fprintln(os.Stderr, "sharecode: give -seed, -game or -decode")
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// storedShareCode reads the share code recorded when a game's map was generated
+func storedShareCode(ctx context.Context, gameID string) (string, error) {
+	mongoURI := os.Getenv("MONGO_URI")
+	if mongoURI == "" {
+		mongoURI = "mongodb://localhost:27017"
+	}
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		dbName = "simciv"
+	}
+
+	options := repository.MongoOptionsFromEnv()
+	options.MaxConnectAttempts = 1
+	repo, err := repository.NewMongoRepositoryWithOptions(ctx, mongoURI, dbName, options)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Close(ctx)
+
+	metadata, err := repo.GetMapMetadata(ctx, gameID)
+	if err != nil {
+		return "", fmt.Errorf("no map for game %s: %w", gameID, err)
+	}
+	if metadata.ShareCode == "" {
+		return "", fmt.Errorf("game %s's map predates share codes", gameID)
+	}
+	return metadata.ShareCode, nil
+}
+
+// fail reports an error and exits
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "sharecode: %v\n", err)
+	os.Exit(1)
+}
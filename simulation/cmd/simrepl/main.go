@@ -0,0 +1,508 @@
+// Command simrepl is an interactive tuning session for the simulator: set a
+// starting condition, rerun the standard seeds, and compare the balance
+// metrics with the previous run or any earlier one, without editing code
+// and rerunning go test. The session's runs can be exported as JSON or
+// markdown to attach to a balance change.
+//
+// Usage:
+//
+//	go run ./cmd/simrepl -seeds 20
+//
+// Then, at the prompt:
+//
+//	> set food_allocation 0.6
+//	> policy science_rush
+//	> run
+//	> compare 1 2
+//	> export tuning.md
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/balancereport"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+// parameter is a starting condition the session can set
+type parameter struct {
+	name string
+	help string
+	get  func(conditions *simulator.StartingConditions) float64
+	set  func(conditions *simulator.StartingConditions, value float64)
+}
+
+// parameters are the tunable starting conditions, in the order they are shown
+var parameters = []parameter{
+	{"population", "humans at the start",
+		func(c *simulator.StartingConditions) float64 { return float64(c.Population) },
+		func(c *simulator.StartingConditions, v float64) { c.Population = int(v) }},
+	{"health_min", "minimum starting health",
+		func(c *simulator.StartingConditions) float64 { return c.StartingHealthMin },
+		func(c *simulator.StartingConditions, v float64) { c.StartingHealthMin = v }},
+	{"health_max", "maximum starting health",
+		func(c *simulator.StartingConditions) float64 { return c.StartingHealthMax },
+		func(c *simulator.StartingConditions, v float64) { c.StartingHealthMax = v }},
+	{"food_stockpile", "starting food units",
+		func(c *simulator.StartingConditions) float64 { return c.FoodStockpile },
+		func(c *simulator.StartingConditions, v float64) { c.FoodStockpile = v }},
+	{"food_allocation", "share of work spent on food (0-1)",
+		func(c *simulator.StartingConditions) float64 { return c.FoodAllocationRatio },
+		func(c *simulator.StartingConditions, v float64) { c.FoodAllocationRatio = v }},
+	{"terrain", "terrain food multiplier (1 = normal)",
+		func(c *simulator.StartingConditions) float64 { return c.TerrainMultiplier },
+		func(c *simulator.StartingConditions, v float64) { c.TerrainMultiplier = v }},
+	{"shelter", "people natural shelter protects in winter",
+		func(c *simulator.StartingConditions) float64 { return float64(c.ShelterCapacity) },
+		func(c *simulator.StartingConditions, v float64) { c.ShelterCapacity = int(v) }},
+	{"pollution", "average pollution of the surroundings (0-1)",
+		func(c *simulator.StartingConditions) float64 { return c.Pollution },
+		func(c *simulator.StartingConditions, v float64) { c.Pollution = v }},
+	{"livestock", "nearby tiles with herds to domesticate",
+		func(c *simulator.StartingConditions) float64 { return float64(c.Livestock) },
+		func(c *simulator.StartingConditions, v float64) { c.Livestock = int(v) }},
+	{"trade", "extra share of food from trade (0-1)",
+		func(c *simulator.StartingConditions) float64 { return c.Trade },
+		func(c *simulator.StartingConditions, v float64) { c.Trade = v }},
+	{"child_labor", "share of children aged 10-15 put to work (0-1)",
+		func(c *simulator.StartingConditions) float64 { return c.ChildLabor },
+		func(c *simulator.StartingConditions, v float64) { c.ChildLabor = v }},
+	{"carrying_capacity", "people supported before overcrowding (0 = no limit)",
+		func(c *simulator.StartingConditions) float64 { return float64(c.CarryingCapacity) },
+		func(c *simulator.StartingConditions, v float64) { c.CarryingCapacity = int(v) }},
+	{"unrest", "share of food lost to disorder (0-1)",
+		func(c *simulator.StartingConditions) float64 { return c.Unrest },
+		func(c *simulator.StartingConditions, v float64) { c.Unrest = v }},
+	{"tax", "share of food taken as tax (0-1)",
+		func(c *simulator.StartingConditions) float64 { return c.Tax },
+		func(c *simulator.StartingConditions, v float64) { c.Tax = v }},
+	{"corvee", "share of work hours drafted for public works (0-1)",
+		func(c *simulator.StartingConditions) float64 { return c.Corvee },
+		func(c *simulator.StartingConditions, v float64) { c.Corvee = v }},
+}
+
+// findParameter returns the parameter with a name
+// policies are the built-in labor policies a session can run under; without
+// one, runs keep the food_allocation they start with
+var policies = map[string]func() simulator.Policy{
+	"starvation_avoidance": func() simulator.Policy { return simulator.NewStarvationAvoidancePolicy() },
+	"science_rush":         func() simulator.Policy { return simulator.NewScienceRushPolicy() },
+}
+
+func findParameter(name string) (parameter, bool) {
+	for _, p := range parameters {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return parameter{}, false
+}
+
+// run is one rerun of the seed suite in the session history
+type run struct {
+	Number   int                   `json:"number"`
+	Scenario string                `json:"scenario"`
+	Changes  map[string]float64    `json:"changes"` // Parameters that differ from the scenario's defaults
+	Policy   string                `json:"policy,omitempty"`
+	Seeds    int                   `json:"seeds"`
+	MaxDays  int                   `json:"maxDays"`
+	Report   *models.BalanceReport `json:"report"`
+}
+
+// session is the state of an interactive tuning session
+type session struct {
+	out        io.Writer
+	scenario   simulator.BalanceScenario // The scenario last loaded, whose conditions changes are measured from
+	conditions simulator.StartingConditions
+	policy     string // Built-in labor policy runs use, "" for none
+	maxDays    int
+	seeds      []int
+	history    []*run
+}
+
+func main() {
+	seedCount := flag.Int("seeds", 20, "how many of the standard seeds each run uses")
+	scenario := flag.String("scenario", "default", "balance scenario to start from")
+	flag.Parse()
+
+	s := &session{out: os.Stdout}
+	if err := s.setSeeds(*seedCount); err != nil {
+		fail(err)
+	}
+	if err := s.load(*scenario); err != nil {
+		fail(err)
+	}
+
+	fmt.Fprintln(s.out, "simrepl: type help for commands")
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(s.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(s.out)
+			return
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "exit" {
+			return
+		}
+		if err := s.execute(fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+	}
+}
+
+// execute runs one command
+func (s *session) execute(command string, args []string) error {
+	switch command {
+	case "help":
+		s.help()
+	case "show":
+		s.show()
+	case "set":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: set <parameter> <value>")
+		}
+		return s.set(args[0], args[1])
+	case "reset":
+		s.conditions = s.scenario.Conditions
+		s.maxDays = s.scenario.MaxDays
+		s.policy = ""
+	case "policy":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: policy <name|none>")
+		}
+		return s.setPolicy(args[0])
+	case "scenario":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: scenario <name>")
+		}
+		return s.load(args[0])
+	case "run":
+		s.run()
+	case "compare":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: compare <run> <run>")
+		}
+		base, err := s.lookup(args[0])
+		if err != nil {
+			return err
+		}
+		current, err := s.lookup(args[1])
+		if err != nil {
+			return err
+		}
+		s.compare(base, current)
+	case "history":
+		s.listHistory()
+	case "export":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: export <file.json|file.md>")
+		}
+		return s.export(args[0])
+	default:
+		return fmt.Errorf("unknown command %q; type help for commands", command)
+	}
+	return nil
+}
+
+// help lists the commands and parameters
+func (s *session) help() {
+	fmt.Fprintln(s.out, `Commands:
+  show                   show the parameters and how they differ from the scenario
+  set <param> <value>    set a parameter; days and seeds set the run length and seed count
+  reset                  restore the scenario's parameters and drop any policy
+  policy <name|none>     run under a built-in labor policy, or none
+  scenario <name>        start from a standard balance scenario
+  run                    rerun the seeds and compare with the previous run
+  compare <a> <b>        compare two runs from the history
+  history                list the runs so far
+  export <file>          save the runs as JSON, or as markdown if the file ends in .md
+  quit                   leave the session
+
+Parameters:`)
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	for _, p := range parameters {
+		fmt.Fprintf(w, "  %s\t%s\n", p.name, p.help)
+	}
+	w.Flush()
+
+	var names []string
+	for _, scenario := range simulator.BalanceScenarios() {
+		names = append(names, scenario.Name)
+	}
+	fmt.Fprintf(s.out, "\nScenarios: %s\n", strings.Join(names, ", "))
+	fmt.Fprintf(s.out, "Policies: %s\n", strings.Join(policyNames(), ", "))
+}
+
+// policyNames returns the built-in policies alphabetically
+func policyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// show prints every parameter, marking those changed from the scenario
+func (s *session) show() {
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "scenario\t%s\t\n", s.scenario.Name)
+	fmt.Fprintf(w, "seeds\t%d\t\n", len(s.seeds))
+	if s.policy != "" {
+		fmt.Fprintf(w, "policy\t%s\t\n", s.policy)
+	}
+	fmt.Fprintf(w, "days\t%d\t%s\n", s.maxDays, changedMark(float64(s.maxDays), float64(s.scenario.MaxDays)))
+	for _, p := range parameters {
+		value := p.get(&s.conditions)
+		fmt.Fprintf(w, "%s\t%g\t%s\n", p.name, value, changedMark(value, p.get(&s.scenario.Conditions)))
+	}
+	w.Flush()
+}
+
+// changedMark notes a value that differs from the scenario's
+func changedMark(value, original float64) string {
+	if value == original {
+		return ""
+	}
+	return fmt.Sprintf("(was %g)", original)
+}
+
+// set changes a parameter, the run length or the seed count
+func (s *session) set(name, text string) error {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", text)
+	}
+	switch name {
+	case "seeds":
+		return s.setSeeds(int(value))
+	case "days":
+		if value < 1 {
+			return fmt.Errorf("days must be at least 1")
+		}
+		s.maxDays = int(value)
+		return nil
+	}
+	p, ok := findParameter(name)
+	if !ok {
+		return fmt.Errorf("unknown parameter %q; type help for the list", name)
+	}
+	p.set(&s.conditions, value)
+	return nil
+}
+
+// setPolicy runs the session under a built-in labor policy, or none
+func (s *session) setPolicy(name string) error {
+	if name == "none" {
+		s.policy = ""
+		return nil
+	}
+	if _, ok := policies[name]; !ok {
+		return fmt.Errorf("unknown policy %q; choose from %s", name, strings.Join(policyNames(), ", "))
+	}
+	s.policy = name
+	return nil
+}
+
+// setSeeds uses the first count standard seeds
+func (s *session) setSeeds(count int) error {
+	if count < 1 || count > len(simulator.BalanceSeeds) {
+		return fmt.Errorf("seeds must be between 1 and %d", len(simulator.BalanceSeeds))
+	}
+	s.seeds = simulator.BalanceSeeds[:count]
+	return nil
+}
+
+// load starts from a standard balance scenario
+func (s *session) load(name string) error {
+	for _, scenario := range simulator.BalanceScenarios() {
+		if scenario.Name == name {
+			s.scenario = scenario
+			s.conditions = scenario.Conditions
+			s.maxDays = scenario.MaxDays
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown scenario %q", name)
+}
+
+// run reruns the seeds with the current parameters, adds the run to the
+// history and compares it with the previous run
+func (s *session) run() {
+	changes := map[string]float64{}
+	for _, p := range parameters {
+		if value := p.get(&s.conditions); value != p.get(&s.scenario.Conditions) {
+			changes[p.name] = value
+		}
+	}
+	if s.maxDays != s.scenario.MaxDays {
+		changes["days"] = float64(s.maxDays)
+	}
+
+	var previous *run
+	var baseline *models.BalanceReport
+	if len(s.history) > 0 {
+		previous = s.history[len(s.history)-1]
+		baseline = previous.Report
+	}
+	scenario := simulator.BalanceScenario{Name: s.scenario.Name, Conditions: s.conditions, MaxDays: s.maxDays}
+	if s.policy != "" {
+		scenario.Policy = policies[s.policy]()
+	}
+	started := time.Now()
+	report := balancereport.Generate([]simulator.BalanceScenario{scenario}, s.seeds, models.CurrentRulesVersion, baseline, started)
+	current := &run{
+		Number:   len(s.history) + 1,
+		Scenario: s.scenario.Name,
+		Changes:  changes,
+		Policy:   s.policy,
+		Seeds:    len(s.seeds),
+		MaxDays:  s.maxDays,
+		Report:   report,
+	}
+	s.history = append(s.history, current)
+
+	fmt.Fprintf(s.out, "Run %d: %s %s, %d seeds in %s\n", current.Number, current.Scenario, describeChanges(changes, s.policy), current.Seeds, time.Since(started).Round(time.Millisecond))
+	s.compare(previous, current)
+}
+
+// compare prints a run's metrics beside a base run's, marking those that
+// moved further than a balance golden would allow
+func (s *session) compare(base, current *run) {
+	metrics := current.Report.Scenarios[0].Metrics
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	if base == nil {
+		fmt.Fprintf(w, "metric\trun %d\t\n", current.Number)
+		for _, name := range sortedMetrics(metrics) {
+			fmt.Fprintf(w, "%s\t%s\t\n", name, balancereport.FormatMetric(name, metrics[name]))
+		}
+		w.Flush()
+		return
+	}
+
+	var baseMetrics map[string]float64
+	if len(base.Report.Scenarios) > 0 {
+		baseMetrics = base.Report.Scenarios[0].Metrics
+	}
+	golden := simulator.NewBalanceGolden(simulator.BalanceScenario{Name: base.Scenario, MaxDays: base.MaxDays}, base.Seeds, baseMetrics, nil)
+	drifted := false
+	fmt.Fprintf(w, "metric\trun %d\trun %d\tchange\t\n", base.Number, current.Number)
+	for _, name := range sortedMetrics(metrics) {
+		band, ok := golden.Metrics[name]
+		if !ok {
+			fmt.Fprintf(w, "%s\t-\t%s\t-\t\n", name, balancereport.FormatMetric(name, metrics[name]))
+			continue
+		}
+		change := metrics[name] - band.Value
+		mark := ""
+		if change > band.Tolerance || -change > band.Tolerance {
+			mark = " *"
+			drifted = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%+.4g%s\t\n", name, balancereport.FormatMetric(name, band.Value), balancereport.FormatMetric(name, metrics[name]), change, mark)
+	}
+	w.Flush()
+	if drifted {
+		fmt.Fprintln(s.out, "(* moved outside the balance golden tolerance)")
+	}
+}
+
+// lookup finds a run in the history by number
+func (s *session) lookup(text string) (*run, error) {
+	number, err := strconv.Atoi(text)
+	if err != nil || number < 1 || number > len(s.history) {
+		return nil, fmt.Errorf("no run %q; there are %d runs", text, len(s.history))
+	}
+	return s.history[number-1], nil
+}
+
+// listHistory prints one line per run
+func (s *session) listHistory() {
+	if len(s.history) == 0 {
+		fmt.Fprintln(s.out, "No runs yet")
+		return
+	}
+	for _, r := range s.history {
+		metrics := r.Report.Scenarios[0].Metrics
+		fmt.Fprintf(s.out, "%d: %s %s, %d seeds: viability %s, fire mastery %s\n", r.Number, r.Scenario, describeChanges(r.Changes, r.Policy), r.Seeds,
+			balancereport.FormatMetric(simulator.MetricViabilityRate, metrics[simulator.MetricViabilityRate]),
+			balancereport.FormatMetric(simulator.MetricFireMasteryRate, metrics[simulator.MetricFireMasteryRate]))
+	}
+}
+
+// export writes the session's runs to a file, as markdown if its name ends
+// in .md and as JSON otherwise
+func (s *session) export(path string) error {
+	var data []byte
+	if strings.HasSuffix(path, ".md") {
+		var b strings.Builder
+		b.WriteString("# Tuning session\n\n")
+		for _, r := range s.history {
+			fmt.Fprintf(&b, "## Run %d: %s %s\n\n", r.Number, r.Scenario, describeChanges(r.Changes, r.Policy))
+			b.WriteString(r.Report.Markdown)
+		}
+		data = []byte(b.String())
+	} else {
+		encoded, err := json.MarshalIndent(s.history, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(encoded, '\n')
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Exported %d runs to %s\n", len(s.history), path)
+	return nil
+}
+
+// describeChanges lists changed parameters, or says there are none
+func describeChanges(changes map[string]float64, policy string) string {
+	if len(changes) == 0 && policy == "" {
+		return "(unchanged)"
+	}
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%g", name, changes[name])
+	}
+	if policy != "" {
+		parts = append(parts, "policy="+policy)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// sortedMetrics returns metric names alphabetically
+func sortedMetrics(metrics map[string]float64) []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fail reports an error and exits
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "simrepl: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,7 @@
+// Package integration runs the real engine against a real MongoDB started
+// in a container, covering the seams between the engine and the Mongo
+// repository that the in-memory mocks cannot. The tests need Docker and
+// only build with the integration tag:
+//
+//	go test -tags integration ./integration/...
+package integration
@@ -0,0 +1,202 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/anicolao/simciv/simulation/pkg/engine"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+const (
+	testSeed   = "integration-seed"
+	testTicks  = 20
+	tickWait   = 30 * time.Second
+	startYear  = -5000
+	mongoImage = "mongo:7"
+)
+
+var testPlayers = []string{"alice", "bob"}
+
+// startMongo runs a single-node replica set, which the repository's
+// transactions need, for the length of the test
+func startMongo(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+
+	container, err := mongodb.Run(ctx, mongoImage, mongodb.WithReplicaSet("rs0"))
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("Failed to start MongoDB: %v", err)
+	}
+	uri, err := container.ConnectionString(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get the MongoDB connection string: %v", err)
+	}
+	return uri + "&directConnection=true"
+}
+
+// createGame inserts a full game waiting for its map, as the web server
+// does once the last player joins
+func createGame(t *testing.T, uri, dbName, gameID string) {
+	t.Helper()
+	ctx := context.Background()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	game := &models.Game{
+		GameID:             gameID,
+		CreatorUserID:      testPlayers[0],
+		MaxPlayers:         len(testPlayers),
+		CurrentPlayers:     len(testPlayers),
+		PlayerList:         testPlayers,
+		State:              models.GameStateStarting,
+		CurrentYear:        startYear,
+		CreatedAt:          time.Now(),
+		SimulationFidelity: models.SimulationFidelityAggregated,
+	}
+	if _, err := client.Database(dbName).Collection("games").InsertOne(ctx, game); err != nil {
+		t.Fatalf("Failed to create game: %v", err)
+	}
+}
+
+// runGame plays a fixed-seed game for ticks years with the real engine and
+// returns the repository holding the result
+func runGame(t *testing.T, uri, dbName, gameID string, ticks int) *repository.MongoRepository {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	createGame(t, uri, dbName, gameID)
+	repo, err := repository.NewMongoRepository(ctx, uri, dbName)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close(context.Background()) })
+
+	// Manual ticks let the test step the engine one year at a time
+	t.Setenv("E2E_TEST_MODE", "true")
+	t.Setenv("TEST_MAP_SEED", testSeed)
+	gameEngine := engine.NewGameEngine(repo)
+	go gameEngine.Run(ctx)
+
+	for tick := 1; tick <= ticks; tick++ {
+		gameEngine.TriggerManualTick(gameID)
+		waitForYear(t, repo, gameID, startYear+tick)
+	}
+	return repo
+}
+
+// waitForYear polls until the game reaches year
+func waitForYear(t *testing.T, repo *repository.MongoRepository, gameID string, year int) {
+	t.Helper()
+	deadline := time.Now().Add(tickWait)
+	for time.Now().Before(deadline) {
+		game, err := repo.GetGame(context.Background(), gameID)
+		if err == nil && game.CurrentYear >= year {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("Game %s did not reach year %d within %v", gameID, year, tickWait)
+}
+
+// mapFingerprint summarizes a game's terrain and starting positions
+func mapFingerprint(t *testing.T, repo *repository.MongoRepository, gameID string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	tiles, err := repo.GetMapTiles(ctx, gameID, nil)
+	if err != nil {
+		t.Fatalf("GetMapTiles failed: %v", err)
+	}
+	terrain := make(map[models.Location]string, len(tiles))
+	for _, tile := range tiles {
+		terrain[models.Location{X: tile.X, Y: tile.Y}] = tile.TerrainType
+	}
+	fingerprint := fmt.Sprint(len(terrain), terrain)
+	for _, playerID := range testPlayers {
+		position, err := repo.GetStartingPosition(ctx, gameID, playerID)
+		if err != nil || position == nil {
+			t.Fatalf("Expected a starting position for %s, got %v (%v)", playerID, position, err)
+		}
+		fingerprint += fmt.Sprintf(" %s@(%d,%d)", playerID, position.StartingCityX, position.StartingCityY)
+	}
+	return fingerprint
+}
+
+func TestEngine_FixedSeedGame(t *testing.T) {
+	ctx := context.Background()
+	uri := startMongo(t)
+	repo := runGame(t, uri, "simciv_a", "game-a", testTicks)
+
+	game, err := repo.GetGame(ctx, "game-a")
+	if err != nil {
+		t.Fatalf("GetGame failed: %v", err)
+	}
+	if game.State != models.GameStateActive || game.CurrentYear != startYear+testTicks {
+		t.Errorf("Expected a started game in year %d, got %s in %d", startYear+testTicks, game.State, game.CurrentYear)
+	}
+	if game.Seeds == nil || game.Rules == nil || game.LastTickAt == nil {
+		t.Errorf("Expected seeds, rules and a tick time to be persisted, got %+v", game)
+	}
+
+	metadata, err := repo.GetMapMetadata(ctx, "game-a")
+	if err != nil {
+		t.Fatalf("GetMapMetadata failed: %v", err)
+	}
+	tiles, err := repo.GetMapTiles(ctx, "game-a", nil)
+	if err != nil {
+		t.Fatalf("GetMapTiles failed: %v", err)
+	}
+	if len(tiles) != metadata.Width*metadata.Height {
+		t.Errorf("Expected %dx%d tiles, got %d", metadata.Width, metadata.Height, len(tiles))
+	}
+
+	// Every player has a civ: settlers still looking for a site or a settlement
+	for _, playerID := range testPlayers {
+		units, err := repo.GetUnitsByPlayer(ctx, "game-a", playerID)
+		if err != nil {
+			t.Fatalf("GetUnitsByPlayer failed: %v", err)
+		}
+		settlements, err := repo.GetSettlementsByPlayer(ctx, "game-a", playerID)
+		if err != nil {
+			t.Fatalf("GetSettlementsByPlayer failed: %v", err)
+		}
+		if len(units)+len(settlements) == 0 {
+			t.Errorf("Expected %s to have units or settlements", playerID)
+		}
+		for _, settlement := range settlements {
+			if settlement.Population <= 0 {
+				t.Errorf("Expected settlement %s to be populated, got %d", settlement.SettlementID, settlement.Population)
+			}
+		}
+		seen, err := repo.GetMapTiles(ctx, "game-a", &playerID)
+		if err != nil {
+			t.Fatalf("GetMapTiles failed: %v", err)
+		}
+		if len(seen) == 0 {
+			t.Errorf("Expected %s to see part of the map", playerID)
+		}
+	}
+
+	// The same seed generates the same world in a fresh database
+	replay := runGame(t, uri, "simciv_b", "game-a", 1)
+	if mapFingerprint(t, repo, "game-a") != mapFingerprint(t, replay, "game-a") {
+		t.Error("Expected the same seed to generate the same map and starting positions")
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/anicolao/simciv/simulation/pkg/engine"
+	"github.com/anicolao/simciv/simulation/pkg/eventbus"
 	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/webhooks"
 )
 
 func main() {
@@ -32,8 +36,8 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Connect to database
-	repo, err := repository.NewMongoRepository(ctx, mongoURI, dbName)
+	// Connect to database, retrying while MongoDB is unavailable
+	repo, err := repository.NewMongoRepositoryWithOptions(ctx, mongoURI, dbName, repository.MongoOptionsFromEnv())
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -41,14 +45,83 @@ func main() {
 
 	log.Println("Connected to MongoDB")
 
+	// In a dry run the engine ticks an in-memory snapshot of the started
+	// games, logging what it would have written
+	var gameRepo repository.GameRepository
+	var dryRun *repository.DryRunRepository
+	if os.Getenv("DRY_RUN") == "true" {
+		dryRun, err = repository.NewDryRunRepository(ctx, repo)
+		if err != nil {
+			log.Fatalf("Failed to snapshot games for dry run: %v", err)
+		}
+		defer dryRun.LogSummary()
+		gameRepo = dryRun
+	}
+
+	// Cache started games between ticks, following the games change stream
+	// when MongoDB runs as a replica set
+	pollInterval := repository.DefaultGameCachePollInterval
+	if value := os.Getenv("GAME_CACHE_POLL_INTERVAL"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			pollInterval = d
+		} else {
+			log.Printf("Ignoring invalid GAME_CACHE_POLL_INTERVAL %q", value)
+		}
+	}
+	if dryRun == nil {
+		cachedRepo := repository.NewCachedRepository(repo, pollInterval)
+		go cachedRepo.Watch(ctx)
+		gameRepo = cachedRepo
+	}
+
+	// Post game lifecycle events to any configured webhooks, and mirror the
+	// event log to the configured event bus
+	var eventHooks []repository.EventHook
+	if webhookConfig := webhooks.ConfigFromEnv(); len(webhookConfig.URLs) > 0 && dryRun == nil {
+		dispatcher := webhooks.NewDispatcher(webhookConfig)
+		go dispatcher.Run(ctx)
+		eventHooks = append(eventHooks, dispatcher.Notify)
+		log.Printf("Posting %s events to %d webhooks", strings.Join(webhookConfig.Events, ", "), len(webhookConfig.URLs))
+	}
+	publisher, err := eventbus.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure the event bus: %v", err)
+	}
+	if publisher != nil && dryRun == nil {
+		bus := eventbus.NewBus(publisher)
+		go bus.Run(ctx)
+		eventHooks = append(eventHooks, bus.Notify)
+		log.Printf("Publishing events to %s", os.Getenv("EVENT_BUS"))
+	}
+	if len(eventHooks) > 0 {
+		gameRepo = repository.NewHookedRepository(gameRepo, eventHooks...)
+	}
+
 	// Create simulation engine
-	gameEngine := engine.NewGameEngine(repo)
+	gameEngine := engine.NewGameEngine(gameRepo)
+
+	// Serve read-only queries, such as combat previews, to the web server and
+	// load metrics to orchestration
+	queryPort := 3002
+	if value := os.Getenv("ENGINE_QUERY_PORT"); value != "" {
+		if port, err := strconv.Atoi(value); err == nil {
+			queryPort = port
+		} else {
+			log.Printf("Ignoring invalid ENGINE_QUERY_PORT %q", value)
+		}
+	}
+	go engine.StartQueryServer(gameEngine, queryPort)
 
 	// Start control server for manual ticks in E2E mode
 	if os.Getenv("E2E_TEST_MODE") == "true" {
 		go engine.StartControlServer(gameEngine, 3001)
 	}
 
+	// In development, hot-reload balance changes into sandbox games
+	if balanceFile := os.Getenv("BALANCE_CONFIG_FILE"); balanceFile != "" {
+		go gameEngine.WatchBalanceConfig(ctx, balanceFile, time.Second)
+	}
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -0,0 +1,180 @@
+// Package balancereport measures the current ruleset against the standard
+// balance scenarios and seeds, and describes how the outcomes shifted since
+// the previous report, so balance drift is noticed between releases rather
+// than by players.
+package balancereport
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+// Generate runs every scenario once per seed and reports its balance
+// metrics. With a baseline, each metric is compared with the baseline's
+// value for the same scenario and marked drifted when it moved further than
+// a balance golden recorded from the baseline would allow.
+func Generate(scenarios []simulator.BalanceScenario, seeds []int, rulesVersion int, baseline *models.BalanceReport, now time.Time) *models.BalanceReport {
+	report := &models.BalanceReport{
+		ReportID:     "balance-" + now.UTC().Format("20060102T150405Z"),
+		GeneratedAt:  now,
+		RulesVersion: rulesVersion,
+		Seeds:        len(seeds),
+	}
+	if baseline != nil {
+		report.BaselineID = baseline.ReportID
+	}
+	for _, scenario := range scenarios {
+		measured := simulator.MeasureBalance(scenario, seeds)
+		scenarioReport := models.BalanceScenarioReport{Scenario: scenario.Name, MaxDays: scenario.MaxDays, Metrics: measured}
+		if previous := findScenario(baseline, scenario.Name); previous != nil {
+			scenarioReport.Shifts = shifts(simulator.NewBalanceGolden(scenario, baseline.Seeds, previous.Metrics, nil), measured)
+		}
+		report.Scenarios = append(report.Scenarios, scenarioReport)
+	}
+	report.Markdown = Markdown(report)
+	return report
+}
+
+// shifts compares measured metrics with a golden recorded from the baseline,
+// in metric order
+func shifts(golden *simulator.BalanceGolden, measured map[string]float64) []models.BalanceShift {
+	var result []models.BalanceShift
+	for _, name := range metricNames(measured) {
+		band, ok := golden.Metrics[name]
+		if !ok {
+			continue
+		}
+		change := measured[name] - band.Value
+		result = append(result, models.BalanceShift{
+			Metric:   name,
+			Baseline: band.Value,
+			Current:  measured[name],
+			Change:   change,
+			Drifted:  math.Abs(change) > band.Tolerance,
+		})
+	}
+	return result
+}
+
+// findScenario returns a report's results for a scenario; a nil report has
+// none
+func findScenario(report *models.BalanceReport, name string) *models.BalanceScenarioReport {
+	if report == nil {
+		return nil
+	}
+	for i := range report.Scenarios {
+		if report.Scenarios[i].Scenario == name {
+			return &report.Scenarios[i]
+		}
+	}
+	return nil
+}
+
+// Drifts returns a description of every metric in a report that drifted
+// from its baseline, in scenario and metric order
+func Drifts(report *models.BalanceReport) []string {
+	var drifts []string
+	for _, scenario := range report.Scenarios {
+		for _, shift := range scenario.Shifts {
+			if shift.Drifted {
+				drifts = append(drifts, fmt.Sprintf("%s: %s moved from %.4g to %.4g", scenario.Scenario, shift.Metric, shift.Baseline, shift.Current))
+			}
+		}
+	}
+	return drifts
+}
+
+// Markdown renders a report for reading: each scenario's viability, its
+// fire mastery timing and the rest of its metrics, with the shift from the
+// baseline when there is one
+func Markdown(report *models.BalanceReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Balance report %s\n\n", report.GeneratedAt.UTC().Format("2006-01-02"))
+	fmt.Fprintf(&b, "Rules version %d, %d seeds per scenario.", report.RulesVersion, report.Seeds)
+	if report.BaselineID != "" {
+		fmt.Fprintf(&b, " Compared with %s.", report.BaselineID)
+	} else {
+		b.WriteString(" No baseline to compare with.")
+	}
+	b.WriteString("\n\n")
+
+	if drifts := Drifts(report); len(drifts) > 0 {
+		b.WriteString("## Drifted\n\n")
+		for _, drift := range drifts {
+			fmt.Fprintf(&b, "- %s\n", drift)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, scenario := range report.Scenarios {
+		fmt.Fprintf(&b, "## %s (%d days)\n\n", scenario.Scenario, scenario.MaxDays)
+		byMetric := make(map[string]models.BalanceShift, len(scenario.Shifts))
+		for _, shift := range scenario.Shifts {
+			byMetric[shift.Metric] = shift
+		}
+		if len(scenario.Shifts) > 0 {
+			b.WriteString("| Metric | Value | Baseline | Change |\n|---|---:|---:|---:|\n")
+		} else {
+			b.WriteString("| Metric | Value |\n|---|---:|\n")
+		}
+		for _, name := range metricNames(scenario.Metrics) {
+			value := scenario.Metrics[name]
+			shift, ok := byMetric[name]
+			switch {
+			case ok && shift.Drifted:
+				fmt.Fprintf(&b, "| %s | %s | %s | **%+.4g** |\n", name, FormatMetric(name, value), FormatMetric(name, shift.Baseline), shift.Change)
+			case ok:
+				fmt.Fprintf(&b, "| %s | %s | %s | %+.4g |\n", name, FormatMetric(name, value), FormatMetric(name, shift.Baseline), shift.Change)
+			case len(scenario.Shifts) > 0:
+				fmt.Fprintf(&b, "| %s | %s | - | - |\n", name, FormatMetric(name, value))
+			default:
+				fmt.Fprintf(&b, "| %s | %s |\n", name, FormatMetric(name, value))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// metricNames returns the metrics measured, viability first, then fire
+// mastery timing, then the rest alphabetically
+func metricNames(metrics map[string]float64) []string {
+	leading := []string{simulator.MetricViabilityRate, simulator.MetricSurvivalRate, simulator.MetricFireMasteryRate, simulator.MetricMedianDaysToFireMastery}
+	var names, rest []string
+	for _, name := range leading {
+		if _, ok := metrics[name]; ok {
+			names = append(names, name)
+		}
+	}
+	for name := range metrics {
+		if !containsString(leading, name) {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// FormatMetric shows rates as percentages and other metrics as numbers
+func FormatMetric(name string, value float64) string {
+	if strings.HasSuffix(name, "_rate") {
+		return fmt.Sprintf("%.0f%%", value*100)
+	}
+	return fmt.Sprintf("%.4g", value)
+}
+
+// containsString reports whether a slice holds a string
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package balancereport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+func TestGenerate(t *testing.T) {
+	scenarios := []simulator.BalanceScenario{
+		{Name: "default", Conditions: simulator.DefaultStartingConditions(), MaxDays: simulator.DaysPerYear},
+	}
+	seeds := simulator.BalanceSeeds[:4]
+	now := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+
+	first := Generate(scenarios, seeds, 1, nil, now)
+	if first.Seeds != 4 || len(first.Scenarios) != 1 {
+		t.Fatalf("Expected one scenario over 4 seeds, got %+v", first)
+	}
+	if len(first.Scenarios[0].Shifts) != 0 {
+		t.Errorf("Expected no shifts without a baseline, got %v", first.Scenarios[0].Shifts)
+	}
+	if !strings.Contains(first.Markdown, "No baseline") || !strings.Contains(first.Markdown, simulator.MetricViabilityRate) {
+		t.Errorf("Unexpected markdown:\n%s", first.Markdown)
+	}
+
+	// The same rules and seeds reproduce the baseline exactly
+	second := Generate(scenarios, seeds, 1, first, now.Add(24*time.Hour))
+	if second.BaselineID != first.ReportID {
+		t.Errorf("Expected baseline %s, got %s", first.ReportID, second.BaselineID)
+	}
+	if len(second.Scenarios[0].Shifts) != len(first.Scenarios[0].Metrics) {
+		t.Errorf("Expected a shift per metric, got %v", second.Scenarios[0].Shifts)
+	}
+	if drifts := Drifts(second); len(drifts) != 0 {
+		t.Errorf("Expected no drift against an identical baseline, got %v", drifts)
+	}
+
+	// A baseline with a very different peak population has drifted
+	first.Scenarios[0].Metrics[simulator.MetricMedianPeakPopulation] += 1000
+	third := Generate(scenarios, seeds, 1, first, now.Add(48*time.Hour))
+	drifts := Drifts(third)
+	if len(drifts) != 1 || !strings.Contains(drifts[0], simulator.MetricMedianPeakPopulation) {
+		t.Errorf("Expected peak population to drift, got %v", drifts)
+	}
+	if !strings.Contains(third.Markdown, "## Drifted") {
+		t.Errorf("Expected the markdown to list drift:\n%s", third.Markdown)
+	}
+}
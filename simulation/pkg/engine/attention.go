@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+// attentionWindow is how long after a player last looked at a settlement it
+// keeps simulating in full under attention fidelity
+const attentionWindow = 10 * time.Minute
+
+// NoteAttention records that a player is looking at a settlement, so it
+// simulates with the full human model while the attention lasts
+func (e *GameEngine) NoteAttention(settlementID string) {
+	e.attentionMu.Lock()
+	e.attention[settlementID] = time.Now()
+	e.attentionMu.Unlock()
+}
+
+// attended reports whether a player looked at a settlement within the
+// attention window, forgetting attention that has lapsed
+func (e *GameEngine) attended(settlementID string) bool {
+	e.attentionMu.Lock()
+	defer e.attentionMu.Unlock()
+	seen, ok := e.attention[settlementID]
+	if ok && time.Since(seen) > attentionWindow {
+		delete(e.attention, settlementID)
+		return false
+	}
+	return ok
+}
+
+// advanceSettlementYears advances a settlement's simulation by the years a
+// tick spans at the game's fidelity, a year at a time. Under attention
+// fidelity, settlements players are looking at run the daily model and the
+// rest the cheaper cohort model; both work on the same humans, so a
+// settlement switches whenever attention comes or goes.
+func (e *GameEngine) advanceSettlementYears(game *models.Game, settlement *models.Settlement, sim *simulator.Simulation, years int) *simulator.DailyMetrics {
+	period := e.advanceSettlementYear(game, settlement, sim)
+	for year := 1; year < years; year++ {
+		period.Add(e.advanceSettlementYear(game, settlement, sim))
+	}
+	return period
+}
+
+// advanceSettlementYear advances a settlement's simulation by a year at the
+// game's fidelity
+func (e *GameEngine) advanceSettlementYear(game *models.Game, settlement *models.Settlement, sim *simulator.Simulation) *simulator.DailyMetrics {
+	switch game.Fidelity() {
+	case models.SimulationFidelityAggregated:
+		return sim.AdvanceAggregated(simulator.DaysPerYear)
+	case models.SimulationFidelityAttention:
+		if !e.attended(settlement.SettlementID) {
+			return sim.AdvanceCohorts(simulator.DaysPerYear)
+		}
+	}
+	return sim.AdvanceDays(simulator.DaysPerYear)
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// BalanceConfig is a development balance file: ruleset overrides applied to
+// the listed sandbox games. Ruleset fields left out keep their current values,
+// and a building cost or unit strength left out of its table keeps its own.
+// Everything on models.Ruleset can be tuned this way, including the
+// settlement simulation's yields, fertility and mortality, building costs,
+// combat strengths and trade rates. The tables the ruleset scales, such as
+// terrain yields and the technology tree, are compiled in and need a rebuild.
+//
+//	{"sandboxGames": ["game-id"], "rules": {"settlersWalkSteps": 1, "settlement": {"fertility": 1.2}, "buildingCosts": {"library": 30}}}
+type BalanceConfig struct {
+	SandboxGames []string        `json:"sandboxGames"`
+	Rules        *models.Ruleset `json:"rules"`
+}
+
+// LoadBalanceConfig reads a balance config file, filling unspecified rules
+// from the current ruleset
+func LoadBalanceConfig(path string) (*BalanceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &BalanceConfig{Rules: models.DefaultRuleset()}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing balance config %s: %w", path, err)
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = models.DefaultRuleset()
+	}
+	return cfg, nil
+}
+
+// ApplyBalanceConfig pins the config's rules to each sandbox game. Games that
+// do not exist are skipped; other games are never touched.
+func (e *GameEngine) ApplyBalanceConfig(ctx context.Context, cfg *BalanceConfig) error {
+	for _, gameID := range cfg.SandboxGames {
+		if _, err := e.repo.GetGame(ctx, gameID); errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Balance config: sandbox game %s not found", gameID)
+			continue
+		} else if err != nil {
+			return err
+		}
+		rules := *cfg.Rules
+		rules.BuildingCosts = maps.Clone(rules.BuildingCosts)
+		rules.UnitStrengths = maps.Clone(rules.UnitStrengths)
+		if err := e.repo.UpdateGameRules(ctx, gameID, &rules); err != nil {
+			return err
+		}
+		log.Printf("Balance config applied to sandbox game %s", gameID)
+	}
+	return nil
+}
+
+// WatchBalanceConfig polls a balance config file and applies it to its
+// sandbox games whenever it changes, until ctx is cancelled. Intended for
+// development: tune balance on live test games without restarting the engine.
+func (e *GameEngine) WatchBalanceConfig(ctx context.Context, path string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Watching balance config %s", path)
+
+	var lastModified time.Time
+	for {
+		if info, err := os.Stat(path); err != nil {
+			log.Printf("Balance config: %v", err)
+		} else if !info.ModTime().Equal(lastModified) {
+			lastModified = info.ModTime()
+			cfg, err := LoadBalanceConfig(path)
+			if err != nil {
+				log.Printf("Balance config: %v", err)
+			} else if err := e.ApplyBalanceConfig(ctx, cfg); err != nil {
+				log.Printf("Error applying balance config: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
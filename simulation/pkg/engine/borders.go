@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// borderSides are the edges of a tile, each as the neighbour across it and
+// the corners it runs between clockwise, offset from the tile's own corner
+var borderSides = []struct {
+	neighbour, from, to models.Location
+}{
+	{neighbour: models.Location{Y: -1}, from: models.Location{}, to: models.Location{X: 1}},          // Top
+	{neighbour: models.Location{X: 1}, from: models.Location{X: 1}, to: models.Location{X: 1, Y: 1}}, // Right
+	{neighbour: models.Location{Y: 1}, from: models.Location{X: 1, Y: 1}, to: models.Location{Y: 1}}, // Bottom
+	{neighbour: models.Location{X: -1}, from: models.Location{Y: 1}, to: models.Location{}},          // Left
+}
+
+// buildBorders outlines every territory a player knows of. An owned tile's
+// edge is a border where the tile across it belongs to someone else or no
+// one, or lies off the map; edges onto tiles the player has not explored
+// are left out, so lines stop at the fog rather than guess.
+func buildBorders(game *models.Game, metadata *models.MapMetadata, tiles []*models.MapTile, explored []*models.ExploredTile, playerID string) *models.Borders {
+	known := knownTiles(game, tiles, explored, playerID)
+
+	edges := make(map[string]map[models.Location][]models.Location) // Owner -> corner -> corners edges lead on to
+	for loc, tile := range known {
+		if tile.ownerID == nil {
+			continue
+		}
+		owner := *tile.ownerID
+		for _, side := range borderSides {
+			across := models.Location{X: loc.X + side.neighbour.X, Y: loc.Y + side.neighbour.Y}
+			onMap := across.X >= 0 && across.Y >= 0 && across.X < metadata.Width && across.Y < metadata.Height
+			if neighbour, ok := known[across]; onMap && (!ok || (neighbour.ownerID != nil && *neighbour.ownerID == owner)) {
+				continue
+			}
+			if edges[owner] == nil {
+				edges[owner] = make(map[models.Location][]models.Location)
+			}
+			from := models.Location{X: loc.X + side.from.X, Y: loc.Y + side.from.Y}
+			to := models.Location{X: loc.X + side.to.X, Y: loc.Y + side.to.Y}
+			edges[owner][from] = append(edges[owner][from], to)
+		}
+	}
+
+	owners := make([]string, 0, len(edges))
+	for owner := range edges {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	territories := make([]models.TerritoryBorder, 0, len(owners))
+	for _, owner := range owners {
+		territories = append(territories, models.TerritoryBorder{OwnerID: owner, Lines: traceBorderLines(edges[owner])})
+	}
+
+	return &models.Borders{
+		GameID:      game.GameID,
+		PlayerID:    playerID,
+		Territories: territories,
+		Year:        game.CurrentYear,
+		GeneratedAt: time.Now(),
+	}
+}
+
+// traceBorderLines joins a territory's border edges, given as the corners
+// each corner's edges lead on to, into lines. Lines that stop at the fog are
+// traced first from where they start, then the closed outlines, each from
+// its top-left corner. Corners where a line runs straight on are dropped.
+func traceBorderLines(next map[models.Location][]models.Location) [][]models.Location {
+	corners := make([]models.Location, 0, len(next))
+	arriving := make(map[models.Location]int)
+	for corner, ends := range next {
+		corners = append(corners, corner)
+		for _, end := range ends {
+			arriving[end]++
+		}
+		sort.Slice(ends, func(i, j int) bool { return lessLocation(ends[i], ends[j]) })
+	}
+	sort.Slice(corners, func(i, j int) bool { return lessLocation(corners[i], corners[j]) })
+
+	var lines [][]models.Location
+	trace := func(start models.Location, closed bool) {
+		line := []models.Location{start}
+		for corner := start; len(next[corner]) > 0; {
+			end := next[corner][0]
+			next[corner] = next[corner][1:]
+			arriving[end]--
+			line = append(line, end)
+			corner = end
+			if closed && corner == start {
+				break
+			}
+		}
+		lines = append(lines, straighten(line))
+	}
+	for _, corner := range corners {
+		for len(next[corner]) > arriving[corner] {
+			trace(corner, false)
+		}
+	}
+	for _, corner := range corners {
+		for len(next[corner]) > 0 {
+			trace(corner, true)
+		}
+	}
+	return lines
+}
+
+// straighten drops the corners of a line where it runs straight on
+func straighten(line []models.Location) []models.Location {
+	kept := line[:1]
+	for i := 1; i < len(line)-1; i++ {
+		before, after := kept[len(kept)-1], line[i+1]
+		if (before.X == line[i].X && line[i].X == after.X) || (before.Y == line[i].Y && line[i].Y == after.Y) {
+			continue
+		}
+		kept = append(kept, line[i])
+	}
+	return append(kept, line[len(line)-1])
+}
+
+// lessLocation orders locations by row, then column
+func lessLocation(a, b models.Location) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.X < b.X
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// executeBuildOrder spends a settlement's banked production on a building,
+// at the cost the game's ruleset sets. The player must have the building's
+// strategic resources, and a harbor needs water within the settlement's
+// work area.
+func (e *GameEngine) executeBuildOrder(ctx context.Context, game *models.Game, order *models.Order) error {
+	cost, known := game.Ruleset().BuildingCosts[order.Item]
+	if !known {
+		return fmt.Errorf("unknown building %q", order.Item)
+	}
+
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, order.PlayerID)
+	if err != nil {
+		return err
+	}
+	var settlement *models.Settlement
+	for _, s := range settlements {
+		if s.SettlementID == order.SettlementID {
+			settlement = s
+		}
+	}
+	if settlement == nil {
+		return fmt.Errorf("settlement %s not found for player", order.SettlementID)
+	}
+	if containsString(settlement.Buildings, order.Item) {
+		return fmt.Errorf("%s already has a %s", settlement.Name, order.Item)
+	}
+	if err := e.CanBuild(game.GameID, order.PlayerID, order.Item); err != nil {
+		return err
+	}
+	if order.Item == models.BuildingHarbor && !e.isCoastalSettlement(ctx, game, settlement) {
+		return fmt.Errorf("%s has no water to build a harbor on", settlement.Name)
+	}
+	if settlement.Production < cost {
+		return fmt.Errorf("%s needs %d production for a %s, has %d", settlement.Name, cost, order.Item, settlement.Production)
+	}
+
+	settlement.Production -= cost
+	settlement.Buildings = append(settlement.Buildings, order.Item)
+	settlement.LastUpdated = time.Now()
+	if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+		return err
+	}
+	log.Printf("Game %s: %s built a %s", game.GameID, settlement.Name, order.Item)
+	return nil
+}
+
+// isCoastalSettlement reports whether a settlement's work area reaches water
+func (e *GameEngine) isCoastalSettlement(ctx context.Context, game *models.Game, settlement *models.Settlement) bool {
+	for _, tile := range e.settlementWorkTiles(ctx, game, settlement) {
+		if terrain.IsWater(tile.TerrainType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// behindScheduleTicks is how many tick intervals a due game may wait before
+// it counts as behind schedule
+const behindScheduleTicks = 2
+
+// Capacity summarises the simulation load of an engine replica after its
+// last pass, so orchestration can scale replicas on the work actually being
+// done rather than on CPU or memory
+type Capacity struct {
+	Games            int       `json:"games"`            // Running games this replica ticks
+	QueueDepth       int       `json:"queueDepth"`       // Ticks owed but not yet run: games deferred past the pass budget plus catch-up ticks
+	BehindSchedule   int       `json:"behindSchedule"`   // Games catching up, overloaded or a full tick interval late
+	AvgTickLatencyMs float64   `json:"avgTickLatencyMs"` // Mean of the games' smoothed tick durations
+	Degraded         bool      `json:"degraded"`         // Waiting out a store outage; load figures are stale
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Capacity returns the load measured at the end of the engine's last pass
+func (e *GameEngine) Capacity() Capacity {
+	e.capacityMu.RLock()
+	defer e.capacityMu.RUnlock()
+	return e.capacity
+}
+
+// recordCapacity measures the engine's load once a pass has ticked what it
+// could; deferred are the due games left for a later pass
+func (e *GameEngine) recordCapacity(games []*models.Game, deferred map[string]bool) {
+	now := time.Now()
+	c := Capacity{Games: len(games), UpdatedAt: now}
+	var latency time.Duration
+	measured := 0
+	for _, game := range games {
+		cost := e.tickCosts[game.GameID]
+		if cost != nil {
+			latency += cost.average
+			measured++
+		}
+		late := game.LastTickAt != nil && now.Sub(*game.LastTickAt) > behindScheduleTicks*models.TickInterval
+		catchUp := e.catchUp[game.GameID]
+		if deferred[game.GameID] {
+			c.QueueDepth++
+		}
+		c.QueueDepth += catchUp
+		if catchUp > 0 || (cost != nil && cost.overloaded) || (deferred[game.GameID] && late) {
+			c.BehindSchedule++
+		}
+	}
+	if measured > 0 {
+		c.AvgTickLatencyMs = float64(latency/time.Duration(measured)) / float64(time.Millisecond)
+	}
+
+	e.capacityMu.Lock()
+	defer e.capacityMu.Unlock()
+	c.Degraded = e.capacity.Degraded
+	e.capacity = c
+}
+
+// recordDegraded notes whether the engine is degraded for capacity reports
+func (e *GameEngine) recordDegraded() {
+	e.capacityMu.Lock()
+	defer e.capacityMu.Unlock()
+	e.capacity.Degraded = e.Degraded()
+}
+
+// writeCapacityMetrics writes the capacity in the Prometheus text format
+func writeCapacityMetrics(w io.Writer, c Capacity) {
+	degraded := 0
+	if c.Degraded {
+		degraded = 1
+	}
+	metrics := []struct {
+		name, help string
+		value      float64
+	}{
+		{"simciv_engine_games", "Running games this engine replica ticks.", float64(c.Games)},
+		{"simciv_engine_queue_depth", "Ticks owed but not yet run.", float64(c.QueueDepth)},
+		{"simciv_engine_games_behind_schedule", "Games catching up, overloaded or a tick interval late.", float64(c.BehindSchedule)},
+		{"simciv_engine_tick_latency_seconds", "Mean smoothed tick duration across games.", c.AvgTickLatencyMs / 1000},
+		{"simciv_engine_degraded", "1 while the engine waits out a store outage.", float64(degraded)},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", m.name, m.help, m.name, m.name, m.value)
+	}
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// Catch-up modes, chosen with CATCHUP_MODE
+const (
+	// CatchUpReplay runs the ticks missed during downtime in rate-limited batches
+	CatchUpReplay = "replay"
+	// CatchUpAdjust skips the missed ticks and records a clock adjustment
+	CatchUpAdjust = "adjust"
+)
+
+// downtimeGraceTicks is how many missed ticks are tolerated before a gap
+// counts as engine downtime
+const downtimeGraceTicks = 5
+
+// catchUpBatchTicks is how many missed ticks a game replays per engine pass
+const catchUpBatchTicks = 10
+
+// maxCatchUpTicks caps how far a game is replayed after a long outage
+const maxCatchUpTicks = 24 * 60 * 60
+
+// SetCatchUpMode chooses how games recover time lost while the engine was down
+func (e *GameEngine) SetCatchUpMode(mode string) {
+	e.catchUpMode = mode
+}
+
+// catchUpModeFromEnv reads CATCHUP_MODE, falling back to replay
+func catchUpModeFromEnv() string {
+	switch mode := os.Getenv("CATCHUP_MODE"); mode {
+	case "", CatchUpReplay:
+		return CatchUpReplay
+	case CatchUpAdjust:
+		return CatchUpAdjust
+	default:
+		log.Printf("Ignoring invalid CATCHUP_MODE %q", mode)
+		return CatchUpReplay
+	}
+}
+
+// missedTicks counts the ticks a game should have run between its last tick
+// and now, excluding time its schedule held it paused
+func missedTicks(game *models.Game, now time.Time) int {
+	if game.LastTickAt == nil {
+		return 0
+	}
+	from := *game.LastTickAt
+	if now.Sub(from) > maxCatchUpTicks*models.TickInterval {
+		from = now.Add(-maxCatchUpTicks * models.TickInterval)
+	}
+	if game.Schedule == nil {
+		return int(now.Sub(from)/models.TickInterval) - 1
+	}
+
+	// Schedules work in whole minutes, so count the scheduled ones
+	missed := 0
+	for t := from; t.Before(now); t = t.Add(time.Minute) {
+		if game.Schedule.Allows(t) {
+			missed += int(min(time.Minute, now.Sub(t)) / models.TickInterval)
+		}
+	}
+	return missed - 1
+}
+
+// detectDowntime notices when a game has missed ticks because the engine was
+// down and either queues them for replay or records a clock adjustment
+func (e *GameEngine) detectDowntime(ctx context.Context, game *models.Game) error {
+	if e.catchUp[game.GameID] > 0 {
+		return nil
+	}
+	missed := missedTicks(game, time.Now())
+	if missed < downtimeGraceTicks {
+		return nil
+	}
+
+	if e.catchUpMode == CatchUpAdjust {
+		log.Printf("Game %s missed %d ticks during downtime, adjusting its clock", game.GameID, missed)
+		return e.repo.RecordClockAdjustment(ctx, game.GameID, models.ClockAdjustment{
+			At:           time.Now(),
+			Year:         game.CurrentYear,
+			SkippedTicks: missed,
+		})
+	}
+
+	log.Printf("Game %s missed %d ticks during downtime, catching up", game.GameID, missed)
+	e.catchUp[game.GameID] = missed
+	return nil
+}
+
+// processCatchUp replays one batch of a game's missed ticks
+func (e *GameEngine) processCatchUp(ctx context.Context, game *models.Game) error {
+	remaining := e.catchUp[game.GameID]
+	batch := min(remaining, catchUpBatchTicks)
+	for i := 0; i < batch; i++ {
+		if !game.IsStarted() {
+			remaining = 0
+			break
+		}
+		if err := withTimeout(ctx, e.tickTimeout, func(ctx context.Context) error { return e.processGameTick(ctx, game) }); err != nil {
+			e.catchUp[game.GameID] = remaining
+			return err
+		}
+		remaining--
+	}
+
+	if remaining == 0 {
+		delete(e.catchUp, game.GameID)
+		log.Printf("Game %s caught up at year %d", game.GameID, game.CurrentYear)
+	} else {
+		e.catchUp[game.GameID] = remaining
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	combatRounds = 12 // Rounds fought before an undecided combat breaks off
+	combatDamage = 20 // Health the loser of a round loses
+)
+
+// unitCombatant returns a unit's side of a combat at the strength the rules
+// give its type, stronger while a great general inspires it
+func unitCombatant(rules *models.Ruleset, unit *models.Unit) combatant {
+	strength := rules.UnitStrengths[unit.UnitType]
+	if unit.Aura > 0 {
+		strength *= 1 + generalAuraBonus
+	}
+	return combatant{strength: strength, health: unit.Health()}
+}
+
+// ErrCombatantNotFound is returned when a combat names a unit or settlement
+// that does not exist in the game
+var ErrCombatantNotFound = errors.New("combatant not found")
+
+// combatant is one side of a combat as the resolver sees it
+type combatant struct {
+	strength float64
+	health   int
+}
+
+// CombatOdds is the outcome distribution of a combat, computed exactly
+// without fighting it
+type CombatOdds struct {
+	AttackerStrength       float64         `json:"attackerStrength"`
+	DefenderStrength       float64         `json:"defenderStrength"`
+	AttackerWins           float64         `json:"attackerWins"` // Chance the defender is destroyed
+	DefenderWins           float64         `json:"defenderWins"` // Chance the attacker is destroyed
+	Stalemate              float64         `json:"stalemate"`    // Chance both survive combatRounds
+	AttackerHealth         map[int]float64 `json:"attackerHealth"`
+	DefenderHealth         map[int]float64 `json:"defenderHealth"`
+	ExpectedAttackerHealth float64         `json:"expectedAttackerHealth"`
+	ExpectedDefenderHealth float64         `json:"expectedDefenderHealth"`
+}
+
+// PreviewCombat returns the odds of a unit attacking another unit or a
+// settlement, identified by ID, as the resolver would fight it this year.
+// Nothing is changed.
+func (e *GameEngine) PreviewCombat(ctx context.Context, gameID, attackerID, defenderID string) (*CombatOdds, error) {
+	game, err := e.repo.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	attacker, defender, err := e.combatants(ctx, game, attackerID, defenderID)
+	if err != nil {
+		return nil, err
+	}
+	return combatOdds(attacker, defender), nil
+}
+
+// combatants looks up the two sides of a combat. The attacker is a unit; the
+// defender is a unit, which fights with the terrain and any settlement it
+// stands in and defends, or a settlement, which fights with its garrison.
+func (e *GameEngine) combatants(ctx context.Context, game *models.Game, attackerID, defenderID string) (combatant, combatant, error) {
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return combatant{}, combatant{}, err
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return combatant{}, combatant{}, err
+	}
+	overlords, err := e.overlords(ctx, game)
+	if err != nil {
+		return combatant{}, combatant{}, err
+	}
+
+	var attackerUnit, defenderUnit *models.Unit
+	for _, unit := range units {
+		switch unit.UnitID {
+		case attackerID:
+			attackerUnit = unit
+		case defenderID:
+			defenderUnit = unit
+		}
+	}
+	if attackerUnit == nil {
+		return combatant{}, combatant{}, fmt.Errorf("%w: attacker %s", ErrCombatantNotFound, attackerID)
+	}
+	rules := game.Ruleset()
+	attacker := unitCombatant(rules, attackerUnit)
+
+	var defender combatant
+	var location models.Location
+	if defenderUnit != nil {
+		defender = unitCombatant(rules, defenderUnit)
+		location = defenderUnit.Location
+		for _, settlement := range settlements {
+			if settlement.Location == location && defends(game, overlords, defenderUnit.PlayerID, settlement.PlayerID) {
+				defender.strength *= settlement.Defense()
+			}
+		}
+	} else {
+		var settlement *models.Settlement
+		for _, s := range settlements {
+			if s.SettlementID == defenderID {
+				settlement = s
+			}
+		}
+		if settlement == nil {
+			return combatant{}, combatant{}, fmt.Errorf("%w: defender %s", ErrCombatantNotFound, defenderID)
+		}
+		defender = combatant{strength: rules.SettlementStrength * settlement.Defense(), health: models.MaxUnitHealth}
+		location = settlement.Location
+	}
+	if tile, err := e.repo.GetMapTile(ctx, game.GameID, location.X, location.Y); err == nil && tile != nil {
+		defender.strength *= terrain.DefenseModifier(tile.TerrainType)
+	}
+	return attacker, defender, nil
+}
+
+// roundOdds is the chance the attacker wins each round
+func roundOdds(attacker, defender combatant) float64 {
+	if attacker.strength+defender.strength <= 0 {
+		return 0.5
+	}
+	return attacker.strength / (attacker.strength + defender.strength)
+}
+
+// resolveCombat fights a combat round by round with draws from the stream,
+// returning the health each side is left with
+func resolveCombat(attacker, defender combatant, stream *rng.Stream) (int, int) {
+	p := roundOdds(attacker, defender)
+	a, d := attacker.health, defender.health
+	for round := 0; round < combatRounds && a > 0 && d > 0; round++ {
+		if stream.Float64() < p {
+			d = max(d-combatDamage, 0)
+		} else {
+			a = max(a-combatDamage, 0)
+		}
+	}
+	return a, d
+}
+
+// combatOdds computes the exact outcome distribution of resolveCombat by
+// carrying the chance of every pair of hits taken through each round
+func combatOdds(attacker, defender combatant) *CombatOdds {
+	p := roundOdds(attacker, defender)
+	odds := &CombatOdds{
+		AttackerStrength: attacker.strength,
+		DefenderStrength: defender.strength,
+		AttackerHealth:   make(map[int]float64),
+		DefenderHealth:   make(map[int]float64),
+	}
+
+	// chances[i][j] is the chance the attacker has taken i hits and the
+	// defender j; a side is destroyed once it has taken its last hit
+	attackerHits := (attacker.health + combatDamage - 1) / combatDamage
+	defenderHits := (defender.health + combatDamage - 1) / combatDamage
+	grid := func() [][]float64 {
+		g := make([][]float64, attackerHits+1)
+		for i := range g {
+			g[i] = make([]float64, defenderHits+1)
+		}
+		return g
+	}
+	chances := grid()
+	chances[0][0] = 1
+	for round := 0; round < combatRounds; round++ {
+		next := grid()
+		for i, row := range chances {
+			for j, chance := range row {
+				if i == attackerHits || j == defenderHits {
+					next[i][j] += chance
+					continue
+				}
+				next[i][j+1] += chance * p
+				next[i+1][j] += chance * (1 - p)
+			}
+		}
+		chances = next
+	}
+
+	for i, row := range chances {
+		for j, chance := range row {
+			if chance == 0 {
+				continue
+			}
+			switch {
+			case j == defenderHits:
+				odds.AttackerWins += chance
+			case i == attackerHits:
+				odds.DefenderWins += chance
+			default:
+				odds.Stalemate += chance
+			}
+			a := max(attacker.health-i*combatDamage, 0)
+			d := max(defender.health-j*combatDamage, 0)
+			odds.AttackerHealth[a] += chance
+			odds.DefenderHealth[d] += chance
+			odds.ExpectedAttackerHealth += chance * float64(a)
+			odds.ExpectedDefenderHealth += chance * float64(d)
+		}
+	}
+	return odds
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// defaultTickTimeout bounds one game's tick, so a hung store call stalls
+// that game rather than the whole engine loop
+const defaultTickTimeout = 30 * time.Second
+
+// mapGenerationTimeout bounds generating and saving a new game's map, which
+// takes far longer than a tick
+const mapGenerationTimeout = 2 * time.Minute
+
+// tickClaimLease is how long an engine instance's claim on a game's tick
+// holds. An instance that dies mid-tick stalls the game this long before
+// another takes the year over; it must outlast any tick that is running.
+const tickClaimLease = 10 * time.Minute
+
+// errTickTimeout marks work abandoned because it ran past its own deadline
+var errTickTimeout = errors.New("deadline exceeded")
+
+// SetTickTimeout sets how long a game's tick may run before its remaining
+// phases are skipped
+func (e *GameEngine) SetTickTimeout(timeout time.Duration) {
+	e.tickTimeout = timeout
+}
+
+// tickTimeoutFromEnv reads TICK_TIMEOUT as a Go duration such as "10s"
+func tickTimeoutFromEnv() time.Duration {
+	if value := os.Getenv("TICK_TIMEOUT"); value != "" {
+		if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+			return timeout
+		}
+		log.Printf("Ignoring invalid TICK_TIMEOUT %q", value)
+	}
+	return defaultTickTimeout
+}
+
+// withTimeout runs fn under a deadline of its own. Work that overruns it is
+// reported as errTickTimeout rather than as whatever store error the
+// expiring context surfaced, so one slow game is not mistaken for the store
+// being down; an error from ctx itself being cancelled is returned as is.
+func withTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	bounded, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := fn(bounded)
+	if err != nil && ctx.Err() == nil && errors.Is(bounded.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %v: %v", errTickTimeout, timeout, err)
+	}
+	return err
+}
+
+// abortsPass reports whether a game's failure should end the whole engine
+// pass: the store is unreachable, or the engine itself is stopping
+func abortsPass(ctx context.Context, err error) bool {
+	return errors.Is(err, repository.ErrUnavailable) || ctx.Err() != nil
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// Backoff bounds between attempts to reach the store while degraded
+const (
+	degradedInitialBackoff = 500 * time.Millisecond
+	degradedMaxBackoff     = 30 * time.Second
+)
+
+// healthCheckTimeout bounds each reachability check while degraded
+const healthCheckTimeout = 5 * time.Second
+
+// Degraded reports whether the engine has lost contact with its store and
+// is waiting to retry instead of ticking
+func (e *GameEngine) Degraded() bool {
+	return e.storeFailures > 0
+}
+
+// storeReady reports whether the engine should attempt a pass. While
+// degraded it waits out the backoff, then checks the store's health before
+// resuming.
+func (e *GameEngine) storeReady(ctx context.Context) bool {
+	if e.storeFailures == 0 {
+		return true
+	}
+	if time.Now().Before(e.nextStoreAttempt) {
+		return false
+	}
+
+	if checker, ok := e.repo.(repository.HealthChecker); ok {
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		defer cancel()
+		if err := checker.Ping(pingCtx); err != nil {
+			e.recordStoreFailure(err)
+			return false
+		}
+	}
+	return true
+}
+
+// recordStoreFailure enters or extends degraded mode, backing off
+// exponentially before the next attempt
+func (e *GameEngine) recordStoreFailure(err error) {
+	e.storeFailures++
+	delay := repository.Backoff(e.storeFailures, degradedInitialBackoff, degradedMaxBackoff)
+	e.nextStoreAttempt = time.Now().Add(delay)
+	if e.storeFailures == 1 {
+		log.Printf("Store unavailable, entering degraded mode: %v", err)
+	}
+	log.Printf("Store attempt %d failed, retrying in %v", e.storeFailures, delay)
+}
+
+// recordStoreSuccess leaves degraded mode
+func (e *GameEngine) recordStoreSuccess() {
+	if e.storeFailures > 0 {
+		log.Printf("Store reachable again after %d failed attempts, resuming", e.storeFailures)
+	}
+	e.storeFailures = 0
+	e.nextStoreAttempt = time.Time{}
+}
+
+// recordPassResult updates degraded mode after an engine pass. A missing
+// document or a lost race means the store answered, so only other failures
+// count against it.
+func (e *GameEngine) recordPassResult(err error) {
+	switch {
+	case err == nil:
+		e.recordStoreSuccess()
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrConflict):
+		log.Printf("Engine pass interrupted: %v", err)
+		e.recordStoreSuccess()
+	default:
+		e.recordStoreFailure(err)
+	}
+	e.recordDegraded()
+}
+
+// handleTickError deals with a game's failed tick. A game deleted mid-tick
+// has its engine state dropped; a tick that lost a race to another engine
+// instance is left to it. A tick that ran past its deadline closed its year
+// without its remaining phases; the next year ticks as usual.
+func (e *GameEngine) handleTickError(game *models.Game, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		log.Printf("Game %s is gone, dropping its engine state: %v", game.GameID, err)
+		e.forgetGame(game.GameID)
+	case errors.Is(err, repository.ErrConflict):
+		log.Printf("Game %s changed concurrently, retrying next pass: %v", game.GameID, err)
+	case errors.Is(err, errTickTimeout):
+		log.Printf("Game %s tick cut short at its deadline: %v", game.GameID, err)
+	default:
+		log.Printf("Error processing game %s tick: %v", game.GameID, err)
+	}
+}
+
+// forgetGame drops the per-game state the engine holds between ticks
+func (e *GameEngine) forgetGame(gameID string) {
+	delete(e.catchUp, gameID)
+	delete(e.tickCosts, gameID)
+	delete(e.spawnedPlayers, gameID)
+	delete(e.unitSightings, gameID)
+
+	e.governorMu.Lock()
+	delete(e.governed, gameID)
+	e.governorMu.Unlock()
+	e.accessMu.Lock()
+	delete(e.resourceAccess, gameID)
+	e.accessMu.Unlock()
+	e.tripsMu.Lock()
+	delete(e.trips, gameID)
+	e.tripsMu.Unlock()
+	e.invalidatePaths(gameID)
+}
+
+// forgetStoppedGames drops the engine state of games no longer running:
+// those that finished, were paused or archived, or were deleted since the
+// engine last ticked them
+func (e *GameEngine) forgetStoppedGames(running []*models.Game) {
+	live := make(map[string]bool, len(running))
+	for _, game := range running {
+		live[game.GameID] = true
+	}
+
+	held := make(map[string]bool)
+	for gameID := range e.tickCosts {
+		held[gameID] = true
+	}
+	for gameID := range e.unitSightings {
+		held[gameID] = true
+	}
+	e.pathsMu.Lock()
+	for gameID := range e.pathGraphs {
+		held[gameID] = true
+	}
+	e.pathsMu.Unlock()
+	e.accessMu.RLock()
+	for gameID := range e.resourceAccess {
+		held[gameID] = true
+	}
+	e.accessMu.RUnlock()
+
+	for gameID := range held {
+		if !live[gameID] {
+			log.Printf("Game %s is no longer running, dropping its engine state", gameID)
+			e.forgetGame(gameID)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+const (
+	desireGrowth            = 0.1  // How much an unmet desire strengthens each year
+	desireRelief            = 0.25 // How much a met desire fades each year
+	desireProposalThreshold = 0.5  // Strength at which a desire proposes a building
+	desireProposalYears     = 10   // Years before a building the player ignored is proposed again
+)
+
+// settlementDesires lists desires in the order they are weighed
+var settlementDesires = []string{models.DesireRoads, models.DesireTrade, models.DesireSecurity, models.DesireKnowledge}
+
+// processDesires lets each settlement's people want what their conditions
+// lack: roads to the player's other settlements, trade partners, security
+// and learning. Unmet desires strengthen every year and met ones fade; a
+// settlement is as unhappy as its desires are strong on average, and more
+// so under heavy taxes and corvée; a strong desire proposes a building that
+// would meet it for the player to approve.
+func (e *GameEngine) processDesires(ctx context.Context, game *models.Game) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+
+	holdings := make(map[string]int)
+	for _, settlement := range settlements {
+		holdings[settlement.PlayerID]++
+	}
+	river := e.riverPartners(ctx, game, settlements)
+	policies := e.playerPolicies(ctx, game.GameID)
+	unpaved := make(map[string]bool)
+	for _, route := range e.busyRoutes(ctx, game, settlements) {
+		unpaved[route.from.SettlementID] = true
+		unpaved[route.to.SettlementID] = true
+	}
+
+	for _, settlement := range settlements {
+		if settlement.PlayerID == models.NeutralPlayerID || models.IsMinorCiv(settlement.PlayerID) {
+			continue
+		}
+		met := e.metDesires(ctx, game, settlement, holdings[settlement.PlayerID], river[settlement.SettlementID], unpaved[settlement.SettlementID])
+
+		if settlement.Desires == nil {
+			settlement.Desires = make(map[string]float64)
+		}
+		total := 0.0
+		for _, desire := range settlementDesires {
+			strength := settlement.Desires[desire]
+			if met[desire] {
+				strength = max(0, strength-desireRelief)
+			} else {
+				strength = min(1, strength+desireGrowth)
+			}
+			if strength == 0 {
+				delete(settlement.Desires, desire)
+			} else {
+				settlement.Desires[desire] = strength
+			}
+			total += strength
+		}
+		tax, corvee := settlementLevers(policies[settlement.PlayerID], settlement)
+		settlement.Unhappiness = min(1, total/float64(len(settlementDesires))+leverUnhappiness(tax, corvee))
+
+		for _, desire := range settlementDesires {
+			if settlement.Desires[desire] < desireProposalThreshold {
+				continue
+			}
+			if building := e.desiredBuilding(ctx, game, settlement, desire); building != "" {
+				e.proposeBuilding(ctx, game, settlement, building)
+			}
+		}
+
+		settlement.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating desires of settlement %s: %v", settlement.SettlementID, err)
+		}
+	}
+	return nil
+}
+
+// metDesires reports which desires a settlement's conditions meet. A
+// player's only settlement has nowhere to build roads to; others want a road
+// nearby and every busy route from them paved. A nomadic camp is too busy
+// moving to long for learning.
+func (e *GameEngine) metDesires(ctx context.Context, game *models.Game, settlement *models.Settlement, holdings, riverPartners int, unpavedRoute bool) map[string]bool {
+	met := map[string]bool{
+		models.DesireRoads:     holdings <= 1,
+		models.DesireTrade:     riverPartners > 0 || len(settlement.SeaRoutes) > 0 || containsString(settlement.Buildings, "treasury"),
+		models.DesireSecurity:  settlement.Garrison > 0 || containsString(settlement.Buildings, models.BuildingPalisade),
+		models.DesireKnowledge: settlement.Type == models.SettlementTypeNomadicCamp || containsString(settlement.Buildings, models.BuildingLibrary),
+	}
+	if !met[models.DesireRoads] && !unpavedRoute {
+		for _, tile := range e.settlementWorkTiles(ctx, game, settlement) {
+			if containsString(tile.Improvements, models.ImprovementRoad) {
+				met[models.DesireRoads] = true
+				break
+			}
+		}
+	}
+	return met
+}
+
+// desiredBuilding returns the building that would meet a settlement's
+// desire, or "" when none would or the settlement already has it. Roads
+// are built by workers, not proposed as buildings.
+func (e *GameEngine) desiredBuilding(ctx context.Context, game *models.Game, settlement *models.Settlement, desire string) string {
+	var building string
+	switch desire {
+	case models.DesireTrade:
+		building = "treasury"
+		if e.isCoastalSettlement(ctx, game, settlement) {
+			building = models.BuildingHarbor
+		}
+	case models.DesireSecurity:
+		building = models.BuildingPalisade
+	case models.DesireKnowledge:
+		building = models.BuildingLibrary
+	}
+	if building == "" || containsString(settlement.Buildings, building) {
+		return ""
+	}
+	return building
+}
+
+// proposeBuilding queues a proposed build order for a settlement, unless it
+// proposed the same building recently or the player cannot build it
+func (e *GameEngine) proposeBuilding(ctx context.Context, game *models.Game, settlement *models.Settlement, building string) {
+	if e.CanBuild(game.GameID, settlement.PlayerID, building) != nil {
+		return
+	}
+	order := &models.Order{OrderType: models.OrderTypeBuild, SettlementID: settlement.SettlementID, Item: building}
+	e.propose(ctx, game, settlement, building, order, fmt.Sprintf("The people of %s propose building a %s", settlement.Name, building))
+}
+
+// propose queues an order a settlement's people put forward for the player
+// to approve and records it as an event; players who end their turns
+// automatically have it approved at once. Each project, named by key, is
+// proposed again only once desireProposalYears have passed.
+func (e *GameEngine) propose(ctx context.Context, game *models.Game, settlement *models.Settlement, key string, order *models.Order, detail string) {
+	if year, ok := settlement.Proposals[key]; ok && game.CurrentYear-year < desireProposalYears {
+		return
+	}
+
+	order.OrderID = generateUUID()
+	order.GameID = game.GameID
+	order.PlayerID = settlement.PlayerID
+	order.Status = models.OrderStatusProposed
+	if e.autoEndsTurn(ctx, game.GameID, settlement.PlayerID) {
+		order.Status = models.OrderStatusPending
+	}
+	order.CreatedAt = time.Now()
+	if err := e.repo.CreateOrder(ctx, order); err != nil {
+		log.Printf("Error proposing %s for settlement %s: %v", key, settlement.SettlementID, err)
+		return
+	}
+	if settlement.Proposals == nil {
+		settlement.Proposals = make(map[string]int)
+	}
+	settlement.Proposals[key] = game.CurrentYear
+
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventProjectProposed,
+		PlayerID:  settlement.PlayerID,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording proposal of settlement %s: %v", settlement.SettlementID, err)
+	}
+	log.Printf("Game %s: %s", game.GameID, detail)
+}
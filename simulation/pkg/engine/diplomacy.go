@@ -0,0 +1,288 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+const (
+	demandExpiry   = 5    // Years a demand stands before it lapses unanswered
+	vassalShare    = 0.25 // Share of its banked production a vassal sends its overlord each year
+	grievanceYears = 50   // Years after a broken agreement before the pair can make another
+)
+
+// diplomacyState returns the stored standing between two players, or a
+// fresh one in their default stance
+func (e *GameEngine) diplomacyState(ctx context.Context, game *models.Game, a, b string) (*models.DiplomacyState, error) {
+	states, err := e.repo.GetDiplomacyStates(ctx, game.GameID)
+	if err != nil {
+		return nil, err
+	}
+	fresh := models.NewDiplomacyState(game, a, b)
+	for _, state := range states {
+		if state.PlayerA == fresh.PlayerA && state.PlayerB == fresh.PlayerB {
+			return state, nil
+		}
+	}
+	return fresh, nil
+}
+
+// saveDiplomacyState stamps and persists the standing between two players
+func (e *GameEngine) saveDiplomacyState(ctx context.Context, state *models.DiplomacyState) error {
+	state.LastUpdated = time.Now()
+	return e.repo.SaveDiplomacyState(ctx, state)
+}
+
+// executeDemandOrder records a player's demand that another pay them tribute
+// or become their vassal. The demand stands until the other player accepts
+// it or it lapses.
+func (e *GameEngine) executeDemandOrder(ctx context.Context, game *models.Game, order *models.Order) error {
+	target := order.TargetPlayer
+	if target == order.PlayerID || !containsString(game.ActivePlayers(), target) {
+		return fmt.Errorf("player %s cannot be made a demand of", target)
+	}
+	switch order.Item {
+	case models.AgreementTribute:
+		if order.Amount <= 0 {
+			return fmt.Errorf("tribute must be a positive amount of production")
+		}
+	case models.AgreementVassalage:
+	default:
+		return fmt.Errorf("unknown agreement %q", order.Item)
+	}
+
+	state, err := e.diplomacyState(ctx, game, order.PlayerID, target)
+	if err != nil {
+		return err
+	}
+	if state.Aggrieved(game.CurrentYear) {
+		return fmt.Errorf("no agreement with %s is possible until year %d", target, state.GrievanceUntil)
+	}
+	if state.Agreement != nil {
+		return fmt.Errorf("an agreement with %s is already in force", target)
+	}
+
+	state.Demand = &models.Agreement{
+		Type:   order.Item,
+		From:   target,
+		To:     order.PlayerID,
+		Amount: order.Amount,
+		Year:   game.CurrentYear,
+	}
+	if order.Item == models.AgreementVassalage {
+		state.Demand.Amount = 0
+	}
+	return e.saveDiplomacyState(ctx, state)
+}
+
+// executeAcceptOrder gives in to the demand another player made of the
+// ordering player, putting the agreement in force
+func (e *GameEngine) executeAcceptOrder(ctx context.Context, game *models.Game, order *models.Order) error {
+	state, err := e.diplomacyState(ctx, game, order.PlayerID, order.TargetPlayer)
+	if err != nil {
+		return err
+	}
+	if state.Demand == nil || state.Demand.From != order.PlayerID {
+		return fmt.Errorf("player %s has made no demand to accept", order.TargetPlayer)
+	}
+
+	state.Agreement = state.Demand
+	state.Agreement.Year = game.CurrentYear
+	state.Demand = nil
+	if err := e.saveDiplomacyState(ctx, state); err != nil {
+		return err
+	}
+	e.recordAgreementEvent(ctx, game, models.EventAgreementMade, order.PlayerID, state.Agreement)
+	log.Printf("Game %s: %s accepted %s to %s", game.GameID, order.PlayerID, state.Agreement.Type, order.TargetPlayer)
+	return nil
+}
+
+// executeBreakOrder breaks the agreement between the ordering player and
+// another. Either side may break it, at the cost of the pair's goodwill.
+func (e *GameEngine) executeBreakOrder(ctx context.Context, game *models.Game, order *models.Order) error {
+	state, err := e.diplomacyState(ctx, game, order.PlayerID, order.TargetPlayer)
+	if err != nil {
+		return err
+	}
+	if state.Agreement == nil {
+		return fmt.Errorf("no agreement with %s to break", order.TargetPlayer)
+	}
+	return e.breakAgreement(ctx, game, state, order.PlayerID)
+}
+
+// breakAgreement ends a pair's agreement and applies the diplomatic
+// penalty: the pair turn hostile, unless teammates, and can make no new
+// agreement for grievanceYears
+func (e *GameEngine) breakAgreement(ctx context.Context, game *models.Game, state *models.DiplomacyState, breaker string) error {
+	agreement := state.Agreement
+	state.Agreement = nil
+	state.Demand = nil
+	state.BrokenBy = breaker
+	state.GrievanceUntil = game.CurrentYear + grievanceYears
+	if !game.Allied(state.PlayerA, state.PlayerB) {
+		state.Stance = models.StanceHostile
+	}
+	if err := e.saveDiplomacyState(ctx, state); err != nil {
+		return err
+	}
+	e.recordAgreementEvent(ctx, game, models.EventAgreementBroken, breaker, agreement)
+	log.Printf("Game %s: %s broke the %s between %s and %s", game.GameID, breaker, agreement.Type, agreement.From, agreement.To)
+	return nil
+}
+
+// recordAgreementEvent records an agreement being made or broken
+func (e *GameEngine) recordAgreementEvent(ctx context.Context, game *models.Game, eventType, playerID string, agreement *models.Agreement) {
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      eventType,
+		PlayerID:  playerID,
+		Detail:    fmt.Sprintf("%s from %s to %s", agreement.Type, agreement.From, agreement.To),
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording %s event in game %s: %v", eventType, game.GameID, err)
+	}
+}
+
+// processDiplomacy carries out the agreements in force each tick: payers
+// send tribute for each year the tick spans and vassals a share of their
+// production to the recipient's largest settlement. A payer who cannot meet its tribute breaks the
+// agreement. Unanswered demands lapse, and agreements with players who have
+// left the game end.
+func (e *GameEngine) processDiplomacy(ctx context.Context, game *models.Game) error {
+	states, err := e.repo.GetDiplomacyStates(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	byPlayer := make(map[string][]*models.Settlement)
+	for _, settlement := range settlements {
+		byPlayer[settlement.PlayerID] = append(byPlayer[settlement.PlayerID], settlement)
+	}
+	// Order each player's settlements so ties between them break the same way every year
+	for _, owned := range byPlayer {
+		sort.Slice(owned, func(i, j int) bool { return owned[i].SettlementID < owned[j].SettlementID })
+	}
+	active := game.ActivePlayers()
+
+	for _, state := range states {
+		changed := false
+		if state.Demand != nil && game.CurrentYear-state.Demand.Year >= demandExpiry {
+			state.Demand = nil
+			changed = true
+		}
+
+		if agreement := state.Agreement; agreement != nil {
+			if !containsString(active, agreement.From) || !containsString(active, agreement.To) {
+				state.Agreement = nil
+				changed = true
+			} else {
+				payer := byPlayer[agreement.From]
+				banked := 0
+				for _, settlement := range payer {
+					banked += settlement.Production
+				}
+				amount := agreement.Amount * game.YearsPerTick()
+				if agreement.Type == models.AgreementVassalage {
+					amount = int(float64(banked) * vassalShare)
+				}
+				if amount > banked {
+					if err := e.breakAgreement(ctx, game, state, agreement.From); err != nil {
+						log.Printf("Error breaking defaulted %s in game %s: %v", agreement.Type, game.GameID, err)
+					}
+					continue
+				}
+				if err := e.payTribute(ctx, payer, byPlayer[agreement.To], amount); err != nil {
+					log.Printf("Error paying %s in game %s: %v", agreement.Type, game.GameID, err)
+				}
+			}
+		}
+
+		if changed {
+			if err := e.saveDiplomacyState(ctx, state); err != nil {
+				log.Printf("Error updating diplomacy between %s and %s in game %s: %v", state.PlayerA, state.PlayerB, game.GameID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// payTribute moves an amount of banked production from the payer's
+// settlements, richest first, into the recipient's most populous settlement
+func (e *GameEngine) payTribute(ctx context.Context, payer, recipient []*models.Settlement, amount int) error {
+	if amount <= 0 || len(recipient) == 0 {
+		return nil
+	}
+	capital := recipient[0]
+	for _, settlement := range recipient[1:] {
+		if settlement.Population > capital.Population {
+			capital = settlement
+		}
+	}
+
+	sources := append([]*models.Settlement(nil), payer...)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Production > sources[j].Production })
+	remaining := amount
+	for _, settlement := range sources {
+		if remaining == 0 {
+			break
+		}
+		paid := min(settlement.Production, remaining)
+		if paid == 0 {
+			continue
+		}
+		settlement.Production -= paid
+		remaining -= paid
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			return err
+		}
+	}
+	capital.Production += amount - remaining
+	return e.repo.UpdateSettlement(ctx, capital)
+}
+
+// overlords maps each vassal to its overlord, and each minor civ to its ally
+func (e *GameEngine) overlords(ctx context.Context, game *models.Game) (map[string]string, error) {
+	states, err := e.repo.GetDiplomacyStates(ctx, game.GameID)
+	if err != nil {
+		return nil, err
+	}
+	overlords := make(map[string]string)
+	for _, state := range states {
+		for _, playerID := range []string{state.PlayerA, state.PlayerB} {
+			if overlord := state.OverlordOf(playerID); overlord != "" {
+				overlords[playerID] = overlord
+			}
+		}
+	}
+	civs, err := e.repo.GetMinorCivs(ctx, game.GameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, civ := range civs {
+		if civ.Ally != "" && civ.ConqueredBy == "" {
+			overlords[civ.MinorCivID] = civ.Ally
+		}
+	}
+	return overlords, nil
+}
+
+// defends reports whether a player's units defend a settlement owner's
+// settlements: their own, their teammates', their vassals' and their allied
+// minor civs'
+func defends(game *models.Game, overlords map[string]string, defender, owner string) bool {
+	return game.Allied(defender, owner) || overlords[owner] == defender
+}
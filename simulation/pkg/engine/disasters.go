@@ -0,0 +1,242 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	floodChance     = 0.5 // Chance each river system bursts its banks after heavy rains
+	floodRise       = 20  // Meters above its river a bank may lie and still be flooded
+	earthquakeReach = 3   // Tiles from its epicenter the strongest earthquake reaches
+	minSeverity     = 0.2 // Severity of the mildest disaster; the worst is 1
+)
+
+// tectonicFeatures are the map features earthquakes strike near
+var tectonicFeatures = map[string]bool{
+	models.FeatureMountainRange: true,
+	models.FeatureRiftValley:    true,
+	models.FeatureVolcanicArc:   true,
+}
+
+// processDisasters lets geography strike back. Heavy rains warn that the
+// rivers will rise: the tick after, river systems may flood their banks and
+// low-lying neighbours. Plate boundaries may shake with an earthquake that
+// reaches further the more severe it is, more likely in ticks spanning
+// several years. Whether a disaster strikes depends
+// only on the game seed and year; its severity, and so the share of
+// improvements and buildings it wrecks, is drawn from the game's events
+// stream. Wrecked works stop counting until workers repair them.
+func (e *GameEngine) processDisasters(ctx context.Context, game *models.Game) error {
+	if heavyRain(game, game.CurrentYear) {
+		e.recordDisaster(ctx, game, models.EventHeavyRain, "Heavy rains swelled the rivers; they will flood next year")
+	}
+	strikes := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, fmt.Sprintf("disasters:%d", game.CurrentYear)))
+
+	var epicenters []models.Location
+	quakeChance := chanceOverYears(game.Ruleset().EarthquakeChance, game.YearsPerTick())
+	metadata, err := e.repo.GetMapMetadata(ctx, game.GameID)
+	if err == nil && metadata != nil {
+		for _, feature := range metadata.Features {
+			if tectonicFeatures[feature.Type] && strikes.Float64() < quakeChance {
+				epicenters = append(epicenters, models.Location{X: feature.X, Y: feature.Y})
+			}
+		}
+	}
+	flooding := heavyRain(game, game.PreviousTickYear())
+	if !flooding && len(epicenters) == 0 {
+		return nil
+	}
+
+	loaded, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return err
+	}
+	// Visit tiles in a fixed order so the draws replay identically
+	tiles := append([]*models.MapTile(nil), loaded...)
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].Y != tiles[j].Y {
+			return tiles[i].Y < tiles[j].Y
+		}
+		return tiles[i].X < tiles[j].X
+	})
+	tileAt := make(map[models.Location]*models.MapTile, len(tiles))
+	for _, tile := range tiles {
+		tileAt[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+
+	events := game.Seeds.Stream(models.SeedStreamEvents)
+	var changed []*models.MapTile
+	if flooding {
+		systems := riverSystems(tiles)
+		ids := make([]int, 0, len(systems))
+		for id := range systems {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			if strikes.Float64() >= floodChance {
+				continue
+			}
+			severity := minSeverity + (1-minSeverity)*events.Float64()
+			flooded := floodedTiles(systems[id], tileAt)
+			wrecked := e.wreckImprovements(ctx, game, flooded, severity, events)
+			changed = append(changed, wrecked...)
+			e.recordDisaster(ctx, game, models.EventFlood, fmt.Sprintf("A flood of severity %.2f along river %d wrecked improvements on %d tiles",
+				severity, id, len(wrecked)))
+		}
+	}
+
+	for _, epicenter := range epicenters {
+		severity := minSeverity + (1-minSeverity)*events.Float64()
+		reach := max(1, int(math.Round(severity*earthquakeReach)))
+		var shaken []*models.MapTile
+		inReach := make(map[models.Location]bool)
+		for _, loc := range grid.Unbounded.Disk(epicenter, reach) {
+			inReach[loc] = true
+			if tile := tileAt[loc]; tile != nil {
+				shaken = append(shaken, tile)
+			}
+		}
+		wrecked := e.wreckImprovements(ctx, game, shaken, severity, events)
+		changed = append(changed, wrecked...)
+		buildings := 0
+		for _, settlement := range settlements {
+			if inReach[settlement.Location] {
+				buildings += e.wreckBuildings(ctx, settlement, severity, events)
+			}
+		}
+		e.recordDisaster(ctx, game, models.EventEarthquake, fmt.Sprintf("An earthquake of severity %.2f at (%d, %d) wrecked improvements on %d tiles and %d buildings",
+			severity, epicenter.X, epicenter.Y, len(wrecked), buildings))
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	e.invalidatePaths(game.GameID)
+	return e.refreshSettlementYields(ctx, game, changed)
+}
+
+// heavyRain reports whether heavy rains fall in a game year
+func heavyRain(game *models.Game, year int) bool {
+	return rng.NewStream(rng.DeriveSeed(game.Seeds.Master, fmt.Sprintf("rain:%d", year))).Float64() < game.Ruleset().HeavyRainChance
+}
+
+// riverSystems groups land river tiles by river system, each in row order
+func riverSystems(tiles []*models.MapTile) map[int][]*models.MapTile {
+	systems := make(map[int][]*models.MapTile)
+	for _, tile := range tiles {
+		if tile.HasRiver && tile.RiverID != 0 && !terrain.IsWater(tile.TerrainType) {
+			systems[tile.RiverID] = append(systems[tile.RiverID], tile)
+		}
+	}
+	return systems
+}
+
+// floodedTiles returns the tiles a river system floods: the river's own
+// tiles and the land beside them lying no more than floodRise above it, in
+// row order
+func floodedTiles(river []*models.MapTile, tileAt map[models.Location]*models.MapTile) []*models.MapTile {
+	flooded := make(map[*models.MapTile]bool)
+	for _, tile := range river {
+		flooded[tile] = true
+		for _, loc := range grid.Unbounded.Neighbors8(models.Location{X: tile.X, Y: tile.Y}) {
+			if bank := tileAt[loc]; bank != nil && !terrain.IsWater(bank.TerrainType) && bank.Elevation <= tile.Elevation+floodRise {
+				flooded[bank] = true
+			}
+		}
+	}
+	tiles := make([]*models.MapTile, 0, len(flooded))
+	for tile := range flooded {
+		tiles = append(tiles, tile)
+	}
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].Y != tiles[j].Y {
+			return tiles[i].Y < tiles[j].Y
+		}
+		return tiles[i].X < tiles[j].X
+	})
+	return tiles
+}
+
+// wreckImprovements wrecks each improvement on the struck tiles with a
+// chance of the disaster's severity, returning the tiles that lost any
+func (e *GameEngine) wreckImprovements(ctx context.Context, game *models.Game, struck []*models.MapTile, severity float64, events *rng.Stream) []*models.MapTile {
+	var wrecked []*models.MapTile
+	for _, tile := range struck {
+		kept, lost := []string{}, []string(nil)
+		for _, improvement := range tile.Improvements {
+			if events.Float64() < severity {
+				lost = append(lost, improvement)
+			} else {
+				kept = append(kept, improvement)
+			}
+		}
+		if len(lost) == 0 {
+			continue
+		}
+		tile.Improvements = kept
+		tile.Damaged = append(tile.Damaged, lost...)
+		tile.LastModifiedTick = game.CurrentYear
+		if err := e.repo.UpdateTileImprovements(ctx, tile); err != nil {
+			log.Printf("Error wrecking improvements at (%d, %d) in game %s: %v", tile.X, tile.Y, game.GameID, err)
+			continue
+		}
+		wrecked = append(wrecked, tile)
+	}
+	return wrecked
+}
+
+// wreckBuildings wrecks each of a settlement's buildings with a chance of
+// the disaster's severity, returning how many it lost
+func (e *GameEngine) wreckBuildings(ctx context.Context, settlement *models.Settlement, severity float64, events *rng.Stream) int {
+	var kept, lost []string
+	for _, building := range settlement.Buildings {
+		if events.Float64() < severity {
+			lost = append(lost, building)
+		} else {
+			kept = append(kept, building)
+		}
+	}
+	if len(lost) == 0 {
+		return 0
+	}
+	settlement.Buildings = kept
+	settlement.Damaged = append(settlement.Damaged, lost...)
+	settlement.LastUpdated = time.Now()
+	if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+		log.Printf("Error wrecking buildings of settlement %s: %v", settlement.SettlementID, err)
+		return 0
+	}
+	return len(lost)
+}
+
+// recordDisaster records a disaster event for every player to review
+func (e *GameEngine) recordDisaster(ctx context.Context, game *models.Game, eventType, detail string) {
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      eventType,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording %s event in game %s: %v", eventType, game.GameID, err)
+	}
+	log.Printf("Game %s: %s", game.GameID, detail)
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// processElimination eliminates every player who has founded a civ but has
+// since lost all of their settlements and units
+func (e *GameEngine) processElimination(ctx context.Context, game *models.Game) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+
+	hasAssets := make(map[string]bool)
+	for _, settlement := range settlements {
+		hasAssets[settlement.PlayerID] = true
+	}
+	for _, unit := range units {
+		hasAssets[unit.PlayerID] = true
+	}
+
+	for _, playerID := range game.ActivePlayers() {
+		if hasAssets[playerID] {
+			continue
+		}
+		// Players still waiting for a late-join civ have nothing to lose yet
+		position, err := e.repo.GetStartingPosition(ctx, game.GameID, playerID)
+		if err != nil || position == nil {
+			continue
+		}
+		if err := e.eliminatePlayer(ctx, game, playerID, models.EventPlayerEliminated); err != nil {
+			log.Printf("Error eliminating player %s in game %s: %v", playerID, game.GameID, err)
+		}
+	}
+	return nil
+}
+
+// eliminatePlayer takes a player out of the game: their territory and
+// visibility are released, units disbanded and settlements left to the
+// neutral player. The elimination is recorded as an event of the given type.
+func (e *GameEngine) eliminatePlayer(ctx context.Context, game *models.Game, playerID string, eventType string) error {
+	if game.IsEliminated(playerID) {
+		return fmt.Errorf("player %s is already eliminated", playerID)
+	}
+	if err := e.repo.EliminatePlayer(ctx, game.GameID, playerID, game.CurrentYear); err != nil {
+		return err
+	}
+	e.invalidatePaths(game.GameID)
+	game.EliminatedPlayers = append(game.EliminatedPlayers, playerID)
+
+	e.governorMu.Lock()
+	delete(e.governed[game.GameID], playerID)
+	e.governorMu.Unlock()
+
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      eventType,
+		PlayerID:  playerID,
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording %s event for player %s: %v", eventType, playerID, err)
+	}
+
+	log.Printf("Game %s: player %s is out (%s) in year %d", game.GameID, playerID, eventType, game.CurrentYear)
+	return nil
+}
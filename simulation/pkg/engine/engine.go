@@ -4,13 +4,18 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/anicolao/simciv/simulation/pkg/mapgen"
 	"github.com/anicolao/simciv/simulation/pkg/models"
 	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
 )
 
 // GameEngine processes game ticks for all active games
@@ -18,6 +23,74 @@ type GameEngine struct {
 	repo         repository.GameRepository
 	e2eTestMode  bool
 	manualTickCh chan string // Channel for manual tick requests (gameID)
+
+	// settlementSims holds each settlement's human simulation between ticks
+	settlementSims map[string]*simulator.Simulation
+
+	// resourceAccess holds each player's strategic resource access (gameID -> playerID)
+	resourceAccess map[string]map[string]ResourceAccess
+	accessMu       sync.RWMutex
+
+	// invariantInterval is how many years pass between invariant checks (0 disables them)
+	invariantInterval int
+	// unitSightings holds unit positions seen at the last invariant check (gameID -> unitID)
+	unitSightings map[string]map[string]unitSighting
+
+	// governed holds the absent players the governor is running (gameID -> playerID)
+	governed   map[string]map[string]bool
+	governorMu sync.RWMutex
+
+	// spawnedPlayers is how many of a persistent game's players have a civ (gameID -> count)
+	spawnedPlayers map[string]int
+
+	// catchUpMode decides how downtime is recovered; catchUp holds each
+	// game's missed ticks still to replay (gameID -> ticks)
+	catchUpMode string
+	catchUp     map[string]int
+
+	// storeFailures counts consecutive passes that could not reach the store;
+	// while non-zero the engine is degraded and waits until nextStoreAttempt
+	storeFailures    int
+	nextStoreAttempt time.Time
+
+	// tickCosts measures each game's ticks (gameID -> cost); with
+	// adaptiveFidelity, overloaded games are simulated in aggregate
+	tickCosts        map[string]*tickCost
+	adaptiveFidelity bool
+
+	// tickTimeout bounds each game's tick; an overrunning tick skips its
+	// remaining phases so the remaining games still get theirs
+	tickTimeout time.Duration
+
+	// capacity is the load measured at the end of the last pass, read by
+	// the query server for autoscaling
+	capacity   Capacity
+	capacityMu sync.RWMutex
+
+	// pathGraphs holds each game's cluster graph and cached routes (gameID ->
+	// graph), dropped when the game's terrain or tile ownership changes
+	pathGraphs map[string]*pathGraph
+	pathsMu    sync.Mutex
+
+	// trips holds the unit moves of each game's tick in progress (gameID ->
+	// trips), drained by road planning into settlement traffic
+	trips   map[string][]trip
+	tripsMu sync.Mutex
+
+	// attention holds when players last looked at each settlement
+	// (settlementID -> time); under attention fidelity those seen recently
+	// simulate in full and the rest as cohorts
+	attention   map[string]time.Time
+	attentionMu sync.Mutex
+
+	// tickObserver, when set, is called after every game tick with its duration
+	tickObserver func(gameID string, elapsed time.Duration, err error)
+}
+
+// SetTickObserver registers a callback invoked after each game tick.
+// It is used by tooling such as the load tester to measure tick latency.
+func (e *GameEngine) SetTickObserver(observer func(gameID string, elapsed time.Duration, err error)) {
+	e.tickObserver = observer
 }
 
 // NewGameEngine creates a new game engine
@@ -31,6 +104,27 @@ func NewGameEngine(repo repository.GameRepository) *GameEngine {
 		repo:         repo,
 		e2eTestMode:  e2eTestMode,
 		manualTickCh: make(chan string, 10),
+
+		settlementSims: make(map[string]*simulator.Simulation),
+		resourceAccess: make(map[string]map[string]ResourceAccess),
+
+		invariantInterval: invariantIntervalFromEnv(),
+		unitSightings:     make(map[string]map[string]unitSighting),
+
+		governed:       make(map[string]map[string]bool),
+		spawnedPlayers: make(map[string]int),
+
+		catchUpMode: catchUpModeFromEnv(),
+		catchUp:     make(map[string]int),
+
+		tickCosts:        make(map[string]*tickCost),
+		adaptiveFidelity: adaptiveFidelityFromEnv(),
+
+		tickTimeout: tickTimeoutFromEnv(),
+
+		pathGraphs: make(map[string]*pathGraph),
+		trips:      make(map[string][]trip),
+		attention:  make(map[string]time.Time),
 	}
 }
 
@@ -50,7 +144,7 @@ func (e *GameEngine) TriggerManualTick(gameID string) error {
 
 // Run starts the game engine loop
 func (e *GameEngine) Run(ctx context.Context) error {
-	ticker := time.NewTicker(100 * time.Millisecond) // Check every 100ms
+	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
 	log.Println("Game engine running...")
@@ -66,11 +160,10 @@ func (e *GameEngine) Run(ctx context.Context) error {
 				log.Printf("Error processing manual tick for game %s: %v", gameID, err)
 			}
 		case <-ticker.C:
-			// Only process automatic ticks if not in E2E test mode
-			if !e.e2eTestMode {
-				if err := e.processTick(ctx); err != nil {
-					log.Printf("Error processing tick: %v", err)
-				}
+			// Only process automatic ticks if not in E2E test mode, and
+			// back off while the store is unreachable
+			if !e.e2eTestMode && e.storeReady(ctx) {
+				e.recordPassResult(e.processTick(ctx))
 			}
 		}
 	}
@@ -79,30 +172,29 @@ func (e *GameEngine) Run(ctx context.Context) error {
 // processManualTick processes a manual tick for a specific game (E2E test mode)
 func (e *GameEngine) processManualTick(ctx context.Context, gameID string) error {
 	game, err := e.repo.GetGame(ctx, gameID)
-	if err != nil {
-		return err
-	}
-	
-	if game == nil {
+	if errors.Is(err, repository.ErrNotFound) {
 		log.Printf("Game %s not found for manual tick", gameID)
 		return nil
 	}
+	if err != nil {
+		return err
+	}
 	
-	if !game.IsStarted() {
+	if !game.IsRunning() {
 		log.Printf("Game %s is not started, cannot tick", gameID)
 		return nil
 	}
 	
 	// Check if map needs to be generated (new game just started)
-	if game.CurrentYear == -5000 && game.LastTickAt == nil {
-		if err := e.generateMapForGame(ctx, game); err != nil {
+	if needsMap(game) {
+		if err := withTimeout(ctx, mapGenerationTimeout, func(ctx context.Context) error { return e.startGame(ctx, game) }); err != nil {
 			log.Printf("Error generating map for game %s: %v", game.GameID, err)
 			return err
 		}
 	}
 	
 	// Force tick regardless of timing
-	if err := e.processGameTick(ctx, game); err != nil {
+	if err := withTimeout(ctx, e.tickTimeout, func(ctx context.Context) error { return e.processGameTick(ctx, game) }); err != nil {
 		log.Printf("Error processing manual tick for game %s: %v", game.GameID, err)
 		return err
 	}
@@ -117,79 +209,350 @@ func (e *GameEngine) processTick(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	e.forgetStoppedGames(games)
 
+	var ready []*models.Game
 	for _, game := range games {
 		// Check if map needs to be generated (new game just started)
-		if game.CurrentYear == -5000 && game.LastTickAt == nil {
+		if needsMap(game) {
 			// Generate map for new game
-			if err := e.generateMapForGame(ctx, game); err != nil {
+			if err := withTimeout(ctx, mapGenerationTimeout, func(ctx context.Context) error { return e.startGame(ctx, game) }); err != nil {
 				log.Printf("Error generating map for game %s: %v", game.GameID, err)
+				if abortsPass(ctx, err) {
+					return err
+				}
 				continue
 			}
 		}
 
-		if game.ShouldTick() {
-			if err := e.processGameTick(ctx, game); err != nil {
-				log.Printf("Error processing game %s tick: %v", game.GameID, err)
+		// Replay ticks missed while the engine was down, a batch per pass
+		if e.catchUp[game.GameID] > 0 {
+			if err := e.processCatchUp(ctx, game); err != nil {
+				if abortsPass(ctx, err) {
+					return err
+				}
+				e.handleTickError(game, err)
+			}
+			continue
+		}
+		ready = append(ready, game)
+	}
+
+	// Tick the games that are due, deferring any that would overrun this
+	// pass to the next
+	passStart := time.Now()
+	deferred := make(map[string]bool)
+	for i, game := range scheduleTicks(ready) {
+		if !e.withinBudget(game, passStart, i == 0) {
+			deferred[game.GameID] = true
+			continue
+		}
+		if err := e.detectDowntime(ctx, game); err != nil {
+			log.Printf("Error recording downtime for game %s: %v", game.GameID, err)
+		}
+
+		e.applyFidelity(game)
+		tickStart := time.Now()
+		err := withTimeout(ctx, e.tickTimeout, func(ctx context.Context) error { return e.processGameTick(ctx, game) })
+		elapsed := time.Since(tickStart)
+		e.recordTickCost(game, elapsed)
+		if e.tickObserver != nil {
+			e.tickObserver(game.GameID, elapsed, err)
+		}
+		if err != nil {
+			// Give up on the pass rather than fail every other game's tick too
+			if abortsPass(ctx, err) {
+				return err
 			}
+			e.handleTickError(game, err)
 		}
 	}
 
+	e.recordCapacity(games, deferred)
 	return nil
 }
 
-// processGameTick processes a single game tick
+// tickPhase is one subsystem's step of a game tick
+type tickPhase struct {
+	name string
+	run  func(e *GameEngine, ctx context.Context, game *models.Game) error
+}
+
+// tickPhases run in order every tick. A failing phase is logged and the tick
+// goes on, but a cancelled or expired tick stops before its next phase.
+//
+// Every phase keeps to an ordering contract so a tick replays identically
+// from the same state and seeds, whatever order the store returns documents
+// in:
+//   - Each phase sees everything earlier phases changed during the tick and
+//     nothing later phases will; a phase that depends on another must come
+//     after it in this list.
+//   - Within a phase, entities that affect one another or draw from a shared
+//     stream are visited in a fixed order: settlements by settlement ID,
+//     units by unit ID, orders as the store queues them, players in the
+//     game's player list order and tiles by row, then column.
+//   - Randomness comes from the game's seeded streams, never from map
+//     iteration or the clock.
+var tickPhases = []tickPhase{
+	// Spawn civs for players who joined a persistent game mid-way
+	{"late joins", (*GameEngine).processLateJoins},
+
+	// Free the regions of players who never showed up
+	{"no-shows", (*GameEngine).processNoShows},
+
+	// Hand absent players' decisions to the governor until they reconnect
+	{"governor", (*GameEngine).processGovernor},
+
+	// Process settlers units (settle orders, 3-step walk and auto-settle)
+	{"settlers units", (*GameEngine).processSettlersUnits},
+
+	// Units sent somewhere by a move order travel as far as their movement points carry them
+	{"unit movement", (*GameEngine).processUnitMovement},
+
+	// Automated workers build improvements by their mode's priority rules
+	{"workers", (*GameEngine).processWorkers},
+
+	// Workers carry on with long terrain transformations
+	{"projects", (*GameEngine).processProjects},
+
+	// Heal damaged units and man settlement garrisons
+	{"unit maintenance", (*GameEngine).processUnitMaintenance},
+
+	// Exploit improved resource tiles and regrow renewables
+	{"resource depletion", (*GameEngine).processResourceDepletion},
+
+	// Exhaust over-farmed soil and let fallow soil recover
+	{"soil", (*GameEngine).processSoil},
+
+	// Pollute around mines and dense settlements; let the land recover
+	{"pollution", (*GameEngine).processPollution},
+
+	// Every few decades let forests reclaim the grassland around them
+	{"environment", (*GameEngine).processEnvironment},
+
+	// Rivers flood the year after heavy rains; earthquakes shake plate boundaries
+	{"disasters", (*GameEngine).processDisasters},
+
+	// Settlements trade along shared rivers and between harbors by sea
+	{"trade", (*GameEngine).processTrade},
+
+	// Pay tribute and vassal dues; let unanswered demands lapse
+	{"diplomacy", (*GameEngine).processDiplomacy},
+
+	// Minor civs set quests, pick allies and reward their friends
+	{"minor civs", (*GameEngine).processMinorCivs},
+
+	// Crowded, unhappy settlements grow restless and may revolt; garrisons keep order
+	{"unrest", (*GameEngine).processUnrest},
+
+	// Advance settlement populations by the years the tick spans
+	{"settlement growth", (*GameEngine).processSettlementGrowth},
+
+	// Grow camps into villages, towns and cities
+	{"settlement progression", (*GameEngine).processSettlementProgression},
+
+	// Settlements' buildings and learning draw great people
+	{"great people", (*GameEngine).processGreatPeople},
+
+	// Turn the year's trips and river trade into traffic; busy routes get roads proposed or planned
+	{"road planning", (*GameEngine).processRoadPlanning},
+
+	// Settlements' people want roads, trade, security and learning; strong wants propose buildings
+	{"desires", (*GameEngine).processDesires},
+
+	// Reward met objectives, fail lapsed ones and set new ones
+	{"objectives", (*GameEngine).processObjectives},
+
+	// Merge or attach settlements of the same player that have grown adjacent
+	{"settlement merging", (*GameEngine).processSettlementMerging},
+
+	// Record what each player can see for their explored-tiles layer
+	{"exploration", (*GameEngine).processExploration},
+
+	// Refresh each player's cached minimap and territory borders every few years
+	{"minimaps", (*GameEngine).processMinimaps},
+
+	// Sample every player's standing every few years for the game's summary
+	{"history", (*GameEngine).processHistory},
+
+	// Eliminate players left with no settlements or units
+	{"elimination", (*GameEngine).processElimination},
+
+	// End the game once a single player or team remains
+	{"victory", (*GameEngine).processVictory},
+
+	// Periodically reconcile persisted populations with their simulations
+	{"population audit", (*GameEngine).processPopulationAudit},
+
+	// Recompute which strategic resources each player can build with
+	{"resource access", (*GameEngine).processResourceAccess},
+
+	// Safety net: validate cross-entity invariants at the configured frequency
+	{"invariant checks", (*GameEngine).processInvariantChecks},
+}
+
+// processGameTick processes a single game tick. The year is claimed before
+// anything is written, so an engine instance that loses the race to another
+// skips the tick with nothing to undo.
 func (e *GameEngine) processGameTick(ctx context.Context, game *models.Game) error {
-	// Process settlers units (3-step walk and auto-settle)
-	if err := e.processSettlersUnits(ctx, game); err != nil {
-		log.Printf("Error processing settlers units for game %s: %v", game.GameID, err)
-		// Continue with tick processing even if settlers processing fails
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("tick of game %s stopped before starting: %w", game.GameID, err)
+	}
+	if err := e.repo.ClaimGameTick(ctx, game.GameID, game.CurrentYear, time.Now(), tickClaimLease); err != nil {
+		if errors.Is(err, repository.ErrConcurrentTick) {
+			log.Printf("Game %s year %d is being ticked by another engine instance", game.GameID, game.CurrentYear)
+		}
+		return err
+	}
+
+	// Games created before the seed registry existed get one lazily
+	if game.Seeds == nil {
+		seeds, err := newGameSeeds()
+		if err != nil {
+			return err
+		}
+		game.Seeds = seeds
+		if err := e.repo.UpdateGameSeeds(ctx, game.GameID, game.Seeds); err != nil {
+			return err
+		}
+	}
+	seedPositions := game.Seeds.Positions()
+
+	// Games created before rules versioning are pinned to the current ruleset
+	if game.Rules == nil {
+		if err := e.pinRules(ctx, game); err != nil {
+			return err
+		}
+	}
+
+	// A tick that runs past its deadline skips its remaining phases but still
+	// closes the year: the phases that ran have written their changes, and
+	// running them again for the same year would apply them twice
+	var stopped error
+	for _, phase := range tickPhases {
+		if err := ctx.Err(); err != nil {
+			stopped = fmt.Errorf("tick of game %s stopped before %s, closing year %d without the rest: %w", game.GameID, phase.name, game.CurrentYear, err)
+			break
+		}
+		if err := phase.run(e, ctx, game); err != nil {
+			log.Printf("Error processing %s for game %s: %v", phase.name, game.GameID, err)
+		}
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	// Persist RNG stream positions if any subsystem drew from its stream
+	if game.Seeds.Positions() != seedPositions {
+		if err := e.repo.UpdateGameSeeds(ctx, game.GameID, game.Seeds); err != nil {
+			return err
+		}
 	}
 
-	// Increment year (1 year per second)
-	newYear := game.CurrentYear + 1
+	// Advance the year, by more than one in the early eras of paced games
+	newYear := game.CurrentYear + game.YearsPerTick()
 
-	// Update game in database
-	if err := e.repo.UpdateGameTick(ctx, game.GameID, newYear, ctx); err != nil {
+	// Update game in database, releasing the claim
+	now := time.Now()
+	if err := e.repo.UpdateGameTick(ctx, game.GameID, game.CurrentYear, newYear, now); err != nil {
 		return err
 	}
+	calendar := models.YearCalendar(newYear)
+	game.CurrentYear = newYear
+	game.LastTickAt = &now
+	game.Calendar = &calendar
 
 	// Log significant milestones
-	if newYear%100 == 0 {
+	if calendar.NewCentury {
 		log.Printf("Game %s: Year %d", game.GameID, newYear)
 	}
+	if calendar.NewEra {
+		e.recordNewEra(ctx, game, calendar)
+	}
 
-	return nil
+	return stopped
+}
+
+// chanceOverYears returns the chance something with a yearly chance happens
+// at least once over the years a tick spans
+func chanceOverYears(yearly float64, years int) float64 {
+	if years <= 1 {
+		return yearly
+	}
+	return 1 - math.Pow(1-yearly, float64(years))
+}
+
+// createEvent records a game event dated with the calendar of its year
+func (e *GameEngine) createEvent(ctx context.Context, event *models.GameEvent) error {
+	if event.Calendar == nil {
+		calendar := models.YearCalendar(event.Year)
+		event.Calendar = &calendar
+	}
+	return e.repo.CreateEvent(ctx, event)
+}
+
+// recordNewEra records the turn of an era for every player to review,
+// carrying the calendar so clients can mark it
+func (e *GameEngine) recordNewEra(ctx context.Context, game *models.Game, calendar models.Calendar) {
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      calendar.Year,
+		Type:      models.EventNewEra,
+		Detail:    fmt.Sprintf("The %s era began in year %d", calendar.Era, calendar.Year),
+		Calendar:  &calendar,
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording the %s era in game %s: %v", calendar.Era, game.GameID, err)
+	}
 }
 
 // generateMapForGame generates the map when a game starts
 func (e *GameEngine) generateMapForGame(ctx context.Context, game *models.Game) error {
 	log.Printf("Generating map for game %s with %d players", game.GameID, game.MaxPlayers)
 
-	// Generate seed
-	var seed string
-	testSeed := os.Getenv("TEST_MAP_SEED")
-	if testSeed != "" {
-		// Use deterministic seed for testing
-		seed = testSeed
-		log.Printf("Using test seed: %s", seed)
-	} else {
-		// Generate random seed for production
-		seedBytes := make([]byte, 16)
-		if _, err := rand.Read(seedBytes); err != nil {
+	// A game created from a share code rebuilds the shared world
+	share := mapgen.ShareCode{Players: game.MaxPlayers, Options: mapgen.DefaultMapOptions()}
+	if game.ShareCode != "" {
+		parsed, err := mapgen.ParseShareCode(game.ShareCode)
+		if err != nil {
+			return fmt.Errorf("game %s has an invalid share code: %w", game.GameID, err)
+		}
+		share = parsed
+		if game.Seeds == nil {
+			game.Seeds = models.NewGameSeeds(share.Seed)
+		}
+	}
+
+	// Create the seed registry; the master seed doubles as the map seed
+	if game.Seeds == nil {
+		seeds, err := newGameSeeds()
+		if err != nil {
 			return err
 		}
-		seed = hex.EncodeToString(seedBytes)
+		game.Seeds = seeds
+	}
+	share.Seed = game.Seeds.Master
+	if err := e.repo.UpdateGameSeeds(ctx, game.GameID, game.Seeds); err != nil {
+		return err
+	}
+
+	// Pin the ruleset so later balance changes do not affect this game
+	if err := e.pinRules(ctx, game); err != nil {
+		return err
 	}
+	rules := game.Ruleset()
 
 	// Create generator
-	generator := mapgen.NewGenerator(seed, game.MaxPlayers)
+	generator := mapgen.NewGeneratorWithOptions(share.Seed, share.Players, share.Options)
 
 	// Generate map
-	metadata, tiles, positions, err := generator.GenerateMap(ctx, game.GameID, game.MaxPlayers)
+	metadata, tiles, positions, err := generator.GenerateMap(ctx, game.GameID, share.Players)
 	if err != nil {
 		return err
 	}
+	if metadata.ShareCode, err = share.Encode(); err != nil {
+		log.Printf("Game %s's world cannot be shared: %v", game.GameID, err)
+	}
 
 	log.Printf("Generated map: %dx%d with %d tiles, %d starting positions in %dms",
 		metadata.Width, metadata.Height, len(tiles), len(positions), metadata.GenerationTimeMs)
@@ -199,10 +562,16 @@ func (e *GameEngine) generateMapForGame(ctx context.Context, game *models.Game)
 		if i < len(game.PlayerList) {
 			position.PlayerID = game.PlayerList[i]
 			position.GameID = game.GameID
+			position.AssignedYear = game.CurrentYear
 			position.CreatedAt = time.Now()
 		}
 	}
 
+	// Every tile starts out modified as of the game's first year
+	for _, tile := range tiles {
+		tile.LastModifiedTick = game.CurrentYear
+	}
+
 	// Initialize tile visibility for all players
 	// Each player can see tiles around their starting position
 	for _, position := range positions {
@@ -210,7 +579,7 @@ func (e *GameEngine) generateMapForGame(ctx context.Context, game *models.Game)
 			continue
 		}
 		// Make tiles within vision range visible to this player
-		visionRange := 3 // tiles around starting position
+		visionRange := rules.StartingVisionRange
 		for _, tile := range tiles {
 			dx := tile.X - position.CenterX
 			dy := tile.Y - position.CenterY
@@ -229,11 +598,17 @@ func (e *GameEngine) generateMapForGame(ctx context.Context, game *models.Game)
 	if err := e.repo.SaveMapTiles(ctx, tiles); err != nil {
 		return err
 	}
+	e.invalidatePaths(game.GameID)
 
 	if err := e.repo.SaveStartingPositions(ctx, positions); err != nil {
 		return err
 	}
 
+	// Found the minor civilizations in the regions left to them
+	if err := e.createMinorCivs(ctx, game, metadata.MinorCivSites); err != nil {
+		return err
+	}
+
 	// Initialize settlers units for each player
 	for _, position := range positions {
 		if position.PlayerID == "" {
@@ -251,7 +626,7 @@ func (e *GameEngine) generateMapForGame(ctx context.Context, game *models.Game)
 				Y: position.StartingCityY,
 			},
 			StepsTaken:     0,
-			PopulationCost: 100,
+			PopulationCost: rules.SettlersPopulationCost,
 			CreatedAt:      time.Now(),
 			LastUpdated:    time.Now(),
 		}
@@ -267,6 +642,33 @@ func (e *GameEngine) generateMapForGame(ctx context.Context, game *models.Game)
 	return nil
 }
 
+// pinRules snapshots the current ruleset onto a game that has none
+func (e *GameEngine) pinRules(ctx context.Context, game *models.Game) error {
+	if game.Rules != nil {
+		return nil
+	}
+	game.Rules = models.DefaultRuleset()
+	log.Printf("Game %s pinned to rules version %d", game.GameID, game.Rules.Version)
+	return e.repo.UpdateGameRules(ctx, game.GameID, game.Rules)
+}
+
+// newGameSeeds creates a seed registry from TEST_MAP_SEED or a random master seed
+func newGameSeeds() (*models.GameSeeds, error) {
+	testSeed := os.Getenv("TEST_MAP_SEED")
+	if testSeed != "" {
+		// Use deterministic seed for testing
+		log.Printf("Using test seed: %s", testSeed)
+		return models.NewGameSeeds(testSeed), nil
+	}
+
+	// Generate random seed for production
+	seedBytes := make([]byte, 16)
+	if _, err := rand.Read(seedBytes); err != nil {
+		return nil, err
+	}
+	return models.NewGameSeeds(hex.EncodeToString(seedBytes)), nil
+}
+
 // generateUUID generates a simple UUID for units and settlements
 func generateUUID() string {
 	b := make([]byte, 16)
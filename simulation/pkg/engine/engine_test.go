@@ -2,10 +2,26 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+	"github.com/anicolao/simciv/simulation/pkg/yields"
 )
 
 // MockRepository implements GameRepository for testing
@@ -13,9 +29,27 @@ type MockRepository struct {
 	games             map[string]*models.Game
 	updateCalls       int
 	getStartedCalls   int
+	getMapTileCalls   int
 	mapMetadata       map[string]*models.MapMetadata
 	mapTiles          map[string][]*models.MapTile
 	startingPositions map[string][]*models.StartingPosition
+	units             []*models.Unit
+	exploredTiles     []*models.ExploredTile
+	minimaps          map[string]*models.Minimap
+	borders           map[string]*models.Borders
+	playerActivity    []*models.PlayerActivity
+	playerPolicies    []*models.PlayerPolicy
+	playerSettings    []*models.PlayerSettings
+	events            []*models.GameEvent
+	settlements       []*models.Settlement
+	settlementSims    map[string]*models.SettlementSimulation
+	orders            []*models.Order
+	diplomacy         []*models.DiplomacyState
+	minorCivs         []*models.MinorCiv
+	objectives        []*models.Objective
+	gameResults       []*models.GameResult
+	gameHistory       []*models.GameHistoryPoint
+	gameSummary       *models.GameSummary
 }
 
 func NewMockRepository() *MockRepository {
@@ -24,6 +58,8 @@ func NewMockRepository() *MockRepository {
 		mapMetadata:       make(map[string]*models.MapMetadata),
 		mapTiles:          make(map[string][]*models.MapTile),
 		startingPositions: make(map[string][]*models.StartingPosition),
+		minimaps:          make(map[string]*models.Minimap),
+		borders:           make(map[string]*models.Borders),
 	}
 }
 
@@ -31,7 +67,7 @@ func (m *MockRepository) GetStartedGames(ctx context.Context) ([]*models.Game, e
 	m.getStartedCalls++
 	var games []*models.Game
 	for _, game := range m.games {
-		if game.IsStarted() {
+		if game.IsRunning() {
 			games = append(games, game)
 		}
 	}
@@ -39,16 +75,164 @@ func (m *MockRepository) GetStartedGames(ctx context.Context) ([]*models.Game, e
 }
 
 func (m *MockRepository) GetGame(ctx context.Context, gameID string) (*models.Game, error) {
-	return m.games[gameID], nil
+	if game, exists := m.games[gameID]; exists {
+		return game, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *MockRepository) CreateGame(ctx context.Context, game *models.Game) error {
+	if _, exists := m.games[game.GameID]; exists {
+		return repository.ErrConflict
+	}
+	m.games[game.GameID] = game
+	return nil
+}
+
+func (m *MockRepository) ClaimGameTick(ctx context.Context, gameID string, year int, claimedAt time.Time, lease time.Duration) error {
+	if game, exists := m.games[gameID]; exists {
+		if game.CurrentYear != year {
+			return repository.ErrConcurrentTick
+		}
+		if claim := game.TickClaim; claim != nil && claim.Year == year && !claim.ClaimedAt.Before(claimedAt.Add(-lease)) {
+			return repository.ErrConcurrentTick
+		}
+		game.TickClaim = &models.TickClaim{Year: year, ClaimedAt: claimedAt}
+	}
+	return nil
 }
 
-func (m *MockRepository) UpdateGameTick(ctx context.Context, gameID string, newYear int, tickTime context.Context) error {
+func (m *MockRepository) UpdateGameTick(ctx context.Context, gameID string, expectedYear, newYear int, tickTime time.Time) error {
 	m.updateCalls++
 	if game, exists := m.games[gameID]; exists {
+		if game.CurrentYear != expectedYear {
+			return repository.ErrConcurrentTick
+		}
+		calendar := models.YearCalendar(newYear)
 		game.CurrentYear = newYear
-		now := time.Now()
-		game.LastTickAt = &now
+		game.LastTickAt = &tickTime
+		game.Calendar = &calendar
+		game.TickClaim = nil
+	}
+	return nil
+}
+
+func (m *MockRepository) UpdateGameSeeds(ctx context.Context, gameID string, seeds *models.GameSeeds) error {
+	if game, exists := m.games[gameID]; exists {
+		game.Seeds = seeds
+	}
+	return nil
+}
+
+func (m *MockRepository) UpdateGameRules(ctx context.Context, gameID string, rules *models.Ruleset) error {
+	if game, exists := m.games[gameID]; exists {
+		game.Rules = rules
+	}
+	return nil
+}
+
+func (m *MockRepository) TransitionGameState(ctx context.Context, gameID string, from, to models.GameState) error {
+	game, exists := m.games[gameID]
+	if !exists {
+		return errors.New("game not found")
+	}
+	if game.State != from {
+		return repository.ErrStateConflict
+	}
+	game.State = to
+	return nil
+}
+
+func (m *MockRepository) EndGame(ctx context.Context, gameID string, from models.GameState, winners []string) error {
+	if game, exists := m.games[gameID]; exists {
+		if game.State != from {
+			return repository.ErrStateConflict
+		}
+		game.State = models.GameStateFinished
+		game.Winners = winners
+	}
+	return nil
+}
+
+func (m *MockRepository) RecordFireMastery(ctx context.Context, gameID string, playerID string, year int) error {
+	if game, exists := m.games[gameID]; exists {
+		if game.FireMastery == nil {
+			game.FireMastery = make(map[string]int)
+		}
+		if recorded, ok := game.FireMastery[playerID]; !ok || year < recorded {
+			game.FireMastery[playerID] = year
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) RecordClockAdjustment(ctx context.Context, gameID string, adjustment models.ClockAdjustment) error {
+	if game, exists := m.games[gameID]; exists {
+		game.ClockAdjustments = append(game.ClockAdjustments, adjustment)
+	}
+	return nil
+}
+
+func (m *MockRepository) GetPlayerActivity(ctx context.Context, gameID string) ([]*models.PlayerActivity, error) {
+	var activity []*models.PlayerActivity
+	for _, record := range m.playerActivity {
+		if record.GameID == gameID {
+			activity = append(activity, record)
+		}
+	}
+	return activity, nil
+}
+
+func (m *MockRepository) GetPlayerPolicies(ctx context.Context, gameID string) ([]*models.PlayerPolicy, error) {
+	var policies []*models.PlayerPolicy
+	for _, policy := range m.playerPolicies {
+		if policy.GameID == gameID {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+func (m *MockRepository) SavePlayerPolicy(ctx context.Context, policy *models.PlayerPolicy) error {
+	for i, existing := range m.playerPolicies {
+		if existing.GameID == policy.GameID && existing.PlayerID == policy.PlayerID {
+			m.playerPolicies[i] = policy
+			return nil
+		}
+	}
+	m.playerPolicies = append(m.playerPolicies, policy)
+	return nil
+}
+
+func (m *MockRepository) GetPlayerSettings(ctx context.Context, gameID string) ([]*models.PlayerSettings, error) {
+	var settings []*models.PlayerSettings
+	for _, saved := range m.playerSettings {
+		if saved.GameID == gameID {
+			settings = append(settings, saved)
+		}
+	}
+	return settings, nil
+}
+
+func (m *MockRepository) SavePlayerSettings(ctx context.Context, settings *models.PlayerSettings) error {
+	for i, existing := range m.playerSettings {
+		if existing.GameID == settings.GameID && existing.PlayerID == settings.PlayerID {
+			m.playerSettings[i] = settings
+			return nil
+		}
+	}
+	m.playerSettings = append(m.playerSettings, settings)
+	return nil
+}
+
+func (m *MockRepository) SavePlayerActivity(ctx context.Context, record *models.PlayerActivity) error {
+	for i, existing := range m.playerActivity {
+		if existing.GameID == record.GameID && existing.PlayerID == record.PlayerID {
+			m.playerActivity[i] = record
+			return nil
+		}
 	}
+	m.playerActivity = append(m.playerActivity, record)
 	return nil
 }
 
@@ -71,7 +255,7 @@ func (m *MockRepository) SaveStartingPositions(ctx context.Context, positions []
 		return nil
 	}
 	gameID := positions[0].GameID
-	m.startingPositions[gameID] = positions
+	m.startingPositions[gameID] = append(m.startingPositions[gameID], positions...)
 	return nil
 }
 
@@ -83,6 +267,53 @@ func (m *MockRepository) GetMapTiles(ctx context.Context, gameID string, playerI
 	return m.mapTiles[gameID], nil
 }
 
+func (m *MockRepository) GetSeenTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	var tiles []*models.MapTile
+	for _, tile := range m.mapTiles[gameID] {
+		if len(tile.VisibleTo) > 0 || tile.OwnerID != nil {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+func (m *MockRepository) GetExploredTiles(ctx context.Context, gameID string) ([]*models.ExploredTile, error) {
+	var tiles []*models.ExploredTile
+	for _, tile := range m.exploredTiles {
+		if tile.GameID == gameID {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+func (m *MockRepository) SaveExploredTiles(ctx context.Context, tiles []*models.ExploredTile) error {
+	for _, tile := range tiles {
+		replaced := false
+		for i, other := range m.exploredTiles {
+			if other.GameID == tile.GameID && other.PlayerID == tile.PlayerID && other.X == tile.X && other.Y == tile.Y {
+				m.exploredTiles[i] = tile
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			m.exploredTiles = append(m.exploredTiles, tile)
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) SaveMinimap(ctx context.Context, minimap *models.Minimap) error {
+	m.minimaps[minimap.PlayerID] = minimap
+	return nil
+}
+
+func (m *MockRepository) SaveBorders(ctx context.Context, borders *models.Borders) error {
+	m.borders[borders.PlayerID] = borders
+	return nil
+}
+
 func (m *MockRepository) GetStartingPosition(ctx context.Context, gameID string, playerID string) (*models.StartingPosition, error) {
 	positions := m.startingPositions[gameID]
 	for _, pos := range positions {
@@ -93,173 +324,489 @@ func (m *MockRepository) GetStartingPosition(ctx context.Context, gameID string,
 	return nil, nil
 }
 
-func (m *MockRepository) Close(ctx context.Context) error {
+func (m *MockRepository) CreateUnit(ctx context.Context, unit *models.Unit) error {
+	m.units = append(m.units, unit)
 	return nil
 }
 
-func TestGameEngine_ProcessTick(t *testing.T) {
-	repo := NewMockRepository()
-	engine := NewGameEngine(repo)
-
-	// Add a started game
-	now := time.Now().Add(-2 * time.Second) // 2 seconds ago
-	repo.games["game1"] = &models.Game{
-		GameID:      "game1",
-		State:       "started",
-		CurrentYear: -5000,
-		LastTickAt:  &now,
+func (m *MockRepository) GetUnits(ctx context.Context, gameID string) ([]*models.Unit, error) {
+	var units []*models.Unit
+	for _, unit := range m.units {
+		if unit.GameID == gameID {
+			units = append(units, unit)
+		}
 	}
+	return units, nil
+}
 
-	// Process tick
-	ctx := context.Background()
-	err := engine.processTick(ctx)
-	if err != nil {
-		t.Fatalf("processTick failed: %v", err)
+func (m *MockRepository) GetUnitsByPlayer(ctx context.Context, gameID string, playerID string) ([]*models.Unit, error) {
+	var units []*models.Unit
+	for _, unit := range m.units {
+		if unit.GameID == gameID && unit.PlayerID == playerID {
+			units = append(units, unit)
+		}
 	}
+	return units, nil
+}
 
-	// Verify game was updated
-	if repo.updateCalls != 1 {
-		t.Errorf("Expected 1 update call, got %d", repo.updateCalls)
-	}
+func (m *MockRepository) UpdateUnit(ctx context.Context, unit *models.Unit) error {
+	return nil
+}
 
-	game := repo.games["game1"]
-	if game.CurrentYear != -4999 {
-		t.Errorf("Expected year -4999, got %d", game.CurrentYear)
+func (m *MockRepository) DeleteUnit(ctx context.Context, unitID string) error {
+	for i, unit := range m.units {
+		if unit.UnitID == unitID {
+			m.units = append(m.units[:i], m.units[i+1:]...)
+			break
+		}
 	}
+	return nil
 }
 
-func TestGameEngine_SkipWaitingGames(t *testing.T) {
-	repo := NewMockRepository()
-	engine := NewGameEngine(repo)
+func (m *MockRepository) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	m.settlements = append(m.settlements, settlement)
+	return nil
+}
 
-	// Add a waiting game
-	repo.games["game1"] = &models.Game{
-		GameID:      "game1",
-		State:       "waiting",
-		CurrentYear: -5000,
+func (m *MockRepository) GetSettlements(ctx context.Context, gameID string) ([]*models.Settlement, error) {
+	var settlements []*models.Settlement
+	for _, settlement := range m.settlements {
+		if settlement.GameID == gameID {
+			settlements = append(settlements, settlement)
+		}
 	}
+	return settlements, nil
+}
 
-	// Process tick
-	ctx := context.Background()
-	err := engine.processTick(ctx)
-	if err != nil {
-		t.Fatalf("processTick failed: %v", err)
+func (m *MockRepository) GetSettlementsByPlayer(ctx context.Context, gameID string, playerID string) ([]*models.Settlement, error) {
+	var settlements []*models.Settlement
+	for _, settlement := range m.settlements {
+		if settlement.GameID == gameID && settlement.PlayerID == playerID {
+			settlements = append(settlements, settlement)
+		}
 	}
+	return settlements, nil
+}
 
-	// Verify no updates (waiting games shouldn't be processed)
-	if repo.updateCalls != 0 {
-		t.Errorf("Expected 0 update calls for waiting game, got %d", repo.updateCalls)
+func (m *MockRepository) UpdateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	return nil
+}
+
+func (m *MockRepository) GetSettlementSimulation(ctx context.Context, settlementID string) (*models.SettlementSimulation, error) {
+	if saved, ok := m.settlementSims[settlementID]; ok {
+		return saved, nil
 	}
+	return nil, repository.ErrNotFound
 }
 
-func TestGameEngine_MultipleGames(t *testing.T) {
-	repo := NewMockRepository()
-	engine := NewGameEngine(repo)
+func (m *MockRepository) SaveSettlementSimulation(ctx context.Context, saved *models.SettlementSimulation) error {
+	if m.settlementSims == nil {
+		m.settlementSims = make(map[string]*models.SettlementSimulation)
+	}
+	m.settlementSims[saved.SettlementID] = saved
+	return nil
+}
 
-	// Add multiple started games
-	now := time.Now().Add(-2 * time.Second)
-	repo.games["game1"] = &models.Game{
-		GameID:      "game1",
-		State:       "started",
-		CurrentYear: -5000,
-		LastTickAt:  &now,
+func (m *MockRepository) EliminatePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	if game, exists := m.games[gameID]; exists && !game.IsEliminated(playerID) {
+		game.EliminatedPlayers = append(game.EliminatedPlayers, playerID)
 	}
-	repo.games["game2"] = &models.Game{
-		GameID:      "game2",
-		State:       "started",
-		CurrentYear: -5000,
-		LastTickAt:  &now,
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.OwnerID != nil && *tile.OwnerID == playerID {
+			tile.OwnerID = nil
+			tile.SettlementID = ""
+			tile.LastModifiedTick = tick
+		}
+		var visibleTo []string
+		for _, id := range tile.VisibleTo {
+			if id != playerID {
+				visibleTo = append(visibleTo, id)
+			}
+		}
+		tile.VisibleTo = visibleTo
 	}
-	repo.games["game3"] = &models.Game{
-		GameID:      "game3",
-		State:       "waiting",
-		CurrentYear: -5000,
+	var units []*models.Unit
+	for _, unit := range m.units {
+		if unit.GameID != gameID || unit.PlayerID != playerID {
+			units = append(units, unit)
+		}
 	}
-
-	// Process tick
-	ctx := context.Background()
-	err := engine.processTick(ctx)
-	if err != nil {
-		t.Fatalf("processTick failed: %v", err)
+	m.units = units
+	for _, settlement := range m.settlements {
+		if settlement.GameID == gameID && settlement.PlayerID == playerID {
+			settlement.PlayerID = models.NeutralPlayerID
+		}
 	}
+	return nil
+}
 
-	// Verify both started games were updated
-	if repo.updateCalls != 2 {
-		t.Errorf("Expected 2 update calls, got %d", repo.updateCalls)
+func (m *MockRepository) ReleasePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	if game, exists := m.games[gameID]; exists {
+		var players []string
+		for _, id := range game.PlayerList {
+			if id != playerID {
+				players = append(players, id)
+			}
+		}
+		if len(players) < len(game.PlayerList) {
+			game.PlayerList = players
+			game.CurrentPlayers--
+		}
 	}
-
-	if repo.games["game1"].CurrentYear != -4999 {
-		t.Errorf("Game1: Expected year -4999, got %d", repo.games["game1"].CurrentYear)
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.OwnerID != nil && *tile.OwnerID == playerID {
+			tile.OwnerID = nil
+			tile.SettlementID = ""
+			tile.LastModifiedTick = tick
+		}
+		var visibleTo []string
+		for _, id := range tile.VisibleTo {
+			if id != playerID {
+				visibleTo = append(visibleTo, id)
+			}
+		}
+		tile.VisibleTo = visibleTo
 	}
-	if repo.games["game2"].CurrentYear != -4999 {
-		t.Errorf("Game2: Expected year -4999, got %d", repo.games["game2"].CurrentYear)
+	var units []*models.Unit
+	for _, unit := range m.units {
+		if unit.GameID != gameID || unit.PlayerID != playerID {
+			units = append(units, unit)
+		}
 	}
-	if repo.games["game3"].CurrentYear != -5000 {
-		t.Errorf("Game3: Expected year -5000 (unchanged), got %d", repo.games["game3"].CurrentYear)
+	m.units = units
+	var settlements []*models.Settlement
+	for _, settlement := range m.settlements {
+		if settlement.GameID != gameID || settlement.PlayerID != playerID {
+			settlements = append(settlements, settlement)
+		} else {
+			delete(m.settlementSims, settlement.SettlementID)
+		}
+	}
+	m.settlements = settlements
+	var positions []*models.StartingPosition
+	for _, position := range m.startingPositions[gameID] {
+		if position.PlayerID != playerID {
+			positions = append(positions, position)
+		}
 	}
+	m.startingPositions[gameID] = positions
+	delete(m.minimaps, playerID)
+	delete(m.borders, playerID)
+	return nil
 }
 
-func TestGameEngine_YearProgression(t *testing.T) {
-	// Add a game at different year ranges
-	tests := []struct {
-		name        string
-		startYear   int
-		expectedYear int
-	}{
-		{"Ancient", -5000, -4999},
-		{"Classical", -1000, -999},
-		{"Medieval", 500, 501},
-		{"Modern", 1900, 1901},
-		{"Future", 2500, 2501},
+func (m *MockRepository) GetDiplomacyStates(ctx context.Context, gameID string) ([]*models.DiplomacyState, error) {
+	var states []*models.DiplomacyState
+	for _, state := range m.diplomacy {
+		if state.GameID == gameID {
+			states = append(states, state)
+		}
 	}
+	return states, nil
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := NewMockRepository()
-			engine := NewGameEngine(repo)
-
-			now := time.Now().Add(-2 * time.Second)
-			gameID := "test-game"
-			repo.games[gameID] = &models.Game{
-				GameID:      gameID,
-				State:       "started",
-				CurrentYear: tt.startYear,
-				LastTickAt:  &now,
-			}
+func (m *MockRepository) SaveDiplomacyState(ctx context.Context, state *models.DiplomacyState) error {
+	for i, existing := range m.diplomacy {
+		if existing.GameID == state.GameID && existing.PlayerA == state.PlayerA && existing.PlayerB == state.PlayerB {
+			m.diplomacy[i] = state
+			return nil
+		}
+	}
+	m.diplomacy = append(m.diplomacy, state)
+	return nil
+}
 
-			ctx := context.Background()
-			err := engine.processTick(ctx)
-			if err != nil {
-				t.Fatalf("processTick failed: %v", err)
-			}
+func (m *MockRepository) GetMinorCivs(ctx context.Context, gameID string) ([]*models.MinorCiv, error) {
+	var civs []*models.MinorCiv
+	for _, civ := range m.minorCivs {
+		if civ.GameID == gameID {
+			civs = append(civs, civ)
+		}
+	}
+	return civs, nil
+}
 
-			if repo.games[gameID].CurrentYear != tt.expectedYear {
-				t.Errorf("Expected year %d, got %d", tt.expectedYear, repo.games[gameID].CurrentYear)
-			}
-		})
+func (m *MockRepository) SaveMinorCiv(ctx context.Context, civ *models.MinorCiv) error {
+	for i, existing := range m.minorCivs {
+		if existing.GameID == civ.GameID && existing.MinorCivID == civ.MinorCivID {
+			m.minorCivs[i] = civ
+			return nil
+		}
 	}
+	m.minorCivs = append(m.minorCivs, civ)
+	return nil
 }
 
-func TestGameEngine_TickTiming(t *testing.T) {
+func (m *MockRepository) GetObjectives(ctx context.Context, gameID string) ([]*models.Objective, error) {
+	var objectives []*models.Objective
+	for _, objective := range m.objectives {
+		if objective.GameID == gameID {
+			objectives = append(objectives, objective)
+		}
+	}
+	return objectives, nil
+}
+
+func (m *MockRepository) SaveObjective(ctx context.Context, objective *models.Objective) error {
+	for i, existing := range m.objectives {
+		if existing.GameID == objective.GameID && existing.ObjectiveID == objective.ObjectiveID {
+			m.objectives[i] = objective
+			return nil
+		}
+	}
+	m.objectives = append(m.objectives, objective)
+	return nil
+}
+
+func (m *MockRepository) RecordGameResult(ctx context.Context, result *models.GameResult) error {
+	m.gameResults = append(m.gameResults, result)
+	return nil
+}
+
+func (m *MockRepository) CaptureSettlement(ctx context.Context, settlement *models.Settlement, playerID string, tick int) error {
+	settlement.PlayerID = playerID
+	for _, tile := range m.mapTiles[settlement.GameID] {
+		if tile.SettlementID == settlement.SettlementID {
+			owner := playerID
+			tile.OwnerID = &owner
+			tile.LastModifiedTick = tick
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) CreateEvent(ctx context.Context, event *models.GameEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *MockRepository) GetEvents(ctx context.Context, gameID string) ([]*models.GameEvent, error) {
+	var events []*models.GameEvent
+	for _, event := range m.events {
+		if event.GameID == gameID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *MockRepository) GetGameHistory(ctx context.Context, gameID string) ([]*models.GameHistoryPoint, error) {
+	var history []*models.GameHistoryPoint
+	for _, point := range m.gameHistory {
+		if point.GameID == gameID {
+			history = append(history, point)
+		}
+	}
+	return history, nil
+}
+
+func (m *MockRepository) SaveGameHistory(ctx context.Context, point *models.GameHistoryPoint) error {
+	for i, existing := range m.gameHistory {
+		if existing.GameID == point.GameID && existing.Year == point.Year {
+			m.gameHistory[i] = point
+			return nil
+		}
+	}
+	m.gameHistory = append(m.gameHistory, point)
+	return nil
+}
+
+func (m *MockRepository) GetGameSummary(ctx context.Context, gameID string) (*models.GameSummary, error) {
+	if m.gameSummary == nil || m.gameSummary.GameID != gameID {
+		return nil, repository.ErrNotFound
+	}
+	return m.gameSummary, nil
+}
+
+func (m *MockRepository) SaveGameSummary(ctx context.Context, summary *models.GameSummary) error {
+	m.gameSummary = summary
+	return nil
+}
+
+func (m *MockRepository) RevealTiles(ctx context.Context, gameID string, playerID string, locations []models.Location, tick int) error {
+	for _, loc := range locations {
+		for _, tile := range m.mapTiles[gameID] {
+			if tile.X == loc.X && tile.Y == loc.Y && !containsString(tile.VisibleTo, playerID) {
+				tile.VisibleTo = append(tile.VisibleTo, playerID)
+				tile.LastModifiedTick = tick
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) AssignTiles(ctx context.Context, gameID string, settlementID string, playerID string, locations []models.Location, tick int) error {
+	for _, loc := range locations {
+		for _, tile := range m.mapTiles[gameID] {
+			if tile.X == loc.X && tile.Y == loc.Y && (tile.OwnerID == nil || *tile.OwnerID == playerID) {
+				owner := playerID
+				tile.OwnerID = &owner
+				tile.SettlementID = settlementID
+				tile.LastModifiedTick = tick
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) MergeSettlements(ctx context.Context, survivor *models.Settlement, absorbed *models.Settlement, asSuburb bool, tick int) error {
+	for _, settlement := range m.settlements {
+		switch {
+		case settlement.SettlementID == survivor.SettlementID:
+			*settlement = *survivor
+		case settlement.SettlementID == absorbed.SettlementID:
+			*settlement = *absorbed
+		case settlement.ParentID == absorbed.SettlementID:
+			settlement.ParentID = survivor.SettlementID
+		}
+	}
+	if asSuburb {
+		return nil
+	}
+	for _, tile := range m.mapTiles[absorbed.GameID] {
+		if tile.SettlementID == absorbed.SettlementID {
+			tile.SettlementID = survivor.SettlementID
+			tile.LastModifiedTick = tick
+		}
+	}
+	for i, settlement := range m.settlements {
+		if settlement.SettlementID == absorbed.SettlementID {
+			m.settlements = append(m.settlements[:i], m.settlements[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) GetMapTile(ctx context.Context, gameID string, x int, y int) (*models.MapTile, error) {
+	m.getMapTileCalls++
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.X == x && tile.Y == y {
+			return tile, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) GetResourceTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	var tiles []*models.MapTile
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.ResourceQuantities != nil {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+func (m *MockRepository) UpdateTileResources(ctx context.Context, tile *models.MapTile) error {
+	return nil
+}
+
+func (m *MockRepository) GetSoilTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	var tiles []*models.MapTile
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.NaturalFertility > 0 && (containsString(tile.Improvements, models.ImprovementFarm) || tile.Fertility < tile.NaturalFertility) {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+func (m *MockRepository) UpdateTileFertility(ctx context.Context, tile *models.MapTile) error {
+	return nil
+}
+
+func (m *MockRepository) GetPollutedTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	var tiles []*models.MapTile
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.Pollution > 0 {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+func (m *MockRepository) GetRiverTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	var tiles []*models.MapTile
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.RiverID > 0 {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+func (m *MockRepository) UpdateTilePollution(ctx context.Context, tile *models.MapTile) error {
+	return nil
+}
+
+func (m *MockRepository) UpdateTileTerrain(ctx context.Context, tile *models.MapTile) error {
+	return nil
+}
+
+func (m *MockRepository) AddTileImprovement(ctx context.Context, gameID string, x int, y int, improvement string, tick int) error {
+	for _, tile := range m.mapTiles[gameID] {
+		if tile.X == x && tile.Y == y {
+			if !containsString(tile.Improvements, improvement) {
+				tile.Improvements = append(tile.Improvements, improvement)
+			}
+			tile.LastModifiedTick = tick
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) UpdateTileImprovements(ctx context.Context, tile *models.MapTile) error {
+	return nil
+}
+
+func (m *MockRepository) GetTilesWithImprovement(ctx context.Context, gameID string, improvement string) ([]*models.MapTile, error) {
+	var tiles []*models.MapTile
+	for _, tile := range m.mapTiles[gameID] {
+		for _, imp := range tile.Improvements {
+			if imp == improvement {
+				tiles = append(tiles, tile)
+				break
+			}
+		}
+	}
+	return tiles, nil
+}
+
+func (m *MockRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	m.orders = append(m.orders, order)
+	return nil
+}
+
+func (m *MockRepository) GetPendingOrders(ctx context.Context, gameID string) ([]*models.Order, error) {
+	var orders []*models.Order
+	for _, order := range m.orders {
+		if order.GameID == gameID && order.Status == models.OrderStatusPending {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (m *MockRepository) UpdateOrder(ctx context.Context, order *models.Order) error {
+	return nil
+}
+
+func (m *MockRepository) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestGameEngine_ProcessTick(t *testing.T) {
 	repo := NewMockRepository()
 	engine := NewGameEngine(repo)
 
-	// Add a game that was just ticked (should not tick again)
-	justNow := time.Now().Add(-100 * time.Millisecond)
+	// Add a started game
+	now := time.Now().Add(-2 * time.Second) // 2 seconds ago
 	repo.games["game1"] = &models.Game{
 		GameID:      "game1",
 		State:       "started",
 		CurrentYear: -5000,
-		LastTickAt:  &justNow,
-	}
-
-	// Add a game that needs ticking
-	longAgo := time.Now().Add(-2 * time.Second)
-	repo.games["game2"] = &models.Game{
-		GameID:      "game2",
-		State:       "started",
-		CurrentYear: -5000,
-		LastTickAt:  &longAgo,
+		LastTickAt:  &now,
 	}
 
 	// Process tick
@@ -269,31 +816,54 @@ func TestGameEngine_TickTiming(t *testing.T) {
 		t.Fatalf("processTick failed: %v", err)
 	}
 
-	// Verify only game2 was updated
+	// Verify game was updated
 	if repo.updateCalls != 1 {
 		t.Errorf("Expected 1 update call, got %d", repo.updateCalls)
 	}
 
-	if repo.games["game1"].CurrentYear != -5000 {
-		t.Errorf("Game1 should not have been updated: year %d", repo.games["game1"].CurrentYear)
-	}
-	if repo.games["game2"].CurrentYear != -4999 {
-		t.Errorf("Game2 should have been updated: year %d", repo.games["game2"].CurrentYear)
+	game := repo.games["game1"]
+	if game.CurrentYear != -4999 {
+		t.Errorf("Expected year -4999, got %d", game.CurrentYear)
 	}
 }
 
-func TestGameEngine_MapGenerationOnFirstTick(t *testing.T) {
+func TestGameEngine_ConcurrentTick(t *testing.T) {
 	repo := NewMockRepository()
 	engine := NewGameEngine(repo)
 
-	// Add a newly started game (no last tick, year is -5000)
+	now := time.Now().Add(-2 * time.Second)
 	repo.games["game1"] = &models.Game{
 		GameID:      "game1",
 		State:       "started",
+		CurrentYear: -4999,
+		LastTickAt:  &now,
+	}
+
+	// This engine read the game before another instance advanced it
+	stale := *repo.games["game1"]
+	stale.CurrentYear = -5000
+
+	err := engine.processGameTick(context.Background(), &stale)
+	if !errors.Is(err, repository.ErrConcurrentTick) {
+		t.Fatalf("Expected ErrConcurrentTick, got %v", err)
+	}
+	if repo.games["game1"].CurrentYear != -4999 {
+		t.Errorf("Expected the game to stay at -4999, got %d", repo.games["game1"].CurrentYear)
+	}
+	if stale.CurrentYear != -5000 {
+		t.Errorf("Expected the stale copy not to advance, got %d", stale.CurrentYear)
+	}
+}
+
+func TestGameEngine_SkipWaitingGames(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+
+	// Add a waiting game
+	repo.games["game1"] = &models.Game{
+		GameID:      "game1",
+		State:       "waiting",
 		CurrentYear: -5000,
-		MaxPlayers:  4,
-		PlayerList:  []string{"player1", "player2", "player3", "player4"},
-		LastTickAt:  nil, // First tick
 	}
 
 	// Process tick
@@ -303,43 +873,34 @@ func TestGameEngine_MapGenerationOnFirstTick(t *testing.T) {
 		t.Fatalf("processTick failed: %v", err)
 	}
 
-	// Verify map was generated
-	if repo.mapMetadata["game1"] == nil {
-		t.Error("Expected map metadata to be saved")
-	}
-	if len(repo.mapTiles["game1"]) == 0 {
-		t.Error("Expected map tiles to be saved")
-	}
-	if len(repo.startingPositions["game1"]) != 4 {
-		t.Errorf("Expected 4 starting positions, got %d", len(repo.startingPositions["game1"]))
-	}
-
-	// Verify metadata properties
-	metadata := repo.mapMetadata["game1"]
-	if metadata.Width == 0 || metadata.Height == 0 {
-		t.Error("Map dimensions should be non-zero")
-	}
-	if metadata.Seed == "" {
-		t.Error("Map seed should be set")
-	}
-	if metadata.PlayerCount != 4 {
-		t.Errorf("Expected player count 4, got %d", metadata.PlayerCount)
+	// Verify no updates (waiting games shouldn't be processed)
+	if repo.updateCalls != 0 {
+		t.Errorf("Expected 0 update calls for waiting game, got %d", repo.updateCalls)
 	}
 }
 
-func TestGameEngine_MapGenerationOnlyOnFirstTick(t *testing.T) {
+func TestGameEngine_MultipleGames(t *testing.T) {
 	repo := NewMockRepository()
 	engine := NewGameEngine(repo)
 
-	// Add a game that already has a last tick (not first tick)
-	lastTick := time.Now().Add(-2 * time.Second)
+	// Add multiple started games
+	now := time.Now().Add(-2 * time.Second)
 	repo.games["game1"] = &models.Game{
 		GameID:      "game1",
 		State:       "started",
-		CurrentYear: -3999, // Already past first tick
-		MaxPlayers:  4,
-		PlayerList:  []string{"player1", "player2", "player3", "player4"},
-		LastTickAt:  &lastTick,
+		CurrentYear: -5000,
+		LastTickAt:  &now,
+	}
+	repo.games["game2"] = &models.Game{
+		GameID:      "game2",
+		State:       "started",
+		CurrentYear: -5000,
+		LastTickAt:  &now,
+	}
+	repo.games["game3"] = &models.Game{
+		GameID:      "game3",
+		State:       "waiting",
+		CurrentYear: -5000,
 	}
 
 	// Process tick
@@ -349,9 +910,4238 @@ func TestGameEngine_MapGenerationOnlyOnFirstTick(t *testing.T) {
 		t.Fatalf("processTick failed: %v", err)
 	}
 
-	// Verify map was NOT generated (already exists)
-	if repo.mapMetadata["game1"] != nil {
-		t.Error("Map should not be generated on subsequent ticks")
+	// Verify both started games were updated
+	if repo.updateCalls != 2 {
+		t.Errorf("Expected 2 update calls, got %d", repo.updateCalls)
+	}
+
+	if repo.games["game1"].CurrentYear != -4999 {
+		t.Errorf("Game1: Expected year -4999, got %d", repo.games["game1"].CurrentYear)
+	}
+	if repo.games["game2"].CurrentYear != -4999 {
+		t.Errorf("Game2: Expected year -4999, got %d", repo.games["game2"].CurrentYear)
+	}
+	if repo.games["game3"].CurrentYear != -5000 {
+		t.Errorf("Game3: Expected year -5000 (unchanged), got %d", repo.games["game3"].CurrentYear)
 	}
 }
 
+func TestGameEngine_YearProgression(t *testing.T) {
+	// Add a game at different year ranges
+	tests := []struct {
+		name        string
+		startYear   int
+		expectedYear int
+	}{
+		{"Ancient", -5000, -4999},
+		{"Classical", -1000, -999},
+		{"Medieval", 500, 501},
+		{"Modern", 1900, 1901},
+		{"Future", 2500, 2501},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockRepository()
+			engine := NewGameEngine(repo)
+
+			now := time.Now().Add(-2 * time.Second)
+			gameID := "test-game"
+			repo.games[gameID] = &models.Game{
+				GameID:      gameID,
+				State:       "started",
+				CurrentYear: tt.startYear,
+				LastTickAt:  &now,
+			}
+
+			ctx := context.Background()
+			err := engine.processTick(ctx)
+			if err != nil {
+				t.Fatalf("processTick failed: %v", err)
+			}
+
+			if repo.games[gameID].CurrentYear != tt.expectedYear {
+				t.Errorf("Expected year %d, got %d", tt.expectedYear, repo.games[gameID].CurrentYear)
+			}
+		})
+	}
+}
+
+func TestGameEngine_TickTiming(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+
+	// Add a game that was just ticked (should not tick again)
+	justNow := time.Now().Add(-100 * time.Millisecond)
+	repo.games["game1"] = &models.Game{
+		GameID:      "game1",
+		State:       "started",
+		CurrentYear: -5000,
+		LastTickAt:  &justNow,
+	}
+
+	// Add a game that needs ticking
+	longAgo := time.Now().Add(-2 * time.Second)
+	repo.games["game2"] = &models.Game{
+		GameID:      "game2",
+		State:       "started",
+		CurrentYear: -5000,
+		LastTickAt:  &longAgo,
+	}
+
+	// Process tick
+	ctx := context.Background()
+	err := engine.processTick(ctx)
+	if err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+
+	// Verify only game2 was updated
+	if repo.updateCalls != 1 {
+		t.Errorf("Expected 1 update call, got %d", repo.updateCalls)
+	}
+
+	if repo.games["game1"].CurrentYear != -5000 {
+		t.Errorf("Game1 should not have been updated: year %d", repo.games["game1"].CurrentYear)
+	}
+	if repo.games["game2"].CurrentYear != -4999 {
+		t.Errorf("Game2 should have been updated: year %d", repo.games["game2"].CurrentYear)
+	}
+}
+
+func TestGameEngine_MapGenerationOnFirstTick(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+
+	// Add a game whose lobby just filled
+	repo.games["game1"] = &models.Game{
+		GameID:      "game1",
+		State:       models.GameStateStarting,
+		CurrentYear: -5000,
+		MaxPlayers:  4,
+		PlayerList:  []string{"player1", "player2", "player3", "player4"},
+		LastTickAt:  nil, // First tick
+	}
+
+	// Process tick
+	ctx := context.Background()
+	err := engine.processTick(ctx)
+	if err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+
+	// Verify map was generated
+	if repo.mapMetadata["game1"] == nil {
+		t.Error("Expected map metadata to be saved")
+	}
+	if len(repo.mapTiles["game1"]) == 0 {
+		t.Error("Expected map tiles to be saved")
+	}
+	if len(repo.startingPositions["game1"]) != 4 {
+		t.Errorf("Expected 4 starting positions, got %d", len(repo.startingPositions["game1"]))
+	}
+
+	// Verify metadata properties
+	metadata := repo.mapMetadata["game1"]
+	if metadata.Width == 0 || metadata.Height == 0 {
+		t.Error("Map dimensions should be non-zero")
+	}
+	if metadata.Seed == "" {
+		t.Error("Map seed should be set")
+	}
+	if metadata.PlayerCount != 4 {
+		t.Errorf("Expected player count 4, got %d", metadata.PlayerCount)
+	}
+
+	// Only starting games get a map: an active game that has never ticked
+	// is not taken for a new one
+	repo.games["game2"] = &models.Game{GameID: "game2", State: models.GameStateActive, CurrentYear: -5000, PlayerList: []string{"player1"}}
+	if needsMap(repo.games["game2"]) {
+		t.Error("Expected an active game not to need a map")
+	}
+}
+
+func TestGameEngine_Lifecycle(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	repo.games["game1"] = &models.Game{
+		GameID:      "game1",
+		State:       models.GameStateStarting,
+		CurrentYear: -5000,
+		MaxPlayers:  2,
+		PlayerList:  []string{"player1", "player2"},
+	}
+
+	// A starting game gets its map and becomes active
+	if err := engine.processTick(ctx); err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+	game := repo.games["game1"]
+	if !game.IsStarted() || game.LastTickAt == nil {
+		t.Fatalf("Expected an active game with a tick clock, got state %q", game.State)
+	}
+	if repo.mapMetadata["game1"] == nil {
+		t.Fatal("Expected the map to be generated")
+	}
+
+	// The map is not generated again on later passes
+	repo.mapMetadata["game1"] = nil
+	if err := engine.processTick(ctx); err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+	if repo.mapMetadata["game1"] != nil {
+		t.Error("Expected the map to be generated only once")
+	}
+
+	// Paused games do not tick
+	if err := engine.PauseGame(ctx, "game1"); err != nil {
+		t.Fatalf("PauseGame failed: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	game.LastTickAt = &past
+	year := game.CurrentYear
+	if err := engine.processTick(ctx); err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+	if game.CurrentYear != year {
+		t.Errorf("Expected a paused game to hold at %d, got %d", year, game.CurrentYear)
+	}
+
+	// Resuming restarts the tick clock rather than replaying the pause
+	if err := engine.ResumeGame(ctx, "game1"); err != nil {
+		t.Fatalf("ResumeGame failed: %v", err)
+	}
+	if !game.IsStarted() || time.Since(*game.LastTickAt) > time.Minute {
+		t.Errorf("Expected a resumed game with a fresh tick clock, got state %q", game.State)
+	}
+
+	// Active games cannot skip straight to the archive
+	if err := engine.ArchiveGame(ctx, "game1"); !errors.Is(err, models.ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition archiving an active game, got %v", err)
+	}
+
+	// A transition from a stale copy is rejected
+	stale := *game
+	stale.State = models.GameStatePaused
+	if err := engine.TransitionGame(ctx, &stale, models.GameStateActive); !errors.Is(err, repository.ErrStateConflict) {
+		t.Errorf("Expected ErrStateConflict, got %v", err)
+	}
+
+	// So is a victory read before the game was paused
+	won := *game
+	won.EliminatedPlayers = []string{"player2"}
+	if err := engine.PauseGame(ctx, "game1"); err != nil {
+		t.Fatalf("PauseGame failed: %v", err)
+	}
+	if err := engine.processVictory(ctx, &won); !errors.Is(err, repository.ErrStateConflict) {
+		t.Errorf("Expected ErrStateConflict ending a game that was paused, got %v", err)
+	}
+	if !game.IsPaused() || game.Winners != nil {
+		t.Errorf("Expected the paused game not to end, got state %q winners %v", game.State, game.Winners)
+	}
+}
+
+func TestGameEngine_MapGenerationOnlyOnFirstTick(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+
+	// Add a game that already has a last tick (not first tick)
+	lastTick := time.Now().Add(-2 * time.Second)
+	repo.games["game1"] = &models.Game{
+		GameID:      "game1",
+		State:       "started",
+		CurrentYear: -3999, // Already past first tick
+		MaxPlayers:  4,
+		PlayerList:  []string{"player1", "player2", "player3", "player4"},
+		LastTickAt:  &lastTick,
+	}
+
+	// Process tick
+	ctx := context.Background()
+	err := engine.processTick(ctx)
+	if err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+
+	// Verify map was NOT generated (already exists)
+	if repo.mapMetadata["game1"] != nil {
+		t.Error("Map should not be generated on subsequent ticks")
+	}
+}
+
+
+func TestGameEngine_SettlersWalkReproducibleFromSeeds(t *testing.T) {
+	walk := func() []models.Location {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+
+		lastTick := time.Now().Add(-2 * time.Second)
+		repo.games["game1"] = &models.Game{
+			GameID:      "game1",
+			State:       "started",
+			CurrentYear: -4990,
+			LastTickAt:  &lastTick,
+			Seeds:       models.NewGameSeeds("walk-seed"),
+		}
+		repo.mapMetadata["game1"] = &models.MapMetadata{GameID: "game1", Width: 50, Height: 50}
+		repo.units = []*models.Unit{{UnitID: "u1", GameID: "game1", UnitType: "settlers", Location: models.Location{X: 25, Y: 25}}}
+
+		var path []models.Location
+		for i := 0; i < 3; i++ {
+			if err := engine.processGameTick(context.Background(), repo.games["game1"]); err != nil {
+				t.Fatalf("processGameTick failed: %v", err)
+			}
+			path = append(path, repo.units[0].Location)
+		}
+
+		if repo.games["game1"].Seeds.Movement.Position != 3 {
+			t.Errorf("Expected movement stream position 3, got %d", repo.games["game1"].Seeds.Movement.Position)
+		}
+		return path
+	}
+
+	first := walk()
+	second := walk()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Step %d differs between runs: %v vs %v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestGameEngine_SettlementGrowthFidelity(t *testing.T) {
+	for _, fidelity := range []string{models.SimulationFidelityDaily, models.SimulationFidelityAggregated} {
+		t.Run(fidelity, func(t *testing.T) {
+			repo := NewMockRepository()
+			engine := NewGameEngine(repo)
+
+			lastTick := time.Now().Add(-2 * time.Second)
+			repo.games["game1"] = &models.Game{
+				GameID:             "game1",
+				State:              "started",
+				CurrentYear:        -4990,
+				LastTickAt:         &lastTick,
+				Seeds:              models.NewGameSeeds("growth-seed"),
+				SimulationFidelity: fidelity,
+			}
+			repo.settlements = []*models.Settlement{{SettlementID: "s1", GameID: "game1", Population: 100}}
+
+			if err := engine.processGameTick(context.Background(), repo.games["game1"]); err != nil {
+				t.Fatalf("processGameTick failed: %v", err)
+			}
+
+			sim := engine.settlementSims["s1"]
+			if sim == nil {
+				t.Fatal("Expected a simulation for the settlement")
+			}
+			if sim.State.CurrentDay != 365 {
+				t.Errorf("Expected 365 simulated days per tick, got %d", sim.State.CurrentDay)
+			}
+			if repo.settlements[0].Population != sim.Population() {
+				t.Errorf("Settlement population %d not synced with simulation %d", repo.settlements[0].Population, sim.Population())
+			}
+		})
+	}
+}
+
+// savesRecordingRepository is a MockRepository that records the order
+// settlement simulations are saved in
+type savesRecordingRepository struct {
+	*MockRepository
+	saved []string
+}
+
+func (r *savesRecordingRepository) SaveSettlementSimulation(ctx context.Context, saved *models.SettlementSimulation) error {
+	r.saved = append(r.saved, saved.SettlementID)
+	return r.MockRepository.SaveSettlementSimulation(ctx, saved)
+}
+
+func TestGameEngine_SettlementGrowthOrder(t *testing.T) {
+	repo := &savesRecordingRepository{MockRepository: NewMockRepository()}
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990, Seeds: models.NewGameSeeds("order-seed")}
+	repo.games["game1"] = game
+	// The store returns settlements in whatever order it likes
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "s3", GameID: "game1", Population: 50},
+		{SettlementID: "s1", GameID: "game1", Population: 50},
+		{SettlementID: "s2", GameID: "game1", Population: 50},
+	}
+
+	if err := engine.processSettlementGrowth(context.Background(), game); err != nil {
+		t.Fatalf("processSettlementGrowth failed: %v", err)
+	}
+	if want := []string{"s1", "s2", "s3"}; !reflect.DeepEqual(repo.saved, want) {
+		t.Errorf("Expected settlements to grow in ID order %v, got %v", want, repo.saved)
+	}
+	if repo.settlements[0].SettlementID != "s3" {
+		t.Error("Expected the store's own order to be left alone")
+	}
+}
+
+func TestCompareGrowth(t *testing.T) {
+	// At daily fidelity the engine runs the standalone simulator's model,
+	// whether it keeps simulations in memory or resumes them every year
+	for _, restart := range []bool{false, true} {
+		comparison, err := CompareGrowth(context.Background(), GrowthCheck{
+			Seed: "growth-seed", Population: 100, Terrain: "GRASSLAND", Fidelity: models.SimulationFidelityDaily, Years: 10, Restart: restart,
+		})
+		if err != nil {
+			t.Fatalf("CompareGrowth failed: %v", err)
+		}
+		if len(comparison.Years) != 10 || comparison.MaxGap != 0 || comparison.TechnologyYears != 0 {
+			t.Errorf("Expected the engine to match the simulator with restart %v, got %+v", restart, comparison)
+		}
+	}
+
+	if _, err := CompareGrowth(context.Background(), GrowthCheck{Population: 100}); err == nil {
+		t.Error("Expected a check without years to be refused")
+	}
+}
+
+func TestGameEngine_ResourceDepletion(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+
+	lastTick := time.Now().Add(-2 * time.Second)
+	repo.games["game1"] = &models.Game{
+		GameID:      "game1",
+		State:       "started",
+		CurrentYear: -4990,
+		LastTickAt:  &lastTick,
+		Seeds:       models.NewGameSeeds("depletion-seed"),
+	}
+	mine := &models.MapTile{
+		GameID:             "game1",
+		X:                  0,
+		Y:                  0,
+		TerrainType:        "HILLS",
+		Resources:          []string{"IRON"},
+		ResourceQuantities: map[string]int{"IRON": 1},
+		Improvements:       []string{"MINE"},
+	}
+	farm := &models.MapTile{
+		GameID:             "game1",
+		X:                  1,
+		Y:                  0,
+		TerrainType:        "GRASSLAND",
+		Resources:          []string{"WHEAT"},
+		ResourceQuantities: map[string]int{"WHEAT": 1},
+		Improvements:       []string{"FARM"},
+		Fertility:          1.0,
+		NaturalFertility:   1.0,
+	}
+	fallow := &models.MapTile{
+		GameID:           "game1",
+		X:                2,
+		Y:                0,
+		TerrainType:      "GRASSLAND",
+		Fertility:        0.5,
+		NaturalFertility: 1.0,
+	}
+	repo.mapTiles["game1"] = []*models.MapTile{mine, farm, fallow}
+
+	if err := engine.processGameTick(context.Background(), repo.games["game1"]); err != nil {
+		t.Fatalf("processGameTick failed: %v", err)
+	}
+
+	if len(mine.Resources) != 0 {
+		t.Errorf("Exhausted IRON should be removed from the tile, got %v", mine.Resources)
+	}
+	if len(farm.Resources) != 1 || farm.ResourceQuantities["WHEAT"] != 0 {
+		t.Errorf("Over-exploited WHEAT should remain at zero quantity, got %v %v", farm.Resources, farm.ResourceQuantities)
+	}
+	if farm.Fertility != 1.0-terrain.SoilDepletion || farm.LastModifiedTick != -4990 {
+		t.Errorf("Farmed soil should wear down, got %.3f", farm.Fertility)
+	}
+	if fallow.Fertility != 0.5+terrain.SoilRecovery {
+		t.Errorf("Fallow soil should recover, got %.3f", fallow.Fertility)
+	}
+}
+
+func TestGameEngine_Pollution(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 6; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"})
+		}
+	}
+	tileAt := func(x, y int) *models.MapTile {
+		tile, _ := repo.GetMapTile(ctx, "game1", x, y)
+		return tile
+	}
+	tileAt(5, 1).Improvements = []string{models.ImprovementMine}
+	tileAt(5, 2).Pollution = 0.5
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "city", GameID: "game1", Location: models.Location{X: 1, Y: 1}, Population: 2 * terrain.DenseSettlementPopulation},
+	}
+
+	if err := engine.processPollution(ctx, game); err != nil {
+		t.Fatalf("processPollution failed: %v", err)
+	}
+
+	cityEmissions := terrain.SettlementEmissions(2 * terrain.DenseSettlementPopulation)
+	for _, tile := range []*models.MapTile{tileAt(0, 0), tileAt(1, 1), tileAt(2, 2)} {
+		if tile.Pollution != cityEmissions-terrain.PollutionRecovery || tile.LastModifiedTick != -4000 {
+			t.Errorf("Expected the dense settlement to pollute (%d, %d), got %.4f", tile.X, tile.Y, tile.Pollution)
+		}
+	}
+	if tile := tileAt(3, 1); tile.Pollution != 0 {
+		t.Errorf("Expected tiles outside the work area clean, got %.4f", tile.Pollution)
+	}
+	if tile := tileAt(5, 1); tile.Pollution != terrain.MinePollution-terrain.PollutionRecovery {
+		t.Errorf("Expected the mine to pollute its tile, got %.4f", tile.Pollution)
+	}
+	if tile := tileAt(5, 2); tile.Pollution != 0.5-terrain.PollutionRecovery {
+		t.Errorf("Expected idle polluted land to recover, got %.4f", tile.Pollution)
+	}
+	if yield := terrain.TileMultiplier(tileAt(5, 2)); yield.Production >= terrain.BaseYield("GRASSLAND").Production {
+		t.Errorf("Expected pollution to cut yields, got %.3f", yield.Production)
+	}
+}
+
+func TestGameEngine_Forests(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("forests")}
+	repo.games["game1"] = game
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{
+				GameID:           "game1",
+				X:                x,
+				Y:                y,
+				TerrainType:      "GRASSLAND",
+				Fertility:        1.0,
+				NaturalFertility: 1.0,
+			})
+		}
+	}
+	tileAt := func(x, y int) *models.MapTile {
+		tile, _ := repo.GetMapTile(ctx, "game1", x, y)
+		return tile
+	}
+	tileAt(2, 2).TerrainType = "FOREST"
+	tileAt(2, 2).Resources = []string{"WOOD"}
+	tileAt(2, 2).ResourceQuantities = map[string]int{"WOOD": 200}
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "near", GameID: "game1", PlayerID: "player1", Location: models.Location{X: 0, Y: 0}},
+		{SettlementID: "far", GameID: "game1", PlayerID: "player1", Location: models.Location{X: 20, Y: 20}},
+	}
+
+	// Regrowth spreads into grassland bordering the forest, never beyond it
+	for year := -4000; year < -3000; year += environmentInterval {
+		game.CurrentYear = year
+		if err := engine.processEnvironment(ctx, game); err != nil {
+			t.Fatalf("processEnvironment failed: %v", err)
+		}
+	}
+	if tile := tileAt(0, 0); tile.TerrainType != "GRASSLAND" {
+		t.Errorf("Expected the settlement center to stay clear, got %s", tile.TerrainType)
+	}
+	forested := 0
+	for _, tile := range repo.mapTiles["game1"] {
+		if tile.TerrainType == "FOREST" {
+			forested++
+		}
+	}
+	if forested < 9 {
+		t.Errorf("Expected the forest to reclaim its neighbors over centuries, got %d forest tiles", forested)
+	}
+
+	// Felling a forest banks timber in the nearest settlement and dries the soil around it
+	for _, tile := range repo.mapTiles["game1"] {
+		tile.TerrainType, tile.Resources, tile.ResourceQuantities = "GRASSLAND", nil, nil
+		tile.Fertility, tile.NaturalFertility = 1.0, 1.0
+	}
+	tileAt(2, 2).TerrainType = "FOREST"
+	tileAt(2, 2).Resources = []string{"WOOD"}
+	tileAt(2, 2).ResourceQuantities = map[string]int{"WOOD": 200}
+	game.CurrentYear = -2999
+	repo.units = []*models.Unit{{UnitID: "workers1", GameID: "game1", PlayerID: "player1", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 2, Y: 2}}}
+	chop := &models.Order{OrderID: "chop1", GameID: "game1", PlayerID: "player1", UnitID: "workers1", OrderType: models.OrderTypeChop, Status: models.OrderStatusPending}
+	repo.orders = []*models.Order{chop}
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+
+	if chop.Status != models.OrderStatusExecuted {
+		t.Fatalf("Expected the chop order executed, got %s (%s)", chop.Status, chop.Reason)
+	}
+	if tile := tileAt(2, 2); tile.TerrainType != "GRASSLAND" || len(tile.Resources) != 0 || tile.LastModifiedTick != -2999 {
+		t.Errorf("Expected the forest felled, got %s %v", tile.TerrainType, tile.Resources)
+	}
+	if near := repo.settlements[0]; near.Production != terrain.ChopProduction+200/terrain.WoodPerProduction {
+		t.Errorf("Expected the nearest settlement to bank the timber, got %d", near.Production)
+	}
+	if far := repo.settlements[1]; far.Production != 0 {
+		t.Errorf("Expected the distant settlement to get nothing, got %d", far.Production)
+	}
+	if tile := tileAt(4, 4); tile.NaturalFertility != 1.0-terrain.ForestMoisture {
+		t.Errorf("Expected deforestation to dry nearby soil, got %.2f", tile.NaturalFertility)
+	}
+
+	// There is nothing left to chop
+	chop.Status = models.OrderStatusPending
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if chop.Status != models.OrderStatusRejected {
+		t.Errorf("Expected a chop order on grassland rejected, got %s", chop.Status)
+	}
+}
+
+func TestGameEngine_TerrainProjects(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("projects")}
+	repo.games["game1"] = game
+	for x := 0; x < 3; x++ {
+		repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{
+			GameID: "game1", X: x, Y: 0, TerrainType: "DESERT", HasRiver: x == 1, Fertility: 0.2, NaturalFertility: 0.2,
+		})
+	}
+	settlement := &models.Settlement{SettlementID: "s1", GameID: "game1", PlayerID: "player1", Location: models.Location{X: 0, Y: 0}}
+	repo.settlements = []*models.Settlement{settlement}
+	workers := &models.Unit{UnitID: "workers1", GameID: "game1", PlayerID: "player1", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 1, Y: 0}}
+	repo.units = []*models.Unit{workers}
+	irrigate := &models.Order{OrderID: "irrigate1", GameID: "game1", PlayerID: "player1", UnitID: "workers1", OrderType: models.OrderTypeTransform, Item: terrain.TransformIrrigation, Status: models.OrderStatusPending}
+	repo.orders = []*models.Order{irrigate}
+
+	// Irrigation needs the technology first
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if irrigate.Status != models.OrderStatusRejected {
+		t.Fatalf("Expected irrigation rejected without %s, got %s", simulator.TechShelterBuilding, irrigate.Status)
+	}
+
+	settlement.Technologies = []string{simulator.TechShelterBuilding}
+	irrigate.Status = models.OrderStatusPending
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if irrigate.Status != models.OrderStatusExecuted || workers.Project == nil {
+		t.Fatalf("Expected the workers to begin irrigating, got %s (%s)", irrigate.Status, irrigate.Reason)
+	}
+
+	// The desert stays desert until the project's years are up
+	years := terrain.Transformations[terrain.TransformIrrigation].Years
+	tile, _ := repo.GetMapTile(ctx, "game1", 1, 0)
+	for i := 0; i < years; i++ {
+		if tile.TerrainType != "DESERT" {
+			t.Fatalf("Expected the desert unchanged after %d years, got %s", i, tile.TerrainType)
+		}
+		if err := engine.processProjects(ctx, game); err != nil {
+			t.Fatalf("processProjects failed: %v", err)
+		}
+		game.CurrentYear++
+	}
+	if tile.TerrainType != "PLAINS" || tile.NaturalFertility <= 0.2 || tile.LastModifiedTick != game.CurrentYear-1 {
+		t.Errorf("Expected irrigated plains with moister soil, got %s at %.2f", tile.TerrainType, tile.NaturalFertility)
+	}
+	if workers.Project != nil {
+		t.Error("Expected the workers free once the project is done")
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != models.EventLandTransformed {
+		t.Errorf("Expected a %s event, got %v", models.EventLandTransformed, repo.events)
+	}
+
+	// A desert without a river cannot be irrigated
+	workers.Location = models.Location{X: 2, Y: 0}
+	irrigate.Status = models.OrderStatusPending
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if irrigate.Status != models.OrderStatusRejected {
+		t.Errorf("Expected irrigation away from a river rejected, got %s", irrigate.Status)
+	}
+}
+
+func TestGameEngine_Disasters(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("disasters")}
+	repo.games["game1"] = game
+	// A river runs along y=2 between a low bank to the north and high
+	// ground to the south; every tile is farmed
+	owner := "player1"
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 9; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND", Elevation: 100, OwnerID: &owner, Improvements: []string{models.ImprovementFarm}}
+			switch y {
+			case 1:
+				tile.Elevation = 20
+			case 2:
+				tile.Elevation, tile.HasRiver, tile.RiverID = 10, true, 1
+			}
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+		}
+	}
+	tileAt := func(x, y int) *models.MapTile { return repo.mapTiles["game1"][y*9+x] }
+	settlement := &models.Settlement{SettlementID: "s1", GameID: "game1", PlayerID: owner, Name: "Quakeville", Location: models.Location{X: 4, Y: 4},
+		Buildings: []string{models.BuildingPalisade, models.BuildingLibrary}}
+	repo.settlements = []*models.Settlement{settlement}
+
+	countEvents := func(eventType string) int {
+		n := 0
+		for _, event := range repo.events {
+			if event.Type == eventType {
+				n++
+			}
+		}
+		return n
+	}
+	damaged := func() int {
+		n := len(settlement.Damaged)
+		for _, tile := range repo.mapTiles["game1"] {
+			n += len(tile.Damaged)
+			if len(tile.Damaged)+len(tile.Improvements) != 1 {
+				t.Fatalf("Expected the farm at (%d, %d) kept or wrecked, got %v and %v", tile.X, tile.Y, tile.Improvements, tile.Damaged)
+			}
+		}
+		return n
+	}
+
+	// Floods follow heavy rains a year later and spare the high ground
+	for countEvents(models.EventFlood) == 0 && game.CurrentYear < -3000 {
+		if err := engine.processDisasters(ctx, game); err != nil {
+			t.Fatalf("processDisasters failed: %v", err)
+		}
+		game.CurrentYear++
+	}
+	flood := repo.events[len(repo.events)-1]
+	if flood.Type != models.EventFlood {
+		t.Fatalf("Expected a flood within a thousand years, got %v", repo.events)
+	}
+	if rain := repo.events[len(repo.events)-2]; rain.Type != models.EventHeavyRain || rain.Year != flood.Year-1 {
+		t.Errorf("Expected heavy rain the year before the flood, got %+v", rain)
+	}
+	if damaged() == 0 {
+		t.Fatal("Expected the flood to wreck some farms")
+	}
+	for x := 0; x < 9; x++ {
+		for _, y := range []int{0, 3, 4} {
+			if len(tileAt(x, y).Damaged) > 0 {
+				t.Errorf("Expected the high ground at (%d, %d) spared, got %v", x, y, tileAt(x, y).Damaged)
+			}
+		}
+	}
+
+	// Workers repair the wreckage, one improvement a year
+	workers := &models.Unit{UnitID: "workers1", GameID: "game1", PlayerID: owner, UnitType: models.UnitTypeWorkers, Automation: models.AutomationImproveNearest, Location: models.Location{X: 0, Y: 0}}
+	repo.units = []*models.Unit{workers}
+	for i := 0; i < 100 && damaged() > 0; i++ {
+		if err := engine.processWorkers(ctx, game); err != nil {
+			t.Fatalf("processWorkers failed: %v", err)
+		}
+	}
+	if damaged() != 0 {
+		t.Fatal("Expected the workers to repair every farm")
+	}
+	repo.units = nil
+
+	// An earthquake by a plate boundary shakes the settlement nearby
+	repo.mapMetadata["game1"] = &models.MapMetadata{GameID: "game1", Features: []models.MapFeature{{Type: models.FeatureRiftValley, X: 4, Y: 4}}}
+	for countEvents(models.EventEarthquake) == 0 && game.CurrentYear < -2000 {
+		if err := engine.processDisasters(ctx, game); err != nil {
+			t.Fatalf("processDisasters failed: %v", err)
+		}
+		game.CurrentYear++
+	}
+	if countEvents(models.EventEarthquake) == 0 {
+		t.Fatal("Expected an earthquake within a thousand years")
+	}
+	if len(settlement.Buildings)+len(settlement.Damaged) != 2 {
+		t.Errorf("Expected the buildings kept or wrecked, got %v and %v", settlement.Buildings, settlement.Damaged)
+	}
+	damaged() // Every farm the quake shook is still kept or wrecked
+}
+
+func TestGameEngine_Harbors(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("harbors")}
+	repo.games["game1"] = game
+	// A strait of water from x=2 to x=9 separates two coasts
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 12; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "OCEAN"}
+			if x < 2 || x > 9 {
+				tile.TerrainType = "GRASSLAND"
+			}
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+		}
+	}
+	west := &models.Settlement{SettlementID: "west", GameID: "game1", PlayerID: "p1", Name: "West", Location: models.Location{X: 1, Y: 2}, Production: 100}
+	east := &models.Settlement{SettlementID: "east", GameID: "game1", PlayerID: "p2", Name: "East", Location: models.Location{X: 10, Y: 2}, Buildings: []string{models.BuildingHarbor}}
+	inland := &models.Settlement{SettlementID: "inland", GameID: "game1", PlayerID: "p1", Name: "Inland", Location: models.Location{X: 11, Y: 0}, Production: 100}
+	repo.settlements = []*models.Settlement{west, east, inland}
+
+	build := func(settlementID string) *models.Order {
+		order := &models.Order{OrderID: "build-" + settlementID, GameID: "game1", PlayerID: "p1", OrderType: models.OrderTypeBuild, SettlementID: settlementID, Item: models.BuildingHarbor, Status: models.OrderStatusPending}
+		repo.orders = append(repo.orders, order)
+		return order
+	}
+	westOrder, inlandOrder := build("west"), build("inland")
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if westOrder.Status != models.OrderStatusExecuted || !containsString(west.Buildings, models.BuildingHarbor) {
+		t.Fatalf("Expected the coastal settlement to build a harbor, got %s (%s)", westOrder.Status, westOrder.Reason)
+	}
+	if west.Production != 100-models.DefaultRuleset().BuildingCosts[models.BuildingHarbor] {
+		t.Errorf("Expected the harbor paid from banked production, %d left", west.Production)
+	}
+	if inlandOrder.Status != models.OrderStatusRejected {
+		t.Error("Expected an inland settlement unable to build a harbor")
+	}
+
+	if err := engine.processTrade(ctx, game); err != nil {
+		t.Fatalf("processTrade failed: %v", err)
+	}
+	if len(west.SeaRoutes) != 1 || west.SeaRoutes[0].PartnerID != "east" || west.SeaRoutes[0].Distance != 7 {
+		t.Fatalf("Expected a sea route across the strait, got %+v", west.SeaRoutes)
+	}
+	if got, want := engine.settlementSims["west"].Conditions.Trade, terrain.SeaTrade(models.DefaultRuleset(), 7); got != want {
+		t.Errorf("Expected the route to earn %.3f, got %.3f", want, got)
+	}
+	if len(inland.SeaRoutes) != 0 {
+		t.Errorf("Expected no sea routes without a harbor, got %+v", inland.SeaRoutes)
+	}
+
+	// Galleys hostile to West raid its route; East's own galleys do not threaten East
+	for i := 0; i < 4; i++ {
+		repo.units = append(repo.units, &models.Unit{UnitID: fmt.Sprintf("galley%d", i), GameID: "game1", PlayerID: "p2", UnitType: models.UnitTypeGalley, Location: models.Location{X: 5, Y: 2}})
+	}
+	if err := engine.processTrade(ctx, game); err != nil {
+		t.Fatalf("processTrade failed: %v", err)
+	}
+	if !west.SeaRoutes[0].Raided || engine.settlementSims["west"].Conditions.Trade != 0 {
+		t.Errorf("Expected pirates to raid West's route, got %+v", west.SeaRoutes)
+	}
+	if east.SeaRoutes[0].Raided {
+		t.Error("Expected East's own galleys to leave its route alone")
+	}
+}
+
+func TestComputeResourceAccess(t *testing.T) {
+	player1 := "player1"
+	player2 := "player2"
+	settlements := []*models.Settlement{
+		{SettlementID: "s1", PlayerID: player1, Location: models.Location{X: 0, Y: 0}},
+	}
+	roads := []*models.MapTile{
+		{X: 2, Y: 0, Improvements: []string{models.ImprovementRoad}, OwnerID: &player1},
+		{X: 3, Y: 0, Improvements: []string{models.ImprovementRoad}, OwnerID: &player1},
+		{X: 5, Y: 0, Improvements: []string{models.ImprovementRoad}, OwnerID: &player1},
+	}
+	resourceTiles := []*models.MapTile{
+		// Inside the work area
+		{X: 1, Y: 1, Resources: []string{"COPPER"}, ResourceQuantities: map[string]int{"COPPER": 10}},
+		// At the end of a connected road in the player's territory
+		{X: 3, Y: 0, Resources: []string{"IRON"}, ResourceQuantities: map[string]int{"IRON": 10}, OwnerID: &player1},
+		// On a road cut off from the network
+		{X: 5, Y: 0, Resources: []string{"COAL"}, ResourceQuantities: map[string]int{"COAL": 10}, OwnerID: &player1},
+		// Exhausted deposit
+		{X: 0, Y: 1, Resources: []string{"GOLD"}, ResourceQuantities: map[string]int{"GOLD": 0}},
+		// Owned by another player
+		{X: -1, Y: 0, Resources: []string{"IRON"}, ResourceQuantities: map[string]int{"IRON": 10}, OwnerID: &player2},
+	}
+
+	access := computeResourceAccess(settlements, roads, resourceTiles, models.DefaultRuleset())[player1]
+	want := ResourceAccess{"COPPER": 1, "IRON": 1}
+	if len(access) != len(want) {
+		t.Fatalf("Expected access %v, got %v", want, access)
+	}
+	for resource, count := range want {
+		if access[resource] != count {
+			t.Errorf("Expected %d %s, got %d", count, resource, access[resource])
+		}
+	}
+
+	if err := CheckRequirements("spearmen", access); err != nil {
+		t.Errorf("Spearmen should be buildable with COPPER: %v", err)
+	}
+	if err := CheckRequirements("forge", access); !errors.Is(err, ErrResourceUnavailable) {
+		t.Errorf("Forge without COAL should fail with ErrResourceUnavailable, got %v", err)
+	}
+	if err := CheckRequirements("settlers", access); err != nil {
+		t.Errorf("Settlers have no strategic requirements: %v", err)
+	}
+}
+
+func TestRiverNavigation(t *testing.T) {
+	// A river runs along row 2 from (0, 2) to (6, 2)
+	tiles := make(map[models.Location]*models.MapTile)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 8; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "PLAINS"}
+			if y == 2 && x <= 6 {
+				tile.HasRiver, tile.RiverID = true, 1
+			}
+			tiles[models.Location{X: x, Y: y}] = tile
+		}
+	}
+	lookup := func(loc models.Location) *models.MapTile { return tiles[loc] }
+
+	route := findRoute(models.Location{X: 0, Y: 1}, models.Location{X: 6, Y: 1}, lookup)
+	if got, want := routeCost(route, lookup), routeCost(findPath(models.Location{X: 0, Y: 1}, models.Location{X: 6, Y: 1}), lookup); got >= want {
+		t.Errorf("Expected the route to follow the river for less than %.1f, got %.1f along %v", want, got, route)
+	}
+	if route[0] != (models.Location{X: 0, Y: 1}) || route[len(route)-1] != (models.Location{X: 6, Y: 1}) {
+		t.Errorf("Expected the route to run end to end, got %v", route)
+	}
+	if straight := findRoute(models.Location{X: 0, Y: 4}, models.Location{X: 2, Y: 4}, lookup); len(straight) != 3 {
+		t.Errorf("Expected the straight path away from rivers, got %v", straight)
+	}
+
+	onRiver := findRoute(models.Location{X: 0, Y: 2}, models.Location{X: 6, Y: 2}, lookup)
+	if path := advanceAlongRoute(onRiver, lookup, &models.Unit{UnitType: models.UnitTypeWorkers}, 1); len(path) != 3 {
+		t.Errorf("Expected two river steps in a year, got %v", path)
+	}
+	if path := advanceAlongRoute(findPath(models.Location{X: 0, Y: 4}, models.Location{X: 3, Y: 4}), lookup, &models.Unit{UnitType: models.UnitTypeWorkers}, 1); len(path) != 2 {
+		t.Errorf("Expected one plain step in a year, got %v", path)
+	}
+
+	// The river carries trade to a resource far outside the work area
+	player1 := "player1"
+	settlements := []*models.Settlement{{SettlementID: "s1", PlayerID: player1, Location: models.Location{X: 0, Y: 1}}}
+	var rivers []*models.MapTile
+	for _, tile := range tiles {
+		if tile.RiverID != 0 {
+			rivers = append(rivers, tile)
+		}
+	}
+	iron := &models.MapTile{X: 6, Y: 2, RiverID: 1, Resources: []string{"IRON"}, ResourceQuantities: map[string]int{"IRON": 10}, OwnerID: &player1}
+	if access := computeResourceAccess(settlements, rivers, []*models.MapTile{iron}, models.DefaultRuleset())[player1]; access["IRON"] != 1 {
+		t.Errorf("Expected IRON reachable along the river, got %v", access)
+	}
+}
+
+func TestGameEngine_RiverTrade(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("rivers")}
+	repo.games["game1"] = game
+	for x := 0; x < 12; x++ {
+		for y := 0; y < 3; y++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"}
+			if y == 1 && x < 8 {
+				tile.HasRiver, tile.RiverID = true, 1
+			}
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+		}
+	}
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "upstream", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 1, Y: 1}, Population: 50},
+		{SettlementID: "downstream", GameID: "game1", PlayerID: "p2", Location: models.Location{X: 6, Y: 1}, Population: 50},
+		{SettlementID: "inland", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 10, Y: 1}, Population: 50},
+	}
+
+	if err := engine.processTrade(ctx, game); err != nil {
+		t.Fatalf("processTrade failed: %v", err)
+	}
+	for id, want := range map[string]float64{"upstream": terrain.RiverTrade(models.DefaultRuleset(), 1), "downstream": terrain.RiverTrade(models.DefaultRuleset(), 1), "inland": 0} {
+		if got := engine.settlementSims[id].Conditions.Trade; got != want {
+			t.Errorf("Expected %s to trade for %.2f, got %.2f", id, want, got)
+		}
+	}
+}
+
+func TestGameEngine_UnitMovement(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	// A road runs from (0, 0) to (3, 0); a marsh lies at (1, 2) and the sea at (9, 2)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 10; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"}
+			if y == 0 && x <= 3 {
+				tile.Improvements = []string{models.ImprovementRoad}
+			}
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+		}
+	}
+	marsh, _ := repo.GetMapTile(ctx, "game1", 1, 2)
+	marsh.TerrainType = terrain.Marsh
+	sea, _ := repo.GetMapTile(ctx, "game1", 9, 2)
+	sea.TerrainType = terrain.Ocean
+	lookup := func(loc models.Location) *models.MapTile {
+		tile, _ := repo.GetMapTile(ctx, "game1", loc.X, loc.Y)
+		return tile
+	}
+
+	// Great people travel two plain steps a year
+	general := &models.Unit{UnitType: models.UnitTypeGreatGeneral}
+	if path := advanceAlongRoute(findPath(models.Location{X: 0, Y: 1}, models.Location{X: 5, Y: 1}), lookup, general, 1); len(path) != 3 {
+		t.Errorf("Expected a great person to take two plain steps, got %v", path)
+	}
+
+	// A step into the marsh costs two years of a worker's movement
+	wader := &models.Unit{UnitType: models.UnitTypeWorkers}
+	intoMarsh := findPath(models.Location{X: 0, Y: 2}, models.Location{X: 1, Y: 2})
+	if path := advanceAlongRoute(intoMarsh, lookup, wader, 1); len(path) != 1 || wader.MovesLeft != 1 {
+		t.Errorf("Expected the worker to save its movement for the marsh, got %v with %.2f saved", path, wader.MovesLeft)
+	}
+	if path := advanceAlongRoute(intoMarsh, lookup, wader, 1); len(path) != 2 || wader.MovesLeft != 0 {
+		t.Errorf("Expected the worker to wade in the next year, got %v with %.2f saved", path, wader.MovesLeft)
+	}
+
+	// A move order sends a worker along the road, three tiles in its first
+	// year, then a tile a year beyond it
+	worker := &models.Unit{UnitID: "w1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 0, Y: 0}}
+	galley := &models.Unit{UnitID: "g1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeGalley, Location: models.Location{X: 9, Y: 2}}
+	repo.units = []*models.Unit{worker, galley}
+	repo.orders = []*models.Order{
+		{OrderID: "o1", GameID: "game1", PlayerID: "p1", UnitID: "w1", OrderType: models.OrderTypeMove, Target: &models.Location{X: 9, Y: 2}, Status: models.OrderStatusPending},
+		{OrderID: "o2", GameID: "game1", PlayerID: "p1", UnitID: "g1", OrderType: models.OrderTypeMove, Target: &models.Location{X: 8, Y: 2}, Status: models.OrderStatusPending},
+		{OrderID: "o3", GameID: "game1", PlayerID: "p1", UnitID: "w1", OrderType: models.OrderTypeMove, Target: &models.Location{X: 6, Y: 0}, Status: models.OrderStatusPending},
+	}
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if repo.orders[0].Status != models.OrderStatusRejected || repo.orders[1].Status != models.OrderStatusRejected {
+		t.Errorf("Expected moves onto water and by galley over land to be rejected, got %s and %s", repo.orders[0].Status, repo.orders[1].Status)
+	}
+	if repo.orders[2].Status != models.OrderStatusExecuted || worker.Destination == nil || len(repo.orders[2].Path) != 7 {
+		t.Fatalf("Expected the worker sent to (6, 0) along a 7-tile route, got %s with %v", repo.orders[2].Status, repo.orders[2].Path)
+	}
+
+	// The route's tiles load with the map, not one query a step
+	repo.getMapTileCalls = 0
+	for year, want := range []int{3, 4, 5, 6} {
+		if err := engine.processUnitMovement(ctx, game); err != nil {
+			t.Fatalf("processUnitMovement failed: %v", err)
+		}
+		if worker.Location != (models.Location{X: want, Y: 0}) {
+			t.Fatalf("Expected the worker at (%d, 0) after %d years, got %v", want, year+1, worker.Location)
+		}
+	}
+	if worker.Destination != nil || worker.MovesLeft != 0 {
+		t.Errorf("Expected the worker to stop on arrival, got destination %v with %.2f saved", worker.Destination, worker.MovesLeft)
+	}
+	if repo.getMapTileCalls != 0 {
+		t.Errorf("Expected movement to look up no tiles one at a time, got %d lookups", repo.getMapTileCalls)
+	}
+}
+
+func TestGameEngine_SettlersWander(t *testing.T) {
+	setup := func(terrainType string, pacing string) (*MockRepository, *GameEngine, *models.Game) {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+		game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990, Seeds: models.NewGameSeeds("wander-seed"), Pacing: pacing}
+		repo.games["game1"] = game
+		repo.mapMetadata["game1"] = &models.MapMetadata{GameID: "game1", Width: 9, Height: 9}
+		for y := 0; y < 9; y++ {
+			for x := 0; x < 9; x++ {
+				tileType := terrainType
+				if x == 4 && y == 4 {
+					tileType = "GRASSLAND"
+				}
+				repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: tileType})
+			}
+		}
+		repo.units = []*models.Unit{{UnitID: "u1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeSettlers, Location: models.Location{X: 4, Y: 4}}}
+		return repo, engine, game
+	}
+	ctx := context.Background()
+
+	// A step out into the marsh costs two years of movement
+	repo, engine, game := setup(terrain.Marsh, "")
+	if err := engine.processSettlersUnits(ctx, game); err != nil {
+		t.Fatalf("processSettlersUnits failed: %v", err)
+	}
+	if unit := repo.units[0]; unit.Location != (models.Location{X: 4, Y: 4}) || unit.StepsTaken != 0 || unit.MovesLeft != 1 {
+		t.Errorf("Expected the settlers to save their movement for the marsh, got %v after %d steps with %.2f saved", unit.Location, unit.StepsTaken, unit.MovesLeft)
+	}
+	if err := engine.processSettlersUnits(ctx, game); err != nil {
+		t.Fatalf("processSettlersUnits failed: %v", err)
+	}
+	if unit := repo.units[0]; unit.Location == (models.Location{X: 4, Y: 4}) || unit.StepsTaken != 1 {
+		t.Errorf("Expected the settlers to wade in the next year, got %v after %d steps", unit.Location, unit.StepsTaken)
+	}
+
+	// Settlers never wander onto water
+	repo, engine, game = setup(terrain.Ocean, "")
+	if err := engine.processSettlersUnits(ctx, game); err != nil {
+		t.Fatalf("processSettlersUnits failed: %v", err)
+	}
+	if unit := repo.units[0]; unit.Location != (models.Location{X: 4, Y: 4}) || unit.StepsTaken != game.Ruleset().SettlersWalkSteps {
+		t.Errorf("Expected the settlers to stay on their island, got %v after %d steps", unit.Location, unit.StepsTaken)
+	}
+
+	// A tick spanning many years walks the whole way at once
+	repo, engine, game = setup("GRASSLAND", models.PacingEras)
+	if err := engine.processSettlersUnits(ctx, game); err != nil {
+		t.Fatalf("processSettlersUnits failed: %v", err)
+	}
+	if unit := repo.units[0]; unit.StepsTaken != game.Ruleset().SettlersWalkSteps || unit.MovesLeft != 0 {
+		t.Errorf("Expected the walk done in one long tick, got %d steps with %.2f saved", unit.StepsTaken, unit.MovesLeft)
+	}
+}
+
+func TestGameEngine_PlayerDirectedStart(t *testing.T) {
+	setup := func(startedAgo time.Duration) (*MockRepository, *GameEngine, *models.Game) {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+
+		started := time.Now().Add(-startedAgo)
+		game := &models.Game{
+			GameID:      "game1",
+			State:       "started",
+			CurrentYear: -4990,
+			StartedAt:   &started,
+			LastTickAt:  &started,
+			Seeds:       models.NewGameSeeds("start-seed"),
+			StartMode:   models.StartModePlayer,
+		}
+		repo.games["game1"] = game
+		repo.mapMetadata["game1"] = &models.MapMetadata{GameID: "game1", Width: 10, Height: 10}
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				terrainType := "TUNDRA"
+				if x == 0 {
+					terrainType = "OCEAN"
+				} else if x == 6 && y == 5 {
+					terrainType = "GRASSLAND"
+				}
+				repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: terrainType})
+			}
+		}
+		repo.units = []*models.Unit{{UnitID: "u1", GameID: "game1", PlayerID: "p1", UnitType: "settlers", Location: models.Location{X: 5, Y: 5}, PopulationCost: 100}}
+		return repo, engine, game
+	}
+
+	t.Run("waits for an order before the deadline", func(t *testing.T) {
+		repo, engine, game := setup(time.Second)
+		if err := engine.processGameTick(context.Background(), game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+		if len(repo.units) != 1 || repo.units[0].Location != (models.Location{X: 5, Y: 5}) || len(repo.settlements) != 0 {
+			t.Errorf("Settlers should wait in place, got units %v settlements %d", repo.units, len(repo.settlements))
+		}
+	})
+
+	t.Run("executes a settle order", func(t *testing.T) {
+		repo, engine, game := setup(time.Second)
+		repo.orders = []*models.Order{
+			{OrderID: "o1", GameID: "game1", PlayerID: "p1", UnitID: "u1", OrderType: models.OrderTypeSettle, Target: &models.Location{X: 0, Y: 5}, Status: models.OrderStatusPending},
+			{OrderID: "o2", GameID: "game1", PlayerID: "p1", UnitID: "u1", OrderType: models.OrderTypeSettle, Target: &models.Location{X: 4, Y: 4}, Status: models.OrderStatusPending},
+		}
+		if err := engine.processGameTick(context.Background(), game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+		if repo.orders[0].Status != models.OrderStatusRejected {
+			t.Errorf("Settling on water should be rejected, got %s", repo.orders[0].Status)
+		}
+		if repo.orders[1].Status != models.OrderStatusExecuted {
+			t.Errorf("Valid settle order should execute, got %s (%s)", repo.orders[1].Status, repo.orders[1].Reason)
+		}
+		if len(repo.settlements) != 1 || repo.settlements[0].Location != (models.Location{X: 4, Y: 4}) {
+			t.Errorf("Expected a settlement at (4, 4), got %v", repo.settlements)
+		}
+
+		// The route is kept on the order and emitted as timed keyframes
+		wantPath := []models.Location{{X: 5, Y: 5}, {X: 4, Y: 5}, {X: 4, Y: 4}}
+		if len(repo.orders[1].Path) != len(wantPath) {
+			t.Fatalf("Expected path %v on the order, got %v", wantPath, repo.orders[1].Path)
+		}
+		var movement *models.Movement
+		for _, event := range repo.events {
+			if event.Type == models.EventUnitMoved {
+				movement = event.Movement
+			}
+		}
+		if movement == nil || movement.UnitID != "u1" || len(movement.Keyframes) != len(wantPath) {
+			t.Fatalf("Expected a movement event for u1 along %v, got %+v", wantPath, movement)
+		}
+		for i, keyframe := range movement.Keyframes {
+			if (models.Location{X: keyframe.X, Y: keyframe.Y}) != wantPath[i] || keyframe.AtMs != i*movement.DurationMs/2 {
+				t.Errorf("Keyframe %d = %+v, want %v at %dms", i, keyframe, wantPath[i], i*movement.DurationMs/2)
+			}
+		}
+	})
+
+	t.Run("auto-settles at the best nearby tile after the deadline", func(t *testing.T) {
+		repo, engine, game := setup(2 * models.DefaultSettleTimeLimit)
+
+		// The settlers walk to the site, then settle on it
+		for tick := 0; tick < 2; tick++ {
+			if err := engine.processGameTick(context.Background(), game); err != nil {
+				t.Fatalf("processGameTick failed: %v", err)
+			}
+			if tick == 0 && len(repo.settlements) != 0 {
+				t.Fatalf("Expected the settlers to walk to the site before settling, got %v", repo.settlements)
+			}
+		}
+		if len(repo.settlements) != 1 || repo.settlements[0].Location != (models.Location{X: 6, Y: 5}) {
+			t.Errorf("Expected auto-settle on the grassland at (6, 5), got %v", repo.settlements)
+		}
+	})
+}
+
+func TestGameEngine_AutoSettlePicksBestNearbySite(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+
+	lastTick := time.Now().Add(-2 * time.Second)
+	game := &models.Game{
+		GameID:      "game1",
+		State:       "started",
+		CurrentYear: -4990,
+		LastTickAt:  &lastTick,
+		Seeds:       models.NewGameSeeds("settle-seed"),
+	}
+	repo.games["game1"] = game
+	repo.mapMetadata["game1"] = &models.MapMetadata{GameID: "game1", Width: 10, Height: 10}
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			terrainType := "TUNDRA"
+			if x >= 6 {
+				terrainType = "GRASSLAND"
+			}
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: terrainType})
+		}
+	}
+	// The random walk has finished on tundra right next to grassland
+	repo.units = []*models.Unit{{UnitID: "u1", GameID: "game1", PlayerID: "p1", UnitType: "settlers", Location: models.Location{X: 5, Y: 5}, StepsTaken: 3}}
+
+	// The settlers spend the year walking to the site rather than jumping there
+	if err := engine.processGameTick(context.Background(), game); err != nil {
+		t.Fatalf("processGameTick failed: %v", err)
+	}
+	if len(repo.settlements) != 0 || repo.units[0].Location == (models.Location{X: 5, Y: 5}) {
+		t.Fatalf("Expected the settlers a step along their way, got %v at %v", repo.settlements, repo.units[0].Location)
+	}
+	for tick := 0; tick < 3 && len(repo.settlements) == 0; tick++ {
+		if err := engine.processGameTick(context.Background(), game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+	}
+
+	if len(repo.settlements) != 1 {
+		t.Fatalf("Expected one settlement, got %d", len(repo.settlements))
+	}
+	loc := repo.settlements[0].Location
+	tile, _ := repo.GetMapTile(context.Background(), "game1", loc.X, loc.Y)
+	if tile.TerrainType != "GRASSLAND" {
+		t.Errorf("Expected auto-settle to move onto grassland, settled on %s at (%d, %d)", tile.TerrainType, loc.X, loc.Y)
+	}
+}
+
+func TestGameEngine_SettlementMerging(t *testing.T) {
+	setup := func(rule string) (*MockRepository, *GameEngine, *models.Game) {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+		game := &models.Game{GameID: "game1", State: "started", SettlementMergeRule: rule}
+		repo.games["game1"] = game
+		repo.settlements = []*models.Settlement{
+			{SettlementID: "small", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 3, Y: 0}, Population: 80, Buildings: []string{"granary"}},
+			{SettlementID: "big", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 0, Y: 0}, Population: 120, Buildings: []string{"granary", "shrine"}},
+			{SettlementID: "far", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 20, Y: 0}, Population: 100},
+			{SettlementID: "rival", GameID: "game1", PlayerID: "p2", Location: models.Location{X: 1, Y: 2}, Population: 50},
+			{SettlementID: "outskirts", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 40, Y: 0}, Population: 30, ParentID: "small"},
+		}
+		repo.mapTiles["game1"] = []*models.MapTile{
+			{GameID: "game1", X: 3, Y: 0, SettlementID: "small"},
+			{GameID: "game1", X: 0, Y: 0, SettlementID: "big"},
+		}
+		return repo, engine, game
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		repo, engine, game := setup(models.SettlementMergeMerge)
+		if err := engine.processSettlementMerging(context.Background(), game); err != nil {
+			t.Fatalf("processSettlementMerging failed: %v", err)
+		}
+		if len(repo.settlements) != 4 {
+			t.Fatalf("Expected the smaller settlement to be absorbed, got %d settlements", len(repo.settlements))
+		}
+		big := repo.settlements[0]
+		if big.SettlementID != "big" || big.Population != 200 {
+			t.Errorf("Expected big to hold the combined population 200, got %s with %d", big.SettlementID, big.Population)
+		}
+		if len(big.Buildings) != 2 {
+			t.Errorf("Expected buildings to be consolidated without duplicates, got %v", big.Buildings)
+		}
+		if repo.mapTiles["game1"][0].SettlementID != "big" {
+			t.Errorf("Expected absorbed tiles to be reassigned, got %s", repo.mapTiles["game1"][0].SettlementID)
+		}
+		if outskirts := repo.settlements[3]; outskirts.ParentID != "big" {
+			t.Errorf("Expected the absorbed settlement's suburb to move to big, got parent %q", outskirts.ParentID)
+		}
+	})
+
+	t.Run("suburb", func(t *testing.T) {
+		repo, engine, game := setup(models.SettlementMergeSuburb)
+		if err := engine.processSettlementMerging(context.Background(), game); err != nil {
+			t.Fatalf("processSettlementMerging failed: %v", err)
+		}
+		if len(repo.settlements) != 5 {
+			t.Fatalf("Suburb rule should keep all settlements, got %d", len(repo.settlements))
+		}
+		if repo.settlements[0].ParentID != "big" {
+			t.Errorf("Expected small to become a suburb of big, got parent %q", repo.settlements[0].ParentID)
+		}
+		if repo.settlements[2].ParentID != "" || repo.settlements[3].ParentID != "" {
+			t.Error("Distant and rival settlements should not become suburbs")
+		}
+		if repo.settlements[4].ParentID != "big" {
+			t.Errorf("Expected small's own suburb to join big, got parent %q", repo.settlements[4].ParentID)
+		}
+	})
+}
+
+func TestSettlementsAdjacent(t *testing.T) {
+	rules := models.DefaultRuleset()
+	at := func(x, y int) *models.Settlement {
+		return &models.Settlement{Location: models.Location{X: x, Y: y}, Population: 10}
+	}
+	// Footprints of radius 1 touch when their centers are 3 steps apart,
+	// diagonal steps included, as work areas count them
+	if !settlementsAdjacent(rules, at(0, 0), at(3, 3)) {
+		t.Error("Expected diagonal footprints 3 steps apart to touch")
+	}
+	if settlementsAdjacent(rules, at(0, 0), at(4, 1)) {
+		t.Error("Expected footprints 4 steps apart not to touch")
+	}
+}
+
+func TestGameEngine_PopulationReconciliation(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", Seeds: models.NewGameSeeds("audit-seed")}
+	repo.games["game1"] = game
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Population: 100},
+		{SettlementID: "s2", GameID: "game1", PlayerID: "p1", Population: 40},
+	}
+	repo.units = []*models.Unit{{UnitID: "u1", GameID: "game1", PlayerID: "p1", UnitType: "settlers", PopulationCost: 100}}
+
+	// Run s1's simulation forward without persisting, as if an update was lost
+	sim := engine.settlementSimulation(context.Background(), game, repo.settlements[0])
+	sim.AdvanceDays(365)
+	if sim.Population() == 100 {
+		t.Skip("Simulation population unchanged after a year; nothing to reconcile")
+	}
+
+	totals, healed, err := engine.reconcilePopulation(context.Background(), game)
+	if err != nil {
+		t.Fatalf("reconcilePopulation failed: %v", err)
+	}
+	if healed != 1 {
+		t.Errorf("Expected 1 settlement healed, got %d", healed)
+	}
+	if repo.settlements[0].Population != sim.Population() {
+		t.Errorf("Expected s1 population healed to %d, got %d", sim.Population(), repo.settlements[0].Population)
+	}
+	want := PopulationTotals{Settlements: sim.Population() + 40, Units: 100, Total: sim.Population() + 140}
+	if totals["p1"] != want {
+		t.Errorf("Expected totals %+v, got %+v", want, totals["p1"])
+	}
+
+	// A second pass finds nothing to heal
+	if _, healed, _ := engine.reconcilePopulation(context.Background(), game); healed != 0 {
+		t.Errorf("Expected no drift after healing, got %d", healed)
+	}
+}
+
+func TestGameEngine_CheckInvariants(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990}
+	repo.games["game1"] = game
+
+	p1, p2 := "p1", "p2"
+	repo.units = []*models.Unit{{UnitID: "u1", GameID: "game1", PlayerID: p1, UnitType: "settlers", Location: models.Location{X: 5, Y: 5}}}
+	repo.settlements = []*models.Settlement{{SettlementID: "s1", GameID: "game1", PlayerID: p1, Population: 100}}
+	repo.mapTiles["game1"] = []*models.MapTile{
+		{GameID: "game1", X: 0, Y: 0, SettlementID: "s1", OwnerID: &p1},
+		{GameID: "game1", X: 1, Y: 0, SettlementID: "s1", OwnerID: &p2},
+		{GameID: "game1", X: 2, Y: 0, SettlementID: "gone", OwnerID: &p1},
+		{GameID: "game1", X: 3, Y: 0, ResourceQuantities: map[string]int{"IRON": -5}},
+	}
+
+	violations, err := engine.checkInvariants(context.Background(), game)
+	if err != nil {
+		t.Fatalf("checkInvariants failed: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, v := range violations {
+		counts[v.Invariant]++
+		if v.Year != -4990 {
+			t.Errorf("Expected violation at year -4990, got %d", v.Year)
+		}
+	}
+	if counts[InvariantTileOwnership] != 2 || counts[InvariantNegativeStockpile] != 1 || counts[InvariantUnitTeleported] != 0 {
+		t.Errorf("Unexpected violations on first check: %+v", violations)
+	}
+
+	// One year later the unit has jumped four tiles, farther than even a road carries it
+	game.CurrentYear++
+	repo.units[0].Location = models.Location{X: 9, Y: 5}
+	violations, err = engine.checkInvariants(context.Background(), game)
+	if err != nil {
+		t.Fatalf("checkInvariants failed: %v", err)
+	}
+	teleported := false
+	for _, v := range violations {
+		if v.Invariant == InvariantUnitTeleported && v.EntityID == "u1" {
+			teleported = true
+		}
+	}
+	if !teleported {
+		t.Error("Expected the unit's jump to be reported")
+	}
+}
+
+func TestGameEngine_CheckInvariants_LegalMoves(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990}
+	repo.games["game1"] = game
+	repo.units = []*models.Unit{
+		{UnitID: "settlers", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeSettlers, Location: models.Location{X: 0, Y: 0}},
+		{UnitID: "galley", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeGalley, Location: models.Location{X: 0, Y: 5}},
+	}
+	for x := 0; x < 12; x++ {
+		repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: 0, TerrainType: terrain.Grassland, Improvements: []string{models.ImprovementRoad}})
+	}
+	if _, err := engine.checkInvariants(context.Background(), game); err != nil {
+		t.Fatalf("checkInvariants failed: %v", err)
+	}
+
+	// In a year the settlers walk three road tiles and the galley sails three tiles
+	game.CurrentYear++
+	ctx := context.Background()
+	tileAt, err := engine.mapTileLookup(ctx, "game1")
+	if err != nil {
+		t.Fatalf("mapTileLookup failed: %v", err)
+	}
+	settlers := repo.units[0]
+	path := advanceAlongRoute(findPath(settlers.Location, models.Location{X: 11, Y: 0}), tileAt, settlers, 1)
+	settlers.Location = path[len(path)-1]
+	if settlers.Location.X != 3 {
+		t.Fatalf("Expected the settlers three tiles down the road, got %+v", settlers.Location)
+	}
+	repo.units[1].Location = models.Location{X: 3, Y: 5}
+
+	violations, err := engine.checkInvariants(ctx, game)
+	if err != nil {
+		t.Fatalf("checkInvariants failed: %v", err)
+	}
+	for _, v := range violations {
+		if v.Invariant == InvariantUnitTeleported {
+			t.Errorf("Expected legal moves not reported, got %+v", v)
+		}
+	}
+}
+
+func TestGameEngine_PinnedRules(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+
+	lastTick := time.Now().Add(-2 * time.Second)
+	legacy := &models.Game{GameID: "legacy", State: "started", CurrentYear: -4990, LastTickAt: &lastTick, Seeds: models.NewGameSeeds("rules-seed")}
+	repo.games["legacy"] = legacy
+	if err := engine.processGameTick(context.Background(), legacy); err != nil {
+		t.Fatalf("processGameTick failed: %v", err)
+	}
+	if legacy.Rules == nil || legacy.Rules.Version != models.CurrentRulesVersion {
+		t.Fatalf("Expected legacy game to be pinned to version %d, got %+v", models.CurrentRulesVersion, legacy.Rules)
+	}
+
+	// A game pinned to different rules plays by them, not the current ones
+	rules := models.DefaultRuleset()
+	rules.SettlersWalkSteps = 0
+	pinned := &models.Game{GameID: "pinned", State: "started", CurrentYear: -4990, LastTickAt: &lastTick, Seeds: models.NewGameSeeds("rules-seed"), Rules: rules}
+	repo.games["pinned"] = pinned
+	repo.mapMetadata["pinned"] = &models.MapMetadata{GameID: "pinned", Width: 5, Height: 5}
+	repo.mapTiles["pinned"] = []*models.MapTile{{GameID: "pinned", X: 2, Y: 2, TerrainType: "GRASSLAND"}}
+	repo.units = []*models.Unit{{UnitID: "u1", GameID: "pinned", PlayerID: "p1", UnitType: "settlers", Location: models.Location{X: 2, Y: 2}, PopulationCost: 100}}
+
+	if err := engine.processGameTick(context.Background(), pinned); err != nil {
+		t.Fatalf("processGameTick failed: %v", err)
+	}
+	if len(repo.settlements) != 1 {
+		t.Errorf("Expected the unit to settle without walking under the pinned rules, got %d settlements", len(repo.settlements))
+	}
+	if pinned.Rules != rules {
+		t.Error("Pinned rules should not be replaced")
+	}
+}
+
+func TestGameEngine_ApplyBalanceConfig(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	repo.games["sandbox"] = &models.Game{GameID: "sandbox", State: "started", Rules: models.DefaultRuleset()}
+	repo.games["live"] = &models.Game{GameID: "live", State: "started", Rules: models.DefaultRuleset()}
+
+	path := filepath.Join(t.TempDir(), "balance.json")
+	content := `{"sandboxGames": ["sandbox", "missing"], "rules": {"settlersWalkSteps": 1, "greatPersonThreshold": 40,
+		"settlement": {"fertility": 1.5, "foodYield": 0.8}, "buildingCosts": {"library": 30}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing balance config: %v", err)
+	}
+
+	cfg, err := LoadBalanceConfig(path)
+	if err != nil {
+		t.Fatalf("LoadBalanceConfig failed: %v", err)
+	}
+	if err := engine.ApplyBalanceConfig(context.Background(), cfg); err != nil {
+		t.Fatalf("ApplyBalanceConfig failed: %v", err)
+	}
+
+	sandbox := repo.games["sandbox"].Rules
+	if sandbox.SettlersWalkSteps != 1 {
+		t.Errorf("Expected sandbox game to pick up settlersWalkSteps=1, got %d", sandbox.SettlersWalkSteps)
+	}
+	if sandbox.GreatPersonThreshold != 40 {
+		t.Errorf("Expected sandbox game to pick up greatPersonThreshold=40, got %d", sandbox.GreatPersonThreshold)
+	}
+	if sandbox.Settlement.Fertility != 1.5 || sandbox.Settlement.FoodYield != 0.8 {
+		t.Errorf("Expected sandbox game to pick up tuned settlement rates, got %+v", sandbox.Settlement)
+	}
+	if sandbox.BuildingCosts[models.BuildingLibrary] != 30 {
+		t.Errorf("Expected sandbox library to cost 30, got %d", sandbox.BuildingCosts[models.BuildingLibrary])
+	}
+	defaults := models.DefaultRuleset()
+	if sandbox.SettlementWorkRadius != defaults.SettlementWorkRadius {
+		t.Error("Unspecified rules should keep their current values")
+	}
+	if sandbox.Settlement.Mortality != defaults.Settlement.Mortality {
+		t.Error("Unspecified settlement rates should keep their current values")
+	}
+	if sandbox.BuildingCosts[models.BuildingPalisade] != defaults.BuildingCosts[models.BuildingPalisade] {
+		t.Error("Unspecified building costs should keep their current values")
+	}
+	if repo.games["live"].Rules.BuildingCosts[models.BuildingLibrary] != defaults.BuildingCosts[models.BuildingLibrary] {
+		t.Error("Tuning a sandbox game's building costs must not change other games")
+	}
+	if repo.games["live"].Rules.SettlersWalkSteps != models.DefaultRuleset().SettlersWalkSteps {
+		t.Error("Games not listed as sandboxes must not change")
+	}
+}
+
+func TestGameEngine_Exploration(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990}
+	repo.games["game1"] = game
+
+	p1 := "p1"
+	repo.mapTiles["game1"] = []*models.MapTile{
+		{GameID: "game1", X: 0, Y: 0, TerrainType: "GRASSLAND", OwnerID: &p1},
+		{GameID: "game1", X: 1, Y: 0, TerrainType: "FOREST", VisibleTo: []string{"p1", "p2"}},
+		{GameID: "game1", X: 2, Y: 0, TerrainType: "HILLS"},
+	}
+
+	if err := engine.processExploration(context.Background(), game); err != nil {
+		t.Fatalf("processExploration failed: %v", err)
+	}
+	if len(repo.exploredTiles) != 3 {
+		t.Fatalf("Expected owned and visible tiles recorded for each viewer (3 records), got %d", len(repo.exploredTiles))
+	}
+
+	// The forest is cleared while p2 still sees it, then drops out of sight
+	game.CurrentYear++
+	repo.mapTiles["game1"][1].TerrainType = "PLAINS"
+	if err := engine.processExploration(context.Background(), game); err != nil {
+		t.Fatalf("processExploration failed: %v", err)
+	}
+	repo.mapTiles["game1"][1].VisibleTo = []string{"p1"}
+	repo.mapTiles["game1"][1].Improvements = []string{"FARM"}
+	game.CurrentYear++
+	if err := engine.processExploration(context.Background(), game); err != nil {
+		t.Fatalf("processExploration failed: %v", err)
+	}
+
+	for _, record := range repo.exploredTiles {
+		if record.X != 1 {
+			if record.SeenYear != -4990 {
+				t.Errorf("Unchanged tile (%d, %d) should not be rewritten, seen year %d", record.X, record.Y, record.SeenYear)
+			}
+			continue
+		}
+		switch record.PlayerID {
+		case "p1":
+			if len(record.Improvements) != 1 || record.SeenYear != -4988 {
+				t.Errorf("p1 still sees the tile and should have the live farm, got %+v", record)
+			}
+		case "p2":
+			if record.TerrainType != "PLAINS" || len(record.Improvements) != 0 || record.SeenYear != -4989 {
+				t.Errorf("p2 should keep the last-seen state from year -4989, got %+v", record)
+			}
+		}
+	}
+}
+
+func TestBuildMinimap(t *testing.T) {
+	game := &models.Game{GameID: "game1", PlayerList: []string{"p1", "p2"}, CurrentYear: -4000}
+	metadata := &models.MapMetadata{GameID: "game1", Width: 128, Height: 4}
+
+	p2 := "p2"
+	var tiles []*models.MapTile
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 128; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"}
+			if x < 2 {
+				tile.VisibleTo = []string{"p1"}
+			}
+			if x == 2 {
+				tile.TerrainType = "FOREST"
+				tile.OwnerID = &p2
+			}
+			tiles = append(tiles, tile)
+		}
+	}
+	// p1 once saw the cell at x=2..3 as ocean
+	explored := []*models.ExploredTile{
+		{GameID: "game1", PlayerID: "p1", X: 2, Y: 0, TerrainType: "OCEAN"},
+		{GameID: "game1", PlayerID: "p1", X: 3, Y: 0, TerrainType: "OCEAN"},
+	}
+
+	minimap := buildMinimap(game, metadata, tiles, explored, "p1")
+	if minimap.Width != 64 || minimap.Height != 4 {
+		t.Fatalf("Expected a 64x4 minimap, got %dx%d", minimap.Width, minimap.Height)
+	}
+	if len(minimap.Terrain) != 256 || len(minimap.Owners) != 256 {
+		t.Fatalf("Expected 256 cells per layer, got %d and %d", len(minimap.Terrain), len(minimap.Owners))
+	}
+	if minimap.Terrain[0] != 'G' || minimap.Owners[0] != models.MinimapUnowned {
+		t.Errorf("Visible cell should be unowned grassland, got %c/%c", minimap.Terrain[0], minimap.Owners[0])
+	}
+	if minimap.Terrain[1] != 'O' {
+		t.Errorf("Explored cell should show the last-seen ocean, got %c", minimap.Terrain[1])
+	}
+	if minimap.Terrain[2] != models.MinimapUnknown || minimap.Owners[2] != models.MinimapUnknown {
+		t.Errorf("Unexplored cell should be unknown, got %c/%c", minimap.Terrain[2], minimap.Owners[2])
+	}
+
+	// p2 owns the forest column and sees it live
+	minimap = buildMinimap(game, metadata, tiles, nil, "p2")
+	if minimap.Terrain[1] != 'F' || minimap.Owners[1] != '1' {
+		t.Errorf("Owner sees its territory as forest owned by player index 1, got %c/%c", minimap.Terrain[1], minimap.Owners[1])
+	}
+	if minimap.Legend["F"] != "FOREST" {
+		t.Errorf("Legend should map F to FOREST, got %q", minimap.Legend["F"])
+	}
+}
+
+func TestBuildMinimap_OwnerCodes(t *testing.T) {
+	game := &models.Game{GameID: "game1", CurrentYear: -4000}
+	for i := 0; i < 16; i++ {
+		game.PlayerList = append(game.PlayerList, fmt.Sprintf("p%d", i))
+	}
+	metadata := &models.MapMetadata{GameID: "game1", Width: 18, Height: 1}
+
+	// Each player owns one tile, the minor civ the next and the last is unowned
+	var tiles []*models.MapTile
+	for x := 0; x < 18; x++ {
+		tile := &models.MapTile{GameID: "game1", X: x, Y: 0, TerrainType: "GRASSLAND", VisibleTo: []string{"p0"}}
+		switch {
+		case x < 16:
+			tile.OwnerID = &game.PlayerList[x]
+		case x == 16:
+			minor := models.MinorCivPrefix + "m1"
+			tile.OwnerID = &minor
+		}
+		tiles = append(tiles, tile)
+	}
+
+	minimap := buildMinimap(game, metadata, tiles, nil, "p0")
+	if want := "0123456789ABCDEF*."; minimap.Owners != want {
+		t.Errorf("Expected distinct owner codes %q, got %q", want, minimap.Owners)
+	}
+}
+
+func TestBuildBorders(t *testing.T) {
+	game := &models.Game{GameID: "game1", PlayerList: []string{"p1", "p2", "p3"}, CurrentYear: -4000}
+	metadata := &models.MapMetadata{GameID: "game1", Width: 6, Height: 4}
+
+	// p1 holds a 2x2 block, p2 the tile east of it and the map's top-right corner
+	p1, p2 := "p1", "p2"
+	owners := map[models.Location]*string{
+		{X: 1, Y: 1}: &p1, {X: 2, Y: 1}: &p1, {X: 1, Y: 2}: &p1, {X: 2, Y: 2}: &p1,
+		{X: 3, Y: 1}: &p2, {X: 5, Y: 0}: &p2,
+	}
+	var tiles []*models.MapTile
+	var explored []*models.ExploredTile
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			owner := owners[models.Location{X: x, Y: y}]
+			tiles = append(tiles, &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND", OwnerID: owner, VisibleTo: []string{"p1"}})
+			// p3 has only explored the two westernmost columns
+			if x <= 1 {
+				explored = append(explored, &models.ExploredTile{GameID: "game1", PlayerID: "p3", X: x, Y: y, TerrainType: "GRASSLAND", OwnerID: owner})
+			}
+		}
+	}
+	loop := func(corners ...[2]int) []models.Location {
+		var line []models.Location
+		for _, c := range corners {
+			line = append(line, models.Location{X: c[0], Y: c[1]})
+		}
+		return line
+	}
+
+	borders := buildBorders(game, metadata, tiles, nil, "p1")
+	want := []models.TerritoryBorder{
+		{OwnerID: "p1", Lines: [][]models.Location{loop([2]int{1, 1}, [2]int{3, 1}, [2]int{3, 3}, [2]int{1, 3}, [2]int{1, 1})}},
+		{OwnerID: "p2", Lines: [][]models.Location{
+			loop([2]int{5, 0}, [2]int{6, 0}, [2]int{6, 1}, [2]int{5, 1}, [2]int{5, 0}),
+			loop([2]int{3, 1}, [2]int{4, 1}, [2]int{4, 2}, [2]int{3, 2}, [2]int{3, 1}),
+		}},
+	}
+	if !reflect.DeepEqual(borders.Territories, want) {
+		t.Errorf("Expected outlines %v, got %v", want, borders.Territories)
+	}
+
+	// Borders stop where the player's knowledge ends
+	borders = buildBorders(game, metadata, tiles, explored, "p3")
+	want = []models.TerritoryBorder{
+		{OwnerID: "p1", Lines: [][]models.Location{loop([2]int{2, 3}, [2]int{1, 3}, [2]int{1, 1}, [2]int{2, 1})}},
+	}
+	if !reflect.DeepEqual(borders.Territories, want) {
+		t.Errorf("Expected the explored part of p1's outline %v, got %v", want, borders.Territories)
+	}
+}
+
+func TestGameEngine_TileModifiedTick(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4321}
+	repo.games["game1"] = game
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND", LastModifiedTick: -5000})
+		}
+	}
+	unit := &models.Unit{UnitID: "u1", GameID: "game1", PlayerID: "p1", UnitType: "settlers", Location: models.Location{X: 2, Y: 2}, PopulationCost: 100}
+	repo.units = []*models.Unit{unit}
+
+	if err := engine.settleAtLocation(context.Background(), game, unit); err != nil {
+		t.Fatalf("settleAtLocation failed: %v", err)
+	}
+
+	for _, tile := range repo.mapTiles["game1"] {
+		claimed := abs(tile.X-2) <= 1 && abs(tile.Y-2) <= 1
+		if claimed && tile.LastModifiedTick != -4321 {
+			t.Errorf("Claimed tile (%d, %d) should be stamped with the current tick, got %d", tile.X, tile.Y, tile.LastModifiedTick)
+		}
+		if !claimed && tile.LastModifiedTick != -5000 {
+			t.Errorf("Untouched tile (%d, %d) should keep its tick, got %d", tile.X, tile.Y, tile.LastModifiedTick)
+		}
+	}
+}
+
+func TestGameEngine_WorkerAutomation(t *testing.T) {
+	setup := func(mode string) (*MockRepository, *GameEngine, *models.Game) {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+		game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+		repo.games["game1"] = game
+
+		p1 := "p1"
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 6; x++ {
+				tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "PLAINS"}
+				if x == 3 && y == 1 {
+					tile.TerrainType = "GRASSLAND"
+					tile.HasRiver = true
+				}
+				if x == 1 && y == 1 {
+					tile.TerrainType = "HILLS"
+				}
+				if x <= 3 {
+					tile.OwnerID = &p1
+				}
+				repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+			}
+		}
+		repo.settlements = []*models.Settlement{
+			{SettlementID: "a", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 0, Y: 0}},
+			{SettlementID: "b", GameID: "game1", PlayerID: "p1", Location: models.Location{X: 5, Y: 2}},
+		}
+		repo.units = []*models.Unit{{UnitID: "w1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 1, Y: 0}, Automation: mode}}
+		return repo, engine, game
+	}
+	tileAt := func(repo *MockRepository, x, y int) *models.MapTile {
+		tile, _ := repo.GetMapTile(context.Background(), "game1", x, y)
+		return tile
+	}
+	run := func(engine *GameEngine, game *models.Game, ticks int) {
+		for i := 0; i < ticks; i++ {
+			if err := engine.processWorkers(context.Background(), game); err != nil {
+				t.Fatalf("processWorkers failed: %v", err)
+			}
+		}
+	}
+
+	t.Run("improve nearest", func(t *testing.T) {
+		repo, engine, game := setup(models.AutomationImproveNearest)
+		run(engine, game, 1)
+		if !containsString(tileAt(repo, 1, 0).Improvements, models.ImprovementFarm) {
+			t.Error("Expected a farm on the worker's own tile first")
+		}
+		run(engine, game, 2)
+		if !containsString(tileAt(repo, 0, 0).Improvements, models.ImprovementFarm) {
+			t.Errorf("Expected the nearest tile (0, 0) farmed next, worker at %+v", repo.units[0].Location)
+		}
+	})
+
+	t.Run("focus food", func(t *testing.T) {
+		repo, engine, game := setup(models.AutomationFocusFood)
+		run(engine, game, 4)
+		if !containsString(tileAt(repo, 3, 1).Improvements, models.ImprovementFarm) {
+			t.Errorf("Expected the river grassland farmed first, worker at %+v", repo.units[0].Location)
+		}
+		if len(tileAt(repo, 1, 1).Improvements) != 0 {
+			t.Error("Focus food should never mine hills")
+		}
+	})
+
+	t.Run("pastures", func(t *testing.T) {
+		repo, engine, game := setup(models.AutomationImproveNearest)
+		tileAt(repo, 1, 0).Resources = []string{"CATTLE"}
+		tileAt(repo, 0, 0).Resources = []string{"GAME"}
+		run(engine, game, 1)
+		if !containsString(tileAt(repo, 1, 0).Improvements, models.ImprovementFarm) {
+			t.Error("Expected herds farmed over until husbandry is known")
+		}
+		repo.settlements[1].Technologies = []string{simulator.TechFireMastery, simulator.TechDomestication, simulator.TechHusbandry}
+		run(engine, game, 2)
+		if !containsString(tileAt(repo, 0, 0).Improvements, models.ImprovementPasture) {
+			t.Errorf("Expected a pasture on the game tile, got %v", tileAt(repo, 0, 0).Improvements)
+		}
+	})
+
+	t.Run("connect cities", func(t *testing.T) {
+		repo, engine, game := setup(models.AutomationConnectCities)
+		run(engine, game, 20)
+		for _, loc := range roadPath(models.Location{X: 0, Y: 0}, models.Location{X: 5, Y: 2}) {
+			if !containsString(tileAt(repo, loc.X, loc.Y).Improvements, models.ImprovementRoad) {
+				t.Errorf("Expected a road at (%d, %d)", loc.X, loc.Y)
+			}
+		}
+	})
+}
+
+func TestGameEngine_Governor(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4900, PlayerList: []string{"p1", "p2"}, GovernorAfterTicks: 50}
+	repo.games["game1"] = game
+	repo.playerActivity = []*models.PlayerActivity{
+		{GameID: "game1", PlayerID: "p1", LastActiveTick: -4920},
+		{GameID: "game1", PlayerID: "p2", LastActiveTick: -4960},
+	}
+	p1, p2 := "p1", "p2"
+	repo.mapTiles["game1"] = []*models.MapTile{
+		{GameID: "game1", X: 0, Y: 0, TerrainType: "PLAINS", OwnerID: &p1},
+		{GameID: "game1", X: 1, Y: 0, TerrainType: "PLAINS", OwnerID: &p2},
+	}
+	repo.units = []*models.Unit{
+		{UnitID: "w1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 0, Y: 0}},
+		{UnitID: "w2", GameID: "game1", PlayerID: "p2", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 1, Y: 0}},
+	}
+
+	if err := engine.processGovernor(context.Background(), game); err != nil {
+		t.Fatalf("processGovernor failed: %v", err)
+	}
+	if engine.Governed("game1", "p1") {
+		t.Error("Expected p1, active 20 ticks ago, to keep control")
+	}
+	if !engine.Governed("game1", "p2") {
+		t.Error("Expected p2, absent 60 ticks, to be governed")
+	}
+
+	if err := engine.processWorkers(context.Background(), game); err != nil {
+		t.Fatalf("processWorkers failed: %v", err)
+	}
+	if tile, _ := repo.GetMapTile(context.Background(), "game1", 0, 0); len(tile.Improvements) != 0 {
+		t.Errorf("Expected the active player's idle worker to stay idle, got %v", tile.Improvements)
+	}
+	if tile, _ := repo.GetMapTile(context.Background(), "game1", 1, 0); !containsString(tile.Improvements, models.ImprovementFarm) {
+		t.Errorf("Expected the governed worker to farm its tile, got %v", tile.Improvements)
+	}
+	if repo.units[1].Automation != "" {
+		t.Error("Expected the governor not to persist an automation mode")
+	}
+
+	// p2 reconnects and takes back control
+	repo.playerActivity[1].LastActiveTick = -4900
+	if err := engine.processGovernor(context.Background(), game); err != nil {
+		t.Fatalf("processGovernor failed: %v", err)
+	}
+	if engine.Governed("game1", "p2") {
+		t.Error("Expected p2 to regain control after reconnecting")
+	}
+}
+
+func TestGameEngine_PlayerSettings(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("governor thresholds", func(t *testing.T) {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+		game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4900, PlayerList: []string{"p1", "p2", "p3"}, GovernorAfterTicks: 50}
+		repo.games["game1"] = game
+		repo.playerActivity = []*models.PlayerActivity{
+			{GameID: "game1", PlayerID: "p1", LastActiveTick: -4920},
+			{GameID: "game1", PlayerID: "p2", LastActiveTick: -4960},
+			{GameID: "game1", PlayerID: "p3", LastActiveTick: -4960},
+		}
+		repo.playerSettings = []*models.PlayerSettings{
+			{GameID: "game1", PlayerID: "p1", GovernorAfterTicks: 10},
+			{GameID: "game1", PlayerID: "p2", GovernorOff: true},
+		}
+
+		if err := engine.processGovernor(ctx, game); err != nil {
+			t.Fatalf("processGovernor failed: %v", err)
+		}
+		if !engine.Governed("game1", "p1") {
+			t.Error("Expected p1, absent 20 ticks with a threshold of 10, to be governed")
+		}
+		if engine.Governed("game1", "p2") {
+			t.Error("Expected p2, who turned the governor off, to keep control")
+		}
+		if !engine.Governed("game1", "p3") {
+			t.Error("Expected p3, with no settings, to be governed at the game's threshold")
+		}
+	})
+
+	t.Run("auto-end-turn settles and approves proposals", func(t *testing.T) {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+		started := time.Now()
+		game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990, StartedAt: &started, LastTickAt: &started,
+			Seeds: models.NewGameSeeds("settings-seed"), StartMode: models.StartModePlayer}
+		repo.games["game1"] = game
+		repo.mapMetadata["game1"] = &models.MapMetadata{GameID: "game1", Width: 10, Height: 10}
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"})
+			}
+		}
+		repo.units = []*models.Unit{{UnitID: "u1", GameID: "game1", PlayerID: "p1", UnitType: "settlers", Location: models.Location{X: 5, Y: 5}, PopulationCost: 100}}
+		repo.playerSettings = []*models.PlayerSettings{{GameID: "game1", PlayerID: "p1", AutoEndTurn: true}}
+
+		// The settle deadline is far off, but the player does not wait for it
+		if err := engine.processGameTick(ctx, game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+		if len(repo.settlements) != 1 {
+			t.Fatalf("Expected the settlers to settle at once, got %d settlements", len(repo.settlements))
+		}
+
+		village := repo.settlements[0]
+		village.Type = models.SettlementTypeVillage
+		village.Population = 100
+		for i := 0; i < 5; i++ {
+			if err := engine.processDesires(ctx, game); err != nil {
+				t.Fatalf("processDesires failed: %v", err)
+			}
+			game.CurrentYear++
+		}
+		if len(repo.orders) == 0 {
+			t.Fatal("Expected the village's people to propose buildings")
+		}
+		for _, order := range repo.orders {
+			if order.Status != models.OrderStatusPending {
+				t.Errorf("Expected %s to be approved at once, got %s", order.Item, order.Status)
+			}
+		}
+	})
+}
+
+func TestGameEngine_LateJoin(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Persistent: true, PlayerList: []string{"p1", "p2"}}
+	repo.games["game1"] = game
+	repo.mapMetadata["game1"] = &models.MapMetadata{GameID: "game1", Width: 40, Height: 40}
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND", HasRiver: true})
+		}
+	}
+	repo.startingPositions["game1"] = []*models.StartingPosition{{GameID: "game1", PlayerID: "p1", CenterX: 7, CenterY: 7}}
+
+	if err := engine.processLateJoins(context.Background(), game); err != nil {
+		t.Fatalf("processLateJoins failed: %v", err)
+	}
+
+	position, _ := repo.GetStartingPosition(context.Background(), "game1", "p2")
+	if position == nil {
+		t.Fatal("Expected the late joiner to get a starting position")
+	}
+	if position.CenterX == 7 && position.CenterY == 7 {
+		t.Error("Expected the late joiner away from the existing civ")
+	}
+
+	// 1000 years in: two era boosts on top of the usual settlers
+	settlers, workers := 0, 0
+	for _, unit := range repo.units {
+		if unit.PlayerID != "p2" {
+			continue
+		}
+		switch unit.UnitType {
+		case models.UnitTypeSettlers:
+			settlers++
+		case models.UnitTypeWorkers:
+			workers++
+		}
+	}
+	if settlers != 3 || workers != 2 {
+		t.Errorf("Expected 3 settlers and 2 workers, got %d and %d", settlers, workers)
+	}
+
+	center, _ := repo.GetMapTile(context.Background(), "game1", position.CenterX, position.CenterY)
+	if !containsString(center.VisibleTo, "p2") {
+		t.Error("Expected the late joiner's region to be revealed")
+	}
+	if center.LastModifiedTick != game.CurrentYear {
+		t.Errorf("Expected revealed tiles stamped with year %d, got %d", game.CurrentYear, center.LastModifiedTick)
+	}
+
+	// Nothing more happens until another player joins
+	if err := engine.processLateJoins(context.Background(), game); err != nil {
+		t.Fatalf("processLateJoins failed: %v", err)
+	}
+	if len(repo.units) != 5 {
+		t.Errorf("Expected no further units, got %d", len(repo.units))
+	}
+}
+
+func TestBoostSettlerSites(t *testing.T) {
+	// The city sits on the east edge of a strip of coast, ocean to the west
+	metadata := &models.MapMetadata{GameID: "game1", Width: 12, Height: 12}
+	var tiles []*models.MapTile
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			terrainType := terrain.Ocean
+			if x >= 9 {
+				terrainType = terrain.Grassland
+			}
+			tiles = append(tiles, &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: terrainType})
+		}
+	}
+	position := &models.StartingPosition{GameID: "game1", PlayerID: "p2", CenterX: 11, CenterY: 6}
+	position.GuaranteedFootprint.MinX, position.GuaranteedFootprint.MaxX = 0, 11
+	position.GuaranteedFootprint.MinY, position.GuaranteedFootprint.MaxY = 0, 11
+	city := models.Location{X: 11, Y: 6}
+
+	sites := boostSettlerSites(metadata, tiles, position, city, 3)
+	if len(sites) != 3 {
+		t.Fatalf("Expected 3 sites, got %v", sites)
+	}
+	taken := []models.Location{city}
+	for _, site := range sites {
+		if site.X < 9 || site.X >= 12 || site.Y < 0 || site.Y >= 12 {
+			t.Errorf("Expected settlers on land on the map, got %+v", site)
+		}
+		for _, other := range taken {
+			if max(abs(site.X-other.X), abs(site.Y-other.Y)) < boostSettlerSpacing {
+				t.Errorf("Expected %+v at least %d tiles from %+v", site, boostSettlerSpacing, other)
+			}
+		}
+		taken = append(taken, site)
+	}
+
+	// With no free land left the extra settlers start at the city
+	for _, tile := range tiles {
+		tile.TerrainType = terrain.Ocean
+	}
+	for _, site := range boostSettlerSites(metadata, tiles, position, city, 2) {
+		if site != city {
+			t.Errorf("Expected settlers without land to start at the city, got %+v", site)
+		}
+	}
+}
+
+func TestGameEngine_NoShows(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	repo.games["game1"] = &models.Game{
+		GameID: "game1", State: "started", CurrentYear: -4990, Persistent: true,
+		PlayerList: []string{"p1", "p2"}, CurrentPlayers: 2,
+		NoShowGraceTicks: 10, NoShowPolicy: models.NoShowPolicyRelease,
+	}
+	game := *repo.games["game1"]
+	repo.startingPositions["game1"] = []*models.StartingPosition{
+		{GameID: "game1", PlayerID: "p1", AssignedYear: -5000},
+		{GameID: "game1", PlayerID: "p2", AssignedYear: -5000},
+	}
+	p2 := "p2"
+	repo.mapTiles["game1"] = []*models.MapTile{{GameID: "game1", X: 0, Y: 0, TerrainType: "PLAINS", OwnerID: &p2, SettlementID: "s2", VisibleTo: []string{"p1", "p2"}}}
+	repo.settlements = []*models.Settlement{{SettlementID: "s2", GameID: "game1", PlayerID: "p2"}}
+	repo.units = []*models.Unit{
+		{UnitID: "u1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeSettlers},
+		{UnitID: "u2", GameID: "game1", PlayerID: "p2", UnitType: models.UnitTypeSettlers},
+	}
+	repo.playerActivity = []*models.PlayerActivity{{GameID: "game1", PlayerID: "p1", LastActiveTick: -4999}}
+	repo.borders["p2"] = &models.Borders{GameID: "game1", PlayerID: "p2"}
+	repo.settlementSims = map[string]*models.SettlementSimulation{"s2": {SettlementID: "s2", GameID: "game1"}}
+
+	// p2 never connected within the grace period
+	if err := engine.processNoShows(context.Background(), &game); err != nil {
+		t.Fatalf("processNoShows failed: %v", err)
+	}
+	if len(game.PlayerList) != 1 || game.PlayerList[0] != "p1" || game.CurrentPlayers != 1 {
+		t.Fatalf("Expected only p1 left, got %v (%d players)", game.PlayerList, game.CurrentPlayers)
+	}
+	if len(repo.units) != 1 || len(repo.settlements) != 0 {
+		t.Errorf("Expected p2's units and settlements removed, got %d and %d", len(repo.units), len(repo.settlements))
+	}
+	if position, _ := repo.GetStartingPosition(context.Background(), "game1", "p2"); position != nil {
+		t.Error("Expected p2's region freed")
+	}
+	if tile := repo.mapTiles["game1"][0]; tile.OwnerID != nil || containsString(tile.VisibleTo, "p2") {
+		t.Error("Expected p2's tiles and visibility released")
+	}
+	if _, ok := repo.borders["p2"]; ok {
+		t.Error("Expected p2's borders removed")
+	}
+	if _, ok := repo.settlementSims["s2"]; ok {
+		t.Error("Expected p2's saved settlement simulations removed")
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != models.EventPlayerReleased {
+		t.Errorf("Expected a player_released event, got %+v", repo.events)
+	}
+
+	// Under the governor policy, no-shows are played once the grace runs out
+	governed := &models.Game{GameID: "game2", CurrentYear: -4990, PlayerList: []string{"p1", "p2"}, NoShowGraceTicks: 10}
+	absent := absentPlayers(governed, []*models.PlayerActivity{{GameID: "game2", PlayerID: "p1", LastActiveTick: -5000}}, nil)
+	if !absent["p2"] || absent["p1"] {
+		t.Errorf("Expected only the no-show governed, got %v", absent)
+	}
+}
+
+func TestGameEngine_Elimination(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4500, PlayerList: []string{"p1", "p2"}}
+	repo.games["game1"] = game
+	repo.startingPositions["game1"] = []*models.StartingPosition{
+		{GameID: "game1", PlayerID: "p1"},
+		{GameID: "game1", PlayerID: "p2"},
+	}
+	p1 := "p1"
+	repo.mapTiles["game1"] = []*models.MapTile{{GameID: "game1", X: 0, Y: 0, TerrainType: "PLAINS", OwnerID: &p1, SettlementID: "s1", VisibleTo: []string{"p1", "p2"}}}
+	repo.settlements = []*models.Settlement{{SettlementID: "s1", GameID: "game1", PlayerID: "p1"}}
+	repo.units = []*models.Unit{{UnitID: "w1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers}}
+
+	// p2 has lost everything
+	if err := engine.processElimination(context.Background(), game); err != nil {
+		t.Fatalf("processElimination failed: %v", err)
+	}
+	if !game.IsEliminated("p2") || game.IsEliminated("p1") {
+		t.Fatalf("Expected only p2 eliminated, got %v", game.EliminatedPlayers)
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != models.EventPlayerEliminated || repo.events[0].PlayerID != "p2" {
+		t.Errorf("Expected a player_eliminated event for p2, got %+v", repo.events)
+	}
+	tile := repo.mapTiles["game1"][0]
+	if containsString(tile.VisibleTo, "p2") {
+		t.Error("Expected p2's visibility released")
+	}
+
+	// p1 surrenders; a later order from them is rejected
+	repo.orders = []*models.Order{
+		{OrderID: "o1", GameID: "game1", PlayerID: "p1", OrderType: models.OrderTypeSurrender, Status: models.OrderStatusPending},
+		{OrderID: "o2", GameID: "game1", PlayerID: "p1", UnitID: "w1", OrderType: models.OrderTypeSettle, Status: models.OrderStatusPending},
+	}
+	if err := engine.processOrders(context.Background(), game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if repo.orders[0].Status != models.OrderStatusExecuted || repo.orders[1].Status != models.OrderStatusRejected {
+		t.Errorf("Expected surrender executed and later order rejected, got %s and %s", repo.orders[0].Status, repo.orders[1].Status)
+	}
+	if tile.OwnerID != nil || tile.SettlementID != "" || tile.LastModifiedTick != -4500 {
+		t.Errorf("Expected p1's territory released, got %+v", tile)
+	}
+	if repo.settlements[0].PlayerID != models.NeutralPlayerID {
+		t.Errorf("Expected p1's settlement to go neutral, got %q", repo.settlements[0].PlayerID)
+	}
+	if len(repo.units) != 0 {
+		t.Errorf("Expected p1's units disbanded, got %d", len(repo.units))
+	}
+	if repo.events[1].Type != models.EventPlayerSurrendered {
+		t.Errorf("Expected a player_surrendered event, got %s", repo.events[1].Type)
+	}
+	if game.ShouldTick() {
+		t.Error("Expected a game with no players left not to tick")
+	}
+}
+
+func TestGameEngine_Teams(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{
+		GameID: "game1", State: "started", CurrentYear: -4500,
+		PlayerList: []string{"p1", "p2", "p3"},
+		TeamCount:  2,
+		Teams:      map[string]int{"p1": 0, "p2": 0, "p3": 1},
+	}
+	repo.games["game1"] = game
+	p1 := "p1"
+	repo.mapTiles["game1"] = []*models.MapTile{
+		{GameID: "game1", X: 0, Y: 0, TerrainType: "PLAINS", OwnerID: &p1},
+		{GameID: "game1", X: 1, Y: 0, TerrainType: "PLAINS", VisibleTo: []string{"p3"}},
+	}
+
+	// Teammates share their fog of war
+	if err := engine.processExploration(context.Background(), game); err != nil {
+		t.Fatalf("processExploration failed: %v", err)
+	}
+	type view struct {
+		playerID string
+		x        int
+	}
+	seen := make(map[view]bool)
+	for _, record := range repo.exploredTiles {
+		seen[view{record.PlayerID, record.X}] = true
+	}
+	if !seen[view{"p2", 0}] || seen[view{"p3", 0}] || seen[view{"p2", 1}] {
+		t.Errorf("Expected p2 to share p1's view only, got %v", seen)
+	}
+
+	// Non-aggression: no settling inside a teammate's territory
+	repo.units = []*models.Unit{{UnitID: "s2", GameID: "game1", PlayerID: "p2", UnitType: models.UnitTypeSettlers, Location: models.Location{X: 1, Y: 0}}}
+	err := engine.executeSettleOrder(context.Background(), game, &models.Order{PlayerID: "p2", UnitID: "s2", Target: &models.Location{X: 0, Y: 0}}, repo.units[0])
+	if !errors.Is(err, ErrNonAggression) {
+		t.Errorf("Expected ErrNonAggression, got %v", err)
+	}
+
+	// The team wins once its only opponent is out, eliminated members included
+	game.EliminatedPlayers = []string{"p2"}
+	if err := engine.processVictory(context.Background(), game); err != nil {
+		t.Fatalf("processVictory failed: %v", err)
+	}
+	if game.IsEnded() {
+		t.Fatal("Expected the game to continue while both teams have players")
+	}
+	game.EliminatedPlayers = append(game.EliminatedPlayers, "p3")
+	if err := engine.processVictory(context.Background(), game); err != nil {
+		t.Fatalf("processVictory failed: %v", err)
+	}
+	if !game.IsEnded() || len(game.Winners) != 2 || game.Winners[0] != "p1" || game.Winners[1] != "p2" {
+		t.Errorf("Expected team 0 (p1, p2) to win, got state %s winners %v", game.State, game.Winners)
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != models.EventVictory {
+		t.Errorf("Expected a victory event, got %+v", repo.events)
+	}
+}
+
+func TestGameEngine_CatchUp(t *testing.T) {
+	ctx := context.Background()
+	newGame := func() (*MockRepository, *models.Game) {
+		repo := NewMockRepository()
+		lastTick := time.Now().Add(-30 * time.Second)
+		game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, LastTickAt: &lastTick}
+		repo.games["game1"] = game
+		return repo, game
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		repo, game := newGame()
+		engine := NewGameEngine(repo)
+		engine.SetCatchUpMode(CatchUpReplay)
+
+		// The regular tick runs and the 29 missed ticks are queued
+		if err := engine.processTick(ctx); err != nil {
+			t.Fatalf("processTick failed: %v", err)
+		}
+		if game.CurrentYear != -3999 || engine.catchUp["game1"] != 29 {
+			t.Fatalf("Expected year -3999 with 29 ticks queued, got %d with %d", game.CurrentYear, engine.catchUp["game1"])
+		}
+
+		// Replayed in batches of catchUpBatchTicks per pass
+		if err := engine.processTick(ctx); err != nil {
+			t.Fatalf("processTick failed: %v", err)
+		}
+		if game.CurrentYear != -3999+catchUpBatchTicks {
+			t.Errorf("Expected one batch replayed, got year %d", game.CurrentYear)
+		}
+		for i := 0; i < 2; i++ {
+			if err := engine.processTick(ctx); err != nil {
+				t.Fatalf("processTick failed: %v", err)
+			}
+		}
+		if game.CurrentYear != -3970 || len(engine.catchUp) != 0 {
+			t.Errorf("Expected to be caught up at year -3970, got %d with %v queued", game.CurrentYear, engine.catchUp)
+		}
+	})
+
+	t.Run("adjust", func(t *testing.T) {
+		repo, game := newGame()
+		engine := NewGameEngine(repo)
+		engine.SetCatchUpMode(CatchUpAdjust)
+
+		if err := engine.processTick(ctx); err != nil {
+			t.Fatalf("processTick failed: %v", err)
+		}
+		if game.CurrentYear != -3999 || len(engine.catchUp) != 0 {
+			t.Errorf("Expected a single tick and nothing queued, got year %d", game.CurrentYear)
+		}
+		if len(game.ClockAdjustments) != 1 || game.ClockAdjustments[0].SkippedTicks != 29 {
+			t.Errorf("Expected a clock adjustment of 29 ticks, got %+v", game.ClockAdjustments)
+		}
+	})
+
+	t.Run("scheduled pause is not downtime", func(t *testing.T) {
+		_, game := newGame()
+		now := time.Date(2024, 1, 8, 12, 0, 0, 0, time.Local)
+		lastTick := now.Add(-2 * time.Hour)
+		game.LastTickAt = &lastTick
+		game.Schedule = &models.TickSchedule{WindowStart: "08:00", WindowEnd: "10:30"}
+		if missed := missedTicks(game, now); missed != 30*60-1 {
+			t.Errorf("Expected only the scheduled half hour missed, got %d ticks", missed)
+		}
+	})
+}
+
+// pingableRepository is a MockRepository that reports store health
+type pingableRepository struct {
+	*MockRepository
+	pingErr error
+}
+
+func (p *pingableRepository) Ping(ctx context.Context) error {
+	return p.pingErr
+}
+
+func TestGameEngine_DegradedMode(t *testing.T) {
+	repo := &pingableRepository{MockRepository: NewMockRepository()}
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	if engine.Degraded() || !engine.storeReady(ctx) {
+		t.Fatal("Expected a fresh engine to be ready")
+	}
+
+	engine.recordStoreFailure(errors.New("connection refused"))
+	if !engine.Degraded() || engine.storeReady(ctx) {
+		t.Fatal("Expected the engine to wait out its backoff after a failure")
+	}
+	firstDelay := time.Until(engine.nextStoreAttempt)
+
+	// The store is still down when the backoff expires: back off longer
+	repo.pingErr = errors.New("connection refused")
+	engine.nextStoreAttempt = time.Now().Add(-time.Millisecond)
+	if engine.storeReady(ctx) {
+		t.Fatal("Expected a failed health check to keep the engine degraded")
+	}
+	if engine.storeFailures != 2 || time.Until(engine.nextStoreAttempt) <= firstDelay {
+		t.Errorf("Expected a longer second backoff, got %d failures and %v", engine.storeFailures, time.Until(engine.nextStoreAttempt))
+	}
+
+	// The store is back
+	repo.pingErr = nil
+	engine.nextStoreAttempt = time.Now().Add(-time.Millisecond)
+	if !engine.storeReady(ctx) {
+		t.Fatal("Expected a healthy store to let the engine resume")
+	}
+	engine.recordStoreSuccess()
+	if engine.Degraded() {
+		t.Error("Expected the engine to leave degraded mode")
+	}
+}
+
+// failingTickRepository is a MockRepository whose game ticks fail with tickErr
+type failingTickRepository struct {
+	*MockRepository
+	tickErr error
+}
+
+func (f *failingTickRepository) UpdateGameTick(ctx context.Context, gameID string, expectedYear, newYear int, tickTime time.Time) error {
+	if f.tickErr != nil && gameID == "a" {
+		return f.tickErr
+	}
+	return f.MockRepository.UpdateGameTick(ctx, gameID, expectedYear, newYear, tickTime)
+}
+
+func TestGameEngine_ForgetStoppedGames(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	for gameID, state := range map[string]models.GameState{"live": models.GameStateActive, "won": models.GameStateFinished, "old": models.GameStateArchived} {
+		repo.games[gameID] = &models.Game{GameID: gameID, State: state, CurrentYear: -4000}
+		engine.tickCosts[gameID] = &tickCost{}
+		engine.pathGraphs[gameID] = newPathGraph(nil)
+	}
+	engine.noteTrip("won", trip{})
+
+	// Games that finished or were archived since the last pass release
+	// their engine state; running games keep theirs
+	if err := engine.processTick(ctx); err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+	for _, gameID := range []string{"won", "old"} {
+		if _, ok := engine.tickCosts[gameID]; ok || engine.pathGraphs[gameID] != nil || engine.trips[gameID] != nil {
+			t.Errorf("Expected game %s's engine state to be dropped", gameID)
+		}
+	}
+	if engine.tickCosts["live"] == nil || engine.pathGraphs["live"] == nil {
+		t.Error("Expected the running game to keep its engine state")
+	}
+}
+
+func TestGameEngine_TypedErrors(t *testing.T) {
+	repo := &failingTickRepository{MockRepository: NewMockRepository()}
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	lastTick := time.Now().Add(-2 * time.Second)
+	newGames := func() {
+		for i, gameID := range []string{"a", "b"} {
+			// Game a is the more overdue, so it always ticks first
+			at := lastTick.Add(time.Duration(i) * time.Millisecond)
+			repo.games[gameID] = &models.Game{GameID: gameID, State: "started", CurrentYear: -4000, LastTickAt: &at}
+		}
+	}
+
+	// A game deleted mid-tick is forgotten; the others still tick
+	newGames()
+	engine.tickCosts["a"] = &tickCost{}
+	engine.pathGraphs["a"] = newPathGraph(nil)
+	repo.tickErr = fmt.Errorf("%w: game a", repository.ErrNotFound)
+	err := engine.processTick(ctx)
+	engine.recordPassResult(err)
+	if err != nil || engine.Degraded() {
+		t.Fatalf("Expected a missing game not to fail the pass, got %v", err)
+	}
+	if _, ok := engine.tickCosts["a"]; ok || engine.pathGraphs["a"] != nil {
+		t.Error("Expected the missing game's engine state and cached paths to be dropped")
+	}
+	if repo.games["b"].CurrentYear != -3999 {
+		t.Errorf("Expected game b to tick, got year %d", repo.games["b"].CurrentYear)
+	}
+
+	// Losing a race is retried next pass without touching the store's health
+	newGames()
+	repo.tickErr = repository.ErrConcurrentTick
+	engine.recordPassResult(engine.processTick(ctx))
+	if engine.Degraded() || repo.games["b"].CurrentYear != -3999 {
+		t.Errorf("Expected a conflict to leave other games ticking, degraded %v", engine.Degraded())
+	}
+
+	// An unreachable store abandons the pass and degrades the engine
+	newGames()
+	repo.tickErr = fmt.Errorf("%w: server selection timeout", repository.ErrUnavailable)
+	err = engine.processTick(ctx)
+	if !errors.Is(err, repository.ErrUnavailable) {
+		t.Fatalf("Expected ErrUnavailable, got %v", err)
+	}
+	engine.recordPassResult(err)
+	if !engine.Degraded() || repo.games["b"].CurrentYear != -4000 {
+		t.Errorf("Expected the pass to stop before game b and degrade, got year %d", repo.games["b"].CurrentYear)
+	}
+
+	// Repositories report a missing game as ErrNotFound
+	if _, err := repository.NewMemoryRepository().GetGame(ctx, "nope"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing game, got %v", err)
+	}
+	if err := engine.processManualTick(ctx, "nope"); err != nil {
+		t.Errorf("Expected a manual tick of a missing game to be ignored, got %v", err)
+	}
+}
+
+func TestGameEngine_TickClaim(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	repo.settlements = []*models.Settlement{{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Population: 100}}
+
+	// Another engine instance holds the year: this one writes nothing
+	other := time.Now().Add(-time.Minute)
+	repo.games["game1"].TickClaim = &models.TickClaim{Year: -4000, ClaimedAt: other}
+	if err := engine.processGameTick(ctx, game); !errors.Is(err, repository.ErrConcurrentTick) {
+		t.Fatalf("Expected the claimed tick to be skipped with ErrConcurrentTick, got %v", err)
+	}
+	if game.CurrentYear != -4000 || game.Seeds != nil || !repo.settlements[0].LastUpdated.IsZero() {
+		t.Error("Expected an instance that lost the claim to run no phase")
+	}
+
+	// A claim past its lease was abandoned and is taken over
+	repo.games["game1"].TickClaim.ClaimedAt = time.Now().Add(-tickClaimLease - time.Minute)
+	if err := engine.processGameTick(ctx, game); err != nil {
+		t.Fatalf("Expected an abandoned claim to be taken over, got %v", err)
+	}
+	if game.CurrentYear != -3999 || game.TickClaim != nil {
+		t.Errorf("Expected the tick to advance the year and release its claim, got year %d claim %+v", game.CurrentYear, game.TickClaim)
+	}
+
+	// An instance still on the old year cannot claim it
+	stale := *game
+	stale.CurrentYear = -4000
+	if err := engine.processGameTick(ctx, &stale); !errors.Is(err, repository.ErrConcurrentTick) {
+		t.Errorf("Expected a tick of an advanced year to be skipped, got %v", err)
+	}
+	if game.CurrentYear != -3999 {
+		t.Errorf("Expected the year not to advance twice, got %d", game.CurrentYear)
+	}
+}
+
+// hangingRepository is a MockRepository whose settlement reads for game "a"
+// hang until their context ends, as a stuck store call would
+type hangingRepository struct {
+	*MockRepository
+}
+
+func (h *hangingRepository) GetSettlements(ctx context.Context, gameID string) ([]*models.Settlement, error) {
+	if gameID == "a" {
+		<-ctx.Done()
+		return nil, fmt.Errorf("%w: %w", repository.ErrUnavailable, ctx.Err())
+	}
+	return h.MockRepository.GetSettlements(ctx, gameID)
+}
+
+func TestGameEngine_TickDeadlines(t *testing.T) {
+	repo := &hangingRepository{MockRepository: NewMockRepository()}
+	engine := NewGameEngine(repo)
+	engine.SetTickTimeout(50 * time.Millisecond)
+	ctx := context.Background()
+	lastTick := time.Now().Add(-2 * time.Second)
+	for _, gameID := range []string{"a", "b"} {
+		at := lastTick
+		repo.games[gameID] = &models.Game{GameID: gameID, State: "started", CurrentYear: -4000, LastTickAt: &at}
+	}
+
+	// A hung tick skips its remaining phases at its deadline and closes its
+	// year, neither stalling the other games nor counting against the store
+	err := engine.processTick(ctx)
+	engine.recordPassResult(err)
+	if err != nil || engine.Degraded() {
+		t.Fatalf("Expected a timed out tick not to fail the pass, got %v", err)
+	}
+	if repo.games["a"].CurrentYear != -3999 || repo.games["a"].TickClaim != nil {
+		t.Errorf("Expected the cut short tick to close game a's year once, got year %d", repo.games["a"].CurrentYear)
+	}
+	if repo.games["b"].CurrentYear != -3999 {
+		t.Errorf("Expected game b to tick, got year %d", repo.games["b"].CurrentYear)
+	}
+
+	// A cancelled tick stops before its next phase and is not saved
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = engine.processGameTick(cancelled, repo.games["b"])
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancelled tick to report context.Canceled, got %v", err)
+	}
+	if repo.games["b"].CurrentYear != -3999 {
+		t.Errorf("Expected the cancelled tick not to advance game b, got year %d", repo.games["b"].CurrentYear)
+	}
+
+	// Stopping the engine mid-pass abandons the pass
+	repo.games["b"].LastTickAt = &lastTick
+	if err := engine.processTick(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled pass to report context.Canceled, got %v", err)
+	}
+}
+
+// stallingRepository is a MockRepository whose seen-tile reads, made by the
+// exploration phase late in a tick, hang until their context ends while
+// stall is set
+type stallingRepository struct {
+	*MockRepository
+	stall bool
+}
+
+func (s *stallingRepository) GetSeenTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	if s.stall {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return s.MockRepository.GetSeenTiles(ctx, gameID)
+}
+
+func TestGameEngine_TickCutShort(t *testing.T) {
+	// Two identical games, one of whose first tick hits its deadline mid-way
+	setup := func() (*stallingRepository, *GameEngine) {
+		repo := &stallingRepository{MockRepository: NewMockRepository()}
+		engine := NewGameEngine(repo)
+		engine.SetTickTimeout(50 * time.Millisecond)
+		repo.games["game1"] = &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("cut-short")}
+		repo.settlements = []*models.Settlement{{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Population: 200}}
+		repo.mapTiles["game1"] = []*models.MapTile{{GameID: "game1", X: 0, Y: 0, TerrainType: "HILLS", Resources: []string{"IRON"},
+			ResourceQuantities: map[string]int{"IRON": 10}, Improvements: []string{"MINE"}, SettlementID: "s1"}}
+		return repo, engine
+	}
+	tick := func(repo *stallingRepository, engine *GameEngine) error {
+		game := repo.games["game1"]
+		return withTimeout(context.Background(), engine.tickTimeout, func(ctx context.Context) error { return engine.processGameTick(ctx, game) })
+	}
+
+	cut, cutEngine := setup()
+	cut.stall = true
+	if err := tick(cut, cutEngine); !errors.Is(err, errTickTimeout) {
+		t.Fatalf("Expected the stalled tick to report errTickTimeout, got %v", err)
+	}
+	if cut.games["game1"].CurrentYear != -3999 {
+		t.Fatalf("Expected the cut short tick to close its year, got %d", cut.games["game1"].CurrentYear)
+	}
+	cut.stall = false
+	if err := tick(cut, cutEngine); err != nil {
+		t.Fatalf("Expected the next tick to run in full, got %v", err)
+	}
+
+	whole, wholeEngine := setup()
+	for i := 0; i < 2; i++ {
+		if err := tick(whole, wholeEngine); err != nil {
+			t.Fatalf("tick failed: %v", err)
+		}
+	}
+
+	// Growth and depletion ran once a year in both games
+	if got, want := cut.settlements[0].Population, whole.settlements[0].Population; got != want || got == 200 {
+		t.Errorf("Expected the cut short game's population to grow as the uninterrupted one's, got %d want %d", got, want)
+	}
+	if got, want := cut.mapTiles["game1"][0].ResourceQuantities["IRON"], whole.mapTiles["game1"][0].ResourceQuantities["IRON"]; got != want || got != 8 {
+		t.Errorf("Expected two years of mining to leave 8 iron in both games, got %d and %d", got, want)
+	}
+}
+
+func TestGameEngine_Capacity(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	started := func(gameID string, sinceTick time.Duration) {
+		at := time.Now().Add(-sinceTick)
+		repo.games[gameID] = &models.Game{GameID: gameID, State: "started", CurrentYear: -4000, LastTickAt: &at}
+	}
+	started("a", 5*time.Second)
+	started("b", time.Second)
+	started("c", 3*time.Second)
+	engine.catchUp["b"] = catchUpBatchTicks + 5
+	engine.tickCosts["c"] = &tickCost{average: time.Second, overloaded: true}
+
+	// Game a runs first; c is deferred past the pass budget and b still
+	// owes ticks after its catch-up batch
+	if err := engine.processTick(ctx); err != nil {
+		t.Fatalf("processTick failed: %v", err)
+	}
+	capacity := engine.Capacity()
+	if capacity.Games != 3 || capacity.QueueDepth != 6 || capacity.BehindSchedule != 2 {
+		t.Errorf("Expected 3 games, 6 queued ticks and 2 behind, got %+v", capacity)
+	}
+	if capacity.AvgTickLatencyMs <= 0 || capacity.Degraded {
+		t.Errorf("Expected a measured latency on a healthy engine, got %+v", capacity)
+	}
+
+	// Both endpoints serve the figures
+	handler := NewQueryHandler(engine)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/capacity", nil))
+	var served Capacity
+	if err := json.NewDecoder(recorder.Body).Decode(&served); err != nil || served.QueueDepth != 6 {
+		t.Errorf("Expected /capacity to serve the queue depth, got %+v: %v", served, err)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if body := recorder.Body.String(); !strings.Contains(body, "simciv_engine_queue_depth 6\n") ||
+		!strings.Contains(body, "simciv_engine_games_behind_schedule 2\n") {
+		t.Errorf("Expected /metrics to expose the capacity gauges, got:\n%s", body)
+	}
+
+	// An outage is reported so orchestration doesn't scale on stale load
+	engine.recordPassResult(repository.ErrUnavailable)
+	if !engine.Capacity().Degraded {
+		t.Error("Expected the capacity to report the engine degraded")
+	}
+}
+
+func TestGameEngine_ResumesSettlementSimulations(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	lastTick := time.Now().Add(-2 * time.Second)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990, LastTickAt: &lastTick, Seeds: models.NewGameSeeds("resume-seed")}
+	repo.games["game1"] = game
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Name: "Camp", Population: 100},
+	}
+	for year := 0; year < 2; year++ {
+		if err := engine.processGameTick(ctx, game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+	}
+	running := engine.settlementSims["s1"].Snapshot()
+
+	// A restarted engine picks up the settlement's people where they were
+	camp := repo.settlements[0]
+	restarted := NewGameEngine(repo)
+	if resumed := restarted.settlementSimulation(ctx, game, camp).Snapshot(); !reflect.DeepEqual(resumed, running) {
+		t.Errorf("Expected the restarted engine to resume day %d, got day %d", running.State.CurrentDay, resumed.State.CurrentDay)
+	}
+
+	// A saved simulation that no longer matches its settlement is rebuilt
+	camp.Population += 40
+	rebuilt := NewGameEngine(repo)
+	if sim := rebuilt.settlementSimulation(ctx, game, camp); sim.State.CurrentDay != 0 || sim.Population() != camp.Population {
+		t.Errorf("Expected a fresh simulation of %d people, got %d on day %d", camp.Population, sim.Population(), sim.State.CurrentDay)
+	}
+}
+
+func TestGameEngine_Calendar(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	lastTick := time.Now().Add(-2 * time.Second)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -1002, LastTickAt: &lastTick, Seeds: models.NewGameSeeds("calendar-seed")}
+	repo.games["game1"] = game
+
+	// Each tick dates the game; only the year a new era begins is an event
+	for year := 0; year < 3; year++ {
+		if err := engine.processGameTick(ctx, game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+	}
+	if game.Calendar == nil || game.Calendar.Year != -999 || game.Calendar.Era != "classical" || game.Calendar.Season != "" {
+		t.Errorf("Expected the game dated year -999 in the classical era, with no day, got %+v", game.Calendar)
+	}
+	if stored := repo.games["game1"].Calendar; stored == nil || stored.Year != -999 {
+		t.Errorf("Expected the stored game dated year -999, got %+v", stored)
+	}
+	var eras []*models.GameEvent
+	for _, event := range repo.events {
+		if event.Type == models.EventNewEra {
+			eras = append(eras, event)
+		}
+	}
+	if len(eras) != 1 || eras[0].Year != -1000 || eras[0].Calendar == nil || !eras[0].Calendar.NewMillennium {
+		t.Errorf("Expected one new era event in year -1000, got %+v", eras)
+	}
+
+	// Every event the engine records carries its year's calendar
+	engine.recordCrisis(ctx, game, &models.Settlement{SettlementID: "s1", PlayerID: "p1", Name: "Ur"}, &simulator.DailyMetrics{Crisis: simulator.CrisisEpidemic})
+	if crisis := repo.events[len(repo.events)-1]; crisis.Calendar == nil || crisis.Calendar.Year != -999 || crisis.Calendar.Era != "classical" {
+		t.Errorf("Expected the crisis dated year -999, got %+v", crisis.Calendar)
+	}
+}
+
+func TestGameEngine_YearPacing(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	lastTick := time.Now().Add(-2 * time.Second)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -1050, LastTickAt: &lastTick, Seeds: models.NewGameSeeds("pacing-seed"),
+		Pacing: models.PacingEras, PlayerList: []string{"p1"}, GovernorAfterTicks: 4}
+	repo.games["game1"] = game
+
+	// Ancient ticks run 25 years, stopping on the classical era's first year
+	for _, want := range []int{-1025, -1000, -990} {
+		if err := engine.processGameTick(ctx, game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+		if game.CurrentYear != want || game.Calendar.Year != want {
+			t.Fatalf("Expected the tick to reach year %d, got %d", want, game.CurrentYear)
+		}
+	}
+	for _, event := range repo.events {
+		if event.Type == models.EventNewEra && event.Year != -1000 {
+			t.Errorf("Expected the classical era dated -1000, got %d", event.Year)
+		}
+	}
+
+	// Absence is counted in ticks, however many years they spanned
+	activity := []*models.PlayerActivity{{GameID: "game1", PlayerID: "p1", LastActiveTick: -1050}}
+	if absentPlayers(game, activity, nil)["p1"] {
+		t.Error("Expected a player absent 60 years but only 3 ticks to keep control")
+	}
+	if err := engine.processGameTick(ctx, game); err != nil {
+		t.Fatalf("processGameTick failed: %v", err)
+	}
+	if !absentPlayers(game, activity, nil)["p1"] {
+		t.Error("Expected a player absent 4 ticks to be handed to the governor")
+	}
+}
+
+func TestGameEngine_PacedTickWork(t *testing.T) {
+	// tick runs the per-year phases once under a pacing, returning the days
+	// the settlement lived and the years left on the workers' project
+	tick := func(pacing string) (*models.Game, int, int) {
+		repo := NewMockRepository()
+		engine := NewGameEngine(repo)
+		ctx := context.Background()
+		game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990, Seeds: models.NewGameSeeds("paced-seed"),
+			Pacing: pacing, SimulationFidelity: models.SimulationFidelityAggregated}
+		repo.games["game1"] = game
+		repo.settlements = []*models.Settlement{{SettlementID: "s1", GameID: "game1", Population: 100}}
+		repo.units = []*models.Unit{{UnitID: "workers1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers, Aura: 100,
+			Project: &models.Project{Transformation: terrain.TransformIrrigation, YearsLeft: 100}}}
+
+		if err := engine.processSettlementGrowth(ctx, game); err != nil {
+			t.Fatalf("processSettlementGrowth failed: %v", err)
+		}
+		if err := engine.processProjects(ctx, game); err != nil {
+			t.Fatalf("processProjects failed: %v", err)
+		}
+		if err := engine.processUnitMaintenance(ctx, game); err != nil {
+			t.Fatalf("processUnitMaintenance failed: %v", err)
+		}
+		if aura := repo.units[0].Aura; aura != 100-game.YearsPerTick() {
+			t.Errorf("Expected inspiration to wear off over %d years, got %d left", game.YearsPerTick(), aura)
+		}
+		return game, engine.settlementSims["s1"].State.CurrentDay, repo.units[0].Project.YearsLeft
+	}
+
+	// A paced tick does every year's work of the years it spans
+	constant, constantDays, constantLeft := tick(models.PacingConstant)
+	paced, pacedDays, pacedLeft := tick(models.PacingEras)
+	if constant.YearsPerTick() != 1 || paced.YearsPerTick() <= 1 {
+		t.Fatalf("Expected a one-year constant tick and a longer paced tick, got %d and %d", constant.YearsPerTick(), paced.YearsPerTick())
+	}
+	if constantDays != simulator.DaysPerYear || pacedDays != simulator.DaysPerYear*paced.YearsPerTick() {
+		t.Errorf("Expected %d and %d simulated days, got %d and %d",
+			simulator.DaysPerYear, simulator.DaysPerYear*paced.YearsPerTick(), constantDays, pacedDays)
+	}
+	if constantLeft != 99 || pacedLeft != 100-paced.YearsPerTick() {
+		t.Errorf("Expected 99 and %d project years left, got %d and %d", 100-paced.YearsPerTick(), constantLeft, pacedLeft)
+	}
+
+	// Yearly chances compound over the years of a tick
+	quake := paced.Ruleset().EarthquakeChance
+	if chanceOverYears(quake, 1) != quake || chanceOverYears(quake, paced.YearsPerTick()) <= quake {
+		t.Error("Expected a paced tick to risk more earthquakes than a one-year tick")
+	}
+}
+
+func TestGameEngine_Mortality(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	lastTick := time.Now().Add(-2 * time.Second)
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4990, LastTickAt: &lastTick, Seeds: models.NewGameSeeds("mortality-seed")}
+	repo.games["game1"] = game
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "s2", GameID: "game1", PlayerID: "p1", Name: "Hamlet", Population: 100},
+		{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Name: "Camp", Population: 100},
+	}
+
+	// A year of simulated life records its deaths by cause
+	for year := 0; year < 3; year++ {
+		if err := engine.processGameTick(ctx, game); err != nil {
+			t.Fatalf("processGameTick failed: %v", err)
+		}
+	}
+	for _, settlement := range repo.settlements {
+		sim := engine.settlementSims[settlement.SettlementID]
+		if settlement.Deaths.Total() == 0 || settlement.Population != sim.Population() {
+			t.Errorf("Expected deaths recorded in %s, got %+v", settlement.Name, settlement.Deaths)
+		}
+	}
+
+	// Combat kills defenders in proportion to the damage done
+	camp := repo.settlements[1]
+	before := camp.Population
+	if err := engine.inflictCasualties(ctx, game, camp, models.MaxUnitHealth/2); err != nil {
+		t.Fatalf("inflictCasualties failed: %v", err)
+	}
+	if want := int(math.Round(float64(before) * combatCasualtyRate / 2)); camp.Deaths.Combat != want || camp.Population != before-want {
+		t.Errorf("Expected %d killed defending the camp, got %d (population %d -> %d)", want, camp.Deaths.Combat, before, camp.Population)
+	}
+
+	reports, err := engine.SettlementMortality(ctx, "game1", "")
+	if err != nil || len(reports) != 2 || reports[0].SettlementID != "s1" || reports[0].Combat != camp.Deaths.Combat || reports[0].Total != camp.Deaths.Total() {
+		t.Fatalf("Expected both settlements reported in order, got %+v: %v", reports, err)
+	}
+
+	handler := NewQueryHandler(engine)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/mortality?gameId=game1&settlementId=s2", nil))
+	var served []MortalityReport
+	if err := json.NewDecoder(recorder.Body).Decode(&served); err != nil || len(served) != 1 || served[0].Name != "Hamlet" {
+		t.Errorf("Expected /mortality to serve one settlement, got %+v: %v", served, err)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/mortality?gameId=game1&settlementId=nowhere", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown settlement to be 404, got %d", recorder.Code)
+	}
+}
+
+// routingMap is a size by size plain with a sea in the middle and a river
+// running east along row 30 and then south down column 130
+func routingMap(size int) []*models.MapTile {
+	var tiles []*models.MapTile
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "PLAINS"}
+			if x >= 60 && x < 100 && y >= 60 && y < 100 {
+				tile.TerrainType = terrain.Ocean
+			}
+			if (y == 30 && x <= 130) || (x == 130 && y >= 30) {
+				tile.HasRiver, tile.RiverID = true, 1
+			}
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles
+}
+
+func TestPathGraph(t *testing.T) {
+	graph := newPathGraph(routingMap(160))
+	from, to := models.Location{X: 5, Y: 31}, models.Location{X: 135, Y: 150}
+
+	// The planned route follows the river nearly as cheaply as a search of
+	// every tile would
+	route := graph.route(from, to)
+	if route[0] != from || route[len(route)-1] != to {
+		t.Fatalf("Expected the route to run end to end, got %v to %v", route[0], route[len(route)-1])
+	}
+	for i := 1; i < len(route); i++ {
+		if abs(route[i].X-route[i-1].X)+abs(route[i].Y-route[i-1].Y) != 1 || !graph.isLand(route[i]) {
+			t.Fatalf("Expected single steps over land, got %v then %v", route[i-1], route[i])
+		}
+	}
+	cost, _ := searchRoutes(from, graph.isLand, graph.tileAt, nil)
+	if got := routeCost(route, graph.tileAt); got > cost[to]*1.1 {
+		t.Errorf("Expected a route within 10%% of the cheapest %.1f, got %.1f", cost[to], got)
+	}
+
+	// Routes are cached until the map changes
+	graph.tiles[route[len(route)/2]].TerrainType = terrain.Ocean
+	if cached := graph.route(from, to); !reflect.DeepEqual(cached, route) {
+		t.Errorf("Expected the cached route, got %v", cached)
+	}
+
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	repo.mapTiles["game1"] = routingMap(160)
+	first := engine.gameRoute(context.Background(), "game1", from, to)
+	if !reflect.DeepEqual(first, route) || engine.pathGraphs["game1"] == nil {
+		t.Errorf("Expected the game's graph built and the same route, got %v", first)
+	}
+	engine.invalidatePaths("game1")
+	if engine.pathGraphs["game1"] != nil {
+		t.Error("Expected invalidation to drop the game's graph")
+	}
+
+	// Short routes are searched tile by tile, as before
+	near := models.Location{X: 9, Y: 31}
+	if got := graph.route(from, near); !reflect.DeepEqual(got, findRoute(from, near, graph.tileAt)) {
+		t.Errorf("Expected a short route to match findRoute, got %v", got)
+	}
+}
+
+// BenchmarkFindRoute searches every tile between the corners of a large map
+func BenchmarkFindRoute(b *testing.B) {
+	graph := newPathGraph(routingMap(160))
+	from, to := models.Location{X: 5, Y: 31}, models.Location{X: 135, Y: 150}
+	for i := 0; i < b.N; i++ {
+		findRoute(from, to, graph.tileAt)
+	}
+}
+
+// BenchmarkPathGraphPlan plans the same route over the cluster graph
+func BenchmarkPathGraphPlan(b *testing.B) {
+	graph := newPathGraph(routingMap(160))
+	from, to := models.Location{X: 5, Y: 31}, models.Location{X: 135, Y: 150}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.plan(from, to)
+	}
+}
+
+// BenchmarkPathGraphRoute looks the same route up in the route cache
+func BenchmarkPathGraphRoute(b *testing.B) {
+	graph := newPathGraph(routingMap(160))
+	from, to := models.Location{X: 5, Y: 31}, models.Location{X: 135, Y: 150}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.route(from, to)
+	}
+}
+
+// BenchmarkNewPathGraph builds the cluster graph of a large map
+func BenchmarkNewPathGraph(b *testing.B) {
+	tiles := routingMap(160)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newPathGraph(tiles)
+	}
+}
+
+func TestGameEngine_PreviewTileYields(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	repo.mapTiles["game1"] = []*models.MapTile{
+		{GameID: "game1", X: 1, Y: 1, TerrainType: terrain.Grassland, SettlementID: "s1"},
+		{GameID: "game1", X: 2, Y: 1, TerrainType: terrain.Grassland},
+	}
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Technologies: []string{simulator.TechFireMastery}},
+		{SettlementID: "s2", GameID: "game1", PlayerID: "p2", Technologies: []string{simulator.TechDomestication}},
+	}
+
+	// A worked tile yields what its settlement gets from it
+	worked, err := engine.PreviewTileYields(ctx, "game1", 1, 1, "p2", nil, nil)
+	if err != nil {
+		t.Fatalf("PreviewTileYields failed: %v", err)
+	}
+	if want := yields.ComputeTileYields(repo.mapTiles["game1"][0], nil, []string{simulator.TechFireMastery}); worked.TileYields != want {
+		t.Errorf("Expected the working settlement's yields %+v, got %+v", want, worked.TileYields)
+	}
+
+	// An unworked tile is previewed with the player's technologies
+	unworked, err := engine.PreviewTileYields(ctx, "game1", 2, 1, "p2", nil, nil)
+	if err != nil || len(unworked.Technologies) != 1 || unworked.Technologies[0] != simulator.TechDomestication {
+		t.Errorf("Expected the player's technologies, got %+v: %v", unworked, err)
+	}
+
+	handler := NewQueryHandler(engine)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/yields?gameId=game1&x=1&y=1&techs=", nil))
+	var served TileYieldsPreview
+	if err := json.NewDecoder(recorder.Body).Decode(&served); err != nil || len(served.Technologies) != 0 || served.TileYields != yields.ComputeTileYields(repo.mapTiles["game1"][0], nil, nil) {
+		t.Errorf("Expected /yields to preview the tile without technologies, got %+v: %v", served, err)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/yields?gameId=game1&x=9&y=9", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown tile to be 404, got %d", recorder.Code)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/yields?gameId=game1&x=left", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected a malformed tile to be 400, got %d", recorder.Code)
+	}
+}
+
+func TestGameEngine_OvercrowdingCrisis(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("crowded-seed")}
+	repo.games["game1"] = game
+	repo.mapTiles["game1"] = []*models.MapTile{{GameID: "game1", TerrainType: "DESERT"}}
+	repo.settlements = []*models.Settlement{{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Name: "Oasis", Population: 100}}
+
+	// One desert tile feeds a handful, so overcrowding soon strikes
+	for year := 0; year < 5 && len(repo.events) == 0; year++ {
+		if err := engine.processSettlementGrowth(ctx, game); err != nil {
+			t.Fatalf("processSettlementGrowth failed: %v", err)
+		}
+	}
+	if len(repo.events) == 0 || repo.events[0].Type != models.EventSettlementCrisis || repo.events[0].PlayerID != "p1" {
+		t.Fatalf("Expected a crisis recorded for the settlement's player, got %+v", repo.events)
+	}
+	if !strings.HasPrefix(repo.events[0].Detail, "Overcrowding in Oasis brought ") {
+		t.Errorf("Expected the crisis described, got %q", repo.events[0].Detail)
+	}
+}
+
+func TestGameEngine_UnitMaintenance(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	owner := "player1"
+	for x := 0; x < 6; x++ {
+		tile := &models.MapTile{GameID: "game1", X: x, Y: 0, TerrainType: "GRASSLAND"}
+		if x < 3 {
+			tile.OwnerID = &owner
+		}
+		repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+	}
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "home", GameID: "game1", PlayerID: "player1", Location: models.Location{X: 0, Y: 0}},
+	}
+	repo.units = []*models.Unit{
+		{UnitID: "garrisoned", GameID: "game1", PlayerID: "player1", Location: models.Location{X: 0, Y: 0}, Damage: 60},
+		{UnitID: "territory", GameID: "game1", PlayerID: "player1", Location: models.Location{X: 2, Y: 0}, Damage: 60},
+		{UnitID: "abroad", GameID: "game1", PlayerID: "player1", Location: models.Location{X: 5, Y: 0}, Damage: 60},
+		{UnitID: "intruder", GameID: "game1", PlayerID: "player2", Location: models.Location{X: 1, Y: 0}, Damage: 60},
+	}
+
+	if err := engine.processUnitMaintenance(ctx, game); err != nil {
+		t.Fatalf("processUnitMaintenance failed: %v", err)
+	}
+	expected := map[string]int{"garrisoned": 35, "territory": 50, "abroad": 60, "intruder": 60}
+	for _, unit := range repo.units {
+		if unit.Damage != expected[unit.UnitID] {
+			t.Errorf("Expected %s to have %d damage after a year, got %d", unit.UnitID, expected[unit.UnitID], unit.Damage)
+		}
+	}
+	// Only the friendly unit in the settlement garrisons it, weighted by its health
+	if garrison := repo.settlements[0].Garrison; garrison != 0.16 {
+		t.Errorf("Expected a 0.16 garrison bonus from a unit at 65 health, got %v", garrison)
+	}
+
+	// Units heal fully and never past full health
+	for year := 0; year < 10; year++ {
+		if err := engine.processUnitMaintenance(ctx, game); err != nil {
+			t.Fatalf("processUnitMaintenance failed: %v", err)
+		}
+	}
+	if unit := repo.units[0]; unit.Damage != 0 || unit.Health() != models.MaxUnitHealth {
+		t.Errorf("Expected the garrisoned unit to heal fully, got %d damage", unit.Damage)
+	}
+	if defense := repo.settlements[0].Defense(); defense != 1.25 {
+		t.Errorf("Expected a healthy garrison to raise defense to 1.25, got %v", defense)
+	}
+
+	// The bonus goes when the garrison marches out
+	repo.units[0].Location = models.Location{X: 1, Y: 0}
+	if err := engine.processUnitMaintenance(ctx, game); err != nil {
+		t.Fatalf("processUnitMaintenance failed: %v", err)
+	}
+	if garrison := repo.settlements[0].Garrison; garrison != 0 {
+		t.Errorf("Expected no garrison bonus once the unit left, got %v", garrison)
+	}
+}
+
+func TestGameEngine_CombatPreview(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	repo.mapTiles["game1"] = []*models.MapTile{
+		{GameID: "game1", X: 0, Y: 0, TerrainType: "GRASSLAND"},
+		{GameID: "game1", X: 1, Y: 0, TerrainType: "HILLS"},
+	}
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "fort", GameID: "game1", PlayerID: "player2", Location: models.Location{X: 1, Y: 0}, Garrison: 0.25},
+	}
+	repo.units = []*models.Unit{
+		{UnitID: "galley", GameID: "game1", PlayerID: "player1", UnitType: models.UnitTypeGalley, Location: models.Location{X: 0, Y: 0}},
+		{UnitID: "scouts", GameID: "game1", PlayerID: "player2", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 0, Y: 0}, Damage: 40},
+		{UnitID: "guard", GameID: "game1", PlayerID: "player2", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 1, Y: 0}},
+	}
+
+	odds, err := engine.PreviewCombat(ctx, "game1", "galley", "scouts")
+	if err != nil {
+		t.Fatalf("PreviewCombat failed: %v", err)
+	}
+	if total := odds.AttackerWins + odds.DefenderWins + odds.Stalemate; math.Abs(total-1) > 1e-9 {
+		t.Errorf("Expected outcome chances to sum to 1, got %v", total)
+	}
+	if odds.AttackerWins < 0.9 {
+		t.Errorf("Expected a galley to beat wounded workers in the open, got %v", odds.AttackerWins)
+	}
+	if odds.DefenderHealth[0] != odds.AttackerWins {
+		t.Errorf("Expected the defender's zero-health chance to match the attacker's win chance")
+	}
+
+	// The resolver fights out the odds the preview promises
+	stream := rng.NewStream(42)
+	wins := 0
+	const trials = 20000
+	for i := 0; i < trials; i++ {
+		if _, d := resolveCombat(combatant{3, 100}, combatant{1, 60}, stream); d == 0 {
+			wins++
+		}
+	}
+	if rate := float64(wins) / trials; math.Abs(rate-odds.AttackerWins) > 0.01 {
+		t.Errorf("Expected the resolver to win %.3f of fights, got %.3f", odds.AttackerWins, rate)
+	}
+
+	// Units defending in a friendly settlement gain its garrison and terrain
+	guarded, err := engine.PreviewCombat(ctx, "game1", "galley", "guard")
+	if err != nil {
+		t.Fatalf("PreviewCombat failed: %v", err)
+	}
+	if guarded.DefenderStrength != 1.875 {
+		t.Errorf("Expected a workers unit in a garrisoned hill fort to defend at 1.875, got %v", guarded.DefenderStrength)
+	}
+	fort, err := engine.PreviewCombat(ctx, "game1", "galley", "fort")
+	if err != nil {
+		t.Fatalf("PreviewCombat failed: %v", err)
+	}
+	if fort.DefenderStrength != 3.75 {
+		t.Errorf("Expected the hill fort to defend at 3.75, got %v", fort.DefenderStrength)
+	}
+
+	// Previews never change the game
+	if repo.units[1].Damage != 40 || repo.units[0].Damage != 0 {
+		t.Error("Expected a preview to leave unit health untouched")
+	}
+
+	// Over HTTP, unknown combatants are not found
+	handler := NewQueryHandler(engine)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/combat/preview?gameId=game1&attackerId=galley&defenderId=nobody", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown defender, got %d", recorder.Code)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/combat/preview?gameId=nogame&attackerId=galley&defenderId=fort", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown game, got %d", recorder.Code)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/combat/preview?gameId=game1&attackerId=galley&defenderId=fort", nil))
+	var served CombatOdds
+	if err := json.NewDecoder(recorder.Body).Decode(&served); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("Expected a preview over HTTP, got %d: %v", recorder.Code, err)
+	}
+	if served.AttackerWins != fort.AttackerWins {
+		t.Errorf("Expected the served odds to match the engine's")
+	}
+}
+
+func TestGameEngine_Diplomacy(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, PlayerList: []string{"p1", "p2", "p3"}}
+	repo.games["game1"] = game
+	repo.settlements = []*models.Settlement{
+		{SettlementID: "a", GameID: "game1", PlayerID: "p1", Population: 200, Location: models.Location{X: 0, Y: 0}},
+		{SettlementID: "b1", GameID: "game1", PlayerID: "p2", Production: 30, Location: models.Location{X: 5, Y: 0}},
+		{SettlementID: "b2", GameID: "game1", PlayerID: "p2", Production: 10, Location: models.Location{X: 6, Y: 0}},
+		{SettlementID: "c", GameID: "game1", PlayerID: "p3", Production: 40, Location: models.Location{X: 9, Y: 0}},
+	}
+	a, b1, b2, c := repo.settlements[0], repo.settlements[1], repo.settlements[2], repo.settlements[3]
+	order := func(id, playerID, orderType, target, item string, amount int) *models.Order {
+		o := &models.Order{OrderID: id, GameID: "game1", PlayerID: playerID, OrderType: orderType,
+			TargetPlayer: target, Item: item, Amount: amount, Status: models.OrderStatusPending}
+		repo.orders = append(repo.orders, o)
+		return o
+	}
+	processOrders := func() {
+		if err := engine.processOrders(ctx, game); err != nil {
+			t.Fatalf("processOrders failed: %v", err)
+		}
+	}
+	year := func() {
+		game.CurrentYear++
+		if err := engine.processDiplomacy(ctx, game); err != nil {
+			t.Fatalf("processDiplomacy failed: %v", err)
+		}
+	}
+	state := func(x, y string) *models.DiplomacyState {
+		s, err := engine.diplomacyState(ctx, game, x, y)
+		if err != nil {
+			t.Fatalf("diplomacyState failed: %v", err)
+		}
+		return s
+	}
+
+	// A demand stands until answered; accepting it puts tribute in force
+	order("d1", "p1", models.OrderTypeDemand, "p2", models.AgreementTribute, 15)
+	processOrders()
+	if demand := state("p2", "p1").Demand; demand == nil || demand.From != "p2" || demand.To != "p1" {
+		t.Fatalf("Expected p1's demand of p2 to be recorded, got %+v", demand)
+	}
+	rejected := order("x1", "p1", models.OrderTypeAccept, "p2", "", 0)
+	processOrders()
+	if rejected.Status != models.OrderStatusRejected {
+		t.Error("Expected the demanding player to be unable to accept their own demand")
+	}
+	order("a1", "p2", models.OrderTypeAccept, "p1", "", 0)
+	processOrders()
+
+	// Tribute is paid from the richest settlement into the most populous
+	year()
+	if b1.Production != 15 || b2.Production != 10 || a.Production != 15 {
+		t.Errorf("Expected 15 tribute from b1 to a, got b1=%d b2=%d a=%d", b1.Production, b2.Production, a.Production)
+	}
+	year()
+	if b1.Production != 0 || b2.Production != 10 || a.Production != 30 {
+		t.Errorf("Expected a second 15 tribute from b1 to a, got b1=%d b2=%d a=%d", b1.Production, b2.Production, a.Production)
+	}
+
+	// A payer who cannot meet its tribute breaks the agreement and is penalized
+	year()
+	broken := state("p1", "p2")
+	if broken.Agreement != nil || broken.BrokenBy != "p2" || broken.Stance != models.StanceHostile {
+		t.Errorf("Expected the defaulted tribute to be broken by p2, got %+v", broken)
+	}
+	if a.Production != 30 || b2.Production != 10 {
+		t.Error("Expected no partial tribute from a defaulting payer")
+	}
+	rejected = order("d2", "p1", models.OrderTypeDemand, "p2", models.AgreementTribute, 5)
+	processOrders()
+	if rejected.Status != models.OrderStatusRejected {
+		t.Error("Expected no new demand to be possible while the grievance stands")
+	}
+
+	// Vassals pay a share of their production and are defended by their overlord
+	order("d3", "p1", models.OrderTypeDemand, "p3", models.AgreementVassalage, 0)
+	order("a3", "p3", models.OrderTypeAccept, "p1", "", 0)
+	processOrders()
+	year()
+	if c.Production != 30 || a.Production != 40 {
+		t.Errorf("Expected the vassal to send a quarter of its production, got c=%d a=%d", c.Production, a.Production)
+	}
+	repo.units = []*models.Unit{{UnitID: "legion", GameID: "game1", PlayerID: "p1", Location: c.Location}}
+	if err := engine.processUnitMaintenance(ctx, game); err != nil {
+		t.Fatalf("processUnitMaintenance failed: %v", err)
+	}
+	if c.Garrison != 0.25 {
+		t.Errorf("Expected the overlord's unit to garrison its vassal, got %v", c.Garrison)
+	}
+
+	// Demands nobody answers lapse
+	order("d4", "p3", models.OrderTypeDemand, "p2", models.AgreementTribute, 5)
+	processOrders()
+	for i := 0; i < demandExpiry; i++ {
+		year()
+	}
+	if demand := state("p2", "p3").Demand; demand != nil {
+		t.Errorf("Expected the unanswered demand to lapse, got %+v", demand)
+	}
+
+	// Agreements end with a player's elimination
+	game.EliminatedPlayers = []string{"p3"}
+	year()
+	if agreement := state("p1", "p3").Agreement; agreement != nil {
+		t.Errorf("Expected the vassalage to end with the vassal's elimination, got %+v", agreement)
+	}
+}
+
+func TestGameEngine_GreatPeople(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("great-people")}
+	repo.games["game1"] = game
+	owner := "p1"
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 6; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND", OwnerID: &owner})
+		}
+	}
+	town := &models.Settlement{SettlementID: "town", GameID: "game1", PlayerID: "p1", Name: "Town", Population: 100,
+		Buildings: []string{"forge", "bronze_works"}, Technologies: []string{simulator.TechFireMastery}}
+	repo.settlements = []*models.Settlement{town}
+
+	// Buildings and technologies earn points until a great person is born;
+	// each one born makes the next costlier
+	for year := 0; year < 34; year++ {
+		if err := engine.processGreatPeople(ctx, game); err != nil {
+			t.Fatalf("processGreatPeople failed: %v", err)
+		}
+	}
+	if len(repo.units) != 1 {
+		t.Fatalf("Expected one great person after 34 years, got %d", len(repo.units))
+	}
+	builder := repo.units[0]
+	if builder.UnitType != models.UnitTypeGreatBuilder || !containsString(greatPersonNames[models.UnitTypeGreatBuilder], builder.Name) {
+		t.Errorf("Expected a named great builder, got %s %q", builder.UnitType, builder.Name)
+	}
+	if town.GreatPeople != 1 || town.GreatPoints[models.UnitTypeGreatGeneral] != 102 || town.GreatPoints[models.UnitTypeGreatScientist] != 34 {
+		t.Errorf("Expected the general to wait for the raised threshold, got %+v", town.GreatPoints)
+	}
+
+	activate := func(unit *models.Unit) *models.Order {
+		order := &models.Order{OrderID: "activate-" + unit.UnitID, GameID: "game1", PlayerID: unit.PlayerID, UnitID: unit.UnitID,
+			OrderType: models.OrderTypeActivate, Status: models.OrderStatusPending}
+		repo.orders = append(repo.orders, order)
+		if err := engine.processOrders(ctx, game); err != nil {
+			t.Fatalf("processOrders failed: %v", err)
+		}
+		return order
+	}
+
+	// A great builder improves the tile under them at once and is spent
+	if order := activate(builder); order.Status != models.OrderStatusExecuted {
+		t.Fatalf("Expected the builder to be activated, got %s: %s", order.Status, order.Reason)
+	}
+	if tile, _ := repo.GetMapTile(ctx, "game1", 0, 0); !containsString(tile.Improvements, models.ImprovementFarm) {
+		t.Errorf("Expected a farm under the builder, got %v", tile.Improvements)
+	}
+	if len(repo.units) != 0 {
+		t.Errorf("Expected the builder to be spent")
+	}
+
+	// A great scientist brings the settlement forward a technology
+	repo.settlements = append(repo.settlements, &models.Settlement{SettlementID: "far", GameID: "game1", PlayerID: "p1", Population: 100, Location: models.Location{X: 5, Y: 5}})
+	scientist := &models.Unit{UnitID: "scientist", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeGreatScientist, Location: models.Location{X: 4, Y: 4}}
+	repo.units = []*models.Unit{scientist}
+	activate(scientist)
+	if far := repo.settlements[1]; !containsString(far.Technologies, simulator.TechFireMastery) {
+		t.Errorf("Expected the nearest settlement to learn fire mastery, got %v", far.Technologies)
+	}
+
+	// A great general inspires nearby units in combat for a decade
+	general := &models.Unit{UnitID: "general", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeGreatGeneral, Location: models.Location{X: 1, Y: 1}}
+	near := &models.Unit{UnitID: "near", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 3, Y: 2}}
+	far := &models.Unit{UnitID: "far", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 5, Y: 5}}
+	enemy := &models.Unit{UnitID: "enemy", GameID: "game1", PlayerID: "p2", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 2, Y: 2}}
+	repo.units = []*models.Unit{general, near, far, enemy}
+	activate(general)
+	if near.Aura != generalAuraYears || far.Aura != 0 || enemy.Aura != 0 {
+		t.Errorf("Expected only the player's nearby unit to be inspired, got near=%d far=%d enemy=%d", near.Aura, far.Aura, enemy.Aura)
+	}
+	odds, err := engine.PreviewCombat(ctx, "game1", "near", "enemy")
+	if err != nil {
+		t.Fatalf("PreviewCombat failed: %v", err)
+	}
+	if odds.AttackerStrength != 1.5 {
+		t.Errorf("Expected an inspired workers unit to attack at 1.5, got %v", odds.AttackerStrength)
+	}
+	for year := 0; year < generalAuraYears; year++ {
+		if err := engine.processUnitMaintenance(ctx, game); err != nil {
+			t.Fatalf("processUnitMaintenance failed: %v", err)
+		}
+	}
+	if near.Aura != 0 {
+		t.Errorf("Expected the inspiration to wear off, got %d years left", near.Aura)
+	}
+
+	// Only great people can be activated
+	if order := activate(far); order.Status != models.OrderStatusRejected {
+		t.Error("Expected activating a workers unit to be rejected")
+	}
+}
+
+func TestGameEngine_Desires(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	owner := "p1"
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND", OwnerID: &owner})
+		}
+	}
+	village := &models.Settlement{SettlementID: "village", GameID: "game1", PlayerID: "p1", Name: "Village", Type: models.SettlementTypeVillage,
+		Population: 100, Location: models.Location{X: 2, Y: 2}}
+	camp := &models.Settlement{SettlementID: "camp", GameID: "game1", PlayerID: "p1", Name: "Camp", Type: models.SettlementTypeNomadicCamp,
+		Population: 20, Location: models.Location{X: 9, Y: 9}, Garrison: 1}
+	repo.settlements = []*models.Settlement{village, camp}
+
+	tick := func(years int) {
+		for i := 0; i < years; i++ {
+			if err := engine.processDesires(ctx, game); err != nil {
+				t.Fatalf("processDesires failed: %v", err)
+			}
+			game.CurrentYear++
+		}
+	}
+	proposals := func() []string {
+		var items []string
+		for _, order := range repo.orders {
+			if order.Status == models.OrderStatusProposed {
+				items = append(items, order.SettlementID+":"+order.Item)
+			}
+		}
+		sort.Strings(items)
+		return items
+	}
+
+	// Without roads, trade, walls or learning the village's people grow
+	// unhappy; a garrisoned camp only misses roads and trade
+	tick(5)
+	if village.Desires[models.DesireSecurity] != 0.5 || village.Desires[models.DesireKnowledge] != 0.5 || village.Unhappiness != 0.5 {
+		t.Errorf("Expected the village's desires to grow, got %v (unhappiness %.2f)", village.Desires, village.Unhappiness)
+	}
+	if camp.Desires[models.DesireSecurity] != 0 || camp.Desires[models.DesireKnowledge] != 0 || camp.Unhappiness != 0.25 {
+		t.Errorf("Expected the camp to want only roads and trade, got %v (unhappiness %.2f)", camp.Desires, camp.Unhappiness)
+	}
+
+	// Strong desires propose the buildings that meet them, once; a treasury
+	// needs gold the player lacks and roads are left to workers
+	want := []string{"village:library", "village:palisade"}
+	if got := proposals(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected proposals %v, got %v", want, got)
+	}
+	tick(3)
+	if got := proposals(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected no repeated proposals, got %v", got)
+	}
+
+	// Proposals wait for the player's approval
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if got := proposals(); !reflect.DeepEqual(got, want) || len(village.Buildings) != 0 {
+		t.Errorf("Expected proposals to stay unbuilt, got %v and buildings %v", got, village.Buildings)
+	}
+
+	// Meeting a desire lets it fade; roads reaching the settlements meet
+	// their desire for them
+	village.Buildings = []string{models.BuildingPalisade}
+	for _, loc := range []models.Location{{X: 3, Y: 3}, {X: 8, Y: 8}} {
+		tile, _ := repo.GetMapTile(ctx, "game1", loc.X, loc.Y)
+		tile.Improvements = []string{models.ImprovementRoad}
+	}
+	tick(4)
+	if _, ok := village.Desires[models.DesireSecurity]; ok {
+		t.Errorf("Expected the village's security desire to fade, got %v", village.Desires)
+	}
+	if village.Desires[models.DesireRoads] != 0 || camp.Desires[models.DesireRoads] != 0 {
+		t.Errorf("Expected the road to meet both settlements' desire for roads, got %v and %v", village.Desires, camp.Desires)
+	}
+}
+
+func TestGameEngine_RoadPlanning(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 20; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"})
+		}
+	}
+	west := &models.Settlement{SettlementID: "west", GameID: "game1", PlayerID: "p1", Name: "West", Location: models.Location{X: 2, Y: 2}}
+	east := &models.Settlement{SettlementID: "east", GameID: "game1", PlayerID: "p1", Name: "East", Location: models.Location{X: 14, Y: 2}}
+	repo.settlements = []*models.Settlement{west, east}
+	nearby, _ := repo.GetMapTile(ctx, "game1", 3, 2)
+	nearby.Improvements = []string{models.ImprovementRoad}
+
+	plan := func() {
+		if err := engine.processRoadPlanning(ctx, game); err != nil {
+			t.Fatalf("processRoadPlanning failed: %v", err)
+		}
+		game.CurrentYear++
+	}
+	proposals := func() []*models.Order {
+		var orders []*models.Order
+		for _, order := range repo.orders {
+			if order.OrderType == models.OrderTypeRoad {
+				orders = append(orders, order)
+			}
+		}
+		return orders
+	}
+
+	// Trips past the midpoint between the settlements build traffic both
+	// ways; moves within one settlement's half do not
+	for year := 0; year < 3; year++ {
+		engine.noteTrip("game1", trip{playerID: "p1", from: models.Location{X: 7, Y: 2}, to: models.Location{X: 9, Y: 2}})
+		engine.noteTrip("game1", trip{playerID: "p1", from: models.Location{X: 3, Y: 2}, to: models.Location{X: 5, Y: 2}})
+		if year < 2 && len(proposals()) > 0 {
+			t.Fatalf("Expected no road to be proposed before the route is busy")
+		}
+		plan()
+	}
+	if math.Abs(west.Traffic["east"]-2.44) > 1e-9 || east.Traffic["west"] != west.Traffic["east"] {
+		t.Errorf("Expected traffic of 2.44 both ways, got %v and %v", west.Traffic, east.Traffic)
+	}
+
+	// A busy unpaved route makes both settlements want roads, even one with
+	// a road nearby, and has a road proposed for the player to approve
+	if err := engine.processDesires(ctx, game); err != nil {
+		t.Fatalf("processDesires failed: %v", err)
+	}
+	if west.Desires[models.DesireRoads] == 0 || east.Desires[models.DesireRoads] == 0 {
+		t.Errorf("Expected both settlements to want roads, got %v and %v", west.Desires, east.Desires)
+	}
+	orders := proposals()
+	if len(orders) != 1 || orders[0].Status != models.OrderStatusProposed || orders[0].SettlementID != "east" || *orders[0].Target != west.Location {
+		t.Fatalf("Expected a proposed road from East to West, got %+v", orders)
+	}
+
+	// Once approved the road is planned along the route, leaving out tiles
+	// already paved, and not proposed again
+	orders[0].Status = models.OrderStatusPending
+	if err := engine.processOrders(ctx, game); err != nil {
+		t.Fatalf("processOrders failed: %v", err)
+	}
+	if orders[0].Status != models.OrderStatusExecuted {
+		t.Fatalf("Expected the road order to execute, got %s: %s", orders[0].Status, orders[0].Reason)
+	}
+	if len(east.RoadPlan) != 10 || east.RoadPlan[9] != (models.Location{X: 4, Y: 2}) {
+		t.Errorf("Expected the 10 tiles between the settlements and the road to pave, got %v", east.RoadPlan)
+	}
+	engine.noteTrip("game1", trip{playerID: "p1", from: models.Location{X: 9, Y: 2}, to: models.Location{X: 7, Y: 2}})
+	plan()
+	if len(proposals()) != 1 {
+		t.Errorf("Expected a planned road not to be proposed again, got %d proposals", len(proposals()))
+	}
+
+	// Automated workers pave planned roads before any other work
+	worker := &models.Unit{UnitID: "w1", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers,
+		Automation: models.AutomationImproveNearest, Location: models.Location{X: 2, Y: 2}}
+	repo.units = []*models.Unit{worker}
+	for year := 0; year < 3; year++ {
+		if err := engine.processWorkers(ctx, game); err != nil {
+			t.Fatalf("processWorkers failed: %v", err)
+		}
+	}
+	if tile, _ := repo.GetMapTile(ctx, "game1", 4, 2); !containsString(tile.Improvements, models.ImprovementRoad) {
+		t.Errorf("Expected the worker to pave the nearest planned tile, got %v", tile.Improvements)
+	}
+	plan()
+	if len(east.RoadPlan) != 9 || east.RoadPlan[8] != (models.Location{X: 5, Y: 2}) {
+		t.Errorf("Expected the paved tile to leave the plan, got %v", east.RoadPlan)
+	}
+
+	// Under a high infrastructure policy busy routes are planned at once
+	north := &models.Settlement{SettlementID: "north", GameID: "game1", PlayerID: "p2", Name: "North", Location: models.Location{X: 2, Y: 6},
+		Traffic: map[string]float64{"south": 3}}
+	south := &models.Settlement{SettlementID: "south", GameID: "game1", PlayerID: "p2", Name: "South", Location: models.Location{X: 8, Y: 6},
+		Traffic: map[string]float64{"north": 3}}
+	repo.settlements = append(repo.settlements, north, south)
+	repo.SavePlayerPolicy(ctx, &models.PlayerPolicy{GameID: "game1", PlayerID: "p2", Infrastructure: models.PolicyHigh})
+	plan()
+	if len(north.RoadPlan) != 5 || len(proposals()) != 1 {
+		t.Errorf("Expected the road to be planned without a proposal, got %v and %d proposals", north.RoadPlan, len(proposals()))
+	}
+}
+
+func TestGameEngine_Unrest(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -3000, Seeds: models.NewGameSeeds("unrest-seed")}
+	repo.games["game1"] = game
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"})
+		}
+	}
+	city := &models.Settlement{SettlementID: "city", GameID: "game1", PlayerID: "p1", Name: "City", Type: models.SettlementTypeCity,
+		Population: 2500, Location: models.Location{X: 2, Y: 2}, Unhappiness: 1}
+	town := &models.Settlement{SettlementID: "town", GameID: "game1", PlayerID: "p1", Name: "Town", Type: models.SettlementTypeCity,
+		Population: 2500, Location: models.Location{X: 9, Y: 2}, Unhappiness: 1, Garrison: 0.25, Buildings: []string{models.BuildingPalisade}}
+	village := &models.Settlement{SettlementID: "village", GameID: "game1", PlayerID: "p1", Name: "Village", Type: models.SettlementTypeVillage,
+		Population: 500, Location: models.Location{X: 2, Y: 9}, Unhappiness: 1}
+	repo.settlements = []*models.Settlement{city, town, village}
+
+	tick := func() {
+		if err := engine.processUnrest(ctx, game); err != nil {
+			t.Fatalf("processUnrest failed: %v", err)
+		}
+		game.CurrentYear++
+	}
+	rebels := func() []*models.Unit {
+		var units []*models.Unit
+		for _, unit := range repo.units {
+			if unit.UnitType == models.UnitTypeRebels {
+				units = append(units, unit)
+			}
+		}
+		return units
+	}
+
+	// Unrest closes half the gap to its pressure each year, and skims food
+	tick()
+	if city.Unrest != 0.375 {
+		t.Errorf("Expected the crowded, unhappy city's unrest to reach 0.375, got %.3f", city.Unrest)
+	}
+	if got := engine.settlementSims["city"].Conditions.Unrest; got != 0.375*unrestSkim {
+		t.Errorf("Expected the city to lose %.4f of its food, got %.4f", 0.375*unrestSkim, got)
+	}
+	if village.Unrest != 0 {
+		t.Errorf("Expected a small village to stay calm, got unrest %.3f", village.Unrest)
+	}
+
+	// A garrison and walls keep the town below revolt
+	for i := 0; i < 30 && len(rebels()) == 0; i++ {
+		tick()
+	}
+	if town.Unrest >= revoltUnrest || town.Unrest < 0.25 {
+		t.Errorf("Expected the town's garrison and palisade to hold its unrest near 0.3, got %.3f", town.Unrest)
+	}
+
+	// The city revolts, raising a band of rebels for each thousand people
+	raised := rebels()
+	if len(raised) != 2 {
+		t.Fatalf("Expected the city to raise 2 bands of rebels, got %d", len(raised))
+	}
+	for _, unit := range raised {
+		if unit.PlayerID != models.RebelPlayerID || unit.HomeID != "city" || unit.Location == city.Location {
+			t.Errorf("Expected rebels from the city beside it, got %+v", unit)
+		}
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != models.EventRevolt || repo.events[0].PlayerID != "p1" {
+		t.Errorf("Expected a revolt event, got %v", repo.events)
+	}
+
+	// Rebels at large feed the unrest and no second revolt breaks out
+	if pressure := settlementUnrestPressure(city, 2); pressure != 0.95 {
+		t.Errorf("Expected two bands of rebels to raise the city's pressure to 0.95, got %.3f", pressure)
+	}
+	tick()
+	if len(rebels()) != 2 || len(repo.events) != 1 {
+		t.Errorf("Expected no second revolt while rebels are at large, got %d rebels and %d events", len(rebels()), len(repo.events))
+	}
+
+	// A full garrison puts the rebels down; rebels of a calm or vanished
+	// settlement go home
+	city.Garrison = 1
+	repo.units = append(repo.units,
+		&models.Unit{UnitID: "calm", GameID: "game1", PlayerID: models.RebelPlayerID, UnitType: models.UnitTypeRebels, HomeID: "village"},
+		&models.Unit{UnitID: "lost", GameID: "game1", PlayerID: models.RebelPlayerID, UnitType: models.UnitTypeRebels, HomeID: "razed"})
+	tick()
+	if got := rebels(); len(got) != 0 {
+		t.Errorf("Expected every band of rebels to be gone, got %d", len(got))
+	}
+	if city.Unrest >= revoltUnrest || len(repo.events) != 1 {
+		t.Errorf("Expected the garrison to calm the city, got unrest %.3f and %d events", city.Unrest, len(repo.events))
+	}
+}
+
+func TestGameEngine_PolicyLevers(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, Seeds: models.NewGameSeeds("levers-seed"),
+		SimulationFidelity: models.SimulationFidelityAggregated}
+	repo.games["game1"] = game
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"})
+		}
+	}
+	levied := &models.Settlement{SettlementID: "levied", GameID: "game1", PlayerID: "p1", Name: "Levied", Type: models.SettlementTypeVillage,
+		Population: 100, Location: models.Location{X: 2, Y: 2}}
+	free := &models.Settlement{SettlementID: "free", GameID: "game1", PlayerID: "p2", Name: "Free", Type: models.SettlementTypeVillage,
+		Population: 100, Location: models.Location{X: 9, Y: 9}}
+	repo.settlements = []*models.Settlement{levied, free}
+	repo.SavePlayerPolicy(ctx, &models.PlayerPolicy{GameID: "game1", PlayerID: "p1", TaxRate: 0.5, Corvee: 0.5})
+
+	// Without the technologies to bear more, levies stop at the base rates
+	if err := engine.processSettlementGrowth(ctx, game); err != nil {
+		t.Fatalf("processSettlementGrowth failed: %v", err)
+	}
+	if sim := engine.settlementSims["levied"]; sim.Conditions.Tax != baseMaxTaxRate || sim.Conditions.Corvee != baseMaxCorvee {
+		t.Errorf("Expected levies capped at %.2f and %.2f, got %.2f and %.2f", baseMaxTaxRate, baseMaxCorvee, sim.Conditions.Tax, sim.Conditions.Corvee)
+	}
+	if levied.Gold == 0 || levied.Production == 0 {
+		t.Errorf("Expected taxes and corvée to bank gold and production, got %d and %d", levied.Gold, levied.Production)
+	}
+	if free.Gold != 0 || free.Production != 0 {
+		t.Errorf("Expected a player without a policy to levy nothing, got %d gold and %d production", free.Gold, free.Production)
+	}
+
+	// Herding and building let people bear heavier levies
+	policy := &models.PlayerPolicy{TaxRate: 0.5, Corvee: 0.5}
+	skilled := &models.Settlement{Technologies: []string{simulator.TechFireMastery, simulator.TechDomestication, simulator.TechHusbandry, simulator.TechShelterBuilding}}
+	if tax, corvee := settlementLevers(policy, skilled); math.Abs(tax-0.3) > 1e-9 || math.Abs(corvee-0.3) > 1e-9 {
+		t.Errorf("Expected technologies to raise both caps to 0.3, got %.2f and %.2f", tax, corvee)
+	}
+
+	// Levies weigh on happiness
+	if err := engine.processDesires(ctx, game); err != nil {
+		t.Fatalf("processDesires failed: %v", err)
+	}
+	want := leverUnhappiness(baseMaxTaxRate, baseMaxCorvee)
+	if got := levied.Unhappiness - free.Unhappiness; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected levies to add %.2f unhappiness, got %.2f", want, got)
+	}
+}
+
+func TestGameEngine_SettlementProgression(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000}
+	repo.games["game1"] = game
+	rival := "p2"
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 7; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"}
+			if x == 6 && y == 6 {
+				tile.OwnerID = &rival
+			}
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+		}
+	}
+	camp := &models.Settlement{SettlementID: "camp", GameID: "game1", PlayerID: "p1", Name: "Camp",
+		Type: models.SettlementTypeNomadicCamp, Location: models.Location{X: 3, Y: 3}, Population: 200}
+	repo.settlements = []*models.Settlement{camp}
+	rules := game.Ruleset()
+
+	progress := func() {
+		if err := engine.processSettlementProgression(ctx, game); err != nil {
+			t.Fatalf("processSettlementProgression failed: %v", err)
+		}
+	}
+
+	// A small camp stays a camp
+	progress()
+	if camp.Type != models.SettlementTypeNomadicCamp || len(repo.events) != 0 {
+		t.Fatalf("Expected a camp of 200 to stay a camp, got %s", camp.Type)
+	}
+
+	// Population alone makes a village, with walls but no wider work area
+	camp.Population = 500
+	progress()
+	if camp.Type != models.SettlementTypeVillage {
+		t.Fatalf("Expected a village, got %s", camp.Type)
+	}
+	if camp.Defense() != 1.25 || camp.WorkRadius(rules) != rules.SettlementWorkRadius {
+		t.Errorf("Expected a village to defend at 1.25 within radius %d, got %v within %d", rules.SettlementWorkRadius, camp.Defense(), camp.WorkRadius(rules))
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != models.EventSettlementGrew || repo.events[0].PlayerID != "p1" {
+		t.Fatalf("Expected a settlement_grew event, got %+v", repo.events)
+	}
+
+	// A town needs a building as well as people
+	camp.Population = 5000
+	progress()
+	if camp.Type != models.SettlementTypeVillage {
+		t.Fatalf("Expected a village without buildings to stay a village, got %s", camp.Type)
+	}
+
+	// Enough buildings jump a settlement straight to the tier it qualifies for
+	camp.Buildings = []string{"granary", "forge", "treasury"}
+	progress()
+	if camp.Type != models.SettlementTypeCity || camp.WorkRadius(rules) != rules.SettlementWorkRadius+2 {
+		t.Fatalf("Expected a city working radius %d, got %s working %d", rules.SettlementWorkRadius+2, camp.Type, camp.WorkRadius(rules))
+	}
+	if got := len(engine.settlementWorkTiles(ctx, game, camp)); got != 49 {
+		t.Errorf("Expected a city to work 49 tiles, got %d", got)
+	}
+	claimed := 0
+	for _, tile := range repo.mapTiles["game1"] {
+		if tile.OwnerID != nil && *tile.OwnerID == "p1" {
+			claimed++
+		}
+	}
+	if claimed != 48 {
+		t.Errorf("Expected the city to claim every unowned tile it works, got %d", claimed)
+	}
+	if len(repo.events) != 2 {
+		t.Errorf("Expected one event per promotion, got %d", len(repo.events))
+	}
+
+	// Settlements never shrink back
+	camp.Population = 100
+	progress()
+	if camp.Type != models.SettlementTypeCity {
+		t.Errorf("Expected a city to stay a city, got %s", camp.Type)
+	}
+}
+
+func TestGameEngine_MinorCivs(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, PlayerList: []string{"p1", "p2"}, Seeds: models.NewGameSeeds("minor-civs")}
+	repo.games["game1"] = game
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"})
+		}
+	}
+	home := &models.Settlement{SettlementID: "home", GameID: "game1", PlayerID: "p1", Production: 100}
+	rival := &models.Settlement{SettlementID: "rival", GameID: "game1", PlayerID: "p2", Location: models.Location{X: 8, Y: 8}}
+	repo.settlements = []*models.Settlement{home, rival}
+
+	// Map generation's sites become minor civs with a village and territory
+	if err := engine.createMinorCivs(ctx, game, []models.Location{{X: 4, Y: 4}}); err != nil {
+		t.Fatalf("createMinorCivs failed: %v", err)
+	}
+	if len(repo.minorCivs) != 1 || len(repo.settlements) != 3 {
+		t.Fatalf("Expected one minor civ with a settlement, got %d civs and %d settlements", len(repo.minorCivs), len(repo.settlements))
+	}
+	civ, city := repo.minorCivs[0], repo.settlements[2]
+	if city.PlayerID != civ.MinorCivID || !models.IsMinorCiv(city.PlayerID) || !containsString(minorCivNames, civ.Name) {
+		t.Fatalf("Expected a named minor civ owning its settlement, got %+v", civ)
+	}
+	if tile, _ := repo.GetMapTile(ctx, "game1", 5, 5); tile.OwnerID == nil || *tile.OwnerID != civ.MinorCivID {
+		t.Errorf("Expected the minor civ to own its work area")
+	}
+
+	year := func() {
+		if err := engine.processMinorCivs(ctx, game); err != nil {
+			t.Fatalf("processMinorCivs failed: %v", err)
+		}
+		game.CurrentYear++
+	}
+	order := func(o *models.Order) *models.Order {
+		o.GameID, o.Status = "game1", models.OrderStatusPending
+		repo.orders = append(repo.orders, o)
+		if err := engine.processOrders(ctx, game); err != nil {
+			t.Fatalf("processOrders failed: %v", err)
+		}
+		return o
+	}
+
+	// Quests are set on the decade
+	year()
+	if civ.Quest == nil {
+		t.Fatal("Expected the minor civ to set a quest")
+	}
+
+	// A gift buys friendship and completes a gift quest it meets
+	civ.Quest = &models.MinorQuest{Type: models.MinorQuestGift, Amount: 30, Expires: game.CurrentYear + minorQuestYears}
+	gift := order(&models.Order{OrderID: "gift", PlayerID: "p1", OrderType: models.OrderTypeGift, TargetPlayer: civ.MinorCivID, SettlementID: "home", Amount: 60})
+	if gift.Status != models.OrderStatusExecuted {
+		t.Fatalf("Expected the gift to be sent, got %s: %s", gift.Status, gift.Reason)
+	}
+	if civ.Friendship["p1"] != 70 || civ.Quest != nil || home.Production != 40 || city.Production != 60 {
+		t.Errorf("Expected 70 friendship and a completed quest, got %d and %+v", civ.Friendship["p1"], civ.Quest)
+	}
+	if rejected := order(&models.Order{OrderID: "overdrawn", PlayerID: "p1", OrderType: models.OrderTypeGift, TargetPlayer: civ.MinorCivID, SettlementID: "home", Amount: 500}); rejected.Status != models.OrderStatusRejected {
+		t.Error("Expected a gift beyond the settlement's production to be rejected")
+	}
+
+	// The best friend becomes its ally and receives more production than a friend
+	civ.Quest = &models.MinorQuest{Type: models.MinorQuestVisit, Expires: game.CurrentYear + minorQuestYears}
+	scout := &models.Unit{UnitID: "scout", GameID: "game1", PlayerID: "p2", UnitType: models.UnitTypeWorkers, Location: models.Location{X: 6, Y: 6}}
+	repo.units = []*models.Unit{scout}
+	year()
+	if civ.Ally != "p1" || civ.Friendship["p1"] != 69 || civ.Friendship["p2"] != minorQuestFriendship {
+		t.Fatalf("Expected p1 allied and p2 rewarded for visiting, got ally %q and %v", civ.Ally, civ.Friendship)
+	}
+	if home.Production != 40+allyProduction || rival.Production != friendProduction {
+		t.Errorf("Expected the ally %d and the friend %d production, got %d and %d", allyProduction, friendProduction, home.Production-40, rival.Production)
+	}
+
+	// Its ally's units garrison it
+	guard := &models.Unit{UnitID: "guard", GameID: "game1", PlayerID: "p1", UnitType: models.UnitTypeWorkers, Location: city.Location}
+	repo.units = append(repo.units, guard)
+	if err := engine.processUnitMaintenance(ctx, game); err != nil {
+		t.Fatalf("processUnitMaintenance failed: %v", err)
+	}
+	if city.Garrison != garrisonDefense {
+		t.Errorf("Expected the ally's unit to garrison the minor civ, got %v", city.Garrison)
+	}
+
+	// Attacking it costs the attacker its friendship; breaking its defense conquers it
+	if rejected := order(&models.Order{OrderID: "far", PlayerID: "p2", UnitID: "scout", OrderType: models.OrderTypeAttack, SettlementID: city.SettlementID}); rejected.Status != models.OrderStatusRejected {
+		t.Error("Expected an attack from two tiles away to be rejected")
+	}
+	guard.Location = models.Location{X: 0, Y: 0}
+	city.Garrison = 0
+	for attempt := 0; attempt < 20 && civ.ConqueredBy == ""; attempt++ {
+		id := fmt.Sprintf("raider-%d", attempt)
+		repo.units = append(repo.units, &models.Unit{UnitID: id, GameID: "game1", PlayerID: "p2", UnitType: models.UnitTypeGalley, Location: models.Location{X: 5, Y: 5}})
+		if attack := order(&models.Order{OrderID: "attack-" + id, PlayerID: "p2", UnitID: id, OrderType: models.OrderTypeAttack, SettlementID: city.SettlementID}); attack.Status != models.OrderStatusExecuted {
+			t.Fatalf("Expected the attack to be fought, got %s: %s", attack.Status, attack.Reason)
+		}
+		if _, friendly := civ.Friendship["p2"]; friendly {
+			t.Fatal("Expected the minor civ to forget its friendship with its attacker")
+		}
+	}
+	if civ.ConqueredBy != "p2" || city.PlayerID != "p2" || civ.Ally != "" {
+		t.Fatalf("Expected p2 to conquer the minor civ, got conqueror %q owner %q", civ.ConqueredBy, city.PlayerID)
+	}
+	if tile, _ := repo.GetMapTile(ctx, "game1", 5, 5); *tile.OwnerID != "p2" {
+		t.Errorf("Expected the conqueror to take the minor civ's territory, got %s", *tile.OwnerID)
+	}
+	if last := repo.events[len(repo.events)-1]; last.Type != models.EventSettlementTaken || last.PlayerID != "p2" {
+		t.Errorf("Expected a settlement_taken event, got %+v", last)
+	}
+	if rejected := order(&models.Order{OrderID: "late-gift", PlayerID: "p1", OrderType: models.OrderTypeGift, TargetPlayer: civ.MinorCivID, SettlementID: "home", Amount: 10}); rejected.Status != models.OrderStatusRejected {
+		t.Error("Expected a gift to a conquered minor civ to be rejected")
+	}
+}
+
+func TestGameEngine_Objectives(t *testing.T) {
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	ctx := context.Background()
+
+	game := &models.Game{GameID: "game1", State: "started", CurrentYear: -4000, PlayerList: []string{"p1"}, Seeds: models.NewGameSeeds("objectives")}
+	repo.games["game1"] = game
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 3; y++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND"}
+			if y == 1 && x >= 3 && x <= 5 {
+				tile.Improvements = []string{models.ImprovementRoad}
+			}
+			repo.mapTiles["game1"] = append(repo.mapTiles["game1"], tile)
+		}
+	}
+	capital := &models.Settlement{SettlementID: "capital", GameID: "game1", PlayerID: "p1", Population: 600, Location: models.Location{X: 2, Y: 1}}
+	outpost := &models.Settlement{SettlementID: "outpost", GameID: "game1", PlayerID: "p1", Population: 100, Location: models.Location{X: 7, Y: 1}}
+	repo.settlements = []*models.Settlement{capital, outpost}
+	objective := func(id, objectiveType string, target, deadline int) *models.Objective {
+		o := &models.Objective{GameID: "game1", ObjectiveID: id, PlayerID: "p1", Type: objectiveType, Target: target,
+			Deadline: deadline, Reward: objectiveReward, Status: models.ObjectiveActive}
+		repo.objectives = append(repo.objectives, o)
+		return o
+	}
+	process := func() {
+		if err := engine.processObjectives(ctx, game); err != nil {
+			t.Fatalf("processObjectives failed: %v", err)
+		}
+	}
+
+	// The road stops a tile short of the outpost, so the settlements are not yet connected
+	connect := objective("connect", models.ObjectiveConnectSettlements, 0, -3900)
+	grow := objective("grow", models.ObjectivePopulation, 5000, -3900)
+	process()
+	if connect.Status != models.ObjectiveActive || grow.Status != models.ObjectiveActive || len(repo.objectives) != 2 {
+		t.Fatalf("Expected both objectives to stay open and none added, got %s, %s and %d objectives", connect.Status, grow.Status, len(repo.objectives))
+	}
+
+	// Finishing the road meets the objective and pays out to the largest settlement
+	tile, _ := repo.GetMapTile(ctx, "game1", 6, 1)
+	tile.Improvements = []string{models.ImprovementRoad}
+	game.CurrentYear = -3900
+	process()
+	if connect.Status != models.ObjectiveCompleted || connect.Resolved != -3900 || capital.Production != objectiveReward {
+		t.Errorf("Expected the connection to be rewarded with %d production, got %s and %d", objectiveReward, connect.Status, capital.Production)
+	}
+	if grow.Status != models.ObjectiveFailed {
+		t.Errorf("Expected the population objective to fail at its deadline, got %s", grow.Status)
+	}
+
+	// New objectives replace them, of types the player has not already met
+	var open []*models.Objective
+	for _, o := range repo.objectives {
+		if o.Status == models.ObjectiveActive {
+			open = append(open, o)
+		}
+	}
+	if len(open) != objectivesPerPlayer {
+		t.Fatalf("Expected %d new objectives, got %d", objectivesPerPlayer, len(open))
+	}
+	for _, o := range open {
+		if o.Type == models.ObjectiveConnectSettlements || o.Deadline != -3900+objectiveYears {
+			t.Errorf("Expected an unmet objective due in %d years, got %+v", objectiveYears, o)
+		}
+		if o.Type == models.ObjectivePopulation && o.Target != 1100 {
+			t.Errorf("Expected a population target of 1100, got %d", o.Target)
+		}
+	}
+	counts := make(map[string]int)
+	for _, event := range repo.events {
+		if event.PlayerID != "p1" || event.Detail == "" {
+			t.Errorf("Expected objective events addressed to the player, got %+v", event)
+		}
+		counts[event.Type]++
+	}
+	if counts[models.EventObjectiveDone] != 1 || counts[models.EventObjectiveFailed] != 1 || counts[models.EventObjectiveAssigned] != objectivesPerPlayer {
+		t.Errorf("Expected one done, one failed and %d assigned events, got %v", objectivesPerPlayer, counts)
+	}
+}
+
+func TestGameEngine_UserStats(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: models.GameStateActive, CurrentYear: -4900, PlayerList: []string{"p1", "p2"}}
+	repo.games["game1"] = game
+	settlement := &models.Settlement{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Technologies: []string{simulator.TechFireMastery}}
+
+	// Only the first mastery of fire is recorded
+	engine.recordFireMastery(ctx, game, settlement)
+	game.CurrentYear = -4800
+	engine.recordFireMastery(ctx, game, settlement)
+	engine.recordFireMastery(ctx, game, &models.Settlement{SettlementID: "s2", GameID: "game1", PlayerID: "p2"})
+	if len(game.FireMastery) != 1 || game.FireMastery["p1"] != -4900 {
+		t.Fatalf("Expected p1 to have mastered fire in -4900, got %v", game.FireMastery)
+	}
+
+	// Every player's result is recorded once the game is won
+	game.EliminatedPlayers = []string{"p2"}
+	if err := engine.processVictory(ctx, game); err != nil {
+		t.Fatalf("processVictory failed: %v", err)
+	}
+	if len(repo.gameResults) != 2 {
+		t.Fatalf("Expected a result for each player, got %+v", repo.gameResults)
+	}
+	winner, loser := repo.gameResults[0], repo.gameResults[1]
+	if winner.UserID != "p1" || winner.VictoryType != models.VictoryConquest || winner.FireMasteryYears != 101 {
+		t.Errorf("Expected p1 to win by conquest after mastering fire in 101 years, got %+v", winner)
+	}
+	if loser.UserID != "p2" || loser.VictoryType != "" || loser.FireMasteryYears != 0 {
+		t.Errorf("Expected p2 to have lost without mastering fire, got %+v", loser)
+	}
+}
+
+func TestGameEngine_AttentionFidelity(t *testing.T) {
+	engine := NewGameEngine(NewMockRepository())
+	game := &models.Game{GameID: "game1", SimulationFidelity: models.SimulationFidelityAttention}
+	if game.Fidelity() != models.SimulationFidelityAttention {
+		t.Fatalf("Expected attention fidelity to be kept, got %q", game.Fidelity())
+	}
+	conditions := simulator.DefaultStartingConditions()
+	advance := func(settlementID string) *simulator.DailyMetrics {
+		return engine.advanceSettlementYear(game, &models.Settlement{SettlementID: settlementID}, simulator.NewSimulation(conditions, 7))
+	}
+	daily := simulator.NewSimulation(conditions, 7).AdvanceDays(simulator.DaysPerYear)
+	cohorts := simulator.NewSimulation(conditions, 7).AdvanceCohorts(simulator.DaysPerYear)
+
+	// Settlements a player looks at run the daily model, the rest cohorts
+	handler := NewQueryHandler(engine)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/attention?settlementId=watched", nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("Expected attention to be noted, got %d", recorder.Code)
+	}
+	if got := advance("watched"); *got != *daily {
+		t.Errorf("Expected a watched settlement to run daily, got %+v", got)
+	}
+	if got := advance("ignored"); *got != *cohorts {
+		t.Errorf("Expected an ignored settlement to run as cohorts, got %+v", got)
+	}
+
+	// Attention lapses after the window
+	engine.attention["stale"] = time.Now().Add(-2 * attentionWindow)
+	if got := advance("stale"); *got != *cohorts {
+		t.Errorf("Expected lapsed attention to fall back to cohorts, got %+v", got)
+	}
+	if _, ok := engine.attention["stale"]; ok {
+		t.Error("Expected lapsed attention to be forgotten")
+	}
+
+	// Other fidelities ignore attention
+	game.SimulationFidelity = models.SimulationFidelityDaily
+	if got := advance("ignored"); *got != *daily {
+		t.Errorf("Expected daily fidelity to run every settlement daily, got %+v", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/attention", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected attention without a settlement to be 400, got %d", recorder.Code)
+	}
+}
+
+func TestGameEngine_AdaptiveScheduling(t *testing.T) {
+	engine := NewGameEngine(NewMockRepository())
+	engine.SetAdaptiveFidelity(true)
+	now := time.Now()
+	recent, stale := now.Add(-2*time.Second), now.Add(-5*time.Second)
+	a := &models.Game{GameID: "a", State: "started", LastTickAt: &recent}
+	b := &models.Game{GameID: "b", State: "started", LastTickAt: &stale}
+	c := &models.Game{GameID: "c", State: "started", LastTickAt: &now}
+
+	// Only due games are scheduled, most overdue first
+	due := scheduleTicks([]*models.Game{a, b, c})
+	if len(due) != 2 || due[0] != b || due[1] != a {
+		t.Fatalf("Expected b then a to be due, got %v", due)
+	}
+
+	// A game whose ticks would overrun the pass waits for the next one,
+	// unless it is first in line
+	engine.recordTickCost(a, 2*pollInterval)
+	if engine.withinBudget(a, now, false) || !engine.withinBudget(a, now, true) || !engine.withinBudget(b, now, false) {
+		t.Error("Expected only the costly game to be deferred")
+	}
+
+	// Consistently overrunning the interval drops a game to aggregated simulation
+	for i := 0; i < overloadTicks; i++ {
+		engine.applyFidelity(a)
+		if a.Fidelity() != models.SimulationFidelityDaily {
+			t.Fatalf("Expected daily fidelity before %d overruns, got %s", overloadTicks, a.Fidelity())
+		}
+		engine.recordTickCost(a, 2*models.TickInterval)
+	}
+	engine.applyFidelity(a)
+	if a.Fidelity() != models.SimulationFidelityAggregated {
+		t.Errorf("Expected an overloaded game to be aggregated, got %s", a.Fidelity())
+	}
+
+	// It recovers once its average falls well under the interval
+	for i := 0; i < 20 && engine.tickCosts["a"].overloaded; i++ {
+		engine.recordTickCost(a, 10*time.Millisecond)
+	}
+	if engine.tickCosts["a"].overloaded {
+		t.Errorf("Expected the game to recover, average %v", engine.tickCosts["a"].average)
+	}
+	fresh := &models.Game{GameID: "a", State: "started"}
+	engine.applyFidelity(fresh)
+	if fresh.Fidelity() != models.SimulationFidelityDaily {
+		t.Errorf("Expected a recovered game to keep its fidelity, got %s", fresh.Fidelity())
+	}
+}
+
+func TestGameEngine_GameSummary(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	engine := NewGameEngine(repo)
+	game := &models.Game{GameID: "game1", State: models.GameStateActive, CurrentYear: -4900, PlayerList: []string{"p1", "p2"},
+		FireMastery: map[string]int{"p1": -4900, "p2": -4950}}
+	repo.games["game1"] = game
+	p1, p2 := "p1", "p2"
+	repo.mapTiles["game1"] = []*models.MapTile{
+		{GameID: "game1", X: 0, Y: 0, OwnerID: &p1},
+		{GameID: "game1", X: 1, Y: 0, OwnerID: &p1},
+		{GameID: "game1", X: 2, Y: 0, OwnerID: &p2},
+		{GameID: "game1", X: 3, Y: 0},
+	}
+	home := &models.Settlement{SettlementID: "s1", GameID: "game1", PlayerID: "p1", Name: "Home", Population: 500}
+	outpost := &models.Settlement{SettlementID: "s2", GameID: "game1", PlayerID: "p1", Name: "Outpost", Population: 200}
+	rival := &models.Settlement{SettlementID: "s3", GameID: "game1", PlayerID: "p2", Name: "Rival", Population: 900}
+	repo.settlements = []*models.Settlement{home, outpost, rival}
+	repo.events = []*models.GameEvent{
+		{EventID: "e1", GameID: "game1", Year: -4950, Type: models.EventUnitMoved},
+		{EventID: "e2", GameID: "game1", Year: -4920, Type: models.EventRevolt, PlayerID: "p2"},
+	}
+
+	sim := simulator.NewSimulation(simulator.DefaultStartingConditions(), 1)
+	sim.AddScience(40)
+	engine.settlementSims["s1"] = sim
+
+	// Standings are sampled only every historyInterval years
+	if err := engine.processHistory(ctx, game); err != nil {
+		t.Fatalf("processHistory failed: %v", err)
+	}
+	game.CurrentYear = -4895
+	if err := engine.processHistory(ctx, game); err != nil {
+		t.Fatalf("processHistory failed: %v", err)
+	}
+	if len(repo.gameHistory) != 1 || repo.gameHistory[0].Players[1] != (models.PlayerStanding{PlayerID: "p2", Population: 900, Territory: 1, Settlements: 1}) {
+		t.Fatalf("Expected one sample with p2's standing, got %+v", repo.gameHistory)
+	}
+
+	// Winning writes the summary, with the final standings sampled too
+	repo.settlements = []*models.Settlement{home, outpost}
+	game.EliminatedPlayers = []string{"p2"}
+	if err := engine.processVictory(ctx, game); err != nil {
+		t.Fatalf("processVictory failed: %v", err)
+	}
+	summary, err := repo.GetGameSummary(ctx, "game1")
+	if err != nil {
+		t.Fatalf("Expected a summary once the game is won: %v", err)
+	}
+	if summary.EndYear != -4895 || summary.VictoryType != models.VictoryConquest || len(summary.Winners) != 1 || len(summary.History) != 2 {
+		t.Errorf("Expected a conquest by p1 in -4895 with two samples, got %+v", summary)
+	}
+	if leader := summary.Standings[0]; leader.PlayerID != "p1" || leader.Population != 700 || leader.Territory != 2 || leader.Science != 40 {
+		t.Errorf("Expected p1 to lead the final standings, got %+v", summary.Standings)
+	}
+	var timeline []string
+	for _, event := range summary.Timeline {
+		timeline = append(timeline, event.Type)
+	}
+	if len(timeline) != 2 || timeline[0] != models.EventRevolt || timeline[1] != models.EventVictory {
+		t.Errorf("Expected the revolt and victory in the timeline, got %v", timeline)
+	}
+
+	records := make(map[string]models.Superlative)
+	for _, record := range summary.Superlatives {
+		records[record.Title] = record
+	}
+	if city := records["Largest city"]; city.SettlementID != "s1" || city.Value != 500 {
+		t.Errorf("Expected Home to be the largest city, got %+v", city)
+	}
+	if peak := records["Most populous civilization"]; peak.PlayerID != "p2" || peak.Value != 900 || peak.Year != -4900 {
+		t.Errorf("Expected p2's peak population to stand, got %+v", peak)
+	}
+	if fire := records["First to master fire"]; fire.PlayerID != "p2" || fire.Value != 51 {
+		t.Errorf("Expected p2 to have mastered fire first, got %+v", fire)
+	}
+}
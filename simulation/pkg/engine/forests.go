@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// environmentInterval is how many game years pass between environment ticks
+const environmentInterval = 10
+
+// executeChopOrder fells the forest under a workers unit. The player's
+// nearest settlement banks the timber as a one-time production windfall, and
+// the surrounding soil dries out without the trees.
+func (e *GameEngine) executeChopOrder(ctx context.Context, game *models.Game, order *models.Order, unit *models.Unit) error {
+	if unit == nil || unit.PlayerID != order.PlayerID {
+		return fmt.Errorf("unit %s not found for player", order.UnitID)
+	}
+	if unit.UnitType != models.UnitTypeWorkers {
+		return fmt.Errorf("unit %s is not a workers unit", unit.UnitID)
+	}
+
+	tile, err := e.repo.GetMapTile(ctx, game.GameID, unit.Location.X, unit.Location.Y)
+	if err != nil || tile == nil {
+		return fmt.Errorf("no tile at (%d, %d)", unit.Location.X, unit.Location.Y)
+	}
+	if !terrain.IsForest(tile.TerrainType) {
+		return fmt.Errorf("no forest to chop at (%d, %d)", tile.X, tile.Y)
+	}
+
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, unit.PlayerID)
+	if err != nil {
+		return err
+	}
+	var nearest *models.Settlement
+	for _, settlement := range settlements {
+		if nearest == nil || manhattan(settlement.Location, unit.Location) < manhattan(nearest.Location, unit.Location) {
+			nearest = settlement
+		}
+	}
+	if nearest == nil {
+		return fmt.Errorf("player %s has no settlement to bank the timber", unit.PlayerID)
+	}
+
+	windfall := terrain.ChopYield(tile)
+	terrain.ClearForest(tile)
+	tile.LastModifiedTick = game.CurrentYear
+	if err := e.repo.UpdateTileTerrain(ctx, tile); err != nil {
+		return err
+	}
+	e.invalidatePaths(game.GameID)
+	nearest.Production += windfall
+	if err := e.repo.UpdateSettlement(ctx, nearest); err != nil {
+		return err
+	}
+	log.Printf("Game %s: workers %s felled the forest at (%d, %d) for %d production in %s",
+		game.GameID, unit.UnitID, tile.X, tile.Y, windfall, nearest.Name)
+
+	changed := append([]*models.MapTile{tile}, e.shiftForestMoisture(ctx, game, tile, -terrain.ForestMoisture)...)
+	return e.refreshSettlementYields(ctx, game, changed)
+}
+
+// processEnvironment runs the slow environment tick every environmentInterval
+// years, once for each such year the game tick spans
+func (e *GameEngine) processEnvironment(ctx context.Context, game *models.Game) error {
+	for _, year := range game.TickYearsEvery(environmentInterval) {
+		if err := e.regrowForests(ctx, game, year); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// regrowForests is the environment tick of a year: grassland bordering
+// woodland may be reclaimed by the forest, with each neighboring forest
+// raising the odds, and the regrown trees moisten the soil around them again
+func (e *GameEngine) regrowForests(ctx context.Context, game *models.Game, year int) error {
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return err
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	centers := make(map[models.Location]bool, len(settlements))
+	for _, settlement := range settlements {
+		centers[settlement.Location] = true
+	}
+
+	// Visit tiles in a fixed order so the draws replay identically
+	sorted := append([]*models.MapTile(nil), tiles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+	tileAt := make(map[models.Location]*models.MapTile, len(sorted))
+	for _, tile := range sorted {
+		tileAt[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+
+	// Decide every regrowth against the forest as it stood at the start of
+	// the tick, so woodland spreads at most one tile per environment tick
+	stream := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, fmt.Sprintf("environment:%d", year)))
+	type regrowth struct {
+		tile       *models.MapTile
+		forestType string
+	}
+	var regrown []regrowth
+	for _, tile := range sorted {
+		if tile.TerrainType != terrain.Grassland || len(tile.Improvements) > 0 || centers[models.Location{X: tile.X, Y: tile.Y}] {
+			continue
+		}
+		counts := make(map[string]int)
+		for _, loc := range grid.Unbounded.Neighbors8(models.Location{X: tile.X, Y: tile.Y}) {
+			if neighbor := tileAt[loc]; neighbor != nil && terrain.IsForest(neighbor.TerrainType) {
+				counts[neighbor.TerrainType]++
+			}
+		}
+		forested := counts[terrain.Forest] + counts[terrain.Jungle]
+		if forested == 0 || stream.Float64() >= terrain.ForestRegrowthChance*float64(forested) {
+			continue
+		}
+		forestType := terrain.Forest
+		if counts[terrain.Jungle] > counts[terrain.Forest] {
+			forestType = terrain.Jungle
+		}
+		regrown = append(regrown, regrowth{tile: tile, forestType: forestType})
+	}
+
+	var changed []*models.MapTile
+	for _, r := range regrown {
+		// Reload the tile: an earlier regrowth may have moistened its soil
+		tile, err := e.repo.GetMapTile(ctx, game.GameID, r.tile.X, r.tile.Y)
+		if err != nil || tile == nil || !terrain.RegrowForest(tile, r.forestType) {
+			continue
+		}
+		tile.LastModifiedTick = game.CurrentYear
+		if err := e.repo.UpdateTileTerrain(ctx, tile); err != nil {
+			log.Printf("Error regrowing forest at (%d, %d) in game %s: %v", tile.X, tile.Y, game.GameID, err)
+			continue
+		}
+		changed = append(changed, tile)
+		changed = append(changed, e.shiftForestMoisture(ctx, game, tile, terrain.ForestMoisture)...)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	e.invalidatePaths(game.GameID)
+	log.Printf("Game %s: forest reclaimed %d tiles", game.GameID, len(regrown))
+	return e.refreshSettlementYields(ctx, game, changed)
+}
+
+// shiftForestMoisture moves the soil fertility of tiles within the forest
+// moisture radius of a felled or regrown forest, returning those that changed
+func (e *GameEngine) shiftForestMoisture(ctx context.Context, game *models.Game, forest *models.MapTile, delta float64) []*models.MapTile {
+	var changed []*models.MapTile
+	center := models.Location{X: forest.X, Y: forest.Y}
+	for _, loc := range grid.Unbounded.Disk(center, terrain.ForestMoistureRadius) {
+		if loc == center {
+			continue
+		}
+		tile, err := e.repo.GetMapTile(ctx, game.GameID, loc.X, loc.Y)
+		if err != nil || tile == nil || !terrain.ShiftMoisture(tile, delta) {
+			continue
+		}
+		tile.LastModifiedTick = game.CurrentYear
+		if err := e.repo.UpdateTileTerrain(ctx, tile); err != nil {
+			log.Printf("Error updating soil moisture at (%d, %d) in game %s: %v", tile.X, tile.Y, game.GameID, err)
+			continue
+		}
+		changed = append(changed, tile)
+	}
+	return changed
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"log"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// gameStartYear is the year every game begins in; players with no recorded
+// activity are treated as last seen then
+const gameStartYear = -5000
+
+// governorWorkerAutomation is the mode the governor gives idle workers
+const governorWorkerAutomation = models.AutomationFocusFood
+
+// absentPlayers returns the players of a game who have not used the API for
+// at least their governor threshold: their own if their settings give one,
+// else the game's. Players who have never connected are handed over once
+// the no-show grace period, if shorter, runs out. Players who turned the
+// governor off are never handed over.
+func absentPlayers(game *models.Game, activity []*models.PlayerActivity, settings map[string]*models.PlayerSettings) map[string]bool {
+	lastActive := make(map[string]int, len(activity))
+	for _, record := range activity {
+		lastActive[record.PlayerID] = record.LastActiveTick
+	}
+
+	absent := make(map[string]bool)
+	for _, playerID := range game.ActivePlayers() {
+		if settings[playerID] != nil && settings[playerID].GovernorOff {
+			continue
+		}
+		last, ok := lastActive[playerID]
+		threshold := settings[playerID].GovernorThreshold(game)
+		if !ok {
+			last = gameStartYear
+			if game.NoShowGraceTicks > 0 && !game.ReleasesNoShows() {
+				threshold = min(threshold, game.NoShowGraceTicks)
+			}
+		}
+		if game.TicksSince(last) >= threshold {
+			absent[playerID] = true
+		}
+	}
+	return absent
+}
+
+// processGovernor decides which players the absent player governor runs this
+// tick. Governed players' settlers settle without waiting for orders and
+// their idle workers are automated; decisions go back to the player as soon
+// as they reconnect.
+func (e *GameEngine) processGovernor(ctx context.Context, game *models.Game) error {
+	activity, err := e.repo.GetPlayerActivity(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	absent := absentPlayers(game, activity, e.playerSettings(ctx, game.GameID))
+
+	e.governorMu.Lock()
+	previous := e.governed[game.GameID]
+	e.governed[game.GameID] = absent
+	e.governorMu.Unlock()
+
+	for playerID := range absent {
+		if !previous[playerID] {
+			log.Printf("Game %s: governor taking over for absent player %s", game.GameID, playerID)
+		}
+	}
+	for playerID := range previous {
+		if !absent[playerID] {
+			log.Printf("Game %s: player %s is back, governor standing down", game.GameID, playerID)
+		}
+	}
+	return nil
+}
+
+// Governed reports whether the governor is running a player's civilization
+func (e *GameEngine) Governed(gameID, playerID string) bool {
+	e.governorMu.RLock()
+	defer e.governorMu.RUnlock()
+	return e.governed[gameID][playerID]
+}
+
+// workerMode returns the automation mode a worker runs under this tick:
+// its own, or the governor's when the worker is idle and its player absent
+func (e *GameEngine) workerMode(game *models.Game, worker *models.Unit) string {
+	if worker.Automation == "" && e.Governed(game.GameID, worker.PlayerID) {
+		return governorWorkerAutomation
+	}
+	return worker.Automation
+}
@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+const (
+	buildingGreatPoints   = 3     // Points each building adds toward its great person every year
+	techGreatPoints       = 1     // Great scientist points each known technology adds every year
+	greatScientistScience = 200.0 // Science a great scientist brings their settlement
+	generalAuraRadius     = 2     // How far a great general's inspiration reaches
+	generalAuraYears      = 10    // Years inspired units fight harder
+	generalAuraBonus      = 0.5   // Strength bonus of inspired units
+)
+
+// greatPersonSources maps each building to the great person it draws
+var greatPersonSources = map[string]string{
+	models.BuildingHarbor: models.UnitTypeGreatScientist,
+	"treasury":            models.UnitTypeGreatScientist,
+	"forge":               models.UnitTypeGreatBuilder,
+	"bronze_works":        models.UnitTypeGreatGeneral,
+}
+
+// greatPersonNames are the names great people of each type are born with
+var greatPersonNames = map[string][]string{
+	models.UnitTypeGreatScientist: {"Imhotep", "Hypatia", "Aryabhata", "Zhang Heng", "Al-Khwarizmi", "Eratosthenes"},
+	models.UnitTypeGreatBuilder:   {"Hemiunu", "Ictinus", "Vitruvius", "Apollodorus", "Yu the Great", "Senenmut"},
+	models.UnitTypeGreatGeneral:   {"Sargon", "Thutmose", "Sun Tzu", "Hannibal", "Chandragupta", "Boudica"},
+}
+
+// greatPersonTypes lists great person types in the order they are born
+// when a settlement completes several in the same year
+var greatPersonTypes = []string{models.UnitTypeGreatScientist, models.UnitTypeGreatBuilder, models.UnitTypeGreatGeneral}
+
+// processGreatPeople adds each settlement's great person points for the
+// years the tick spans, from its buildings and, for scientists, its
+// technologies, and gives birth to a great person once a type's points reach
+// the settlement's threshold
+func (e *GameEngine) processGreatPeople(ctx context.Context, game *models.Game) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+
+	for _, settlement := range settlements {
+		if settlement.PlayerID == models.NeutralPlayerID || models.IsMinorCiv(settlement.PlayerID) {
+			continue
+		}
+		earned := make(map[string]int)
+		for _, building := range settlement.Buildings {
+			if greatType, ok := greatPersonSources[building]; ok {
+				earned[greatType] += buildingGreatPoints
+			}
+		}
+		if len(settlement.Technologies) > 0 {
+			earned[models.UnitTypeGreatScientist] += techGreatPoints * len(settlement.Technologies)
+		}
+		if len(earned) == 0 {
+			continue
+		}
+
+		if settlement.GreatPoints == nil {
+			settlement.GreatPoints = make(map[string]int)
+		}
+		for _, greatType := range greatPersonTypes {
+			if earned[greatType] == 0 {
+				continue
+			}
+			settlement.GreatPoints[greatType] += earned[greatType] * game.YearsPerTick()
+			threshold := game.Ruleset().GreatPersonThreshold * (settlement.GreatPeople + 1)
+			if settlement.GreatPoints[greatType] < threshold {
+				continue
+			}
+			if err := e.createGreatPerson(ctx, game, settlement, greatType); err != nil {
+				log.Printf("Error creating %s in settlement %s: %v", greatType, settlement.SettlementID, err)
+				continue
+			}
+			settlement.GreatPoints[greatType] -= threshold
+			settlement.GreatPeople++
+		}
+
+		settlement.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating great person points of settlement %s: %v", settlement.SettlementID, err)
+		}
+	}
+	return nil
+}
+
+// createGreatPerson creates a named great person unit in a settlement
+func (e *GameEngine) createGreatPerson(ctx context.Context, game *models.Game, settlement *models.Settlement, greatType string) error {
+	names := greatPersonNames[greatType]
+	stream := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, fmt.Sprintf("great-person:%s:%d", settlement.SettlementID, settlement.GreatPeople)))
+
+	now := time.Now()
+	unit := &models.Unit{
+		UnitID:      generateUUID(),
+		GameID:      game.GameID,
+		PlayerID:    settlement.PlayerID,
+		UnitType:    greatType,
+		Name:        names[stream.Intn(len(names))],
+		Location:    settlement.Location,
+		CreatedAt:   now,
+		LastUpdated: now,
+	}
+	if err := e.repo.CreateUnit(ctx, unit); err != nil {
+		return err
+	}
+	log.Printf("Game %s: %s, a %s, was born in %s", game.GameID, unit.Name, greatType, settlement.Name)
+	return nil
+}
+
+// executeActivateOrder spends a great person on their one-time ability: a
+// scientist brings the settlement they stand in, or the player's nearest,
+// science at once; a builder improves the player's tile they stand on; a
+// general inspires the player's units around them for generalAuraYears
+func (e *GameEngine) executeActivateOrder(ctx context.Context, game *models.Game, order *models.Order, unit *models.Unit) error {
+	if unit == nil || unit.PlayerID != order.PlayerID {
+		return fmt.Errorf("unit %s not found for player", order.UnitID)
+	}
+
+	var err error
+	switch unit.UnitType {
+	case models.UnitTypeGreatScientist:
+		err = e.activateGreatScientist(ctx, game, unit)
+	case models.UnitTypeGreatBuilder:
+		err = e.activateGreatBuilder(ctx, game, unit)
+	case models.UnitTypeGreatGeneral:
+		err = e.activateGreatGeneral(ctx, game, unit)
+	default:
+		return fmt.Errorf("unit %s is not a great person", unit.UnitID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := e.repo.DeleteUnit(ctx, unit.UnitID); err != nil {
+		return err
+	}
+	log.Printf("Game %s: %s the %s was spent", game.GameID, unit.Name, unit.UnitType)
+	return nil
+}
+
+// activateGreatScientist adds science to the player's settlement nearest
+// the scientist, unlocking what it completes
+func (e *GameEngine) activateGreatScientist(ctx context.Context, game *models.Game, unit *models.Unit) error {
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, unit.PlayerID)
+	if err != nil {
+		return err
+	}
+	var nearest *models.Settlement
+	for _, settlement := range settlements {
+		if nearest == nil || manhattan(settlement.Location, unit.Location) < manhattan(nearest.Location, unit.Location) {
+			nearest = settlement
+		}
+	}
+	if nearest == nil {
+		return fmt.Errorf("player %s has no settlement to teach", unit.PlayerID)
+	}
+
+	sim := e.settlementSimulation(ctx, game, nearest)
+	sim.AddScience(greatScientistScience)
+	nearest.Technologies = sim.Technologies()
+	nearest.LastUpdated = time.Now()
+	if err := e.repo.UpdateSettlement(ctx, nearest); err != nil {
+		return err
+	}
+	e.recordFireMastery(ctx, game, nearest)
+	return nil
+}
+
+// activateGreatBuilder builds the improvement workers would on the tile the
+// builder stands on, which the player must own
+func (e *GameEngine) activateGreatBuilder(ctx context.Context, game *models.Game, unit *models.Unit) error {
+	tile, err := e.repo.GetMapTile(ctx, game.GameID, unit.Location.X, unit.Location.Y)
+	if err != nil || tile == nil {
+		return fmt.Errorf("no tile at (%d, %d)", unit.Location.X, unit.Location.Y)
+	}
+	if tile.OwnerID == nil || *tile.OwnerID != unit.PlayerID {
+		return fmt.Errorf("(%d, %d) is outside the player's territory", tile.X, tile.Y)
+	}
+	if isImproved(tile) {
+		return fmt.Errorf("(%d, %d) is already improved", tile.X, tile.Y)
+	}
+
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, unit.PlayerID)
+	if err != nil {
+		return err
+	}
+	pastoral := false
+	for _, settlement := range settlements {
+		pastoral = pastoral || containsString(settlement.Technologies, simulator.TechHusbandry)
+	}
+	improvement := tileImprovement(tile, pastoral)
+	if improvement == "" {
+		return fmt.Errorf("nothing can be built at (%d, %d)", tile.X, tile.Y)
+	}
+	if err := e.repo.AddTileImprovement(ctx, game.GameID, tile.X, tile.Y, improvement, game.CurrentYear); err != nil {
+		return err
+	}
+	tile.Improvements = append(tile.Improvements, improvement)
+	return e.refreshSettlementYields(ctx, game, []*models.MapTile{tile})
+}
+
+// activateGreatGeneral inspires the player's units within the general's
+// reach
+func (e *GameEngine) activateGreatGeneral(ctx context.Context, game *models.Game, general *models.Unit) error {
+	units, err := e.repo.GetUnitsByPlayer(ctx, game.GameID, general.PlayerID)
+	if err != nil {
+		return err
+	}
+	for _, unit := range units {
+		if unit.UnitID == general.UnitID || models.IsGreatPerson(unit.UnitType) {
+			continue
+		}
+		if abs(unit.Location.X-general.Location.X) > generalAuraRadius || abs(unit.Location.Y-general.Location.Y) > generalAuraRadius {
+			continue
+		}
+		unit.Aura = generalAuraYears
+		unit.LastUpdated = time.Now()
+		if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+// GrowthCheck is a settlement whose growth CompareGrowth follows in the
+// engine and in the standalone simulator
+type GrowthCheck struct {
+	Seed       string // Game seed; the settlement's simulation seed derives from it
+	Population int    // Humans at the start
+	Terrain    string // Terrain of every tile the settlement works
+	Fidelity   string // Simulation fidelity the engine's game runs at
+	Years      int
+	Restart    bool // Start a fresh engine every year, resuming from saved simulations as after a restart
+}
+
+// GrowthYear is the state of both population models at the end of a year
+type GrowthYear struct {
+	Year                  int     `json:"year"`
+	EnginePopulation      int     `json:"enginePopulation"`
+	SimulatorPopulation   int     `json:"simulatorPopulation"`
+	Gap                   float64 `json:"gap"` // Population difference as a share of the larger, 0 to 1
+	EngineTechnologies    int     `json:"engineTechnologies"`
+	SimulatorTechnologies int     `json:"simulatorTechnologies"`
+}
+
+// GrowthComparison is how far the engine's settlement growth strayed from
+// the standalone simulator's over a check
+type GrowthComparison struct {
+	Check           GrowthCheck  `json:"check"`
+	Years           []GrowthYear `json:"years"`
+	MaxGap          float64      `json:"maxGap"`
+	MeanGap         float64      `json:"meanGap"`
+	FinalGap        float64      `json:"finalGap"`
+	TechnologyYears int          `json:"technologyYears"` // Years the two knew different technologies
+}
+
+// CompareGrowth runs a settlement's growth in the engine, on an in-memory
+// game, and in a standalone simulation from the same starting conditions
+// and seed, reporting how far the two populations drift apart each year.
+// At daily fidelity the two should agree exactly; other fidelities and
+// resuming from saved simulations show how far the engine's shortcuts
+// stray from the full model.
+func CompareGrowth(ctx context.Context, check GrowthCheck) (*GrowthComparison, error) {
+	if check.Years < 1 {
+		return nil, fmt.Errorf("a growth check needs at least one year, got %d", check.Years)
+	}
+	if check.Population < 1 {
+		return nil, fmt.Errorf("a growth check needs a population, got %d", check.Population)
+	}
+
+	repo := repository.NewMemoryRepository()
+	game := &models.Game{
+		GameID:             "growthcheck",
+		State:              "started",
+		CurrentYear:        gameStartYear,
+		Seeds:              models.NewGameSeeds(check.Seed),
+		SimulationFidelity: check.Fidelity,
+	}
+	settlement := &models.Settlement{SettlementID: "settlement", GameID: game.GameID, Population: check.Population}
+	radius := settlement.WorkRadius(game.Ruleset())
+	settlement.Location = models.Location{X: radius, Y: radius}
+	var tiles []*models.MapTile
+	for y := 0; y <= 2*radius; y++ {
+		for x := 0; x <= 2*radius; x++ {
+			tiles = append(tiles, &models.MapTile{GameID: game.GameID, X: x, Y: y, TerrainType: check.Terrain})
+		}
+	}
+	repo.InsertGame(game)
+	if err := repo.SaveMapTiles(ctx, tiles); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateSettlement(ctx, settlement); err != nil {
+		return nil, err
+	}
+
+	engine := NewGameEngine(repo)
+	conditions := simulator.DefaultStartingConditions()
+	conditions.Population = check.Population
+	applyWorkArea(&conditions, engine.settlementWorkTiles(ctx, game, settlement))
+	seed := rng.DeriveSeed(game.Seeds.Master, "settlement:"+settlement.SettlementID)
+	standalone := simulator.NewSimulation(conditions, int(seed&0x7fffffff))
+	standalone.SetRates(&game.Ruleset().Settlement)
+
+	comparison := &GrowthComparison{Check: check}
+	for i := 0; i < check.Years; i++ {
+		if check.Restart {
+			engine = NewGameEngine(repo)
+		}
+		if err := engine.processSettlementGrowth(ctx, game); err != nil {
+			return nil, err
+		}
+		standalone.AdvanceDays(simulator.DaysPerYear)
+
+		settlements, err := repo.GetSettlements(ctx, game.GameID)
+		if err != nil {
+			return nil, err
+		}
+		grown := settlements[0]
+		year := GrowthYear{
+			Year:                  game.CurrentYear,
+			EnginePopulation:      grown.Population,
+			SimulatorPopulation:   standalone.Population(),
+			EngineTechnologies:    len(grown.Technologies),
+			SimulatorTechnologies: len(standalone.Technologies()),
+		}
+		year.Gap = math.Abs(float64(year.EnginePopulation-year.SimulatorPopulation)) / float64(max(year.EnginePopulation, year.SimulatorPopulation, 1))
+		comparison.Years = append(comparison.Years, year)
+		comparison.MaxGap = max(comparison.MaxGap, year.Gap)
+		comparison.MeanGap += year.Gap / float64(check.Years)
+		if year.EngineTechnologies != year.SimulatorTechnologies {
+			comparison.TechnologyYears++
+		}
+		game.CurrentYear++
+	}
+	comparison.FinalGap = comparison.Years[len(comparison.Years)-1].Gap
+	return comparison, nil
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// defaultInvariantInterval is how many game years pass between invariant checks
+const defaultInvariantInterval = 10
+
+// cheapestMoveCost is the least a step can cost, by road or along a river
+var cheapestMoveCost = math.Min(terrain.RoadMoveCost, terrain.RiverMoveCost)
+
+// Invariant names reported in violations
+const (
+	InvariantUnitTeleported     = "unit_teleported"
+	InvariantNegativeStockpile  = "negative_stockpile"
+	InvariantPopulationMismatch = "population_mismatch"
+	InvariantTileOwnership      = "tile_ownership"
+)
+
+// InvariantViolation describes one broken invariant and the entity responsible
+type InvariantViolation struct {
+	Invariant string
+	EntityID  string
+	Year      int
+	Detail    string
+}
+
+// unitSighting is where a unit was seen at the last invariant check, with
+// the movement it had saved then
+type unitSighting struct {
+	Location  models.Location
+	Year      int
+	MovesLeft float64
+}
+
+// maxUnitSteps returns the farthest (Manhattan distance) a unit may travel
+// over some years on its movement points and those it had saved, were every
+// step as cheap as a step can be
+func maxUnitSteps(unit *models.Unit, years int, saved float64) int {
+	return int(math.Floor((movementPoints(unit)*float64(years)+saved)/cheapestMoveCost + moveSlack))
+}
+
+// SetInvariantCheckInterval sets how many game years pass between invariant
+// checks; 0 disables them
+func (e *GameEngine) SetInvariantCheckInterval(years int) {
+	e.invariantInterval = years
+}
+
+// invariantIntervalFromEnv reads INVARIANT_CHECK_INTERVAL, falling back to the default
+func invariantIntervalFromEnv() int {
+	if value := os.Getenv("INVARIANT_CHECK_INTERVAL"); value != "" {
+		if years, err := strconv.Atoi(value); err == nil && years >= 0 {
+			return years
+		}
+		log.Printf("Ignoring invalid INVARIANT_CHECK_INTERVAL %q", value)
+	}
+	return defaultInvariantInterval
+}
+
+// processInvariantChecks runs the invariant checks every invariantInterval
+// years and logs each violation
+func (e *GameEngine) processInvariantChecks(ctx context.Context, game *models.Game) error {
+	if e.invariantInterval <= 0 || len(game.TickYearsEvery(e.invariantInterval)) == 0 {
+		return nil
+	}
+
+	violations, err := e.checkInvariants(ctx, game)
+	if err != nil {
+		return err
+	}
+	for _, v := range violations {
+		log.Printf("Invariant violation in game %s at year %d: %s %s: %s", game.GameID, v.Year, v.Invariant, v.EntityID, v.Detail)
+	}
+	return nil
+}
+
+// checkInvariants validates that no unit moved farther than it could since the
+// last check, that no resource or food stockpile is negative, that settlement
+// populations match their simulations and that tile ownership agrees with the
+// owning settlement
+func (e *GameEngine) checkInvariants(ctx context.Context, game *models.Game) ([]InvariantViolation, error) {
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return nil, err
+	}
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	year := game.CurrentYear
+	var violations []InvariantViolation
+	report := func(invariant, entityID, format string, args ...interface{}) {
+		violations = append(violations, InvariantViolation{
+			Invariant: invariant,
+			EntityID:  entityID,
+			Year:      year,
+			Detail:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Units move at most as far as their movement points carry them
+	previous := e.unitSightings[game.GameID]
+	sightings := make(map[string]unitSighting, len(units))
+	for _, unit := range units {
+		if last, ok := previous[unit.UnitID]; ok {
+			distance := abs(unit.Location.X-last.Location.X) + abs(unit.Location.Y-last.Location.Y)
+			if allowed := maxUnitSteps(unit, year-last.Year, last.MovesLeft); distance > allowed {
+				report(InvariantUnitTeleported, unit.UnitID, "moved %d tiles from (%d, %d) to (%d, %d) in %d years",
+					distance, last.Location.X, last.Location.Y, unit.Location.X, unit.Location.Y, year-last.Year)
+			}
+		}
+		sightings[unit.UnitID] = unitSighting{Location: unit.Location, Year: year, MovesLeft: unit.MovesLeft}
+	}
+	e.unitSightings[game.GameID] = sightings
+
+	settlementsByID := make(map[string]*models.Settlement, len(settlements))
+	for _, settlement := range settlements {
+		settlementsByID[settlement.SettlementID] = settlement
+
+		if settlement.Population < 0 {
+			report(InvariantPopulationMismatch, settlement.SettlementID, "negative population %d", settlement.Population)
+		}
+		if sim, ok := e.settlementSims[settlement.SettlementID]; ok {
+			if sim.Population() != settlement.Population {
+				report(InvariantPopulationMismatch, settlement.SettlementID, "recorded population %d, simulation has %d",
+					settlement.Population, sim.Population())
+			}
+			if sim.State.FoodStockpile < 0 {
+				report(InvariantNegativeStockpile, settlement.SettlementID, "food stockpile %.1f", sim.State.FoodStockpile)
+			}
+		}
+	}
+
+	for _, tile := range tiles {
+		tileID := fmt.Sprintf("tile(%d,%d)", tile.X, tile.Y)
+		for resource, quantity := range tile.ResourceQuantities {
+			if quantity < 0 {
+				report(InvariantNegativeStockpile, tileID, "%s quantity %d", resource, quantity)
+			}
+		}
+
+		if tile.SettlementID == "" {
+			continue
+		}
+		settlement, ok := settlementsByID[tile.SettlementID]
+		if !ok {
+			report(InvariantTileOwnership, tileID, "assigned to missing settlement %s", tile.SettlementID)
+			continue
+		}
+		if tile.OwnerID == nil || *tile.OwnerID != settlement.PlayerID {
+			owner := "<none>"
+			if tile.OwnerID != nil {
+				owner = *tile.OwnerID
+			}
+			report(InvariantTileOwnership, tileID, "owned by %s but assigned to settlement %s of player %s",
+				owner, settlement.SettlementID, settlement.PlayerID)
+		}
+	}
+
+	return violations, nil
+}
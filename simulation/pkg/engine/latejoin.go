@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/mapgen"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// lateJoinEraYears is how many years of play earn a late joiner one era boost
+const lateJoinEraYears = 500
+
+// maxLateJoinBoosts caps the era boosts a late joiner can receive
+const maxLateJoinBoosts = 3
+
+// boostSettlerSpacing is how many tiles apart a late joiner's settlers start
+const boostSettlerSpacing = 2
+
+// lateJoinBoosts returns how many era boosts a player joining in the given
+// year receives: each adds an extra settlers unit and a workers unit
+func lateJoinBoosts(year int) int {
+	return min((year-gameStartYear)/lateJoinEraYears, maxLateJoinBoosts)
+}
+
+// processLateJoins gives every player who joined a persistent game after its
+// map was generated a starting region and a civ to play
+func (e *GameEngine) processLateJoins(ctx context.Context, game *models.Game) error {
+	if !game.Persistent || e.spawnedPlayers[game.GameID] == len(game.PlayerList) {
+		return nil
+	}
+
+	metadata, err := e.repo.GetMapMetadata(ctx, game.GameID)
+	if err != nil || metadata == nil {
+		return err
+	}
+
+	var occupied []models.Location
+	var joiners []string
+	for _, playerID := range game.PlayerList {
+		position, err := e.repo.GetStartingPosition(ctx, game.GameID, playerID)
+		if err != nil {
+			return err
+		}
+		if position == nil {
+			joiners = append(joiners, playerID)
+			continue
+		}
+		occupied = append(occupied, models.Location{X: position.CenterX, Y: position.CenterY})
+	}
+	if len(joiners) == 0 {
+		e.spawnedPlayers[game.GameID] = len(game.PlayerList)
+		return nil
+	}
+
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	for _, settlement := range settlements {
+		occupied = append(occupied, settlement.Location)
+	}
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, playerID := range joiners {
+		position := mapgen.ReserveRegion(metadata, tiles, occupied, playerID)
+		if position == nil {
+			log.Printf("Game %s: no viable region left for late joiner %s, will retry", game.GameID, playerID)
+			continue
+		}
+		if err := e.spawnLateJoiner(ctx, game, metadata, tiles, position); err != nil {
+			return err
+		}
+		occupied = append(occupied, models.Location{X: position.CenterX, Y: position.CenterY})
+	}
+
+	return nil
+}
+
+// spawnLateJoiner reserves a late joiner's region, reveals it and creates
+// their era-boosted starting units
+func (e *GameEngine) spawnLateJoiner(ctx context.Context, game *models.Game, metadata *models.MapMetadata, tiles []*models.MapTile, position *models.StartingPosition) error {
+	position.AssignedYear = game.CurrentYear
+	position.CreatedAt = time.Now()
+	if err := e.repo.SaveStartingPositions(ctx, []*models.StartingPosition{position}); err != nil {
+		return err
+	}
+
+	rules := game.Ruleset()
+	center := models.Location{X: position.CenterX, Y: position.CenterY}
+	var visible []models.Location
+	for _, loc := range workAreaLocations(center, rules.StartingVisionRange) {
+		dx, dy := loc.X-center.X, loc.Y-center.Y
+		if dx*dx+dy*dy <= rules.StartingVisionRange*rules.StartingVisionRange {
+			visible = append(visible, loc)
+		}
+	}
+	if err := e.repo.RevealTiles(ctx, game.GameID, position.PlayerID, visible, game.CurrentYear); err != nil {
+		return err
+	}
+
+	// Later arrivals get extra settlers and workers to catch up with the era;
+	// extra settlers start on free land spread around the starting city
+	boosts := lateJoinBoosts(game.CurrentYear)
+	city := models.Location{X: position.StartingCityX, Y: position.StartingCityY}
+	sites := boostSettlerSites(metadata, tiles, position, city, boosts)
+	units := []*models.Unit{{UnitType: models.UnitTypeSettlers, Location: city, PopulationCost: rules.SettlersPopulationCost}}
+	for _, site := range sites {
+		units = append(units,
+			&models.Unit{UnitType: models.UnitTypeSettlers, Location: site, PopulationCost: rules.SettlersPopulationCost},
+			&models.Unit{UnitType: models.UnitTypeWorkers, Location: city})
+	}
+	for _, unit := range units {
+		unit.UnitID = generateUUID()
+		unit.GameID = game.GameID
+		unit.PlayerID = position.PlayerID
+		unit.CreatedAt = time.Now()
+		unit.LastUpdated = time.Now()
+		if err := e.repo.CreateUnit(ctx, unit); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Game %s: late joiner %s starts at (%d, %d) with %d era boosts",
+		game.GameID, position.PlayerID, position.CenterX, position.CenterY, boosts)
+	return nil
+}
+
+// boostSettlerSites returns where a late joiner's extra settlers start: unowned
+// land tiles of the reserved region, in rings outward from the starting city,
+// at least boostSettlerSpacing tiles from the city and each other. Settlers
+// that find no such tile start at the city.
+func boostSettlerSites(metadata *models.MapMetadata, tiles []*models.MapTile, position *models.StartingPosition, city models.Location, count int) []models.Location {
+	byLocation := make(map[models.Location]*models.MapTile, len(tiles))
+	for _, tile := range tiles {
+		byLocation[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+	footprint := position.GuaranteedFootprint
+	inRegion := func(loc models.Location) bool {
+		return loc.X >= footprint.MinX && loc.X <= footprint.MaxX && loc.Y >= footprint.MinY && loc.Y <= footprint.MaxY
+	}
+	spaced := func(loc models.Location, taken []models.Location) bool {
+		for _, other := range taken {
+			if max(abs(loc.X-other.X), abs(loc.Y-other.Y)) < boostSettlerSpacing {
+				return false
+			}
+		}
+		return true
+	}
+
+	mapGrid := grid.Grid{Width: metadata.Width, Height: metadata.Height}
+	taken := []models.Location{city}
+	var sites []models.Location
+	reach := max(footprint.MaxX-footprint.MinX, footprint.MaxY-footprint.MinY)
+	for radius := boostSettlerSpacing; radius <= reach && len(sites) < count; radius++ {
+		for _, loc := range mapGrid.Ring(city, radius) {
+			tile := byLocation[loc]
+			if tile == nil || terrain.IsWater(tile.TerrainType) || tile.OwnerID != nil || !inRegion(loc) || !spaced(loc, taken) {
+				continue
+			}
+			sites = append(sites, loc)
+			taken = append(taken, loc)
+			if len(sites) == count {
+				break
+			}
+		}
+	}
+	for len(sites) < count {
+		sites = append(sites, city)
+	}
+	return sites
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// TransitionGame moves a game to a new lifecycle state. The transition is
+// validated against the state machine and applied only if the stored game
+// is still in the state this copy was read in.
+func (e *GameEngine) TransitionGame(ctx context.Context, game *models.Game, to models.GameState) error {
+	if err := models.ValidateTransition(game.State, to); err != nil {
+		return err
+	}
+	if err := e.repo.TransitionGameState(ctx, game.GameID, game.State, to); err != nil {
+		if errors.Is(err, repository.ErrStateConflict) {
+			log.Printf("Game %s left state %q before it could move to %q", game.GameID, game.State, to)
+		}
+		return err
+	}
+
+	log.Printf("Game %s: %s -> %s", game.GameID, game.State, to)
+	game.State = to
+	return nil
+}
+
+// PauseGame holds an active game; it stops ticking until resumed
+func (e *GameEngine) PauseGame(ctx context.Context, gameID string) error {
+	game, err := e.repo.GetGame(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	return e.TransitionGame(ctx, game, models.GameStatePaused)
+}
+
+// ResumeGame reactivates a paused game. Its tick clock restarts from now so
+// the pause is not mistaken for engine downtime and replayed.
+func (e *GameEngine) ResumeGame(ctx context.Context, gameID string) error {
+	game, err := e.repo.GetGame(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if err := e.TransitionGame(ctx, game, models.GameStateActive); err != nil {
+		return err
+	}
+	return e.repo.UpdateGameTick(ctx, game.GameID, game.CurrentYear, game.CurrentYear, time.Now())
+}
+
+// ArchiveGame retires a finished game, or an abandoned lobby
+func (e *GameEngine) ArchiveGame(ctx context.Context, gameID string) error {
+	game, err := e.repo.GetGame(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	return e.TransitionGame(ctx, game, models.GameStateArchived)
+}
+
+// needsMap reports whether a game has yet to have its map generated: the
+// web server moved its full lobby to starting
+func needsMap(game *models.Game) bool {
+	return game.IsStarting()
+}
+
+// startGame generates a new game's map, records its start and moves a
+// starting game to active, stamping its tick clock so the map is never
+// generated twice
+func (e *GameEngine) startGame(ctx context.Context, game *models.Game) error {
+	if err := e.generateMapForGame(ctx, game); err != nil {
+		return err
+	}
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventGameStarted,
+		Detail:    strings.Join(game.PlayerList, ", "),
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording the start of game %s: %v", game.GameID, err)
+	}
+
+	if err := e.TransitionGame(ctx, game, models.GameStateActive); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := e.repo.UpdateGameTick(ctx, game.GameID, game.CurrentYear, game.CurrentYear, now); err != nil {
+		return err
+	}
+	game.LastTickAt = &now
+	return nil
+}
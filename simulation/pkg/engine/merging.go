@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// settlementExtent returns how many tiles a settlement's footprint reaches
+// from its center; it starts at the work radius and grows with population
+func settlementExtent(rules *models.Ruleset, population int) int {
+	extent := rules.SettlementWorkRadius + population/rules.SettlementGrowthPerRing
+	if extent > rules.MaxSettlementExtent {
+		extent = rules.MaxSettlementExtent
+	}
+	return extent
+}
+
+// settlementsAdjacent reports whether two settlements' footprints touch,
+// measuring footprints as work areas are measured
+func settlementsAdjacent(rules *models.Ruleset, a, b *models.Settlement) bool {
+	reach := settlementExtent(rules, a.Population) + settlementExtent(rules, b.Population) + 1
+	for _, loc := range workAreaLocations(a.Location, reach) {
+		if loc == b.Location {
+			return true
+		}
+	}
+	return false
+}
+
+// processSettlementMerging applies the game's merge rule to settlements of
+// the same player that have grown adjacent. Under "merge" the smaller is
+// absorbed into the larger; under "suburb" it becomes the larger's suburb.
+func (e *GameEngine) processSettlementMerging(ctx context.Context, game *models.Game) error {
+	rule := game.SettlementMergeRule
+	if rule != models.SettlementMergeMerge && rule != models.SettlementMergeSuburb {
+		return nil
+	}
+
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+
+	rules := game.Ruleset()
+	absorbed := make(map[string]bool)
+	for i, a := range settlements {
+		for j := i + 1; j < len(settlements); j++ {
+			b := settlements[j]
+			if absorbed[a.SettlementID] || absorbed[b.SettlementID] {
+				continue
+			}
+			if a.PlayerID != b.PlayerID || !settlementsAdjacent(rules, a, b) {
+				continue
+			}
+
+			larger, smaller := a, b
+			if outranks(b, a) {
+				larger, smaller = b, a
+			}
+
+			if rule == models.SettlementMergeMerge {
+				if err := e.mergeSettlement(ctx, game, settlements, larger, smaller); err != nil {
+					log.Printf("Error merging settlement %s into %s: %v", smaller.SettlementID, larger.SettlementID, err)
+					continue
+				}
+				absorbed[smaller.SettlementID] = true
+			} else if err := e.makeSuburb(ctx, game, settlements, larger, smaller); err != nil {
+				log.Printf("Error making settlement %s a suburb of %s: %v", smaller.SettlementID, larger.SettlementID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// outranks reports whether settlement a should absorb (or parent) b: the more
+// populous wins, then the older, then the lower ID
+func outranks(a, b *models.Settlement) bool {
+	if a.Population != b.Population {
+		return a.Population > b.Population
+	}
+	if !a.Founded.Equal(b.Founded) {
+		return a.Founded.Before(b.Founded)
+	}
+	return a.SettlementID < b.SettlementID
+}
+
+// mergeSettlement absorbs smaller into larger: population and buildings are
+// consolidated, tiles reassigned and suburbs re-parented
+func (e *GameEngine) mergeSettlement(ctx context.Context, game *models.Game, settlements []*models.Settlement, larger, smaller *models.Settlement) error {
+	merged := *larger
+	merged.Population += smaller.Population
+	merged.Buildings = append([]string(nil), larger.Buildings...)
+	for _, building := range smaller.Buildings {
+		if !containsString(merged.Buildings, building) {
+			merged.Buildings = append(merged.Buildings, building)
+		}
+	}
+	if merged.ParentID == smaller.SettlementID {
+		merged.ParentID = ""
+	}
+	merged.LastUpdated = time.Now()
+
+	if err := e.repo.MergeSettlements(ctx, &merged, smaller, false, game.CurrentYear); err != nil {
+		return err
+	}
+	*larger = merged
+	reparentSuburbs(settlements, smaller, larger)
+
+	// Both simulations are rebuilt from the merged population next tick
+	delete(e.settlementSims, larger.SettlementID)
+	delete(e.settlementSims, smaller.SettlementID)
+
+	log.Printf("Settlement %s merged into %s (population %d)", smaller.SettlementID, larger.SettlementID, larger.Population)
+	return nil
+}
+
+// makeSuburb attaches smaller, with its own suburbs, to larger's root
+// settlement as a suburb
+func (e *GameEngine) makeSuburb(ctx context.Context, game *models.Game, settlements []*models.Settlement, larger, smaller *models.Settlement) error {
+	if smaller.ParentID != "" {
+		return nil
+	}
+
+	parent := larger
+	if larger.ParentID != "" {
+		parent = nil
+		for _, settlement := range settlements {
+			if settlement.SettlementID == larger.ParentID {
+				parent = settlement
+			}
+		}
+		if parent == nil {
+			return fmt.Errorf("parent settlement %s not found", larger.ParentID)
+		}
+	}
+	if parent.SettlementID == smaller.SettlementID {
+		return nil
+	}
+
+	suburb := *smaller
+	suburb.ParentID = parent.SettlementID
+	suburb.LastUpdated = time.Now()
+	if err := e.repo.MergeSettlements(ctx, parent, &suburb, true, game.CurrentYear); err != nil {
+		return err
+	}
+	*smaller = suburb
+	reparentSuburbs(settlements, smaller, parent)
+
+	log.Printf("Settlement %s is now a suburb of %s", smaller.SettlementID, parent.SettlementID)
+	return nil
+}
+
+// reparentSuburbs moves the suburbs of from to to, as MergeSettlements has
+// in the repository
+func reparentSuburbs(settlements []*models.Settlement, from, to *models.Settlement) {
+	for _, settlement := range settlements {
+		if settlement.ParentID == from.SettlementID && settlement != to {
+			settlement.ParentID = to.SettlementID
+		}
+	}
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// workAreaLocations lists the locations within radius of a center
+func workAreaLocations(center models.Location, radius int) []models.Location {
+	return grid.Unbounded.Disk(center, radius)
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	// minimapInterval is how many game years pass between minimap refreshes
+	minimapInterval = 5
+
+	// minimapSize is the widest and tallest a minimap gets, in cells
+	minimapSize = 64
+)
+
+// minimapTerrainCodes maps each terrain type to its minimap character
+var minimapTerrainCodes = map[string]byte{
+	terrain.Ocean:        'O',
+	terrain.ShallowWater: 'W',
+	terrain.Grassland:    'G',
+	terrain.Plains:       'P',
+	terrain.Forest:       'F',
+	terrain.Jungle:       'J',
+	terrain.Hills:        'H',
+	terrain.Mountain:     'M',
+	terrain.Tundra:       'T',
+	terrain.Desert:       'D',
+	terrain.Beach:        'B',
+	terrain.Savanna:      'S',
+	terrain.Taiga:        'A',
+	terrain.Marsh:        'K',
+	terrain.Oasis:        'Q',
+}
+
+// minimapOwnerCodes are the owner characters of the players, by their index
+// in the player list; they cover more players than a game may have
+const minimapOwnerCodes = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// minimapLegend is minimapTerrainCodes keyed by code, as stored with each minimap
+var minimapLegend = func() map[string]string {
+	legend := make(map[string]string, len(minimapTerrainCodes))
+	for terrainType, code := range minimapTerrainCodes {
+		legend[string(code)] = terrainType
+	}
+	return legend
+}()
+
+// knownTile is one tile as a particular player knows it
+type knownTile struct {
+	terrainType string
+	ownerID     *string
+}
+
+// processMinimaps rebuilds every player's minimap and the territory borders
+// they know of every minimapInterval years
+func (e *GameEngine) processMinimaps(ctx context.Context, game *models.Game) error {
+	if len(game.TickYearsEvery(minimapInterval)) == 0 || len(game.PlayerList) == 0 {
+		return nil
+	}
+
+	metadata, err := e.repo.GetMapMetadata(ctx, game.GameID)
+	if err != nil || metadata == nil {
+		return err
+	}
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return err
+	}
+	explored, err := e.repo.GetExploredTiles(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+
+	for _, playerID := range game.ActivePlayers() {
+		minimap := buildMinimap(game, metadata, tiles, explored, playerID)
+		if err := e.repo.SaveMinimap(ctx, minimap); err != nil {
+			return err
+		}
+		borders := buildBorders(game, metadata, tiles, explored, playerID)
+		if err := e.repo.SaveBorders(ctx, borders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildMinimap downsamples the map to at most minimapSize cells a side as the
+// player knows it: live state for tiles they see or own, last-seen state for
+// explored tiles and MinimapUnknown elsewhere. Each cell shows the most common
+// terrain and owner among the tiles the player knows in it.
+func buildMinimap(game *models.Game, metadata *models.MapMetadata, tiles []*models.MapTile, explored []*models.ExploredTile, playerID string) *models.Minimap {
+	known := knownTiles(game, tiles, explored, playerID)
+
+	playerIndex := make(map[string]byte, len(game.PlayerList))
+	for i, id := range game.PlayerList {
+		if i < len(minimapOwnerCodes) {
+			playerIndex[id] = minimapOwnerCodes[i]
+		}
+	}
+
+	width, height := min(metadata.Width, minimapSize), min(metadata.Height, minimapSize)
+	terrainCells := make([]byte, 0, width*height)
+	ownerCells := make([]byte, 0, width*height)
+	for cy := 0; cy < height; cy++ {
+		for cx := 0; cx < width; cx++ {
+			terrainCounts := make(map[byte]int)
+			ownerCounts := make(map[byte]int)
+			for y := cy * metadata.Height / height; y < (cy+1)*metadata.Height/height; y++ {
+				for x := cx * metadata.Width / width; x < (cx+1)*metadata.Width/width; x++ {
+					tile, ok := known[models.Location{X: x, Y: y}]
+					if !ok {
+						continue
+					}
+					if code, ok := minimapTerrainCodes[tile.terrainType]; ok {
+						terrainCounts[code]++
+					}
+					owner := byte(models.MinimapUnowned)
+					if tile.ownerID != nil {
+						if index, ok := playerIndex[*tile.ownerID]; ok {
+							owner = index
+						} else if models.IsMinorCiv(*tile.ownerID) {
+							owner = models.MinimapMinorCiv
+						}
+					}
+					ownerCounts[owner]++
+				}
+			}
+			terrainCells = append(terrainCells, mostCommon(terrainCounts))
+			ownerCells = append(ownerCells, mostCommon(ownerCounts))
+		}
+	}
+
+	return &models.Minimap{
+		GameID:      game.GameID,
+		PlayerID:    playerID,
+		Width:       width,
+		Height:      height,
+		Terrain:     string(terrainCells),
+		Owners:      string(ownerCells),
+		Legend:      minimapLegend,
+		Year:        game.CurrentYear,
+		GeneratedAt: time.Now(),
+	}
+}
+
+// knownTiles returns the tiles a player knows: live state for tiles they see
+// or own and last-seen state for tiles they explored
+func knownTiles(game *models.Game, tiles []*models.MapTile, explored []*models.ExploredTile, playerID string) map[models.Location]knownTile {
+	known := make(map[models.Location]knownTile)
+	for _, record := range explored {
+		if record.PlayerID == playerID {
+			known[models.Location{X: record.X, Y: record.Y}] = knownTile{record.TerrainType, record.OwnerID}
+		}
+	}
+	for _, tile := range tiles {
+		if containsString(tileViewers(game, tile), playerID) {
+			known[models.Location{X: tile.X, Y: tile.Y}] = knownTile{tile.TerrainType, tile.OwnerID}
+		}
+	}
+	return known
+}
+
+// mostCommon returns the most frequent code, the lowest on ties, or
+// MinimapUnknown if there are none
+func mostCommon(counts map[byte]int) byte {
+	best, bestCount := byte(models.MinimapUnknown), 0
+	for code, count := range counts {
+		if count > bestCount || (count == bestCount && code < best) {
+			best, bestCount = code, count
+		}
+	}
+	return best
+}
@@ -0,0 +1,413 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+)
+
+const (
+	minorCivPopulation   = 400 // People a minor civ's settlement is founded with
+	giftFriendship       = 0.5 // Friendship each unit of gifted production buys
+	friendshipDecay      = 1   // Friendship a minor civ loses toward every player each year
+	maxFriendship        = 100
+	friendFriendship     = 30 // Friendship at which a minor civ sends a player production
+	allyFriendship       = 60 // Friendship at which a minor civ may ally with a player
+	friendProduction     = 2  // Production sent each year to each friend
+	allyProduction       = 5  // Production sent each year to the ally instead
+	minorQuestInterval   = 10 // Years between a minor civ's quests
+	minorQuestYears      = 30 // Years a quest stands before it lapses
+	minorQuestFriendship = 40 // Friendship earned by the first player to complete a quest
+	minorVisitRadius     = 2  // How close a unit must come to complete a visit quest
+)
+
+// minorCivNames are the names minor civilizations are founded with
+var minorCivNames = []string{
+	"Ur", "Byblos", "Ugarit", "Mari", "Ebla", "Sidon", "Tyre", "Uruk",
+	"Lagash", "Kish", "Jericho", "Knossos", "Caral", "Mohenjo-daro",
+}
+
+// createMinorCivs founds a minor civilization at each of the map's minor civ
+// sites, each with a village and its work area
+func (e *GameEngine) createMinorCivs(ctx context.Context, game *models.Game, sites []models.Location) error {
+	if len(sites) == 0 {
+		return nil
+	}
+	stream := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, "minor-civs"))
+	names := append([]string(nil), minorCivNames...)
+	rules := game.Ruleset()
+
+	for i, site := range sites {
+		name := fmt.Sprintf("City-state %d", i+1)
+		if len(names) > 0 {
+			pick := stream.Intn(len(names))
+			name = names[pick]
+			names = append(names[:pick], names[pick+1:]...)
+		}
+
+		now := time.Now()
+		civ := &models.MinorCiv{
+			GameID:       game.GameID,
+			MinorCivID:   fmt.Sprintf("%s%d", models.MinorCivPrefix, i+1),
+			Name:         name,
+			SettlementID: generateUUID(),
+			Friendship:   make(map[string]int),
+			LastUpdated:  now,
+		}
+		settlement := &models.Settlement{
+			SettlementID: civ.SettlementID,
+			GameID:       game.GameID,
+			PlayerID:     civ.MinorCivID,
+			Name:         name,
+			Type:         models.SettlementTypeVillage,
+			Location:     site,
+			Population:   minorCivPopulation,
+			Founded:      now,
+			LastUpdated:  now,
+		}
+		if err := e.repo.CreateSettlement(ctx, settlement); err != nil {
+			return err
+		}
+		if err := e.repo.AssignTiles(ctx, game.GameID, settlement.SettlementID, civ.MinorCivID, workAreaLocations(site, settlement.WorkRadius(rules)), game.CurrentYear); err != nil {
+			log.Printf("Error assigning tiles to minor civ %s: %v", civ.Name, err)
+		}
+		e.invalidatePaths(game.GameID)
+		if err := e.repo.SaveMinorCiv(ctx, civ); err != nil {
+			return err
+		}
+		log.Printf("Game %s: minor civ %s founded at (%d, %d)", game.GameID, name, site.X, site.Y)
+	}
+	return nil
+}
+
+// processMinorCivs runs each minor civ's yearly turn: its goodwill toward
+// every player fades, it sets and checks its quests, takes the player it
+// likes best as its ally once friendly enough, and sends its friends
+// production
+func (e *GameEngine) processMinorCivs(ctx context.Context, game *models.Game) error {
+	civs, err := e.repo.GetMinorCivs(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	if len(civs) == 0 {
+		return nil
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	settlementByID := make(map[string]*models.Settlement, len(settlements))
+	for _, settlement := range settlements {
+		settlementByID[settlement.SettlementID] = settlement
+	}
+	active := game.ActivePlayers()
+
+	for _, civ := range civs {
+		settlement := settlementByID[civ.SettlementID]
+		if civ.ConqueredBy != "" || settlement == nil {
+			continue
+		}
+
+		for playerID, friendship := range civ.Friendship {
+			if !containsString(active, playerID) {
+				delete(civ.Friendship, playerID)
+				continue
+			}
+			civ.Friendship[playerID] = max(friendship-friendshipDecay, 0)
+		}
+
+		if civ.Quest != nil && game.CurrentYear >= civ.Quest.Expires {
+			civ.Quest = nil
+		}
+		if civ.Quest != nil && civ.Quest.Type == models.MinorQuestVisit {
+			if visitor := minorCivVisitor(active, units, settlement); visitor != "" {
+				e.completeMinorQuest(ctx, game, civ, visitor)
+			}
+		}
+		if civ.Quest == nil && len(game.TickYearsEvery(minorQuestInterval)) > 0 {
+			civ.Quest = newMinorQuest(game, civ)
+		}
+
+		if ally := minorCivAlly(civ); ally != civ.Ally {
+			civ.Ally = ally
+			if ally != "" {
+				e.recordMinorCivEvent(ctx, game, models.EventMinorCivAllied, ally, fmt.Sprintf("%s allied with %s", civ.Name, ally))
+			}
+		}
+
+		for _, playerID := range friendsOf(civ) {
+			if civ.Friendship[playerID] < friendFriendship {
+				continue
+			}
+			amount := friendProduction
+			if playerID == civ.Ally {
+				amount = allyProduction
+			}
+			if err := e.sendProduction(ctx, game, playerID, amount); err != nil {
+				log.Printf("Error sending production from %s to %s: %v", civ.Name, playerID, err)
+			}
+		}
+
+		civ.LastUpdated = time.Now()
+		if err := e.repo.SaveMinorCiv(ctx, civ); err != nil {
+			log.Printf("Error updating minor civ %s in game %s: %v", civ.Name, game.GameID, err)
+		}
+	}
+	return nil
+}
+
+// newMinorQuest draws the next quest a minor civ asks of the players
+func newMinorQuest(game *models.Game, civ *models.MinorCiv) *models.MinorQuest {
+	stream := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, fmt.Sprintf("minor-quest:%s:%d", civ.MinorCivID, game.CurrentYear)))
+	quest := &models.MinorQuest{Type: models.MinorQuestVisit, Expires: game.CurrentYear + minorQuestYears}
+	if stream.Intn(2) == 0 {
+		quest.Type = models.MinorQuestGift
+		quest.Amount = 20 + 10*stream.Intn(5)
+	}
+	return quest
+}
+
+// minorCivVisitor returns the first active player, in seat order, with a
+// unit within minorVisitRadius of a minor civ's settlement
+func minorCivVisitor(active []string, units []*models.Unit, settlement *models.Settlement) string {
+	for _, playerID := range active {
+		for _, unit := range units {
+			if unit.PlayerID == playerID &&
+				abs(unit.Location.X-settlement.Location.X) <= minorVisitRadius && abs(unit.Location.Y-settlement.Location.Y) <= minorVisitRadius {
+				return playerID
+			}
+		}
+	}
+	return ""
+}
+
+// minorCivAlly returns the player a minor civ likes best if they are
+// friendly enough to ally with; its current ally keeps a tie
+func minorCivAlly(civ *models.MinorCiv) string {
+	best, bestFriendship := "", 0
+	for _, playerID := range friendsOf(civ) {
+		friendship := civ.Friendship[playerID]
+		if friendship < allyFriendship {
+			continue
+		}
+		if friendship > bestFriendship || (friendship == bestFriendship && playerID == civ.Ally) {
+			best, bestFriendship = playerID, friendship
+		}
+	}
+	return best
+}
+
+// friendsOf lists the players a minor civ has any friendship with, in ID order
+func friendsOf(civ *models.MinorCiv) []string {
+	players := make([]string, 0, len(civ.Friendship))
+	for playerID := range civ.Friendship {
+		players = append(players, playerID)
+	}
+	sort.Strings(players)
+	return players
+}
+
+// befriend raises a minor civ's friendship toward a player, up to maxFriendship
+func befriend(civ *models.MinorCiv, playerID string, amount int) {
+	if civ.Friendship == nil {
+		civ.Friendship = make(map[string]int)
+	}
+	civ.Friendship[playerID] = min(civ.Friendship[playerID]+amount, maxFriendship)
+}
+
+// completeMinorQuest rewards the player who completed a minor civ's quest
+func (e *GameEngine) completeMinorQuest(ctx context.Context, game *models.Game, civ *models.MinorCiv, playerID string) {
+	befriend(civ, playerID, minorQuestFriendship)
+	e.recordMinorCivEvent(ctx, game, models.EventMinorQuestDone, playerID, fmt.Sprintf("%s completed the %s quest of %s", playerID, civ.Quest.Type, civ.Name))
+	civ.Quest = nil
+}
+
+// sendProduction adds production to a player's most populous settlement
+func (e *GameEngine) sendProduction(ctx context.Context, game *models.Game, playerID string, amount int) error {
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, playerID)
+	if err != nil || len(settlements) == 0 {
+		return err
+	}
+	capital := settlements[0]
+	for _, settlement := range settlements[1:] {
+		if settlement.Population > capital.Population {
+			capital = settlement
+		}
+	}
+	capital.Production += amount
+	return e.repo.UpdateSettlement(ctx, capital)
+}
+
+// recordMinorCivEvent records a minor civ's dealings with a player
+func (e *GameEngine) recordMinorCivEvent(ctx context.Context, game *models.Game, eventType, playerID, detail string) {
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      eventType,
+		PlayerID:  playerID,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording %s event in game %s: %v", eventType, game.GameID, err)
+	}
+}
+
+// minorCiv returns a game's minor civilization by ID, or nil
+func (e *GameEngine) minorCiv(ctx context.Context, game *models.Game, minorCivID string) (*models.MinorCiv, error) {
+	civs, err := e.repo.GetMinorCivs(ctx, game.GameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, civ := range civs {
+		if civ.MinorCivID == minorCivID {
+			return civ, nil
+		}
+	}
+	return nil, nil
+}
+
+// executeGiftOrder sends a minor civ production banked in one of the
+// player's settlements, buying its friendship. A gift large enough
+// completes the minor civ's gift quest.
+func (e *GameEngine) executeGiftOrder(ctx context.Context, game *models.Game, order *models.Order) error {
+	civ, err := e.minorCiv(ctx, game, order.TargetPlayer)
+	if err != nil {
+		return err
+	}
+	if civ == nil || civ.ConqueredBy != "" {
+		return fmt.Errorf("no minor civ %s to send gifts to", order.TargetPlayer)
+	}
+	if order.Amount <= 0 {
+		return fmt.Errorf("a gift must be a positive amount of production")
+	}
+
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	var source, recipient *models.Settlement
+	for _, settlement := range settlements {
+		switch settlement.SettlementID {
+		case order.SettlementID:
+			source = settlement
+		case civ.SettlementID:
+			recipient = settlement
+		}
+	}
+	if source == nil || source.PlayerID != order.PlayerID {
+		return fmt.Errorf("settlement %s not found for player", order.SettlementID)
+	}
+	if source.Production < order.Amount {
+		return fmt.Errorf("%s has %d production, not %d", source.Name, source.Production, order.Amount)
+	}
+
+	source.Production -= order.Amount
+	source.LastUpdated = time.Now()
+	if err := e.repo.UpdateSettlement(ctx, source); err != nil {
+		return err
+	}
+	if recipient != nil {
+		recipient.Production += order.Amount
+		recipient.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, recipient); err != nil {
+			return err
+		}
+	}
+
+	befriend(civ, order.PlayerID, int(float64(order.Amount)*giftFriendship))
+	if civ.Quest != nil && civ.Quest.Type == models.MinorQuestGift && order.Amount >= civ.Quest.Amount {
+		e.completeMinorQuest(ctx, game, civ, order.PlayerID)
+	}
+	civ.LastUpdated = time.Now()
+	return e.repo.SaveMinorCiv(ctx, civ)
+}
+
+// executeAttackOrder has a unit attack an adjacent minor civ settlement. The
+// combat is fought by the resolver; a unit left without health is
+// destroyed, the blows the settlement takes kill some of its people, and a
+// settlement whose defense is broken is conquered with its territory. A
+// minor civ never forgives its attacker.
+func (e *GameEngine) executeAttackOrder(ctx context.Context, game *models.Game, order *models.Order, unit *models.Unit) error {
+	if unit == nil || unit.PlayerID != order.PlayerID {
+		return fmt.Errorf("unit %s not found for player", order.UnitID)
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	var target *models.Settlement
+	for _, settlement := range settlements {
+		if settlement.SettlementID == order.SettlementID {
+			target = settlement
+		}
+	}
+	if target == nil || !models.IsMinorCiv(target.PlayerID) {
+		return fmt.Errorf("no minor civ settlement %s to attack", order.SettlementID)
+	}
+	if abs(target.Location.X-unit.Location.X) > 1 || abs(target.Location.Y-unit.Location.Y) > 1 {
+		return fmt.Errorf("%s is not adjacent to unit %s", target.Name, unit.UnitID)
+	}
+	civ, err := e.minorCiv(ctx, game, target.PlayerID)
+	if err != nil {
+		return err
+	}
+
+	attacker, defender, err := e.combatants(ctx, game, unit.UnitID, target.SettlementID)
+	if err != nil {
+		return err
+	}
+	stream := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, "combat:"+order.OrderID))
+	attackerHealth, defenderHealth := resolveCombat(attacker, defender, stream)
+
+	unit.Damage = models.MaxUnitHealth - attackerHealth
+	unit.LastUpdated = time.Now()
+	if attackerHealth == 0 {
+		err = e.repo.DeleteUnit(ctx, unit.UnitID)
+		log.Printf("Game %s: unit %s fell attacking %s", game.GameID, unit.UnitID, target.Name)
+	} else {
+		err = e.repo.UpdateUnit(ctx, unit)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := e.inflictCasualties(ctx, game, target, models.MaxUnitHealth-defenderHealth); err != nil {
+		return err
+	}
+
+	if civ != nil {
+		delete(civ.Friendship, unit.PlayerID)
+		if civ.Ally == unit.PlayerID {
+			civ.Ally = ""
+		}
+	}
+	if defenderHealth == 0 {
+		if err := e.repo.CaptureSettlement(ctx, target, unit.PlayerID, game.CurrentYear); err != nil {
+			return err
+		}
+		e.invalidatePaths(game.GameID)
+		if civ != nil {
+			civ.ConqueredBy = unit.PlayerID
+			civ.Ally = ""
+			civ.Quest = nil
+		}
+		e.recordMinorCivEvent(ctx, game, models.EventSettlementTaken, unit.PlayerID, fmt.Sprintf("%s conquered %s", unit.PlayerID, target.Name))
+		log.Printf("Game %s: %s conquered %s", game.GameID, unit.PlayerID, target.Name)
+	}
+	if civ == nil {
+		return nil
+	}
+	civ.LastUpdated = time.Now()
+	return e.repo.SaveMinorCiv(ctx, civ)
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+// combatCasualtyRate is the share of a settlement's people killed when its
+// defense is broken completely; lighter blows kill proportionally fewer
+const combatCasualtyRate = 0.2
+
+// MortalityReport is a settlement's deaths by cause since it was founded
+type MortalityReport struct {
+	SettlementID string `json:"settlementId"`
+	PlayerID     string `json:"playerId"`
+	Name         string `json:"name"`
+	Population   int    `json:"population"`
+	Starvation   int    `json:"starvation"`
+	Age          int    `json:"age"`
+	Disease      int    `json:"disease"`
+	Accident     int    `json:"accident"`
+	Combat       int    `json:"combat"`
+	Childbirth   int    `json:"childbirth"`
+	Total        int    `json:"total"`
+}
+
+// SettlementMortality reports the deaths in every settlement of a game, or
+// in a single settlement when settlementID is given, ordered by ID
+func (e *GameEngine) SettlementMortality(ctx context.Context, gameID, settlementID string) ([]*MortalityReport, error) {
+	settlements, err := e.repo.GetSettlements(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []*MortalityReport{}
+	for _, settlement := range settlements {
+		if settlementID != "" && settlement.SettlementID != settlementID {
+			continue
+		}
+		deaths := settlement.Deaths
+		reports = append(reports, &MortalityReport{
+			SettlementID: settlement.SettlementID,
+			PlayerID:     settlement.PlayerID,
+			Name:         settlement.Name,
+			Population:   settlement.Population,
+			Starvation:   deaths.Starvation,
+			Age:          deaths.Age,
+			Disease:      deaths.Disease,
+			Accident:     deaths.Accident,
+			Combat:       deaths.Combat,
+			Childbirth:   deaths.Childbirth,
+			Total:        deaths.Total(),
+		})
+	}
+	if settlementID != "" && len(reports) == 0 {
+		return nil, fmt.Errorf("%w: settlement %s", repository.ErrNotFound, settlementID)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].SettlementID < reports[j].SettlementID })
+	return reports, nil
+}
+
+// recordDeaths adds a simulated period's deaths to a settlement's tally
+func recordDeaths(settlement *models.Settlement, mortality simulator.Mortality) {
+	settlement.Deaths.Starvation += mortality.Starvation
+	settlement.Deaths.Age += mortality.Age
+	settlement.Deaths.Disease += mortality.Disease
+	settlement.Deaths.Accident += mortality.Accident
+	settlement.Deaths.Combat += mortality.Combat
+	settlement.Deaths.Childbirth += mortality.Childbirth
+}
+
+// inflictCasualties kills the settlement's defenders in proportion to the
+// damage its defense took in combat, out of maxUnitHealth
+func (e *GameEngine) inflictCasualties(ctx context.Context, game *models.Game, settlement *models.Settlement, damage int) error {
+	sim := e.settlementSimulation(ctx, game, settlement)
+	share := combatCasualtyRate * float64(damage) / float64(models.MaxUnitHealth)
+	killed := sim.KillInCombat(int(math.Round(float64(sim.Population()) * share)))
+	if killed == 0 {
+		return nil
+	}
+
+	recordDeaths(settlement, simulator.Mortality{Combat: killed})
+	e.saveSettlementSimulation(ctx, game, settlement.SettlementID, sim)
+	settlement.Population = sim.Population()
+	settlement.LastUpdated = time.Now()
+	return e.repo.UpdateSettlement(ctx, settlement)
+}
@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// unitMovementPoints is how far each unit type travels in a year, in plain
+// steps (see terrain.MoveCost); types not listed travel one. A tick spanning
+// several years gives units each year's points.
+var unitMovementPoints = map[string]float64{
+	models.UnitTypeGalley: 3.0,
+
+	models.UnitTypeGreatScientist: 2.0,
+	models.UnitTypeGreatBuilder:   2.0,
+	models.UnitTypeGreatGeneral:   2.0,
+}
+
+// moveSlack absorbs rounding when steps of a third add up to a whole point
+const moveSlack = 1e-9
+
+// movementPoints returns how far a unit travels in a year
+func movementPoints(unit *models.Unit) float64 {
+	if points, ok := unitMovementPoints[unit.UnitType]; ok {
+		return points
+	}
+	return 1.0
+}
+
+// findPath returns the tiles a unit crosses travelling from one location to
+// another, both ends included
+func findPath(from, to models.Location) []models.Location {
+	path := []models.Location{from}
+	for current := from; current != to; {
+		current = stepToward(current, to)
+		path = append(path, current)
+	}
+	return path
+}
+
+// recordMovement emits a unit_moved event carrying the tiles a unit crossed
+// this tick and when it reaches each, so clients can animate the move. The
+// move is also noted as a trip for road planning
+func (e *GameEngine) recordMovement(ctx context.Context, game *models.Game, unit *models.Unit, path []models.Location) {
+	if len(path) < 2 {
+		return
+	}
+
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventUnitMoved,
+		PlayerID:  unit.PlayerID,
+		Movement:  models.NewMovement(unit.UnitID, path),
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording movement of unit %s: %v", unit.UnitID, err)
+	}
+	e.noteTrip(game.GameID, trip{playerID: unit.PlayerID, from: path[0], to: path[len(path)-1]})
+}
+
+// mapTileLookup loads a game's map in one query and returns a lookup of its
+// tiles by location, nil for locations off the map
+func (e *GameEngine) mapTileLookup(ctx context.Context, gameID string) (func(models.Location) *models.MapTile, error) {
+	tiles, err := e.repo.GetMapTiles(ctx, gameID, nil)
+	if err != nil {
+		return nil, err
+	}
+	byLocation := make(map[models.Location]*models.MapTile, len(tiles))
+	for _, tile := range tiles {
+		byLocation[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+	return func(loc models.Location) *models.MapTile { return byLocation[loc] }, nil
+}
+
+// advanceAlongRoute returns the start of a route a unit covers in the years
+// a tick spans. The unit spends those years' movement points, with what it
+// saved in earlier ticks, on as many steps as they pay for, so units travel
+// further by road and along rivers. Movement left over short of the
+// destination is saved on the unit, so a step dearer than a tick's movement
+// is taken once enough has built up.
+func advanceAlongRoute(route []models.Location, tileAt func(models.Location) *models.MapTile, unit *models.Unit, years int) []models.Location {
+	path := route[:1]
+	budget := movementPoints(unit)*float64(years) + unit.MovesLeft
+	for i := 1; i < len(route); i++ {
+		cost := terrain.MoveCost(tileAt(route[i-1]), tileAt(route[i]))
+		if cost > budget+moveSlack {
+			break
+		}
+		budget -= cost
+		path = route[:i+1]
+	}
+	unit.MovesLeft = 0
+	if len(path) < len(route) {
+		unit.MovesLeft = max(budget, 0)
+	}
+	return path
+}
+
+// executeMoveOrder sends a unit to the order's target, which it travels to
+// over the following years as far as its movement points carry it each
+// year. The route is kept on the order so clients can show it.
+func (e *GameEngine) executeMoveOrder(ctx context.Context, game *models.Game, order *models.Order, unit *models.Unit) error {
+	if unit == nil || unit.PlayerID != order.PlayerID {
+		return fmt.Errorf("unit %s not found for player", order.UnitID)
+	}
+	if unit.UnitType == models.UnitTypeGalley {
+		return fmt.Errorf("galley %s cannot travel over land", unit.UnitID)
+	}
+	if unit.Project != nil {
+		return fmt.Errorf("workers %s are busy with %s", unit.UnitID, unit.Project.Transformation)
+	}
+	if order.Target == nil {
+		return fmt.Errorf("a move order needs a target")
+	}
+	target := *order.Target
+	tile, err := e.repo.GetMapTile(ctx, game.GameID, target.X, target.Y)
+	if err != nil || tile == nil {
+		return fmt.Errorf("no tile at (%d, %d)", target.X, target.Y)
+	}
+	if terrain.IsWater(tile.TerrainType) {
+		return fmt.Errorf("cannot move onto water at (%d, %d)", target.X, target.Y)
+	}
+
+	order.Path = e.gameRoute(ctx, game.GameID, unit.Location, target)
+	unit.Destination = &target
+	unit.LastUpdated = time.Now()
+	return e.repo.UpdateUnit(ctx, unit)
+}
+
+// processUnitMovement moves every unit a move order sent somewhere along
+// its route, as far as its movement points carry it this tick. A unit that
+// arrives stops there; settlers then settle at the best site near where
+// they stand. Units move in unit ID order.
+func (e *GameEngine) processUnitMovement(ctx context.Context, game *models.Game) error {
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	var travelling []*models.Unit
+	for _, unit := range units {
+		if unit.Destination != nil {
+			travelling = append(travelling, unit)
+		}
+	}
+	if len(travelling) == 0 {
+		return nil
+	}
+	sort.Slice(travelling, func(i, j int) bool { return travelling[i].UnitID < travelling[j].UnitID })
+
+	tileAt, err := e.mapTileLookup(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	for _, unit := range travelling {
+		path := advanceAlongRoute(e.gameRoute(ctx, game.GameID, unit.Location, *unit.Destination), tileAt, unit, game.YearsPerTick())
+		unit.Location = path[len(path)-1]
+		if unit.Location == *unit.Destination {
+			unit.Destination = nil
+			if unit.UnitType == models.UnitTypeSettlers {
+				unit.StepsTaken = max(unit.StepsTaken, game.Ruleset().SettlersWalkSteps)
+			}
+		}
+		unit.LastUpdated = time.Now()
+		if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+			log.Printf("Error moving unit %s: %v", unit.UnitID, err)
+			continue
+		}
+		e.recordMovement(ctx, game, unit, path)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// processNoShows frees the regions of players who never connected within
+// the game's grace period of being given one, so late joiners can have them.
+// Under the default policy no-shows are instead handed to the governor (see
+// absentPlayers).
+func (e *GameEngine) processNoShows(ctx context.Context, game *models.Game) error {
+	if !game.ReleasesNoShows() || game.TicksSince(gameStartYear) < game.NoShowGraceTicks {
+		return nil
+	}
+
+	activity, err := e.repo.GetPlayerActivity(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(activity))
+	for _, record := range activity {
+		seen[record.PlayerID] = true
+	}
+
+	for _, playerID := range game.ActivePlayers() {
+		if seen[playerID] {
+			continue
+		}
+		position, err := e.repo.GetStartingPosition(ctx, game.GameID, playerID)
+		if err != nil {
+			return err
+		}
+		if position == nil || game.TicksSince(position.AssignedYear) < game.NoShowGraceTicks {
+			continue
+		}
+		if err := e.releasePlayer(ctx, game, playerID); err != nil {
+			log.Printf("Error releasing no-show player %s in game %s: %v", playerID, game.GameID, err)
+		}
+	}
+	return nil
+}
+
+// releasePlayer removes a no-show from the game, freeing their seat and
+// region, and records the release as an event
+func (e *GameEngine) releasePlayer(ctx context.Context, game *models.Game, playerID string) error {
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, playerID)
+	if err != nil {
+		return err
+	}
+	if err := e.repo.ReleasePlayer(ctx, game.GameID, playerID, game.CurrentYear); err != nil {
+		return err
+	}
+	e.invalidatePaths(game.GameID)
+
+	players := make([]string, 0, len(game.PlayerList))
+	for _, id := range game.PlayerList {
+		if id != playerID {
+			players = append(players, id)
+		}
+	}
+	game.PlayerList = players
+	game.CurrentPlayers--
+	delete(game.Teams, playerID)
+
+	for _, settlement := range settlements {
+		delete(e.settlementSims, settlement.SettlementID)
+	}
+	// The freed seat may be taken before the next tick; recount joiners
+	delete(e.spawnedPlayers, game.GameID)
+	e.governorMu.Lock()
+	delete(e.governed[game.GameID], playerID)
+	e.governorMu.Unlock()
+
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventPlayerReleased,
+		PlayerID:  playerID,
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording release of player %s: %v", playerID, err)
+	}
+
+	log.Printf("Game %s: released no-show player %s and freed their region", game.GameID, playerID)
+	return nil
+}
@@ -0,0 +1,275 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+)
+
+const (
+	objectivesPerPlayer = 2   // Objectives each player works toward at once
+	objectiveYears      = 100 // Years a player has to meet an objective
+	objectiveReward     = 40  // Production granted for meeting an objective
+)
+
+// objectiveTypes lists the objectives the engine draws from
+var objectiveTypes = []string{
+	models.ObjectiveCoastalSettlement,
+	models.ObjectiveConnectSettlements,
+	models.ObjectivePopulation,
+	models.ObjectiveSettlements,
+	models.ObjectiveBuilding,
+}
+
+// civProgress is what a player's civ has achieved, as objectives measure it
+type civProgress struct {
+	population  int
+	settlements int
+	coastal     bool // Some settlement's work area reaches water
+	connected   bool // Some two settlements are linked by road
+	buildings   map[string]bool
+}
+
+// meets reports whether the progress satisfies an objective
+func (p civProgress) meets(objective *models.Objective) bool {
+	switch objective.Type {
+	case models.ObjectiveCoastalSettlement:
+		return p.coastal
+	case models.ObjectiveConnectSettlements:
+		return p.connected
+	case models.ObjectivePopulation:
+		return p.population >= objective.Target
+	case models.ObjectiveSettlements:
+		return p.settlements >= objective.Target
+	case models.ObjectiveBuilding:
+		return p.buildings[objective.Item]
+	}
+	return false
+}
+
+// processObjectives checks every player's open objectives against the
+// game: those met are rewarded with production in the player's largest
+// settlement, those past their deadline fail. Players are then given new
+// objectives until they have objectivesPerPlayer open. Each outcome is
+// recorded as an event addressed to the player.
+func (e *GameEngine) processObjectives(ctx context.Context, game *models.Game) error {
+	objectives, err := e.repo.GetObjectives(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	roads, err := e.repo.GetTilesWithImprovement(ctx, game.GameID, models.ImprovementRoad)
+	if err != nil {
+		return err
+	}
+	byPlayer := make(map[string][]*models.Settlement)
+	for _, settlement := range settlements {
+		byPlayer[settlement.PlayerID] = append(byPlayer[settlement.PlayerID], settlement)
+	}
+	active := game.ActivePlayers()
+	progress := make(map[string]civProgress, len(active))
+	for _, playerID := range active {
+		progress[playerID] = e.civProgress(ctx, game, byPlayer[playerID], roads)
+	}
+
+	open := make(map[string][]*models.Objective)
+	for _, objective := range objectives {
+		p, playing := progress[objective.PlayerID]
+		if objective.Status != models.ObjectiveActive || !playing {
+			continue
+		}
+		switch {
+		case p.meets(objective):
+			objective.Status = models.ObjectiveCompleted
+			if err := e.sendProduction(ctx, game, objective.PlayerID, objective.Reward); err != nil {
+				log.Printf("Error rewarding objective %s in game %s: %v", objective.ObjectiveID, game.GameID, err)
+			}
+			e.recordObjectiveEvent(ctx, game, models.EventObjectiveDone, objective)
+		case game.CurrentYear >= objective.Deadline:
+			objective.Status = models.ObjectiveFailed
+			e.recordObjectiveEvent(ctx, game, models.EventObjectiveFailed, objective)
+		default:
+			open[objective.PlayerID] = append(open[objective.PlayerID], objective)
+			continue
+		}
+		objective.Resolved = game.CurrentYear
+		objective.LastUpdated = time.Now()
+		if err := e.repo.SaveObjective(ctx, objective); err != nil {
+			log.Printf("Error updating objective %s in game %s: %v", objective.ObjectiveID, game.GameID, err)
+		}
+	}
+
+	for _, playerID := range active {
+		stream := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, fmt.Sprintf("objectives:%s:%d", playerID, game.CurrentYear)))
+		for len(open[playerID]) < objectivesPerPlayer {
+			objective := newObjective(game, playerID, progress[playerID], open[playerID], stream)
+			if objective == nil {
+				break
+			}
+			if err := e.repo.SaveObjective(ctx, objective); err != nil {
+				log.Printf("Error setting objective for %s in game %s: %v", playerID, game.GameID, err)
+				break
+			}
+			open[playerID] = append(open[playerID], objective)
+			e.recordObjectiveEvent(ctx, game, models.EventObjectiveAssigned, objective)
+		}
+	}
+	return nil
+}
+
+// civProgress measures a player's settlements for objectives
+func (e *GameEngine) civProgress(ctx context.Context, game *models.Game, owned []*models.Settlement, roads []*models.MapTile) civProgress {
+	p := civProgress{settlements: len(owned), buildings: make(map[string]bool)}
+	for _, settlement := range owned {
+		p.population += settlement.Population
+		for _, building := range settlement.Buildings {
+			p.buildings[building] = true
+		}
+		p.coastal = p.coastal || e.isCoastalSettlement(ctx, game, settlement)
+	}
+	p.connected = roadConnected(owned, roads)
+	return p
+}
+
+// roadConnected reports whether a road network links any two of the
+// settlements. A road reaches a settlement when it runs through or next to it.
+func roadConnected(settlements []*models.Settlement, roads []*models.MapTile) bool {
+	if len(settlements) < 2 {
+		return false
+	}
+	road := make(map[models.Location]bool, len(roads))
+	for _, tile := range roads {
+		road[models.Location{X: tile.X, Y: tile.Y}] = true
+	}
+	touches := func(loc models.Location, settlement *models.Settlement) bool {
+		return abs(loc.X-settlement.Location.X) <= 1 && abs(loc.Y-settlement.Location.Y) <= 1
+	}
+
+	for i, start := range settlements {
+		seen := make(map[models.Location]bool)
+		var frontier []models.Location
+		for loc := range road {
+			if touches(loc, start) {
+				seen[loc] = true
+				frontier = append(frontier, loc)
+			}
+		}
+		for len(frontier) > 0 {
+			loc := frontier[len(frontier)-1]
+			frontier = frontier[:len(frontier)-1]
+			for _, other := range settlements[i+1:] {
+				if touches(loc, other) {
+					return true
+				}
+			}
+			for _, next := range grid.Unbounded.Neighbors8(loc) {
+				if road[next] && !seen[next] {
+					seen[next] = true
+					frontier = append(frontier, next)
+				}
+			}
+		}
+	}
+	return false
+}
+
+// newObjective draws an objective for a player of a type they are not
+// already working toward and have not already met, or nil if none is left
+func newObjective(game *models.Game, playerID string, p civProgress, open []*models.Objective, stream *rng.Stream) *models.Objective {
+	var candidates []string
+	for _, objectiveType := range objectiveTypes {
+		taken := false
+		for _, objective := range open {
+			taken = taken || objective.Type == objectiveType
+		}
+		if !taken {
+			candidates = append(candidates, objectiveType)
+		}
+	}
+
+	for len(candidates) > 0 {
+		pick := stream.Intn(len(candidates))
+		objectiveType := candidates[pick]
+		candidates = append(candidates[:pick], candidates[pick+1:]...)
+
+		objective := &models.Objective{
+			GameID:      game.GameID,
+			ObjectiveID: generateUUID(),
+			PlayerID:    playerID,
+			Type:        objectiveType,
+			Deadline:    game.CurrentYear + objectiveYears,
+			Reward:      objectiveReward,
+			Status:      models.ObjectiveActive,
+			Assigned:    game.CurrentYear,
+			LastUpdated: time.Now(),
+		}
+		switch objectiveType {
+		case models.ObjectivePopulation:
+			// Half as many people again, in round hundreds
+			objective.Target = max((p.population*3/2+99)/100*100, 500)
+		case models.ObjectiveSettlements:
+			objective.Target = p.settlements + 1
+		case models.ObjectiveBuilding:
+			var missing []string
+			for building := range game.Ruleset().BuildingCosts {
+				if !p.buildings[building] {
+					missing = append(missing, building)
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			sort.Strings(missing)
+			objective.Item = missing[stream.Intn(len(missing))]
+		}
+		if !p.meets(objective) {
+			return objective
+		}
+	}
+	return nil
+}
+
+// describeObjective phrases an objective for the player it was set
+func describeObjective(objective *models.Objective) string {
+	var goal string
+	switch objective.Type {
+	case models.ObjectiveCoastalSettlement:
+		goal = "Found a coastal settlement"
+	case models.ObjectiveConnectSettlements:
+		goal = "Connect two settlements by road"
+	case models.ObjectivePopulation:
+		goal = fmt.Sprintf("Grow to %d people", objective.Target)
+	case models.ObjectiveSettlements:
+		goal = fmt.Sprintf("Hold %d settlements", objective.Target)
+	case models.ObjectiveBuilding:
+		goal = fmt.Sprintf("Build a %s", objective.Item)
+	default:
+		goal = objective.Type
+	}
+	return fmt.Sprintf("%s by year %d", goal, objective.Deadline)
+}
+
+// recordObjectiveEvent notifies a player of an objective being set, met or failed
+func (e *GameEngine) recordObjectiveEvent(ctx context.Context, game *models.Game, eventType string, objective *models.Objective) {
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      eventType,
+		PlayerID:  objective.PlayerID,
+		Detail:    describeObjective(objective),
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording %s event in game %s: %v", eventType, game.GameID, err)
+	}
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// processOrders executes a game's pending player orders in submission order.
+// Orders that cannot be carried out are marked rejected with a reason.
+func (e *GameEngine) processOrders(ctx context.Context, game *models.Game) error {
+	orders, err := e.repo.GetPendingOrders(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	unitsByID := make(map[string]*models.Unit, len(units))
+	for _, unit := range units {
+		unitsByID[unit.UnitID] = unit
+	}
+
+	for _, order := range orders {
+		var execErr error
+		switch {
+		case game.IsEliminated(order.PlayerID):
+			execErr = fmt.Errorf("player %s has been eliminated", order.PlayerID)
+		case order.OrderType == models.OrderTypeSurrender:
+			execErr = e.eliminatePlayer(ctx, game, order.PlayerID, models.EventPlayerSurrendered)
+		case order.OrderType == models.OrderTypeSettle:
+			execErr = e.executeSettleOrder(ctx, game, order, unitsByID[order.UnitID])
+			if execErr == nil {
+				delete(unitsByID, order.UnitID)
+			}
+		case order.OrderType == models.OrderTypeBuild:
+			execErr = e.executeBuildOrder(ctx, game, order)
+		case order.OrderType == models.OrderTypeRoad:
+			execErr = e.executeRoadOrder(ctx, game, order)
+		case order.OrderType == models.OrderTypeChop:
+			execErr = e.executeChopOrder(ctx, game, order, unitsByID[order.UnitID])
+		case order.OrderType == models.OrderTypeTransform:
+			execErr = e.executeTransformOrder(ctx, game, order, unitsByID[order.UnitID])
+		case order.OrderType == models.OrderTypeMove:
+			execErr = e.executeMoveOrder(ctx, game, order, unitsByID[order.UnitID])
+		case order.OrderType == models.OrderTypeActivate:
+			execErr = e.executeActivateOrder(ctx, game, order, unitsByID[order.UnitID])
+			if execErr == nil {
+				delete(unitsByID, order.UnitID)
+			}
+		case order.OrderType == models.OrderTypeDemand:
+			execErr = e.executeDemandOrder(ctx, game, order)
+		case order.OrderType == models.OrderTypeAccept:
+			execErr = e.executeAcceptOrder(ctx, game, order)
+		case order.OrderType == models.OrderTypeBreak:
+			execErr = e.executeBreakOrder(ctx, game, order)
+		case order.OrderType == models.OrderTypeGift:
+			execErr = e.executeGiftOrder(ctx, game, order)
+		case order.OrderType == models.OrderTypeAttack:
+			execErr = e.executeAttackOrder(ctx, game, order, unitsByID[order.UnitID])
+			if unit := unitsByID[order.UnitID]; execErr == nil && unit.Health() == 0 {
+				delete(unitsByID, order.UnitID)
+			}
+		default:
+			execErr = fmt.Errorf("unknown order type %q", order.OrderType)
+		}
+
+		now := time.Now()
+		order.ProcessedAt = &now
+		if execErr != nil {
+			order.Status = models.OrderStatusRejected
+			order.Reason = execErr.Error()
+			log.Printf("Rejected %s order %s for player %s: %v", order.OrderType, order.OrderID, order.PlayerID, execErr)
+		} else {
+			order.Status = models.OrderStatusExecuted
+		}
+		if err := e.repo.UpdateOrder(ctx, order); err != nil {
+			log.Printf("Error updating order %s: %v", order.OrderID, err)
+		}
+	}
+
+	return nil
+}
+
+// ErrNonAggression is returned when an action would encroach on a teammate
+var ErrNonAggression = errors.New("teammates may not encroach on each other")
+
+// executeSettleOrder founds a settlement with a settlers unit at the order's
+// target, which must be a land tile within the ruleset's settle order range of the unit
+func (e *GameEngine) executeSettleOrder(ctx context.Context, game *models.Game, order *models.Order, unit *models.Unit) error {
+	if unit == nil || unit.PlayerID != order.PlayerID {
+		return fmt.Errorf("unit %s not found for player", order.UnitID)
+	}
+	if unit.UnitType != "settlers" {
+		return fmt.Errorf("unit %s is not a settlers unit", unit.UnitID)
+	}
+
+	target := unit.Location
+	if order.Target != nil {
+		target = *order.Target
+	}
+	settleOrderRange := game.Ruleset().SettleOrderRange
+	if abs(target.X-unit.Location.X) > settleOrderRange || abs(target.Y-unit.Location.Y) > settleOrderRange {
+		return fmt.Errorf("target (%d, %d) is more than %d tiles from the unit", target.X, target.Y, settleOrderRange)
+	}
+
+	tile, err := e.repo.GetMapTile(ctx, game.GameID, target.X, target.Y)
+	if err != nil || tile == nil {
+		return fmt.Errorf("no tile at (%d, %d)", target.X, target.Y)
+	}
+	if terrain.IsWater(tile.TerrainType) {
+		return fmt.Errorf("cannot settle on water at (%d, %d)", target.X, target.Y)
+	}
+	if tile.OwnerID != nil && *tile.OwnerID != unit.PlayerID && game.Allied(unit.PlayerID, *tile.OwnerID) {
+		return fmt.Errorf("%w: (%d, %d) is held by ally %s", ErrNonAggression, target.X, target.Y, *tile.OwnerID)
+	}
+
+	// Keep the route on the order so clients can animate it
+	order.Path = e.gameRoute(ctx, game.GameID, unit.Location, target)
+	e.recordMovement(ctx, game, unit, order.Path)
+	unit.Location = target
+	return e.settleAtLocation(ctx, game, unit)
+}
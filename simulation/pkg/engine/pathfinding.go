@@ -0,0 +1,305 @@
+package engine
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sync"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// pathClusterSize is the side, in tiles, of the square clusters long routes
+// are planned across
+const pathClusterSize = 16
+
+// hierarchicalRouteDistance is how far apart, in steps, two locations must be
+// before their route is planned over the cluster graph rather than searched
+// tile by tile
+const hierarchicalRouteDistance = 2 * pathClusterSize
+
+// maxCachedRoutes bounds each game's route cache; a full cache is emptied
+const maxCachedRoutes = 4096
+
+// pathGraph is a game's map abstracted for route planning. The map is cut
+// into square clusters; the graph links the entrances between neighbouring
+// clusters with the cheapest way across each cluster. Long routes are
+// planned over the entrances and stitched from the paths that cross each
+// cluster, which is much cheaper than a search of every tile on the way.
+// Routes found are cached until the game's terrain or ownership changes.
+type pathGraph struct {
+	tiles                  map[models.Location]*models.MapTile
+	minX, minY, maxX, maxY int
+
+	entrances map[models.Location][]models.Location // Cluster origin -> its entrances
+	edges     map[models.Location][]pathEdge        // Entrance -> entrances one crossing away
+
+	mu     sync.Mutex
+	routes map[[2]models.Location][]models.Location
+}
+
+// pathEdge is a crossing from one entrance to another, within a cluster or
+// over the border between two
+type pathEdge struct {
+	to   models.Location
+	cost float64
+	path []models.Location // Both ends included
+}
+
+// newPathGraph builds the cluster graph of a map
+func newPathGraph(tiles []*models.MapTile) *pathGraph {
+	g := &pathGraph{
+		tiles:     make(map[models.Location]*models.MapTile, len(tiles)),
+		entrances: make(map[models.Location][]models.Location),
+		edges:     make(map[models.Location][]pathEdge),
+		routes:    make(map[[2]models.Location][]models.Location),
+	}
+	for i, tile := range tiles {
+		if i == 0 || tile.X < g.minX {
+			g.minX = tile.X
+		}
+		if i == 0 || tile.Y < g.minY {
+			g.minY = tile.Y
+		}
+		if i == 0 || tile.X > g.maxX {
+			g.maxX = tile.X
+		}
+		if i == 0 || tile.Y > g.maxY {
+			g.maxY = tile.Y
+		}
+		g.tiles[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+
+	// Vertical borders, crossed eastward, then horizontal ones, crossed southward
+	for x := g.minX + pathClusterSize - 1; x < g.maxX; x += pathClusterSize {
+		g.linkBorder(models.Location{X: x, Y: g.minY}, models.Location{X: 1}, models.Location{Y: 1}, g.maxY-g.minY+1)
+	}
+	for y := g.minY + pathClusterSize - 1; y < g.maxY; y += pathClusterSize {
+		g.linkBorder(models.Location{X: g.minX, Y: y}, models.Location{Y: 1}, models.Location{X: 1}, g.maxX-g.minX+1)
+	}
+
+	for origin, entrances := range g.entrances {
+		passable := g.clusterPassable(origin, nil)
+		for _, entrance := range entrances {
+			cost, previous := searchRoutes(entrance, passable, g.tileAt, nil)
+			for _, other := range entrances {
+				if c, ok := cost[other]; ok && other != entrance {
+					g.edges[entrance] = append(g.edges[entrance], pathEdge{to: other, cost: c, path: tracePath(previous, entrance, other)})
+				}
+			}
+		}
+	}
+	return g
+}
+
+// linkBorder adds the crossings over one cluster border: the middle of each
+// open stretch of border, and every step that follows a river across it.
+// The border runs length tiles from start along step; cross leads over it.
+func (g *pathGraph) linkBorder(start, cross, step models.Location, length int) {
+	var stretch []models.Location
+	flush := func() {
+		if len(stretch) > 0 {
+			g.addCrossing(stretch[len(stretch)/2], cross)
+		}
+		stretch = nil
+	}
+	for i := 0; i < length; i++ {
+		loc := models.Location{X: start.X + i*step.X, Y: start.Y + i*step.Y}
+		over := models.Location{X: loc.X + cross.X, Y: loc.Y + cross.Y}
+		if i%pathClusterSize == 0 {
+			flush()
+		}
+		if !g.isLand(loc) || !g.isLand(over) {
+			flush()
+			continue
+		}
+		stretch = append(stretch, loc)
+		if terrain.SameRiver(g.tileAt(loc), g.tileAt(over)) {
+			g.addCrossing(loc, cross)
+		}
+	}
+	flush()
+}
+
+// addCrossing links a border tile with its neighbour over the border, both
+// ways, making both entrances of their clusters
+func (g *pathGraph) addCrossing(loc, cross models.Location) {
+	over := models.Location{X: loc.X + cross.X, Y: loc.Y + cross.Y}
+	for _, edge := range g.edges[loc] {
+		if edge.to == over {
+			return
+		}
+	}
+	cost := terrain.MoveCost(g.tileAt(loc), g.tileAt(over))
+	g.edges[loc] = append(g.edges[loc], pathEdge{to: over, cost: cost, path: []models.Location{loc, over}})
+	g.edges[over] = append(g.edges[over], pathEdge{to: loc, cost: cost, path: []models.Location{over, loc}})
+	g.addEntrance(loc)
+	g.addEntrance(over)
+}
+
+// addEntrance records a location as an entrance of its cluster
+func (g *pathGraph) addEntrance(loc models.Location) {
+	origin := g.clusterOf(loc)
+	for _, entrance := range g.entrances[origin] {
+		if entrance == loc {
+			return
+		}
+	}
+	g.entrances[origin] = append(g.entrances[origin], loc)
+}
+
+// route returns the cheapest route the graph knows between two locations,
+// both ends included. Like findRoute it falls back to the straight
+// findPath when that is no dearer.
+func (g *pathGraph) route(from, to models.Location) []models.Location {
+	key := [2]models.Location{from, to}
+	g.mu.Lock()
+	cached, ok := g.routes[key]
+	g.mu.Unlock()
+	if !ok {
+		if abs(to.X-from.X)+abs(to.Y-from.Y) < hierarchicalRouteDistance {
+			cached = findRoute(from, to, g.tileAt)
+		} else {
+			cached = findPath(from, to)
+			if planned, ok := g.plan(from, to); ok && routeCost(planned, g.tileAt) < routeCost(cached, g.tileAt) {
+				cached = planned
+			}
+		}
+		g.mu.Lock()
+		if len(g.routes) >= maxCachedRoutes {
+			g.routes = make(map[[2]models.Location][]models.Location)
+		}
+		g.routes[key] = cached
+		g.mu.Unlock()
+	}
+	return append([]models.Location(nil), cached...)
+}
+
+// plan searches the cluster graph for a route between two locations,
+// reporting false when no route over land joins them
+func (g *pathGraph) plan(from, to models.Location) ([]models.Location, bool) {
+	// Reach the entrances of the start's and the destination's clusters
+	// from each end; steps cost the same either way
+	startCost, startPrevious := searchRoutes(from, g.clusterPassable(g.clusterOf(from), &to), g.tileAt, nil)
+	endCost, endPrevious := searchRoutes(to, g.clusterPassable(g.clusterOf(to), &to), g.tileAt, nil)
+
+	cost := map[models.Location]float64{from: 0}
+	via := make(map[models.Location]models.Location)       // Node each node is reached from
+	segment := make(map[models.Location][]models.Location) // Path from that node
+	queue := &routeQueue{{loc: from}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(routeStep)
+		if current.cost > cost[current.loc] {
+			continue
+		}
+		if current.loc == to {
+			break
+		}
+
+		next := g.edges[current.loc]
+		if current.loc == from {
+			next = next[:len(next):len(next)]
+			if c, ok := startCost[to]; ok {
+				next = append(next, pathEdge{to: to, cost: c, path: tracePath(startPrevious, from, to)})
+			}
+			for _, entrance := range g.entrances[g.clusterOf(from)] {
+				if c, ok := startCost[entrance]; ok {
+					next = append(next, pathEdge{to: entrance, cost: c, path: tracePath(startPrevious, from, entrance)})
+				}
+			}
+		} else if c, ok := endCost[current.loc]; ok && g.clusterOf(current.loc) == g.clusterOf(to) {
+			next = append(next[:len(next):len(next)], pathEdge{to: to, cost: c, path: reversed(tracePath(endPrevious, to, current.loc))})
+		}
+
+		for _, edge := range next {
+			nextCost := current.cost + edge.cost
+			if known, ok := cost[edge.to]; ok && known <= nextCost {
+				continue
+			}
+			cost[edge.to] = nextCost
+			via[edge.to], segment[edge.to] = current.loc, edge.path
+			heap.Push(queue, routeStep{loc: edge.to, cost: nextCost})
+		}
+	}
+	if _, ok := cost[to]; !ok {
+		return nil, false
+	}
+
+	// Stitch the crossings together from the destination back
+	var segments [][]models.Location
+	for loc := to; loc != from; loc = via[loc] {
+		segments = append(segments, segment[loc])
+	}
+	route := []models.Location{from}
+	for i := len(segments) - 1; i >= 0; i-- {
+		route = append(route, segments[i][1:]...)
+	}
+	return route, true
+}
+
+// clusterOf returns the origin of the cluster holding a location
+func (g *pathGraph) clusterOf(loc models.Location) models.Location {
+	return models.Location{
+		X: g.minX + (loc.X-g.minX)/pathClusterSize*pathClusterSize,
+		Y: g.minY + (loc.Y-g.minY)/pathClusterSize*pathClusterSize,
+	}
+}
+
+// clusterPassable reports which locations a search within a cluster may
+// step onto: its land, and the destination when it lies in the cluster
+func (g *pathGraph) clusterPassable(origin models.Location, destination *models.Location) func(models.Location) bool {
+	return func(loc models.Location) bool {
+		if g.clusterOf(loc) != origin || loc.X < g.minX || loc.Y < g.minY {
+			return false
+		}
+		return g.isLand(loc) || (destination != nil && loc == *destination)
+	}
+}
+
+// isLand reports whether a location is a land tile of the map
+func (g *pathGraph) isLand(loc models.Location) bool {
+	tile := g.tiles[loc]
+	return tile != nil && !terrain.IsWater(tile.TerrainType)
+}
+
+// tileAt returns the map tile at a location, nil off the map
+func (g *pathGraph) tileAt(loc models.Location) *models.MapTile {
+	return g.tiles[loc]
+}
+
+// reversed returns a path's locations in reverse order
+func reversed(path []models.Location) []models.Location {
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// gameRoute returns a route between two locations on a game's map from the
+// game's cached cluster graph, building the graph on first use
+func (e *GameEngine) gameRoute(ctx context.Context, gameID string, from, to models.Location) []models.Location {
+	e.pathsMu.Lock()
+	graph, ok := e.pathGraphs[gameID]
+	e.pathsMu.Unlock()
+	if !ok {
+		tiles, err := e.repo.GetMapTiles(ctx, gameID, nil)
+		if err != nil {
+			log.Printf("Error loading the map of game %s for routing: %v", gameID, err)
+			return findPath(from, to)
+		}
+		graph = newPathGraph(tiles)
+		e.pathsMu.Lock()
+		e.pathGraphs[gameID] = graph
+		e.pathsMu.Unlock()
+	}
+	return graph.route(from, to)
+}
+
+// invalidatePaths drops a game's cluster graph and cached routes after its
+// terrain or tile ownership changes
+func (e *GameEngine) invalidatePaths(gameID string) {
+	e.pathsMu.Lock()
+	delete(e.pathGraphs, gameID)
+	e.pathsMu.Unlock()
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+// playerPolicies returns the policies a game's players have set, by player
+// ID. Players who set none are absent, and a nil policy reads as normal.
+func (e *GameEngine) playerPolicies(ctx context.Context, gameID string) map[string]*models.PlayerPolicy {
+	policies, err := e.repo.GetPlayerPolicies(ctx, gameID)
+	if err != nil {
+		log.Printf("Error loading player policies of game %s: %v", gameID, err)
+		return nil
+	}
+	byPlayer := make(map[string]*models.PlayerPolicy, len(policies))
+	for _, policy := range policies {
+		byPlayer[policy.PlayerID] = policy
+	}
+	return byPlayer
+}
+
+// playerSettings returns the settings a game's players have saved, by
+// player ID. Players who saved none are absent, and nil settings read as
+// unset.
+func (e *GameEngine) playerSettings(ctx context.Context, gameID string) map[string]*models.PlayerSettings {
+	settings, err := e.repo.GetPlayerSettings(ctx, gameID)
+	if err != nil {
+		log.Printf("Error loading player settings of game %s: %v", gameID, err)
+		return nil
+	}
+	byPlayer := make(map[string]*models.PlayerSettings, len(settings))
+	for _, saved := range settings {
+		byPlayer[saved.PlayerID] = saved
+	}
+	return byPlayer
+}
+
+// autoEndsTurn reports whether a player has asked the engine to go ahead
+// with the decisions it would otherwise wait on them for
+func (e *GameEngine) autoEndsTurn(ctx context.Context, gameID, playerID string) bool {
+	settings := e.playerSettings(ctx, gameID)[playerID]
+	return settings != nil && settings.AutoEndTurn
+}
+
+const (
+	baseMaxTaxRate     = 0.1    // Share of food any settlement's people bear paying as tax
+	baseMaxCorvee      = 0.1    // Share of work hours any settlement's people bear giving as corvée
+	foodPerGold        = 1000.0 // Taxed food that banks one gold
+	hoursPerProduction = 2000.0 // Corvée work hours that bank one production
+	taxUnhappiness     = 1.0    // Unhappiness a full tax rate brings
+	corveeUnhappiness  = 1.0    // Unhappiness a full corvée brings
+)
+
+// taxRateTechs raise the tax a settlement's people bear once they know each
+// technology: tended herds and managed pastures are wealth to be assessed
+var taxRateTechs = map[string]float64{
+	simulator.TechDomestication: 0.1,
+	simulator.TechHusbandry:     0.1,
+}
+
+// corveeTechs raise the corvée a settlement's people bear once they know
+// each technology: fire and built shelters free hours for common works
+var corveeTechs = map[string]float64{
+	simulator.TechFireMastery:     0.05,
+	simulator.TechShelterBuilding: 0.15,
+}
+
+// settlementLevers returns the tax rate and corvée share a player's policy
+// sets for one of their settlements, each bounded by what the settlement's
+// technologies let its people bear. A nil policy levies neither.
+func settlementLevers(policy *models.PlayerPolicy, settlement *models.Settlement) (float64, float64) {
+	if policy == nil {
+		return 0, 0
+	}
+	maxTax, maxCorvee := baseMaxTaxRate, baseMaxCorvee
+	for _, tech := range settlement.Technologies {
+		maxTax += taxRateTechs[tech]
+		maxCorvee += corveeTechs[tech]
+	}
+	return math.Min(math.Max(policy.TaxRate, 0), maxTax), math.Min(math.Max(policy.Corvee, 0), maxCorvee)
+}
+
+// leverUnhappiness returns the unhappiness a tax rate and corvée share bring
+func leverUnhappiness(tax, corvee float64) float64 {
+	return taxUnhappiness*tax + corveeUnhappiness*corvee
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"log"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// processPollution advances tile pollution by the years the tick spans.
+// Mines pollute their tile and dense settlements their whole work area,
+// while every polluted tile slowly recovers.
+func (e *GameEngine) processPollution(ctx context.Context, game *models.Game) error {
+	tiles := make(map[models.Location]*models.MapTile)
+	emitted := make(map[models.Location]float64)
+	track := func(tile *models.MapTile, amount float64) {
+		location := models.Location{X: tile.X, Y: tile.Y}
+		if _, ok := tiles[location]; !ok {
+			tiles[location] = tile
+		}
+		emitted[location] += amount
+	}
+
+	polluted, err := e.repo.GetPollutedTiles(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	for _, tile := range polluted {
+		track(tile, 0)
+	}
+
+	mines, err := e.repo.GetTilesWithImprovement(ctx, game.GameID, models.ImprovementMine)
+	if err != nil {
+		return err
+	}
+	for _, tile := range mines {
+		track(tile, terrain.MinePollution)
+	}
+
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	for _, settlement := range settlements {
+		amount := terrain.SettlementEmissions(settlement.Population)
+		if amount == 0 {
+			continue
+		}
+		for _, tile := range e.settlementWorkTiles(ctx, game, settlement) {
+			track(tile, amount)
+		}
+	}
+
+	years := game.YearsPerTick()
+	var changed []*models.MapTile
+	for location, tile := range tiles {
+		polluted := false
+		for year := 0; year < years; year++ {
+			polluted = terrain.AdvancePollution(tile, emitted[location]) || polluted
+		}
+		if !polluted {
+			continue
+		}
+		tile.LastModifiedTick = game.CurrentYear
+		if err := e.repo.UpdateTilePollution(ctx, tile); err != nil {
+			log.Printf("Error updating pollution at (%d, %d) in game %s: %v", tile.X, tile.Y, game.GameID, err)
+			continue
+		}
+		changed = append(changed, tile)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	return e.refreshSettlementYields(ctx, game, changed)
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// processSettlementProgression grows settlements into the next settlement
+// type once their population and buildings qualify them: a camp becomes a
+// village, a village a town and a town a city. A settlement never shrinks
+// back. Growing widens the settlement's work area, claiming the unowned
+// tiles it newly reaches, and strengthens its walls.
+func (e *GameEngine) processSettlementProgression(ctx context.Context, game *models.Game) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+
+	rules := game.Ruleset()
+	for _, settlement := range settlements {
+		tier := settlement.QualifiedTier()
+		if tier <= settlement.Tier() {
+			continue
+		}
+		previous := models.SettlementTiers[settlement.Tier()].Type
+		settlement.Type = models.SettlementTiers[tier].Type
+		settlement.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating type of settlement %s: %v", settlement.SettlementID, err)
+			continue
+		}
+
+		if err := e.repo.AssignTiles(ctx, game.GameID, settlement.SettlementID, settlement.PlayerID, workAreaLocations(settlement.Location, settlement.WorkRadius(rules)), game.CurrentYear); err != nil {
+			log.Printf("Error assigning tiles to settlement %s: %v", settlement.SettlementID, err)
+		}
+		e.invalidatePaths(game.GameID)
+		if sim, ok := e.settlementSims[settlement.SettlementID]; ok {
+			applyWorkArea(&sim.Conditions, e.settlementWorkTiles(ctx, game, settlement))
+		}
+
+		event := &models.GameEvent{
+			EventID:   generateUUID(),
+			GameID:    game.GameID,
+			Year:      game.CurrentYear,
+			Type:      models.EventSettlementGrew,
+			PlayerID:  settlement.PlayerID,
+			Detail:    fmt.Sprintf("%s grew from a %s into a %s", settlement.Name, previous, settlement.Type),
+			CreatedAt: time.Now(),
+		}
+		if err := e.createEvent(ctx, event); err != nil {
+			log.Printf("Error recording growth of settlement %s: %v", settlement.SettlementID, err)
+		}
+		log.Printf("Game %s: %s is now a %s", game.GameID, settlement.Name, settlement.Type)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// transformationTechs is the technology a player must know to start each
+// terrain transformation: built shelters teach the digging and shoring that
+// irrigation channels and drainage ditches need
+var transformationTechs = map[string]string{
+	terrain.TransformIrrigation: simulator.TechShelterBuilding,
+	terrain.TransformDrainage:   simulator.TechShelterBuilding,
+}
+
+// executeTransformOrder sets a workers unit to transform the tile it stands
+// on. The project takes the transformation's years; the unit stays put and
+// does no other work until it is done.
+func (e *GameEngine) executeTransformOrder(ctx context.Context, game *models.Game, order *models.Order, unit *models.Unit) error {
+	if unit == nil || unit.PlayerID != order.PlayerID {
+		return fmt.Errorf("unit %s not found for player", order.UnitID)
+	}
+	if unit.UnitType != models.UnitTypeWorkers {
+		return fmt.Errorf("unit %s is not a workers unit", unit.UnitID)
+	}
+	if unit.Project != nil {
+		return fmt.Errorf("workers %s are already carrying out %s", unit.UnitID, unit.Project.Transformation)
+	}
+	transformation, ok := terrain.Transformations[order.Item]
+	if !ok {
+		return fmt.Errorf("unknown transformation %q", order.Item)
+	}
+
+	tile, err := e.repo.GetMapTile(ctx, game.GameID, unit.Location.X, unit.Location.Y)
+	if err != nil || tile == nil {
+		return fmt.Errorf("no tile at (%d, %d)", unit.Location.X, unit.Location.Y)
+	}
+	if !terrain.CanTransform(tile, transformation) {
+		return fmt.Errorf("%s cannot be carried out at (%d, %d)", transformation.Name, tile.X, tile.Y)
+	}
+
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, unit.PlayerID)
+	if err != nil {
+		return err
+	}
+	tech := transformationTechs[transformation.Name]
+	known := false
+	for _, settlement := range settlements {
+		known = known || containsString(settlement.Technologies, tech)
+	}
+	if !known {
+		return fmt.Errorf("%s needs %s", transformation.Name, tech)
+	}
+
+	unit.Project = &models.Project{
+		Transformation: transformation.Name,
+		Location:       unit.Location,
+		StartedYear:    game.CurrentYear,
+		YearsLeft:      transformation.Years,
+	}
+	unit.LastUpdated = time.Now()
+	if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+		return err
+	}
+	log.Printf("Game %s: workers %s began %s at (%d, %d), due in %d years",
+		game.GameID, unit.UnitID, transformation.Name, tile.X, tile.Y, transformation.Years)
+	return nil
+}
+
+// processProjects advances every workers unit's terrain project by the years
+// the tick spans.
+// A finished project changes the tile's terrain, then the yields of the
+// settlements working it and the routes across it are recomputed; players
+// see the new terrain as soon as exploration records the tile again.
+// Projects are processed in unit ID order.
+func (e *GameEngine) processProjects(ctx context.Context, game *models.Game) error {
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	var workers []*models.Unit
+	for _, unit := range units {
+		if unit.Project != nil {
+			workers = append(workers, unit)
+		}
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].UnitID < workers[j].UnitID })
+
+	var changed []*models.MapTile
+	for _, unit := range workers {
+		// Copy the project so a repository that shares the stored unit does
+		// not see it change before it is saved
+		project := *unit.Project
+		project.YearsLeft -= game.YearsPerTick()
+		unit.Project = &project
+		unit.LastUpdated = time.Now()
+		if project.YearsLeft > 0 && unit.Location == project.Location {
+			if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+				log.Printf("Error advancing project of workers %s: %v", unit.UnitID, err)
+			}
+			continue
+		}
+
+		unit.Project = nil
+		if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+			log.Printf("Error ending project of workers %s: %v", unit.UnitID, err)
+			continue
+		}
+		if unit.Location != project.Location {
+			log.Printf("Game %s: workers %s left their %s project at (%d, %d) unfinished",
+				game.GameID, unit.UnitID, project.Transformation, project.Location.X, project.Location.Y)
+			continue
+		}
+		if tile := e.completeProject(ctx, game, unit, project); tile != nil {
+			changed = append(changed, tile)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	e.invalidatePaths(game.GameID)
+	return e.refreshSettlementYields(ctx, game, changed)
+}
+
+// completeProject carries out a finished transformation, returning the
+// changed tile or nil if the tile can no longer be transformed
+func (e *GameEngine) completeProject(ctx context.Context, game *models.Game, unit *models.Unit, project models.Project) *models.MapTile {
+	transformation := terrain.Transformations[project.Transformation]
+	tile, err := e.repo.GetMapTile(ctx, game.GameID, project.Location.X, project.Location.Y)
+	if err != nil || tile == nil {
+		log.Printf("Error loading tile (%d, %d) for workers %s: %v", project.Location.X, project.Location.Y, unit.UnitID, err)
+		return nil
+	}
+	from := tile.TerrainType
+	if !terrain.Transform(tile, transformation) {
+		log.Printf("Game %s: %s at (%d, %d) is no longer possible on %s", game.GameID, project.Transformation, tile.X, tile.Y, from)
+		return nil
+	}
+	tile.LastModifiedTick = game.CurrentYear
+	if err := e.repo.UpdateTileTerrain(ctx, tile); err != nil {
+		log.Printf("Error transforming tile (%d, %d) in game %s: %v", tile.X, tile.Y, game.GameID, err)
+		return nil
+	}
+
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventLandTransformed,
+		PlayerID:  unit.PlayerID,
+		Detail:    fmt.Sprintf("%s turned %s at (%d, %d) into %s", project.Transformation, from, tile.X, tile.Y, tile.TerrainType),
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording %s event in game %s: %v", event.Type, game.GameID, err)
+	}
+	log.Printf("Game %s: workers %s finished %s at (%d, %d)", game.GameID, unit.UnitID, project.Transformation, tile.X, tile.Y)
+	return tile
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// NewQueryHandler serves read-only queries the web server forwards to the
+// engine, such as combat, mortality and tile yield previews, and the
+// engine's load for orchestration.
+// No query changes game state; queries about a settlement only note that a
+// player is looking at it, see NoteAttention.
+func NewQueryHandler(engine *GameEngine) http.Handler {
+	mux := http.NewServeMux()
+
+	// GET /combat/preview?gameId=&attackerId=&defenderId= returns CombatOdds
+	mux.HandleFunc("GET /combat/preview", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		gameID, attackerID, defenderID := query.Get("gameId"), query.Get("attackerId"), query.Get("defenderId")
+		if gameID == "" || attackerID == "" || defenderID == "" {
+			writeQueryError(w, http.StatusBadRequest, "gameId, attackerId and defenderId are required")
+			return
+		}
+
+		odds, err := engine.PreviewCombat(r.Context(), gameID, attackerID, defenderID)
+		if errors.Is(err, ErrCombatantNotFound) || errors.Is(err, repository.ErrNotFound) {
+			writeQueryError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, repository.ErrUnavailable) {
+			writeQueryError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if err != nil {
+			writeQueryError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to preview combat: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(odds)
+	})
+
+	// GET /mortality?gameId=[&settlementId=] returns MortalityReports
+	mux.HandleFunc("GET /mortality", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		gameID := query.Get("gameId")
+		if gameID == "" {
+			writeQueryError(w, http.StatusBadRequest, "gameId is required")
+			return
+		}
+
+		if settlementID := query.Get("settlementId"); settlementID != "" {
+			engine.NoteAttention(settlementID)
+		}
+		reports, err := engine.SettlementMortality(r.Context(), gameID, query.Get("settlementId"))
+		if errors.Is(err, repository.ErrNotFound) {
+			writeQueryError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, repository.ErrUnavailable) {
+			writeQueryError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if err != nil {
+			writeQueryError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to report mortality: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reports)
+	})
+
+	// GET /yields?gameId=&x=&y=[&playerId=][&improvements=A,B][&techs=a,b]
+	// returns a TileYieldsPreview; an empty list previews none
+	mux.HandleFunc("GET /yields", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		gameID := query.Get("gameId")
+		x, errX := strconv.Atoi(query.Get("x"))
+		y, errY := strconv.Atoi(query.Get("y"))
+		if gameID == "" || errX != nil || errY != nil {
+			writeQueryError(w, http.StatusBadRequest, "gameId, x and y are required")
+			return
+		}
+
+		preview, err := engine.PreviewTileYields(r.Context(), gameID, x, y, query.Get("playerId"), queryList(query, "improvements"), queryList(query, "techs"))
+		if errors.Is(err, repository.ErrNotFound) {
+			writeQueryError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, repository.ErrUnavailable) {
+			writeQueryError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if err != nil {
+			writeQueryError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to preview yields: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(preview)
+	})
+
+	// POST /attention?settlementId= notes that a player is looking at a
+	// settlement, which then simulates in full under attention fidelity
+	mux.HandleFunc("POST /attention", func(w http.ResponseWriter, r *http.Request) {
+		settlementID := r.URL.Query().Get("settlementId")
+		if settlementID == "" {
+			writeQueryError(w, http.StatusBadRequest, "settlementId is required")
+			return
+		}
+		engine.NoteAttention(settlementID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// GET /capacity returns the engine's Capacity for autoscaling
+	mux.HandleFunc("GET /capacity", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(engine.Capacity())
+	})
+
+	// GET /metrics exposes the same figures in the Prometheus text format
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeCapacityMetrics(w, engine.Capacity())
+	})
+
+	return mux
+}
+
+// writeQueryError writes a JSON error response
+func writeQueryError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// StartQueryServer serves engine queries on the given port until the process
+// exits
+func StartQueryServer(engine *GameEngine, port int) {
+	addr := fmt.Sprintf(":%d", port)
+	log.Printf("Starting engine query server on %s", addr)
+	if err := http.ListenAndServe(addr, NewQueryHandler(engine)); err != nil {
+		log.Printf("Query server error: %v", err)
+	}
+}
+
+// queryList splits a comma-separated query parameter, returning nil when it
+// is absent and an empty list when it is empty
+func queryList(query url.Values, name string) []string {
+	if !query.Has(name) {
+		return nil
+	}
+	list := []string{}
+	for _, item := range strings.Split(query.Get(name), ",") {
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
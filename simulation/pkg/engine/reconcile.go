@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// populationAuditInterval is how many game years pass between population audits
+const populationAuditInterval = 50
+
+// PopulationTotals is a player's population recomputed from its source entities
+type PopulationTotals struct {
+	Settlements int // Living humans across the player's settlements
+	Units       int // Humans travelling with the player's units
+	Total       int
+}
+
+// reconcilePopulation recomputes every settlement's population from its
+// running simulation, logs any drift from the persisted value and writes the
+// recomputed value back. It returns the per-player totals and the number of
+// settlements healed.
+func (e *GameEngine) reconcilePopulation(ctx context.Context, game *models.Game) (map[string]PopulationTotals, int, error) {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return nil, 0, err
+	}
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totals := make(map[string]PopulationTotals)
+	healed := 0
+	for _, settlement := range settlements {
+		actual := settlement.Population
+		if sim, ok := e.settlementSims[settlement.SettlementID]; ok {
+			actual = sim.Population()
+		}
+		if actual < 0 {
+			actual = 0
+		}
+
+		if actual != settlement.Population {
+			log.Printf("Population drift in game %s: settlement %s recorded %d, actual %d",
+				game.GameID, settlement.SettlementID, settlement.Population, actual)
+			settlement.Population = actual
+			settlement.LastUpdated = time.Now()
+			if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+				log.Printf("Error healing settlement %s population: %v", settlement.SettlementID, err)
+			} else {
+				healed++
+			}
+		}
+
+		player := totals[settlement.PlayerID]
+		player.Settlements += actual
+		player.Total += actual
+		totals[settlement.PlayerID] = player
+	}
+
+	for _, unit := range units {
+		player := totals[unit.PlayerID]
+		player.Units += unit.PopulationCost
+		player.Total += unit.PopulationCost
+		totals[unit.PlayerID] = player
+	}
+
+	return totals, healed, nil
+}
+
+// processPopulationAudit runs the population reconciliation every
+// populationAuditInterval years
+func (e *GameEngine) processPopulationAudit(ctx context.Context, game *models.Game) error {
+	if len(game.TickYearsEvery(populationAuditInterval)) == 0 {
+		return nil
+	}
+
+	totals, healed, err := e.reconcilePopulation(ctx, game)
+	if err != nil {
+		return err
+	}
+	if healed > 0 {
+		log.Printf("Population audit for game %s healed %d settlements", game.GameID, healed)
+	}
+	for playerID, player := range totals {
+		log.Printf("Game %s player %s population: %d (%d settled, %d in units)",
+			game.GameID, playerID, player.Total, player.Settlements, player.Units)
+	}
+	return nil
+}
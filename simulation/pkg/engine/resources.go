@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"log"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// processResourceDepletion advances resource quantities by the years the
+// tick spans: tiles consume the resources their improvements extract, renewables regrow, and
+// exhausted finite deposits disappear from the map
+func (e *GameEngine) processResourceDepletion(ctx context.Context, game *models.Game) error {
+	tiles, err := e.repo.GetResourceTiles(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+
+	years := game.YearsPerTick()
+	var changed []*models.MapTile
+	for _, tile := range tiles {
+		depleted := false
+		for year := 0; year < years; year++ {
+			depleted = terrain.DepleteResources(tile) || depleted
+		}
+		if !depleted {
+			continue
+		}
+		tile.LastModifiedTick = game.CurrentYear
+		if err := e.repo.UpdateTileResources(ctx, tile); err != nil {
+			log.Printf("Error updating resources at (%d, %d) in game %s: %v", tile.X, tile.Y, game.GameID, err)
+			continue
+		}
+		changed = append(changed, tile)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	return e.refreshSettlementYields(ctx, game, changed)
+}
+
+// processSoil advances soil fertility by the years the tick spans: farms
+// wear their soil down and fallow soil recovers toward its natural fertility
+func (e *GameEngine) processSoil(ctx context.Context, game *models.Game) error {
+	tiles, err := e.repo.GetSoilTiles(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+
+	years := game.YearsPerTick()
+	var changed []*models.MapTile
+	for _, tile := range tiles {
+		worn := false
+		for year := 0; year < years; year++ {
+			worn = terrain.DepleteSoil(tile) || worn
+		}
+		if !worn {
+			continue
+		}
+		tile.LastModifiedTick = game.CurrentYear
+		if err := e.repo.UpdateTileFertility(ctx, tile); err != nil {
+			log.Printf("Error updating soil at (%d, %d) in game %s: %v", tile.X, tile.Y, game.GameID, err)
+			continue
+		}
+		changed = append(changed, tile)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	return e.refreshSettlementYields(ctx, game, changed)
+}
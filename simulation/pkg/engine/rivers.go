@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"container/heap"
+	"context"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// routeDetour is how far outside the straight line between two locations a
+// route may wander to follow a river
+const routeDetour = 2
+
+// findRoute returns the cheapest tiles a unit crosses from one location to
+// another, both ends included, given the tiles it can look up. Steps along a
+// river cost less (see terrain.MoveCost) and water is impassable; when no
+// river shortens the trip the route is the straight findPath.
+func findRoute(from, to models.Location, tileAt func(models.Location) *models.MapTile) []models.Location {
+	straight := findPath(from, to)
+	straightCost := routeCost(straight, tileAt)
+
+	minX, maxX := min(from.X, to.X)-routeDetour, max(from.X, to.X)+routeDetour
+	minY, maxY := min(from.Y, to.Y)-routeDetour, max(from.Y, to.Y)+routeDetour
+	passable := func(loc models.Location) bool {
+		if loc.X < minX || loc.X > maxX || loc.Y < minY || loc.Y > maxY {
+			return false
+		}
+		tile := tileAt(loc)
+		return tile == nil || !terrain.IsWater(tile.TerrainType) || loc == to
+	}
+
+	cost, previous := searchRoutes(from, passable, tileAt, func(loc models.Location) bool { return loc == to })
+	best, ok := cost[to]
+	if !ok || best >= straightCost {
+		return straight
+	}
+	return tracePath(previous, from, to)
+}
+
+// searchRoutes finds the cheapest cost from a location to every passable
+// location it reaches, stopping early once done reports true for the
+// cheapest location left. previous holds the step each location is reached
+// from, for tracePath.
+func searchRoutes(from models.Location, passable func(models.Location) bool, tileAt func(models.Location) *models.MapTile, done func(models.Location) bool) (cost map[models.Location]float64, previous map[models.Location]models.Location) {
+	cost = map[models.Location]float64{from: 0}
+	previous = make(map[models.Location]models.Location)
+	queue := &routeQueue{{loc: from}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(routeStep)
+		if current.cost > cost[current.loc] {
+			continue
+		}
+		if done != nil && done(current.loc) {
+			break
+		}
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			next := models.Location{X: current.loc.X + d[0], Y: current.loc.Y + d[1]}
+			if !passable(next) {
+				continue
+			}
+			nextCost := current.cost + terrain.MoveCost(tileAt(current.loc), tileAt(next))
+			if known, ok := cost[next]; ok && known <= nextCost {
+				continue
+			}
+			cost[next] = nextCost
+			previous[next] = current.loc
+			heap.Push(queue, routeStep{loc: next, cost: nextCost})
+		}
+	}
+	return cost, previous
+}
+
+// tracePath follows the steps searchRoutes recorded back from a location it
+// reached, returning the path from the search's start, both ends included
+func tracePath(previous map[models.Location]models.Location, from, to models.Location) []models.Location {
+	path := []models.Location{to}
+	for loc := to; loc != from; {
+		loc = previous[loc]
+		path = append(path, loc)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// routeCost sums the movement cost of each step along a path
+func routeCost(path []models.Location, tileAt func(models.Location) *models.MapTile) float64 {
+	total := 0.0
+	for i := 1; i < len(path); i++ {
+		total += terrain.MoveCost(tileAt(path[i-1]), tileAt(path[i]))
+	}
+	return total
+}
+
+// routeStep is a location queued for route search with its cost so far
+type routeStep struct {
+	loc  models.Location
+	cost float64
+}
+
+// routeQueue orders route steps by cost, then row, then column, so routes
+// are deterministic
+type routeQueue []routeStep
+
+func (q routeQueue) Len() int { return len(q) }
+func (q routeQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	if q[i].loc.Y != q[j].loc.Y {
+		return q[i].loc.Y < q[j].loc.Y
+	}
+	return q[i].loc.X < q[j].loc.X
+}
+func (q routeQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x any)   { *q = append(*q, x.(routeStep)) }
+func (q *routeQueue) Pop() any {
+	old := *q
+	step := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return step
+}
+
+// riverPartners counts, for each settlement, the other settlements whose work
+// areas touch a river system its own work area touches
+func (e *GameEngine) riverPartners(ctx context.Context, game *models.Game, settlements []*models.Settlement) map[string]int {
+	systems := e.riverSystems(ctx, game, settlements)
+
+	partners := make(map[string]int, len(settlements))
+	for _, settlement := range settlements {
+		for _, other := range settlements {
+			if other.SettlementID != settlement.SettlementID && sharesRiver(systems[settlement.SettlementID], systems[other.SettlementID]) {
+				partners[settlement.SettlementID]++
+			}
+		}
+	}
+	return partners
+}
+
+// riverSystems returns, for each settlement, the river systems its work
+// area touches
+func (e *GameEngine) riverSystems(ctx context.Context, game *models.Game, settlements []*models.Settlement) map[string]map[int]bool {
+	systems := make(map[string]map[int]bool, len(settlements))
+	for _, settlement := range settlements {
+		touched := make(map[int]bool)
+		for _, tile := range e.settlementWorkTiles(ctx, game, settlement) {
+			if tile.RiverID != 0 {
+				touched[tile.RiverID] = true
+			}
+		}
+		systems[settlement.SettlementID] = touched
+	}
+	return systems
+}
+
+// sharesRiver reports whether two sets of river systems have one in common
+func sharesRiver(a, b map[int]bool) bool {
+	for river := range a {
+		if b[river] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,337 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	trafficRetention  = 0.8  // Share of a route's traffic still counted a year on
+	tripTraffic       = 1.0  // Traffic a unit's trip between two settlements adds
+	riverTradeTraffic = 0.5  // Traffic a year of trade along a shared river adds
+	busyRouteTraffic  = 2.0  // Traffic at which a route between two settlements wants a road
+	minTraffic        = 0.01 // Traffic below which a route is forgotten
+)
+
+// trip is a unit's move during a tick, from where it started to where it
+// stopped
+type trip struct {
+	playerID string
+	from, to models.Location
+}
+
+// busyRoute is a route between two of a player's settlements busy enough
+// to want a road, with the tiles along it still to be paved
+type busyRoute struct {
+	from, to *models.Settlement
+	unpaved  []models.Location
+}
+
+// noteTrip records a unit's move for the game's next road planning
+func (e *GameEngine) noteTrip(gameID string, t trip) {
+	e.tripsMu.Lock()
+	e.trips[gameID] = append(e.trips[gameID], t)
+	e.tripsMu.Unlock()
+}
+
+// drainTrips returns and forgets the moves noted for a game
+func (e *GameEngine) drainTrips(gameID string) []trip {
+	e.tripsMu.Lock()
+	defer e.tripsMu.Unlock()
+	trips := e.trips[gameID]
+	delete(e.trips, gameID)
+	return trips
+}
+
+// processRoadPlanning turns the year's unit trips and river trade into
+// traffic between each player's settlements. A trip counts between the
+// settlements nearest where a unit started and stopped, so a journey counts
+// once, the year it passes the midpoint. Routes busy enough to want a road
+// and not yet paved get one proposed for the player to approve, or, under
+// a high infrastructure policy, planned for workers to pave at once.
+func (e *GameEngine) processRoadPlanning(ctx context.Context, game *models.Game) error {
+	trips := e.drainTrips(game.GameID)
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+
+	owned := make(map[string][]*models.Settlement)
+	var players []*models.Settlement
+	for _, settlement := range settlements {
+		if settlement.PlayerID == models.NeutralPlayerID || models.IsMinorCiv(settlement.PlayerID) {
+			continue
+		}
+		owned[settlement.PlayerID] = append(owned[settlement.PlayerID], settlement)
+		players = append(players, settlement)
+	}
+
+	changed := make(map[string]bool)
+	for _, settlement := range players {
+		for partner, traffic := range settlement.Traffic {
+			if traffic *= trafficRetention; traffic < minTraffic {
+				delete(settlement.Traffic, partner)
+			} else {
+				settlement.Traffic[partner] = traffic
+			}
+			changed[settlement.SettlementID] = true
+		}
+	}
+	addTraffic := func(a, b *models.Settlement, traffic float64) {
+		for _, pair := range [][2]*models.Settlement{{a, b}, {b, a}} {
+			if pair[0].Traffic == nil {
+				pair[0].Traffic = make(map[string]float64)
+			}
+			pair[0].Traffic[pair[1].SettlementID] += traffic
+			changed[pair[0].SettlementID] = true
+		}
+	}
+
+	for _, t := range trips {
+		from, to := nearestSettlement(owned[t.playerID], t.from), nearestSettlement(owned[t.playerID], t.to)
+		if from != nil && to != nil && from != to {
+			addTraffic(from, to, tripTraffic)
+		}
+	}
+	systems := e.riverSystems(ctx, game, players)
+	for _, group := range owned {
+		for i, a := range group {
+			for _, b := range group[i+1:] {
+				if sharesRiver(systems[a.SettlementID], systems[b.SettlementID]) {
+					addTraffic(a, b, riverTradeTraffic)
+				}
+			}
+		}
+	}
+
+	policies := e.playerPolicies(ctx, game.GameID)
+	for _, route := range e.busyRoutes(ctx, game, players) {
+		switch policies[route.from.PlayerID].InfrastructureLevel() {
+		case models.PolicyHigh:
+			if planRoad(route.from, route.unpaved) > 0 {
+				changed[route.from.SettlementID] = true
+				log.Printf("Game %s: planned a road from %s to %s", game.GameID, route.from.Name, route.to.Name)
+			}
+		case models.PolicyNormal:
+			if !roadPlanned(route, owned[route.from.PlayerID]) {
+				order := &models.Order{OrderType: models.OrderTypeRoad, SettlementID: route.from.SettlementID, Target: &route.to.Location}
+				e.propose(ctx, game, route.from, "road:"+route.to.SettlementID, order,
+					fmt.Sprintf("The people of %s propose a road to %s", route.from.Name, route.to.Name))
+				changed[route.from.SettlementID] = true
+			}
+		}
+	}
+
+	// Drop tiles paved since from road plans
+	if paved, err := e.pavedTiles(ctx, game.GameID); err == nil {
+		for _, settlement := range players {
+			plan := settlement.RoadPlan[:0]
+			for _, loc := range settlement.RoadPlan {
+				if !paved[loc] {
+					plan = append(plan, loc)
+				}
+			}
+			if len(plan) != len(settlement.RoadPlan) {
+				settlement.RoadPlan = plan
+				changed[settlement.SettlementID] = true
+			}
+		}
+	} else {
+		log.Printf("Error loading roads of game %s: %v", game.GameID, err)
+	}
+
+	for _, settlement := range players {
+		if !changed[settlement.SettlementID] {
+			continue
+		}
+		settlement.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating traffic of settlement %s: %v", settlement.SettlementID, err)
+		}
+	}
+	return nil
+}
+
+// busyRoutes returns the routes between settlements of the same player
+// whose traffic wants a road and that still have tiles to pave, in
+// settlement ID order. Routes that cross water are left to ships.
+func (e *GameEngine) busyRoutes(ctx context.Context, game *models.Game, settlements []*models.Settlement) []busyRoute {
+	byID := make(map[string]*models.Settlement, len(settlements))
+	for _, settlement := range settlements {
+		byID[settlement.SettlementID] = settlement
+	}
+	var pairs [][2]*models.Settlement
+	for _, settlement := range settlements {
+		for partnerID, traffic := range settlement.Traffic {
+			partner := byID[partnerID]
+			if partner != nil && partner.PlayerID == settlement.PlayerID && settlement.SettlementID < partnerID && traffic >= busyRouteTraffic {
+				pairs = append(pairs, [2]*models.Settlement{settlement, partner})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0].SettlementID != pairs[j][0].SettlementID {
+			return pairs[i][0].SettlementID < pairs[j][0].SettlementID
+		}
+		return pairs[i][1].SettlementID < pairs[j][1].SettlementID
+	})
+
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		log.Printf("Error loading the map of game %s for road planning: %v", game.GameID, err)
+		return nil
+	}
+	tileAt := make(map[models.Location]*models.MapTile, len(tiles))
+	for _, tile := range tiles {
+		tileAt[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+
+	var routes []busyRoute
+	for _, pair := range pairs {
+		// The far settlement's own tile needs no paving
+		route := e.gameRoute(ctx, game.GameID, pair[0].Location, pair[1].Location)
+		if unpaved, ok := unpavedTiles(route[:len(route)-1], func(loc models.Location) *models.MapTile { return tileAt[loc] }); ok && len(unpaved) > 0 {
+			routes = append(routes, busyRoute{from: pair[0], to: pair[1], unpaved: unpaved})
+		}
+	}
+	return routes
+}
+
+// unpavedTiles returns the tiles along a route, after its start, that have
+// no road, reporting false when the route leaves the land
+func unpavedTiles(route []models.Location, tileAt func(models.Location) *models.MapTile) ([]models.Location, bool) {
+	if len(route) < 2 {
+		return nil, true
+	}
+	var unpaved []models.Location
+	for _, loc := range route[1:] {
+		tile := tileAt(loc)
+		if tile == nil || terrain.IsWater(tile.TerrainType) {
+			return nil, false
+		}
+		if !containsString(tile.Improvements, models.ImprovementRoad) {
+			unpaved = append(unpaved, loc)
+		}
+	}
+	return unpaved, true
+}
+
+// planRoad adds the tiles of a road not already planned to a settlement's
+// road plan, returning how many it added
+func planRoad(settlement *models.Settlement, tiles []models.Location) int {
+	planned := make(map[models.Location]bool, len(settlement.RoadPlan))
+	for _, loc := range settlement.RoadPlan {
+		planned[loc] = true
+	}
+	added := 0
+	for _, loc := range tiles {
+		if !planned[loc] {
+			settlement.RoadPlan = append(settlement.RoadPlan, loc)
+			planned[loc] = true
+			added++
+		}
+	}
+	return added
+}
+
+// roadPlanned reports whether every unpaved tile of a route is already in
+// the road plan of one of the player's settlements
+func roadPlanned(route busyRoute, settlements []*models.Settlement) bool {
+	planned := make(map[models.Location]bool)
+	for _, settlement := range settlements {
+		for _, loc := range settlement.RoadPlan {
+			planned[loc] = true
+		}
+	}
+	for _, loc := range route.unpaved {
+		if !planned[loc] {
+			return false
+		}
+	}
+	return true
+}
+
+// pavedTiles returns the locations of a game's roads
+func (e *GameEngine) pavedTiles(ctx context.Context, gameID string) (map[models.Location]bool, error) {
+	tiles, err := e.repo.GetTilesWithImprovement(ctx, gameID, models.ImprovementRoad)
+	if err != nil {
+		return nil, err
+	}
+	paved := make(map[models.Location]bool, len(tiles))
+	for _, tile := range tiles {
+		paved[models.Location{X: tile.X, Y: tile.Y}] = true
+	}
+	return paved, nil
+}
+
+// nearestSettlement returns the settlement closest to a location, the
+// lowest settlement ID among equals, or nil when there are none
+func nearestSettlement(settlements []*models.Settlement, loc models.Location) *models.Settlement {
+	var nearest *models.Settlement
+	for _, settlement := range settlements {
+		if nearest == nil || manhattan(settlement.Location, loc) < manhattan(nearest.Location, loc) {
+			nearest = settlement
+		}
+	}
+	return nearest
+}
+
+// executeRoadOrder plans a road from one of the player's settlements to the
+// order's target for their workers to pave. The road follows the route
+// units take and must stay on land.
+func (e *GameEngine) executeRoadOrder(ctx context.Context, game *models.Game, order *models.Order) error {
+	if order.Target == nil {
+		return fmt.Errorf("a road order needs a target")
+	}
+	settlements, err := e.repo.GetSettlementsByPlayer(ctx, game.GameID, order.PlayerID)
+	if err != nil {
+		return err
+	}
+	var settlement *models.Settlement
+	for _, s := range settlements {
+		if s.SettlementID == order.SettlementID {
+			settlement = s
+		}
+	}
+	if settlement == nil {
+		return fmt.Errorf("settlement %s not found for player", order.SettlementID)
+	}
+
+	route := e.gameRoute(ctx, game.GameID, settlement.Location, *order.Target)
+	for _, s := range settlements {
+		if s.Location == *order.Target {
+			route = route[:len(route)-1] // A settlement's own tile needs no paving
+			break
+		}
+	}
+	unpaved, ok := unpavedTiles(route, func(loc models.Location) *models.MapTile {
+		tile, err := e.repo.GetMapTile(ctx, game.GameID, loc.X, loc.Y)
+		if err != nil {
+			return nil
+		}
+		return tile
+	})
+	if !ok {
+		return fmt.Errorf("no road over land joins %s to (%d, %d)", settlement.Name, order.Target.X, order.Target.Y)
+	}
+	if planRoad(settlement, unpaved) == 0 {
+		return fmt.Errorf("the road from %s to (%d, %d) is already paved or planned", settlement.Name, order.Target.X, order.Target.Y)
+	}
+
+	settlement.LastUpdated = time.Now()
+	if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+		return err
+	}
+	log.Printf("Game %s: %s planned a road to (%d, %d)", game.GameID, settlement.Name, order.Target.X, order.Target.Y)
+	return nil
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// pollInterval is how often the engine looks for games due a tick. It is
+// also the time budget of one pass: games that would overrun it are left
+// for a later pass, staggering heavy games across the tick interval.
+const pollInterval = 100 * time.Millisecond
+
+const (
+	tickCostSmoothing = 0.2 // Weight of the latest tick in a game's average tick cost
+	overloadTicks     = 5   // Consecutive ticks over the interval before a game counts as overloaded
+	recoveryRatio     = 0.5 // Fraction of the interval an overloaded game's average must fall under to recover
+)
+
+// tickCost tracks how long a game's ticks take
+type tickCost struct {
+	average    time.Duration // Exponentially smoothed tick duration
+	overruns   int           // Consecutive ticks longer than the tick interval
+	overloaded bool
+}
+
+// SetAdaptiveFidelity chooses whether overloaded games drop to aggregated
+// simulation until they can keep up again
+func (e *GameEngine) SetAdaptiveFidelity(enabled bool) {
+	e.adaptiveFidelity = enabled
+}
+
+// adaptiveFidelityFromEnv reads ADAPTIVE_FIDELITY, off unless "true"
+func adaptiveFidelityFromEnv() bool {
+	return os.Getenv("ADAPTIVE_FIDELITY") == "true"
+}
+
+// scheduleTicks orders the games due a tick, most overdue first, so that
+// games deferred by a pass's budget are first in line for the next
+func scheduleTicks(games []*models.Game) []*models.Game {
+	due := make([]*models.Game, 0, len(games))
+	for _, game := range games {
+		if game.ShouldTick() {
+			due = append(due, game)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := due[i].LastTickAt, due[j].LastTickAt
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+	return due
+}
+
+// withinBudget reports whether a game's tick is expected to finish inside
+// the pass that started at passStart. The first game of a pass always runs.
+func (e *GameEngine) withinBudget(game *models.Game, passStart time.Time, first bool) bool {
+	if first {
+		return true
+	}
+	expected := time.Duration(0)
+	if cost := e.tickCosts[game.GameID]; cost != nil {
+		expected = cost.average
+	}
+	return time.Since(passStart)+expected <= pollInterval
+}
+
+// applyFidelity drops an overloaded game to aggregated simulation for this
+// tick when adaptive fidelity is on. The change is never persisted, so the
+// game returns to its chosen fidelity once it recovers.
+func (e *GameEngine) applyFidelity(game *models.Game) {
+	if cost := e.tickCosts[game.GameID]; e.adaptiveFidelity && cost != nil && cost.overloaded {
+		game.SimulationFidelity = models.SimulationFidelityAggregated
+	}
+}
+
+// recordTickCost folds a tick's duration into the game's average, noting
+// when the game starts or stops consistently overrunning its interval
+func (e *GameEngine) recordTickCost(game *models.Game, elapsed time.Duration) {
+	cost := e.tickCosts[game.GameID]
+	if cost == nil {
+		cost = &tickCost{average: elapsed}
+		e.tickCosts[game.GameID] = cost
+	}
+	cost.average += time.Duration(tickCostSmoothing * float64(elapsed-cost.average))
+
+	if elapsed > models.TickInterval {
+		cost.overruns++
+	} else {
+		cost.overruns = 0
+	}
+
+	switch {
+	case !cost.overloaded && cost.overruns >= overloadTicks:
+		cost.overloaded = true
+		log.Printf("Game %s is falling behind: its ticks average %v against a %v interval", game.GameID, cost.average, models.TickInterval)
+	case cost.overloaded && cost.average < time.Duration(recoveryRatio*float64(models.TickInterval)):
+		cost.overloaded = false
+		log.Printf("Game %s is keeping up again: its ticks average %v", game.GameID, cost.average)
+	}
+}
@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// processSettlementGrowth advances every settlement's human simulation by the
+// years the tick spans. The simulator works in days, so each year runs 365
+// daily micro-steps, a single aggregated step or a cohort step depending on
+// game fidelity.
+// The owner's tax rate and corvée take their share of the years' food and
+// work, banked in the settlement as gold and production. Settlements grow
+// in settlement ID order, as tickPhases requires.
+func (e *GameEngine) processSettlementGrowth(ctx context.Context, game *models.Game) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+	policies := e.playerPolicies(ctx, game.GameID)
+
+	for _, settlement := range settlements {
+		sim := e.settlementSimulation(ctx, game, settlement)
+		sim.Conditions.Tax, sim.Conditions.Corvee = settlementLevers(policies[settlement.PlayerID], settlement)
+
+		year := e.advanceSettlementYears(game, settlement, sim, game.YearsPerTick())
+		e.saveSettlementSimulation(ctx, game, settlement.SettlementID, sim)
+
+		population := sim.Population()
+		technologies := sim.Technologies()
+		gold, production := int(year.FoodTaxed/foodPerGold), int(year.CorveeHours/hoursPerProduction)
+		if population == settlement.Population && len(technologies) == len(settlement.Technologies) && year.Deaths == 0 && gold == 0 && production == 0 {
+			continue
+		}
+
+		recordDeaths(settlement, year.Mortality)
+		if year.Crisis != "" {
+			e.recordCrisis(ctx, game, settlement, year)
+		}
+		settlement.Population = population
+		settlement.Technologies = technologies
+		settlement.Gold += gold
+		settlement.Production += production
+		settlement.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating settlement %s: %v", settlement.SettlementID, err)
+		}
+		e.recordFireMastery(ctx, game, settlement)
+	}
+
+	return nil
+}
+
+// settlementSimulation returns the in-memory simulation for a settlement,
+// resuming its saved simulation or creating one sized to the settlement's
+// persisted population if needed. It lives by the game's current rates, so
+// rules tuned on a sandbox game apply from the next tick.
+func (e *GameEngine) settlementSimulation(ctx context.Context, game *models.Game, settlement *models.Settlement) *simulator.Simulation {
+	rates := &game.Ruleset().Settlement
+	if sim, ok := e.settlementSims[settlement.SettlementID]; ok {
+		sim.SetRates(rates)
+		return sim
+	}
+	if sim := e.resumeSettlementSimulation(ctx, game, settlement); sim != nil {
+		sim.SetRates(rates)
+		e.settlementSims[settlement.SettlementID] = sim
+		return sim
+	}
+
+	conditions := simulator.DefaultStartingConditions()
+	if settlement.Population > 0 {
+		conditions.Population = settlement.Population
+	}
+	applyWorkArea(&conditions, e.settlementWorkTiles(ctx, game, settlement))
+
+	seed := rng.DeriveSeed(game.Seeds.Master, "settlement:"+settlement.SettlementID)
+	sim := simulator.NewSimulation(conditions, int(seed&0x7fffffff))
+	sim.SetRates(rates)
+	sim.RestoreTechnologies(settlement.Technologies)
+	e.settlementSims[settlement.SettlementID] = sim
+	return sim
+}
+
+// saveSettlementSimulation persists a settlement's simulation so it survives
+// an engine restart
+func (e *GameEngine) saveSettlementSimulation(ctx context.Context, game *models.Game, settlementID string, sim *simulator.Simulation) {
+	var snapshot bytes.Buffer
+	if err := sim.Snapshot().Encode(&snapshot); err != nil {
+		log.Printf("Error encoding settlement %s simulation: %v", settlementID, err)
+		return
+	}
+	saved := &models.SettlementSimulation{
+		SettlementID: settlementID,
+		GameID:       game.GameID,
+		Year:         game.CurrentYear,
+		Population:   sim.Population(),
+		Snapshot:     snapshot.Bytes(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := e.repo.SaveSettlementSimulation(ctx, saved); err != nil {
+		log.Printf("Error saving settlement %s simulation: %v", settlementID, err)
+	}
+}
+
+// resumeSettlementSimulation restores a settlement's saved simulation, or
+// returns nil if there is none. A saved simulation whose population no
+// longer matches the settlement's, as after a merge, is stale and ignored.
+func (e *GameEngine) resumeSettlementSimulation(ctx context.Context, game *models.Game, settlement *models.Settlement) *simulator.Simulation {
+	saved, err := e.repo.GetSettlementSimulation(ctx, settlement.SettlementID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Error loading settlement %s simulation: %v", settlement.SettlementID, err)
+		}
+		return nil
+	}
+	if saved.Population != settlement.Population {
+		return nil
+	}
+
+	snapshot, err := simulator.DecodeSnapshot(bytes.NewReader(saved.Snapshot))
+	if err != nil {
+		log.Printf("Discarding settlement %s simulation: %v", settlement.SettlementID, err)
+		return nil
+	}
+	sim, err := simulator.Resume(snapshot)
+	if err != nil {
+		log.Printf("Discarding settlement %s simulation: %v", settlement.SettlementID, err)
+		return nil
+	}
+	applyWorkArea(&sim.Conditions, e.settlementWorkTiles(ctx, game, settlement))
+	return sim
+}
+
+// refreshSettlementYields recomputes the terrain multiplier of running
+// settlement simulations whose work area contains one of the changed tiles
+func (e *GameEngine) refreshSettlementYields(ctx context.Context, game *models.Game, changed []*models.MapTile) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+
+	for _, settlement := range settlements {
+		sim, ok := e.settlementSims[settlement.SettlementID]
+		if !ok {
+			continue
+		}
+		radius := settlement.WorkRadius(game.Ruleset())
+		for _, tile := range changed {
+			if abs(tile.X-settlement.Location.X) <= radius && abs(tile.Y-settlement.Location.Y) <= radius {
+				applyWorkArea(&sim.Conditions, e.settlementWorkTiles(ctx, game, settlement))
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyWorkArea sets the conditions a settlement's people live under from
+// the tiles they work
+func applyWorkArea(conditions *simulator.StartingConditions, workTiles []*models.MapTile) {
+	conditions.TerrainMultiplier = terrain.AreaMultiplier(workTiles).Food
+	conditions.ShelterCapacity = terrain.ShelterCapacity(workTiles)
+	conditions.Pollution = terrain.AveragePollution(workTiles)
+	conditions.Livestock = terrain.LivestockTiles(workTiles)
+	conditions.CarryingCapacity = terrain.CarryingCapacity(workTiles)
+}
+
+// settlementWorkTiles returns the tiles worked by a settlement, within its
+// work radius
+func (e *GameEngine) settlementWorkTiles(ctx context.Context, game *models.Game, settlement *models.Settlement) []*models.MapTile {
+	location, radius := settlement.Location, settlement.WorkRadius(game.Ruleset())
+	var tiles []*models.MapTile
+	for _, loc := range grid.Unbounded.Disk(location, radius) {
+		tile, err := e.repo.GetMapTile(ctx, game.GameID, loc.X, loc.Y)
+		if err != nil || tile == nil {
+			continue
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// recordCrisis records the overcrowding crisis that struck a settlement
+// during the year for its player to review
+func (e *GameEngine) recordCrisis(ctx context.Context, game *models.Game, settlement *models.Settlement, year *simulator.DailyMetrics) {
+	detail := fmt.Sprintf("Overcrowding in %s brought %s", settlement.Name, crisisDescriptions[year.Crisis])
+	if year.Migrants > 0 {
+		detail += fmt.Sprintf("; %d people left to seek land elsewhere", year.Migrants)
+	}
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventSettlementCrisis,
+		PlayerID:  settlement.PlayerID,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording crisis in settlement %s: %v", settlement.SettlementID, err)
+	}
+}
+
+// crisisDescriptions describes each kind of overcrowding crisis
+var crisisDescriptions = map[string]string{
+	simulator.CrisisEpidemic:  "an epidemic",
+	simulator.CrisisConflict:  "fighting over land and food",
+	simulator.CrisisMigration: "an exodus",
+}
@@ -3,25 +3,46 @@ package engine
 import (
 	"context"
 	"log"
-	"math/rand"
+	"sort"
 	"time"
 
+	"github.com/anicolao/simciv/simulation/pkg/grid"
 	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
 )
 
-// processSettlersUnits processes all settlers units in the game
+// processSettlersUnits executes pending settle orders, then processes all
+// remaining settlers units in the game in unit ID order. Settlers a move
+// order sent somewhere are left to travel.
 func (e *GameEngine) processSettlersUnits(ctx context.Context, game *models.Game) error {
+	if err := e.processOrders(ctx, game); err != nil {
+		log.Printf("Error processing orders for game %s: %v", game.GameID, err)
+	}
+
 	units, err := e.repo.GetUnits(ctx, game.GameID)
 	if err != nil {
 		return err
 	}
+	sort.Slice(units, func(i, j int) bool { return units[i].UnitID < units[j].UnitID })
 
+	var settlers []*models.Unit
 	for _, unit := range units {
-		if unit.UnitType == "settlers" {
-			if err := e.processSettlersUnit(ctx, game, unit); err != nil {
-				log.Printf("Error processing settlers unit %s: %v", unit.UnitID, err)
-				// Continue with other units
-			}
+		if unit.UnitType == "settlers" && unit.Destination == nil {
+			settlers = append(settlers, unit)
+		}
+	}
+	if len(settlers) == 0 {
+		return nil
+	}
+	tileAt, err := e.mapTileLookup(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+
+	for _, unit := range settlers {
+		if err := e.processSettlersUnit(ctx, game, unit, tileAt); err != nil {
+			log.Printf("Error processing settlers unit %s: %v", unit.UnitID, err)
+			// Continue with other units
 		}
 	}
 
@@ -29,22 +50,53 @@ func (e *GameEngine) processSettlersUnits(ctx context.Context, game *models.Game
 }
 
 // processSettlersUnit processes a single settlers unit
-func (e *GameEngine) processSettlersUnit(ctx context.Context, game *models.Game, unit *models.Unit) error {
-	// If unit has taken fewer than 3 steps, take another step
-	if unit.StepsTaken < 3 {
-		return e.moveUnit(ctx, game, unit)
+func (e *GameEngine) processSettlersUnit(ctx context.Context, game *models.Game, unit *models.Unit, tileAt func(models.Location) *models.MapTile) error {
+	// In player start mode the unit waits for a settle order until the
+	// deadline, unless the governor is playing for an absent player or the
+	// player ends their turns automatically
+	if game.PlayerDirectedStart() {
+		governed := e.Governed(game.GameID, unit.PlayerID)
+		waiting := time.Now().Before(game.SettleDeadline()) && !governed
+		if waiting && !e.autoEndsTurn(ctx, game.GameID, unit.PlayerID) {
+			return nil
+		}
+		switch {
+		case governed:
+			log.Printf("Governor settling unit %s for absent player %s", unit.UnitID, unit.PlayerID)
+		case waiting:
+			log.Printf("Player %s ends turns automatically, settling unit %s", unit.PlayerID, unit.UnitID)
+		default:
+			log.Printf("Settle deadline passed for unit %s, auto-settling", unit.UnitID)
+		}
+		if !e.moveToSite(ctx, game, unit) {
+			return nil
+		}
+		return e.settleAtLocation(ctx, game, unit)
 	}
 
-	// If unit has taken 3 steps, settle at current location
-	if unit.StepsTaken == 3 {
+	// If unit has taken fewer than the ruleset's steps, walk on
+	walkSteps := game.Ruleset().SettlersWalkSteps
+	if unit.StepsTaken < walkSteps {
+		return e.moveUnit(ctx, game, unit, walkSteps, tileAt)
+	}
+
+	// Once the walk is done, settle at the best site near where it ended up
+	if unit.StepsTaken == walkSteps {
+		if !e.moveToSite(ctx, game, unit) {
+			return nil
+		}
 		return e.settleAtLocation(ctx, game, unit)
 	}
 
 	return nil
 }
 
-// moveUnit moves a unit in a random direction
-func (e *GameEngine) moveUnit(ctx context.Context, game *models.Game, unit *models.Unit) error {
+// moveUnit walks a unit in random directions, one step at a time, until it
+// has taken walkSteps steps or its movement for the years the tick spans
+// runs out. Each step spends its terrain.MoveCost; movement short of the
+// next step is saved on the unit for the next tick. A step off the map or
+// onto water is refused but counts toward the walk.
+func (e *GameEngine) moveUnit(ctx context.Context, game *models.Game, unit *models.Unit, walkSteps int, tileAt func(models.Location) *models.MapTile) error {
 	// Get map metadata to know bounds
 	metadata, err := e.repo.GetMapMetadata(ctx, game.GameID)
 	if err != nil {
@@ -56,43 +108,62 @@ func (e *GameEngine) moveUnit(ctx context.Context, game *models.Game, unit *mode
 		dx int
 		dy int
 	}{
-		{dx: 0, dy: -1},  // North
-		{dx: 0, dy: 1},   // South
-		{dx: 1, dy: 0},   // East
-		{dx: -1, dy: 0},  // West
+		{dx: 0, dy: -1}, // North
+		{dx: 0, dy: 1},  // South
+		{dx: 1, dy: 0},  // East
+		{dx: -1, dy: 0}, // West
 	}
 
-	// Use time-based seed for randomness
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	direction := directions[r.Intn(len(directions))]
-
-	// Move unit
-	newX := unit.Location.X + direction.dx
-	newY := unit.Location.Y + direction.dy
-
-	// Ensure within map bounds
-	if newX < 0 {
-		newX = 0
-	}
-	if newX >= metadata.Width {
-		newX = metadata.Width - 1
-	}
-	if newY < 0 {
-		newY = 0
+	path := []models.Location{unit.Location}
+	budget := movementPoints(unit)*float64(game.YearsPerTick()) + unit.MovesLeft
+	for unit.StepsTaken < walkSteps && budget > moveSlack {
+		// Draw from the game's movement stream so walks are reproducible
+		direction := directions[game.Seeds.Movement.Intn(len(directions))]
+		next := models.Location{X: unit.Location.X + direction.dx, Y: unit.Location.Y + direction.dy}
+		tile := tileAt(next)
+		if next.X < 0 || next.X >= metadata.Width || next.Y < 0 || next.Y >= metadata.Height || (tile != nil && terrain.IsWater(tile.TerrainType)) {
+			unit.StepsTaken++
+			continue
+		}
+		cost := terrain.MoveCost(tileAt(unit.Location), tile)
+		if cost > budget+moveSlack {
+			break
+		}
+		budget -= cost
+		unit.Location = next
+		unit.StepsTaken++
+		path = append(path, next)
 	}
-	if newY >= metadata.Height {
-		newY = metadata.Height - 1
+	unit.MovesLeft = 0
+	if unit.StepsTaken < walkSteps {
+		unit.MovesLeft = max(budget, 0)
 	}
-
-	// Update unit location and stepsTaken
-	unit.Location.X = newX
-	unit.Location.Y = newY
-	unit.StepsTaken++
 	unit.LastUpdated = time.Now()
 
-	log.Printf("Unit %s moved to (%d, %d), steps taken: %d", unit.UnitID, newX, newY, unit.StepsTaken)
+	log.Printf("Unit %s moved to (%d, %d), steps taken: %d", unit.UnitID, unit.Location.X, unit.Location.Y, unit.StepsTaken)
 
-	return e.repo.UpdateUnit(ctx, unit)
+	if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+		return err
+	}
+	e.recordMovement(ctx, game, unit, path)
+	return nil
+}
+
+// moveToSite sends a settlers unit that is about to settle to the best site
+// near it, reporting whether it already stands there. A unit sent on
+// travels there in the movement phase like one ordered to move, and settles
+// once it arrives.
+func (e *GameEngine) moveToSite(ctx context.Context, game *models.Game, unit *models.Unit) bool {
+	site := e.bestNearbySite(ctx, game, unit.Location)
+	if site == unit.Location {
+		return true
+	}
+	unit.Destination = &site
+	unit.LastUpdated = time.Now()
+	if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+		log.Printf("Error sending settlers %s to (%d, %d): %v", unit.UnitID, site.X, site.Y, err)
+	}
+	return false
 }
 
 // settleAtLocation creates a settlement at the unit's location
@@ -111,7 +182,7 @@ func (e *GameEngine) settleAtLocation(ctx context.Context, game *models.Game, un
 	}
 
 	// If tile is ocean or shallow water, find nearest land tile
-	if tile.TerrainType == "OCEAN" || tile.TerrainType == "SHALLOW_WATER" {
+	if terrain.IsWater(tile.TerrainType) {
 		log.Printf("Tile at (%d, %d) is water, finding adjacent land tile", location.X, location.Y)
 		tile, location, err = e.findValidAdjacentTile(ctx, game.GameID, location)
 		if err != nil {
@@ -126,8 +197,9 @@ func (e *GameEngine) settleAtLocation(ctx context.Context, game *models.Game, un
 		GameID:       game.GameID,
 		PlayerID:     unit.PlayerID,
 		Name:         "First Settlement",
-		Type:         "nomadic_camp",
+		Type:         models.SettlementTypeNomadicCamp,
 		Location:     location,
+		Population:   unit.PopulationCost,
 		Founded:      time.Now(),
 		LastUpdated:  time.Now(),
 	}
@@ -138,6 +210,12 @@ func (e *GameEngine) settleAtLocation(ctx context.Context, game *models.Game, un
 
 	log.Printf("Settlement %s created at (%d, %d) for player %s", settlement.SettlementID, location.X, location.Y, unit.PlayerID)
 
+	// Claim the settlement's work area
+	if err := e.repo.AssignTiles(ctx, game.GameID, settlement.SettlementID, unit.PlayerID, workAreaLocations(location, settlement.WorkRadius(game.Ruleset())), game.CurrentYear); err != nil {
+		log.Printf("Error assigning tiles to settlement %s: %v", settlement.SettlementID, err)
+	}
+	e.invalidatePaths(game.GameID)
+
 	// Remove settlers unit
 	if err := e.repo.DeleteUnit(ctx, unit.UnitID); err != nil {
 		return err
@@ -148,34 +226,68 @@ func (e *GameEngine) settleAtLocation(ctx context.Context, game *models.Game, un
 	return nil
 }
 
+// bestNearbySite returns the land tile within the auto-settle radius whose work area
+// scores best under the shared site scoring used for starting positions. The
+// site's own tile is counted again so a settlement is not founded on tundra
+// next to grassland. Ties go to the closest tile; the original location is
+// returned if no land tile is found.
+func (e *GameEngine) bestNearbySite(ctx context.Context, game *models.Game, location models.Location) models.Location {
+	rules := game.Ruleset()
+	autoSettleRadius, workRadius := rules.AutoSettleRadius, rules.SettlementWorkRadius
+	reach := autoSettleRadius + workRadius
+	tiles := make(map[models.Location]*models.MapTile)
+	for _, loc := range grid.Unbounded.Disk(location, reach) {
+		tile, err := e.repo.GetMapTile(ctx, game.GameID, loc.X, loc.Y)
+		if err == nil && tile != nil {
+			tiles[models.Location{X: tile.X, Y: tile.Y}] = tile
+		}
+	}
+
+	best := location
+	bestScore := 0.0
+	found := false
+	for _, loc := range grid.Unbounded.Disk(location, autoSettleRadius) {
+		center, ok := tiles[loc]
+		if !ok || terrain.IsWater(center.TerrainType) {
+			continue
+		}
+
+		region := []*models.MapTile{center}
+		for _, worked := range grid.Unbounded.Disk(loc, workRadius) {
+			if tile, ok := tiles[worked]; ok {
+				region = append(region, tile)
+			}
+		}
+		score := terrain.ScoreSite(region).Score
+
+		closer := abs(loc.X-location.X)+abs(loc.Y-location.Y) < abs(best.X-location.X)+abs(best.Y-location.Y)
+		if !found || score > bestScore || (score == bestScore && closer) {
+			best = loc
+			bestScore = score
+			found = true
+		}
+	}
+	return best
+}
+
 // findValidAdjacentTile finds a valid adjacent land tile
 func (e *GameEngine) findValidAdjacentTile(ctx context.Context, gameID string, location models.Location) (*models.MapTile, models.Location, error) {
-	// Try adjacent tiles (spiral search)
-	adjacentOffsets := []struct {
-		dx int
-		dy int
-	}{
-		{dx: 1, dy: 0},
-		{dx: -1, dy: 0},
-		{dx: 0, dy: 1},
-		{dx: 0, dy: -1},
-		{dx: 1, dy: 1},
-		{dx: 1, dy: -1},
-		{dx: -1, dy: 1},
-		{dx: -1, dy: -1},
+	// Try the orthogonal neighbours first, then the diagonal ones
+	candidates := grid.Unbounded.Neighbors4(location)
+	for _, loc := range grid.Unbounded.Neighbors8(location) {
+		if loc.X != location.X && loc.Y != location.Y {
+			candidates = append(candidates, loc)
+		}
 	}
 
-	for _, offset := range adjacentOffsets {
-		adjX := location.X + offset.dx
-		adjY := location.Y + offset.dy
-
-		tile, err := e.repo.GetMapTile(ctx, gameID, adjX, adjY)
+	for _, adjacent := range candidates {
+		tile, err := e.repo.GetMapTile(ctx, gameID, adjacent.X, adjacent.Y)
 		if err != nil {
 			continue
 		}
 
-		if tile != nil && tile.TerrainType != "OCEAN" && tile.TerrainType != "SHALLOW_WATER" {
-			return tile, models.Location{X: adjX, Y: adjY}, nil
+		if tile != nil && !terrain.IsWater(tile.TerrainType) {
+			return tile, adjacent, nil
 		}
 	}
 
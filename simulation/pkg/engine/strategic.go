@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// ErrResourceUnavailable is returned when a player lacks a strategic resource
+var ErrResourceUnavailable = errors.New("strategic resource unavailable")
+
+// BuildRequirements lists the strategic resources each unit or building needs
+var BuildRequirements = map[string][]string{
+	"spearmen":     {"COPPER"},
+	"swordsmen":    {"IRON"},
+	"bronze_works": {"COPPER"},
+	"forge":        {"IRON", "COAL"},
+	"treasury":     {"GOLD"},
+}
+
+// ResourceAccess counts a player's connected sources of each strategic resource
+type ResourceAccess map[string]int
+
+// CheckRequirements verifies that access covers everything item needs
+func CheckRequirements(item string, access ResourceAccess) error {
+	for _, resource := range BuildRequirements[item] {
+		if access[resource] <= 0 {
+			return fmt.Errorf("%w: %s requires %s", ErrResourceUnavailable, item, resource)
+		}
+	}
+	return nil
+}
+
+// CanBuild checks a player's strategic resource access, as of the last
+// tick, against the requirements of a unit or building
+func (e *GameEngine) CanBuild(gameID, playerID, item string) error {
+	return CheckRequirements(item, e.ResourceAccess(gameID, playerID))
+}
+
+// ResourceAccess returns a player's strategic resource access as of the last tick
+func (e *GameEngine) ResourceAccess(gameID, playerID string) ResourceAccess {
+	e.accessMu.RLock()
+	defer e.accessMu.RUnlock()
+	return e.resourceAccess[gameID][playerID]
+}
+
+// processResourceAccess recomputes every player's strategic resource access
+func (e *GameEngine) processResourceAccess(ctx context.Context, game *models.Game) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+
+	var access map[string]ResourceAccess
+	if len(settlements) > 0 {
+		roads, err := e.repo.GetTilesWithImprovement(ctx, game.GameID, models.ImprovementRoad)
+		if err != nil {
+			return err
+		}
+		rivers, err := e.repo.GetRiverTiles(ctx, game.GameID)
+		if err != nil {
+			return err
+		}
+		resourceTiles, err := e.repo.GetResourceTiles(ctx, game.GameID)
+		if err != nil {
+			return err
+		}
+		access = computeResourceAccess(settlements, append(roads, rivers...), resourceTiles, game.Ruleset())
+	}
+
+	e.accessMu.Lock()
+	e.resourceAccess[game.GameID] = access
+	e.accessMu.Unlock()
+	return nil
+}
+
+// computeResourceAccess counts, per player, the strategic resource tiles in
+// the player's territory that are connected to one of their settlements.
+// A settlement's work area is always connected; beyond it, a resource must sit
+// on a trade route reachable from the settlement through route tiles (roads
+// and navigable rivers) that no other player owns.
+func computeResourceAccess(settlements []*models.Settlement, routes []*models.MapTile, resourceTiles []*models.MapTile, rules *models.Ruleset) map[string]ResourceAccess {
+	roadAt := make(map[models.Location]*models.MapTile, len(routes))
+	for _, road := range routes {
+		roadAt[models.Location{X: road.X, Y: road.Y}] = road
+	}
+
+	byPlayer := make(map[string][]*models.Settlement)
+	for _, settlement := range settlements {
+		byPlayer[settlement.PlayerID] = append(byPlayer[settlement.PlayerID], settlement)
+	}
+
+	access := make(map[string]ResourceAccess, len(byPlayer))
+	for playerID, owned := range byPlayer {
+		workArea := make(map[models.Location]bool)
+		connected := make(map[models.Location]bool)
+		var frontier []models.Location
+		for _, settlement := range owned {
+			for _, loc := range grid.Unbounded.Disk(settlement.Location, settlement.WorkRadius(rules)) {
+				workArea[loc] = true
+				if !connected[loc] {
+					connected[loc] = true
+					frontier = append(frontier, loc)
+				}
+			}
+		}
+
+		// Flood outward along routes not owned by another player
+		for len(frontier) > 0 {
+			loc := frontier[len(frontier)-1]
+			frontier = frontier[:len(frontier)-1]
+			for _, next := range grid.Unbounded.Neighbors8(loc) {
+				road, ok := roadAt[next]
+				if !ok || connected[next] || (road.OwnerID != nil && *road.OwnerID != playerID) {
+					continue
+				}
+				connected[next] = true
+				frontier = append(frontier, next)
+			}
+		}
+
+		playerAccess := make(ResourceAccess)
+		for _, tile := range resourceTiles {
+			loc := models.Location{X: tile.X, Y: tile.Y}
+			if tile.OwnerID != nil && *tile.OwnerID != playerID {
+				continue
+			}
+			inTerritory := workArea[loc] || tile.OwnerID != nil
+			if !connected[loc] || !inTerritory {
+				continue
+			}
+			for _, resource := range terrain.ActiveResources(tile) {
+				if terrain.IsStrategic(resource) {
+					playerAccess[resource]++
+				}
+			}
+		}
+		access[playerID] = playerAccess
+	}
+
+	return access
+}
@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// historyInterval is how many game years pass between samples of the
+// players' standings
+const historyInterval = 10
+
+// timelineEvents are the event types a game's summary keeps in its timeline
+var timelineEvents = map[string]bool{
+	models.EventGameStarted:       true,
+	models.EventPlayerEliminated:  true,
+	models.EventPlayerSurrendered: true,
+	models.EventPlayerReleased:    true,
+	models.EventVictory:           true,
+	models.EventAgreementMade:     true,
+	models.EventAgreementBroken:   true,
+	models.EventSettlementGrew:    true,
+	models.EventMinorCivAllied:    true,
+	models.EventSettlementTaken:   true,
+	models.EventSettlementCrisis:  true,
+	models.EventRevolt:            true,
+}
+
+// processHistory records every player's standing every historyInterval
+// years, for the graphs of the game's summary
+func (e *GameEngine) processHistory(ctx context.Context, game *models.Game) error {
+	if len(game.TickYearsEvery(historyInterval)) == 0 || len(game.PlayerList) == 0 {
+		return nil
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	standings, err := e.playerStandings(ctx, game, settlements)
+	if err != nil {
+		return err
+	}
+	return e.repo.SaveGameHistory(ctx, &models.GameHistoryPoint{GameID: game.GameID, Year: game.CurrentYear, Players: standings})
+}
+
+// playerStandings measures each player's civilization as it stands, in the
+// order of the game's player list. Science is read from the settlements'
+// running simulations, which the growth phase has loaded by then.
+func (e *GameEngine) playerStandings(ctx context.Context, game *models.Game, settlements []*models.Settlement) ([]models.PlayerStanding, error) {
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return nil, err
+	}
+	standings := make([]models.PlayerStanding, len(game.PlayerList))
+	index := make(map[string]int, len(game.PlayerList))
+	for i, playerID := range game.PlayerList {
+		standings[i].PlayerID = playerID
+		index[playerID] = i
+	}
+	for _, settlement := range settlements {
+		i, ok := index[settlement.PlayerID]
+		if !ok {
+			continue
+		}
+		standings[i].Population += settlement.Population
+		standings[i].Settlements++
+		if sim, ok := e.settlementSims[settlement.SettlementID]; ok {
+			standings[i].Science += sim.State.SciencePoints
+		}
+	}
+	for _, tile := range tiles {
+		if tile.OwnerID == nil {
+			continue
+		}
+		if i, ok := index[*tile.OwnerID]; ok {
+			standings[i].Territory++
+		}
+	}
+	for i := range standings {
+		standings[i].Science = math.Round(standings[i].Science)
+	}
+	return standings, nil
+}
+
+// recordGameSummary writes the report of a finished game: its final
+// standings, the key events along the way, the standings sampled over the
+// years for graphs, and the records the players set
+func (e *GameEngine) recordGameSummary(ctx context.Context, game *models.Game, victoryType string) {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		log.Printf("Error summarizing game %s: %v", game.GameID, err)
+		return
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+	standings, err := e.playerStandings(ctx, game, settlements)
+	if err != nil {
+		log.Printf("Error summarizing game %s: %v", game.GameID, err)
+		return
+	}
+	final := &models.GameHistoryPoint{GameID: game.GameID, Year: game.CurrentYear, Players: standings}
+	if err := e.repo.SaveGameHistory(ctx, final); err != nil {
+		log.Printf("Error recording the final standings of game %s: %v", game.GameID, err)
+	}
+	history, err := e.repo.GetGameHistory(ctx, game.GameID)
+	if err != nil || len(history) == 0 {
+		history = []*models.GameHistoryPoint{final}
+	}
+	events, err := e.repo.GetEvents(ctx, game.GameID)
+	if err != nil {
+		log.Printf("Error reading the events of game %s: %v", game.GameID, err)
+	}
+
+	summary := &models.GameSummary{
+		GameID:       game.GameID,
+		StartYear:    gameStartYear,
+		EndYear:      game.CurrentYear,
+		Winners:      game.Winners,
+		VictoryType:  victoryType,
+		Standings:    rankStandings(standings),
+		History:      history,
+		Superlatives: superlatives(game, settlements, history),
+		CreatedAt:    time.Now(),
+	}
+	for _, event := range events {
+		if timelineEvents[event.Type] {
+			summary.Timeline = append(summary.Timeline, event)
+		}
+	}
+	if err := e.repo.SaveGameSummary(ctx, summary); err != nil {
+		log.Printf("Error saving the summary of game %s: %v", game.GameID, err)
+	}
+}
+
+// rankStandings returns the standings ordered from the most populous
+// civilization down, the larger territory first among equals
+func rankStandings(standings []models.PlayerStanding) []models.PlayerStanding {
+	ranked := append([]models.PlayerStanding(nil), standings...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Population != ranked[j].Population {
+			return ranked[i].Population > ranked[j].Population
+		}
+		return ranked[i].Territory > ranked[j].Territory
+	})
+	return ranked
+}
+
+// superlatives returns the records the players set over a game. Peaks are
+// read from its history, and the first to reach one holds it.
+func superlatives(game *models.Game, settlements []*models.Settlement, history []*models.GameHistoryPoint) []models.Superlative {
+	var records []models.Superlative
+
+	var largest *models.Settlement
+	for _, settlement := range settlements {
+		if containsString(game.PlayerList, settlement.PlayerID) && (largest == nil || settlement.Population > largest.Population) {
+			largest = settlement
+		}
+	}
+	if largest != nil && largest.Population > 0 {
+		records = append(records, models.Superlative{
+			Title:        "Largest city",
+			PlayerID:     largest.PlayerID,
+			SettlementID: largest.SettlementID,
+			Detail:       fmt.Sprintf("%s, %d people", largest.Name, largest.Population),
+			Value:        float64(largest.Population),
+			Year:         game.CurrentYear,
+		})
+	}
+
+	peaks := []struct {
+		title   string
+		unit    string
+		measure func(models.PlayerStanding) float64
+	}{
+		{"Most populous civilization", "people", func(s models.PlayerStanding) float64 { return float64(s.Population) }},
+		{"Greatest territory", "tiles", func(s models.PlayerStanding) float64 { return float64(s.Territory) }},
+		{"Most settlements", "settlements", func(s models.PlayerStanding) float64 { return float64(s.Settlements) }},
+		{"Most learned", "science", func(s models.PlayerStanding) float64 { return s.Science }},
+	}
+	for _, peak := range peaks {
+		var record *models.Superlative
+		for _, point := range history {
+			for _, standing := range point.Players {
+				if value := peak.measure(standing); value > 0 && (record == nil || value > record.Value) {
+					record = &models.Superlative{Title: peak.title, PlayerID: standing.PlayerID, Value: value, Year: point.Year}
+				}
+			}
+		}
+		if record != nil {
+			record.Detail = fmt.Sprintf("%.0f %s", record.Value, peak.unit)
+			records = append(records, *record)
+		}
+	}
+
+	first := ""
+	for _, playerID := range game.PlayerList {
+		if year, ok := game.FireMastery[playerID]; ok && (first == "" || year < game.FireMastery[first]) {
+			first = playerID
+		}
+	}
+	if first != "" {
+		years := game.FireMastery[first] - gameStartYear + 1
+		records = append(records, models.Superlative{
+			Title:    "First to master fire",
+			PlayerID: first,
+			Detail:   fmt.Sprintf("%d years", years),
+			Value:    float64(years),
+			Year:     game.FireMastery[first],
+		})
+	}
+	return records
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+	"github.com/anicolao/simciv/simulation/pkg/yields"
+)
+
+// TileYieldsPreview is what a tile yields with the given improvements to a
+// settlement knowing the given technologies
+type TileYieldsPreview struct {
+	X            int      `json:"x"`
+	Y            int      `json:"y"`
+	Improvements []string `json:"improvements"`
+	Technologies []string `json:"technologies"`
+	yields.TileYields
+}
+
+// PreviewTileYields returns what the tile at (x, y) yields. Nil
+// improvements preview the tile as it stands; nil techs use those of the
+// settlement working the tile, or else everything the player's settlements
+// know. Nothing is changed.
+func (e *GameEngine) PreviewTileYields(ctx context.Context, gameID string, x, y int, playerID string, improvements, techs []string) (*TileYieldsPreview, error) {
+	tile, err := e.repo.GetMapTile(ctx, gameID, x, y)
+	if err != nil {
+		return nil, err
+	}
+	if tile == nil {
+		return nil, fmt.Errorf("%w: tile (%d, %d)", repository.ErrNotFound, x, y)
+	}
+
+	if improvements == nil {
+		improvements = tile.Improvements
+	}
+	if techs == nil {
+		settlements, err := e.repo.GetSettlements(ctx, gameID)
+		if err != nil {
+			return nil, err
+		}
+		techs = previewTechnologies(tile, playerID, settlements)
+	}
+
+	return &TileYieldsPreview{
+		X:            x,
+		Y:            y,
+		Improvements: improvements,
+		Technologies: techs,
+		TileYields:   yields.ComputeTileYields(tile, improvements, techs),
+	}, nil
+}
+
+// previewTechnologies returns the technologies a tile is worked with: those
+// of the settlement working it, or else all the player's settlements know
+func previewTechnologies(tile *models.MapTile, playerID string, settlements []*models.Settlement) []string {
+	techs := []string{}
+	for _, settlement := range settlements {
+		if tile.SettlementID != "" && settlement.SettlementID == tile.SettlementID {
+			return append(techs, settlement.Technologies...)
+		}
+	}
+	if playerID == "" {
+		return techs
+	}
+	for _, settlement := range settlements {
+		if settlement.PlayerID != playerID {
+			continue
+		}
+		for _, tech := range settlement.Technologies {
+			if !containsString(techs, tech) {
+				techs = append(techs, tech)
+			}
+		}
+	}
+	return techs
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// processTrade sets each settlement's trade bonus for the year: river trade
+// with the settlements on its river systems, plus the sea routes its harbor
+// runs that pirates did not raid
+func (e *GameEngine) processTrade(ctx context.Context, game *models.Game) error {
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+
+	river := e.riverPartners(ctx, game, settlements)
+	sea, err := e.seaRoutes(ctx, game, settlements)
+	if err != nil {
+		return err
+	}
+
+	rules := game.Ruleset()
+	for _, settlement := range settlements {
+		trade := terrain.RiverTrade(rules, river[settlement.SettlementID])
+		routes := sea[settlement.SettlementID]
+		for _, route := range routes {
+			if !route.Raided {
+				trade += terrain.SeaTrade(rules, route.Distance)
+			}
+		}
+		sim := e.settlementSimulation(ctx, game, settlement)
+		sim.Conditions.Trade = trade
+
+		if sameRoutes(settlement.SeaRoutes, routes) {
+			continue
+		}
+		settlement.SeaRoutes = routes
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating sea routes of settlement %s: %v", settlement.SettlementID, err)
+		}
+	}
+	return nil
+}
+
+// seaRoutes finds the sea trade routes each harbor runs this year: its
+// nearest MaxSeaRoutes harbors within SeaTradeRange tiles of water, found by
+// a breadth-first search from the water in its work area. Hostile galleys
+// near a route may raid it.
+func (e *GameEngine) seaRoutes(ctx context.Context, game *models.Game, settlements []*models.Settlement) (map[string][]models.SeaRoute, error) {
+	var harbors []*models.Settlement
+	for _, settlement := range settlements {
+		if containsString(settlement.Buildings, models.BuildingHarbor) {
+			harbors = append(harbors, settlement)
+		}
+	}
+	if len(harbors) < 2 {
+		return nil, nil
+	}
+
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return nil, err
+	}
+	water := make(map[models.Location]bool)
+	for _, tile := range tiles {
+		if terrain.IsWater(tile.TerrainType) {
+			water[models.Location{X: tile.X, Y: tile.Y}] = true
+		}
+	}
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return nil, err
+	}
+	var galleys []*models.Unit
+	for _, unit := range units {
+		if unit.UnitType == models.UnitTypeGalley {
+			galleys = append(galleys, unit)
+		}
+	}
+
+	// The water in a harbor's work area is its port
+	rules := game.Ruleset()
+	portsAt := make(map[models.Location][]*models.Settlement)
+	ports := make(map[string][]models.Location, len(harbors))
+	for _, harbor := range harbors {
+		for _, loc := range grid.Unbounded.Disk(harbor.Location, harbor.WorkRadius(rules)) {
+			if water[loc] {
+				portsAt[loc] = append(portsAt[loc], harbor)
+				ports[harbor.SettlementID] = append(ports[harbor.SettlementID], loc)
+			}
+		}
+	}
+
+	stream := rng.NewStream(rng.DeriveSeed(game.Seeds.Master, fmt.Sprintf("piracy:%d", game.CurrentYear)))
+	routes := make(map[string][]models.SeaRoute, len(harbors))
+	for _, harbor := range harbors {
+		previous := make(map[models.Location]models.Location)
+		distance := make(map[models.Location]int)
+		queue := append([]models.Location(nil), ports[harbor.SettlementID]...)
+		for _, loc := range queue {
+			distance[loc] = 0
+			previous[loc] = loc
+		}
+		found := make(map[string]bool)
+		for len(queue) > 0 && len(routes[harbor.SettlementID]) < terrain.MaxSeaRoutes {
+			loc := queue[0]
+			queue = queue[1:]
+
+			for _, partner := range portsAt[loc] {
+				if partner.SettlementID == harbor.SettlementID || found[partner.SettlementID] || len(routes[harbor.SettlementID]) >= terrain.MaxSeaRoutes {
+					continue
+				}
+				found[partner.SettlementID] = true
+				threats := 0
+				for _, galley := range galleys {
+					if !game.Allied(galley.PlayerID, harbor.PlayerID) && nearPath(galley.Location, loc, previous) {
+						threats++
+					}
+				}
+				route := models.SeaRoute{PartnerID: partner.SettlementID, Distance: distance[loc]}
+				if threats > 0 && stream.Float64() < terrain.RaidChance(threats) {
+					route.Raided = true
+					log.Printf("Game %s: pirates raided the sea route from %s to %s", game.GameID, harbor.Name, partner.Name)
+				}
+				routes[harbor.SettlementID] = append(routes[harbor.SettlementID], route)
+			}
+
+			if distance[loc] >= terrain.SeaTradeRange {
+				continue
+			}
+			for _, next := range grid.Unbounded.Neighbors8(loc) {
+				if _, seen := distance[next]; seen || !water[next] {
+					continue
+				}
+				distance[next] = distance[loc] + 1
+				previous[next] = loc
+				queue = append(queue, next)
+			}
+		}
+	}
+	return routes, nil
+}
+
+// nearPath reports whether a location lies within PiracyRange of the sea path
+// that ends at end, traced back through previous to its starting port
+func nearPath(at, end models.Location, previous map[models.Location]models.Location) bool {
+	for loc := end; ; loc = previous[loc] {
+		if abs(at.X-loc.X) <= terrain.PiracyRange && abs(at.Y-loc.Y) <= terrain.PiracyRange {
+			return true
+		}
+		if previous[loc] == loc {
+			return false
+		}
+	}
+}
+
+// sameRoutes reports whether two sets of sea routes are identical
+func sameRoutes(a, b []models.SeaRoute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+const (
+	garrisonDefense = 0.25 // Defense bonus a fully healthy garrisoned unit lends its settlement
+	maxGarrison     = 1.0  // Ceiling on the garrison defense bonus
+)
+
+// processUnitMaintenance is the unit-maintenance phase of the tick: damaged
+// units heal inside friendly territory, faster when garrisoned in a friendly
+// settlement, and each settlement's defense is recomputed from the health of
+// the units garrisoned in it. Overlords garrison their vassals' settlements.
+// A great general's inspiration wears off a year at a time. Healing and
+// inspiration run for every year the tick spans.
+func (e *GameEngine) processUnitMaintenance(ctx context.Context, game *models.Game) error {
+	years := game.YearsPerTick()
+	rules := game.Ruleset()
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	overlords, err := e.overlords(ctx, game)
+	if err != nil {
+		return err
+	}
+	settlementAt := make(map[models.Location]*models.Settlement, len(settlements))
+	for _, settlement := range settlements {
+		settlementAt[settlement.Location] = settlement
+	}
+
+	garrisons := make(map[string]float64)
+	for _, unit := range units {
+		settlement := settlementAt[unit.Location]
+		garrisoned := settlement != nil && defends(game, overlords, unit.PlayerID, settlement.PlayerID)
+
+		if unit.Aura > 0 {
+			unit.Aura = max(unit.Aura-years, 0)
+			if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+				log.Printf("Error updating inspiration of unit %s in game %s: %v", unit.UnitID, game.GameID, err)
+			}
+		}
+
+		if unit.Damage > 0 {
+			rate := 0.0
+			if garrisoned {
+				rate = rules.GarrisonHealRate
+			} else if tile, err := e.repo.GetMapTile(ctx, game.GameID, unit.Location.X, unit.Location.Y); err == nil && tile != nil &&
+				tile.OwnerID != nil && game.Allied(unit.PlayerID, *tile.OwnerID) {
+				rate = rules.TerritoryHealRate
+			}
+			if heal := int(math.Round(rate*models.MaxUnitHealth)) * years; heal > 0 {
+				unit.Damage = max(unit.Damage-heal, 0)
+				if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+					log.Printf("Error healing unit %s in game %s: %v", unit.UnitID, game.GameID, err)
+				}
+			}
+		}
+
+		if garrisoned {
+			garrisons[settlement.SettlementID] += garrisonDefense * float64(unit.Health()) / models.MaxUnitHealth
+		}
+	}
+
+	for _, settlement := range settlements {
+		garrison := math.Round(min(garrisons[settlement.SettlementID], maxGarrison)*100) / 100
+		if garrison == settlement.Garrison {
+			continue
+		}
+		settlement.Garrison = garrison
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating garrison of settlement %s in game %s: %v", settlement.SettlementID, game.GameID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+const (
+	unrestPopulation     = 1000 // Population at which the crowding of a town starts to breed unrest
+	unrestPopulationSpan = 2000 // Further people over which crowding reaches its full weight
+	unrestBase           = 0.2  // Pressure a fully crowded settlement feels even when content
+	unrestPerUnhappiness = 0.8  // Pressure a fully crowded, fully unhappy settlement adds
+	garrisonOrder        = 1.0  // Pressure each point of garrison holds down
+	unrestSkim           = 0.5  // Share of food a settlement in full unrest loses
+	revoltUnrest         = 0.6  // Unrest from which a settlement may revolt
+	rebelsPerPopulation  = 1000 // People behind each band of rebels a revolt raises
+	maxRebels            = 3    // Most bands of rebels a revolt raises
+	rebelUnrest          = 0.1  // Pressure each band of rebels at large adds to its settlement
+	rebelSuppression     = 100  // Damage a garrison of 1 deals its settlement's rebels each year
+	calmUnrest           = 0.2  // Unrest below which rebels lay down their arms
+)
+
+// unrestRelief is how much each building keeps order in its settlement
+var unrestRelief = map[string]float64{
+	models.BuildingPalisade: 0.2,
+	models.BuildingLibrary:  0.1,
+}
+
+// processUnrest updates each settlement's unrest, which grows with its size
+// and unhappiness and is held down by its garrison and buildings. Unrest
+// skims the settlement's food; high unrest may break into a revolt that
+// raises bands of rebels beside it. Rebels add to the unrest until the
+// garrison puts them down or calm returns and they go home. Unrest closes on
+// its pressure, and revolts and suppression run, for every year the tick
+// spans.
+func (e *GameEngine) processUnrest(ctx context.Context, game *models.Game) error {
+	years := game.YearsPerTick()
+	rules := game.Ruleset()
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*models.Settlement, len(settlements))
+	for _, settlement := range settlements {
+		byID[settlement.SettlementID] = settlement
+	}
+
+	rebels := make(map[string]int)
+	for _, unit := range units {
+		if unit.UnitType != models.UnitTypeRebels {
+			continue
+		}
+		if e.suppressRebels(ctx, game, unit, byID[unit.HomeID], years) {
+			rebels[unit.HomeID]++
+		}
+	}
+
+	for _, settlement := range settlements {
+		if settlement.PlayerID == models.NeutralPlayerID || models.IsMinorCiv(settlement.PlayerID) {
+			continue
+		}
+		pressure := settlementUnrestPressure(settlement, rebels[settlement.SettlementID])
+		unrest := math.Round((settlement.Unrest+(pressure-settlement.Unrest)*chanceOverYears(rules.UnrestAdjustment, years))*1000) / 1000
+		e.settlementSimulation(ctx, game, settlement).Conditions.Unrest = unrest * unrestSkim
+
+		if unrest >= revoltUnrest && rebels[settlement.SettlementID] == 0 && game.Seeds.Stream(models.SeedStreamEvents).Float64() < chanceOverYears(rules.RevoltChance, years) {
+			e.revolt(ctx, game, settlement)
+		}
+		if unrest == settlement.Unrest {
+			continue
+		}
+		settlement.Unrest = unrest
+		settlement.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error updating unrest of settlement %s: %v", settlement.SettlementID, err)
+		}
+	}
+	return nil
+}
+
+// settlementUnrestPressure returns the unrest a settlement settles toward
+func settlementUnrestPressure(settlement *models.Settlement, rebels int) float64 {
+	crowding := math.Min(1, math.Max(0, float64(settlement.Population-unrestPopulation)/unrestPopulationSpan))
+	pressure := crowding*(unrestBase+unrestPerUnhappiness*settlement.Unhappiness) + rebelUnrest*float64(rebels)
+	pressure -= garrisonOrder * settlement.Garrison
+	for _, building := range settlement.Buildings {
+		pressure -= unrestRelief[building]
+	}
+	return math.Min(1, math.Max(0, pressure))
+}
+
+// suppressRebels lets a band of rebels' home settlement deal with them for
+// the years the tick spans: they go home once it is calm or gone, and its garrison wears
+// them down until none are left. It reports whether the band is still at
+// large.
+func (e *GameEngine) suppressRebels(ctx context.Context, game *models.Game, unit *models.Unit, home *models.Settlement, years int) bool {
+	if home != nil && home.Unrest >= calmUnrest && home.Garrison > 0 {
+		unit.Damage += int(math.Round(home.Garrison*rebelSuppression)) * years
+	}
+	if home == nil || home.Unrest < calmUnrest || unit.Health() == 0 {
+		if err := e.repo.DeleteUnit(ctx, unit.UnitID); err != nil {
+			log.Printf("Error disbanding rebels %s in game %s: %v", unit.UnitID, game.GameID, err)
+			return true
+		}
+		return false
+	}
+	if home.Garrison > 0 {
+		unit.LastUpdated = time.Now()
+		if err := e.repo.UpdateUnit(ctx, unit); err != nil {
+			log.Printf("Error updating rebels %s in game %s: %v", unit.UnitID, game.GameID, err)
+		}
+	}
+	return true
+}
+
+// revolt raises bands of rebels beside a settlement, one for each
+// rebelsPerPopulation of its people up to maxRebels
+func (e *GameEngine) revolt(ctx context.Context, game *models.Game, settlement *models.Settlement) {
+	_, location, err := e.findValidAdjacentTile(ctx, game.GameID, settlement.Location)
+	if err != nil {
+		log.Printf("Error finding room for rebels beside settlement %s: %v", settlement.SettlementID, err)
+		return
+	}
+	bands := min(max(settlement.Population/rebelsPerPopulation, 1), maxRebels)
+	for i := 0; i < bands; i++ {
+		rebels := &models.Unit{
+			UnitID:      generateUUID(),
+			GameID:      game.GameID,
+			PlayerID:    models.RebelPlayerID,
+			UnitType:    models.UnitTypeRebels,
+			Location:    location,
+			HomeID:      settlement.SettlementID,
+			CreatedAt:   time.Now(),
+			LastUpdated: time.Now(),
+		}
+		if err := e.repo.CreateUnit(ctx, rebels); err != nil {
+			log.Printf("Error raising rebels in settlement %s: %v", settlement.SettlementID, err)
+			return
+		}
+	}
+
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventRevolt,
+		PlayerID:  settlement.PlayerID,
+		Detail:    fmt.Sprintf("The people of %s revolted, raising %d bands of rebels", settlement.Name, bands),
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording revolt in settlement %s: %v", settlement.SettlementID, err)
+	}
+	log.Printf("Game %s: %s revolted", game.GameID, settlement.Name)
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"context"
+	"log"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+)
+
+// recordFireMastery notes the year a player first mastered fire, which
+// counts toward the fastest Fire Mastery in their statistics
+func (e *GameEngine) recordFireMastery(ctx context.Context, game *models.Game, settlement *models.Settlement) {
+	if _, done := game.FireMastery[settlement.PlayerID]; done || !containsString(settlement.Technologies, simulator.TechFireMastery) {
+		return
+	}
+	if err := e.repo.RecordFireMastery(ctx, game.GameID, settlement.PlayerID, game.CurrentYear); err != nil {
+		log.Printf("Error recording fire mastery for %s in game %s: %v", settlement.PlayerID, game.GameID, err)
+		return
+	}
+	if game.FireMastery == nil {
+		game.FireMastery = make(map[string]int)
+	}
+	game.FireMastery[settlement.PlayerID] = game.CurrentYear
+}
+
+// recordUserStats folds every player's result in a finished game into their
+// cross-game statistics. The year a player mastered fire is counted as the
+// years simulated up to and including it.
+func (e *GameEngine) recordUserStats(ctx context.Context, game *models.Game, victoryType string) {
+	for _, playerID := range game.PlayerList {
+		result := &models.GameResult{GameID: game.GameID, UserID: playerID}
+		if containsString(game.Winners, playerID) {
+			result.VictoryType = victoryType
+		}
+		if year, ok := game.FireMastery[playerID]; ok {
+			result.FireMasteryYears = year - gameStartYear + 1
+		}
+		if err := e.repo.RecordGameResult(ctx, result); err != nil {
+			log.Printf("Error recording statistics for %s in game %s: %v", playerID, game.GameID, err)
+		}
+	}
+}
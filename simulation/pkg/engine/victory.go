@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// factionOf names the side a player fights for: their team, or themselves
+// in a free-for-all
+func factionOf(game *models.Game, playerID string) string {
+	if team := game.TeamOf(playerID); team != models.NoTeam {
+		return "team:" + strconv.Itoa(team)
+	}
+	return "player:" + playerID
+}
+
+// victors returns the winning players once only one faction has players
+// left standing, or nil while the game is still contested. A team wins as a
+// whole, including members who were eliminated along the way.
+func victors(game *models.Game) []string {
+	factions := make(map[string]bool)
+	for _, playerID := range game.PlayerList {
+		factions[factionOf(game, playerID)] = true
+	}
+	active := game.ActivePlayers()
+	if len(factions) < 2 || len(active) == 0 {
+		return nil
+	}
+
+	winner := factionOf(game, active[0])
+	for _, playerID := range active[1:] {
+		if factionOf(game, playerID) != winner {
+			return nil
+		}
+	}
+
+	var winners []string
+	for _, playerID := range game.PlayerList {
+		if factionOf(game, playerID) == winner {
+			winners = append(winners, playerID)
+		}
+	}
+	return winners
+}
+
+// processVictory ends the game once a single player or team remains.
+// Persistent games never end.
+func (e *GameEngine) processVictory(ctx context.Context, game *models.Game) error {
+	if game.Persistent {
+		return nil
+	}
+	winners := victors(game)
+	if winners == nil {
+		return nil
+	}
+
+	if err := models.ValidateTransition(game.State, models.GameStateFinished); err != nil {
+		return err
+	}
+	if err := e.repo.EndGame(ctx, game.GameID, game.State, winners); err != nil {
+		return err
+	}
+	game.State = models.GameStateFinished
+	game.Winners = winners
+
+	event := &models.GameEvent{
+		EventID:   generateUUID(),
+		GameID:    game.GameID,
+		Year:      game.CurrentYear,
+		Type:      models.EventVictory,
+		Detail:    strings.Join(winners, ", "),
+		CreatedAt: time.Now(),
+	}
+	if err := e.createEvent(ctx, event); err != nil {
+		log.Printf("Error recording victory event for game %s: %v", game.GameID, err)
+	}
+
+	log.Printf("Game %s won by %s in year %d", game.GameID, event.Detail, game.CurrentYear)
+	e.recordUserStats(ctx, game, models.VictoryConquest)
+	e.recordGameSummary(ctx, game, models.VictoryConquest)
+	return nil
+}
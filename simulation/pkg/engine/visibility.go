@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// processExploration records each player's view of every tile they can see
+// this tick, so tiles that later leave their sight keep their last-seen state.
+// Only records whose state changed are written.
+func (e *GameEngine) processExploration(ctx context.Context, game *models.Game) error {
+	tiles, err := e.repo.GetSeenTiles(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	if len(tiles) == 0 {
+		return nil
+	}
+
+	explored, err := e.repo.GetExploredTiles(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	type exploredKey struct {
+		playerID string
+		x, y     int
+	}
+	known := make(map[exploredKey]*models.ExploredTile, len(explored))
+	for _, record := range explored {
+		known[exploredKey{record.PlayerID, record.X, record.Y}] = record
+	}
+
+	var changed []*models.ExploredTile
+	for _, tile := range tiles {
+		for _, playerID := range tileViewers(game, tile) {
+			record := snapshotTile(tile, playerID, game.CurrentYear)
+			if previous, ok := known[exploredKey{playerID, tile.X, tile.Y}]; ok && sameTileState(previous, record) {
+				continue
+			}
+			changed = append(changed, record)
+		}
+	}
+
+	return e.repo.SaveExploredTiles(ctx, changed)
+}
+
+// tileViewers lists the players who currently see a tile: those it is
+// visible to plus its owner, whose territory is always visible, and the
+// teammates of all of them, who share their team's fog of war
+func tileViewers(game *models.Game, tile *models.MapTile) []string {
+	sighted := tile.VisibleTo
+	if tile.OwnerID != nil && !containsString(sighted, *tile.OwnerID) {
+		sighted = append(append([]string(nil), sighted...), *tile.OwnerID)
+	}
+	if game.TeamCount == 0 {
+		return sighted
+	}
+
+	var viewers []string
+	for _, playerID := range sighted {
+		for _, teammate := range game.Teammates(playerID) {
+			if !containsString(viewers, teammate) {
+				viewers = append(viewers, teammate)
+			}
+		}
+	}
+	return viewers
+}
+
+// snapshotTile captures the parts of a tile a player can observe
+func snapshotTile(tile *models.MapTile, playerID string, year int) *models.ExploredTile {
+	return &models.ExploredTile{
+		GameID:       tile.GameID,
+		PlayerID:     playerID,
+		X:            tile.X,
+		Y:            tile.Y,
+		TerrainType:  tile.TerrainType,
+		Resources:    append([]string(nil), tile.Resources...),
+		Improvements: append([]string(nil), tile.Improvements...),
+		OwnerID:      tile.OwnerID,
+		SeenYear:     year,
+	}
+}
+
+// sameTileState reports whether two records show the same observable state
+func sameTileState(a, b *models.ExploredTile) bool {
+	if a.TerrainType != b.TerrainType || !sameStrings(a.Resources, b.Resources) || !sameStrings(a.Improvements, b.Improvements) {
+		return false
+	}
+	if (a.OwnerID == nil) != (b.OwnerID == nil) {
+		return false
+	}
+	return a.OwnerID == nil || *a.OwnerID == *b.OwnerID
+}
+
+// sameStrings reports whether two string slices hold the same values in order
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,370 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+	"github.com/anicolao/simciv/simulation/pkg/yields"
+)
+
+// workerJob is an improvement a worker has chosen to build, or a site to
+// repair after a disaster
+type workerJob struct {
+	target      models.Location
+	improvement string
+	repair      bool // Restore what a disaster wrecked at the target
+}
+
+// processWorkers runs every automated worker for one year: each picks a job
+// from its player's road plans, or else by its mode's priority rules, then
+// either builds on the spot or travels toward it as far as its movement
+// carries it. Workers are processed in unit ID order and never pick a
+// tile another worker has claimed this tick, so results are deterministic.
+// Repairing what disasters wrecked comes before any other work. Workers
+// busy with a terrain project, or sent somewhere by a move order, are left
+// to it.
+func (e *GameEngine) processWorkers(ctx context.Context, game *models.Game) error {
+	units, err := e.repo.GetUnits(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	var workers []*models.Unit
+	for _, unit := range units {
+		if unit.UnitType == models.UnitTypeWorkers && unit.Project == nil && unit.Destination == nil && e.workerMode(game, unit) != "" {
+			workers = append(workers, unit)
+		}
+	}
+	if len(workers) == 0 {
+		return nil
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].UnitID < workers[j].UnitID })
+
+	tiles, err := e.repo.GetMapTiles(ctx, game.GameID, nil)
+	if err != nil {
+		return err
+	}
+	settlements, err := e.repo.GetSettlements(ctx, game.GameID)
+	if err != nil {
+		return err
+	}
+	tileAt := make(map[models.Location]*models.MapTile, len(tiles))
+	for _, tile := range tiles {
+		tileAt[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+
+	lookup := func(loc models.Location) *models.MapTile { return tileAt[loc] }
+
+	// Players keep pastures once any of their settlements masters husbandry
+	pastoral := make(map[string]bool)
+	for _, settlement := range settlements {
+		if containsString(settlement.Technologies, simulator.TechHusbandry) {
+			pastoral[settlement.PlayerID] = true
+		}
+	}
+
+	// Roads planned for a player's settlements come before any other work
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].SettlementID < settlements[j].SettlementID })
+	plans := make(map[string][]models.Location)
+	for _, settlement := range settlements {
+		plans[settlement.PlayerID] = append(plans[settlement.PlayerID], settlement.RoadPlan...)
+	}
+
+	claimed := make(map[models.Location]bool)
+	for _, worker := range workers {
+		job, ok := repairJob(worker, tiles, settlements, claimed)
+		if !ok {
+			job, ok = plannedRoadJob(worker, plans[worker.PlayerID], tileAt, claimed)
+		}
+		if !ok {
+			job, ok = chooseWorkerJob(worker, e.workerMode(game, worker), tiles, tileAt, settlements, claimed, pastoral[worker.PlayerID])
+		}
+		if !ok {
+			continue
+		}
+		claimed[job.target] = true
+
+		if worker.Location == job.target && job.repair {
+			e.repairDamage(ctx, game, worker, tileAt[job.target], settlements)
+			continue
+		}
+		if worker.Location == job.target {
+			if err := e.repo.AddTileImprovement(ctx, game.GameID, job.target.X, job.target.Y, job.improvement, game.CurrentYear); err != nil {
+				log.Printf("Error building %s at (%d, %d): %v", job.improvement, job.target.X, job.target.Y, err)
+				continue
+			}
+			tile := tileAt[job.target]
+			tile.Improvements = append(tile.Improvements, job.improvement)
+			log.Printf("Worker %s built %s at (%d, %d)", worker.UnitID, job.improvement, job.target.X, job.target.Y)
+			if job.improvement == models.ImprovementRoad {
+				e.invalidatePaths(game.GameID)
+			}
+			continue
+		}
+
+		path := advanceAlongRoute(e.gameRoute(ctx, game.GameID, worker.Location, job.target), lookup, worker, game.YearsPerTick())
+		worker.Location = path[len(path)-1]
+		worker.LastUpdated = time.Now()
+		if err := e.repo.UpdateUnit(ctx, worker); err != nil {
+			log.Printf("Error moving worker %s: %v", worker.UnitID, err)
+			continue
+		}
+		e.recordMovement(ctx, game, worker, path)
+	}
+
+	return nil
+}
+
+// chooseWorkerJob picks a worker's next job under the given automation mode.
+// Pastoral players, who know husbandry, put pastures on tiles with herds.
+func chooseWorkerJob(worker *models.Unit, mode string, tiles []*models.MapTile, tileAt map[models.Location]*models.MapTile, settlements []*models.Settlement, claimed map[models.Location]bool, pastoral bool) (workerJob, bool) {
+	switch mode {
+	case models.AutomationImproveNearest:
+		return bestTileJob(worker, tiles, claimed, func(tile *models.MapTile) (string, float64) {
+			return tileImprovement(tile, pastoral), 0
+		})
+	case models.AutomationFocusFood:
+		return bestTileJob(worker, tiles, claimed, func(tile *models.MapTile) (string, float64) {
+			improvement := tileImprovement(tile, pastoral)
+			if improvement != models.ImprovementFarm && improvement != models.ImprovementPasture {
+				return "", 0
+			}
+			improved := append(append([]string(nil), tile.Improvements...), improvement)
+			return improvement, yields.ComputeTileYields(tile, improved, nil).Food
+		})
+	case models.AutomationConnectCities:
+		return roadJob(worker, tileAt, settlements, claimed)
+	}
+	return workerJob{}, false
+}
+
+// bestTileJob picks the unimproved tile in the worker's territory with the
+// highest priority, then the nearest, then the lowest (y, x). rate returns the
+// improvement to build on a tile (empty to skip it) and its priority.
+func bestTileJob(worker *models.Unit, tiles []*models.MapTile, claimed map[models.Location]bool, rate func(tile *models.MapTile) (string, float64)) (workerJob, bool) {
+	var best workerJob
+	bestPriority, bestDistance := 0.0, 0
+	found := false
+	for _, tile := range tiles {
+		loc := models.Location{X: tile.X, Y: tile.Y}
+		if tile.OwnerID == nil || *tile.OwnerID != worker.PlayerID || claimed[loc] || isImproved(tile) {
+			continue
+		}
+		improvement, priority := rate(tile)
+		if improvement == "" {
+			continue
+		}
+		distance := manhattan(worker.Location, loc)
+		better := !found || priority > bestPriority ||
+			(priority == bestPriority && (distance < bestDistance ||
+				(distance == bestDistance && (loc.Y < best.target.Y || (loc.Y == best.target.Y && loc.X < best.target.X)))))
+		if better {
+			best = workerJob{target: loc, improvement: improvement}
+			bestPriority, bestDistance = priority, distance
+			found = true
+		}
+	}
+	return best, found
+}
+
+// plannedRoadJob picks the nearest planned road tile not yet paved or
+// claimed, then the lowest (y, x)
+func plannedRoadJob(worker *models.Unit, plan []models.Location, tileAt map[models.Location]*models.MapTile, claimed map[models.Location]bool) (workerJob, bool) {
+	var best models.Location
+	found := false
+	for _, loc := range plan {
+		tile, ok := tileAt[loc]
+		if !ok || claimed[loc] || containsString(tile.Improvements, models.ImprovementRoad) {
+			continue
+		}
+		distance, bestDistance := manhattan(worker.Location, loc), manhattan(worker.Location, best)
+		if !found || distance < bestDistance || (distance == bestDistance && (loc.Y < best.Y || (loc.Y == best.Y && loc.X < best.X))) {
+			best, found = loc, true
+		}
+	}
+	return workerJob{target: best, improvement: models.ImprovementRoad}, found
+}
+
+// repairJob picks the nearest site in the worker's territory a disaster
+// wrecked, a tile's improvements or a settlement's buildings, that no other
+// worker has claimed, then the lowest (y, x)
+func repairJob(worker *models.Unit, tiles []*models.MapTile, settlements []*models.Settlement, claimed map[models.Location]bool) (workerJob, bool) {
+	var sites []models.Location
+	for _, tile := range tiles {
+		if tile.OwnerID != nil && *tile.OwnerID == worker.PlayerID && len(tile.Damaged) > 0 {
+			sites = append(sites, models.Location{X: tile.X, Y: tile.Y})
+		}
+	}
+	for _, settlement := range settlements {
+		if settlement.PlayerID == worker.PlayerID && len(settlement.Damaged) > 0 {
+			sites = append(sites, settlement.Location)
+		}
+	}
+
+	var best models.Location
+	found := false
+	for _, loc := range sites {
+		if claimed[loc] {
+			continue
+		}
+		distance, bestDistance := manhattan(worker.Location, loc), manhattan(worker.Location, best)
+		if !found || distance < bestDistance || (distance == bestDistance && (loc.Y < best.Y || (loc.Y == best.Y && loc.X < best.X))) {
+			best, found = loc, true
+		}
+	}
+	return workerJob{target: best, repair: true}, found
+}
+
+// repairDamage restores one wrecked improvement on the worker's tile or,
+// once those are done, one wrecked building of the player's settlement there
+func (e *GameEngine) repairDamage(ctx context.Context, game *models.Game, worker *models.Unit, tile *models.MapTile, settlements []*models.Settlement) {
+	if tile != nil && len(tile.Damaged) > 0 {
+		improvement := tile.Damaged[0]
+		tile.Damaged = tile.Damaged[1:]
+		if !containsString(tile.Improvements, improvement) {
+			tile.Improvements = append(tile.Improvements, improvement)
+		}
+		tile.LastModifiedTick = game.CurrentYear
+		if err := e.repo.UpdateTileImprovements(ctx, tile); err != nil {
+			log.Printf("Error repairing %s at (%d, %d): %v", improvement, tile.X, tile.Y, err)
+			return
+		}
+		log.Printf("Worker %s repaired %s at (%d, %d)", worker.UnitID, improvement, tile.X, tile.Y)
+		if improvement == models.ImprovementRoad {
+			e.invalidatePaths(game.GameID)
+		}
+		if err := e.refreshSettlementYields(ctx, game, []*models.MapTile{tile}); err != nil {
+			log.Printf("Error refreshing yields after repairs at (%d, %d): %v", tile.X, tile.Y, err)
+		}
+		return
+	}
+
+	for _, settlement := range settlements {
+		if settlement.Location != worker.Location || settlement.PlayerID != worker.PlayerID || len(settlement.Damaged) == 0 {
+			continue
+		}
+		building := settlement.Damaged[0]
+		settlement.Damaged = settlement.Damaged[1:]
+		if !containsString(settlement.Buildings, building) {
+			settlement.Buildings = append(settlement.Buildings, building)
+		}
+		settlement.LastUpdated = time.Now()
+		if err := e.repo.UpdateSettlement(ctx, settlement); err != nil {
+			log.Printf("Error repairing %s in settlement %s: %v", building, settlement.SettlementID, err)
+			return
+		}
+		log.Printf("Worker %s repaired the %s of %s", worker.UnitID, building, settlement.Name)
+		return
+	}
+}
+
+// roadJob finds the first unbuilt road tile between the closest pair of the
+// worker's settlements that are not yet joined. Roads follow an L-shaped path
+// (along x, then along y); pairs whose path crosses water are skipped.
+func roadJob(worker *models.Unit, tileAt map[models.Location]*models.MapTile, settlements []*models.Settlement, claimed map[models.Location]bool) (workerJob, bool) {
+	var owned []*models.Settlement
+	for _, settlement := range settlements {
+		if settlement.PlayerID == worker.PlayerID {
+			owned = append(owned, settlement)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].SettlementID < owned[j].SettlementID })
+
+	type pair struct{ a, b *models.Settlement }
+	var pairs []pair
+	for i := range owned {
+		for j := i + 1; j < len(owned); j++ {
+			pairs = append(pairs, pair{owned[i], owned[j]})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return manhattan(pairs[i].a.Location, pairs[i].b.Location) < manhattan(pairs[j].a.Location, pairs[j].b.Location)
+	})
+
+pairs:
+	for _, p := range pairs {
+		var next *models.Location
+		for _, loc := range roadPath(p.a.Location, p.b.Location) {
+			tile, ok := tileAt[loc]
+			if !ok || terrain.IsWater(tile.TerrainType) {
+				continue pairs
+			}
+			if next == nil && !claimed[loc] && !containsString(tile.Improvements, models.ImprovementRoad) {
+				l := loc
+				next = &l
+			}
+		}
+		if next != nil {
+			return workerJob{target: *next, improvement: models.ImprovementRoad}, true
+		}
+	}
+	return workerJob{}, false
+}
+
+// roadPath lists the tiles from a to b, moving along x first and then y
+func roadPath(a, b models.Location) []models.Location {
+	path := []models.Location{a}
+	for loc := a; loc != b; {
+		loc = stepToward(loc, b)
+		path = append(path, loc)
+	}
+	return path
+}
+
+// stepToward moves one tile from a toward b, along x first and then y
+func stepToward(a, b models.Location) models.Location {
+	switch {
+	case a.X < b.X:
+		a.X++
+	case a.X > b.X:
+		a.X--
+	case a.Y < b.Y:
+		a.Y++
+	case a.Y > b.Y:
+		a.Y--
+	}
+	return a
+}
+
+// improvementFor returns the improvement workers build on a terrain, or "" if none
+func improvementFor(terrainType string) string {
+	switch {
+	case terrain.IsWater(terrainType):
+		return ""
+	case terrainType == terrain.Hills || terrainType == terrain.Mountain:
+		return models.ImprovementMine
+	default:
+		return models.ImprovementFarm
+	}
+}
+
+// tileImprovement returns the improvement workers build on a tile: a pasture
+// where herds roam if the player knows husbandry, otherwise by terrain
+func tileImprovement(tile *models.MapTile, pastoral bool) string {
+	if pastoral && !terrain.IsWater(tile.TerrainType) && terrain.HasLivestock(tile) {
+		return models.ImprovementPasture
+	}
+	return improvementFor(tile.TerrainType)
+}
+
+// isImproved reports whether a tile already has a farm, mine or pasture,
+// standing or awaiting repair
+func isImproved(tile *models.MapTile) bool {
+	for _, improvements := range [][]string{tile.Improvements, tile.Damaged} {
+		if containsString(improvements, models.ImprovementFarm) ||
+			containsString(improvements, models.ImprovementMine) ||
+			containsString(improvements, models.ImprovementPasture) {
+			return true
+		}
+	}
+	return false
+}
+
+// manhattan returns the Manhattan distance between two locations
+func manhattan(a, b models.Location) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
@@ -0,0 +1,135 @@
+// Package eventbus publishes the engine's game events to a message bus, so
+// analytics pipelines and other services can consume them at scale. Events
+// are encoded as the relaxed extended JSON of their documents in the
+// gameEvents collection, mirroring the internal event log.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// Buses EVENT_BUS may name
+const (
+	BusNATS  = "nats"
+	BusKafka = "kafka"
+)
+
+// DefaultTopic is the Kafka topic, or NATS subject prefix, events are
+// published to unless EVENT_BUS_TOPIC says otherwise
+const DefaultTopic = "simciv.events"
+
+const (
+	queueSize      = 4096 // Events that may wait to be published before new ones are dropped
+	maxAttempts    = 5    // Tries per event before it is dropped
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+	publishTimeout = 10 * time.Second // Bound on one attempt, so a stalled connection cannot hold up the bus
+)
+
+// Publisher sends game events to a message bus
+type Publisher interface {
+	// Publish sends one event, returning an error if the bus could not take it
+	Publish(ctx context.Context, event *models.GameEvent) error
+
+	// Close releases the publisher's connection
+	Close() error
+}
+
+// FromEnv creates the publisher EVENT_BUS names ("nats" or "kafka"), sending
+// to EVENT_BUS_URL under EVENT_BUS_TOPIC. It returns nil when EVENT_BUS is
+// unset.
+func FromEnv() (Publisher, error) {
+	bus := os.Getenv("EVENT_BUS")
+	if bus == "" {
+		return nil, nil
+	}
+	url := os.Getenv("EVENT_BUS_URL")
+	if url == "" {
+		return nil, fmt.Errorf("EVENT_BUS %s needs EVENT_BUS_URL", bus)
+	}
+	topic := os.Getenv("EVENT_BUS_TOPIC")
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	switch bus {
+	case BusNATS:
+		return NewNATSPublisher(url, topic)
+	case BusKafka:
+		return NewKafkaPublisher(url, topic), nil
+	default:
+		return nil, fmt.Errorf("unknown EVENT_BUS %q (want %s or %s)", bus, BusNATS, BusKafka)
+	}
+}
+
+// encode returns an event as it is stored in the event log
+func encode(event *models.GameEvent) ([]byte, error) {
+	return bson.MarshalExtJSON(event, false, false)
+}
+
+// Bus publishes events in the background, in the order they were recorded,
+// so recording an event never waits on the message bus
+type Bus struct {
+	publisher Publisher
+	queue     chan *models.GameEvent
+}
+
+// NewBus creates a bus; Run publishes what it is notified of
+func NewBus(publisher Publisher) *Bus {
+	return &Bus{publisher: publisher, queue: make(chan *models.GameEvent, queueSize)}
+}
+
+// Notify queues an event to publish. It never blocks: the event is dropped
+// if the queue is full.
+func (b *Bus) Notify(event *models.GameEvent) {
+	select {
+	case b.queue <- event:
+	default:
+		log.Printf("Event bus is backed up; dropping %s event %s", event.Type, event.EventID)
+	}
+}
+
+// Run publishes queued events until ctx is cancelled, then closes the
+// publisher. An event the bus refuses is retried with backoff before it is
+// dropped.
+func (b *Bus) Run(ctx context.Context) {
+	defer b.publisher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-b.queue:
+			b.publish(ctx, event)
+		}
+	}
+}
+
+// publish sends one event, retrying with backoff. Each attempt has
+// publishTimeout to complete.
+func (b *Bus) publish(ctx context.Context, event *models.GameEvent) {
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err := b.publisher.Publish(attemptCtx, event)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= maxAttempts {
+			log.Printf("Dropping %s event %s after %d attempts to publish it: %v", event.Type, event.EventID, attempt, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(repository.Backoff(attempt, initialBackoff, maxBackoff)):
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+func TestNATSPublisher(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	// A minimal server: greets, checks the credentials, answers the PING
+	// and hands over the first message published, holding the connection
+	// open until the test ends
+	published := make(chan string, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		connect, _ := reader.ReadString('\n')
+		if !strings.Contains(connect, `"auth_token":"secret"`) {
+			conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+			return
+		}
+		reader.ReadString('\n')
+		conn.Write([]byte("PONG\r\n"))
+		header, _ := reader.ReadString('\n')
+		payload, _ := reader.ReadString('\n')
+		published <- header + payload
+		<-done
+	}()
+
+	publisher, err := NewNATSPublisher("nats://secret@"+listener.Addr().String(), DefaultTopic)
+	if err != nil {
+		t.Fatalf("NewNATSPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	bus := NewBus(publisher)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Run(ctx)
+
+	// Every event recorded through the hooked repository is published
+	repo := repository.NewHookedRepository(repository.NewMemoryRepository(), bus.Notify)
+	repo.CreateEvent(ctx, &models.GameEvent{EventID: "e1", GameID: "game1", Year: -4900, Type: models.EventUnitMoved, PlayerID: "p1"})
+	select {
+	case message := <-published:
+		header, payload, _ := strings.Cut(message, "\r\n")
+		if !strings.HasPrefix(header, "PUB simciv.events.game1.unit_moved ") {
+			t.Errorf("Expected the event on its game and type's subject, got %q", header)
+		}
+		var event map[string]any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(payload)), &event); err != nil || event["eventId"] != "e1" || event["playerId"] != "p1" {
+			t.Errorf("Expected the event log's document, got %q (%v)", payload, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the event to be published")
+	}
+
+	// A publish whose deadline has passed gives up rather than writing
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	err = publisher.Publish(expired, &models.GameEvent{EventID: "e2", GameID: "game1", Type: models.EventUnitMoved})
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Expected the publish to stop at its deadline, got %v", err)
+	}
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	var path, contentType string
+	var produced struct {
+		Records []struct {
+			Key   string         `json:"key"`
+			Value map[string]any `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &produced)
+	}))
+	defer server.Close()
+
+	publisher := NewKafkaPublisher(server.URL+"/", DefaultTopic)
+	err := publisher.Publish(context.Background(), &models.GameEvent{EventID: "e1", GameID: "game1", Year: -4800, Type: models.EventVictory, Detail: "p1"})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if path != "/topics/simciv.events" || contentType != kafkaContentType {
+		t.Errorf("Expected a JSON produce request to the topic, got %s (%s)", path, contentType)
+	}
+	if len(produced.Records) != 1 || produced.Records[0].Key != "game1" || produced.Records[0].Value["type"] != models.EventVictory {
+		t.Errorf("Expected the victory keyed by its game, got %+v", produced.Records)
+	}
+}
+
+// deadlinePublisher reports the deadline each publish is given
+type deadlinePublisher struct {
+	deadlines chan time.Time
+}
+
+func (d *deadlinePublisher) Publish(ctx context.Context, event *models.GameEvent) error {
+	deadline, _ := ctx.Deadline()
+	d.deadlines <- deadline
+	return nil
+}
+
+func (d *deadlinePublisher) Close() error { return nil }
+
+func TestBus_PublishDeadline(t *testing.T) {
+	publisher := &deadlinePublisher{deadlines: make(chan time.Time, 1)}
+	bus := NewBus(publisher)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Run(ctx)
+
+	// Each attempt is bounded even though the bus runs until cancelled
+	bus.Notify(&models.GameEvent{EventID: "e1", GameID: "game1", Type: models.EventUnitMoved})
+	select {
+	case deadline := <-publisher.deadlines:
+		if deadline.IsZero() || time.Until(deadline) > publishTimeout {
+			t.Errorf("Expected the publish to be given at most %v, got deadline %v", publishTimeout, deadline)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the event to be published")
+	}
+}
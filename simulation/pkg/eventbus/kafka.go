@@ -0,0 +1,74 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// kafkaContentType is the Kafka REST Proxy's v2 format for JSON records
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaPublisher produces each event to a Kafka topic through a Kafka REST
+// Proxy, keyed by its game so each game's events keep their order within a
+// partition
+type KafkaPublisher struct {
+	endpoint string
+	client   *http.Client
+}
+
+// kafkaRecord is one record of a REST Proxy produce request
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// NewKafkaPublisher creates a publisher for the REST Proxy at proxyURL,
+// such as http://kafka-rest:8082
+func NewKafkaPublisher(proxyURL string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		endpoint: strings.TrimRight(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   &http.Client{Timeout: publishTimeout},
+	}
+}
+
+// Publish produces an event, returning once the proxy has acknowledged it
+func (p *KafkaPublisher) Publish(ctx context.Context, event *models.GameEvent) error {
+	value, err := encode(event)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string][]kafkaRecord{"records": {{Key: event.GameID, Value: value}}})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", kafkaContentType)
+	request.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("Kafka REST Proxy answered %s: %s", response.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// Close does nothing; each event is its own request
+func (p *KafkaPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,129 @@
+// Package grid walks the tiles of a rectangular map: the neighbours of a
+// tile, the rings and disks around it and the connected regions it belongs
+// to. Walks keep to the map, wrapping east to west on maps that wrap, and
+// visit tiles in row order so callers stay deterministic.
+package grid
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+// Grid is the shape of a map. The zero Grid is unbounded, for callers that
+// look tiles up and skip the ones missing.
+type Grid struct {
+	Width  int
+	Height int
+	WrapX  bool // Columns wrap around, the east edge meeting the west
+}
+
+// Unbounded is a grid without edges
+var Unbounded = Grid{}
+
+// Offsets of the neighbours of a tile
+var (
+	orthogonal = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	adjacent   = [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+)
+
+// Wrap returns a location moved onto the grid across a wrapping edge, and
+// whether it lies on the grid
+func (g Grid) Wrap(loc models.Location) (models.Location, bool) {
+	if g.Width <= 0 || g.Height <= 0 {
+		return loc, true
+	}
+	if g.WrapX {
+		loc.X = ((loc.X % g.Width) + g.Width) % g.Width
+	}
+	return loc, loc.X >= 0 && loc.X < g.Width && loc.Y >= 0 && loc.Y < g.Height
+}
+
+// Contains reports whether a location lies on the grid, across a wrapping
+// edge included
+func (g Grid) Contains(loc models.Location) bool {
+	_, ok := g.Wrap(loc)
+	return ok
+}
+
+// Neighbors4 returns the orthogonal neighbours of a location on the grid:
+// east, west, south then north
+func (g Grid) Neighbors4(loc models.Location) []models.Location {
+	return g.offsets(loc, orthogonal)
+}
+
+// Neighbors8 returns the neighbours of a location on the grid, diagonals
+// included, in row order
+func (g Grid) Neighbors8(loc models.Location) []models.Location {
+	return g.offsets(loc, adjacent)
+}
+
+// Ring returns the locations on the grid exactly radius steps from a
+// center, diagonal steps included, in row order. A ring of radius 0 is the
+// center itself.
+func (g Grid) Ring(center models.Location, radius int) []models.Location {
+	var offsets [][2]int
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if max(abs(dx), abs(dy)) == radius {
+				offsets = append(offsets, [2]int{dx, dy})
+			}
+		}
+	}
+	return g.offsets(center, offsets)
+}
+
+// Disk returns the locations on the grid within radius steps of a center,
+// diagonal steps included: the square centered on it, in row order
+func (g Grid) Disk(center models.Location, radius int) []models.Location {
+	offsets := make([][2]int, 0, (2*radius+1)*(2*radius+1))
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			offsets = append(offsets, [2]int{dx, dy})
+		}
+	}
+	return g.offsets(center, offsets)
+}
+
+// Fill returns the region of locations 8-connected to start that include
+// accepts, start first and then in breadth-first order. start is included
+// whether or not include accepts it.
+func (g Grid) Fill(start models.Location, include func(models.Location) bool) []models.Location {
+	start, _ = g.Wrap(start)
+	seen := map[models.Location]bool{start: true}
+	region := []models.Location{start}
+	for i := 0; i < len(region); i++ {
+		for _, next := range g.Neighbors8(region[i]) {
+			if !seen[next] && include(next) {
+				seen[next] = true
+				region = append(region, next)
+			}
+		}
+	}
+	return region
+}
+
+// offsets returns the locations at the given offsets from a location that
+// lie on the grid, each once
+func (g Grid) offsets(from models.Location, offsets [][2]int) []models.Location {
+	locations := make([]models.Location, 0, len(offsets))
+	var seen map[models.Location]bool
+	if g.WrapX {
+		seen = make(map[models.Location]bool, len(offsets))
+	}
+	for _, d := range offsets {
+		loc, ok := g.Wrap(models.Location{X: from.X + d[0], Y: from.Y + d[1]})
+		if !ok || seen[loc] {
+			continue
+		}
+		if seen != nil {
+			seen[loc] = true
+		}
+		locations = append(locations, loc)
+	}
+	return locations
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
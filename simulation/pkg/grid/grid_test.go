@@ -0,0 +1,52 @@
+package grid
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func loc(x, y int) models.Location { return models.Location{X: x, Y: y} }
+
+func TestGrid(t *testing.T) {
+	g := Grid{Width: 4, Height: 3}
+
+	if got := g.Neighbors4(loc(0, 0)); !reflect.DeepEqual(got, []models.Location{loc(1, 0), loc(0, 1)}) {
+		t.Errorf("Expected the corner's east and south neighbours, got %v", got)
+	}
+	if got := g.Neighbors8(loc(1, 1)); len(got) != 8 || got[0] != loc(0, 0) || got[7] != loc(2, 2) {
+		t.Errorf("Expected 8 neighbours in row order, got %v", got)
+	}
+	if got := g.Disk(loc(0, 0), 1); !reflect.DeepEqual(got, []models.Location{loc(0, 0), loc(1, 0), loc(0, 1), loc(1, 1)}) {
+		t.Errorf("Expected the disk clipped to the map, got %v", got)
+	}
+	if got := g.Ring(loc(1, 1), 0); !reflect.DeepEqual(got, []models.Location{loc(1, 1)}) {
+		t.Errorf("Expected a ring of radius 0 to be its center, got %v", got)
+	}
+	if got := Unbounded.Ring(loc(0, 0), 2); len(got) != 16 {
+		t.Errorf("Expected 16 tiles two steps out, got %d", len(got))
+	}
+
+	// Wrapping maps join their east and west edges
+	wrapped := Grid{Width: 4, Height: 3, WrapX: true}
+	if got := wrapped.Neighbors4(loc(0, 0)); !reflect.DeepEqual(got, []models.Location{loc(1, 0), loc(3, 0), loc(0, 1)}) {
+		t.Errorf("Expected the west neighbour across the edge, got %v", got)
+	}
+	if got := wrapped.Disk(loc(0, 1), 3); len(got) != 12 {
+		t.Errorf("Expected a disk wider than the map to hold each tile once, got %v", got)
+	}
+	if !wrapped.Contains(loc(-1, 2)) || wrapped.Contains(loc(0, 3)) {
+		t.Error("Expected wrapping across columns but not rows")
+	}
+}
+
+func TestGrid_Fill(t *testing.T) {
+	// Two islands, one touching the other only diagonally, and a third apart
+	land := map[models.Location]bool{loc(0, 0): true, loc(1, 0): true, loc(2, 1): true, loc(5, 5): true}
+	g := Grid{Width: 8, Height: 8}
+	region := g.Fill(loc(0, 0), func(l models.Location) bool { return land[l] })
+	if !reflect.DeepEqual(region, []models.Location{loc(0, 0), loc(1, 0), loc(2, 1)}) {
+		t.Errorf("Expected the diagonally joined region, got %v", region)
+	}
+}
@@ -0,0 +1,51 @@
+package mapgen
+
+import (
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// biomeTransition turns a tile of one biome into a transitional biome when
+// it borders any of the listed neighbor biomes
+type biomeTransition struct {
+	from      string
+	neighbors []string
+	to        string
+}
+
+// biomeTransitions softens the hard borders between climate bands
+var biomeTransitions = []biomeTransition{
+	{from: terrain.Desert, neighbors: []string{terrain.Grassland, terrain.Plains, terrain.Forest, terrain.Jungle}, to: terrain.Savanna},
+	{from: terrain.Tundra, neighbors: []string{terrain.Forest, terrain.Grassland, terrain.Plains}, to: terrain.Taiga},
+}
+
+// blendBiomes applies biomeTransitions to the edge tiles of each biome.
+// Neighbors are read from a snapshot so transitional tiles do not spread.
+func (g *Generator) blendBiomes(tiles []*models.MapTile) {
+	original := make([]string, len(tiles))
+	for i, tile := range tiles {
+		original[i] = tile.TerrainType
+	}
+
+	for i, tile := range tiles {
+		for _, transition := range biomeTransitions {
+			if original[i] == transition.from && g.bordersTerrain(original, tile.X, tile.Y, transition.neighbors) {
+				tile.TerrainType = transition.to
+				break
+			}
+		}
+	}
+}
+
+// bordersTerrain reports whether any of the 8 neighbors has one of the given terrain types
+func (g *Generator) bordersTerrain(terrainTypes []string, x, y int, wanted []string) bool {
+	for _, n := range g.mapGrid().Neighbors8(models.Location{X: x, Y: y}) {
+		neighbor := terrainTypes[n.Y*g.width+n.X]
+		for _, w := range wanted {
+			if neighbor == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package mapgen
+
+import (
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	// caveDensity is the share of hill and mountain tiles hollowed into caves
+	caveDensity = 0.03
+
+	// Chance a cave holds obsidian rather than flint
+	obsidianChanceHills    = 0.15
+	obsidianChanceMountain = 0.4
+)
+
+// placeCaves hollows caves into a few hill and mountain tiles. Each cave
+// shelters nearby settlements through winter and holds a deposit of flint
+// or, more often high in the mountains, obsidian.
+func (g *Generator) placeCaves(tiles []*models.MapTile) {
+	random := g.random(phaseResources)
+	var candidates []*models.MapTile
+	for _, tile := range tiles {
+		if tile.TerrainType == terrain.Hills || tile.TerrainType == terrain.Mountain {
+			candidates = append(candidates, tile)
+		}
+	}
+
+	count := int(float64(len(candidates)) * caveDensity)
+	random.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	for _, tile := range candidates[:count] {
+		chance := obsidianChanceHills
+		if tile.TerrainType == terrain.Mountain {
+			chance = obsidianChanceMountain
+		}
+		resource := "FLINT"
+		if random.Float64() < chance {
+			resource = "OBSIDIAN"
+		}
+
+		tile.HasCave = true
+		tile.Resources = append(tile.Resources, resource)
+		tile.ResourceQuantities = terrain.InitialQuantities(tile.Resources)
+	}
+}
@@ -0,0 +1,76 @@
+package mapgen
+
+import (
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	// coastlineSmoothingPasses is how many cellular-automaton passes are run
+	coastlineSmoothingPasses = 2
+
+	// beachMaxElevation is the highest elevation above sea level classified as beach
+	beachMaxElevation = 40
+)
+
+// smoothCoastlines removes single-tile coastal jags (lone islets, spikes,
+// notches and one-tile lakes) by flipping tiles that are outnumbered by the
+// opposite land/water type in their neighborhood. Each pass is computed from
+// a snapshot so the result does not depend on scan order.
+func (g *Generator) smoothCoastlines(elevationGrid [][]int, seaLevel int) {
+	type flip struct {
+		x, y   int
+		toLand bool
+	}
+
+	for pass := 0; pass < coastlineSmoothingPasses; pass++ {
+		var flips []flip
+		for y := 0; y < g.height; y++ {
+			for x := 0; x < g.width; x++ {
+				isLand := elevationGrid[y][x] >= seaLevel
+				same, total := 0, 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if dx == 0 && dy == 0 {
+							continue
+						}
+						nx, ny := x+dx, y+dy
+						if nx < 0 || nx >= g.width || ny < 0 || ny >= g.height {
+							continue
+						}
+						total++
+						if (elevationGrid[ny][nx] >= seaLevel) == isLand {
+							same++
+						}
+					}
+				}
+				// Flip when at most a quarter of the neighbors share this tile's type
+				if same*4 <= total {
+					flips = append(flips, flip{x: x, y: y, toLand: !isLand})
+				}
+			}
+		}
+
+		for _, f := range flips {
+			if f.toLand {
+				elevationGrid[f.y][f.x] = seaLevel
+			} else {
+				elevationGrid[f.y][f.x] = seaLevel - 1
+			}
+		}
+	}
+}
+
+// assignBeaches classifies low-lying coastal land as beach. Cold and rugged
+// coasts keep their terrain.
+func (g *Generator) assignBeaches(tiles []*models.MapTile, seaLevel int) {
+	for _, tile := range tiles {
+		if !tile.IsCoastal || tile.Elevation-seaLevel > beachMaxElevation {
+			continue
+		}
+		switch tile.TerrainType {
+		case terrain.Grassland, terrain.Plains, terrain.Desert, terrain.Forest, terrain.Jungle, terrain.Savanna:
+			tile.TerrainType = terrain.Beach
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package mapgen
+
+import (
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+const (
+	// narrowPassageWidth is the widest gap (in tiles) still counted as a strait or isthmus
+	narrowPassageWidth = 2
+
+	// harborEnclosure is the minimum land neighbors for a water tile to be a sheltered bay
+	harborEnclosure = 5
+
+	// islandMaxSize is the largest landmass (in tiles) counted as an island
+	islandMaxSize = 30
+
+	// islandChainGap is the widest water gap between islands of the same chain
+	islandChainGap = 3
+
+	// islandChainMin is the fewest islands that form a chain
+	islandChainMin = 3
+)
+
+// detectFeatures tags straits, isthmuses, natural harbors and island chains.
+// Adjacent tiles of the same kind are reported as a single feature.
+func (g *Generator) detectFeatures(elevationGrid [][]int, seaLevel int) []models.MapFeature {
+	isLand := func(x, y int) bool {
+		return elevationGrid[y][x] >= seaLevel
+	}
+
+	straits := g.newMask()
+	isthmuses := g.newMask()
+	harbors := g.newMask()
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if g.isNarrowPassage(x, y, isLand) {
+				if isLand(x, y) {
+					isthmuses[y][x] = true
+				} else {
+					straits[y][x] = true
+				}
+			}
+			if isLand(x, y) && g.isHarbor(x, y, isLand) {
+				harbors[y][x] = true
+			}
+		}
+	}
+
+	var features []models.MapFeature
+	features = append(features, g.clusterFeatures(straits, models.FeatureStrait)...)
+	features = append(features, g.clusterFeatures(isthmuses, models.FeatureIsthmus)...)
+	features = append(features, g.clusterFeatures(harbors, models.FeatureHarbor)...)
+	features = append(features, g.findIslandChains(isLand)...)
+	return features
+}
+
+// isNarrowPassage reports whether the tile lies in a gap of at most
+// narrowPassageWidth tiles between two bodies of the opposite type, with the
+// passage continuing on both perpendicular sides. For water this is a strait
+// linking two seas; for land, an isthmus linking two landmasses.
+func (g *Generator) isNarrowPassage(x, y int, isLand func(x, y int) bool) bool {
+	self := isLand(x, y)
+	for _, axis := range [][2]int{{1, 0}, {0, 1}} {
+		dx, dy := axis[0], axis[1]
+
+		// The passage must continue perpendicular to the constriction
+		px1, py1, px2, py2 := x+dy, y+dx, x-dy, y-dx
+		if !g.inBounds(px1, py1) || !g.inBounds(px2, py2) || isLand(px1, py1) != self || isLand(px2, py2) != self {
+			continue
+		}
+
+		forward := g.distanceToOpposite(x, y, dx, dy, self, isLand)
+		backward := g.distanceToOpposite(x, y, -dx, -dy, self, isLand)
+		if forward > 0 && backward > 0 && forward+backward-1 <= narrowPassageWidth {
+			return true
+		}
+	}
+	return false
+}
+
+// distanceToOpposite walks from (x, y) in direction (dx, dy) and returns the
+// step at which the land/water type changes, or 0 if it does not change
+// within narrowPassageWidth steps or the walk leaves the map
+func (g *Generator) distanceToOpposite(x, y, dx, dy int, self bool, isLand func(x, y int) bool) int {
+	for step := 1; step <= narrowPassageWidth; step++ {
+		nx, ny := x+dx*step, y+dy*step
+		if !g.inBounds(nx, ny) {
+			return 0
+		}
+		if isLand(nx, ny) != self {
+			return step
+		}
+	}
+	return 0
+}
+
+// isHarbor reports whether a land tile borders a sheltered bay: a water tile
+// mostly enclosed by land that still opens onto other water
+func (g *Generator) isHarbor(x, y int, isLand func(x, y int) bool) bool {
+	for _, bay := range g.mapGrid().Neighbors4(models.Location{X: x, Y: y}) {
+		if isLand(bay.X, bay.Y) {
+			continue
+		}
+		land, water := 0, 0
+		for _, n := range g.mapGrid().Neighbors8(bay) {
+			if isLand(n.X, n.Y) {
+				land++
+			} else {
+				water++
+			}
+		}
+		if land >= harborEnclosure && water > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// findIslandChains groups small landmasses separated by at most
+// islandChainGap tiles of water and tags groups of islandChainMin or more
+func (g *Generator) findIslandChains(isLand func(x, y int) bool) []models.MapFeature {
+	labels := make([][]int, g.height)
+	for y := range labels {
+		labels[y] = make([]int, g.width)
+		for x := range labels[y] {
+			labels[y][x] = -1
+		}
+	}
+
+	// Label 8-connected landmasses in scan order
+	var sizes []int
+	var origins [][2]int
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if !isLand(x, y) || labels[y][x] >= 0 {
+				continue
+			}
+			label := len(sizes)
+			size := g.floodFill(x, y, func(nx, ny int) bool {
+				return isLand(nx, ny) && labels[ny][nx] < 0
+			}, func(nx, ny int) {
+				labels[ny][nx] = label
+			})
+			sizes = append(sizes, size)
+			origins = append(origins, [2]int{x, y})
+		}
+	}
+
+	// Union islands that lie within islandChainGap of each other
+	parent := make([]int, len(sizes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	isIsland := func(label int) bool {
+		return label >= 0 && sizes[label] <= islandMaxSize
+	}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			label := labels[y][x]
+			if !isIsland(label) {
+				continue
+			}
+			for _, n := range g.mapGrid().Disk(models.Location{X: x, Y: y}, islandChainGap+1) {
+				other := labels[n.Y][n.X]
+				if other != label && isIsland(other) {
+					a, b := find(label), find(other)
+					if a < b {
+						parent[b] = a
+					} else if b < a {
+						parent[a] = b
+					}
+				}
+			}
+		}
+	}
+
+	counts := make(map[int]int)
+	for label := range sizes {
+		if isIsland(label) {
+			counts[find(label)]++
+		}
+	}
+
+	var features []models.MapFeature
+	for label := range sizes {
+		if isIsland(label) && find(label) == label && counts[label] >= islandChainMin {
+			features = append(features, models.MapFeature{
+				Type: models.FeatureIslandChain,
+				X:    origins[label][0],
+				Y:    origins[label][1],
+				Size: counts[label],
+			})
+		}
+	}
+	return features
+}
+
+// clusterFeatures merges 8-connected tagged tiles into one feature each,
+// located at the cluster's first tile in scan order
+func (g *Generator) clusterFeatures(mask [][]bool, featureType string) []models.MapFeature {
+	var features []models.MapFeature
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if !mask[y][x] {
+				continue
+			}
+			size := g.floodFill(x, y, func(nx, ny int) bool {
+				return mask[ny][nx]
+			}, func(nx, ny int) {
+				mask[ny][nx] = false
+			})
+			features = append(features, models.MapFeature{Type: featureType, X: x, Y: y, Size: size})
+		}
+	}
+	return features
+}
+
+// floodFill visits the 8-connected region from (x, y) of tiles accepted by
+// include, calling visit on each (which must make include false for that tile),
+// and returns the region's size
+func (g *Generator) floodFill(x, y int, include func(x, y int) bool, visit func(x, y int)) int {
+	region := g.mapGrid().Fill(models.Location{X: x, Y: y}, func(loc models.Location) bool {
+		return include(loc.X, loc.Y)
+	})
+	for _, loc := range region {
+		visit(loc.X, loc.Y)
+	}
+	return len(region)
+}
+
+// newMask allocates a width x height boolean grid
+func (g *Generator) newMask() [][]bool {
+	mask := make([][]bool, g.height)
+	for y := range mask {
+		mask[y] = make([]bool, g.width)
+	}
+	return mask
+}
+
+// inBounds reports whether (x, y) is on the map
+func (g *Generator) inBounds(x, y int) bool {
+	return g.mapGrid().Contains(models.Location{X: x, Y: y})
+}
+
+// mapGrid returns the shape of the map being generated
+func (g *Generator) mapGrid() grid.Grid {
+	return grid.Grid{Width: g.width, Height: g.height}
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -2,41 +2,46 @@ package mapgen
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"math"
 	"math/rand"
+	"runtime"
 	"sort"
 	"time"
 
 	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/rng"
 )
 
 // Generator generates procedural maps for SimCiv
 type Generator struct {
-	seed   string
-	rng    *rand.Rand
-	width  int
-	height int
+	seed    string
+	streams map[string]*rand.Rand // Random stream of each generation phase
+	width   int
+	height  int
+	options MapOptions
+	workers int // Goroutines computing rows in parallel
 }
 
-// NewGenerator creates a new map generator
+// NewGenerator creates a new map generator with the default options
 func NewGenerator(seed string, playerCount int) *Generator {
+	return NewGeneratorWithOptions(seed, playerCount, DefaultMapOptions())
+}
+
+// NewGeneratorWithOptions creates a map generator tuned by opts
+func NewGeneratorWithOptions(seed string, playerCount int, opts MapOptions) *Generator {
 	// Calculate map dimensions based on player count
 	// Formula: sqrt(players * 1600 * 2)
 	tiles := playerCount * 1600 * 2
 	dimension := int(math.Ceil(math.Sqrt(float64(tiles))))
 
-	// Generate seed hash for RNG
-	h := sha256.Sum256([]byte(seed))
-	seedInt := int64(h[0])<<56 | int64(h[1])<<48 | int64(h[2])<<40 | int64(h[3])<<32 |
-		int64(h[4])<<24 | int64(h[5])<<16 | int64(h[6])<<8 | int64(h[7])
-
 	return &Generator{
-		seed:   seed,
-		rng:    rand.New(rand.NewSource(seedInt)),
-		width:  dimension,
-		height: dimension,
+		seed:    seed,
+		streams: newPhaseStreams(seed),
+		width:   dimension,
+		height:  dimension,
+		options: opts.normalized(),
+		workers: runtime.GOMAXPROCS(0),
 	}
 }
 
@@ -47,25 +52,35 @@ func (g *Generator) GenerateMap(ctx context.Context, gameID string, playerCount
 	// Step 1: Generate great circles for terrain features
 	greatCircles := g.generateGreatCircles(playerCount)
 
-	// Step 2: Calculate base elevation for all tiles
-	tiles := make([]*models.MapTile, 0, g.width*g.height)
+	// Step 2: Calculate base elevation for all tiles, a row per worker
+	tiles := make([]*models.MapTile, g.width*g.height)
 	elevationGrid := make([][]int, g.height)
 	for y := 0; y < g.height; y++ {
 		elevationGrid[y] = make([]int, g.width)
 	}
 
-	for y := 0; y < g.height; y++ {
+	g.forEachRow(func(y int) {
 		for x := 0; x < g.width; x++ {
-			elevation := g.calculateElevation(x, y, greatCircles)
-			elevationGrid[y][x] = elevation
+			elevationGrid[y][x] = g.calculateElevation(x, y, greatCircles)
 		}
-	}
+	})
 
-	// Step 3: Determine sea level (median elevation)
+	// Step 2b: Raise ranges, arcs and rifts along tectonic plate boundaries
+	plates := g.generatePlates(playerCount)
+	tectonicFeatures, volcanic := g.applyTectonics(elevationGrid, plates)
+
+	// Step 3: Determine sea level for the target land ratio
 	seaLevel := g.calculateSeaLevel(elevationGrid)
 
-	// Step 4: Assign terrain types based on elevation and climate
-	for y := 0; y < g.height; y++ {
+	// Step 3b: Smooth noisy single-tile coastline jags
+	g.smoothCoastlines(elevationGrid, seaLevel)
+	landRatio := g.landRatio(elevationGrid, seaLevel)
+
+	// Step 4: Assign terrain types based on elevation and climate, a row
+	// per worker; each row draws from its own stream so the map is the same
+	// however the rows are shared out
+	g.forEachRow(func(y int) {
+		random := g.rowStream("terrain", y)
 		for x := 0; x < g.width; x++ {
 			tile := &models.MapTile{
 				GameID:       gameID,
@@ -79,21 +94,43 @@ func (g *Generator) GenerateMap(ctx context.Context, gameID string, playerCount
 			}
 
 			// Assign terrain type
-			tile.TerrainType = g.assignTerrainType(x, y, elevationGrid[y][x], seaLevel)
+			tile.TerrainType = g.assignTerrainType(x, y, elevationGrid[y][x], seaLevel, random)
 			tile.ClimateZone = g.assignClimateZone(y, elevationGrid[y][x])
 			tile.IsCoastal = g.isCoastal(x, y, elevationGrid, seaLevel)
 			tile.HasRiver = false // Will be set during river generation
 
-			tiles = append(tiles, tile)
+			tiles[y*g.width+x] = tile
 		}
-	}
+	})
+
+	// Step 4b: Blend hard borders between biome bands
+	g.blendBiomes(tiles)
+
+	// Step 4c: Classify low-lying coastal land as beach
+	g.assignBeaches(tiles, seaLevel)
 
 	// Step 5: Generate rivers
 	g.generateRivers(tiles, elevationGrid, seaLevel)
 
+	// Step 5a: Number the river systems rivers join into
+	g.assignRiverSystems(tiles)
+
+	// Step 5b: Flood marshes by rivers and spring oases in arid country
+	g.placeMarshes(tiles, seaLevel)
+	g.placeOases(tiles)
+
 	// Step 6: Distribute resources
 	g.distributeResources(tiles, elevationGrid, seaLevel)
 
+	// Step 6a: Hollow rare caves into hills and mountains
+	g.placeCaves(tiles)
+
+	// Step 6b: Rate soil fertility
+	g.assignFertility(tiles, seaLevel, volcanic)
+
+	// Step 6c: Tag straits, isthmuses, harbors and island chains
+	features := append(g.detectFeatures(elevationGrid, seaLevel), tectonicFeatures...)
+
 	// Step 7: Find starting positions
 	playerIDs := make([]string, playerCount)
 	for i := 0; i < playerCount; i++ {
@@ -104,6 +141,9 @@ func (g *Generator) GenerateMap(ctx context.Context, gameID string, playerCount
 	// Step 8: Reveal starting areas for each player
 	g.revealStartingAreas(tiles, startingPositions)
 
+	// Step 9: Set aside sites for minor civilizations, one per player
+	minorCivSites := g.findMinorCivSites(tiles, startingPositions, playerCount)
+
 	// Create metadata
 	metadata := &models.MapMetadata{
 		GameID:           gameID,
@@ -112,7 +152,12 @@ func (g *Generator) GenerateMap(ctx context.Context, gameID string, playerCount
 		Height:           g.height,
 		PlayerCount:      playerCount,
 		SeaLevel:         seaLevel,
+		LandRatio:        landRatio,
 		GreatCircles:     greatCircles,
+		Plates:           plates,
+		Features:         features,
+		MinorCivSites:    minorCivSites,
+		Stats:            g.computeStats(tiles),
 		GeneratedAt:      time.Now(),
 		GenerationTimeMs: time.Since(startTime).Milliseconds(),
 	}
@@ -122,34 +167,35 @@ func (g *Generator) GenerateMap(ctx context.Context, gameID string, playerCount
 
 // generateGreatCircles creates great circles for terrain generation
 func (g *Generator) generateGreatCircles(playerCount int) []models.GreatCircle {
+	random := g.random(phaseTerrain)
 	numCircles := 8 + playerCount*2
 	circles := make([]models.GreatCircle, numCircles)
 
 	for i := 0; i < numCircles; i++ {
 		// Random point on sphere
-		lon := g.rng.Float64()*2*math.Pi - math.Pi
-		lat := math.Asin(g.rng.Float64()*2 - 1)
+		lon := random.Float64()*2*math.Pi - math.Pi
+		lat := math.Asin(random.Float64()*2 - 1)
 
 		// Random vector through center
-		theta := g.rng.Float64() * 2 * math.Pi
-		phi := math.Acos(g.rng.Float64()*2 - 1)
+		theta := random.Float64() * 2 * math.Pi
+		phi := math.Acos(random.Float64()*2 - 1)
 		vx := math.Sin(phi) * math.Cos(theta)
 		vy := math.Sin(phi) * math.Sin(theta)
 		vz := math.Cos(phi)
 
 		// Assign type based on distribution
-		roll := g.rng.Float64()
+		roll := random.Float64()
 		var circleType string
 		var heightModifier float64
 		if roll < 0.3 {
 			circleType = "continental_boundary"
-			heightModifier = g.rng.Float64()*1000 - 500 // -500 to +500m
+			heightModifier = random.Float64()*1000 - 500 // -500 to +500m
 		} else if roll < 0.7 {
 			circleType = "mountain_range"
-			heightModifier = g.rng.Float64()*2000 + 500 // 500 to 2500m
+			heightModifier = random.Float64()*2000 + 500 // 500 to 2500m
 		} else {
 			circleType = "ocean_trench"
-			heightModifier = g.rng.Float64()*-600 - 200 // -800 to -200m
+			heightModifier = random.Float64()*-600 - 200 // -800 to -200m
 		}
 
 		circles[i] = models.GreatCircle{
@@ -159,9 +205,9 @@ func (g *Generator) generateGreatCircles(playerCount int) []models.GreatCircle {
 			VectorY:        vy,
 			VectorZ:        vz,
 			Type:           circleType,
-			Radius:         g.rng.Float64()*8 + 4, // 4-12 tiles
+			Radius:         random.Float64()*8 + 4, // 4-12 tiles
 			HeightModifier: heightModifier,
-			Weight:         g.rng.Float64()*0.7 + 0.3, // 0.3-1.0
+			Weight:         random.Float64()*0.7 + 0.3, // 0.3-1.0
 		}
 	}
 
@@ -238,7 +284,10 @@ func (g *Generator) calculateNoise(x, y int) float64 {
 	return noise
 }
 
-// calculateSeaLevel determines the sea level threshold (use 35th percentile for more land)
+// calculateSeaLevel finds the sea level that leaves the target fraction of
+// tiles as land. It starts from the matching elevation percentile and, when
+// tied elevations leave that outside the tolerance, bisects the elevation
+// range, keeping the closest level found.
 func (g *Generator) calculateSeaLevel(elevationGrid [][]int) int {
 	// Collect all elevations
 	elevations := make([]int, 0, g.width*g.height)
@@ -251,13 +300,47 @@ func (g *Generator) calculateSeaLevel(elevationGrid [][]int) int {
 	// Sort using Go's built-in sort (much faster than insertion sort)
 	sort.Ints(elevations)
 
-	// Use 35th percentile instead of median for more land
-	percentileIndex := len(elevations) * 35 / 100
-	return elevations[percentileIndex]
+	target := g.options.LandRatio
+	landAt := func(level int) float64 {
+		return float64(len(elevations)-sort.SearchInts(elevations, level)) / float64(len(elevations))
+	}
+
+	waterTiles := len(elevations) - int(math.Round(float64(len(elevations))*target))
+	seaLevel := elevations[min(waterTiles, len(elevations)-1)]
+	best, bestMiss := seaLevel, math.Abs(landAt(seaLevel)-target)
+
+	// Land shrinks as the sea rises; search [low, high) for the target
+	low, high := elevations[0], elevations[len(elevations)-1]+1
+	for i := 0; i < g.options.SeaLevelIterations && bestMiss > g.options.LandRatioTolerance && low < high; i++ {
+		if landAt(seaLevel) > target {
+			low = seaLevel + 1
+		} else {
+			high = seaLevel
+		}
+		seaLevel = low + (high-low)/2
+		if miss := math.Abs(landAt(seaLevel) - target); miss < bestMiss {
+			best, bestMiss = seaLevel, miss
+		}
+	}
+	return best
+}
+
+// landRatio returns the fraction of tiles at or above sea level
+func (g *Generator) landRatio(elevationGrid [][]int, seaLevel int) float64 {
+	land := 0
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if elevationGrid[y][x] >= seaLevel {
+				land++
+			}
+		}
+	}
+	return float64(land) / float64(g.width*g.height)
 }
 
-// assignTerrainType assigns terrain type based on elevation and climate
-func (g *Generator) assignTerrainType(x, y, elevation, seaLevel int) string {
+// assignTerrainType assigns terrain type based on elevation and climate,
+// drawing any randomness from the row's stream
+func (g *Generator) assignTerrainType(x, y, elevation, seaLevel int, random *rng.Stream) string {
 	if elevation < seaLevel-20 {
 		return "OCEAN"
 	} else if elevation < seaLevel {
@@ -286,21 +369,21 @@ func (g *Generator) assignTerrainType(x, y, elevation, seaLevel int) string {
 		}
 		return "GRASSLAND"
 	} else if lat > 30 {
-		if g.rng.Float64() < 0.4 {
+		if random.Float64() < 0.4 {
 			return "GRASSLAND"
 		}
 		return "FOREST"
 	} else if lat > 15 {
-		if g.rng.Float64() < 0.3 {
+		if random.Float64() < 0.3 {
 			return "DESERT"
-		} else if g.rng.Float64() < 0.5 {
+		} else if random.Float64() < 0.5 {
 			return "PLAINS"
 		}
 		return "GRASSLAND"
 	} else {
-		if g.rng.Float64() < 0.3 {
+		if random.Float64() < 0.3 {
 			return "JUNGLE"
-		} else if g.rng.Float64() < 0.6 {
+		} else if random.Float64() < 0.6 {
 			return "FOREST"
 		}
 		return "GRASSLAND"
@@ -337,17 +420,9 @@ func (g *Generator) isCoastal(x, y int, elevationGrid [][]int, seaLevel int) boo
 	}
 
 	// Check adjacent tiles
-	for dy := -1; dy <= 1; dy++ {
-		for dx := -1; dx <= 1; dx++ {
-			if dx == 0 && dy == 0 {
-				continue
-			}
-			nx, ny := x+dx, y+dy
-			if nx >= 0 && nx < g.width && ny >= 0 && ny < g.height {
-				if elevationGrid[ny][nx] < seaLevel {
-					return true
-				}
-			}
+	for _, n := range g.mapGrid().Neighbors8(models.Location{X: x, Y: y}) {
+		if elevationGrid[n.Y][n.X] < seaLevel {
+			return true
 		}
 	}
 	return false
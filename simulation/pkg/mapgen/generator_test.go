@@ -3,7 +3,11 @@ package mapgen
 import (
 	"context"
 	"math"
+	"reflect"
 	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -110,6 +114,62 @@ func TestGenerateMap_Deterministic(t *testing.T) {
 	}
 }
 
+func TestGenerateMap_ParallelDeterministic(t *testing.T) {
+	// However the rows are shared among workers, the map is the same
+	serial := NewGenerator("parallel-test", 4)
+	serial.workers = 1
+	_, serialTiles, _, err := serial.GenerateMap(context.Background(), "game1", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+	parallel := NewGenerator("parallel-test", 4)
+	parallel.workers = 8
+	_, parallelTiles, _, err := parallel.GenerateMap(context.Background(), "game1", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+
+	for i, tile := range serialTiles {
+		other := parallelTiles[i]
+		if tile.X != other.X || tile.Y != other.Y || tile.Elevation != other.Elevation || tile.TerrainType != other.TerrainType ||
+			tile.ClimateZone != other.ClimateZone || tile.IsCoastal != other.IsCoastal || tile.HasRiver != other.HasRiver {
+			t.Fatalf("Expected tile %d to match, got %+v and %+v", i, tile, other)
+		}
+	}
+}
+
+func TestGenerateMap_PhaseStreams(t *testing.T) {
+	_, tiles, _, err := NewGenerator("phase-test", 2).GenerateMap(context.Background(), "game1", 2)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+
+	// Extra draws in the river phase leave terrain and caves alone
+	shifted := NewGenerator("phase-test", 2)
+	for i := 0; i < 10; i++ {
+		shifted.random(phaseRivers).Float64()
+	}
+	_, shiftedTiles, _, err := shifted.GenerateMap(context.Background(), "game1", 2)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+	for i, tile := range tiles {
+		other := shiftedTiles[i]
+		if tile.Elevation != other.Elevation || tile.TerrainType != other.TerrainType || tile.HasCave != other.HasCave {
+			t.Fatalf("Expected tile %d unchanged by river draws, got %+v and %+v", i, tile, other)
+		}
+	}
+}
+
+// BenchmarkGenerateMap generates the map of a large (16 player) game
+func BenchmarkGenerateMap(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := NewGenerator("benchmark", 16).GenerateMap(context.Background(), "game1", 16); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestGenerateMap_TerrainVariety(t *testing.T) {
 	gen := NewGenerator("variety-test", 4)
 	
@@ -281,3 +341,668 @@ func TestGenerateMap_TileVisibility(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateMap_SmoothCoastlinesAndBeaches(t *testing.T) {
+	gen := NewGenerator("test-seed-123", 4)
+	metadata, tiles, _, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+
+	isLand := func(x, y int) bool {
+		return tiles[y*metadata.Width+x].Elevation >= metadata.SeaLevel
+	}
+
+	// No interior single-tile islets or one-tile lakes should survive smoothing
+	jags := 0
+	for y := 1; y < metadata.Height-1; y++ {
+		for x := 1; x < metadata.Width-1; x++ {
+			same := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && isLand(x+dx, y+dy) == isLand(x, y) {
+						same++
+					}
+				}
+			}
+			if same == 0 {
+				jags++
+			}
+		}
+	}
+	if jags > 0 {
+		t.Errorf("Found %d isolated single-tile coastline jags after smoothing", jags)
+	}
+
+	beaches := 0
+	for _, tile := range tiles {
+		if tile.TerrainType == "BEACH" {
+			beaches++
+			if !tile.IsCoastal {
+				t.Errorf("Beach at (%d, %d) is not coastal", tile.X, tile.Y)
+			}
+		}
+	}
+	if beaches == 0 {
+		t.Error("Expected some beach tiles on a generated map")
+	}
+	t.Logf("Beach tiles: %d", beaches)
+}
+
+func TestDetectFeatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []string
+		feature string
+		x, y    int
+		size    int
+	}{
+		{
+			name: "strait",
+			rows: []string{
+				".........",
+				".........",
+				"####.####",
+				"####.####",
+				"####.####",
+				".........",
+				".........",
+			},
+			feature: models.FeatureStrait, x: 4, y: 2, size: 3,
+		},
+		{
+			name: "isthmus",
+			rows: []string{
+				"###.....###",
+				"###.....###",
+				"###########",
+				"###.....###",
+				"###.....###",
+			},
+			feature: models.FeatureIsthmus, x: 3, y: 2, size: 5,
+		},
+		{
+			name: "island chain",
+			rows: []string{
+				"..........",
+				".#..#..#..",
+				"..........",
+			},
+			feature: models.FeatureIslandChain, x: 1, y: 1, size: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &Generator{width: len(tt.rows[0]), height: len(tt.rows)}
+			grid := make([][]int, len(tt.rows))
+			for y, row := range tt.rows {
+				grid[y] = make([]int, len(row))
+				for x, c := range row {
+					if c == '#' {
+						grid[y][x] = 100
+					}
+				}
+			}
+
+			found := false
+			for _, f := range gen.detectFeatures(grid, 50) {
+				if f.Type == tt.feature {
+					if f.X != tt.x || f.Y != tt.y || f.Size != tt.size {
+						t.Errorf("Got %s at (%d, %d) size %d, want (%d, %d) size %d", f.Type, f.X, f.Y, f.Size, tt.x, tt.y, tt.size)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected a %s feature", tt.feature)
+			}
+		})
+	}
+
+	// A generated map tags its features on tiles of the right kind, the same
+	// way every time for a seed
+	gen := NewGenerator("test-seed-123", 4)
+	metadata, tiles, _, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, f := range metadata.Features {
+		counts[f.Type]++
+		if f.X < 0 || f.X >= metadata.Width || f.Y < 0 || f.Y >= metadata.Height || f.Size < 1 {
+			t.Errorf("Feature %s at (%d, %d) size %d lies off the map or is empty", f.Type, f.X, f.Y, f.Size)
+			continue
+		}
+		water := terrain.IsWater(getTile(tiles, f.X, f.Y, metadata.Width).TerrainType)
+		switch f.Type {
+		case models.FeatureStrait:
+			if !water {
+				t.Errorf("Strait at (%d, %d) is on land", f.X, f.Y)
+			}
+		case models.FeatureIsthmus, models.FeatureHarbor, models.FeatureIslandChain:
+			if water {
+				t.Errorf("%s at (%d, %d) is on water", f.Type, f.X, f.Y)
+			}
+		}
+	}
+	if counts[models.FeatureHarbor] == 0 {
+		t.Errorf("Expected the map's coasts to have harbors, got %v", counts)
+	}
+
+	again, _, _, err := NewGenerator("test-seed-123", 4).GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+	if !reflect.DeepEqual(again.Features, metadata.Features) {
+		t.Error("Expected the same seed to tag the same features")
+	}
+}
+
+func TestBlendBiomes(t *testing.T) {
+	rows := [][]string{
+		{"DESERT", "DESERT", "GRASSLAND", "GRASSLAND"},
+		{"TUNDRA", "TUNDRA", "TUNDRA", "FOREST"},
+	}
+	gen := &Generator{width: 4, height: 2}
+	var tiles []*models.MapTile
+	for y, row := range rows {
+		for x, terrainType := range row {
+			tiles = append(tiles, &models.MapTile{X: x, Y: y, TerrainType: terrainType})
+		}
+	}
+
+	gen.blendBiomes(tiles)
+
+	want := []string{
+		"DESERT", "SAVANNA", "GRASSLAND", "GRASSLAND",
+		"TUNDRA", "TAIGA", "TAIGA", "FOREST",
+	}
+	for i, tile := range tiles {
+		if tile.TerrainType != want[i] {
+			t.Errorf("Tile (%d, %d) = %s, want %s", tile.X, tile.Y, tile.TerrainType, want[i])
+		}
+	}
+}
+
+func TestReserveRegion(t *testing.T) {
+	gen := NewGenerator("test-seed-123", 4)
+	metadata, tiles, positions, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+
+	// The first civ owns its whole starting region
+	owner := "p1"
+	first := positions[0]
+	for _, tile := range tiles {
+		if abs(tile.X-first.CenterX) <= 7 && abs(tile.Y-first.CenterY) <= 7 {
+			tile.OwnerID = &owner
+		}
+	}
+
+	var occupied []models.Location
+	for _, pos := range positions {
+		occupied = append(occupied, models.Location{X: pos.CenterX, Y: pos.CenterY})
+	}
+	position := ReserveRegion(metadata, tiles, occupied, "late")
+	if position == nil {
+		t.Fatal("Expected a viable region for the late joiner")
+	}
+	if position.PlayerID != "late" || position.GameID != "test-game" {
+		t.Errorf("Unexpected position identity: %+v", position)
+	}
+	if abs(position.CenterX-first.CenterX) <= 14 && abs(position.CenterY-first.CenterY) <= 14 {
+		t.Errorf("Reserved region at (%d, %d) overlaps the owned region at (%d, %d)",
+			position.CenterX, position.CenterY, first.CenterX, first.CenterY)
+	}
+	for _, loc := range occupied {
+		if loc.X == position.CenterX && loc.Y == position.CenterY {
+			t.Errorf("Reserved an occupied region at (%d, %d)", loc.X, loc.Y)
+		}
+	}
+}
+
+func TestGenerateMap_MinorCivSites(t *testing.T) {
+	gen := NewGenerator("test-seed-123", 4)
+	metadata, tiles, positions, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+	sites := metadata.MinorCivSites
+	if len(sites) == 0 || len(sites) > 4 {
+		t.Fatalf("Expected one to four minor civ sites, got %d", len(sites))
+	}
+
+	taken := make([]models.Location, 0, len(positions))
+	for _, pos := range positions {
+		taken = append(taken, models.Location{X: pos.CenterX, Y: pos.CenterY})
+	}
+	for _, site := range sites {
+		if tile := getTile(tiles, site.X, site.Y, metadata.Width); terrain.IsWater(tile.TerrainType) {
+			t.Errorf("Minor civ site (%d, %d) is on water", site.X, site.Y)
+		}
+		if score := gen.scoreStartingRegion(tiles, site.X, site.Y); score > 50 {
+			t.Errorf("Minor civ site (%d, %d) is in a region fit for a player start, scoring %.1f", site.X, site.Y, score)
+		}
+		for _, loc := range taken {
+			dx, dy := float64(site.X-loc.X), float64(site.Y-loc.Y)
+			if math.Sqrt(dx*dx+dy*dy) < minorCivSpacing {
+				t.Errorf("Minor civ site (%d, %d) is crowding (%d, %d)", site.X, site.Y, loc.X, loc.Y)
+			}
+		}
+		taken = append(taken, site)
+	}
+}
+
+func TestGenerateMap_Stats(t *testing.T) {
+	gen := NewGenerator("variety-test", 4)
+
+	metadata, tiles, _, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+	stats := metadata.Stats
+	if stats == nil {
+		t.Fatal("Expected metadata to carry map statistics")
+	}
+
+	total := 0
+	for _, count := range stats.TerrainCounts {
+		total += count
+	}
+	if total != len(tiles) {
+		t.Errorf("Expected terrain counts to cover %d tiles, got %d", len(tiles), total)
+	}
+	if stats.LandPercentage <= 0 || stats.LandPercentage >= 100 {
+		t.Errorf("Expected a mix of land and water, got %.1f%% land", stats.LandPercentage)
+	}
+	if len(stats.ResourceCounts) == 0 {
+		t.Error("Expected resource counts")
+	}
+	if stats.RiverTiles > 0 && stats.RiverSystems == 0 {
+		t.Errorf("Expected %d river tiles to form river systems", stats.RiverTiles)
+	}
+	if stats.ContinentCount == 0 || stats.LargestContinent == 0 {
+		t.Errorf("Expected at least one continent, got %d (largest %d)", stats.ContinentCount, stats.LargestContinent)
+	}
+	land := int(math.Round(stats.LandPercentage * float64(len(tiles)) / 100))
+	if stats.LargestContinent > land {
+		t.Errorf("Largest continent %d exceeds %d land tiles", stats.LargestContinent, land)
+	}
+
+	// Two landmasses touching only diagonally are one continent
+	small := &Generator{width: 4, height: 3}
+	grid := []string{
+		"LWWW",
+		"WLWL",
+		"WWWW",
+	}
+	var smallTiles []*models.MapTile
+	for y, row := range grid {
+		for x, c := range row {
+			tile := &models.MapTile{X: x, Y: y, TerrainType: terrain.Ocean}
+			if c == 'L' {
+				tile.TerrainType = terrain.Grassland
+				tile.HasRiver = x == 0
+			}
+			smallTiles = append(smallTiles, tile)
+		}
+	}
+	smallStats := small.computeStats(smallTiles)
+	if smallStats.ContinentCount != 2 || smallStats.LargestContinent != 2 {
+		t.Errorf("Expected 2 continents, largest 2, got %d, largest %d", smallStats.ContinentCount, smallStats.LargestContinent)
+	}
+	if smallStats.RiverTiles != 1 || smallStats.TerrainCounts[terrain.Grassland] != 3 {
+		t.Errorf("Unexpected counts: %+v", smallStats)
+	}
+	if smallStats.LandPercentage != 25 {
+		t.Errorf("Expected 25%% land, got %.1f", smallStats.LandPercentage)
+	}
+}
+
+func TestGenerateMap_LandRatio(t *testing.T) {
+	for _, target := range []float64{0.3, 0.65, 0.8} {
+		opts := DefaultMapOptions()
+		opts.LandRatio = target
+		gen := NewGeneratorWithOptions("variety-test", 4, opts)
+
+		metadata, _, _, err := gen.GenerateMap(context.Background(), "test-game", 4)
+		if err != nil {
+			t.Fatalf("GenerateMap failed: %v", err)
+		}
+		// Coastline smoothing may move a few tiles after the solver runs
+		if math.Abs(metadata.LandRatio-target) > 0.03 {
+			t.Errorf("Target land ratio %.2f, achieved %.3f", target, metadata.LandRatio)
+		}
+	}
+
+	// Heavily tied elevations defeat the percentile guess; the solver still
+	// finds a level within tolerance
+	gen := &Generator{width: 10, height: 10, options: MapOptions{LandRatio: 0.5, LandRatioTolerance: 0.05}.normalized()}
+	grid := make([][]int, gen.height)
+	for y := range grid {
+		grid[y] = make([]int, gen.width)
+		for x := range grid[y] {
+			if y >= 3 {
+				grid[y][x] = 100 + y*10 // Rows 0-2 share one elevation
+			}
+		}
+	}
+	seaLevel := gen.calculateSeaLevel(grid)
+	if ratio := gen.landRatio(grid, seaLevel); math.Abs(ratio-0.5) > 0.05 {
+		t.Errorf("Expected about half land, got %.2f at sea level %d", ratio, seaLevel)
+	}
+}
+
+func TestApplyTectonics(t *testing.T) {
+	flat := func(g *Generator) [][]int {
+		grid := make([][]int, g.height)
+		for y := range grid {
+			grid[y] = make([]int, g.width)
+			for x := range grid[y] {
+				grid[y][x] = 500
+			}
+		}
+		return grid
+	}
+	gen := &Generator{width: 12, height: 8}
+
+	// Two continents driving into each other raise an unbroken range along
+	// their whole shared boundary
+	colliding := []models.TectonicPlate{
+		{ID: 0, SeedX: 2, SeedY: 4, Continental: true, DriftX: 1},
+		{ID: 1, SeedX: 9, SeedY: 4, Continental: true, DriftX: -1},
+	}
+	grid := flat(gen)
+	features, _ := gen.applyTectonics(grid, colliding)
+	if len(features) != 1 || features[0].Type != models.FeatureMountainRange || features[0].Size != 2*gen.height {
+		t.Fatalf("Expected one range spanning the map, got %+v", features)
+	}
+	for y := 0; y < gen.height; y++ {
+		if grid[y][5] < 2000 || grid[y][6] < 2000 {
+			t.Errorf("Row %d: expected peaks at the boundary, got %d and %d", y, grid[y][5], grid[y][6])
+		}
+		if grid[y][0] != 500+continentalShelf {
+			t.Errorf("Row %d: expected the plate interior untouched beyond the shelf, got %d", y, grid[y][0])
+		}
+	}
+
+	// The same continents pulling apart open a rift
+	separating := []models.TectonicPlate{
+		{ID: 0, SeedX: 2, SeedY: 4, Continental: true, DriftX: -1},
+		{ID: 1, SeedX: 9, SeedY: 4, Continental: true, DriftX: 1},
+	}
+	grid = flat(gen)
+	features, _ = gen.applyTectonics(grid, separating)
+	if len(features) != 1 || features[0].Type != models.FeatureRiftValley {
+		t.Fatalf("Expected a rift valley, got %+v", features)
+	}
+	if grid[0][5] >= grid[0][0] {
+		t.Errorf("Expected the rift below the plate interior, got %d vs %d", grid[0][5], grid[0][0])
+	}
+
+	// An ocean plate sinking under a continent raises an arc on the
+	// continent and a trench on the ocean side
+	subducting := []models.TectonicPlate{
+		{ID: 0, SeedX: 2, SeedY: 4, Continental: false, DriftX: 1},
+		{ID: 1, SeedX: 9, SeedY: 4, Continental: true},
+	}
+	grid = flat(gen)
+	features, volcanic := gen.applyTectonics(grid, subducting)
+	if len(features) != 1 || features[0].Type != models.FeatureVolcanicArc || features[0].X != 6 {
+		t.Fatalf("Expected a volcanic arc on the continental side, got %+v", features)
+	}
+	if grid[0][5] >= 500-continentalShelf {
+		t.Errorf("Expected a trench on the oceanic side, got %d", grid[0][5])
+	}
+	if volcanic[0][5] || !volcanic[0][6] || !volcanic[0][6+tectonicReach] || volcanic[0][7+tectonicReach] {
+		t.Errorf("Expected ash across the arc's reach on the continental side, got %v", volcanic[0])
+	}
+}
+
+func TestGenerateMap_Caves(t *testing.T) {
+	gen := NewGenerator("variety-test", 4)
+
+	metadata, tiles, _, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+
+	caves := 0
+	for _, tile := range tiles {
+		if !tile.HasCave {
+			continue
+		}
+		caves++
+		if tile.TerrainType != terrain.Hills && tile.TerrainType != terrain.Mountain {
+			t.Errorf("Cave at (%d, %d) on %s", tile.X, tile.Y, tile.TerrainType)
+		}
+		toolstone := 0
+		for _, resource := range tile.Resources {
+			if resource == "FLINT" || resource == "OBSIDIAN" {
+				toolstone++
+				if tile.ResourceQuantities[resource] != terrain.ResourceInfos[resource].Quantity {
+					t.Errorf("Cave at (%d, %d) has %d %s", tile.X, tile.Y, tile.ResourceQuantities[resource], resource)
+				}
+			}
+		}
+		if toolstone != 1 {
+			t.Errorf("Expected one toolstone deposit in the cave at (%d, %d), got %v", tile.X, tile.Y, tile.Resources)
+		}
+	}
+	if caves == 0 {
+		t.Fatal("Expected some caves")
+	}
+	if caves != metadata.Stats.CaveTiles {
+		t.Errorf("Stats count %d caves, found %d", metadata.Stats.CaveTiles, caves)
+	}
+	if caves*10 > metadata.Stats.TerrainCounts[terrain.Hills]+metadata.Stats.TerrainCounts[terrain.Mountain] {
+		t.Errorf("Expected caves to be rare, got %d", caves)
+	}
+}
+
+func TestGenerateMap_Fertility(t *testing.T) {
+	gen := NewGenerator("variety-test", 4)
+
+	metadata, tiles, _, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+
+	var riverSoil, drySoil float64
+	var rivers, dry int
+	for _, tile := range tiles {
+		if terrain.IsWater(tile.TerrainType) {
+			if tile.Fertility != 0 {
+				t.Errorf("Water at (%d, %d) has soil %.2f", tile.X, tile.Y, tile.Fertility)
+			}
+			continue
+		}
+		if tile.Fertility < minFertility || tile.Fertility > maxFertility || tile.Fertility != tile.NaturalFertility {
+			t.Fatalf("Land at (%d, %d) has soil %.2f (natural %.2f)", tile.X, tile.Y, tile.Fertility, tile.NaturalFertility)
+		}
+		if tile.TerrainType != terrain.Grassland {
+			continue
+		}
+		if tile.HasRiver {
+			riverSoil += tile.Fertility
+			rivers++
+		} else {
+			drySoil += tile.Fertility
+			dry++
+		}
+	}
+	if rivers == 0 || dry == 0 {
+		t.Fatal("Expected grassland both on and off rivers")
+	}
+	if riverSoil/float64(rivers) <= drySoil/float64(dry) {
+		t.Errorf("Expected river grassland richer than dry grassland, got %.2f vs %.2f", riverSoil/float64(rivers), drySoil/float64(dry))
+	}
+	if metadata.Stats.AverageFertility <= 0 {
+		t.Error("Expected the stats to report average fertility")
+	}
+}
+
+func TestAssignRiverSystems(t *testing.T) {
+	gen := &Generator{width: 6, height: 3}
+	grid := []string{
+		"RR.R..",
+		"..R.WR",
+		"......",
+	}
+	var tiles []*models.MapTile
+	for y, row := range grid {
+		for x, c := range row {
+			tile := &models.MapTile{X: x, Y: y, TerrainType: terrain.Grassland, HasRiver: c == 'R' || c == 'W'}
+			if c == 'W' {
+				tile.TerrainType = terrain.Ocean
+			}
+			tiles = append(tiles, tile)
+		}
+	}
+
+	if systems := gen.assignRiverSystems(tiles); systems != 2 {
+		t.Fatalf("Expected two river systems, got %d", systems)
+	}
+	at := func(x, y int) int { return tiles[y*gen.width+x].RiverID }
+	if at(0, 0) != 1 || at(1, 0) != 1 || at(2, 1) != 1 || at(3, 0) != 1 {
+		t.Errorf("Expected the diagonal network to be system 1, got %d %d %d %d", at(0, 0), at(1, 0), at(2, 1), at(3, 0))
+	}
+	if at(5, 1) != 2 || at(4, 1) != 0 || at(0, 2) != 0 {
+		t.Errorf("Expected a second system past the river mouth, got %d (mouth %d)", at(5, 1), at(4, 1))
+	}
+}
+
+func TestPlaceMarshes(t *testing.T) {
+	gen := &Generator{width: 8, height: 3}
+	// W sea, R river grassland, g grassland, . plains, h high plains, T river tundra
+	grid := []string{
+		"WR..hggg",
+		"WR..hggg",
+		"WT..h...",
+	}
+	// M marsh; other tiles keep their terrain
+	want := []string{
+		"WMM.hgMM",
+		"WMM.hgMM",
+		"WTM.h...",
+	}
+	var tiles []*models.MapTile
+	for y, row := range grid {
+		for x, c := range row {
+			tile := &models.MapTile{X: x, Y: y, TerrainType: terrain.Plains, Elevation: 5, HasRiver: c == 'R' || c == 'T'}
+			switch c {
+			case 'W':
+				tile.TerrainType, tile.Elevation = terrain.Ocean, -10
+			case 'R', 'g':
+				tile.TerrainType = terrain.Grassland
+			case 'T':
+				tile.TerrainType = terrain.Tundra
+			case 'h':
+				tile.Elevation = marshMaxElevation + 50
+			}
+			tiles = append(tiles, tile)
+		}
+	}
+
+	gen.placeMarshes(tiles, 0)
+	for y, row := range want {
+		for x, c := range row {
+			tile := tiles[y*gen.width+x]
+			if (c == 'M') != (tile.TerrainType == terrain.Marsh) {
+				t.Errorf("(%d, %d): expected %c, got %s", x, y, c, tile.TerrainType)
+			}
+		}
+	}
+}
+
+func TestPlaceOases(t *testing.T) {
+	newTiles := func() []*models.MapTile {
+		var tiles []*models.MapTile
+		for y := 0; y < 12; y++ {
+			for x := 0; x < 12; x++ {
+				tile := &models.MapTile{X: x, Y: y, TerrainType: terrain.Desert}
+				if y == 11 {
+					tile.TerrainType = terrain.Tundra
+				}
+				tile.HasRiver = x == 1 && y == 1
+				tiles = append(tiles, tile)
+			}
+		}
+		return tiles
+	}
+	place := func() []*models.MapTile {
+		gen := &Generator{width: 12, height: 12, streams: newPhaseStreams("oases")}
+		tiles := newTiles()
+		gen.placeOases(tiles)
+		return tiles
+	}
+
+	tiles := place()
+	var oases []models.Location
+	for _, tile := range tiles {
+		if tile.TerrainType != terrain.Oasis {
+			continue
+		}
+		oases = append(oases, models.Location{X: tile.X, Y: tile.Y})
+		if tile.X <= 1+oasisDryRadius && tile.Y <= 1+oasisDryRadius {
+			t.Errorf("Oasis at (%d, %d) within reach of the river", tile.X, tile.Y)
+		}
+	}
+	// 11 desert rows less the 4x4 corner by the river
+	candidates := 12*11 - 16
+	if want := int(float64(candidates) * oasisDensity); len(oases) != want {
+		t.Fatalf("Expected %d oases, got %v", want, oases)
+	}
+
+	again := place()
+	for _, loc := range oases {
+		if again[loc.Y*12+loc.X].TerrainType != terrain.Oasis {
+			t.Errorf("Expected the same seed to place an oasis at %v again", loc)
+		}
+	}
+}
+
+func TestGenerateMap_Wetlands(t *testing.T) {
+	gen := NewGenerator("abc", 4)
+
+	metadata, tiles, _, err := gen.GenerateMap(context.Background(), "test-game", 4)
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+
+	suitable := map[string]map[string]bool{
+		terrain.Marsh: {"GAME": true},
+		terrain.Oasis: {"WHEAT": true},
+	}
+	counts := map[string]int{}
+	for _, tile := range tiles {
+		allowed, ok := suitable[tile.TerrainType]
+		if !ok {
+			continue
+		}
+		counts[tile.TerrainType]++
+		if tile.TerrainType == terrain.Marsh && tile.Elevation-metadata.SeaLevel > marshMaxElevation {
+			t.Errorf("Marsh at (%d, %d) %dm above the sea", tile.X, tile.Y, tile.Elevation-metadata.SeaLevel)
+		}
+		for _, resource := range tile.Resources {
+			if !allowed[resource] {
+				t.Errorf("%s on %s at (%d, %d)", resource, tile.TerrainType, tile.X, tile.Y)
+			}
+		}
+	}
+	if counts[terrain.Marsh] == 0 || counts[terrain.Oasis] == 0 {
+		t.Fatalf("Expected marshes and oases, got %v", counts)
+	}
+	if counts[terrain.Oasis] > metadata.Stats.TerrainCounts[terrain.Savanna]/10 {
+		t.Errorf("Expected oases to be rare, got %d", counts[terrain.Oasis])
+	}
+}
@@ -0,0 +1,215 @@
+package mapgen
+
+import (
+	"sort"
+
+	"github.com/anicolao/simciv/simulation/pkg/grid"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// GeoJSON layers, recorded in each feature's "layer" property
+const (
+	GeoJSONLayerTile   = "tile"
+	GeoJSONLayerRiver  = "river"
+	GeoJSONLayerBorder = "border"
+)
+
+// FeatureCollection is a GeoJSON (RFC 7946) feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry; Coordinates nest according to Type
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Position is a GeoJSON [longitude, latitude] pair
+type Position [2]float64
+
+// ExportGeoJSON converts a map into a GeoJSON feature collection: a polygon
+// per tile carrying its terrain, a line layer tracing rivers downhill, and a
+// line layer per player outlining their territory. Tile coordinates are
+// projected onto longitude and latitude the same way the generator places
+// tiles on the globe, so the output opens directly in GIS tools.
+func ExportGeoJSON(metadata *models.MapMetadata, tiles []*models.MapTile) *FeatureCollection {
+	tileAt := make(map[models.Location]*models.MapTile, len(tiles))
+	for _, tile := range tiles {
+		tileAt[models.Location{X: tile.X, Y: tile.Y}] = tile
+	}
+
+	sorted := append([]*models.MapTile(nil), tiles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+
+	project := func(x, y float64) Position {
+		return Position{
+			(x/float64(metadata.Width) - 0.5) * 360,
+			(y/float64(metadata.Height) - 0.5) * 180,
+		}
+	}
+
+	collection := &FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(tiles)+1)}
+	for _, tile := range sorted {
+		collection.Features = append(collection.Features, tileFeature(tile, project))
+	}
+	if rivers := riverSegments(sorted, tileAt); len(rivers) > 0 {
+		collection.Features = append(collection.Features, lineFeature(rivers, project, map[string]any{
+			"layer": GeoJSONLayerRiver,
+		}))
+	}
+	for _, border := range borderSegments(sorted, tileAt) {
+		collection.Features = append(collection.Features, lineFeature(border.segments, project, map[string]any{
+			"layer":   GeoJSONLayerBorder,
+			"ownerId": border.ownerID,
+		}))
+	}
+	return collection
+}
+
+// tileFeature outlines a tile as a polygon carrying its attributes
+func tileFeature(tile *models.MapTile, project func(x, y float64) Position) Feature {
+	x, y := float64(tile.X), float64(tile.Y)
+	ring := []Position{project(x, y), project(x+1, y), project(x+1, y+1), project(x, y+1), project(x, y)}
+
+	properties := map[string]any{
+		"layer":       GeoJSONLayerTile,
+		"x":           tile.X,
+		"y":           tile.Y,
+		"terrainType": tile.TerrainType,
+		"climateZone": tile.ClimateZone,
+		"elevation":   tile.Elevation,
+		"hasRiver":    tile.HasRiver,
+		"isCoastal":   tile.IsCoastal,
+	}
+	if tile.RiverID != 0 {
+		properties["riverId"] = tile.RiverID
+	}
+	if tile.HasCave {
+		properties["hasCave"] = true
+	}
+	if tile.Fertility > 0 {
+		properties["fertility"] = tile.Fertility
+	}
+	if len(tile.Resources) > 0 {
+		properties["resources"] = tile.Resources
+	}
+	if len(tile.Improvements) > 0 {
+		properties["improvements"] = tile.Improvements
+	}
+	if tile.OwnerID != nil {
+		properties["ownerId"] = *tile.OwnerID
+	}
+
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Polygon", Coordinates: [][]Position{ring}},
+		Properties: properties,
+	}
+}
+
+// segment is a line between two points in tile coordinates
+type segment [2][2]float64
+
+// lineFeature joins segments into a MultiLineString feature
+func lineFeature(segments []segment, project func(x, y float64) Position, properties map[string]any) Feature {
+	lines := make([][]Position, len(segments))
+	for i, s := range segments {
+		lines[i] = []Position{project(s[0][0], s[0][1]), project(s[1][0], s[1][1])}
+	}
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "MultiLineString", Coordinates: lines},
+		Properties: properties,
+	}
+}
+
+// riverSegments links each river tile's center to the lowest neighbor it
+// drains into, as the generator traced it, when that neighbor carries the
+// river on or is the water it empties into
+func riverSegments(tiles []*models.MapTile, tileAt map[models.Location]*models.MapTile) []segment {
+	var segments []segment
+	for _, tile := range tiles {
+		if !tile.HasRiver || terrain.IsWater(tile.TerrainType) {
+			continue
+		}
+
+		var next *models.MapTile
+		for _, loc := range grid.Unbounded.Neighbors8(models.Location{X: tile.X, Y: tile.Y}) {
+			neighbor := tileAt[loc]
+			if neighbor == nil {
+				continue
+			}
+			if neighbor.Elevation < tile.Elevation && (next == nil || neighbor.Elevation < next.Elevation) {
+				next = neighbor
+			}
+		}
+		if next == nil || !(next.HasRiver || terrain.IsWater(next.TerrainType)) {
+			continue
+		}
+		segments = append(segments, segment{
+			{float64(tile.X) + 0.5, float64(tile.Y) + 0.5},
+			{float64(next.X) + 0.5, float64(next.Y) + 0.5},
+		})
+	}
+	return segments
+}
+
+// ownerBorder is the outline of one player's territory
+type ownerBorder struct {
+	ownerID  string
+	segments []segment
+}
+
+// borderSegments collects, per owner, the tile edges that separate the
+// owner's tiles from tiles they do not own, ordered by owner ID
+func borderSegments(tiles []*models.MapTile, tileAt map[models.Location]*models.MapTile) []ownerBorder {
+	ownerOf := func(x, y int) string {
+		if tile := tileAt[models.Location{X: x, Y: y}]; tile != nil && tile.OwnerID != nil {
+			return *tile.OwnerID
+		}
+		return ""
+	}
+
+	byOwner := make(map[string][]segment)
+	for _, tile := range tiles {
+		if tile.OwnerID == nil {
+			continue
+		}
+		owner := *tile.OwnerID
+		x, y := float64(tile.X), float64(tile.Y)
+		if ownerOf(tile.X, tile.Y-1) != owner {
+			byOwner[owner] = append(byOwner[owner], segment{{x, y}, {x + 1, y}})
+		}
+		if ownerOf(tile.X+1, tile.Y) != owner {
+			byOwner[owner] = append(byOwner[owner], segment{{x + 1, y}, {x + 1, y + 1}})
+		}
+		if ownerOf(tile.X, tile.Y+1) != owner {
+			byOwner[owner] = append(byOwner[owner], segment{{x + 1, y + 1}, {x, y + 1}})
+		}
+		if ownerOf(tile.X-1, tile.Y) != owner {
+			byOwner[owner] = append(byOwner[owner], segment{{x, y + 1}, {x, y}})
+		}
+	}
+
+	borders := make([]ownerBorder, 0, len(byOwner))
+	for owner, segments := range byOwner {
+		borders = append(borders, ownerBorder{ownerID: owner, segments: segments})
+	}
+	sort.Slice(borders, func(i, j int) bool { return borders[i].ownerID < borders[j].ownerID })
+	return borders
+}
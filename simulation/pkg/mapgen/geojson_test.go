@@ -0,0 +1,74 @@
+package mapgen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestExportGeoJSON(t *testing.T) {
+	metadata := &models.MapMetadata{GameID: "game1", Width: 4, Height: 2}
+	p1 := "p1"
+	var tiles []*models.MapTile
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			tile := &models.MapTile{GameID: "game1", X: x, Y: y, TerrainType: "GRASSLAND", Elevation: 100 - 10*x}
+			if x == 3 {
+				tile.TerrainType = "OCEAN"
+			}
+			tiles = append(tiles, tile)
+		}
+	}
+	// A river runs east along the top row into the ocean; p1 owns two tiles
+	tiles[1].HasRiver, tiles[2].HasRiver = true, true
+	tiles[0].OwnerID, tiles[1].OwnerID = &p1, &p1
+
+	collection := ExportGeoJSON(metadata, tiles)
+
+	layers := make(map[string][]Feature)
+	for _, feature := range collection.Features {
+		layer := feature.Properties["layer"].(string)
+		layers[layer] = append(layers[layer], feature)
+	}
+	if len(layers[GeoJSONLayerTile]) != 8 {
+		t.Errorf("Expected 8 tile features, got %d", len(layers[GeoJSONLayerTile]))
+	}
+
+	// The first tile spans a quarter of the longitudes and half the latitudes
+	ring := layers[GeoJSONLayerTile][0].Geometry.Coordinates.([][]Position)[0]
+	if ring[0] != (Position{-180, -90}) || ring[2] != (Position{-90, 0}) {
+		t.Errorf("Unexpected projection of tile (0, 0): %v", ring)
+	}
+
+	if len(layers[GeoJSONLayerRiver]) != 1 {
+		t.Fatalf("Expected one river feature, got %d", len(layers[GeoJSONLayerRiver]))
+	}
+	if lines := layers[GeoJSONLayerRiver][0].Geometry.Coordinates.([][]Position); len(lines) != 2 {
+		t.Errorf("Expected the river to run through 2 segments to the sea, got %d", len(lines))
+	}
+
+	borders := layers[GeoJSONLayerBorder]
+	if len(borders) != 1 || borders[0].Properties["ownerId"] != "p1" {
+		t.Fatalf("Expected one border for p1, got %+v", borders)
+	}
+	if lines := borders[0].Geometry.Coordinates.([][]Position); len(lines) != 6 {
+		t.Errorf("Expected a 2x1 territory to have 6 border edges, got %d", len(lines))
+	}
+
+	data, err := json.Marshal(collection)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type string `json:"type"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Type != "FeatureCollection" || decoded.Features[0].Geometry.Type != "Polygon" {
+		t.Errorf("Expected valid GeoJSON, got %s (%v)", data[:min(len(data), 120)], err)
+	}
+}
@@ -0,0 +1,33 @@
+package mapgen
+
+// MapOptions tunes map generation
+type MapOptions struct {
+	LandRatio          float64 // Target fraction of tiles at or above sea level
+	LandRatioTolerance float64 // How far the achieved fraction may miss the target
+	SeaLevelIterations int     // Cap on the sea level solver's refinement steps
+}
+
+// DefaultMapOptions returns the options maps are generated with unless told
+// otherwise: roughly two thirds land
+func DefaultMapOptions() MapOptions {
+	return MapOptions{
+		LandRatio:          0.65,
+		LandRatioTolerance: 0.01,
+		SeaLevelIterations: 32,
+	}
+}
+
+// normalized fills unset or out-of-range options with their defaults
+func (o MapOptions) normalized() MapOptions {
+	defaults := DefaultMapOptions()
+	if o.LandRatio <= 0 || o.LandRatio > 1 {
+		o.LandRatio = defaults.LandRatio
+	}
+	if o.LandRatioTolerance <= 0 {
+		o.LandRatioTolerance = defaults.LandRatioTolerance
+	}
+	if o.SeaLevelIterations <= 0 {
+		o.SeaLevelIterations = defaults.SeaLevelIterations
+	}
+	return o
+}
@@ -0,0 +1,34 @@
+package mapgen
+
+import "sync"
+
+// forEachRow calls fn once for every row of the map, spreading the rows over
+// the generator's workers. fn may only write to its own row, so the result
+// does not depend on how many workers there are or how they interleave.
+func (g *Generator) forEachRow(fn func(y int)) {
+	workers := min(max(g.workers, 1), g.height)
+	if workers <= 1 {
+		for y := 0; y < g.height; y++ {
+			fn(y)
+		}
+		return
+	}
+
+	rows := make(chan int, g.height)
+	for y := 0; y < g.height; y++ {
+		rows <- y
+	}
+	close(rows)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				fn(y)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -2,29 +2,36 @@ package mapgen
 
 import (
 	"math"
+	"sort"
 
 	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
 )
 
 // findStartingPositions finds fair starting positions for all players
 func (g *Generator) findStartingPositions(tiles []*models.MapTile, playerIDs []string, elevationGrid [][]int, seaLevel int) []*models.StartingPosition {
 	// Step 1: Identify candidate starting regions
-	candidates := g.findCandidateRegions(tiles, elevationGrid, seaLevel)
+	candidates := g.findCandidateRegions(tiles)
 
 	if len(candidates) < len(playerIDs) {
 		// Not enough candidates, use what we have
 		for len(candidates) < len(playerIDs) {
 			// Add any land tile as fallback
+			added := false
 			for _, tile := range tiles {
-				if tile.TerrainType != "OCEAN" && tile.TerrainType != "SHALLOW_WATER" {
+				if !terrain.IsWater(tile.TerrainType) {
 					candidates = append(candidates, &candidateRegion{
 						centerX: tile.X,
 						centerY: tile.Y,
 						score:   10.0,
 					})
+					added = true
 					break
 				}
 			}
+			if !added {
+				break // An all-water map has nowhere to start
+			}
 		}
 	}
 
@@ -109,6 +116,76 @@ func (g *Generator) findStartingPositions(tiles []*models.MapTile, playerIDs []s
 	return selectedPositions
 }
 
+// ReserveRegion picks a starting position for a player joining a game that
+// is already under way. Candidate regions are scored on the current map as at
+// game start; regions containing owned tiles are skipped and the rest are
+// weighted by distance to the nearest occupied location. It returns nil when
+// no viable region is left.
+func ReserveRegion(metadata *models.MapMetadata, tiles []*models.MapTile, occupied []models.Location, playerID string) *models.StartingPosition {
+	g := &Generator{width: metadata.Width, height: metadata.Height}
+
+	// Scoring indexes tiles row-major, so order them as the generator did
+	grid := append([]*models.MapTile(nil), tiles...)
+	sort.Slice(grid, func(i, j int) bool {
+		if grid[i].Y != grid[j].Y {
+			return grid[i].Y < grid[j].Y
+		}
+		return grid[i].X < grid[j].X
+	})
+	if len(grid) != g.width*g.height {
+		return nil
+	}
+
+	diagonal := math.Sqrt(float64(g.width*g.width + g.height*g.height))
+	var best *candidateRegion
+	bestScore := 0.0
+	for _, candidate := range g.findCandidateRegions(grid) {
+		if regionClaimed(g, grid, candidate.centerX, candidate.centerY) {
+			continue
+		}
+
+		minDist := diagonal
+		for _, loc := range occupied {
+			dx := float64(candidate.centerX - loc.X)
+			dy := float64(candidate.centerY - loc.Y)
+			minDist = math.Min(minDist, math.Sqrt(dx*dx+dy*dy))
+		}
+
+		if combined := candidate.score * (minDist / diagonal); combined > bestScore {
+			best, bestScore = candidate, combined
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	position := &models.StartingPosition{
+		GameID:        metadata.GameID,
+		PlayerID:      playerID,
+		CenterX:       best.centerX,
+		CenterY:       best.centerY,
+		StartingCityX: best.centerX,
+		StartingCityY: best.centerY,
+		RegionScore:   best.score,
+		RevealedTiles: 15 * 15,
+	}
+	position.GuaranteedFootprint.MinX = max(0, position.CenterX-20)
+	position.GuaranteedFootprint.MaxX = min(g.width-1, position.CenterX+20)
+	position.GuaranteedFootprint.MinY = max(0, position.CenterY-20)
+	position.GuaranteedFootprint.MaxY = min(g.height-1, position.CenterY+20)
+	return position
+}
+
+// regionClaimed reports whether any tile of the 15x15 region is owned
+func regionClaimed(g *Generator, tiles []*models.MapTile, centerX, centerY int) bool {
+	for _, loc := range g.mapGrid().Disk(models.Location{X: centerX, Y: centerY}, 7) {
+		if tile := getTile(tiles, loc.X, loc.Y, g.width); tile != nil && tile.OwnerID != nil {
+			return true
+		}
+	}
+	return false
+}
+
 type candidateRegion struct {
 	centerX int
 	centerY int
@@ -116,13 +193,13 @@ type candidateRegion struct {
 }
 
 // findCandidateRegions scans the map for suitable 15x15 starting regions
-func (g *Generator) findCandidateRegions(tiles []*models.MapTile, elevationGrid [][]int, seaLevel int) []*candidateRegion {
+func (g *Generator) findCandidateRegions(tiles []*models.MapTile) []*candidateRegion {
 	candidates := []*candidateRegion{}
 
 	// Scan every 10 tiles to find candidates
 	for y := 7; y < g.height-7; y += 10 {
 		for x := 7; x < g.width-7; x += 10 {
-			score := g.scoreStartingRegion(tiles, x, y, elevationGrid, seaLevel)
+			score := g.scoreStartingRegion(tiles, x, y)
 			if score > 50 { // Minimum threshold
 				candidates = append(candidates, &candidateRegion{
 					centerX: x,
@@ -146,102 +223,91 @@ func (g *Generator) findCandidateRegions(tiles []*models.MapTile, elevationGrid
 }
 
 // scoreStartingRegion evaluates a 15x15 region for starting position suitability
-func (g *Generator) scoreStartingRegion(tiles []*models.MapTile, centerX, centerY int, elevationGrid [][]int, seaLevel int) float64 {
-	score := 0.0
-	landTiles := 0
-	coastalTiles := 0
-	resourceCount := 0
-	terrainTypes := make(map[string]bool)
-
-	// Evaluate 15x15 region
-	for dy := -7; dy <= 7; dy++ {
-		for dx := -7; dx <= 7; dx++ {
-			x := centerX + dx
-			y := centerY + dy
-
-			if x < 0 || x >= g.width || y < 0 || y >= g.height {
-				continue
-			}
-
-			tile := getTile(tiles, x, y, g.width)
-			if tile == nil {
-				continue
-			}
-
-			elevation := elevationGrid[y][x]
-
-			// Count land tiles
-			if elevation >= seaLevel {
-				landTiles++
-				terrainTypes[tile.TerrainType] = true
-
-				// Prefer buildable terrain
-				if tile.TerrainType == "GRASSLAND" || tile.TerrainType == "PLAINS" {
-					score += 2.0
-				} else if tile.TerrainType == "FOREST" {
-					score += 1.5
-				} else if tile.TerrainType == "MOUNTAIN" || tile.TerrainType == "TUNDRA" {
-					score -= 1.0 // Less desirable
-				}
-
-				// Count coastal access
-				if tile.IsCoastal {
-					coastalTiles++
-				}
-
-				// Count resources
-				if len(tile.Resources) > 0 {
-					resourceCount += len(tile.Resources)
-				}
-
-				// Prefer moderate elevation
-				if elevation >= seaLevel && elevation <= 800 {
-					score += 1.0
-				}
-			}
+func (g *Generator) scoreStartingRegion(tiles []*models.MapTile, centerX, centerY int) float64 {
+	region := make([]*models.MapTile, 0, 15*15)
+	for _, loc := range g.mapGrid().Disk(models.Location{X: centerX, Y: centerY}, 7) {
+		if tile := getTile(tiles, loc.X, loc.Y, g.width); tile != nil {
+			region = append(region, tile)
 		}
 	}
 
+	site := terrain.ScoreSite(region)
+
 	// Apply viability criteria
-	if landTiles < 180 { // Need at least 80% land
+	if site.LandTiles < 180 { // Need at least 80% land
 		return 0
 	}
 
-	if coastalTiles < 3 { // Need some coastal access
-		score -= 10
-	}
+	return site.Score
+}
 
-	if resourceCount < 2 { // Need some resources
-		score -= 10
-	}
+const (
+	minorCivScanStep = 5  // Spacing of the grid scanned for minor civ sites
+	minorCivSpacing  = 15 // Closest a minor civ may sit to a start or another minor civ
+)
 
-	// Reward terrain diversity (2-4 types ideal)
-	terrainDiversity := len(terrainTypes)
-	if terrainDiversity >= 2 && terrainDiversity <= 4 {
-		score += float64(terrainDiversity) * 5
+// findMinorCivSites picks up to count sites for minor civilizations in
+// regions too poor or too small to start a player in, such as islands,
+// deserts and tundra. Each site's own work area must be mostly land, and
+// sites keep minorCivSpacing from starting positions and each other. Better
+// local sites are taken first.
+func (g *Generator) findMinorCivSites(tiles []*models.MapTile, startingPositions []*models.StartingPosition, count int) []models.Location {
+	type site struct {
+		location models.Location
+		score    float64
 	}
+	var candidates []site
+	for y := minorCivScanStep / 2; y < g.height; y += minorCivScanStep {
+		for x := minorCivScanStep / 2; x < g.width; x += minorCivScanStep {
+			center := getTile(tiles, x, y, g.width)
+			if center == nil || terrain.IsWater(center.TerrainType) || g.scoreStartingRegion(tiles, x, y) > 50 {
+				continue
+			}
+			var area []*models.MapTile
+			for _, loc := range g.mapGrid().Disk(models.Location{X: x, Y: y}, 1) {
+				area = append(area, getTile(tiles, loc.X, loc.Y, g.width))
+			}
+			local := terrain.ScoreSite(area)
+			if local.LandTiles < 5 {
+				continue
+			}
+			candidates = append(candidates, site{models.Location{X: x, Y: y}, local.Score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
 
-	// Add base score for land tiles
-	score += float64(landTiles) / 2.0
-
-	return score
+	taken := make([]models.Location, 0, len(startingPositions)+count)
+	for _, position := range startingPositions {
+		taken = append(taken, models.Location{X: position.CenterX, Y: position.CenterY})
+	}
+	var sites []models.Location
+	for _, candidate := range candidates {
+		if len(sites) == count {
+			break
+		}
+		crowded := false
+		for _, loc := range taken {
+			dx, dy := candidate.location.X-loc.X, candidate.location.Y-loc.Y
+			if dx*dx+dy*dy < minorCivSpacing*minorCivSpacing {
+				crowded = true
+				break
+			}
+		}
+		if !crowded {
+			sites = append(sites, candidate.location)
+			taken = append(taken, candidate.location)
+		}
+	}
+	return sites
 }
 
 // revealStartingAreas reveals the 15x15 starting region for each player
 func (g *Generator) revealStartingAreas(tiles []*models.MapTile, startingPositions []*models.StartingPosition) {
 	for _, position := range startingPositions {
 		// Reveal 15x15 region around starting position
-		for dy := -7; dy <= 7; dy++ {
-			for dx := -7; dx <= 7; dx++ {
-				x := position.CenterX + dx
-				y := position.CenterY + dy
-
-				if x >= 0 && x < g.width && y >= 0 && y < g.height {
-					tile := getTile(tiles, x, y, g.width)
-					if tile != nil {
-						tile.VisibleTo = append(tile.VisibleTo, position.PlayerID)
-					}
-				}
+		for _, loc := range g.mapGrid().Disk(models.Location{X: position.CenterX, Y: position.CenterY}, 7) {
+			if tile := getTile(tiles, loc.X, loc.Y, g.width); tile != nil {
+				tile.VisibleTo = append(tile.VisibleTo, position.PlayerID)
 			}
 		}
 	}
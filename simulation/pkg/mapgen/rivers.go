@@ -2,6 +2,7 @@ package mapgen
 
 import (
 	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
 )
 
 // generateRivers creates rivers flowing from high elevations to the sea
@@ -25,10 +26,11 @@ func (g *Generator) generateRivers(tiles []*models.MapTile, elevationGrid [][]in
 
 // findRiverSource finds a suitable starting point for a river
 func (g *Generator) findRiverSource(elevationGrid [][]int, seaLevel int) (int, int) {
+	random := g.random(phaseRivers)
 	// Try to find a mountain tile (elevation > 1200)
 	for attempt := 0; attempt < 50; attempt++ {
-		x := g.rng.Intn(g.width)
-		y := g.rng.Intn(g.height)
+		x := random.Intn(g.width)
+		y := random.Intn(g.height)
 		if elevationGrid[y][x] > 1200 && elevationGrid[y][x] >= seaLevel {
 			return x, y
 		}
@@ -69,18 +71,10 @@ func (g *Generator) traceRiver(tiles []*models.MapTile, elevationGrid [][]int, s
 		lowestElev := elevationGrid[y][x]
 		nextX, nextY := x, y
 
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				if dx == 0 && dy == 0 {
-					continue
-				}
-				nx, ny := x+dx, y+dy
-				if nx >= 0 && nx < g.width && ny >= 0 && ny < g.height {
-					if elevationGrid[ny][nx] < lowestElev {
-						lowestElev = elevationGrid[ny][nx]
-						nextX, nextY = nx, ny
-					}
-				}
+		for _, n := range g.mapGrid().Neighbors8(models.Location{X: x, Y: y}) {
+			if elevationGrid[n.Y][n.X] < lowestElev {
+				lowestElev = elevationGrid[n.Y][n.X]
+				nextX, nextY = n.X, n.Y
 			}
 		}
 
@@ -93,6 +87,29 @@ func (g *Generator) traceRiver(tiles []*models.MapTile, elevationGrid [][]int, s
 	}
 }
 
+// assignRiverSystems extracts the river graph: each 8-connected network of
+// land river tiles is a river system, numbered from 1 in row order and
+// recorded on its tiles. It returns the number of systems.
+func (g *Generator) assignRiverSystems(tiles []*models.MapTile) int {
+	unlabeled := func(x, y int) bool {
+		tile := tiles[y*g.width+x]
+		return tile.HasRiver && tile.RiverID == 0 && !terrain.IsWater(tile.TerrainType)
+	}
+	systems := 0
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if !unlabeled(x, y) {
+				continue
+			}
+			systems++
+			g.floodFill(x, y, unlabeled, func(nx, ny int) {
+				tiles[ny*g.width+nx].RiverID = systems
+			})
+		}
+	}
+	return systems
+}
+
 // distributeResources places resources on the map based on terrain
 func (g *Generator) distributeResources(tiles []*models.MapTile, elevationGrid [][]int, seaLevel int) {
 	// Strategic resources
@@ -102,16 +119,22 @@ func (g *Generator) distributeResources(tiles []*models.MapTile, elevationGrid [
 	g.placeResource(tiles, "GOLD", 0.01, []string{"MOUNTAIN", "HILLS"})
 
 	// Basic resources
-	g.placeResource(tiles, "WHEAT", 0.08, []string{"GRASSLAND", "PLAINS"})
-	g.placeResource(tiles, "CATTLE", 0.06, []string{"GRASSLAND", "PLAINS"})
+	g.placeResource(tiles, "WHEAT", 0.08, []string{"GRASSLAND", "PLAINS", "OASIS"})
+	g.placeResource(tiles, "CATTLE", 0.06, []string{"GRASSLAND", "PLAINS", "SAVANNA"})
 	g.placeResource(tiles, "FISH", 0.05, []string{"OCEAN", "SHALLOW_WATER"})
 	g.placeResource(tiles, "STONE", 0.05, []string{"HILLS", "MOUNTAIN"})
-	g.placeResource(tiles, "WOOD", 0.06, []string{"FOREST", "JUNGLE"})
-	g.placeResource(tiles, "GAME", 0.04, []string{"FOREST"})
+	g.placeResource(tiles, "WOOD", 0.06, []string{"FOREST", "JUNGLE", "TAIGA"})
+	g.placeResource(tiles, "GAME", 0.04, []string{"FOREST", "SAVANNA", "TAIGA", "MARSH"})
+
+	// Track remaining quantities so deposits can deplete over a long game
+	for _, tile := range tiles {
+		tile.ResourceQuantities = terrain.InitialQuantities(tile.Resources)
+	}
 }
 
 // placeResource places a specific resource type on suitable terrain
 func (g *Generator) placeResource(tiles []*models.MapTile, resourceType string, density float64, suitableTerrain []string) {
+	random := g.random(phaseResources)
 	// Find suitable tiles
 	suitable := []*models.MapTile{}
 	for _, tile := range tiles {
@@ -135,17 +158,17 @@ func (g *Generator) placeResource(tiles []*models.MapTile, resourceType string,
 
 	for i := 0; i < numClusters; i++ {
 		// Pick random starting tile
-		centerTile := suitable[g.rng.Intn(len(suitable))]
+		centerTile := suitable[random.Intn(len(suitable))]
 
 		// Place 3-7 tiles per cluster
-		clusterSize := g.rng.Intn(5) + 3
+		clusterSize := random.Intn(5) + 3
 		placed := 0
 
 		// Try to place around center
 		for attempt := 0; attempt < clusterSize*3 && placed < clusterSize; attempt++ {
 			// Random offset from center
-			dx := g.rng.Intn(5) - 2
-			dy := g.rng.Intn(5) - 2
+			dx := random.Intn(5) - 2
+			dy := random.Intn(5) - 2
 			x := centerTile.X + dx
 			y := centerTile.Y + dy
 
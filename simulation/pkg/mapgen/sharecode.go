@@ -0,0 +1,180 @@
+package mapgen
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// shareCodeVersion is the share code layout this build writes
+const shareCodeVersion = 1
+
+// Share code flags
+const (
+	shareHexSeed  = 1 << iota // The seed is hex and stored packed, half its length
+	shareTuning               // The land ratio tolerance and sea level iterations follow
+	shareScenario             // A scenario ID follows the seed
+)
+
+// shareEncoding spells share codes in upper-case letters and digits 2-7,
+// which survive being read aloud or copied by hand
+var shareEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ShareCode is everything needed to generate a world again: a game created
+// from the same code gets the same map. Options are kept to the precision a
+// code can hold, a tenth of a percent of land.
+type ShareCode struct {
+	Seed     string
+	Players  int // Sizes the map and sets how many starting positions it has
+	Options  MapOptions
+	Scenario string // Optional scenario the world is played under
+}
+
+// Encode spells the share code as dash-separated groups of five characters.
+// Seeds and scenario IDs may be up to 255 bytes long.
+func (c ShareCode) Encode() (string, error) {
+	switch {
+	case c.Seed == "" || len(c.Seed) > math.MaxUint8:
+		return "", fmt.Errorf("share code seed must be 1 to %d bytes", math.MaxUint8)
+	case len(c.Scenario) > math.MaxUint8:
+		return "", fmt.Errorf("share code scenario must be at most %d bytes", math.MaxUint8)
+	case c.Players < 1 || c.Players > math.MaxUint8:
+		return "", fmt.Errorf("share code player count must be 1 to %d", math.MaxUint8)
+	}
+	opts := c.Options.normalized()
+	var flags byte
+	seed := []byte(c.Seed)
+	if packed, err := hex.DecodeString(c.Seed); err == nil && strings.ToLower(c.Seed) == c.Seed {
+		flags |= shareHexSeed
+		seed = packed
+	}
+	defaults := DefaultMapOptions()
+	if opts.LandRatioTolerance != defaults.LandRatioTolerance || opts.SeaLevelIterations != defaults.SeaLevelIterations {
+		flags |= shareTuning
+	}
+	if c.Scenario != "" {
+		flags |= shareScenario
+	}
+
+	data := []byte{shareCodeVersion, flags, byte(c.Players)}
+	data = appendUint16(data, uint16(math.Round(opts.LandRatio*1000)))
+	if flags&shareTuning != 0 {
+		data = appendUint16(data, uint16(math.Round(opts.LandRatioTolerance*10000)))
+		data = append(data, byte(min(opts.SeaLevelIterations, math.MaxUint8)))
+	}
+	data = append(data, byte(len(seed)))
+	data = append(data, seed...)
+	if flags&shareScenario != 0 {
+		data = append(data, byte(len(c.Scenario)))
+		data = append(data, c.Scenario...)
+	}
+	data = appendUint16(data, shareChecksum(data))
+
+	encoded := shareEncoding.EncodeToString(data)
+	var groups []string
+	for len(encoded) > 5 {
+		groups = append(groups, encoded[:5])
+		encoded = encoded[5:]
+	}
+	return strings.Join(append(groups, encoded), "-"), nil
+}
+
+// ParseShareCode decodes a share code. Case, spaces and dashes are ignored,
+// and a mistyped code is caught by its checksum.
+func ParseShareCode(code string) (ShareCode, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+	data, err := shareEncoding.DecodeString(cleaned)
+	if err != nil || len(data) < 8 {
+		return ShareCode{}, errors.New("not a share code")
+	}
+	body, sum := data[:len(data)-2], uint16(data[len(data)-2])<<8|uint16(data[len(data)-1])
+	if shareChecksum(body) != sum {
+		return ShareCode{}, errors.New("share code checksum mismatch; check it was copied correctly")
+	}
+	if body[0] != shareCodeVersion {
+		return ShareCode{}, fmt.Errorf("share code version %d is not supported", body[0])
+	}
+
+	r := shareReader{data: body[1:]}
+	flags := r.nextByte()
+	c := ShareCode{Players: int(r.nextByte()), Options: DefaultMapOptions()}
+	c.Options.LandRatio = float64(r.nextUint16()) / 1000
+	if flags&shareTuning != 0 {
+		c.Options.LandRatioTolerance = float64(r.nextUint16()) / 10000
+		c.Options.SeaLevelIterations = int(r.nextByte())
+	}
+	seed := r.next(int(r.nextByte()))
+	if flags&shareHexSeed != 0 {
+		c.Seed = hex.EncodeToString(seed)
+	} else {
+		c.Seed = string(seed)
+	}
+	if flags&shareScenario != 0 {
+		c.Scenario = string(r.next(int(r.nextByte())))
+	}
+	if r.short || len(r.data) > 0 {
+		return ShareCode{}, errors.New("share code is malformed")
+	}
+	if c.Players < 1 || c.Seed == "" {
+		return ShareCode{}, errors.New("share code has no seed or players")
+	}
+	c.Options = c.Options.normalized()
+	return c, nil
+}
+
+// appendUint16 appends v big-endian
+func appendUint16(data []byte, v uint16) []byte {
+	return append(data, byte(v>>8), byte(v))
+}
+
+// shareChecksum is the Fletcher-16 checksum of data
+func shareChecksum(data []byte) uint16 {
+	var a, b uint16
+	for _, d := range data {
+		a = (a + uint16(d)) % 255
+		b = (b + a) % 255
+	}
+	return b<<8 | a
+}
+
+// shareReader reads a share code's fields, noting if it runs out of data
+type shareReader struct {
+	data  []byte
+	short bool
+}
+
+// next reads n bytes
+func (r *shareReader) next(n int) []byte {
+	if n > len(r.data) {
+		r.short = true
+		n = len(r.data)
+	}
+	read := r.data[:n]
+	r.data = r.data[n:]
+	return read
+}
+
+// nextByte reads a byte
+func (r *shareReader) nextByte() byte {
+	if read := r.next(1); len(read) == 1 {
+		return read[0]
+	}
+	return 0
+}
+
+// nextUint16 reads a big-endian uint16
+func (r *shareReader) nextUint16() uint16 {
+	read := r.next(2)
+	if len(read) < 2 {
+		return 0
+	}
+	return uint16(read[0])<<8 | uint16(read[1])
+}
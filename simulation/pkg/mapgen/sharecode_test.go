@@ -0,0 +1,68 @@
+package mapgen
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestShareCode(t *testing.T) {
+	tuned := DefaultMapOptions()
+	tuned.LandRatio = 0.4
+	tuned.LandRatioTolerance = 0.02
+	tuned.SeaLevelIterations = 12
+	cases := []ShareCode{
+		{Seed: "3f9a0c1d2e4b5a6978877665544332211", Players: 4, Options: DefaultMapOptions()},
+		{Seed: "3f9a0c1d2e4b5a697887766554433221", Players: 4, Options: DefaultMapOptions()},
+		{Seed: "Archipelago", Players: 2, Options: tuned, Scenario: "bronze-age"},
+	}
+	for _, want := range cases {
+		code, err := want.Encode()
+		if err != nil {
+			t.Fatalf("Encode(%+v) failed: %v", want, err)
+		}
+		got, err := ParseShareCode(strings.ToLower(code))
+		if err != nil {
+			t.Fatalf("ParseShareCode(%q) failed: %v", code, err)
+		}
+		if got != want {
+			t.Errorf("Expected %q to decode to %+v, got %+v", code, want, got)
+		}
+	}
+
+	// Engine seeds are hex and pack to half their length
+	hexCode, _ := cases[1].Encode()
+	textCode, _ := ShareCode{Seed: strings.ToUpper(cases[1].Seed), Players: 4}.Encode()
+	if len(hexCode) >= len(textCode) {
+		t.Errorf("Expected a hex seed to make a shorter code: %q vs %q", hexCode, textCode)
+	}
+
+	// Mistyped and foreign codes are refused
+	typo := []byte(hexCode)
+	if typo[0] == 'A' {
+		typo[0] = 'B'
+	} else {
+		typo[0] = 'A'
+	}
+	for _, bad := range []string{string(typo), "hello", hexCode[:len(hexCode)-4]} {
+		if _, err := ParseShareCode(bad); err == nil {
+			t.Errorf("Expected %q to be refused", bad)
+		}
+	}
+	if _, err := (ShareCode{Seed: strings.Repeat("x", 256), Players: 2}).Encode(); err == nil {
+		t.Error("Expected a seed too long for a code to be refused")
+	}
+
+	// A shared code generates the same world
+	share, _ := ParseShareCode(hexCode)
+	original, originalTiles, _, _ := NewGenerator(cases[1].Seed, 4).GenerateMap(context.Background(), "a", 4)
+	shared, sharedTiles, _, _ := NewGeneratorWithOptions(share.Seed, share.Players, share.Options).GenerateMap(context.Background(), "b", share.Players)
+	if original.SeaLevel != shared.SeaLevel || len(originalTiles) != len(sharedTiles) {
+		t.Fatalf("Expected the shared world to match, got sea level %d vs %d", original.SeaLevel, shared.SeaLevel)
+	}
+	for i := range originalTiles {
+		if originalTiles[i].TerrainType != sharedTiles[i].TerrainType || originalTiles[i].Elevation != sharedTiles[i].Elevation {
+			t.Fatalf("Expected tile %d to match in the shared world", i)
+		}
+	}
+}
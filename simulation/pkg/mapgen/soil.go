@@ -0,0 +1,93 @@
+package mapgen
+
+import (
+	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	// soilHighland is the height above sea level at which soil is thinnest
+	soilHighland = 2000.0
+
+	// floodplainReach is how far (in tiles) a river enriches the soil around it
+	floodplainReach = 2
+
+	riverSoilBonus   = 0.3 // On a river, halving with each tile away
+	volcanicAshBonus = 0.3
+
+	minFertility = 0.1
+	maxFertility = 1.5
+)
+
+// soilMoisture is how well each land terrain holds water for crops (0-1)
+var soilMoisture = map[string]float64{
+	terrain.Grassland: 0.8,
+	terrain.Plains:    0.6,
+	terrain.Forest:    0.8,
+	terrain.Jungle:    0.7, // Wet, but rains leach the soil
+	terrain.Hills:     0.5,
+	terrain.Mountain:  0.2,
+	terrain.Tundra:    0.3,
+	terrain.Desert:    0.1,
+	terrain.Beach:     0.3,
+	terrain.Savanna:   0.5,
+	terrain.Taiga:     0.5,
+	terrain.Marsh:     0.9, // Silt-rich, once drained
+	terrain.Oasis:     0.7,
+}
+
+// assignFertility rates each land tile's soil from its moisture and height,
+// enriched near rivers and by volcanic ash. Water has no soil.
+func (g *Generator) assignFertility(tiles []*models.MapTile, seaLevel int, volcanic [][]bool) {
+	riverDistance := g.riverDistances(tiles)
+	for _, tile := range tiles {
+		if terrain.IsWater(tile.TerrainType) {
+			continue
+		}
+
+		height := math.Min(math.Max(float64(tile.Elevation-seaLevel)/soilHighland, 0), 1)
+		fertility := 0.2 + soilMoisture[tile.TerrainType]*(1-0.6*height)
+		if d := riverDistance[tile.Y][tile.X]; d >= 0 {
+			fertility += riverSoilBonus * math.Pow(0.5, float64(d))
+		}
+		if volcanic[tile.Y][tile.X] {
+			fertility += volcanicAshBonus
+		}
+
+		fertility = math.Round(math.Min(math.Max(fertility, minFertility), maxFertility)*100) / 100
+		tile.Fertility = fertility
+		tile.NaturalFertility = fertility
+	}
+}
+
+// riverDistances returns each tile's distance (in tiles, Chebyshev) to the
+// nearest river within floodplainReach, or -1 if there is none that close
+func (g *Generator) riverDistances(tiles []*models.MapTile) [][]int {
+	distance := make([][]int, g.height)
+	for y := range distance {
+		distance[y] = make([]int, g.width)
+		for x := range distance[y] {
+			distance[y][x] = -1
+		}
+	}
+	for _, tile := range tiles {
+		if !tile.HasRiver {
+			continue
+		}
+		for dy := -floodplainReach; dy <= floodplainReach; dy++ {
+			for dx := -floodplainReach; dx <= floodplainReach; dx++ {
+				x, y := tile.X+dx, tile.Y+dy
+				if !g.inBounds(x, y) {
+					continue
+				}
+				d := max(max(dx, -dx), max(dy, -dy))
+				if distance[y][x] < 0 || d < distance[y][x] {
+					distance[y][x] = d
+				}
+			}
+		}
+	}
+	return distance
+}
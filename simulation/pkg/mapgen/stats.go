@@ -0,0 +1,61 @@
+package mapgen
+
+import (
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// computeStats tallies terrain, resources, rivers and landmasses over the
+// finished tiles, which are laid out row by row
+func (g *Generator) computeStats(tiles []*models.MapTile) *models.MapStats {
+	stats := &models.MapStats{
+		TerrainCounts:  make(map[string]int),
+		ResourceCounts: make(map[string]int),
+	}
+	if len(tiles) == 0 {
+		return stats
+	}
+
+	land := 0
+	fertility := 0.0
+	for _, tile := range tiles {
+		stats.TerrainCounts[tile.TerrainType]++
+		for _, resource := range tile.Resources {
+			stats.ResourceCounts[resource]++
+		}
+		if terrain.IsWater(tile.TerrainType) {
+			continue
+		}
+		land++
+		fertility += tile.Fertility
+		if tile.HasRiver {
+			stats.RiverTiles++
+		}
+		stats.RiverSystems = max(stats.RiverSystems, tile.RiverID)
+		if tile.HasCave {
+			stats.CaveTiles++
+		}
+	}
+	stats.LandPercentage = float64(land) * 100 / float64(len(tiles))
+	if land > 0 {
+		stats.AverageFertility = fertility / float64(land)
+	}
+
+	visited := g.newMask()
+	unvisitedLand := func(x, y int) bool {
+		return !visited[y][x] && !terrain.IsWater(tiles[y*g.width+x].TerrainType)
+	}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if !unvisitedLand(x, y) {
+				continue
+			}
+			size := g.floodFill(x, y, unvisitedLand, func(nx, ny int) {
+				visited[ny][nx] = true
+			})
+			stats.ContinentCount++
+			stats.LargestContinent = max(stats.LargestContinent, size)
+		}
+	}
+	return stats
+}
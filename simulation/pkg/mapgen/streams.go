@@ -0,0 +1,47 @@
+package mapgen
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+
+	"github.com/anicolao/simciv/simulation/pkg/rng"
+)
+
+// Generation phases, each drawing from its own random stream derived from
+// the map seed. A phase that draws more or fewer numbers leaves every other
+// phase's draws, and so the rest of each seed's map, unchanged.
+const (
+	phaseTerrain   = "terrain"   // Great circles and tectonic plates
+	phaseRivers    = "rivers"    // River sources
+	phaseResources = "resources" // Resource clusters and caves
+	phasePlacement = "placement" // Starting positions and minor civ sites
+	phaseWetlands  = "wetlands"  // Oases sampled in arid country
+)
+
+// newPhaseStreams derives the random stream of every generation phase from
+// the map seed. Terrain keeps the seeding the whole generator once shared,
+// so existing seeds keep their continents.
+func newPhaseStreams(seed string) map[string]*rand.Rand {
+	h := sha256.Sum256([]byte(seed))
+	streams := map[string]*rand.Rand{
+		phaseTerrain: rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(h[:8])))),
+	}
+	for _, phase := range []string{phaseRivers, phaseResources, phasePlacement, phaseWetlands} {
+		streams[phase] = rand.New(rand.NewSource(rng.DeriveSeed(seed, phase)))
+	}
+	return streams
+}
+
+// random returns the random stream of a generation phase
+func (g *Generator) random(phase string) *rand.Rand {
+	return g.streams[phase]
+}
+
+// rowStream returns the random stream a phase draws from for one row,
+// derived from the map seed so every row is random independently of the
+// rows generated before it
+func (g *Generator) rowStream(phase string, y int) *rng.Stream {
+	return rng.NewStream(rng.DeriveSeed(g.seed, fmt.Sprintf("%s-row-%d", phase, y)))
+}
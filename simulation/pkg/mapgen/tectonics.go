@@ -0,0 +1,194 @@
+package mapgen
+
+import (
+	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+const (
+	// tectonicReach is how far (in tiles) from a boundary its uplift or subsidence extends
+	tectonicReach = 3
+
+	// convergenceThreshold is the closing (or opening) speed below which plates merely slide past
+	convergenceThreshold = 0.3
+
+	// continentalShelf raises continental plates, and lowers oceanic ones, by this much
+	continentalShelf = 250.0
+)
+
+// boundaryKind is what a plate boundary does to the tiles on one side of it
+type boundaryKind int
+
+const (
+	boundaryTransform boundaryKind = iota // Plates slide past; no relief
+	boundaryRange                         // Continental collision
+	boundaryArc                           // Overriding side of a subduction zone
+	boundaryTrench                        // Subducting side
+	boundaryRift                          // Continental plate pulling away
+	boundaryRidge                         // Oceanic plate pulling away
+)
+
+// boundaryUplift is the elevation change at each kind of boundary
+var boundaryUplift = map[boundaryKind]float64{
+	boundaryRange:  1400,
+	boundaryArc:    900,
+	boundaryTrench: -500,
+	boundaryRift:   -500,
+	boundaryRidge:  200,
+}
+
+// generatePlates seeds a handful more plates than players, each continental
+// or oceanic and drifting in a random direction
+func (g *Generator) generatePlates(playerCount int) []models.TectonicPlate {
+	random := g.random(phaseTerrain)
+	plates := make([]models.TectonicPlate, playerCount+4)
+	for i := range plates {
+		angle := random.Float64() * 2 * math.Pi
+		plates[i] = models.TectonicPlate{
+			ID:          i,
+			SeedX:       random.Intn(g.width),
+			SeedY:       random.Intn(g.height),
+			Continental: random.Float64() < 0.5,
+			DriftX:      math.Cos(angle),
+			DriftY:      math.Sin(angle),
+		}
+	}
+	return plates
+}
+
+// applyTectonics shapes elevationGrid along plate boundaries: colliding
+// continents raise continuous mountain ranges, subduction raises a volcanic
+// arc beside a trench, and separating plates open rift valleys or mid-ocean
+// ridges. The effect fades over tectonicReach tiles from the boundary. It
+// returns the ranges, rifts and arcs as map features, and a mask of the
+// tiles volcanic arcs have dusted with ash.
+func (g *Generator) applyTectonics(elevationGrid [][]int, plates []models.TectonicPlate) ([]models.MapFeature, [][]bool) {
+	volcanic := g.newMask()
+	if len(plates) == 0 {
+		return nil, volcanic
+	}
+	owner := g.assignPlates(plates)
+
+	ranges := g.newMask()
+	rifts := g.newMask()
+	arcs := g.newMask()
+	kind := make([][]boundaryKind, g.height)
+	distance := make([][]int, g.height)
+	var queue [][2]int
+	for y := 0; y < g.height; y++ {
+		kind[y] = make([]boundaryKind, g.width)
+		distance[y] = make([]int, g.width)
+		for x := 0; x < g.width; x++ {
+			distance[y][x] = -1
+			plate := plates[owner[y][x]]
+			for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nx, ny := x+d[0], y+d[1]
+				if !g.inBounds(nx, ny) || owner[ny][nx] == plate.ID {
+					continue
+				}
+				kind[y][x] = classifyBoundary(plate, plates[owner[ny][nx]])
+				switch kind[y][x] {
+				case boundaryRange:
+					ranges[y][x] = true
+				case boundaryArc:
+					arcs[y][x] = true
+				case boundaryRift:
+					rifts[y][x] = true
+				}
+				if kind[y][x] != boundaryTransform {
+					distance[y][x] = 0
+					queue = append(queue, [2]int{x, y})
+					break
+				}
+			}
+		}
+	}
+
+	// Spread each boundary's effect to the tiles it is nearest to
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if distance[p[1]][p[0]] == tectonicReach {
+			continue
+		}
+		for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := p[0]+d[0], p[1]+d[1]
+			if !g.inBounds(nx, ny) || distance[ny][nx] >= 0 || owner[ny][nx] != owner[p[1]][p[0]] {
+				continue
+			}
+			distance[ny][nx] = distance[p[1]][p[0]] + 1
+			kind[ny][nx] = kind[p[1]][p[0]]
+			queue = append(queue, [2]int{nx, ny})
+		}
+	}
+
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			shift := -continentalShelf
+			if plates[owner[y][x]].Continental {
+				shift = continentalShelf
+			}
+			if distance[y][x] >= 0 {
+				shift += boundaryUplift[kind[y][x]] * (1 - float64(distance[y][x])/float64(tectonicReach+1))
+				volcanic[y][x] = kind[y][x] == boundaryArc
+			}
+			elevationGrid[y][x] = min(max(elevationGrid[y][x]+int(shift), -100), 3000)
+		}
+	}
+
+	var features []models.MapFeature
+	features = append(features, g.clusterFeatures(ranges, models.FeatureMountainRange)...)
+	features = append(features, g.clusterFeatures(rifts, models.FeatureRiftValley)...)
+	features = append(features, g.clusterFeatures(arcs, models.FeatureVolcanicArc)...)
+	return features, volcanic
+}
+
+// assignPlates maps each tile to the index of the plate with the nearest seed
+func (g *Generator) assignPlates(plates []models.TectonicPlate) [][]int {
+	owner := make([][]int, g.height)
+	for y := 0; y < g.height; y++ {
+		owner[y] = make([]int, g.width)
+		for x := 0; x < g.width; x++ {
+			best := math.MaxInt
+			for i, plate := range plates {
+				dx, dy := x-plate.SeedX, y-plate.SeedY
+				if d := dx*dx + dy*dy; d < best {
+					best = d
+					owner[y][x] = i
+				}
+			}
+		}
+	}
+	return owner
+}
+
+// classifyBoundary returns what the relative motion of plate and other, which
+// it borders, does to plate's side of the boundary
+func classifyBoundary(plate, other models.TectonicPlate) boundaryKind {
+	nx, ny := float64(other.SeedX-plate.SeedX), float64(other.SeedY-plate.SeedY)
+	length := math.Hypot(nx, ny)
+	if length == 0 {
+		return boundaryTransform
+	}
+	// Positive when the plates close on each other
+	closing := ((plate.DriftX-other.DriftX)*nx + (plate.DriftY-other.DriftY)*ny) / length
+
+	switch {
+	case closing > convergenceThreshold:
+		if plate.Continental && other.Continental {
+			return boundaryRange
+		}
+		// The continental plate, or else the lower-numbered, rides over the other
+		if plate.Continental || (!other.Continental && plate.ID < other.ID) {
+			return boundaryArc
+		}
+		return boundaryTrench
+	case closing < -convergenceThreshold:
+		if plate.Continental {
+			return boundaryRift
+		}
+		return boundaryRidge
+	}
+	return boundaryTransform
+}
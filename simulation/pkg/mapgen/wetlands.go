@@ -0,0 +1,102 @@
+package mapgen
+
+import (
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+const (
+	// marshMaxElevation is the highest elevation above sea level that turns to marsh
+	marshMaxElevation = 60
+	// marshFlatness is the most a marsh's land neighbours rise or fall from it, in meters
+	marshFlatness = 10
+
+	// oasisDensity is the share of dry arid tiles that hold an oasis
+	oasisDensity = 0.04
+	// oasisDryRadius is how far from any river or water an oasis lies
+	oasisDryRadius = 2
+)
+
+// placeMarshes turns low land into marsh: the land at and beside each river
+// mouth, and flat land where water has nowhere to drain, on or beside a
+// river or in damp grassland and jungle basins. Cold and rugged land keeps
+// its terrain.
+func (g *Generator) placeMarshes(tiles []*models.MapTile, seaLevel int) {
+	tileAt := func(loc models.Location) *models.MapTile {
+		return tiles[loc.Y*g.width+loc.X]
+	}
+	isMouth := func(loc models.Location) bool {
+		tile := tileAt(loc)
+		if !tile.HasRiver || terrain.IsWater(tile.TerrainType) {
+			return false
+		}
+		for _, n := range g.mapGrid().Neighbors4(loc) {
+			if terrain.IsWater(tileAt(n).TerrainType) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Decide every tile before changing any, so the result does not depend
+	// on the order tiles are visited in
+	var marshes []*models.MapTile
+	for _, tile := range tiles {
+		switch tile.TerrainType {
+		case terrain.Grassland, terrain.Plains, terrain.Forest, terrain.Jungle, terrain.Savanna, terrain.Beach:
+		default:
+			continue
+		}
+		if tile.Elevation-seaLevel > marshMaxElevation {
+			continue
+		}
+
+		loc := models.Location{X: tile.X, Y: tile.Y}
+		damp := tile.TerrainType == terrain.Grassland || tile.TerrainType == terrain.Jungle
+		flat, byRiver, byMouth := true, tile.HasRiver, isMouth(loc)
+		for _, n := range g.mapGrid().Neighbors8(loc) {
+			neighbor := tileAt(n)
+			if terrain.IsWater(neighbor.TerrainType) {
+				continue
+			}
+			flat = flat && abs(neighbor.Elevation-tile.Elevation) <= marshFlatness
+			byRiver = byRiver || neighbor.HasRiver
+			byMouth = byMouth || isMouth(n)
+		}
+		if byMouth || (flat && (byRiver || damp)) {
+			marshes = append(marshes, tile)
+		}
+	}
+	for _, tile := range marshes {
+		tile.TerrainType = terrain.Marsh
+	}
+}
+
+// placeOases sets springs into a few arid tiles far from any river or
+// water. Biome blending softens most desert into savanna, so both count as
+// arid country.
+func (g *Generator) placeOases(tiles []*models.MapTile) {
+	random := g.random(phaseWetlands)
+	var candidates []*models.MapTile
+	for _, tile := range tiles {
+		if tile.TerrainType != terrain.Desert && tile.TerrainType != terrain.Savanna {
+			continue
+		}
+		dry := true
+		for _, n := range g.mapGrid().Disk(models.Location{X: tile.X, Y: tile.Y}, oasisDryRadius) {
+			near := tiles[n.Y*g.width+n.X]
+			dry = dry && !near.HasRiver && !terrain.IsWater(near.TerrainType)
+		}
+		if dry {
+			candidates = append(candidates, tile)
+		}
+	}
+
+	count := int(float64(len(candidates)) * oasisDensity)
+	random.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	for _, tile := range candidates[:count] {
+		tile.TerrainType = terrain.Oasis
+	}
+}
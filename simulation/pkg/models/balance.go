@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// BalanceShift is how far one balance metric moved from the baseline report
+type BalanceShift struct {
+	Metric   string  `bson:"metric" json:"metric"`
+	Baseline float64 `bson:"baseline" json:"baseline"`
+	Current  float64 `bson:"current" json:"current"`
+	Change   float64 `bson:"change" json:"change"`
+	Drifted  bool    `bson:"drifted" json:"drifted"` // Outside the band a balance golden would allow
+}
+
+// BalanceScenarioReport is one standard scenario's outcomes across the seeds
+type BalanceScenarioReport struct {
+	Scenario string             `bson:"scenario" json:"scenario"`
+	MaxDays  int                `bson:"maxDays" json:"maxDays"`
+	Metrics  map[string]float64 `bson:"metrics" json:"metrics"`
+	Shifts   []BalanceShift     `bson:"shifts,omitempty" json:"shifts,omitempty"` // Absent without a baseline
+}
+
+// BalanceReport is the periodic measurement of the current ruleset against
+// the standard seed suite, compared with the last stored report
+type BalanceReport struct {
+	ReportID     string                  `bson:"reportId" json:"reportId"`
+	GeneratedAt  time.Time               `bson:"generatedAt" json:"generatedAt"`
+	RulesVersion int                     `bson:"rulesVersion" json:"rulesVersion"`
+	Seeds        int                     `bson:"seeds" json:"seeds"`
+	BaselineID   string                  `bson:"baselineId,omitempty" json:"baselineId,omitempty"`
+	Scenarios    []BalanceScenarioReport `bson:"scenarios" json:"scenarios"`
+	Markdown     string                  `bson:"markdown" json:"-"` // The rendered report, for reading without tools
+}
@@ -0,0 +1,131 @@
+package models
+
+import "math"
+
+// DaysPerYear is the number of days in a game year, one engine tick
+const DaysPerYear = 365
+
+// Seasons of the game year
+const (
+	SeasonWinter = "winter"
+	SeasonSpring = "spring"
+	SeasonSummer = "summer"
+	SeasonAutumn = "autumn"
+)
+
+// WinterDays is how many days at the start of each year are winter
+const WinterDays = 90
+
+// seasonStarts is the first day of each season, in order
+var seasonStarts = []struct {
+	day    int
+	season string
+}{
+	{1, SeasonWinter},
+	{WinterDays + 1, SeasonSpring},
+	{182, SeasonSummer},
+	{274, SeasonAutumn},
+}
+
+// months names each month with its length; the year starts in January, so
+// January to March are winter
+var months = []struct {
+	name string
+	days int
+}{
+	{"January", 31}, {"February", 28}, {"March", 31}, {"April", 30},
+	{"May", 31}, {"June", 30}, {"July", 31}, {"August", 31},
+	{"September", 30}, {"October", 31}, {"November", 30}, {"December", 31},
+}
+
+const (
+	midwinterDay     = 45   // The shortest day, in the middle of winter
+	meanDaylight     = 12.0 // Hours of daylight at the equinoxes
+	daylightVariance = 4.0  // Hours midwinter falls short of the mean and midsummer exceeds it
+)
+
+// Era is a span of history, starting in StartYear and lasting until the next
+type Era struct {
+	Name      string
+	StartYear int
+}
+
+// Eras lists the eras of history in order; years before the first belong to it
+var Eras = []Era{
+	{"ancient", -5000},
+	{"classical", -1000},
+	{"medieval", 500},
+	{"renaissance", 1400},
+	{"industrial", 1750},
+	{"modern", 1900},
+}
+
+// Calendar is the date within the game, derived from the year and day so
+// client visuals and seasonal mechanics agree on it. A tick advances a game
+// by whole years, so a game's calendar is a year calendar without the day,
+// month, season or daylight; settlement simulations and clients date the
+// days within the year with CalendarAt.
+type Calendar struct {
+	Year       int     `bson:"year"`
+	Day        int     `bson:"day,omitempty"` // Day of the year, from 1
+	Month      string  `bson:"month,omitempty"`
+	DayOfMonth int     `bson:"dayOfMonth,omitempty"`
+	Season     string  `bson:"season,omitempty"`
+	Daylight   float64 `bson:"daylight,omitempty"` // Hours of daylight in the day
+
+	Era           string `bson:"era"`
+	NewEra        bool   `bson:"newEra,omitempty"` // The year is the first of its era
+	NewCentury    bool   `bson:"newCentury,omitempty"`
+	NewMillennium bool   `bson:"newMillennium,omitempty"`
+}
+
+// YearCalendar returns the calendar of a whole year: its era and whether it
+// turns an era, century or millennium
+func YearCalendar(year int) Calendar {
+	calendar := Calendar{
+		Year:          year,
+		Era:           Eras[0].Name,
+		NewCentury:    year%100 == 0,
+		NewMillennium: year%1000 == 0,
+	}
+	for _, era := range Eras {
+		if year >= era.StartYear {
+			calendar.Era = era.Name
+			calendar.NewEra = year == era.StartYear
+		}
+	}
+	return calendar
+}
+
+// CalendarAt returns the calendar on a day of a year. Days count from 1 and
+// wrap into the day of the year, so a simulation's running day count works.
+func CalendarAt(year, day int) Calendar {
+	day = (day-1)%DaysPerYear + 1
+	if day < 1 {
+		day += DaysPerYear
+	}
+	calendar := YearCalendar(year)
+	calendar.Day = day
+
+	for _, start := range seasonStarts {
+		if day >= start.day {
+			calendar.Season = start.season
+		}
+	}
+	remaining := day
+	for _, month := range months {
+		if remaining <= month.days {
+			calendar.Month = month.name
+			calendar.DayOfMonth = remaining
+			break
+		}
+		remaining -= month.days
+	}
+	calendar.Daylight = meanDaylight - daylightVariance*math.Cos(2*math.Pi*float64(day-midwinterDay)/DaysPerYear)
+	return calendar
+}
+
+// IsWinter reports whether the calendar's day falls in winter
+func (c Calendar) IsWinter() bool {
+	return c.Season == SeasonWinter
+}
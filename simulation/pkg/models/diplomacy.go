@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// StanceHostile is the stance between players after one broke a treaty
+const StanceHostile = "hostile"
+
+// Agreement types
+const (
+	AgreementTribute   = "tribute"   // The payer sends a fixed amount of production every year
+	AgreementVassalage = "vassalage" // The vassal sends a share of its production; its overlord defends it
+)
+
+// Agreement is a tribute or vassalage arrangement between two players, in
+// force or demanded
+type Agreement struct {
+	Type   string `bson:"type"`
+	From   string `bson:"from"`             // Payer, or vassal
+	To     string `bson:"to"`               // Recipient, or overlord
+	Amount int    `bson:"amount,omitempty"` // Production paid each year under tribute
+	Year   int    `bson:"year"`             // Year demanded or accepted
+}
+
+// DiplomacyState is the standing between two players, stored once per pair
+type DiplomacyState struct {
+	GameID         string     `bson:"gameId"`
+	PlayerA        string     `bson:"playerA"` // Lower player ID of the pair
+	PlayerB        string     `bson:"playerB"`
+	Stance         string     `bson:"stance"`
+	Agreement      *Agreement `bson:"agreement"`                // Arrangement in force, if any
+	Demand         *Agreement `bson:"demand"`                   // Arrangement demanded and awaiting an answer
+	BrokenBy       string     `bson:"brokenBy,omitempty"`       // Player who last broke an agreement
+	GrievanceUntil int        `bson:"grievanceUntil,omitempty"` // Year until which no new agreement can be made
+	LastUpdated    time.Time  `bson:"lastUpdated"`
+}
+
+// NewDiplomacyState returns the standing between two players before any
+// diplomacy, in their default stance
+func NewDiplomacyState(game *Game, a, b string) *DiplomacyState {
+	if b < a {
+		a, b = b, a
+	}
+	return &DiplomacyState{
+		GameID:  game.GameID,
+		PlayerA: a,
+		PlayerB: b,
+		Stance:  game.DefaultStance(a, b),
+	}
+}
+
+// Aggrieved reports whether a broken agreement still bars the pair from
+// making a new one in the given year
+func (d *DiplomacyState) Aggrieved(year int) bool {
+	return d.BrokenBy != "" && year < d.GrievanceUntil
+}
+
+// OverlordOf returns the overlord of a player under this pair's vassalage,
+// or "" when the player is not a vassal of the other
+func (d *DiplomacyState) OverlordOf(playerID string) string {
+	if d.Agreement != nil && d.Agreement.Type == AgreementVassalage && d.Agreement.From == playerID {
+		return d.Agreement.To
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Game event types
+const (
+	EventGameStarted       = "game_started" // The map was generated and play began
+	EventPlayerEliminated  = "player_eliminated"
+	EventPlayerSurrendered = "player_surrendered"
+	EventPlayerReleased    = "player_released" // A no-show's seat and region were freed
+	EventVictory           = "victory"
+	EventUnitMoved         = "unit_moved" // Carries the move's path and timing
+	EventAgreementMade     = "agreement_made"
+	EventAgreementBroken   = "agreement_broken"
+	EventSettlementGrew    = "settlement_grew"  // A settlement became a village, town or city
+	EventMinorCivAllied    = "minor_civ_allied" // A minor civ took a new ally
+	EventMinorQuestDone    = "minor_quest_done"
+	EventSettlementTaken   = "settlement_taken"  // A minor civ's settlement was conquered
+	EventSettlementCrisis  = "settlement_crisis" // Overcrowding brought an epidemic, strife or an exodus
+	EventObjectiveAssigned = "objective_assigned"
+	EventObjectiveDone     = "objective_done"
+	EventObjectiveFailed   = "objective_failed"
+	EventProjectProposed   = "project_proposed" // A settlement's people proposed a building
+	EventRevolt            = "revolt"           // Unrest drove a settlement's people to take up arms
+	EventLandTransformed   = "land_transformed" // Workers finished a project transforming a tile's terrain
+	EventHeavyRain         = "heavy_rain"       // Rivers will flood next year
+	EventFlood             = "flood"            // A river burst its banks and wrecked improvements
+	EventEarthquake        = "earthquake"       // A quake by a plate boundary wrecked improvements and buildings
+	EventNewEra            = "new_era"          // The year began a new era of history
+)
+
+// NeutralPlayerID owns settlements left behind by a player who surrendered
+const NeutralPlayerID = "neutral"
+
+// RebelPlayerID owns the rebels that rise up in settlements in revolt
+const RebelPlayerID = "rebels"
+
+// GameEvent is a notable game occurrence recorded for players to review
+type GameEvent struct {
+	EventID   string    `bson:"eventId"`
+	GameID    string    `bson:"gameId"`
+	Year      int       `bson:"year"`
+	Type      string    `bson:"type"`
+	PlayerID  string    `bson:"playerId,omitempty"` // The player the event is about
+	Detail    string    `bson:"detail,omitempty"`
+	Movement  *Movement `bson:"movement,omitempty"`
+	Calendar  *Calendar `bson:"calendar,omitempty"` // The calendar of the event's year
+	CreatedAt time.Time `bson:"createdAt"`
+}
@@ -9,21 +9,222 @@ type Game struct {
 	MaxPlayers     int       `bson:"maxPlayers"`
 	CurrentPlayers int       `bson:"currentPlayers"`
 	PlayerList     []string  `bson:"playerList"`
-	State          string    `bson:"state"` // "waiting" or "started"
+	State          GameState `bson:"state"`
 	CurrentYear    int       `bson:"currentYear"`
 	CreatedAt      time.Time `bson:"createdAt"`
 	StartedAt      *time.Time `bson:"startedAt,omitempty"`
 	LastTickAt     *time.Time `bson:"lastTickAt,omitempty"`
+	TickClaim      *TickClaim `bson:"tickClaim,omitempty"` // Held by the engine instance running the current year's tick
+	Seeds          *GameSeeds `bson:"seeds,omitempty"` // Per-subsystem RNG streams (set when the map is generated)
+	Rules          *Ruleset   `bson:"rules,omitempty"` // Balance constants pinned when the game starts
+
+	// SimulationFidelity selects how settlement humans are simulated within a
+	// year tick: "daily" (365 micro-steps), "aggregated" (one fast step) or
+	// "attention" (daily where players are looking, cohorts elsewhere)
+	SimulationFidelity string `bson:"simulationFidelity,omitempty"`
+
+	// StartMode selects how the first settlement is placed: "auto" (random walk
+	// then settle) or "player" (the player issues a settle order before the
+	// settle deadline, after which the engine auto-settles)
+	StartMode              string `bson:"startMode,omitempty"`
+	SettleTimeLimitSeconds int    `bson:"settleTimeLimitSeconds,omitempty"`
+
+	// SettlementMergeRule decides what happens when two settlements of the same
+	// player grow adjacent: nothing (default), "merge" or "suburb"
+	SettlementMergeRule string `bson:"settlementMergeRule,omitempty"`
+
+	// GovernorAfterTicks is how many ticks a player may go without connecting
+	// before the governor takes over their decisions (0 uses the default)
+	GovernorAfterTicks int `bson:"governorAfterTicks,omitempty"`
+
+	// Persistent games keep accepting players after they start
+	Persistent bool `bson:"persistent,omitempty"`
+
+	// NoShowGraceTicks is how many ticks a player who has never connected
+	// keeps their civ before NoShowPolicy reclaims it (0 disables reclamation)
+	NoShowGraceTicks int    `bson:"noShowGraceTicks,omitempty"`
+	NoShowPolicy     string `bson:"noShowPolicy,omitempty"`
+
+	// EliminatedPlayers lost their last settlement and unit, or surrendered
+	EliminatedPlayers []string `bson:"eliminatedPlayers,omitempty"`
+
+	// TeamCount is how many teams the game is played in (0 for free-for-all);
+	// Teams maps each player to their team index
+	TeamCount int            `bson:"teamCount,omitempty"`
+	Teams     map[string]int `bson:"teams,omitempty"`
+
+	// Winners are the players, or team members, who won an ended game
+	Winners []string `bson:"winners,omitempty"`
+
+	// FireMastery is the year each player first mastered fire
+	FireMastery map[string]int `bson:"fireMastery,omitempty"`
+
+	// Schedule, when set, restricts the times the game ticks
+	Schedule *TickSchedule `bson:"schedule,omitempty"`
+
+	// ClockAdjustments record engine downtime that was skipped rather than replayed
+	ClockAdjustments []ClockAdjustment `bson:"clockAdjustments,omitempty"`
+
+	// Pacing names the schedule of years each tick advances the game, see
+	// PacingSchedules (default one year a tick)
+	Pacing string `bson:"pacing,omitempty"`
+
+	// Calendar is the year calendar of the current year, refreshed every tick
+	Calendar *Calendar `bson:"calendar,omitempty"`
+
+	// ShareCode, when set, is the shared world the game's map is generated from
+	ShareCode string `bson:"shareCode,omitempty"`
+}
+
+// TickClaim marks a year's tick as taken by one engine instance, so no other
+// instance runs it too. A claim older than its lease is abandoned and may be
+// taken again.
+type TickClaim struct {
+	Year      int       `bson:"year"`
+	ClaimedAt time.Time `bson:"claimedAt"`
+}
+
+// ClockAdjustment records ticks a game skipped while the engine was down
+type ClockAdjustment struct {
+	At           time.Time `bson:"at"`
+	Year         int       `bson:"year"`
+	SkippedTicks int       `bson:"skippedTicks"`
+}
+
+// TickInterval is the real time between game ticks (one game year, or more
+// under a pacing schedule)
+const TickInterval = time.Second
+
+// IsEliminated reports whether a player is out of the game
+func (g *Game) IsEliminated(playerID string) bool {
+	for _, id := range g.EliminatedPlayers {
+		if id == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// ActivePlayers returns the players who have not been eliminated
+func (g *Game) ActivePlayers() []string {
+	active := make([]string, 0, len(g.PlayerList))
+	for _, playerID := range g.PlayerList {
+		if !g.IsEliminated(playerID) {
+			active = append(active, playerID)
+		}
+	}
+	return active
+}
+
+// PlayerActivity records the last tick a player used the game's API
+type PlayerActivity struct {
+	GameID         string    `bson:"gameId"`
+	PlayerID       string    `bson:"playerId"`
+	LastActiveTick int       `bson:"lastActiveTick"`
+	LastActiveAt   time.Time `bson:"lastActiveAt"`
+}
+
+// DefaultGovernorAfterTicks is how long a player may be absent before the governor takes over
+const DefaultGovernorAfterTicks = 100
+
+// GovernorThreshold returns how many ticks of absence hand a player to the governor
+func (g *Game) GovernorThreshold() int {
+	if g.GovernorAfterTicks > 0 {
+		return g.GovernorAfterTicks
+	}
+	return DefaultGovernorAfterTicks
+}
+
+// Policies for players who never connect after the game starts
+const (
+	NoShowPolicyGovernor = "governor" // The governor plays their civ (default)
+	NoShowPolicyRelease  = "release"  // Their civ is removed and the region freed for late joiners
+)
+
+// ReleasesNoShows reports whether no-show players are removed to free their
+// region; only persistent games, which take late joiners, do so
+func (g *Game) ReleasesNoShows() bool {
+	return g.NoShowGraceTicks > 0 && g.NoShowPolicy == NoShowPolicyRelease && g.Persistent
+}
+
+// Simulation fidelity levels for per-settlement human simulation
+const (
+	SimulationFidelityDaily      = "daily"
+	SimulationFidelityAggregated = "aggregated"
+	SimulationFidelityAttention  = "attention"
+)
+
+// Fidelity returns the game's simulation fidelity, defaulting to daily
+func (g *Game) Fidelity() string {
+	switch g.SimulationFidelity {
+	case SimulationFidelityAggregated, SimulationFidelityAttention:
+		return g.SimulationFidelity
+	}
+	return SimulationFidelityDaily
+}
+
+// Start modes for placing the first settlement
+const (
+	StartModeAuto   = "auto"
+	StartModePlayer = "player"
+)
+
+// Rules for adjacent settlements of the same player
+const (
+	SettlementMergeNone   = ""
+	SettlementMergeMerge  = "merge"  // The smaller settlement is absorbed into the larger
+	SettlementMergeSuburb = "suburb" // The smaller settlement becomes a suburb of the larger
+)
+
+// DefaultSettleTimeLimit is how long players have to settle in player start mode
+const DefaultSettleTimeLimit = 60 * time.Second
+
+// PlayerDirectedStart returns true if players place their first settlement
+func (g *Game) PlayerDirectedStart() bool {
+	return g.StartMode == StartModePlayer
+}
+
+// SettleDeadline returns when the engine stops waiting for settle orders.
+// Games that have not started have no deadline (zero time).
+func (g *Game) SettleDeadline() time.Time {
+	if g.StartedAt == nil {
+		return time.Time{}
+	}
+	limit := DefaultSettleTimeLimit
+	if g.SettleTimeLimitSeconds > 0 {
+		limit = time.Duration(g.SettleTimeLimitSeconds) * time.Second
+	}
+	return g.StartedAt.Add(limit)
 }
 
 // IsWaiting returns true if the game is waiting for players
 func (g *Game) IsWaiting() bool {
-	return g.State == "waiting"
+	return g.State == GameStateWaiting
+}
+
+// IsStarting returns true if the game is full and awaiting its map
+func (g *Game) IsStarting() bool {
+	return g.State == GameStateStarting
 }
 
-// IsStarted returns true if the game has started
+// IsStarted returns true if the game has started and is ticking
 func (g *Game) IsStarted() bool {
-	return g.State == "started"
+	return g.State == GameStateActive
+}
+
+// IsPaused returns true if the game is being held without ticking
+func (g *Game) IsPaused() bool {
+	return g.State == GameStatePaused
+}
+
+// IsEnded returns true if the game has been won
+func (g *Game) IsEnded() bool {
+	return g.State == GameStateFinished
+}
+
+// IsRunning returns true if the engine drives the game: it is starting or active
+func (g *Game) IsRunning() bool {
+	return g.IsStarting() || g.IsStarted()
 }
 
 // ShouldTick returns true if the game needs a tick processed
@@ -32,10 +233,20 @@ func (g *Game) ShouldTick() bool {
 		return false
 	}
 
+	// Nothing is left to simulate once every player has been eliminated
+	if len(g.PlayerList) > 0 && len(g.ActivePlayers()) == 0 {
+		return false
+	}
+
+	// Scheduled pauses hold the game outside its tick windows
+	if g.Schedule != nil && !g.Schedule.Allows(time.Now()) {
+		return false
+	}
+
 	if g.LastTickAt == nil {
 		return true
 	}
 
 	// Tick every second (1 game year per real second)
-	return time.Since(*g.LastTickAt) >= time.Second
+	return time.Since(*g.LastTickAt) >= TickInterval
 }
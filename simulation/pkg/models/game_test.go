@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -8,7 +10,7 @@ import (
 func TestGame_IsWaiting(t *testing.T) {
 	tests := []struct {
 		name     string
-		state    string
+		state    GameState
 		expected bool
 	}{
 		{"Waiting game", "waiting", true},
@@ -29,7 +31,7 @@ func TestGame_IsWaiting(t *testing.T) {
 func TestGame_IsStarted(t *testing.T) {
 	tests := []struct {
 		name     string
-		state    string
+		state    GameState
 		expected bool
 	}{
 		{"Started game", "started", true},
@@ -55,7 +57,7 @@ func TestGame_ShouldTick(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		state      string
+		state      GameState
 		lastTickAt *time.Time
 		expected   bool
 	}{
@@ -110,3 +112,255 @@ func TestGame_Fields(t *testing.T) {
 		t.Errorf("CurrentYear = %v, want -5000", game.CurrentYear)
 	}
 }
+
+func TestNewGameSeeds_IndependentStreams(t *testing.T) {
+	seeds := NewGameSeeds("master-seed")
+
+	if seeds.Master != "master-seed" {
+		t.Errorf("Master = %v, want master-seed", seeds.Master)
+	}
+	if seeds.Movement.Seed == seeds.Combat.Seed || seeds.Movement.Seed == seeds.Events.Seed {
+		t.Error("Named streams should have distinct seeds")
+	}
+
+	// Drawing from one stream must not affect another
+	combatBefore := seeds.Combat.Clone().Uint64()
+	seeds.Movement.Uint64()
+	if seeds.Stream(SeedStreamCombat).Uint64() != combatBefore {
+		t.Error("Movement draws perturbed the combat stream")
+	}
+	if seeds.Stream("unknown") != nil {
+		t.Error("Unknown stream name should return nil")
+	}
+}
+
+func TestGame_Teams(t *testing.T) {
+	game := &Game{
+		PlayerList: []string{"a", "b", "c"},
+		TeamCount:  2,
+		Teams:      map[string]int{"a": 0, "b": 1, "c": 0},
+	}
+
+	if !game.Allied("a", "c") || game.Allied("a", "b") {
+		t.Error("Expected a and c allied, a and b not")
+	}
+	if teammates := game.Teammates("a"); len(teammates) != 2 || teammates[0] != "a" || teammates[1] != "c" {
+		t.Errorf("Teammates(a) = %v, want [a c]", teammates)
+	}
+	if game.DefaultStance("c", "a") != StanceAllied || game.DefaultStance("b", "c") != StanceNeutral {
+		t.Error("Expected teammates allied and opponents neutral by default")
+	}
+
+	ffa := &Game{PlayerList: []string{"a", "b"}}
+	if ffa.TeamOf("a") != NoTeam || ffa.Allied("a", "b") || !ffa.Allied("a", "a") {
+		t.Error("Expected no alliances in a free-for-all")
+	}
+}
+
+func TestTickSchedule_Allows(t *testing.T) {
+	// 2024-01-06 is a Saturday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name     string
+		schedule TickSchedule
+		when     time.Time
+		expected bool
+	}{
+		{"Empty schedule always ticks", TickSchedule{}, at(6, 3, 0), true},
+		{"Inside daytime window", TickSchedule{WindowStart: "08:00", WindowEnd: "23:00"}, at(8, 8, 0), true},
+		{"Before daytime window", TickSchedule{WindowStart: "08:00", WindowEnd: "23:00"}, at(8, 7, 59), false},
+		{"Window end is exclusive", TickSchedule{WindowStart: "08:00", WindowEnd: "23:00"}, at(8, 23, 0), false},
+		{"Overnight window after midnight", TickSchedule{WindowStart: "22:00", WindowEnd: "06:00"}, at(8, 1, 30), true},
+		{"Overnight window at midday", TickSchedule{WindowStart: "22:00", WindowEnd: "06:00"}, at(8, 12, 0), false},
+		{"Weekend pause on Saturday", TickSchedule{PauseWeekends: true}, at(6, 12, 0), false},
+		{"Weekend pause on Monday", TickSchedule{PauseWeekends: true}, at(8, 12, 0), true},
+		{"Malformed window is ignored", TickSchedule{WindowStart: "8am", WindowEnd: "23:00"}, at(8, 3, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Allows(tt.when); got != tt.expected {
+				t.Errorf("Allows() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGameState_Transitions(t *testing.T) {
+	allowed := [][2]GameState{
+		{GameStateWaiting, GameStateStarting},
+		{GameStateStarting, GameStateActive},
+		{GameStateActive, GameStatePaused},
+		{GameStatePaused, GameStateActive},
+		{GameStateActive, GameStateFinished},
+		{GameStateFinished, GameStateArchived},
+	}
+	for _, tt := range allowed {
+		if err := ValidateTransition(tt[0], tt[1]); err != nil {
+			t.Errorf("Expected %s -> %s to be allowed: %v", tt[0], tt[1], err)
+		}
+	}
+
+	forbidden := [][2]GameState{
+		{GameStateWaiting, GameStateActive},
+		{GameStateActive, GameStateWaiting},
+		{GameStateActive, GameStateArchived},
+		{GameStateFinished, GameStateActive},
+		{GameStateArchived, GameStateWaiting},
+		{GameStateActive, GameStateActive},
+	}
+	for _, tt := range forbidden {
+		if err := ValidateTransition(tt[0], tt[1]); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Expected %s -> %s to be rejected, got %v", tt[0], tt[1], err)
+		}
+	}
+}
+
+func TestCalendarAt(t *testing.T) {
+	tests := []struct {
+		year, day  int
+		month      string
+		dayOfMonth int
+		season     string
+		era        string
+	}{
+		{-5000, 1, "January", 1, SeasonWinter, "ancient"},
+		{-1000, WinterDays, "March", 31, SeasonWinter, "classical"},
+		{-999, WinterDays + 1, "April", 1, SeasonSpring, "classical"},
+		{1492, 200, "July", 19, SeasonSummer, "renaissance"},
+		{1900, DaysPerYear, "December", 31, SeasonAutumn, "modern"},
+		{-6000, DaysPerYear + 1, "January", 1, SeasonWinter, "ancient"}, // Days wrap into the year
+	}
+	for _, tt := range tests {
+		calendar := CalendarAt(tt.year, tt.day)
+		if calendar.Month != tt.month || calendar.DayOfMonth != tt.dayOfMonth || calendar.Season != tt.season || calendar.Era != tt.era {
+			t.Errorf("CalendarAt(%d, %d) = %+v, want %s %d, %s, %s era", tt.year, tt.day, calendar, tt.month, tt.dayOfMonth, tt.season, tt.era)
+		}
+	}
+
+	// Winter days are the shortest and summer days the longest
+	if winter, summer := CalendarAt(0, 45), CalendarAt(0, 228); winter.Daylight >= 9 || summer.Daylight <= 15 || !winter.IsWinter() {
+		t.Errorf("Expected short winter and long summer days, got %.1f and %.1f hours", winter.Daylight, summer.Daylight)
+	}
+
+	// Turns of eras, centuries and millennia are flagged
+	if c := CalendarAt(-1000, 1); !c.NewEra || !c.NewCentury || !c.NewMillennium {
+		t.Errorf("Expected year -1000 to begin an era, century and millennium, got %+v", c)
+	}
+	if c := CalendarAt(1750, 1); !c.NewEra || c.NewCentury {
+		t.Errorf("Expected year 1750 to begin an era mid-century, got %+v", c)
+	}
+	if c := CalendarAt(-999, 1); c.NewEra || c.NewCentury || c.NewMillennium {
+		t.Errorf("Expected year -999 to begin nothing, got %+v", c)
+	}
+
+	// A year calendar has the year's era but no day within it
+	if c := YearCalendar(1492); c.Era != "renaissance" || c.Day != 0 || c.Month != "" || c.Season != "" || c.IsWinter() {
+		t.Errorf("Expected the year 1492 dated without a day, got %+v", c)
+	}
+}
+
+func TestGame_Pacing(t *testing.T) {
+	// Unpaced games advance a year a tick
+	game := &Game{CurrentYear: -5000}
+	if years := game.YearsPerTick(); years != 1 {
+		t.Errorf("Expected one year a tick by default, got %d", years)
+	}
+
+	// Era pacing lands a tick on every era's first year, tapering to one year
+	game = &Game{CurrentYear: -5000, Pacing: PacingEras}
+	eraStarts := map[int]bool{}
+	ticks := 0
+	for game.CurrentYear < 2000 {
+		previous := game.CurrentYear
+		game.CurrentYear += game.YearsPerTick()
+		ticks++
+		if got := game.PreviousTickYear(); got != previous {
+			t.Fatalf("Expected the tick before year %d to start in %d, got %d", game.CurrentYear, previous, got)
+		}
+		if CalendarAt(game.CurrentYear, 1).NewEra {
+			eraStarts[game.CurrentYear] = true
+		}
+	}
+	if len(eraStarts) != len(Eras)-1 {
+		t.Errorf("Expected a tick at the start of every later era, got %v", eraStarts)
+	}
+	if want := 160 + 150 + 180 + 250 + 100; ticks != want || game.TicksSince(-5000) != want {
+		t.Errorf("Expected %d ticks from 5000 BC to 2000, got %d (TicksSince %d)", want, ticks, game.TicksSince(-5000))
+	}
+	if got := game.TicksSince(1000); got != 80+250+100 {
+		t.Errorf("Expected 430 ticks since year 1000, got %d", got)
+	}
+
+	// Counting ticks in one go agrees with taking them one at a time, from
+	// years off the step boundaries too
+	for _, since := range []int{-5010, -4990, -1003, 499, 1401, 1899} {
+		walker := &Game{CurrentYear: since, Pacing: PacingEras}
+		for ticks := 0; walker.CurrentYear < 1950; ticks++ {
+			game.CurrentYear = walker.CurrentYear
+			if got := game.TicksSince(since); got != ticks {
+				t.Fatalf("Expected %d ticks from %d to %d, got %d", ticks, since, walker.CurrentYear, got)
+			}
+			walker.CurrentYear += walker.YearsPerTick()
+		}
+	}
+
+	// A tick spanning years meets every multiple of an interval it covers
+	game = &Game{CurrentYear: -4975, Pacing: PacingEras}
+	if got := game.TickYearsEvery(10); !reflect.DeepEqual(got, []int{-4970, -4960}) {
+		t.Errorf("Expected the tick from -4975 to meet -4970 and -4960, got %v", got)
+	}
+	game = &Game{CurrentYear: -4999}
+	if got := game.TickYearsEvery(10); len(got) != 0 {
+		t.Errorf("Expected a one-year tick off the decade to meet none, got %v", got)
+	}
+	game.CurrentYear = -4990
+	if got := game.TickYearsEvery(10); !reflect.DeepEqual(got, []int{-4990}) {
+		t.Errorf("Expected a one-year tick on the decade to meet it, got %v", got)
+	}
+}
+
+func TestGame_Ruleset(t *testing.T) {
+	game := &Game{}
+	if rules := game.Ruleset(); rules.Version != CurrentRulesVersion {
+		t.Errorf("Expected an unpinned game to play the current rules, got version %d", rules.Version)
+	}
+
+	// Rules pinned before version 2 keep their own values and read the
+	// fields version 2 added from their release
+	game.Rules = &Ruleset{Version: 1, SettlersWalkSteps: 7, SettlementWorkRadius: 2}
+	rules := game.Ruleset()
+	if rules.SettlersWalkSteps != 7 || rules.SettlementWorkRadius != 2 {
+		t.Errorf("Expected the pinned values kept, got %+v", rules)
+	}
+	if rules.EarthquakeChance != RulesetVersion(1).EarthquakeChance || rules.GreatPersonThreshold != RulesetVersion(1).GreatPersonThreshold {
+		t.Errorf("Expected the fields version 2 added read from version 1, got %+v", rules)
+	}
+
+	// Rules pinned at version 2 keep the rates version 2 added and read
+	// those version 3 added from their release
+	game.Rules = &Ruleset{Version: 2, SettlersWalkSteps: 7, EarthquakeChance: 0.5}
+	rules = game.Ruleset()
+	if rules.SettlersWalkSteps != 7 || rules.EarthquakeChance != 0.5 {
+		t.Errorf("Expected the pinned values kept, got %+v", rules)
+	}
+	if rules.Settlement != DesignedSettlementRates || rules.BuildingCosts[BuildingHarbor] != RulesetVersion(2).BuildingCosts[BuildingHarbor] {
+		t.Errorf("Expected the fields version 3 added read from version 2, got %+v", rules)
+	}
+
+	// Current rules play as pinned, zeroes included
+	game.Rules = &Ruleset{Version: CurrentRulesVersion, EarthquakeChance: 0}
+	if game.Ruleset() != game.Rules {
+		t.Errorf("Expected version %d rules to play as pinned", CurrentRulesVersion)
+	}
+
+	// Released tables are copied, so tuning a game's rules leaves the release alone
+	tuned := DefaultRuleset()
+	tuned.BuildingCosts[BuildingHarbor] = 1
+	if DefaultRuleset().BuildingCosts[BuildingHarbor] == 1 {
+		t.Error("Expected the released building costs untouched")
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GameState is a stage in a game's lifecycle
+type GameState string
+
+// Game lifecycle states. Active and finished games keep the "started" and
+// "ended" values games were stored with before the lifecycle was typed.
+const (
+	GameStateWaiting  GameState = "waiting"  // Lobby open for players
+	GameStateStarting GameState = "starting" // Full; the map is being generated
+	GameStateActive   GameState = "started"  // Ticking
+	GameStatePaused   GameState = "paused"   // Held without ticking
+	GameStateFinished GameState = "ended"    // Won, or otherwise over
+	GameStateArchived GameState = "archived" // Retained for history only
+)
+
+// gameTransitions lists the states each state may move to
+var gameTransitions = map[GameState][]GameState{
+	GameStateWaiting:  {GameStateStarting, GameStateArchived},
+	GameStateStarting: {GameStateActive},
+	GameStateActive:   {GameStatePaused, GameStateFinished},
+	GameStatePaused:   {GameStateActive, GameStateFinished},
+	GameStateFinished: {GameStateArchived},
+}
+
+// ErrInvalidTransition is returned for a lifecycle change the state machine forbids
+var ErrInvalidTransition = errors.New("invalid game state transition")
+
+// CanTransitionTo reports whether a game in state s may move to next
+func (s GameState) CanTransitionTo(next GameState) bool {
+	for _, allowed := range gameTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns ErrInvalidTransition unless from may move to to
+func ValidateTransition(from, to GameState) error {
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %q to %q", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
@@ -4,21 +4,94 @@ import "time"
 
 // MapTile represents a single tile on the game map
 type MapTile struct {
-	GameID       string    `bson:"gameId"`
-	X            int       `bson:"x"`
-	Y            int       `bson:"y"`
-	Elevation    int       `bson:"elevation"`    // Meters above sea level (-100 to 3000)
-	TerrainType  string    `bson:"terrainType"`  // OCEAN, GRASSLAND, FOREST, MOUNTAIN, etc.
-	ClimateZone  string    `bson:"climateZone"`  // POLAR, TEMPERATE, TROPICAL, etc.
-	HasRiver     bool      `bson:"hasRiver"`     // True if river flows through tile
-	IsCoastal    bool      `bson:"isCoastal"`    // True if land adjacent to water
-	Resources    []string  `bson:"resources"`    // Array of resource types on this tile
-	Improvements []string  `bson:"improvements"` // Player-built improvements
-	OwnerID      *string   `bson:"ownerId,omitempty"`
-	VisibleTo    []string  `bson:"visibleTo"`
-	CreatedAt    time.Time `bson:"createdAt"`
+	GameID             string         `bson:"gameId"`
+	X                  int            `bson:"x"`
+	Y                  int            `bson:"y"`
+	Elevation          int            `bson:"elevation"`                    // Meters above sea level (-100 to 3000)
+	TerrainType        string         `bson:"terrainType"`                  // OCEAN, GRASSLAND, FOREST, MOUNTAIN, etc.
+	ClimateZone        string         `bson:"climateZone"`                  // POLAR, TEMPERATE, TROPICAL, etc.
+	HasRiver           bool           `bson:"hasRiver"`                     // True if river flows through tile
+	RiverID            int            `bson:"riverId,omitempty"`            // River system the tile's river belongs to (0 if none)
+	HasCave            bool           `bson:"hasCave,omitempty"`            // True if the tile holds a cave system
+	IsCoastal          bool           `bson:"isCoastal"`                    // True if land adjacent to water
+	Resources          []string       `bson:"resources"`                    // Array of resource types on this tile
+	ResourceQuantities map[string]int `bson:"resourceQuantities,omitempty"` // Remaining units per finite/renewable resource
+	Fertility          float64        `bson:"fertility,omitempty"`          // Soil quality as a food multiplier (1.0 is good farmland; 0 if untracked)
+	NaturalFertility   float64        `bson:"naturalFertility,omitempty"`   // Fertility fallow soil recovers to
+	Pollution          float64        `bson:"pollution,omitempty"`          // Environmental degradation, 0 (clean) to 1 (ruined)
+	Improvements       []string       `bson:"improvements"`                 // Player-built improvements
+	Damaged            []string       `bson:"damaged,omitempty"`            // Improvements a disaster wrecked, awaiting repair by workers
+	OwnerID            *string        `bson:"ownerId,omitempty"`
+	SettlementID       string         `bson:"settlementId,omitempty"` // Settlement working this tile
+	VisibleTo          []string       `bson:"visibleTo"`
+	LastModifiedTick   int            `bson:"lastModifiedTick"` // Game year of the last ownership, improvement, resource, soil, pollution or visibility change
+	CreatedAt          time.Time      `bson:"createdAt"`
+}
+
+// ExploredTile is a player's last-seen record of a tile. Tiles a player owns or
+// currently sees are shown live; explored tiles outside that show this record.
+type ExploredTile struct {
+	GameID       string   `bson:"gameId"`
+	PlayerID     string   `bson:"playerId"`
+	X            int      `bson:"x"`
+	Y            int      `bson:"y"`
+	TerrainType  string   `bson:"terrainType"`
+	Resources    []string `bson:"resources"`
+	Improvements []string `bson:"improvements"`
+	OwnerID      *string  `bson:"ownerId,omitempty"`
+	SeenYear     int      `bson:"seenYear"` // Game year the recorded state was observed
+}
+
+// Minimap is a downsampled, fogged overview of the map for one player. Terrain
+// and Owners are row-major strings with one character per cell.
+type Minimap struct {
+	GameID      string            `bson:"gameId"`
+	PlayerID    string            `bson:"playerId"`
+	Width       int               `bson:"width"`
+	Height      int               `bson:"height"`
+	Terrain     string            `bson:"terrain"` // Terrain code per cell, MinimapUnknown if unexplored
+	Owners      string            `bson:"owners"`  // Owner's index in the player list (0-9 then A-Z), MinimapMinorCiv, MinimapUnowned or MinimapUnknown
+	Legend      map[string]string `bson:"legend"`  // Terrain code -> terrain type
+	Year        int               `bson:"year"`
+	GeneratedAt time.Time         `bson:"generatedAt"`
+}
+
+// Minimap cell codes shared by the terrain and owner layers
+const (
+	MinimapUnknown  = '?'
+	MinimapUnowned  = '.'
+	MinimapMinorCiv = '*' // Owner code of every minor civilization
+)
+
+// Borders are the outlines of the territories one player knows of, kept so
+// clients can draw borders without scanning every tile. Lines run along tile
+// edges between tile corners; tile (x, y) spans corners (x, y) to (x+1, y+1).
+type Borders struct {
+	GameID      string            `bson:"gameId"`
+	PlayerID    string            `bson:"playerId"`
+	Territories []TerritoryBorder `bson:"territories"` // By owner ID
+	Year        int               `bson:"year"`
+	GeneratedAt time.Time         `bson:"generatedAt"`
+}
+
+// TerritoryBorder is the outline of one owner's territory as a player knows
+// it. Each line runs clockwise around the territory, with y growing down, and
+// is closed when its ends meet; it stops short where the player has not
+// explored.
+type TerritoryBorder struct {
+	OwnerID string       `bson:"ownerId"`
+	Lines   [][]Location `bson:"lines"` // Corners where the line turns, ends included
 }
 
+// Tile improvements
+const (
+	ImprovementRoad = "ROAD" // Connects territory for trade
+	ImprovementFarm = "FARM"
+	ImprovementMine = "MINE"
+
+	ImprovementPasture = "PASTURE" // Herds bred rather than hunted; needs husbandry
+)
+
 // StartingPosition represents a player's starting position on the map
 type StartingPosition struct {
 	GameID            string    `bson:"gameId"`
@@ -35,7 +108,8 @@ type StartingPosition struct {
 		MinY int `bson:"minY"`
 		MaxY int `bson:"maxY"`
 	} `bson:"guaranteedFootprint"`
-	CreatedAt time.Time `bson:"createdAt"`
+	AssignedYear int       `bson:"assignedYear"` // Game year the player was given the region
+	CreatedAt    time.Time `bson:"createdAt"`
 }
 
 // GreatCircle represents a great circle used for terrain generation
@@ -51,15 +125,65 @@ type GreatCircle struct {
 	Weight         float64 `bson:"weight"`
 }
 
+// Map feature types tagged during generation
+const (
+	FeatureStrait        = "STRAIT"         // Narrow water passage between two land masses
+	FeatureIsthmus       = "ISTHMUS"        // Narrow land bridge between two water bodies
+	FeatureHarbor        = "HARBOR"         // Coastal land beside a sheltered bay
+	FeatureIslandChain   = "ISLAND_CHAIN"   // Group of nearby small islands
+	FeatureMountainRange = "MOUNTAIN_RANGE" // Colliding continental plates
+	FeatureRiftValley    = "RIFT_VALLEY"    // Continental plates pulling apart
+	FeatureVolcanicArc   = "VOLCANIC_ARC"   // Plate overriding a sinking oceanic plate
+)
+
+// TectonicPlate is a plate the generator shaped the terrain with. Tiles
+// belong to the plate with the nearest seed; plates collide or separate
+// according to their drift.
+type TectonicPlate struct {
+	ID          int     `bson:"id"`
+	SeedX       int     `bson:"seedX"`
+	SeedY       int     `bson:"seedY"`
+	Continental bool    `bson:"continental"`
+	DriftX      float64 `bson:"driftX"` // Unit direction of motion
+	DriftY      float64 `bson:"driftY"`
+}
+
+// MapFeature tags a strategically notable location on the map
+type MapFeature struct {
+	Type string `bson:"type"`
+	X    int    `bson:"x"` // Representative tile
+	Y    int    `bson:"y"`
+	Size int    `bson:"size"` // Tiles in the feature (islands for ISLAND_CHAIN)
+}
+
 // MapMetadata stores metadata about map generation
 type MapMetadata struct {
-	GameID           string        `bson:"gameId"`
-	Seed             string        `bson:"seed"`
-	Width            int           `bson:"width"`
-	Height           int           `bson:"height"`
-	PlayerCount      int           `bson:"playerCount"`
-	SeaLevel         int           `bson:"seaLevel"`
-	GreatCircles     []GreatCircle `bson:"greatCircles"`
-	GeneratedAt      time.Time     `bson:"generatedAt"`
-	GenerationTimeMs int64         `bson:"generationTimeMs"`
+	GameID           string          `bson:"gameId"`
+	Seed             string          `bson:"seed"`
+	Width            int             `bson:"width"`
+	Height           int             `bson:"height"`
+	PlayerCount      int             `bson:"playerCount"`
+	SeaLevel         int             `bson:"seaLevel"`
+	LandRatio        float64         `bson:"landRatio"` // Achieved fraction of land tiles
+	GreatCircles     []GreatCircle   `bson:"greatCircles"`
+	Plates           []TectonicPlate `bson:"plates"`
+	Features         []MapFeature    `bson:"features"`
+	MinorCivSites    []Location      `bson:"minorCivSites,omitempty"` // Where minor civilizations are founded
+	Stats            *MapStats       `bson:"stats,omitempty"`
+	ShareCode        string          `bson:"shareCode,omitempty"` // Generates this world again in a new game
+	GeneratedAt      time.Time       `bson:"generatedAt"`
+	GenerationTimeMs int64           `bson:"generationTimeMs"`
+}
+
+// MapStats summarizes a generated map for balance checks
+type MapStats struct {
+	LandPercentage   float64        `bson:"landPercentage"`   // Share of tiles that are land, 0-100
+	TerrainCounts    map[string]int `bson:"terrainCounts"`    // Tiles per terrain type
+	ResourceCounts   map[string]int `bson:"resourceCounts"`   // Occurrences per resource type
+	RiverTiles       int            `bson:"riverTiles"`       // Land tiles carrying a river
+	RiverSystems     int            `bson:"riverSystems"`     // Separate navigable river networks
+	CaveTiles        int            `bson:"caveTiles"`        // Tiles holding a cave system
+	AverageFertility float64        `bson:"averageFertility"` // Mean soil fertility of land tiles
+	ContinentCount   int            `bson:"continentCount"`   // Separate 8-connected landmasses
+	LargestContinent int            `bson:"largestContinent"` // Tiles in the largest landmass
 }
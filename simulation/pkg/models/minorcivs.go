@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// MinorCivPrefix starts the player ID a minor civilization owns its
+// settlement under
+const MinorCivPrefix = "minor:"
+
+// IsMinorCiv reports whether a player ID belongs to a minor civilization
+func IsMinorCiv(playerID string) bool {
+	return strings.HasPrefix(playerID, MinorCivPrefix)
+}
+
+// Minor civilization quest types
+const (
+	MinorQuestGift  = "gift"  // Send the minor civ an amount of production at once
+	MinorQuestVisit = "visit" // Bring a unit within sight of its settlement
+)
+
+// MinorQuest is a favor a minor civ asks of every player; the first to do it
+// earns its friendship
+type MinorQuest struct {
+	Type    string `bson:"type"`
+	Amount  int    `bson:"amount,omitempty"` // Production a gift quest asks for
+	Expires int    `bson:"expires"`          // Year the quest lapses
+}
+
+// MinorCiv is an AI-run city-state with a single settlement. Players befriend
+// it with gifts and quests or conquer it.
+type MinorCiv struct {
+	GameID       string         `bson:"gameId"`
+	MinorCivID   string         `bson:"minorCivId"` // Also the player ID its settlement is owned by
+	Name         string         `bson:"name"`
+	SettlementID string         `bson:"settlementId"`
+	Friendship   map[string]int `bson:"friendship"`            // Goodwill toward each player
+	Ally         string         `bson:"ally,omitempty"`        // Player it is allied with, who defends it
+	Quest        *MinorQuest    `bson:"quest"`                 // Favor it is asking for, if any
+	ConqueredBy  string         `bson:"conqueredBy,omitempty"` // Player who took its settlement
+	LastUpdated  time.Time      `bson:"lastUpdated"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// MovementKeyframe is a tile a moving unit passes through and when it gets
+// there, in milliseconds from the start of the move
+type MovementKeyframe struct {
+	X    int `bson:"x"`
+	Y    int `bson:"y"`
+	AtMs int `bson:"atMs"`
+}
+
+// Movement is one tick of a unit's travel along its path, timed so clients
+// can animate it smoothly instead of jumping to the final position
+type Movement struct {
+	UnitID     string             `bson:"unitId"`
+	Keyframes  []MovementKeyframe `bson:"keyframes"` // Starts at the unit's origin
+	DurationMs int                `bson:"durationMs"`
+}
+
+// NewMovement spreads a path's tiles evenly over one tick; path starts at
+// the unit's origin
+func NewMovement(unitID string, path []Location) *Movement {
+	duration := int(TickInterval / time.Millisecond)
+	movement := &Movement{
+		UnitID:     unitID,
+		Keyframes:  make([]MovementKeyframe, len(path)),
+		DurationMs: duration,
+	}
+	for i, loc := range path {
+		at := 0
+		if len(path) > 1 {
+			at = i * duration / (len(path) - 1)
+		}
+		movement.Keyframes[i] = MovementKeyframe{X: loc.X, Y: loc.Y, AtMs: at}
+	}
+	return movement
+}
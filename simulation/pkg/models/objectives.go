@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Objective types
+const (
+	ObjectiveCoastalSettlement  = "coastal_settlement"  // Hold a settlement whose work area reaches water
+	ObjectiveConnectSettlements = "connect_settlements" // Link two settlements by road
+	ObjectivePopulation         = "population"          // Grow the civ's people to Target
+	ObjectiveSettlements        = "settlements"         // Hold Target settlements
+	ObjectiveBuilding           = "building"            // Construct the Item building anywhere
+)
+
+// Objective statuses
+const (
+	ObjectiveActive    = "active"
+	ObjectiveCompleted = "completed"
+	ObjectiveFailed    = "failed" // The deadline passed first
+)
+
+// Objective is a short-term goal the engine sets a player, rewarded with
+// production if met by its deadline
+type Objective struct {
+	GameID      string    `bson:"gameId"`
+	ObjectiveID string    `bson:"objectiveId"`
+	PlayerID    string    `bson:"playerId"`
+	Type        string    `bson:"type"`
+	Target      int       `bson:"target,omitempty"` // Population or settlement count to reach
+	Item        string    `bson:"item,omitempty"`   // Building to construct
+	Deadline    int       `bson:"deadline"`         // Year by which it must be met
+	Reward      int       `bson:"reward"`           // Production granted on completion
+	Status      string    `bson:"status"`
+	Assigned    int       `bson:"assigned"` // Year it was set
+	Resolved    int       `bson:"resolved"` // Year it was completed or failed
+	LastUpdated time.Time `bson:"lastUpdated"`
+}
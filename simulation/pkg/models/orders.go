@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Order types players can issue
+const (
+	OrderTypeSettle    = "settle"
+	OrderTypeSurrender = "surrender" // Concede the game; needs no unit
+	OrderTypeChop      = "chop"      // Workers fell the forest they stand on
+	OrderTypeBuild     = "build"     // A settlement spends banked production on a building; needs no unit
+	OrderTypeDemand    = "demand"    // Demand tribute or vassalage of another player; needs no unit
+	OrderTypeAccept    = "accept"    // Give in to another player's demand; needs no unit
+	OrderTypeBreak     = "break"     // Break the agreement with another player; needs no unit
+	OrderTypeActivate  = "activate"  // Spend a great person on their ability
+	OrderTypeGift      = "gift"      // Send a minor civ production from a settlement; needs no unit
+	OrderTypeAttack    = "attack"    // Attack an adjacent minor civ settlement to conquer it
+	OrderTypeRoad      = "road"      // Plan a road from a settlement to the target for workers to pave; needs no unit
+	OrderTypeTransform = "transform" // Workers start a long project transforming the terrain they stand on
+	OrderTypeMove      = "move"      // Walk a unit to the target over as many years as the way takes
+)
+
+// Order statuses
+const (
+	OrderStatusPending  = "pending"
+	OrderStatusExecuted = "executed"
+	OrderStatusRejected = "rejected"
+	OrderStatusProposed = "proposed" // Put forward by a settlement's people; waits for the player to approve it
+)
+
+// Order is a player command queued for the engine to execute on its next tick
+type Order struct {
+	OrderID      string     `bson:"orderId"`
+	GameID       string     `bson:"gameId"`
+	PlayerID     string     `bson:"playerId"`
+	UnitID       string     `bson:"unitId"`
+	OrderType    string     `bson:"orderType"`
+	Target       *Location  `bson:"target,omitempty"`       // Defaults to the unit's location; where a road order leads
+	SettlementID string     `bson:"settlementId,omitempty"` // Settlement a build, gift or road order is for, or an attack targets
+	Item         string     `bson:"item,omitempty"`         // Building a build order constructs, agreement type demanded, or transformation to carry out
+	TargetPlayer string     `bson:"targetPlayer,omitempty"` // Other player of a diplomatic order, or minor civ of a gift
+	Amount       int        `bson:"amount,omitempty"`       // Yearly production a tribute demand asks for, or production gifted
+	Status       string     `bson:"status"`
+	Reason       string     `bson:"reason,omitempty"` // Why the order was rejected
+	Path         []Location `bson:"path,omitempty"`   // Tiles the unit crossed carrying it out
+	CreatedAt    time.Time  `bson:"createdAt"`
+	ProcessedAt  *time.Time `bson:"processedAt,omitempty"`
+}
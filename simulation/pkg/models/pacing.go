@@ -0,0 +1,115 @@
+package models
+
+// PacingStep sets how many years each tick advances the game from FromYear
+// until the next step
+type PacingStep struct {
+	FromYear     int `bson:"fromYear"`
+	YearsPerTick int `bson:"yearsPerTick"`
+}
+
+// Year pacing schedules
+const (
+	PacingConstant = "constant" // One year a tick throughout (default)
+	PacingEras     = "eras"     // Long ancient ticks tapering to a year a tick in the modern era
+)
+
+// PacingSchedules are the pacing steps of each schedule, in year order. A
+// tick never crosses into the next step, so each step's span should be a
+// whole number of its ticks to keep its ticks evenly spaced.
+var PacingSchedules = map[string][]PacingStep{
+	PacingConstant: {{FromYear: -5000, YearsPerTick: 1}},
+	PacingEras: {
+		{FromYear: -5000, YearsPerTick: 25}, // Ancient
+		{FromYear: -1000, YearsPerTick: 10}, // Classical
+		{FromYear: 500, YearsPerTick: 5},    // Medieval
+		{FromYear: 1400, YearsPerTick: 2},   // Renaissance and industrial
+		{FromYear: 1900, YearsPerTick: 1},   // Modern
+	},
+}
+
+// pacingSchedule returns the steps of the game's pacing schedule
+func (g *Game) pacingSchedule() []PacingStep {
+	if steps, ok := PacingSchedules[g.Pacing]; ok {
+		return steps
+	}
+	return PacingSchedules[PacingConstant]
+}
+
+// pacingStepAt returns the years per tick of the step year falls in and,
+// unless it is the last step, the year the next step begins. Years before
+// the first step advance one a tick.
+func pacingStepAt(steps []PacingStep, year int) (years, end int, bounded bool) {
+	years = 1
+	for _, step := range steps {
+		if year < step.FromYear {
+			return years, step.FromYear, true
+		}
+		years = max(step.YearsPerTick, 1)
+	}
+	return years, 0, false
+}
+
+// yearsPerTick returns how many years the tick starting in year advances a
+// game, stopping short at the start of the next step
+func yearsPerTick(steps []PacingStep, year int) int {
+	years, end, bounded := pacingStepAt(steps, year)
+	if bounded {
+		return min(years, end-year)
+	}
+	return years
+}
+
+// YearsPerTick returns how many years the game's next tick advances it
+func (g *Game) YearsPerTick() int {
+	return yearsPerTick(g.pacingSchedule(), g.CurrentYear)
+}
+
+// PreviousTickYear returns the year the game's last tick started in
+func (g *Game) PreviousTickYear() int {
+	steps := g.pacingSchedule()
+	previous := g.CurrentYear - 1
+	for _, step := range steps {
+		if step.FromYear >= g.CurrentYear {
+			break
+		}
+		previous = max(g.CurrentYear-max(step.YearsPerTick, 1), step.FromYear)
+	}
+	return previous
+}
+
+// TicksSince returns how many ticks the game has taken since the tick that
+// started in year, which under the constant schedule is the years between
+func (g *Game) TicksSince(year int) int {
+	steps := g.pacingSchedule()
+	ticks := 0
+	for year < g.CurrentYear {
+		// Count the ticks to the end of the step, or to now, in one go
+		years, end, bounded := pacingStepAt(steps, year)
+		target := g.CurrentYear
+		if bounded {
+			target = min(target, end)
+		}
+		n := (target - year + years - 1) / years
+		ticks += n
+		year += n * years
+		if bounded {
+			year = min(year, end)
+		}
+	}
+	return ticks
+}
+
+// TickYearsEvery returns the years the game's next tick covers that are a
+// multiple of interval, so work done every interval years runs once for
+// each however many years the tick spans
+func (g *Game) TickYearsEvery(interval int) []int {
+	first := g.CurrentYear
+	if remainder := (first%interval + interval) % interval; remainder != 0 {
+		first += interval - remainder
+	}
+	var years []int
+	for year := first; year < g.CurrentYear+g.YearsPerTick(); year += interval {
+		years = append(years, year)
+	}
+	return years
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Policy levels a player sets for their civ
+const (
+	PolicyLow    = "low"
+	PolicyNormal = "normal" // Default for every unset policy
+	PolicyHigh   = "high"
+)
+
+// PlayerPolicy is how a player directs their civ as a whole rather than
+// settlement by settlement. Unset policies are PolicyNormal.
+type PlayerPolicy struct {
+	GameID         string    `bson:"gameId"`
+	PlayerID       string    `bson:"playerId"`
+	Infrastructure string    `bson:"infrastructure,omitempty"` // How eagerly busy routes are paved, see Policy*
+	TaxRate        float64   `bson:"taxRate,omitempty"`        // Share of food taken as tax for gold (0-1)
+	Corvee         float64   `bson:"corvee,omitempty"`         // Share of work hours drafted for production (0-1)
+	UpdatedAt      time.Time `bson:"updatedAt"`
+}
+
+// InfrastructureLevel returns the player's infrastructure policy; busy
+// routes get roads proposed at PolicyNormal and planned outright at
+// PolicyHigh. A nil policy is normal.
+func (p *PlayerPolicy) InfrastructureLevel() string {
+	if p == nil || p.Infrastructure == "" {
+		return PolicyNormal
+	}
+	return p.Infrastructure
+}
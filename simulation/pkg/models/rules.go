@@ -0,0 +1,219 @@
+package models
+
+import "maps"
+
+// CurrentRulesVersion is the ruleset version new games are pinned to
+const CurrentRulesVersion = 3
+
+// Ruleset holds the engine's balance constants a game is played under. A
+// copy is pinned to each game when it starts so later balance changes do not
+// shift live games: the settlers and settlement constants, the yields, growth
+// and mortality of the settlement simulation, building costs, combat
+// strengths, trade rates and the rates of disasters, unrest, healing and
+// great people.
+//
+// The tables the ruleset scales are not versioned: the terrain's relative
+// yields, the technology tree, and the simulator's design constants (see
+// SettlementRates). Neither are the engine's rules of play, such as how far
+// borders, forests or pollution spread. Those are compiled in and change for
+// live games when they change; move a constant into the ruleset, in a new
+// version, before tuning it.
+type Ruleset struct {
+	Version int `bson:"version"`
+
+	SettlersPopulationCost int `bson:"settlersPopulationCost"` // Humans carried by a settlers unit
+	SettlersWalkSteps      int `bson:"settlersWalkSteps"`      // Random steps before auto-settling
+	StartingVisionRange    int `bson:"startingVisionRange"`    // Tiles revealed around a starting position
+	SettleOrderRange       int `bson:"settleOrderRange"`       // Farthest a settle order may target from the unit
+	AutoSettleRadius       int `bson:"autoSettleRadius"`       // How far auto-settle looks for a better site
+
+	SettlementWorkRadius    int `bson:"settlementWorkRadius"`    // Tiles around a settlement its people work
+	SettlementGrowthPerRing int `bson:"settlementGrowthPerRing"` // Population per extra ring of footprint
+	MaxSettlementExtent     int `bson:"maxSettlementExtent"`     // Cap on a settlement's footprint
+
+	// Added in version 2
+	EarthquakeChance     float64 `bson:"earthquakeChance"`     // Yearly chance of an earthquake at each plate boundary
+	HeavyRainChance      float64 `bson:"heavyRainChance"`      // Chance each tick of heavy rains, which flood the rivers the tick after
+	UnrestAdjustment     float64 `bson:"unrestAdjustment"`     // Share of the gap to its pressure a settlement's unrest closes each year
+	RevoltChance         float64 `bson:"revoltChance"`         // Yearly chance a settlement that may revolt does
+	TerritoryHealRate    float64 `bson:"territoryHealRate"`    // Fraction of full health a unit recovers each year in friendly territory
+	GarrisonHealRate     float64 `bson:"garrisonHealRate"`     // Fraction recovered each year when garrisoned in a friendly settlement
+	GreatPersonThreshold int     `bson:"greatPersonThreshold"` // Points a settlement's first great person costs; each later one costs as much again
+
+	// Added in version 3
+	Settlement         SettlementRates    `bson:"settlement"`         // Yields, growth and mortality of the settlement simulation
+	BuildingCosts      map[string]int     `bson:"buildingCosts"`      // Banked production each building costs
+	UnitStrengths      map[string]float64 `bson:"unitStrengths"`      // Combat strength of each unit type
+	SettlementStrength float64            `bson:"settlementStrength"` // Combat strength of a settlement's own walls and militia
+	RiverTradeBonus    float64            `bson:"riverTradeBonus"`    // Extra share of food per settlement trading along the same river
+	SeaTradeBase       float64            `bson:"seaTradeBase"`       // Extra share of food a sea route earns however short
+	SeaTradePerTile    float64            `bson:"seaTradePerTile"`    // Extra share per tile of water a sea route crosses
+	MaxSeaTrade        float64            `bson:"maxSeaTrade"`        // Cap on the share a single sea route earns
+}
+
+// SettlementRates scale the settlement simulation's design rates (see
+// package simulator) for a game; 1 plays a rate as designed
+type SettlementRates struct {
+	FoodYield    float64 `bson:"foodYield"`    // Food gathered per work hour
+	ScienceYield float64 `bson:"scienceYield"` // Science researched per work hour
+	Fertility    float64 `bson:"fertility"`    // Chance a couple conceives
+	Mortality    float64 `bson:"mortality"`    // Chance a person dies of age or disease
+}
+
+// DesignedSettlementRates plays every settlement rate as designed
+var DesignedSettlementRates = SettlementRates{FoodYield: 1, ScienceYield: 1, Fertility: 1, Mortality: 1}
+
+// Balance of the releases before version 3, which version 3 keeps
+var (
+	releasedBuildingCosts = map[string]int{
+		BuildingPalisade: 40,
+		BuildingLibrary:  50,
+		BuildingHarbor:   60,
+		"bronze_works":   80,
+		"treasury":       100,
+		"forge":          120,
+	}
+	releasedUnitStrengths = map[string]float64{
+		UnitTypeSettlers: 1.0,
+		UnitTypeWorkers:  1.0,
+		UnitTypeGalley:   3.0,
+		UnitTypeRebels:   1.5,
+
+		UnitTypeGreatScientist: 0.5,
+		UnitTypeGreatBuilder:   0.5,
+		UnitTypeGreatGeneral:   1.0,
+	}
+)
+
+// rulesets lists every released ruleset by version. A release's fields
+// added in later versions hold the values its games were played under.
+var rulesets = map[int]Ruleset{
+	1: {
+		Version:                 1,
+		SettlersPopulationCost:  100,
+		SettlersWalkSteps:       3,
+		StartingVisionRange:     3,
+		SettleOrderRange:        3,
+		AutoSettleRadius:        2,
+		SettlementWorkRadius:    1,
+		SettlementGrowthPerRing: 500,
+		MaxSettlementExtent:     3,
+		EarthquakeChance:        0.01,
+		HeavyRainChance:         0.05,
+		UnrestAdjustment:        0.5,
+		RevoltChance:            0.25,
+		TerritoryHealRate:       0.10,
+		GarrisonHealRate:        0.25,
+		GreatPersonThreshold:    100,
+		Settlement:              DesignedSettlementRates,
+		BuildingCosts:           releasedBuildingCosts,
+		UnitStrengths:           releasedUnitStrengths,
+		SettlementStrength:      2.0,
+		RiverTradeBonus:         0.05,
+		SeaTradeBase:            0.02,
+		SeaTradePerTile:         0.002,
+		MaxSeaTrade:             0.1,
+	},
+	2: {
+		Version:                 2,
+		SettlersPopulationCost:  100,
+		SettlersWalkSteps:       3,
+		StartingVisionRange:     3,
+		SettleOrderRange:        3,
+		AutoSettleRadius:        2,
+		SettlementWorkRadius:    1,
+		SettlementGrowthPerRing: 500,
+		MaxSettlementExtent:     3,
+		EarthquakeChance:        0.01,
+		HeavyRainChance:         0.05,
+		UnrestAdjustment:        0.5,
+		RevoltChance:            0.25,
+		TerritoryHealRate:       0.10,
+		GarrisonHealRate:        0.25,
+		GreatPersonThreshold:    100,
+		Settlement:              DesignedSettlementRates,
+		BuildingCosts:           releasedBuildingCosts,
+		UnitStrengths:           releasedUnitStrengths,
+		SettlementStrength:      2.0,
+		RiverTradeBonus:         0.05,
+		SeaTradeBase:            0.02,
+		SeaTradePerTile:         0.002,
+		MaxSeaTrade:             0.1,
+	},
+	3: {
+		Version:                 3,
+		SettlersPopulationCost:  100,
+		SettlersWalkSteps:       3,
+		StartingVisionRange:     3,
+		SettleOrderRange:        3,
+		AutoSettleRadius:        2,
+		SettlementWorkRadius:    1,
+		SettlementGrowthPerRing: 500,
+		MaxSettlementExtent:     3,
+		EarthquakeChance:        0.01,
+		HeavyRainChance:         0.05,
+		UnrestAdjustment:        0.5,
+		RevoltChance:            0.25,
+		TerritoryHealRate:       0.10,
+		GarrisonHealRate:        0.25,
+		GreatPersonThreshold:    100,
+		Settlement:              DesignedSettlementRates,
+		BuildingCosts:           releasedBuildingCosts,
+		UnitStrengths:           releasedUnitStrengths,
+		SettlementStrength:      2.0,
+		RiverTradeBonus:         0.05,
+		SeaTradeBase:            0.02,
+		SeaTradePerTile:         0.002,
+		MaxSeaTrade:             0.1,
+	},
+}
+
+// RulesetVersion returns a copy of a released ruleset, or nil if the version is unknown
+func RulesetVersion(version int) *Ruleset {
+	rules, ok := rulesets[version]
+	if !ok {
+		return nil
+	}
+	rules.BuildingCosts = maps.Clone(rules.BuildingCosts)
+	rules.UnitStrengths = maps.Clone(rules.UnitStrengths)
+	return &rules
+}
+
+// DefaultRuleset returns a copy of the current ruleset
+func DefaultRuleset() *Ruleset {
+	return RulesetVersion(CurrentRulesVersion)
+}
+
+// Ruleset returns the game's pinned ruleset, or the current one for games
+// that have not been pinned yet. Rules pinned before the current version
+// read the fields later versions added from the release they were pinned to.
+func (g *Game) Ruleset() *Ruleset {
+	if g.Rules == nil {
+		return DefaultRuleset()
+	}
+	if g.Rules.Version >= CurrentRulesVersion {
+		return g.Rules
+	}
+	rules := RulesetVersion(g.Rules.Version)
+	if rules == nil {
+		return g.Rules
+	}
+	if g.Rules.Version >= 2 {
+		rules.EarthquakeChance = g.Rules.EarthquakeChance
+		rules.HeavyRainChance = g.Rules.HeavyRainChance
+		rules.UnrestAdjustment = g.Rules.UnrestAdjustment
+		rules.RevoltChance = g.Rules.RevoltChance
+		rules.TerritoryHealRate = g.Rules.TerritoryHealRate
+		rules.GarrisonHealRate = g.Rules.GarrisonHealRate
+		rules.GreatPersonThreshold = g.Rules.GreatPersonThreshold
+	}
+	rules.SettlersPopulationCost = g.Rules.SettlersPopulationCost
+	rules.SettlersWalkSteps = g.Rules.SettlersWalkSteps
+	rules.StartingVisionRange = g.Rules.StartingVisionRange
+	rules.SettleOrderRange = g.Rules.SettleOrderRange
+	rules.AutoSettleRadius = g.Rules.AutoSettleRadius
+	rules.SettlementWorkRadius = g.Rules.SettlementWorkRadius
+	rules.SettlementGrowthPerRing = g.Rules.SettlementGrowthPerRing
+	rules.MaxSettlementExtent = g.Rules.MaxSettlementExtent
+	return rules
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TickSchedule limits when a game ticks, in server local time, so casual
+// persistent games do not advance while their players are asleep
+type TickSchedule struct {
+	// WindowStart and WindowEnd ("HH:MM") bound the daily tick window; a
+	// window that ends before it starts runs past midnight
+	WindowStart string `bson:"windowStart,omitempty"`
+	WindowEnd   string `bson:"windowEnd,omitempty"`
+
+	// PauseWeekends stops ticking on Saturdays and Sundays
+	PauseWeekends bool `bson:"pauseWeekends,omitempty"`
+}
+
+// Allows reports whether the schedule lets the game tick at t
+func (s *TickSchedule) Allows(t time.Time) bool {
+	if s.PauseWeekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return false
+	}
+
+	start, errStart := ParseClock(s.WindowStart)
+	end, errEnd := ParseClock(s.WindowEnd)
+	if errStart != nil || errEnd != nil || start == end {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// ParseClock converts "HH:MM" to minutes after midnight
+func ParseClock(clock string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(clock, "%d:%d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("invalid clock time %q", clock)
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid clock time %q", clock)
+	}
+	return hour*60 + minute, nil
+}
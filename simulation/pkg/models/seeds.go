@@ -0,0 +1,54 @@
+package models
+
+import "github.com/anicolao/simciv/simulation/pkg/rng"
+
+// Named RNG streams. Each subsystem draws only from its own stream so that
+// adding randomness to one subsystem never perturbs the others.
+const (
+	SeedStreamMapGen   = "mapgen"
+	SeedStreamMovement = "movement"
+	SeedStreamCombat   = "combat"
+	SeedStreamEvents   = "events"
+)
+
+// GameSeeds is the per-game seed registry holding independent RNG streams
+// with their persisted positions
+type GameSeeds struct {
+	Master   string     `bson:"master"`   // Master seed (also used as the map seed)
+	MapGen   rng.Stream `bson:"mapgen"`   // Map randomness applied after generation
+	Movement rng.Stream `bson:"movement"` // Unit movement decisions
+	Combat   rng.Stream `bson:"combat"`   // Combat resolution
+	Events   rng.Stream `bson:"events"`   // Random world events
+}
+
+// NewGameSeeds derives all named streams from a master seed
+func NewGameSeeds(master string) *GameSeeds {
+	return &GameSeeds{
+		Master:   master,
+		MapGen:   rng.Stream{Seed: rng.DeriveSeed(master, SeedStreamMapGen)},
+		Movement: rng.Stream{Seed: rng.DeriveSeed(master, SeedStreamMovement)},
+		Combat:   rng.Stream{Seed: rng.DeriveSeed(master, SeedStreamCombat)},
+		Events:   rng.Stream{Seed: rng.DeriveSeed(master, SeedStreamEvents)},
+	}
+}
+
+// Stream returns the named stream, or nil if the name is unknown
+func (s *GameSeeds) Stream(name string) *rng.Stream {
+	switch name {
+	case SeedStreamMapGen:
+		return &s.MapGen
+	case SeedStreamMovement:
+		return &s.Movement
+	case SeedStreamCombat:
+		return &s.Combat
+	case SeedStreamEvents:
+		return &s.Events
+	}
+	return nil
+}
+
+// Positions returns a snapshot of every stream position, used to detect
+// whether the registry needs to be persisted after a tick
+func (s *GameSeeds) Positions() [4]int64 {
+	return [4]int64{s.MapGen.Position, s.Movement.Position, s.Combat.Position, s.Events.Position}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// PlayerSettings are a player's preferences that change how the game
+// service treats them, kept with the game rather than only in the client.
+// Unset settings leave the game's defaults.
+type PlayerSettings struct {
+	GameID             string    `bson:"gameId"`
+	PlayerID           string    `bson:"playerId"`
+	AutoEndTurn        bool      `bson:"autoEndTurn,omitempty"`        // Go ahead with decisions the engine would otherwise wait on the player for
+	GovernorAfterTicks int       `bson:"governorAfterTicks,omitempty"` // Ticks of absence before the governor plays for the player; 0 is the game's threshold
+	GovernorOff        bool      `bson:"governorOff,omitempty"`        // Never hand the player's civ to the governor
+	MutedEvents        []string  `bson:"mutedEvents,omitempty"`        // Event types left out of the player's notifications
+	UpdatedAt          time.Time `bson:"updatedAt"`
+}
+
+// GovernorThreshold returns how many ticks the player may be absent before
+// the governor takes over: their own threshold if they set one, else the
+// game's. A nil settings reads as unset.
+func (s *PlayerSettings) GovernorThreshold(game *Game) int {
+	if s == nil || s.GovernorAfterTicks <= 0 {
+		return game.GovernorThreshold()
+	}
+	return s.GovernorAfterTicks
+}
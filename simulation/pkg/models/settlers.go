@@ -10,21 +10,207 @@ type Unit struct {
 	UnitType       string    `bson:"unitType"` // "settlers" for minimal implementation
 	Location       Location  `bson:"location"`
 	StepsTaken     int       `bson:"stepsTaken"`
-	PopulationCost int       `bson:"populationCost"` // Fixed at 100 for settlers
+	PopulationCost int       `bson:"populationCost"`       // Fixed at 100 for settlers
+	Automation     string    `bson:"automation,omitempty"` // Worker automation mode, empty when manually controlled
+	Damage         int       `bson:"damage"`               // Health lost in combat, healed in friendly territory
+	Name           string    `bson:"name,omitempty"`       // Great people are known by name
+	Aura           int       `bson:"aura"`                 // Years left fighting inspired by a great general
+	HomeID         string    `bson:"homeId,omitempty"`     // Settlement rebels rose up in
+	Project        *Project  `bson:"project"`              // Terrain transformation a workers unit is carrying out, nil if none
+	Destination    *Location `bson:"destination"`          // Where a move order sent the unit, nil once it arrives
+	MovesLeft      float64   `bson:"movesLeft"`            // Movement saved from earlier years toward a step the unit could not yet pay for
 	CreatedAt      time.Time `bson:"createdAt"`
 	LastUpdated    time.Time `bson:"lastUpdated"`
 }
 
+// UnitTypeSettlers is the unit that founds settlements
+const UnitTypeSettlers = "settlers"
+
+// UnitTypeWorkers is the unit that builds tile improvements
+const UnitTypeWorkers = "workers"
+
+// Project is a long transformation of the tile a workers unit stands on
+type Project struct {
+	Transformation string   `bson:"transformation"` // See terrain.Transformations
+	Location       Location `bson:"location"`
+	StartedYear    int      `bson:"startedYear"`
+	YearsLeft      int      `bson:"yearsLeft"`
+}
+
+// MaxUnitHealth is the health of an undamaged unit
+const MaxUnitHealth = 100
+
+// Health returns how much of MaxUnitHealth a unit has left
+func (u *Unit) Health() int {
+	return max(MaxUnitHealth-u.Damage, 0)
+}
+
+// Great people, born in settlements and spent through an activate order
+const (
+	UnitTypeGreatScientist = "great_scientist" // Brings a settlement's science forward
+	UnitTypeGreatBuilder   = "great_builder"   // Improves the tile they stand on at once
+	UnitTypeGreatGeneral   = "great_general"   // Inspires nearby units in combat
+)
+
+// IsGreatPerson reports whether a unit type is a great person
+func IsGreatPerson(unitType string) bool {
+	return unitType == UnitTypeGreatScientist || unitType == UnitTypeGreatBuilder || unitType == UnitTypeGreatGeneral
+}
+
+// UnitTypeGalley is a warship; hostile galleys raid sea trade routes
+const UnitTypeGalley = "galley"
+
+// UnitTypeRebels is a band of a settlement's people in revolt, owned by
+// RebelPlayerID
+const UnitTypeRebels = "rebels"
+
+// BuildingHarbor lets a coastal settlement trade by sea
+const BuildingHarbor = "harbor"
+
+// Buildings a settlement's people come to want for themselves
+const (
+	BuildingPalisade = "palisade" // Walls that make its people feel safe
+	BuildingLibrary  = "library"  // Somewhere to keep and share learning
+)
+
+// Desires a settlement's people accumulate while their conditions leave
+// them unmet
+const (
+	DesireRoads     = "roads"     // Roads joining them to the player's other settlements
+	DesireTrade     = "trade"     // Trade partners by river or sea
+	DesireSecurity  = "security"  // A garrison or walls
+	DesireKnowledge = "knowledge" // A place of learning
+)
+
+// Worker automation modes
+const (
+	AutomationImproveNearest = "improve_nearest" // Improve the closest unimproved tile in the player's territory
+	AutomationConnectCities  = "connect_cities"  // Build roads between the player's settlements
+	AutomationFocusFood      = "focus_food"      // Farm the territory's best food tiles first
+)
+
 // Settlement represents a player settlement
 type Settlement struct {
+	SettlementID string             `bson:"settlementId"`
+	GameID       string             `bson:"gameId"`
+	PlayerID     string             `bson:"playerId"`
+	Name         string             `bson:"name"`
+	Type         string             `bson:"type"` // One of the SettlementType* constants
+	Location     Location           `bson:"location"`
+	Population   int                `bson:"population"`             // Living humans, updated each year tick
+	ParentID     string             `bson:"parentId,omitempty"`     // Parent settlement when this is a suburb
+	Buildings    []string           `bson:"buildings,omitempty"`    // Buildings constructed in the settlement
+	Damaged      []string           `bson:"damaged"`                // Buildings an earthquake wrecked, awaiting repair by workers
+	Production   int                `bson:"production"`             // Production banked from windfalls such as felled forests and from corvée labor
+	Gold         int                `bson:"gold"`                   // Gold banked from taxes
+	Technologies []string           `bson:"technologies,omitempty"` // Technologies its people have unlocked, see simulator.Tech*
+	SeaRoutes    []SeaRoute         `bson:"seaRoutes,omitempty"`    // Sea trade routes its harbor runs
+	Garrison     float64            `bson:"garrison"`               // Defense bonus from the units garrisoned in it
+	GreatPoints  map[string]int     `bson:"greatPoints,omitempty"`  // Points toward each type of great person
+	GreatPeople  int                `bson:"greatPeople,omitempty"`  // Great people born here; each makes the next costlier
+	Deaths       Mortality          `bson:"deaths"`                 // Deaths of its people by cause since it was founded
+	Desires      map[string]float64 `bson:"desires,omitempty"`      // Strength of each want of its people, 0 to 1, see Desire*
+	Unhappiness  float64            `bson:"unhappiness"`            // Mean strength of its people's desires; 0 is content
+	Unrest       float64            `bson:"unrest"`                 // Crime and disorder, 0 to 1; skims its food and may break into revolt
+	Proposals    map[string]int     `bson:"proposals,omitempty"`    // Year each building was last proposed for its build queue
+	Traffic      map[string]float64 `bson:"traffic,omitempty"`      // Recent trips and trade with each of the player's other settlements, by settlement ID
+	RoadPlan     []Location         `bson:"roadPlan,omitempty"`     // Tiles workers are to pave for its planned roads
+	Founded      time.Time          `bson:"founded"`
+	LastUpdated  time.Time          `bson:"lastUpdated"`
+}
+
+// Mortality counts deaths by cause, see simulator.Mortality
+type Mortality struct {
+	Starvation int `bson:"starvation"`
+	Age        int `bson:"age"`
+	Disease    int `bson:"disease"`
+	Accident   int `bson:"accident"`
+	Combat     int `bson:"combat"`
+	Childbirth int `bson:"childbirth"`
+}
+
+// Total returns the number of deaths of every cause
+func (m Mortality) Total() int {
+	return m.Starvation + m.Age + m.Disease + m.Accident + m.Combat + m.Childbirth
+}
+
+// SettlementSimulation is a settlement's human simulation saved after a year
+// tick, so a restarted engine resumes it rather than building a fresh one
+type SettlementSimulation struct {
 	SettlementID string    `bson:"settlementId"`
 	GameID       string    `bson:"gameId"`
-	PlayerID     string    `bson:"playerId"`
-	Name         string    `bson:"name"`
-	Type         string    `bson:"type"` // "nomadic_camp" for minimal implementation
-	Location     Location  `bson:"location"`
-	Founded      time.Time `bson:"founded"`
-	LastUpdated  time.Time `bson:"lastUpdated"`
+	Year         int       `bson:"year"`       // Game year the simulation was saved after
+	Population   int       `bson:"population"` // Living humans, to match against the settlement
+	Snapshot     []byte    `bson:"snapshot"`   // simulator.Snapshot, JSON encoded
+	UpdatedAt    time.Time `bson:"updatedAt"`
+}
+
+// Settlement types, in the order a settlement grows through them
+const (
+	SettlementTypeNomadicCamp = "nomadic_camp"
+	SettlementTypeVillage     = "village"
+	SettlementTypeTown        = "town"
+	SettlementTypeCity        = "city"
+)
+
+// SettlementTier is a settlement type, what a settlement needs to grow into
+// it and what it gains there
+type SettlementTier struct {
+	Type          string
+	MinPopulation int
+	MinBuildings  int
+	WorkRadius    int     // Rings worked beyond the ruleset's work radius
+	Defense       float64 // Defense bonus of its walls
+}
+
+// SettlementTiers lists the settlement types from a founding camp to a city
+var SettlementTiers = []SettlementTier{
+	{Type: SettlementTypeNomadicCamp},
+	{Type: SettlementTypeVillage, MinPopulation: 300, Defense: 0.25},
+	{Type: SettlementTypeTown, MinPopulation: 1000, MinBuildings: 1, WorkRadius: 1, Defense: 0.5},
+	{Type: SettlementTypeCity, MinPopulation: 3000, MinBuildings: 3, WorkRadius: 2, Defense: 1.0},
+}
+
+// Tier returns the tier of the settlement's type; settlements of an unknown
+// type are treated as camps
+func (s *Settlement) Tier() int {
+	for i, tier := range SettlementTiers {
+		if tier.Type == s.Type {
+			return i
+		}
+	}
+	return 0
+}
+
+// QualifiedTier returns the highest tier the settlement's population and
+// buildings qualify it for
+func (s *Settlement) QualifiedTier() int {
+	qualified := 0
+	for i, tier := range SettlementTiers {
+		if s.Population >= tier.MinPopulation && len(s.Buildings) >= tier.MinBuildings {
+			qualified = i
+		}
+	}
+	return qualified
+}
+
+// WorkRadius returns how many rings of tiles around the settlement its
+// people work, widening as it grows
+func (s *Settlement) WorkRadius(rules *Ruleset) int {
+	return rules.SettlementWorkRadius + SettlementTiers[s.Tier()].WorkRadius
+}
+
+// Defense returns the settlement's defense multiplier, raised by its walls
+// and its garrison
+func (s *Settlement) Defense() float64 {
+	return 1 + SettlementTiers[s.Tier()].Defense + s.Garrison
+}
+
+// SeaRoute is a sea trade route between two harbor settlements
+type SeaRoute struct {
+	PartnerID string `bson:"partnerId"`        // Settlement at the far end
+	Distance  int    `bson:"distance"`         // Tiles of water crossed
+	Raided    bool   `bson:"raided,omitempty"` // Pirates took this year's cargo
 }
 
 // Location represents a position on the map
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// PlayerStanding is how far one player's civilization had come at a point
+// in a game
+type PlayerStanding struct {
+	PlayerID    string  `bson:"playerId"`
+	Population  int     `bson:"population"`
+	Science     float64 `bson:"science"`   // Science their settlements have produced
+	Territory   int     `bson:"territory"` // Tiles they own
+	Settlements int     `bson:"settlements"`
+}
+
+// GameHistoryPoint is every player's standing in one year, sampled as a game
+// ticks so its summary can graph how the players fared
+type GameHistoryPoint struct {
+	GameID  string           `bson:"gameId"`
+	Year    int              `bson:"year"`
+	Players []PlayerStanding `bson:"players"`
+}
+
+// Superlative is a record a player set over a game, such as its largest city
+type Superlative struct {
+	Title        string  `bson:"title"`
+	PlayerID     string  `bson:"playerId"`
+	SettlementID string  `bson:"settlementId,omitempty"` // For records set by a settlement
+	Detail       string  `bson:"detail"`
+	Value        float64 `bson:"value"`
+	Year         int     `bson:"year"`
+}
+
+// GameSummary is the report written when a game finishes, for its results
+// screen
+type GameSummary struct {
+	GameID       string              `bson:"gameId"`
+	StartYear    int                 `bson:"startYear"`
+	EndYear      int                 `bson:"endYear"`
+	Winners      []string            `bson:"winners"`
+	VictoryType  string              `bson:"victoryType"`
+	Standings    []PlayerStanding    `bson:"standings"` // Final standings, the largest civilization first
+	Timeline     []*GameEvent        `bson:"timeline"`  // Key events, oldest first
+	History      []*GameHistoryPoint `bson:"history"`   // Standings over the game, oldest first
+	Superlatives []Superlative       `bson:"superlatives"`
+	CreatedAt    time.Time           `bson:"createdAt"`
+}
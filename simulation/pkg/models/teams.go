@@ -0,0 +1,49 @@
+package models
+
+// Diplomatic stances between players
+const (
+	StanceAllied  = "allied"
+	StanceNeutral = "neutral"
+)
+
+// NoTeam is the team of players in games without teams
+const NoTeam = -1
+
+// TeamOf returns a player's team, or NoTeam when they have none
+func (g *Game) TeamOf(playerID string) int {
+	if team, ok := g.Teams[playerID]; ok && g.TeamCount > 0 {
+		return team
+	}
+	return NoTeam
+}
+
+// Allied reports whether two players are the same player or teammates
+func (g *Game) Allied(a, b string) bool {
+	if a == b {
+		return true
+	}
+	team := g.TeamOf(a)
+	return team != NoTeam && team == g.TeamOf(b)
+}
+
+// Teammates returns the players sharing a player's team, the player included
+func (g *Game) Teammates(playerID string) []string {
+	if g.TeamOf(playerID) == NoTeam {
+		return []string{playerID}
+	}
+	var teammates []string
+	for _, id := range g.PlayerList {
+		if g.Allied(playerID, id) {
+			teammates = append(teammates, id)
+		}
+	}
+	return teammates
+}
+
+// DefaultStance returns the diplomatic stance two players start the game in
+func (g *Game) DefaultStance(a, b string) string {
+	if g.Allied(a, b) {
+		return StanceAllied
+	}
+	return StanceNeutral
+}
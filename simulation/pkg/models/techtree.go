@@ -0,0 +1,25 @@
+package models
+
+// TechEffects are what knowing a technology changes for a settlement's
+// people. Zero fields change nothing.
+type TechEffects struct {
+	FoodMultiplier          float64 `bson:"foodMultiplier,omitempty"`          // Multiplies food produced
+	ScienceMultiplier       float64 `bson:"scienceMultiplier,omitempty"`       // Multiplies science produced
+	HerdFood                float64 `bson:"herdFood,omitempty"`                // Extra share of food from each tended herd; the largest known applies
+	ChildWorkHours          float64 `bson:"childWorkHours,omitempty"`          // A working child's day; the longest known applies
+	ChildAccidentFactor     float64 `bson:"childAccidentFactor,omitempty"`     // Multiplies working children's accident risk
+	ChildMortalityFactor    float64 `bson:"childMortalityFactor,omitempty"`    // Mortality of children under 15; the lowest known applies
+	InfantSurvival          float64 `bson:"infantSurvival,omitempty"`          // Added to the chance a newborn survives birth
+	MaternalMortalityFactor float64 `bson:"maternalMortalityFactor,omitempty"` // Multiplies the risk of dying in childbirth
+}
+
+// Technology is a node of a technology tree: what it needs before it can be
+// researched, the science it costs and what it changes once known
+type Technology struct {
+	Name            string      `bson:"name"`
+	Description     string      `bson:"description,omitempty"`
+	Prerequisites   []string    `bson:"prerequisites,omitempty"` // Technologies that must be known first
+	ScienceRequired float64     `bson:"scienceRequired"`
+	NeedsLivestock  bool        `bson:"needsLivestock,omitempty"` // Only researched where herds roam nearby
+	Effects         TechEffects `bson:"effects"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Victory types a game can be won by
+const (
+	VictoryConquest = "conquest" // The last player or team left standing
+)
+
+// UserStats are a user's achievements across every game they finished
+type UserStats struct {
+	UserID      string         `bson:"userId"`
+	GamesPlayed int            `bson:"gamesPlayed"`
+	Wins        map[string]int `bson:"wins,omitempty"` // Keyed by victory type
+	// FastestFireMastery is the fewest years from a game's start the user
+	// took to master fire (0 if they never have)
+	FastestFireMastery int       `bson:"fastestFireMastery,omitempty"`
+	GameIDs            []string  `bson:"gameIds"` // Games already counted
+	LastUpdated        time.Time `bson:"lastUpdated"`
+}
+
+// GameResult is how one user fared in a finished game
+type GameResult struct {
+	GameID           string
+	UserID           string
+	VictoryType      string // Empty if the user did not win
+	FireMasteryYears int    // Years from the game's start to mastering fire (0 if never)
+}
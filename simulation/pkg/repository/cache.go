@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// GameWatcher is implemented by repositories that can stream changes to
+// game documents
+type GameWatcher interface {
+	// WatchGames calls onChange with each changed game document, or nil when
+	// a game was deleted, until ctx is cancelled or the stream fails
+	WatchGames(ctx context.Context, onChange func(game *models.Game)) error
+}
+
+// Refresh periods for the game cache: a full reload every poll interval
+// when no change stream is available, and a safety resync while one is
+const (
+	DefaultGameCachePollInterval = 5 * time.Second
+	gameCacheResyncInterval      = 5 * time.Minute
+)
+
+// CachedRepository wraps a GameRepository with a registry of started games
+// so the engine's tick loop does not re-read every game document each pass.
+// The engine's own game writes are applied to the cached copies as they
+// succeed; changes made elsewhere (the web server starting games, players
+// joining) arrive through the repository's change stream when it has one,
+// or with the next periodic reload otherwise.
+type CachedRepository struct {
+	GameRepository
+
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	games    map[string]*models.Game
+	loadedAt time.Time
+	watching bool
+}
+
+// NewCachedRepository wraps repo with a started-game cache reloaded every
+// pollInterval while no change stream is available
+func NewCachedRepository(repo GameRepository, pollInterval time.Duration) *CachedRepository {
+	if pollInterval <= 0 {
+		pollInterval = DefaultGameCachePollInterval
+	}
+	return &CachedRepository{
+		GameRepository: repo,
+		pollInterval:   pollInterval,
+	}
+}
+
+// Watch follows the underlying repository's change stream, reconnecting with
+// backoff when it fails, until ctx is cancelled. It returns immediately if
+// the repository cannot stream changes.
+func (c *CachedRepository) Watch(ctx context.Context) {
+	watcher, ok := c.GameRepository.(GameWatcher)
+	if !ok {
+		return
+	}
+
+	for attempt := 1; ctx.Err() == nil; attempt++ {
+		c.setWatching(true)
+		err := watcher.WatchGames(ctx, c.applyChange)
+		c.setWatching(false)
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := Backoff(attempt, time.Second, gameCacheResyncInterval)
+		log.Printf("Game change stream stopped (%v); polling every %v, retrying stream in %v", err, c.pollInterval, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// setWatching records whether the change stream is live. Starting the stream
+// forces a reload so nothing changed before it opened is missed.
+func (c *CachedRepository) setWatching(watching bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watching = watching
+	if watching {
+		c.loadedAt = time.Time{}
+	}
+}
+
+// applyChange folds a streamed game document into the cache
+func (c *CachedRepository) applyChange(game *models.Game) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if game == nil {
+		// Deletions carry no game ID; reload on the next read
+		c.loadedAt = time.Time{}
+		return
+	}
+	if c.games == nil {
+		return
+	}
+	if game.IsRunning() {
+		c.games[game.GameID] = cloneGame(game)
+	} else {
+		delete(c.games, game.GameID)
+	}
+}
+
+// Invalidate forces the next GetStartedGames to reload from the repository
+func (c *CachedRepository) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadedAt = time.Time{}
+}
+
+// GetStartedGames returns copies of the cached started games, reloading them
+// when the refresh period has elapsed
+func (c *CachedRepository) GetStartedGames(ctx context.Context) ([]*models.Game, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	refresh := c.pollInterval
+	if c.watching {
+		refresh = gameCacheResyncInterval
+	}
+	if c.games == nil || time.Since(c.loadedAt) >= refresh {
+		games, err := c.GameRepository.GetStartedGames(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.games = make(map[string]*models.Game, len(games))
+		for _, game := range games {
+			c.games[game.GameID] = cloneGame(game)
+		}
+		c.loadedAt = time.Now()
+	}
+
+	games := make([]*models.Game, 0, len(c.games))
+	for _, game := range c.games {
+		games = append(games, cloneGame(game))
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].GameID < games[j].GameID })
+	return games, nil
+}
+
+// update applies fn to the cached copy of a game, if there is one
+func (c *CachedRepository) update(gameID string, fn func(game *models.Game)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if game, ok := c.games[gameID]; ok {
+		fn(game)
+	}
+}
+
+// CreateGame stores a new game and caches it if it is running
+func (c *CachedRepository) CreateGame(ctx context.Context, game *models.Game) error {
+	if err := c.GameRepository.CreateGame(ctx, game); err != nil {
+		return err
+	}
+	c.applyChange(game)
+	return nil
+}
+
+// ClaimGameTick takes the tick of year. A lost claim means the cache may be
+// stale, so it is reloaded.
+func (c *CachedRepository) ClaimGameTick(ctx context.Context, gameID string, year int, claimedAt time.Time, lease time.Duration) error {
+	if err := c.GameRepository.ClaimGameTick(ctx, gameID, year, claimedAt, lease); err != nil {
+		if errors.Is(err, ErrConcurrentTick) {
+			c.Invalidate()
+		}
+		return err
+	}
+	c.update(gameID, func(game *models.Game) {
+		game.TickClaim = &models.TickClaim{Year: year, ClaimedAt: claimedAt}
+	})
+	return nil
+}
+
+// UpdateGameTick advances the game from expectedYear to newYear. A
+// concurrent advance means the cache is stale, so it is reloaded.
+func (c *CachedRepository) UpdateGameTick(ctx context.Context, gameID string, expectedYear, newYear int, tickTime time.Time) error {
+	if err := c.GameRepository.UpdateGameTick(ctx, gameID, expectedYear, newYear, tickTime); err != nil {
+		if errors.Is(err, ErrConcurrentTick) {
+			c.Invalidate()
+		}
+		return err
+	}
+	calendar := models.YearCalendar(newYear)
+	c.update(gameID, func(game *models.Game) {
+		game.CurrentYear = newYear
+		game.LastTickAt = &tickTime
+		game.Calendar = &calendar
+		game.TickClaim = nil
+	})
+	return nil
+}
+
+// UpdateGameSeeds persists the game's RNG seed registry and stream positions
+func (c *CachedRepository) UpdateGameSeeds(ctx context.Context, gameID string, seeds *models.GameSeeds) error {
+	if err := c.GameRepository.UpdateGameSeeds(ctx, gameID, seeds); err != nil {
+		return err
+	}
+	c.update(gameID, func(game *models.Game) {
+		copied := *seeds
+		game.Seeds = &copied
+	})
+	return nil
+}
+
+// UpdateGameRules pins the ruleset a game is played under
+func (c *CachedRepository) UpdateGameRules(ctx context.Context, gameID string, rules *models.Ruleset) error {
+	if err := c.GameRepository.UpdateGameRules(ctx, gameID, rules); err != nil {
+		return err
+	}
+	c.update(gameID, func(game *models.Game) {
+		copied := *rules
+		game.Rules = &copied
+	})
+	return nil
+}
+
+// TransitionGameState moves a game from one lifecycle state to another,
+// dropping it from the cache once the engine no longer runs it
+func (c *CachedRepository) TransitionGameState(ctx context.Context, gameID string, from, to models.GameState) error {
+	if err := c.GameRepository.TransitionGameState(ctx, gameID, from, to); err != nil {
+		if errors.Is(err, ErrStateConflict) {
+			c.Invalidate()
+		}
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if game, ok := c.games[gameID]; ok {
+		game.State = to
+		if !game.IsRunning() {
+			delete(c.games, gameID)
+		}
+	}
+	return nil
+}
+
+// EndGame marks a game ended with its winners. A conflict means the cache
+// is stale, so it is reloaded.
+func (c *CachedRepository) EndGame(ctx context.Context, gameID string, from models.GameState, winners []string) error {
+	if err := c.GameRepository.EndGame(ctx, gameID, from, winners); err != nil {
+		if errors.Is(err, ErrStateConflict) {
+			c.Invalidate()
+		}
+		return err
+	}
+	c.mu.Lock()
+	delete(c.games, gameID)
+	c.mu.Unlock()
+	return nil
+}
+
+// RecordFireMastery notes the year a player mastered fire, keeping the earliest
+func (c *CachedRepository) RecordFireMastery(ctx context.Context, gameID string, playerID string, year int) error {
+	if err := c.GameRepository.RecordFireMastery(ctx, gameID, playerID, year); err != nil {
+		return err
+	}
+	c.update(gameID, func(game *models.Game) {
+		if game.FireMastery == nil {
+			game.FireMastery = make(map[string]int)
+		}
+		if recorded, ok := game.FireMastery[playerID]; !ok || year < recorded {
+			game.FireMastery[playerID] = year
+		}
+	})
+	return nil
+}
+
+// RecordClockAdjustment appends a skipped-downtime record to a game
+func (c *CachedRepository) RecordClockAdjustment(ctx context.Context, gameID string, adjustment models.ClockAdjustment) error {
+	if err := c.GameRepository.RecordClockAdjustment(ctx, gameID, adjustment); err != nil {
+		return err
+	}
+	c.update(gameID, func(game *models.Game) {
+		game.ClockAdjustments = append(game.ClockAdjustments, adjustment)
+	})
+	return nil
+}
+
+// EliminatePlayer marks a player eliminated and releases their holdings
+func (c *CachedRepository) EliminatePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	if err := c.GameRepository.EliminatePlayer(ctx, gameID, playerID, tick); err != nil {
+		return err
+	}
+	c.update(gameID, func(game *models.Game) {
+		if !containsString(game.EliminatedPlayers, playerID) {
+			game.EliminatedPlayers = append(game.EliminatedPlayers, playerID)
+		}
+	})
+	return nil
+}
+
+// ReleasePlayer removes a player from a game as if they had never joined
+func (c *CachedRepository) ReleasePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	if err := c.GameRepository.ReleasePlayer(ctx, gameID, playerID, tick); err != nil {
+		return err
+	}
+	c.update(gameID, func(game *models.Game) {
+		if containsString(game.PlayerList, playerID) {
+			game.PlayerList = removeString(game.PlayerList, playerID)
+			game.CurrentPlayers--
+			delete(game.Teams, playerID)
+		}
+	})
+	return nil
+}
+
+// Ping checks the underlying repository's store, if it can be checked
+func (c *CachedRepository) Ping(ctx context.Context) error {
+	if checker, ok := c.GameRepository.(HealthChecker); ok {
+		return checker.Ping(ctx)
+	}
+	return nil
+}
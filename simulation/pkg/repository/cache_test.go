@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestCachedRepository_GetStartedGames(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemoryRepository()
+	memory.InsertGame(&models.Game{GameID: "game-a", State: "started", CurrentYear: -5000, PlayerList: []string{"p1", "p2"}})
+	memory.InsertGame(&models.Game{GameID: "game-b", State: "waiting"})
+	cache := NewCachedRepository(memory, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		games, err := cache.GetStartedGames(ctx)
+		if err != nil {
+			t.Fatalf("GetStartedGames failed: %v", err)
+		}
+		if len(games) != 1 || games[0].GameID != "game-a" {
+			t.Fatalf("Expected only game-a, got %v", games)
+		}
+		// Callers may mutate what they are given without touching the cache
+		games[0].CurrentYear = 9999
+	}
+	if reads := memory.OpCounts()["GetStartedGames"]; reads != 1 {
+		t.Errorf("Expected one repository read, got %d", reads)
+	}
+
+	// The engine's writes are applied to the cached copy
+	if err := cache.UpdateGameTick(ctx, "game-a", -5000, -4999, time.Now()); err != nil {
+		t.Fatalf("UpdateGameTick failed: %v", err)
+	}
+	if err := cache.EliminatePlayer(ctx, "game-a", "p2", -4999); err != nil {
+		t.Fatalf("EliminatePlayer failed: %v", err)
+	}
+	if err := cache.RecordFireMastery(ctx, "game-a", "p1", -4999); err != nil {
+		t.Fatalf("RecordFireMastery failed: %v", err)
+	}
+	games, _ := cache.GetStartedGames(ctx)
+	if games[0].CurrentYear != -4999 || games[0].LastTickAt == nil || games[0].Calendar == nil || games[0].Calendar.Year != -4999 {
+		t.Errorf("Expected the cached tick and calendar to advance, got year %d and calendar %+v", games[0].CurrentYear, games[0].Calendar)
+	}
+	if !games[0].IsEliminated("p2") {
+		t.Error("Expected the cached game to record the elimination")
+	}
+	if year, ok := games[0].FireMastery["p1"]; !ok || year != -4999 {
+		t.Errorf("Expected the cached game to record p1 mastering fire, got %v", games[0].FireMastery)
+	}
+
+	// Streamed changes add and remove games
+	cache.applyChange(&models.Game{GameID: "game-b", State: "started"})
+	games, _ = cache.GetStartedGames(ctx)
+	if len(games) != 2 {
+		t.Errorf("Expected the started game to be added, got %d games", len(games))
+	}
+	if err := cache.EndGame(ctx, "game-a", models.GameStateActive, []string{"p1"}); err != nil {
+		t.Fatalf("EndGame failed: %v", err)
+	}
+	games, _ = cache.GetStartedGames(ctx)
+	if len(games) != 1 || games[0].GameID != "game-b" {
+		t.Errorf("Expected only game-b after game-a ended, got %v", games)
+	}
+	if reads := memory.OpCounts()["GetStartedGames"]; reads != 1 {
+		t.Errorf("Expected no further repository reads, got %d", reads)
+	}
+
+	// Invalidation reloads from the repository, which never saw game-b start
+	cache.Invalidate()
+	games, _ = cache.GetStartedGames(ctx)
+	if len(games) != 0 {
+		t.Errorf("Expected no started games after reload, got %d", len(games))
+	}
+	if reads := memory.OpCounts()["GetStartedGames"]; reads != 2 {
+		t.Errorf("Expected a second repository read, got %d", reads)
+	}
+}
@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// DryRunRepository lets the engine tick real games without touching them.
+// It snapshots every started game from a source repository into memory and
+// runs against the copy: reads see the engine's earlier would-be changes,
+// and every write is logged before it is applied to the snapshot. The
+// source is only ever read.
+type DryRunRepository struct {
+	*MemoryRepository
+}
+
+// NewDryRunRepository snapshots the started games of source and the
+// documents the engine reads for them
+func NewDryRunRepository(ctx context.Context, source GameRepository) (*DryRunRepository, error) {
+	snapshot := NewMemoryRepository()
+	games, err := source.GetStartedGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		if err := loadGameSnapshot(ctx, source, snapshot, game); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot.mu.Lock()
+	snapshot.ops = make(map[string]int64)
+	snapshot.mu.Unlock()
+
+	log.Printf("Dry run: loaded %d started games, no changes will be persisted", len(games))
+	return &DryRunRepository{MemoryRepository: snapshot}, nil
+}
+
+// loadGameSnapshot copies one game and everything the engine reads for it
+func loadGameSnapshot(ctx context.Context, source GameRepository, snapshot *MemoryRepository, game *models.Game) error {
+	snapshot.InsertGame(game)
+	gameID := game.GameID
+
+	// A game still starting has no map yet; the engine will generate one
+	metadata, err := source.GetMapMetadata(ctx, gameID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if metadata != nil {
+		snapshot.SaveMapMetadata(ctx, metadata)
+	}
+	tiles, err := source.GetMapTiles(ctx, gameID, nil)
+	if err != nil {
+		return err
+	}
+	snapshot.SaveMapTiles(ctx, tiles)
+
+	var positions []*models.StartingPosition
+	for _, playerID := range game.PlayerList {
+		position, err := source.GetStartingPosition(ctx, gameID, playerID)
+		if err != nil {
+			return err
+		}
+		if position != nil {
+			positions = append(positions, position)
+		}
+	}
+	snapshot.SaveStartingPositions(ctx, positions)
+
+	explored, err := source.GetExploredTiles(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	snapshot.SaveExploredTiles(ctx, explored)
+
+	units, err := source.GetUnits(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, unit := range units {
+		snapshot.CreateUnit(ctx, unit)
+	}
+	settlements, err := source.GetSettlements(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, settlement := range settlements {
+		snapshot.CreateSettlement(ctx, settlement)
+	}
+	orders, err := source.GetPendingOrders(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		snapshot.CreateOrder(ctx, order)
+	}
+
+	activity, err := source.GetPlayerActivity(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, record := range activity {
+		snapshot.RecordPlayerActivity(gameID, record.PlayerID, record.LastActiveTick)
+	}
+	policies, err := source.GetPlayerPolicies(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		snapshot.SavePlayerPolicy(ctx, policy)
+	}
+	settings, err := source.GetPlayerSettings(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, saved := range settings {
+		snapshot.SavePlayerSettings(ctx, saved)
+	}
+	states, err := source.GetDiplomacyStates(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		snapshot.SaveDiplomacyState(ctx, state)
+	}
+	civs, err := source.GetMinorCivs(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, civ := range civs {
+		snapshot.SaveMinorCiv(ctx, civ)
+	}
+	objectives, err := source.GetObjectives(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, objective := range objectives {
+		snapshot.SaveObjective(ctx, objective)
+	}
+	history, err := source.GetGameHistory(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	for _, point := range history {
+		snapshot.SaveGameHistory(ctx, point)
+	}
+	return nil
+}
+
+// would logs a change the engine would have persisted
+func (r *DryRunRepository) would(format string, args ...any) {
+	log.Printf("Dry run: would "+format, args...)
+}
+
+// LogSummary logs how many times each write was made during the dry run
+func (r *DryRunRepository) LogSummary() {
+	counts := r.OpCounts()
+	var writes []string
+	for op, n := range counts {
+		if !strings.HasPrefix(op, "Get") && n > 0 {
+			writes = append(writes, op)
+		}
+	}
+	sort.Strings(writes)
+	log.Printf("Dry run summary: %d kinds of write", len(writes))
+	for _, op := range writes {
+		log.Printf("Dry run summary: %s x%d", op, counts[op])
+	}
+}
+
+// ClaimGameTick logs and applies a claim on a game's tick
+func (r *DryRunRepository) ClaimGameTick(ctx context.Context, gameID string, year int, claimedAt time.Time, lease time.Duration) error {
+	r.would("claim the tick of year %d in game %s", year, gameID)
+	return r.MemoryRepository.ClaimGameTick(ctx, gameID, year, claimedAt, lease)
+}
+
+// UpdateGameTick logs and applies a game's advance to a new year
+func (r *DryRunRepository) UpdateGameTick(ctx context.Context, gameID string, expectedYear, newYear int, tickTime time.Time) error {
+	r.would("advance game %s from year %d to %d", gameID, expectedYear, newYear)
+	return r.MemoryRepository.UpdateGameTick(ctx, gameID, expectedYear, newYear, tickTime)
+}
+
+// UpdateGameSeeds logs and applies a game's new seed positions
+func (r *DryRunRepository) UpdateGameSeeds(ctx context.Context, gameID string, seeds *models.GameSeeds) error {
+	r.would("save seed positions %v for game %s", seeds.Positions(), gameID)
+	return r.MemoryRepository.UpdateGameSeeds(ctx, gameID, seeds)
+}
+
+// UpdateGameRules logs and applies a game's pinned ruleset
+func (r *DryRunRepository) UpdateGameRules(ctx context.Context, gameID string, rules *models.Ruleset) error {
+	r.would("pin rules version %d to game %s", rules.Version, gameID)
+	return r.MemoryRepository.UpdateGameRules(ctx, gameID, rules)
+}
+
+// TransitionGameState logs and applies a game's lifecycle change
+func (r *DryRunRepository) TransitionGameState(ctx context.Context, gameID string, from, to models.GameState) error {
+	r.would("move game %s from %s to %s", gameID, from, to)
+	return r.MemoryRepository.TransitionGameState(ctx, gameID, from, to)
+}
+
+// EndGame logs and applies the end of a game
+func (r *DryRunRepository) EndGame(ctx context.Context, gameID string, from models.GameState, winners []string) error {
+	r.would("end game %s won by %v", gameID, winners)
+	return r.MemoryRepository.EndGame(ctx, gameID, from, winners)
+}
+
+// RecordFireMastery logs and applies a player mastering fire
+func (r *DryRunRepository) RecordFireMastery(ctx context.Context, gameID string, playerID string, year int) error {
+	r.would("record %s mastering fire in game %s in year %d", playerID, gameID, year)
+	return r.MemoryRepository.RecordFireMastery(ctx, gameID, playerID, year)
+}
+
+// RecordClockAdjustment logs and applies a skipped-downtime record
+func (r *DryRunRepository) RecordClockAdjustment(ctx context.Context, gameID string, adjustment models.ClockAdjustment) error {
+	r.would("skip %d ticks of game %s", adjustment.SkippedTicks, gameID)
+	return r.MemoryRepository.RecordClockAdjustment(ctx, gameID, adjustment)
+}
+
+// SaveMapMetadata logs and applies a game's map metadata
+func (r *DryRunRepository) SaveMapMetadata(ctx context.Context, metadata *models.MapMetadata) error {
+	r.would("save %dx%d map metadata for game %s", metadata.Width, metadata.Height, metadata.GameID)
+	return r.MemoryRepository.SaveMapMetadata(ctx, metadata)
+}
+
+// SaveMapTiles logs and applies a batch of map tiles
+func (r *DryRunRepository) SaveMapTiles(ctx context.Context, tiles []*models.MapTile) error {
+	r.would("save %d map tiles", len(tiles))
+	return r.MemoryRepository.SaveMapTiles(ctx, tiles)
+}
+
+// SaveStartingPositions logs and applies players' starting positions
+func (r *DryRunRepository) SaveStartingPositions(ctx context.Context, positions []*models.StartingPosition) error {
+	r.would("save %d starting positions", len(positions))
+	return r.MemoryRepository.SaveStartingPositions(ctx, positions)
+}
+
+// SaveExploredTiles logs and applies last-seen tile records
+func (r *DryRunRepository) SaveExploredTiles(ctx context.Context, tiles []*models.ExploredTile) error {
+	r.would("save %d explored tiles", len(tiles))
+	return r.MemoryRepository.SaveExploredTiles(ctx, tiles)
+}
+
+// SaveMinimap logs and applies a player's minimap
+func (r *DryRunRepository) SaveMinimap(ctx context.Context, minimap *models.Minimap) error {
+	r.would("save the minimap of %s in game %s", minimap.PlayerID, minimap.GameID)
+	return r.MemoryRepository.SaveMinimap(ctx, minimap)
+}
+
+// SaveBorders logs and applies the territory borders a player knows of
+func (r *DryRunRepository) SaveBorders(ctx context.Context, borders *models.Borders) error {
+	r.would("save the borders known to %s in game %s", borders.PlayerID, borders.GameID)
+	return r.MemoryRepository.SaveBorders(ctx, borders)
+}
+
+// RevealTiles logs and applies tiles coming into a player's view
+func (r *DryRunRepository) RevealTiles(ctx context.Context, gameID string, playerID string, locations []models.Location, tick int) error {
+	r.would("reveal %d tiles to %s in game %s", len(locations), playerID, gameID)
+	return r.MemoryRepository.RevealTiles(ctx, gameID, playerID, locations, tick)
+}
+
+// CreateUnit logs and applies a new unit
+func (r *DryRunRepository) CreateUnit(ctx context.Context, unit *models.Unit) error {
+	r.would("create %s unit %s for %s at (%d, %d)", unit.UnitType, unit.UnitID, unit.PlayerID, unit.Location.X, unit.Location.Y)
+	return r.MemoryRepository.CreateUnit(ctx, unit)
+}
+
+// UpdateUnit logs and applies a unit update
+func (r *DryRunRepository) UpdateUnit(ctx context.Context, unit *models.Unit) error {
+	r.would("update unit %s at (%d, %d) with %d health", unit.UnitID, unit.Location.X, unit.Location.Y, unit.Health())
+	return r.MemoryRepository.UpdateUnit(ctx, unit)
+}
+
+// DeleteUnit logs and applies a unit's removal
+func (r *DryRunRepository) DeleteUnit(ctx context.Context, unitID string) error {
+	r.would("delete unit %s", unitID)
+	return r.MemoryRepository.DeleteUnit(ctx, unitID)
+}
+
+// CreateSettlement logs and applies a new settlement
+func (r *DryRunRepository) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	r.would("found %s %s for %s at (%d, %d)", settlement.Type, settlement.Name, settlement.PlayerID, settlement.Location.X, settlement.Location.Y)
+	return r.MemoryRepository.CreateSettlement(ctx, settlement)
+}
+
+// UpdateSettlement logs and applies a settlement update
+func (r *DryRunRepository) UpdateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	r.would("update %s %s: population %d, buildings %v", settlement.Type, settlement.SettlementID, settlement.Population, settlement.Buildings)
+	return r.MemoryRepository.UpdateSettlement(ctx, settlement)
+}
+
+// AssignTiles logs and applies tiles joining a settlement
+func (r *DryRunRepository) AssignTiles(ctx context.Context, gameID string, settlementID string, playerID string, locations []models.Location, tick int) error {
+	r.would("assign up to %d tiles to settlement %s of %s", len(locations), settlementID, playerID)
+	return r.MemoryRepository.AssignTiles(ctx, gameID, settlementID, playerID, locations, tick)
+}
+
+// MergeSettlements logs and applies one settlement absorbing another or
+// becoming its suburb
+func (r *DryRunRepository) MergeSettlements(ctx context.Context, survivor *models.Settlement, absorbed *models.Settlement, asSuburb bool, tick int) error {
+	if asSuburb {
+		r.would("make settlement %s a suburb of %s", absorbed.SettlementID, survivor.SettlementID)
+	} else {
+		r.would("merge settlement %s into %s", absorbed.SettlementID, survivor.SettlementID)
+	}
+	return r.MemoryRepository.MergeSettlements(ctx, survivor, absorbed, asSuburb, tick)
+}
+
+// UpdateTileResources logs and applies a tile's depleted resources
+func (r *DryRunRepository) UpdateTileResources(ctx context.Context, tile *models.MapTile) error {
+	r.would("set the resources of (%d, %d) in game %s to %v", tile.X, tile.Y, tile.GameID, tile.Resources)
+	return r.MemoryRepository.UpdateTileResources(ctx, tile)
+}
+
+// UpdateTileFertility logs and applies a tile's soil fertility
+func (r *DryRunRepository) UpdateTileFertility(ctx context.Context, tile *models.MapTile) error {
+	r.would("set the fertility of (%d, %d) in game %s to %.2f", tile.X, tile.Y, tile.GameID, tile.Fertility)
+	return r.MemoryRepository.UpdateTileFertility(ctx, tile)
+}
+
+// UpdateTilePollution logs and applies a tile's pollution
+func (r *DryRunRepository) UpdateTilePollution(ctx context.Context, tile *models.MapTile) error {
+	r.would("set the pollution of (%d, %d) in game %s to %.2f", tile.X, tile.Y, tile.GameID, tile.Pollution)
+	return r.MemoryRepository.UpdateTilePollution(ctx, tile)
+}
+
+// UpdateTileTerrain logs and applies a tile's changed terrain
+func (r *DryRunRepository) UpdateTileTerrain(ctx context.Context, tile *models.MapTile) error {
+	r.would("turn (%d, %d) in game %s into %s", tile.X, tile.Y, tile.GameID, tile.TerrainType)
+	return r.MemoryRepository.UpdateTileTerrain(ctx, tile)
+}
+
+// AddTileImprovement logs and applies a tile improvement
+func (r *DryRunRepository) AddTileImprovement(ctx context.Context, gameID string, x int, y int, improvement string, tick int) error {
+	r.would("build %s on (%d, %d) in game %s", improvement, x, y, gameID)
+	return r.MemoryRepository.AddTileImprovement(ctx, gameID, x, y, improvement, tick)
+}
+
+// UpdateTileImprovements logs and applies a tile's improvements and damage
+func (r *DryRunRepository) UpdateTileImprovements(ctx context.Context, tile *models.MapTile) error {
+	r.would("set the improvements of (%d, %d) in game %s to %v, damaged %v", tile.X, tile.Y, tile.GameID, tile.Improvements, tile.Damaged)
+	return r.MemoryRepository.UpdateTileImprovements(ctx, tile)
+}
+
+// CreateOrder logs and applies a queued order
+func (r *DryRunRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	r.would("queue %s order %s for %s", order.OrderType, order.OrderID, order.PlayerID)
+	return r.MemoryRepository.CreateOrder(ctx, order)
+}
+
+// UpdateOrder logs and applies an order's outcome
+func (r *DryRunRepository) UpdateOrder(ctx context.Context, order *models.Order) error {
+	r.would("mark %s order %s %s %s", order.OrderType, order.OrderID, order.Status, order.Reason)
+	return r.MemoryRepository.UpdateOrder(ctx, order)
+}
+
+// EliminatePlayer logs and applies a player's elimination
+func (r *DryRunRepository) EliminatePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	r.would("eliminate %s from game %s", playerID, gameID)
+	return r.MemoryRepository.EliminatePlayer(ctx, gameID, playerID, tick)
+}
+
+// ReleasePlayer logs and applies a player's release
+func (r *DryRunRepository) ReleasePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	r.would("release %s from game %s", playerID, gameID)
+	return r.MemoryRepository.ReleasePlayer(ctx, gameID, playerID, tick)
+}
+
+// SaveDiplomacyState logs and applies the standing between two players
+func (r *DryRunRepository) SaveDiplomacyState(ctx context.Context, state *models.DiplomacyState) error {
+	r.would("set %s and %s in game %s to %s", state.PlayerA, state.PlayerB, state.GameID, state.Stance)
+	return r.MemoryRepository.SaveDiplomacyState(ctx, state)
+}
+
+// SaveMinorCiv logs and applies a minor civilization's state
+func (r *DryRunRepository) SaveMinorCiv(ctx context.Context, civ *models.MinorCiv) error {
+	r.would("save minor civ %s in game %s allied to %q", civ.Name, civ.GameID, civ.Ally)
+	return r.MemoryRepository.SaveMinorCiv(ctx, civ)
+}
+
+// CaptureSettlement logs and applies a settlement changing hands
+func (r *DryRunRepository) CaptureSettlement(ctx context.Context, settlement *models.Settlement, playerID string, tick int) error {
+	r.would("hand settlement %s to %s", settlement.SettlementID, playerID)
+	return r.MemoryRepository.CaptureSettlement(ctx, settlement, playerID, tick)
+}
+
+// SavePlayerActivity logs and applies a player's last-active record
+func (r *DryRunRepository) SavePlayerActivity(ctx context.Context, record *models.PlayerActivity) error {
+	r.would("mark %s active in game %s at year %d", record.PlayerID, record.GameID, record.LastActiveTick)
+	return r.MemoryRepository.SavePlayerActivity(ctx, record)
+}
+
+// SavePlayerPolicy logs and applies a player's policies
+func (r *DryRunRepository) SavePlayerPolicy(ctx context.Context, policy *models.PlayerPolicy) error {
+	r.would("set the policies of %s in game %s", policy.PlayerID, policy.GameID)
+	return r.MemoryRepository.SavePlayerPolicy(ctx, policy)
+}
+
+// SavePlayerSettings logs and applies a player's settings
+func (r *DryRunRepository) SavePlayerSettings(ctx context.Context, settings *models.PlayerSettings) error {
+	r.would("save the settings of %s in game %s", settings.PlayerID, settings.GameID)
+	return r.MemoryRepository.SavePlayerSettings(ctx, settings)
+}
+
+// SaveSettlementSimulation logs and applies a settlement's saved human simulation
+func (r *DryRunRepository) SaveSettlementSimulation(ctx context.Context, saved *models.SettlementSimulation) error {
+	r.would("save the simulation of settlement %s after year %d (population %d)", saved.SettlementID, saved.Year, saved.Population)
+	return r.MemoryRepository.SaveSettlementSimulation(ctx, saved)
+}
+
+// SaveObjective logs and applies an objective
+func (r *DryRunRepository) SaveObjective(ctx context.Context, objective *models.Objective) error {
+	r.would("save %s objective %s for %s as %s", objective.Type, objective.ObjectiveID, objective.PlayerID, objective.Status)
+	return r.MemoryRepository.SaveObjective(ctx, objective)
+}
+
+// RecordGameResult logs and applies a user's game result
+func (r *DryRunRepository) RecordGameResult(ctx context.Context, result *models.GameResult) error {
+	r.would("record game %s for %s (victory %q)", result.GameID, result.UserID, result.VictoryType)
+	return r.MemoryRepository.RecordGameResult(ctx, result)
+}
+
+// CreateEvent logs and applies a game event
+func (r *DryRunRepository) CreateEvent(ctx context.Context, event *models.GameEvent) error {
+	r.would("record %s event in game %s: %s", event.Type, event.GameID, event.Detail)
+	return r.MemoryRepository.CreateEvent(ctx, event)
+}
+
+// SaveGameHistory logs and applies a game's standings for a year
+func (r *DryRunRepository) SaveGameHistory(ctx context.Context, point *models.GameHistoryPoint) error {
+	r.would("record the standings of game %s in year %d", point.GameID, point.Year)
+	return r.MemoryRepository.SaveGameHistory(ctx, point)
+}
+
+// SaveGameSummary logs and applies a finished game's summary
+func (r *DryRunRepository) SaveGameSummary(ctx context.Context, summary *models.GameSummary) error {
+	r.would("write the summary of game %s", summary.GameID)
+	return r.MemoryRepository.SaveGameSummary(ctx, summary)
+}
+
+// CreateGame logs and applies the new game to the snapshot
+func (r *DryRunRepository) CreateGame(ctx context.Context, game *models.Game) error {
+	r.would("create game %s", game.GameID)
+	return r.MemoryRepository.CreateGame(ctx, game)
+}
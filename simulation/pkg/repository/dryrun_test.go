@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestDryRunRepository(t *testing.T) {
+	ctx := context.Background()
+	source := NewMemoryRepository()
+	source.InsertGame(&models.Game{GameID: "game-a", State: "started", CurrentYear: -5000, PlayerList: []string{"p1"}})
+	source.InsertGame(&models.Game{GameID: "game-b", State: "waiting"})
+	source.CreateSettlement(ctx, &models.Settlement{SettlementID: "s1", GameID: "game-a", PlayerID: "p1", Population: 100})
+	source.CreateUnit(ctx, &models.Unit{UnitID: "u1", GameID: "game-a", PlayerID: "p1"})
+
+	dryRun, err := NewDryRunRepository(ctx, source)
+	if err != nil {
+		t.Fatalf("NewDryRunRepository failed: %v", err)
+	}
+	before := source.OpCounts()
+
+	// Only started games are snapshotted
+	if game, _ := dryRun.GetGame(ctx, "game-b"); game != nil {
+		t.Errorf("Expected no snapshot of a waiting game, got %+v", game)
+	}
+
+	// The engine sees its own changes
+	if err := dryRun.UpdateGameTick(ctx, "game-a", -5000, -4999, time.Now()); err != nil {
+		t.Fatalf("UpdateGameTick failed: %v", err)
+	}
+	settlements, _ := dryRun.GetSettlements(ctx, "game-a")
+	if len(settlements) != 1 {
+		t.Fatalf("Expected the settlement to be snapshotted, got %v", settlements)
+	}
+	settlements[0].Population = 200
+	dryRun.UpdateSettlement(ctx, settlements[0])
+	dryRun.DeleteUnit(ctx, "u1")
+	if game, _ := dryRun.GetGame(ctx, "game-a"); game.CurrentYear != -4999 {
+		t.Errorf("Expected the snapshot to advance to -4999, got %d", game.CurrentYear)
+	}
+	if settlements, _ := dryRun.GetSettlements(ctx, "game-a"); settlements[0].Population != 200 {
+		t.Errorf("Expected the snapshot's settlement to grow, got %d", settlements[0].Population)
+	}
+
+	// The source is untouched, and not even read again
+	if game, _ := source.GetGame(ctx, "game-a"); game.CurrentYear != -5000 {
+		t.Errorf("Expected the source to stay at -5000, got %d", game.CurrentYear)
+	}
+	if settlements, _ := source.GetSettlements(ctx, "game-a"); settlements[0].Population != 100 {
+		t.Errorf("Expected the source settlement unchanged, got %d", settlements[0].Population)
+	}
+	if units, _ := source.GetUnits(ctx, "game-a"); len(units) != 1 {
+		t.Errorf("Expected the source unit to survive, got %v", units)
+	}
+	for op, n := range before {
+		if op != "GetGame" && op != "GetSettlements" && op != "GetUnits" && source.OpCounts()[op] != n {
+			t.Errorf("Expected no further %s calls on the source", op)
+		}
+	}
+	if writes := dryRun.OpCounts(); writes["UpdateSettlement"] != 1 || writes["CreateSettlement"] != 0 {
+		t.Errorf("Expected only the dry run's own writes to be counted, got %v", writes)
+	}
+}
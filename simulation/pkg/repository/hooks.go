@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// EventHook is called with each game event once it has been recorded
+type EventHook func(event *models.GameEvent)
+
+// HookedRepository wraps a GameRepository so that every event the engine
+// records is also handed to hooks, such as webhooks, after it is stored.
+// Hooks run on the recording goroutine and must not block.
+type HookedRepository struct {
+	GameRepository
+
+	hooks []EventHook
+}
+
+// NewHookedRepository wraps repo to call hooks with each recorded event
+func NewHookedRepository(repo GameRepository, hooks ...EventHook) *HookedRepository {
+	return &HookedRepository{GameRepository: repo, hooks: hooks}
+}
+
+// CreateEvent records a game event, then calls the hooks with it
+func (r *HookedRepository) CreateEvent(ctx context.Context, event *models.GameEvent) error {
+	if err := r.GameRepository.CreateEvent(ctx, event); err != nil {
+		return err
+	}
+	for _, hook := range r.hooks {
+		hook(event)
+	}
+	return nil
+}
+
+// Ping checks the underlying repository's store, if it can be checked
+func (r *HookedRepository) Ping(ctx context.Context) error {
+	if checker, ok := r.GameRepository.(HealthChecker); ok {
+		return checker.Ping(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,1498 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// MemoryRepository implements GameRepository entirely in memory.
+// It is used by the load-testing harness and other tools that need a real
+// repository without a MongoDB server. Documents are copied on every read and
+// write so callers observe the same isolation they would get from MongoDB,
+// and every operation is counted for reporting.
+type MemoryRepository struct {
+	mu                sync.Mutex
+	games             map[string]*models.Game
+	mapMetadata       map[string]*models.MapMetadata
+	mapTiles          map[string][]*models.MapTile
+	startingPositions map[string][]*models.StartingPosition
+	units             map[string]*models.Unit
+	settlements       map[string]*models.Settlement
+	settlementSims    map[string]*models.SettlementSimulation
+	orders            []*models.Order
+	exploredTiles     map[string][]*models.ExploredTile
+	minimaps          map[string]*models.Minimap
+	borders           map[string]*models.Borders
+	playerActivity    []*models.PlayerActivity
+	playerPolicies    []*models.PlayerPolicy
+	playerSettings    []*models.PlayerSettings
+	events            []*models.GameEvent
+	gameHistory       []*models.GameHistoryPoint
+	gameSummaries     map[string]*models.GameSummary
+	balanceReports    []*models.BalanceReport
+	diplomacy         map[string]*models.DiplomacyState
+	minorCivs         map[string]*models.MinorCiv
+	objectives        map[string]*models.Objective
+	userStats         map[string]*models.UserStats
+	ops               map[string]int64
+}
+
+// NewMemoryRepository creates an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		games:             make(map[string]*models.Game),
+		mapMetadata:       make(map[string]*models.MapMetadata),
+		mapTiles:          make(map[string][]*models.MapTile),
+		startingPositions: make(map[string][]*models.StartingPosition),
+		units:             make(map[string]*models.Unit),
+		settlements:       make(map[string]*models.Settlement),
+		settlementSims:    make(map[string]*models.SettlementSimulation),
+		exploredTiles:     make(map[string][]*models.ExploredTile),
+		minimaps:          make(map[string]*models.Minimap),
+		borders:           make(map[string]*models.Borders),
+		diplomacy:         make(map[string]*models.DiplomacyState),
+		minorCivs:         make(map[string]*models.MinorCiv),
+		objectives:        make(map[string]*models.Objective),
+		userStats:         make(map[string]*models.UserStats),
+		gameSummaries:     make(map[string]*models.GameSummary),
+		ops:               make(map[string]int64),
+	}
+}
+
+// count records one call of the named operation; callers must hold r.mu
+func (r *MemoryRepository) count(op string) {
+	r.ops[op]++
+}
+
+// OpCounts returns a snapshot of the number of calls per operation
+func (r *MemoryRepository) OpCounts() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int64, len(r.ops))
+	for op, n := range r.ops {
+		counts[op] = n
+	}
+	return counts
+}
+
+// InsertGame adds a game document (the web server owns game creation in production)
+func (r *MemoryRepository) InsertGame(game *models.Game) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.games[game.GameID] = cloneGame(game)
+}
+
+// CreateGame stores a new game, returning ErrConflict if its ID is taken
+func (r *MemoryRepository) CreateGame(ctx context.Context, game *models.Game) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("CreateGame")
+
+	if _, exists := r.games[game.GameID]; exists {
+		return fmt.Errorf("%w: game %s already exists", ErrConflict, game.GameID)
+	}
+	r.games[game.GameID] = cloneGame(game)
+	return nil
+}
+
+// GetStartedGames returns all games the engine runs: those starting or started
+func (r *MemoryRepository) GetStartedGames(ctx context.Context) ([]*models.Game, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetStartedGames")
+
+	var games []*models.Game
+	for _, game := range r.games {
+		if game.IsRunning() {
+			games = append(games, cloneGame(game))
+		}
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].GameID < games[j].GameID })
+	return games, nil
+}
+
+// GetGame returns a specific game by ID (or unique 8-character prefix)
+func (r *MemoryRepository) GetGame(ctx context.Context, gameID string) (*models.Game, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetGame")
+
+	if game, ok := r.games[gameID]; ok {
+		return cloneGame(game), nil
+	}
+	if len(gameID) == 8 {
+		for id, game := range r.games {
+			if strings.HasPrefix(id, gameID) {
+				return cloneGame(game), nil
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ClaimGameTick takes the tick of year unless another live claim holds it
+func (r *MemoryRepository) ClaimGameTick(ctx context.Context, gameID string, year int, claimedAt time.Time, lease time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("ClaimGameTick")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	if game.CurrentYear != year {
+		return ErrConcurrentTick
+	}
+	if claim := game.TickClaim; claim != nil && claim.Year == year && !claim.ClaimedAt.Before(claimedAt.Add(-lease)) {
+		return ErrConcurrentTick
+	}
+	game.TickClaim = &models.TickClaim{Year: year, ClaimedAt: claimedAt}
+	return nil
+}
+
+// UpdateGameTick advances the game from expectedYear to newYear, stamping
+// it with tickTime and the new year's calendar
+func (r *MemoryRepository) UpdateGameTick(ctx context.Context, gameID string, expectedYear, newYear int, tickTime time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateGameTick")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	if game.CurrentYear != expectedYear {
+		return ErrConcurrentTick
+	}
+	calendar := models.YearCalendar(newYear)
+	game.CurrentYear = newYear
+	game.LastTickAt = &tickTime
+	game.Calendar = &calendar
+	game.TickClaim = nil
+	return nil
+}
+
+// UpdateGameSeeds persists the game's RNG seed registry and stream positions
+func (r *MemoryRepository) UpdateGameSeeds(ctx context.Context, gameID string, seeds *models.GameSeeds) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateGameSeeds")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	copied := *seeds
+	game.Seeds = &copied
+	return nil
+}
+
+// UpdateGameRules pins the ruleset a game is played under
+func (r *MemoryRepository) UpdateGameRules(ctx context.Context, gameID string, rules *models.Ruleset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateGameRules")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	copied := *rules
+	game.Rules = &copied
+	return nil
+}
+
+// TransitionGameState moves a game from one lifecycle state to another
+func (r *MemoryRepository) TransitionGameState(ctx context.Context, gameID string, from, to models.GameState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("TransitionGameState")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	if game.State != from {
+		return ErrStateConflict
+	}
+	game.State = to
+	return nil
+}
+
+// EndGame marks a game ended with its winners if it is still in from
+func (r *MemoryRepository) EndGame(ctx context.Context, gameID string, from models.GameState, winners []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("EndGame")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	if game.State != from {
+		return ErrStateConflict
+	}
+	game.State = models.GameStateFinished
+	game.Winners = append([]string(nil), winners...)
+	return nil
+}
+
+// RecordFireMastery notes the year a player mastered fire, keeping the earliest
+func (r *MemoryRepository) RecordFireMastery(ctx context.Context, gameID string, playerID string, year int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("RecordFireMastery")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	if game.FireMastery == nil {
+		game.FireMastery = make(map[string]int)
+	}
+	if recorded, ok := game.FireMastery[playerID]; !ok || year < recorded {
+		game.FireMastery[playerID] = year
+	}
+	return nil
+}
+
+// RecordClockAdjustment appends a skipped-downtime record to a game
+func (r *MemoryRepository) RecordClockAdjustment(ctx context.Context, gameID string, adjustment models.ClockAdjustment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("RecordClockAdjustment")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	game.ClockAdjustments = append(game.ClockAdjustments, adjustment)
+	return nil
+}
+
+// GetPlayerActivity retrieves the last-active records of a game's players
+func (r *MemoryRepository) GetPlayerActivity(ctx context.Context, gameID string) ([]*models.PlayerActivity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetPlayerActivity")
+
+	var activity []*models.PlayerActivity
+	for _, record := range r.playerActivity {
+		if record.GameID == gameID {
+			copied := *record
+			activity = append(activity, &copied)
+		}
+	}
+	return activity, nil
+}
+
+// SavePlayerActivity inserts or replaces a player's last-active record
+func (r *MemoryRepository) SavePlayerActivity(ctx context.Context, record *models.PlayerActivity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SavePlayerActivity")
+
+	copied := *record
+	for i, existing := range r.playerActivity {
+		if existing.GameID == record.GameID && existing.PlayerID == record.PlayerID {
+			r.playerActivity[i] = &copied
+			return nil
+		}
+	}
+	r.playerActivity = append(r.playerActivity, &copied)
+	return nil
+}
+
+// GetPlayerPolicies retrieves the policies a game's players have set
+func (r *MemoryRepository) GetPlayerPolicies(ctx context.Context, gameID string) ([]*models.PlayerPolicy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetPlayerPolicies")
+
+	var policies []*models.PlayerPolicy
+	for _, policy := range r.playerPolicies {
+		if policy.GameID == gameID {
+			copied := *policy
+			policies = append(policies, &copied)
+		}
+	}
+	return policies, nil
+}
+
+// SavePlayerPolicy inserts or replaces a player's policies
+func (r *MemoryRepository) SavePlayerPolicy(ctx context.Context, policy *models.PlayerPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SavePlayerPolicy")
+
+	copied := *policy
+	for i, existing := range r.playerPolicies {
+		if existing.GameID == policy.GameID && existing.PlayerID == policy.PlayerID {
+			r.playerPolicies[i] = &copied
+			return nil
+		}
+	}
+	r.playerPolicies = append(r.playerPolicies, &copied)
+	return nil
+}
+
+// GetPlayerSettings retrieves the settings a game's players have saved
+func (r *MemoryRepository) GetPlayerSettings(ctx context.Context, gameID string) ([]*models.PlayerSettings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetPlayerSettings")
+
+	var settings []*models.PlayerSettings
+	for _, saved := range r.playerSettings {
+		if saved.GameID == gameID {
+			copied := *saved
+			copied.MutedEvents = append([]string(nil), saved.MutedEvents...)
+			settings = append(settings, &copied)
+		}
+	}
+	return settings, nil
+}
+
+// SavePlayerSettings inserts or replaces a player's settings
+func (r *MemoryRepository) SavePlayerSettings(ctx context.Context, settings *models.PlayerSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SavePlayerSettings")
+
+	copied := *settings
+	copied.MutedEvents = append([]string(nil), settings.MutedEvents...)
+	for i, existing := range r.playerSettings {
+		if existing.GameID == settings.GameID && existing.PlayerID == settings.PlayerID {
+			r.playerSettings[i] = &copied
+			return nil
+		}
+	}
+	r.playerSettings = append(r.playerSettings, &copied)
+	return nil
+}
+
+// RecordPlayerActivity marks a player active at the given tick, standing in
+// for the API server in tests and tools that use the in-memory repository
+func (r *MemoryRepository) RecordPlayerActivity(gameID string, playerID string, tick int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range r.playerActivity {
+		if record.GameID == gameID && record.PlayerID == playerID {
+			record.LastActiveTick = tick
+			record.LastActiveAt = time.Now()
+			return
+		}
+	}
+	r.playerActivity = append(r.playerActivity, &models.PlayerActivity{
+		GameID: gameID, PlayerID: playerID, LastActiveTick: tick, LastActiveAt: time.Now(),
+	})
+}
+
+// SaveMapMetadata saves map generation metadata
+func (r *MemoryRepository) SaveMapMetadata(ctx context.Context, metadata *models.MapMetadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveMapMetadata")
+
+	copied := *metadata
+	r.mapMetadata[metadata.GameID] = &copied
+	return nil
+}
+
+// SaveMapTiles saves map tiles in batch
+func (r *MemoryRepository) SaveMapTiles(ctx context.Context, tiles []*models.MapTile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveMapTiles")
+
+	for _, tile := range tiles {
+		r.mapTiles[tile.GameID] = append(r.mapTiles[tile.GameID], cloneTile(tile))
+	}
+	return nil
+}
+
+// SaveStartingPositions saves player starting positions
+func (r *MemoryRepository) SaveStartingPositions(ctx context.Context, positions []*models.StartingPosition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveStartingPositions")
+
+	for _, pos := range positions {
+		copied := *pos
+		r.startingPositions[pos.GameID] = append(r.startingPositions[pos.GameID], &copied)
+	}
+	return nil
+}
+
+// GetMapMetadata retrieves map metadata for a game
+func (r *MemoryRepository) GetMapMetadata(ctx context.Context, gameID string) (*models.MapMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetMapMetadata")
+
+	metadata, ok := r.mapMetadata[gameID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *metadata
+	return &copied, nil
+}
+
+// GetMapTiles retrieves map tiles for a game (with optional player visibility filtering)
+func (r *MemoryRepository) GetMapTiles(ctx context.Context, gameID string, playerID *string) ([]*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetMapTiles")
+
+	var tiles []*models.MapTile
+	for _, tile := range r.mapTiles[gameID] {
+		if playerID != nil && !containsString(tile.VisibleTo, *playerID) && (tile.OwnerID == nil || *tile.OwnerID != *playerID) {
+			continue
+		}
+		tiles = append(tiles, cloneTile(tile))
+	}
+	return tiles, nil
+}
+
+// GetSeenTiles retrieves tiles currently visible to or owned by any player
+func (r *MemoryRepository) GetSeenTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetSeenTiles")
+
+	var tiles []*models.MapTile
+	for _, tile := range r.mapTiles[gameID] {
+		if len(tile.VisibleTo) > 0 || tile.OwnerID != nil {
+			tiles = append(tiles, cloneTile(tile))
+		}
+	}
+	return tiles, nil
+}
+
+// GetExploredTiles retrieves every player's last-seen tile records for a game
+func (r *MemoryRepository) GetExploredTiles(ctx context.Context, gameID string) ([]*models.ExploredTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetExploredTiles")
+
+	tiles := make([]*models.ExploredTile, 0, len(r.exploredTiles[gameID]))
+	for _, tile := range r.exploredTiles[gameID] {
+		copied := *tile
+		tiles = append(tiles, &copied)
+	}
+	return tiles, nil
+}
+
+// SaveExploredTiles inserts or replaces last-seen tile records
+func (r *MemoryRepository) SaveExploredTiles(ctx context.Context, tiles []*models.ExploredTile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveExploredTiles")
+
+	for _, tile := range tiles {
+		copied := *tile
+		existing := r.exploredTiles[tile.GameID]
+		replaced := false
+		for i, other := range existing {
+			if other.PlayerID == tile.PlayerID && other.X == tile.X && other.Y == tile.Y {
+				existing[i] = &copied
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			r.exploredTiles[tile.GameID] = append(existing, &copied)
+		}
+	}
+	return nil
+}
+
+// SaveMinimap inserts or replaces a player's minimap
+func (r *MemoryRepository) SaveMinimap(ctx context.Context, minimap *models.Minimap) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveMinimap")
+
+	copied := *minimap
+	r.minimaps[minimap.GameID+"/"+minimap.PlayerID] = &copied
+	return nil
+}
+
+// SaveBorders inserts or replaces the territory borders a player knows of
+func (r *MemoryRepository) SaveBorders(ctx context.Context, borders *models.Borders) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveBorders")
+
+	copied := *borders
+	copied.Territories = append([]models.TerritoryBorder(nil), borders.Territories...)
+	r.borders[borders.GameID+"/"+borders.PlayerID] = &copied
+	return nil
+}
+
+// RevealTiles adds a player to the visibleTo list of the given tiles,
+// stamping those newly revealed with the tick
+func (r *MemoryRepository) RevealTiles(ctx context.Context, gameID string, playerID string, locations []models.Location, tick int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("RevealTiles")
+
+	for _, loc := range locations {
+		tile := r.findTile(gameID, loc.X, loc.Y)
+		if tile == nil || containsString(tile.VisibleTo, playerID) {
+			continue
+		}
+		tile.VisibleTo = append(tile.VisibleTo, playerID)
+		tile.LastModifiedTick = tick
+	}
+	return nil
+}
+
+// GetStartingPosition retrieves a player's starting position
+func (r *MemoryRepository) GetStartingPosition(ctx context.Context, gameID string, playerID string) (*models.StartingPosition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetStartingPosition")
+
+	for _, pos := range r.startingPositions[gameID] {
+		if pos.PlayerID == playerID {
+			copied := *pos
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateUnit creates a new unit
+func (r *MemoryRepository) CreateUnit(ctx context.Context, unit *models.Unit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("CreateUnit")
+
+	copied := *unit
+	r.units[unit.UnitID] = &copied
+	return nil
+}
+
+// GetUnits retrieves units for a game
+func (r *MemoryRepository) GetUnits(ctx context.Context, gameID string) ([]*models.Unit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetUnits")
+
+	var units []*models.Unit
+	for _, unit := range r.units {
+		if unit.GameID == gameID {
+			copied := *unit
+			units = append(units, &copied)
+		}
+	}
+	return units, nil
+}
+
+// GetUnitsByPlayer retrieves units for a specific player
+func (r *MemoryRepository) GetUnitsByPlayer(ctx context.Context, gameID string, playerID string) ([]*models.Unit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetUnitsByPlayer")
+
+	var units []*models.Unit
+	for _, unit := range r.units {
+		if unit.GameID == gameID && unit.PlayerID == playerID {
+			copied := *unit
+			units = append(units, &copied)
+		}
+	}
+	return units, nil
+}
+
+// UpdateUnit updates a unit
+func (r *MemoryRepository) UpdateUnit(ctx context.Context, unit *models.Unit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateUnit")
+
+	if _, ok := r.units[unit.UnitID]; !ok {
+		return nil // Mongo UpdateOne with no match is not an error
+	}
+	copied := *unit
+	r.units[unit.UnitID] = &copied
+	return nil
+}
+
+// DeleteUnit deletes a unit
+func (r *MemoryRepository) DeleteUnit(ctx context.Context, unitID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("DeleteUnit")
+
+	delete(r.units, unitID)
+	return nil
+}
+
+// CreateSettlement creates a new settlement
+func (r *MemoryRepository) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("CreateSettlement")
+
+	copied := *settlement
+	r.settlements[settlement.SettlementID] = &copied
+	return nil
+}
+
+// GetSettlements retrieves settlements for a game
+func (r *MemoryRepository) GetSettlements(ctx context.Context, gameID string) ([]*models.Settlement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetSettlements")
+
+	var settlements []*models.Settlement
+	for _, settlement := range r.settlements {
+		if settlement.GameID == gameID {
+			copied := *settlement
+			settlements = append(settlements, &copied)
+		}
+	}
+	return settlements, nil
+}
+
+// GetSettlementsByPlayer retrieves settlements for a specific player
+func (r *MemoryRepository) GetSettlementsByPlayer(ctx context.Context, gameID string, playerID string) ([]*models.Settlement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetSettlementsByPlayer")
+
+	var settlements []*models.Settlement
+	for _, settlement := range r.settlements {
+		if settlement.GameID == gameID && settlement.PlayerID == playerID {
+			copied := *settlement
+			settlements = append(settlements, &copied)
+		}
+	}
+	return settlements, nil
+}
+
+// UpdateSettlement updates a settlement
+func (r *MemoryRepository) UpdateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateSettlement")
+
+	if _, ok := r.settlements[settlement.SettlementID]; !ok {
+		return nil
+	}
+	copied := *settlement
+	r.settlements[settlement.SettlementID] = &copied
+	return nil
+}
+
+// GetSettlementSimulation retrieves a settlement's saved human simulation
+func (r *MemoryRepository) GetSettlementSimulation(ctx context.Context, settlementID string) (*models.SettlementSimulation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetSettlementSimulation")
+
+	saved, ok := r.settlementSims[settlementID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *saved
+	return &copied, nil
+}
+
+// SaveSettlementSimulation inserts or replaces a settlement's saved human simulation
+func (r *MemoryRepository) SaveSettlementSimulation(ctx context.Context, saved *models.SettlementSimulation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveSettlementSimulation")
+
+	copied := *saved
+	copied.Snapshot = append([]byte(nil), saved.Snapshot...)
+	r.settlementSims[saved.SettlementID] = &copied
+	return nil
+}
+
+// AssignTiles assigns unowned (or already player-owned) tiles at the given
+// locations to a settlement and its player
+func (r *MemoryRepository) AssignTiles(ctx context.Context, gameID string, settlementID string, playerID string, locations []models.Location, tick int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("AssignTiles")
+
+	for _, loc := range locations {
+		tile := r.findTile(gameID, loc.X, loc.Y)
+		if tile == nil || (tile.OwnerID != nil && *tile.OwnerID != playerID) {
+			continue
+		}
+		owner := playerID
+		tile.OwnerID = &owner
+		tile.SettlementID = settlementID
+		tile.LastModifiedTick = tick
+	}
+	return nil
+}
+
+// MergeSettlements atomically saves the surviving settlement, re-parents the
+// absorbed settlement's suburbs to it, and either saves the absorbed
+// settlement as its suburb or reassigns its tiles and deletes it
+func (r *MemoryRepository) MergeSettlements(ctx context.Context, survivor *models.Settlement, absorbed *models.Settlement, asSuburb bool, tick int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("MergeSettlements")
+
+	if _, ok := r.settlements[survivor.SettlementID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := r.settlements[absorbed.SettlementID]; !ok {
+		return ErrNotFound
+	}
+	for _, settlement := range []*models.Settlement{survivor, absorbed} {
+		copied := *settlement
+		copied.Buildings = append([]string(nil), settlement.Buildings...)
+		r.settlements[settlement.SettlementID] = &copied
+	}
+
+	for id, settlement := range r.settlements {
+		if settlement.ParentID == absorbed.SettlementID && id != survivor.SettlementID {
+			settlement.ParentID = survivor.SettlementID
+		}
+	}
+
+	if asSuburb {
+		return nil
+	}
+
+	for _, tile := range r.mapTiles[absorbed.GameID] {
+		if tile.SettlementID == absorbed.SettlementID {
+			tile.SettlementID = survivor.SettlementID
+			tile.LastModifiedTick = tick
+		}
+	}
+
+	delete(r.settlements, absorbed.SettlementID)
+	return nil
+}
+
+// EliminatePlayer marks a player eliminated, releases their tiles and
+// visibility, disbands their units and hands their settlements to the
+// neutral player
+func (r *MemoryRepository) EliminatePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("EliminatePlayer")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !containsString(game.EliminatedPlayers, playerID) {
+		game.EliminatedPlayers = append(game.EliminatedPlayers, playerID)
+	}
+
+	for _, tile := range r.mapTiles[gameID] {
+		if tile.OwnerID != nil && *tile.OwnerID == playerID {
+			tile.OwnerID = nil
+			tile.SettlementID = ""
+			tile.LastModifiedTick = tick
+		}
+		visibleTo := tile.VisibleTo[:0]
+		for _, id := range tile.VisibleTo {
+			if id != playerID {
+				visibleTo = append(visibleTo, id)
+			}
+		}
+		tile.VisibleTo = visibleTo
+	}
+
+	for unitID, unit := range r.units {
+		if unit.GameID == gameID && unit.PlayerID == playerID {
+			delete(r.units, unitID)
+		}
+	}
+	for _, settlement := range r.settlements {
+		if settlement.GameID == gameID && settlement.PlayerID == playerID {
+			settlement.PlayerID = models.NeutralPlayerID
+		}
+	}
+	return nil
+}
+
+// ReleasePlayer removes a player from a game as if they had never joined
+func (r *MemoryRepository) ReleasePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("ReleasePlayer")
+
+	game, ok := r.games[gameID]
+	if !ok {
+		return ErrNotFound
+	}
+	if containsString(game.PlayerList, playerID) {
+		game.PlayerList = removeString(game.PlayerList, playerID)
+		game.CurrentPlayers--
+		delete(game.Teams, playerID)
+	}
+
+	for _, tile := range r.mapTiles[gameID] {
+		if tile.OwnerID != nil && *tile.OwnerID == playerID {
+			tile.OwnerID = nil
+			tile.SettlementID = ""
+			tile.LastModifiedTick = tick
+		}
+		tile.VisibleTo = removeString(tile.VisibleTo, playerID)
+	}
+
+	for unitID, unit := range r.units {
+		if unit.GameID == gameID && unit.PlayerID == playerID {
+			delete(r.units, unitID)
+		}
+	}
+	for settlementID, settlement := range r.settlements {
+		if settlement.GameID == gameID && settlement.PlayerID == playerID {
+			delete(r.settlements, settlementID)
+			delete(r.settlementSims, settlementID)
+		}
+	}
+
+	var positions []*models.StartingPosition
+	for _, position := range r.startingPositions[gameID] {
+		if position.PlayerID != playerID {
+			positions = append(positions, position)
+		}
+	}
+	r.startingPositions[gameID] = positions
+
+	var explored []*models.ExploredTile
+	for _, tile := range r.exploredTiles[gameID] {
+		if tile.PlayerID != playerID {
+			explored = append(explored, tile)
+		}
+	}
+	r.exploredTiles[gameID] = explored
+	delete(r.minimaps, gameID+"/"+playerID)
+	delete(r.borders, gameID+"/"+playerID)
+	return nil
+}
+
+// removeString returns list without any occurrence of value
+func removeString(list []string, value string) []string {
+	kept := list[:0]
+	for _, v := range list {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// GetDiplomacyStates retrieves the standing of every pair of players in a
+// game that has had dealings
+func (r *MemoryRepository) GetDiplomacyStates(ctx context.Context, gameID string) ([]*models.DiplomacyState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetDiplomacyStates")
+
+	var states []*models.DiplomacyState
+	for _, state := range r.diplomacy {
+		if state.GameID == gameID {
+			states = append(states, cloneDiplomacyState(state))
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].PlayerA != states[j].PlayerA {
+			return states[i].PlayerA < states[j].PlayerA
+		}
+		return states[i].PlayerB < states[j].PlayerB
+	})
+	return states, nil
+}
+
+// SaveDiplomacyState inserts or replaces the standing between a pair of players
+func (r *MemoryRepository) SaveDiplomacyState(ctx context.Context, state *models.DiplomacyState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveDiplomacyState")
+
+	r.diplomacy[state.GameID+"/"+state.PlayerA+"/"+state.PlayerB] = cloneDiplomacyState(state)
+	return nil
+}
+
+// GetMinorCivs retrieves a game's minor civilizations, ordered by ID
+func (r *MemoryRepository) GetMinorCivs(ctx context.Context, gameID string) ([]*models.MinorCiv, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetMinorCivs")
+
+	var civs []*models.MinorCiv
+	for _, civ := range r.minorCivs {
+		if civ.GameID == gameID {
+			civs = append(civs, cloneMinorCiv(civ))
+		}
+	}
+	sort.Slice(civs, func(i, j int) bool { return civs[i].MinorCivID < civs[j].MinorCivID })
+	return civs, nil
+}
+
+// SaveMinorCiv inserts or replaces a minor civilization
+func (r *MemoryRepository) SaveMinorCiv(ctx context.Context, civ *models.MinorCiv) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveMinorCiv")
+
+	r.minorCivs[civ.GameID+"/"+civ.MinorCivID] = cloneMinorCiv(civ)
+	return nil
+}
+
+// CaptureSettlement hands a settlement and the tiles it owns to another
+// player, stamping the tiles modified at tick
+func (r *MemoryRepository) CaptureSettlement(ctx context.Context, settlement *models.Settlement, playerID string, tick int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("CaptureSettlement")
+
+	stored, ok := r.settlements[settlement.SettlementID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.PlayerID = playerID
+	for _, tile := range r.mapTiles[settlement.GameID] {
+		if tile.SettlementID == settlement.SettlementID {
+			owner := playerID
+			tile.OwnerID = &owner
+			tile.LastModifiedTick = tick
+		}
+	}
+	return nil
+}
+
+// GetObjectives retrieves every objective set in a game, ordered by ID
+func (r *MemoryRepository) GetObjectives(ctx context.Context, gameID string) ([]*models.Objective, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetObjectives")
+
+	var objectives []*models.Objective
+	for _, objective := range r.objectives {
+		if objective.GameID == gameID {
+			copied := *objective
+			objectives = append(objectives, &copied)
+		}
+	}
+	sort.Slice(objectives, func(i, j int) bool { return objectives[i].ObjectiveID < objectives[j].ObjectiveID })
+	return objectives, nil
+}
+
+// SaveObjective inserts or replaces an objective
+func (r *MemoryRepository) SaveObjective(ctx context.Context, objective *models.Objective) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveObjective")
+
+	copied := *objective
+	r.objectives[objective.GameID+"/"+objective.ObjectiveID] = &copied
+	return nil
+}
+
+// RecordGameResult folds a user's game result into their statistics, once per game
+func (r *MemoryRepository) RecordGameResult(ctx context.Context, result *models.GameResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("RecordGameResult")
+
+	stats, ok := r.userStats[result.UserID]
+	if !ok {
+		stats = &models.UserStats{UserID: result.UserID}
+		r.userStats[result.UserID] = stats
+	}
+	for _, gameID := range stats.GameIDs {
+		if gameID == result.GameID {
+			return nil
+		}
+	}
+	stats.GamesPlayed++
+	stats.GameIDs = append(stats.GameIDs, result.GameID)
+	if result.VictoryType != "" {
+		if stats.Wins == nil {
+			stats.Wins = make(map[string]int)
+		}
+		stats.Wins[result.VictoryType]++
+	}
+	if result.FireMasteryYears > 0 && (stats.FastestFireMastery == 0 || result.FireMasteryYears < stats.FastestFireMastery) {
+		stats.FastestFireMastery = result.FireMasteryYears
+	}
+	stats.LastUpdated = time.Now()
+	return nil
+}
+
+// CreateEvent records a game event
+func (r *MemoryRepository) CreateEvent(ctx context.Context, event *models.GameEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("CreateEvent")
+
+	copied := *event
+	r.events = append(r.events, &copied)
+	return nil
+}
+
+// GetEvents returns a game's events, oldest first
+func (r *MemoryRepository) GetEvents(ctx context.Context, gameID string) ([]*models.GameEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetEvents")
+
+	var events []*models.GameEvent
+	for _, event := range r.events {
+		if event.GameID == gameID {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+// GetGameHistory returns the standings sampled over a game, oldest first
+func (r *MemoryRepository) GetGameHistory(ctx context.Context, gameID string) ([]*models.GameHistoryPoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetGameHistory")
+
+	var history []*models.GameHistoryPoint
+	for _, point := range r.gameHistory {
+		if point.GameID == gameID {
+			copied := *point
+			copied.Players = append([]models.PlayerStanding(nil), point.Players...)
+			history = append(history, &copied)
+		}
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Year < history[j].Year })
+	return history, nil
+}
+
+// SaveGameHistory inserts or replaces a game's standings for a year
+func (r *MemoryRepository) SaveGameHistory(ctx context.Context, point *models.GameHistoryPoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveGameHistory")
+
+	copied := *point
+	copied.Players = append([]models.PlayerStanding(nil), point.Players...)
+	for i, existing := range r.gameHistory {
+		if existing.GameID == point.GameID && existing.Year == point.Year {
+			r.gameHistory[i] = &copied
+			return nil
+		}
+	}
+	r.gameHistory = append(r.gameHistory, &copied)
+	return nil
+}
+
+// GetGameSummary retrieves the summary written when a game finished
+func (r *MemoryRepository) GetGameSummary(ctx context.Context, gameID string) (*models.GameSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetGameSummary")
+
+	summary, ok := r.gameSummaries[gameID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *summary
+	return &copied, nil
+}
+
+// SaveGameSummary inserts or replaces a finished game's summary
+func (r *MemoryRepository) SaveGameSummary(ctx context.Context, summary *models.GameSummary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveGameSummary")
+
+	copied := *summary
+	r.gameSummaries[summary.GameID] = &copied
+	return nil
+}
+
+// SaveBalanceReport stores a balance report
+func (r *MemoryRepository) SaveBalanceReport(ctx context.Context, report *models.BalanceReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("SaveBalanceReport")
+
+	copied := *report
+	r.balanceReports = append(r.balanceReports, &copied)
+	return nil
+}
+
+// GetLatestBalanceReport returns the most recently generated balance report
+func (r *MemoryRepository) GetLatestBalanceReport(ctx context.Context) (*models.BalanceReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetLatestBalanceReport")
+
+	var latest *models.BalanceReport
+	for _, report := range r.balanceReports {
+		if latest == nil || !report.GeneratedAt.Before(latest.GeneratedAt) {
+			latest = report
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+// GetMapTile retrieves a specific tile by coordinates
+func (r *MemoryRepository) GetMapTile(ctx context.Context, gameID string, x int, y int) (*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetMapTile")
+
+	tile := r.findTile(gameID, x, y)
+	if tile == nil {
+		return nil, ErrNotFound
+	}
+	return cloneTile(tile), nil
+}
+
+// GetResourceTiles retrieves tiles with tracked resource quantities
+func (r *MemoryRepository) GetResourceTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetResourceTiles")
+
+	var tiles []*models.MapTile
+	for _, tile := range r.mapTiles[gameID] {
+		if tile.ResourceQuantities != nil {
+			tiles = append(tiles, cloneTile(tile))
+		}
+	}
+	return tiles, nil
+}
+
+// UpdateTileResources persists a tile's resources, remaining quantities and last-modified tick
+func (r *MemoryRepository) UpdateTileResources(ctx context.Context, tile *models.MapTile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateTileResources")
+
+	stored := r.findTile(tile.GameID, tile.X, tile.Y)
+	if stored == nil {
+		return ErrNotFound
+	}
+	copied := cloneTile(tile)
+	stored.Resources = copied.Resources
+	stored.ResourceQuantities = copied.ResourceQuantities
+	stored.LastModifiedTick = tile.LastModifiedTick
+	return nil
+}
+
+// GetRiverTiles retrieves tiles on a river system
+func (r *MemoryRepository) GetRiverTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetRiverTiles")
+
+	var tiles []*models.MapTile
+	for _, tile := range r.mapTiles[gameID] {
+		if tile.RiverID > 0 {
+			tiles = append(tiles, cloneTile(tile))
+		}
+	}
+	return tiles, nil
+}
+
+// GetSoilTiles retrieves farmed tiles and tiles whose soil is still recovering
+func (r *MemoryRepository) GetSoilTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetSoilTiles")
+
+	var tiles []*models.MapTile
+	for _, tile := range r.mapTiles[gameID] {
+		if tile.NaturalFertility > 0 && (containsString(tile.Improvements, models.ImprovementFarm) || tile.Fertility < tile.NaturalFertility) {
+			tiles = append(tiles, cloneTile(tile))
+		}
+	}
+	return tiles, nil
+}
+
+// UpdateTileFertility persists a tile's soil fertility and last-modified tick
+func (r *MemoryRepository) UpdateTileFertility(ctx context.Context, tile *models.MapTile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateTileFertility")
+
+	stored := r.findTile(tile.GameID, tile.X, tile.Y)
+	if stored == nil {
+		return ErrNotFound
+	}
+	stored.Fertility = tile.Fertility
+	stored.LastModifiedTick = tile.LastModifiedTick
+	return nil
+}
+
+// GetPollutedTiles retrieves tiles with any pollution
+func (r *MemoryRepository) GetPollutedTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetPollutedTiles")
+
+	var tiles []*models.MapTile
+	for _, tile := range r.mapTiles[gameID] {
+		if tile.Pollution > 0 {
+			tiles = append(tiles, cloneTile(tile))
+		}
+	}
+	return tiles, nil
+}
+
+// UpdateTilePollution persists a tile's pollution and last-modified tick
+func (r *MemoryRepository) UpdateTilePollution(ctx context.Context, tile *models.MapTile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateTilePollution")
+
+	stored := r.findTile(tile.GameID, tile.X, tile.Y)
+	if stored == nil {
+		return ErrNotFound
+	}
+	stored.Pollution = tile.Pollution
+	stored.LastModifiedTick = tile.LastModifiedTick
+	return nil
+}
+
+// UpdateTileTerrain persists a tile's terrain, resources, soil and
+// last-modified tick after the land itself has changed
+func (r *MemoryRepository) UpdateTileTerrain(ctx context.Context, tile *models.MapTile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateTileTerrain")
+
+	stored := r.findTile(tile.GameID, tile.X, tile.Y)
+	if stored == nil {
+		return ErrNotFound
+	}
+	copied := cloneTile(tile)
+	stored.TerrainType = copied.TerrainType
+	stored.Resources = copied.Resources
+	stored.ResourceQuantities = copied.ResourceQuantities
+	stored.Fertility = copied.Fertility
+	stored.NaturalFertility = copied.NaturalFertility
+	stored.LastModifiedTick = copied.LastModifiedTick
+	return nil
+}
+
+// AddTileImprovement adds an improvement to a tile, stamping it modified at tick
+func (r *MemoryRepository) AddTileImprovement(ctx context.Context, gameID string, x int, y int, improvement string, tick int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("AddTileImprovement")
+
+	tile := r.findTile(gameID, x, y)
+	if tile == nil {
+		return ErrNotFound
+	}
+	if !containsString(tile.Improvements, improvement) {
+		tile.Improvements = append(tile.Improvements, improvement)
+	}
+	tile.LastModifiedTick = tick
+	return nil
+}
+
+// UpdateTileImprovements persists a tile's improvements, damaged
+// improvements and last-modified tick
+func (r *MemoryRepository) UpdateTileImprovements(ctx context.Context, tile *models.MapTile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateTileImprovements")
+
+	stored := r.findTile(tile.GameID, tile.X, tile.Y)
+	if stored == nil {
+		return ErrNotFound
+	}
+	copied := cloneTile(tile)
+	stored.Improvements = copied.Improvements
+	stored.Damaged = copied.Damaged
+	stored.LastModifiedTick = copied.LastModifiedTick
+	return nil
+}
+
+// GetTilesWithImprovement retrieves tiles carrying the given improvement
+func (r *MemoryRepository) GetTilesWithImprovement(ctx context.Context, gameID string, improvement string) ([]*models.MapTile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetTilesWithImprovement")
+
+	var tiles []*models.MapTile
+	for _, tile := range r.mapTiles[gameID] {
+		if containsString(tile.Improvements, improvement) {
+			tiles = append(tiles, cloneTile(tile))
+		}
+	}
+	return tiles, nil
+}
+
+// CreateOrder queues a player order
+func (r *MemoryRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("CreateOrder")
+
+	copied := *order
+	r.orders = append(r.orders, &copied)
+	return nil
+}
+
+// GetPendingOrders retrieves a game's pending orders in submission order
+func (r *MemoryRepository) GetPendingOrders(ctx context.Context, gameID string) ([]*models.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("GetPendingOrders")
+
+	var orders []*models.Order
+	for _, order := range r.orders {
+		if order.GameID == gameID && order.Status == models.OrderStatusPending {
+			copied := *order
+			orders = append(orders, &copied)
+		}
+	}
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+	return orders, nil
+}
+
+// UpdateOrder updates an order's status
+func (r *MemoryRepository) UpdateOrder(ctx context.Context, order *models.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count("UpdateOrder")
+
+	for i, stored := range r.orders {
+		if stored.OrderID == order.OrderID {
+			copied := *order
+			r.orders[i] = &copied
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Close is a no-op for the in-memory repository
+func (r *MemoryRepository) Close(ctx context.Context) error {
+	return nil
+}
+
+// findTile locates a stored tile; tiles saved by the generator are row-major
+// so the index is tried first before falling back to a scan
+func (r *MemoryRepository) findTile(gameID string, x, y int) *models.MapTile {
+	tiles := r.mapTiles[gameID]
+	if metadata, ok := r.mapMetadata[gameID]; ok && x >= 0 && y >= 0 && x < metadata.Width {
+		idx := y*metadata.Width + x
+		if idx < len(tiles) && tiles[idx].X == x && tiles[idx].Y == y {
+			return tiles[idx]
+		}
+	}
+	for _, tile := range tiles {
+		if tile.X == x && tile.Y == y {
+			return tile
+		}
+	}
+	return nil
+}
+
+// cloneGame copies a game including its seed registry and ruleset
+func cloneGame(game *models.Game) *models.Game {
+	copied := *game
+	copied.PlayerList = append([]string(nil), game.PlayerList...)
+	copied.EliminatedPlayers = append([]string(nil), game.EliminatedPlayers...)
+	copied.Winners = append([]string(nil), game.Winners...)
+	copied.ClockAdjustments = append([]models.ClockAdjustment(nil), game.ClockAdjustments...)
+	if game.Schedule != nil {
+		schedule := *game.Schedule
+		copied.Schedule = &schedule
+	}
+	if game.FireMastery != nil {
+		copied.FireMastery = make(map[string]int, len(game.FireMastery))
+		for playerID, year := range game.FireMastery {
+			copied.FireMastery[playerID] = year
+		}
+	}
+	if game.Teams != nil {
+		copied.Teams = make(map[string]int, len(game.Teams))
+		for playerID, team := range game.Teams {
+			copied.Teams[playerID] = team
+		}
+	}
+	if game.Seeds != nil {
+		seeds := *game.Seeds
+		copied.Seeds = &seeds
+	}
+	if game.Rules != nil {
+		rules := *game.Rules
+		copied.Rules = &rules
+	}
+	return &copied
+}
+
+// cloneTile copies a tile including its slices
+func cloneTile(tile *models.MapTile) *models.MapTile {
+	copied := *tile
+	copied.Resources = append([]string(nil), tile.Resources...)
+	copied.Improvements = append([]string(nil), tile.Improvements...)
+	copied.Damaged = append([]string(nil), tile.Damaged...)
+	copied.VisibleTo = append([]string(nil), tile.VisibleTo...)
+	if tile.ResourceQuantities != nil {
+		copied.ResourceQuantities = make(map[string]int, len(tile.ResourceQuantities))
+		for resource, quantity := range tile.ResourceQuantities {
+			copied.ResourceQuantities[resource] = quantity
+		}
+	}
+	return &copied
+}
+
+// cloneDiplomacyState copies a diplomacy state including its agreements
+func cloneDiplomacyState(state *models.DiplomacyState) *models.DiplomacyState {
+	copied := *state
+	if state.Agreement != nil {
+		agreement := *state.Agreement
+		copied.Agreement = &agreement
+	}
+	if state.Demand != nil {
+		demand := *state.Demand
+		copied.Demand = &demand
+	}
+	return &copied
+}
+
+// cloneMinorCiv copies a minor civilization including its friendships and quest
+func cloneMinorCiv(civ *models.MinorCiv) *models.MinorCiv {
+	copied := *civ
+	copied.Friendship = make(map[string]int, len(civ.Friendship))
+	for playerID, friendship := range civ.Friendship {
+		copied.Friendship[playerID] = friendship
+	}
+	if civ.Quest != nil {
+		quest := *civ.Quest
+		copied.Quest = &quest
+	}
+	return &copied
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
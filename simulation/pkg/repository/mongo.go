@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/anicolao/simciv/simulation/pkg/models"
@@ -12,41 +14,32 @@ import (
 
 // MongoRepository implements GameRepository using MongoDB
 type MongoRepository struct {
-	client *mongo.Client
-	db     *mongo.Database
+	client       *mongo.Client
+	db           *mongo.Database
+	queryTimeout time.Duration // Deadline of each call; 0 leaves only the caller's
 }
 
-// NewMongoRepository creates a new MongoDB repository
+// NewMongoRepository creates a new MongoDB repository with the default
+// timeouts and retry policy
 func NewMongoRepository(ctx context.Context, uri string, dbName string) (*MongoRepository, error) {
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
-	if err != nil {
-		return nil, err
-	}
-
-	// Ping to verify connection
-	if err := client.Ping(ctx, nil); err != nil {
-		return nil, err
-	}
-
-	return &MongoRepository{
-		client: client,
-		db:     client.Database(dbName),
-	}, nil
+	return NewMongoRepositoryWithOptions(ctx, uri, dbName, DefaultMongoOptions())
 }
 
-// GetStartedGames returns all games in "started" state
+// GetStartedGames returns all games the engine runs: those starting or started
 func (r *MongoRepository) GetStartedGames(ctx context.Context) ([]*models.Game, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("games")
 
-	cursor, err := collection.Find(ctx, bson.M{"state": "started"})
+	cursor, err := collection.Find(ctx, bson.M{"state": bson.M{"$in": []models.GameState{models.GameStateStarting, models.GameStateActive}}})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer cursor.Close(ctx)
 
 	var games []*models.Game
 	if err := cursor.All(ctx, &games); err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return games, nil
@@ -54,6 +47,8 @@ func (r *MongoRepository) GetStartedGames(ctx context.Context) ([]*models.Game,
 
 // GetGame returns a specific game by ID
 func (r *MongoRepository) GetGame(ctx context.Context, gameID string) (*models.Game, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("games")
 
 	var game models.Game
@@ -70,42 +65,340 @@ func (r *MongoRepository) GetGame(ctx context.Context, gameID string) (*models.G
 			"gameId": bson.M{"$regex": "^" + gameID},
 		}).Decode(&game)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 		return &game, nil
 	}
 	
-	return nil, err
+	return nil, wrapError(err)
 }
 
-// UpdateGameTick updates the game's current year and last tick time
-func (r *MongoRepository) UpdateGameTick(ctx context.Context, gameID string, newYear int, tickTime context.Context) error {
+// CreateGame stores a new game document, returning ErrConflict if a game
+// with its ID already exists
+func (r *MongoRepository) CreateGame(ctx context.Context, game *models.Game) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("games")
 
-	now := time.Now()
-	_, err := collection.UpdateOne(
+	existing, err := collection.CountDocuments(ctx, bson.M{"gameId": game.GameID})
+	if err != nil {
+		return wrapError(err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("%w: game %s already exists", ErrConflict, game.GameID)
+	}
+	_, err = collection.InsertOne(ctx, game)
+	return wrapError(err)
+}
+
+// ClaimGameTick takes the tick of year; the year and any live claim are
+// matched in the filter so only one engine instance gets it
+func (r *MongoRepository) ClaimGameTick(ctx context.Context, gameID string, year int, claimedAt time.Time, lease time.Duration) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	result, err := collection.UpdateOne(
 		ctx,
-		bson.M{"gameId": gameID},
+		bson.M{
+			"gameId":      gameID,
+			"currentYear": year,
+			"$or": bson.A{
+				bson.M{"tickClaim.year": bson.M{"$ne": year}},
+				bson.M{"tickClaim.claimedAt": bson.M{"$lt": claimedAt.Add(-lease)}},
+			},
+		},
+		bson.M{"$set": bson.M{"tickClaim": models.TickClaim{Year: year, ClaimedAt: claimedAt}}},
+	)
+	if err != nil {
+		return wrapError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConcurrentTick
+	}
+
+	return nil
+}
+
+// UpdateGameTick advances the game from expectedYear to newYear, stamping
+// it with tickTime and the new year's calendar; the year is matched in the filter so a concurrent
+// advance leaves nothing to update
+func (r *MongoRepository) UpdateGameTick(ctx context.Context, gameID string, expectedYear, newYear int, tickTime time.Time) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID, "currentYear": expectedYear},
 		bson.M{
 			"$set": bson.M{
 				"currentYear": newYear,
-				"lastTickAt":  now,
+				"lastTickAt":  tickTime,
+				"calendar":    models.YearCalendar(newYear),
 			},
+			"$unset": bson.M{"tickClaim": ""},
 		},
 	)
+	if err != nil {
+		return wrapError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConcurrentTick
+	}
 
-	return err
+	return nil
+}
+
+// UpdateGameSeeds persists the game's RNG seed registry and stream positions
+func (r *MongoRepository) UpdateGameSeeds(ctx context.Context, gameID string, seeds *models.GameSeeds) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID},
+		bson.M{"$set": bson.M{"seeds": seeds}},
+	)
+
+	return wrapError(err)
+}
+
+// UpdateGameRules pins the ruleset a game is played under
+func (r *MongoRepository) UpdateGameRules(ctx context.Context, gameID string, rules *models.Ruleset) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID},
+		bson.M{"$set": bson.M{"rules": rules}},
+	)
+
+	return wrapError(err)
+}
+
+// TransitionGameState moves a game from one lifecycle state to another; the
+// current state is matched in the filter so the change is a compare-and-swap
+func (r *MongoRepository) TransitionGameState(ctx context.Context, gameID string, from, to models.GameState) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID, "state": from},
+		bson.M{"$set": bson.M{"state": to}},
+	)
+	if err != nil {
+		return wrapError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrStateConflict
+	}
+
+	return nil
+}
+
+// EndGame marks a game ended with its winners; like TransitionGameState it
+// matches the current state in the filter
+func (r *MongoRepository) EndGame(ctx context.Context, gameID string, from models.GameState, winners []string) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID, "state": from},
+		bson.M{"$set": bson.M{"state": models.GameStateFinished, "winners": winners}},
+	)
+	if err != nil {
+		return wrapError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrStateConflict
+	}
+
+	return nil
+}
+
+// RecordFireMastery notes the year a player mastered fire, keeping the earliest
+func (r *MongoRepository) RecordFireMastery(ctx context.Context, gameID string, playerID string, year int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID},
+		bson.M{"$min": bson.M{"fireMastery." + playerID: year}},
+	)
+
+	return wrapError(err)
+}
+
+// RecordClockAdjustment appends a skipped-downtime record to a game
+func (r *MongoRepository) RecordClockAdjustment(ctx context.Context, gameID string, adjustment models.ClockAdjustment) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("games")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID},
+		bson.M{"$push": bson.M{"clockAdjustments": adjustment}},
+	)
+
+	return wrapError(err)
+}
+
+// GetPlayerActivity retrieves the last-active records of a game's players
+func (r *MongoRepository) GetPlayerActivity(ctx context.Context, gameID string) ([]*models.PlayerActivity, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("playerActivity")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var activity []*models.PlayerActivity
+	if err := cursor.All(ctx, &activity); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return activity, nil
+}
+
+// SavePlayerActivity inserts or replaces a player's last-active record
+func (r *MongoRepository) SavePlayerActivity(ctx context.Context, record *models.PlayerActivity) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("playerActivity")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": record.GameID, "playerId": record.PlayerID},
+		record,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// GetPlayerPolicies retrieves the policies a game's players have set
+func (r *MongoRepository) GetPlayerPolicies(ctx context.Context, gameID string) ([]*models.PlayerPolicy, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("playerPolicies")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var policies []*models.PlayerPolicy
+	if err := cursor.All(ctx, &policies); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return policies, nil
+}
+
+// SavePlayerPolicy inserts or replaces a player's policies
+func (r *MongoRepository) SavePlayerPolicy(ctx context.Context, policy *models.PlayerPolicy) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("playerPolicies")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": policy.GameID, "playerId": policy.PlayerID},
+		policy,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// GetPlayerSettings retrieves the settings a game's players have saved
+func (r *MongoRepository) GetPlayerSettings(ctx context.Context, gameID string) ([]*models.PlayerSettings, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("playerSettings")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var settings []*models.PlayerSettings
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return settings, nil
+}
+
+// SavePlayerSettings inserts or replaces a player's settings
+func (r *MongoRepository) SavePlayerSettings(ctx context.Context, settings *models.PlayerSettings) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("playerSettings")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": settings.GameID, "playerId": settings.PlayerID},
+		settings,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// WatchGames streams changes to game documents until ctx is cancelled or
+// the stream fails; change streams require a replica set
+func (r *MongoRepository) WatchGames(ctx context.Context, onChange func(game *models.Game)) error {
+	collection := r.db.Collection("games")
+
+	stream, err := collection.Watch(ctx, mongo.Pipeline{}, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return wrapError(err)
+	}
+	defer stream.Close(ctx)
+
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument *models.Game `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			return wrapError(err)
+		}
+		onChange(change.FullDocument)
+	}
+
+	return stream.Err()
 }
 
 // SaveMapMetadata saves map generation metadata
 func (r *MongoRepository) SaveMapMetadata(ctx context.Context, metadata *models.MapMetadata) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("mapMetadata")
 	_, err := collection.InsertOne(ctx, metadata)
-	return err
+	return wrapError(err)
 }
 
 // SaveMapTiles saves map tiles in batch
 func (r *MongoRepository) SaveMapTiles(ctx context.Context, tiles []*models.MapTile) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	if len(tiles) == 0 {
 		return nil
 	}
@@ -127,7 +420,7 @@ func (r *MongoRepository) SaveMapTiles(ctx context.Context, tiles []*models.MapT
 		}
 		_, err := collection.InsertMany(ctx, docs[i:end])
 		if err != nil {
-			return err
+			return wrapError(err)
 		}
 	}
 
@@ -136,6 +429,8 @@ func (r *MongoRepository) SaveMapTiles(ctx context.Context, tiles []*models.MapT
 
 // SaveStartingPositions saves player starting positions
 func (r *MongoRepository) SaveStartingPositions(ctx context.Context, positions []*models.StartingPosition) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	if len(positions) == 0 {
 		return nil
 	}
@@ -148,17 +443,47 @@ func (r *MongoRepository) SaveStartingPositions(ctx context.Context, positions [
 	}
 
 	_, err := collection.InsertMany(ctx, docs)
-	return err
+	return wrapError(err)
+}
+
+// RevealTiles adds a player to the visibleTo list of the given tiles,
+// stamping those newly revealed with the tick
+func (r *MongoRepository) RevealTiles(ctx context.Context, gameID string, playerID string, locations []models.Location, tick int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	if len(locations) == 0 {
+		return nil
+	}
+
+	collection := r.db.Collection("mapTiles")
+
+	coords := make(bson.A, 0, len(locations))
+	for _, loc := range locations {
+		coords = append(coords, bson.M{"x": loc.X, "y": loc.Y})
+	}
+
+	_, err := collection.UpdateMany(
+		ctx,
+		bson.M{"gameId": gameID, "visibleTo": bson.M{"$ne": playerID}, "$or": coords},
+		bson.M{
+			"$addToSet": bson.M{"visibleTo": playerID},
+			"$set":      bson.M{"lastModifiedTick": tick},
+		},
+	)
+
+	return wrapError(err)
 }
 
 // GetMapMetadata retrieves map metadata for a game
 func (r *MongoRepository) GetMapMetadata(ctx context.Context, gameID string) (*models.MapMetadata, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("mapMetadata")
 
 	var metadata models.MapMetadata
 	err := collection.FindOne(ctx, bson.M{"gameId": gameID}).Decode(&metadata)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return &metadata, nil
@@ -166,30 +491,137 @@ func (r *MongoRepository) GetMapMetadata(ctx context.Context, gameID string) (*m
 
 // GetMapTiles retrieves map tiles for a game (with optional player visibility filtering)
 func (r *MongoRepository) GetMapTiles(ctx context.Context, gameID string, playerID *string) ([]*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("mapTiles")
 
 	filter := bson.M{"gameId": gameID}
 	if playerID != nil {
-		// Filter by visible tiles only
-		filter["visibleTo"] = *playerID
+		// Filter by visible tiles only; owned tiles are always visible
+		filter["$or"] = bson.A{
+			bson.M{"visibleTo": *playerID},
+			bson.M{"ownerId": *playerID},
+		}
 	}
 
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer cursor.Close(ctx)
 
 	var tiles []*models.MapTile
 	if err := cursor.All(ctx, &tiles); err != nil {
-		return nil, err
+		return nil, wrapError(err)
+	}
+
+	return tiles, nil
+}
+
+// GetSeenTiles retrieves tiles currently visible to or owned by any player
+func (r *MongoRepository) GetSeenTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"gameId": gameID,
+		"$or": bson.A{
+			bson.M{"visibleTo.0": bson.M{"$exists": true}},
+			bson.M{"ownerId": bson.M{"$ne": nil}},
+		},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var tiles []*models.MapTile
+	if err := cursor.All(ctx, &tiles); err != nil {
+		return nil, wrapError(err)
 	}
 
 	return tiles, nil
 }
 
+// GetExploredTiles retrieves every player's last-seen tile records for a game
+func (r *MongoRepository) GetExploredTiles(ctx context.Context, gameID string) ([]*models.ExploredTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("exploredTiles")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var tiles []*models.ExploredTile
+	if err := cursor.All(ctx, &tiles); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return tiles, nil
+}
+
+// SaveExploredTiles inserts or replaces last-seen tile records
+func (r *MongoRepository) SaveExploredTiles(ctx context.Context, tiles []*models.ExploredTile) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	if len(tiles) == 0 {
+		return nil
+	}
+
+	collection := r.db.Collection("exploredTiles")
+
+	writes := make([]mongo.WriteModel, len(tiles))
+	for i, tile := range tiles {
+		writes[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"gameId": tile.GameID, "playerId": tile.PlayerID, "x": tile.X, "y": tile.Y}).
+			SetReplacement(tile).
+			SetUpsert(true)
+	}
+
+	_, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return wrapError(err)
+}
+
+// SaveMinimap inserts or replaces a player's minimap
+func (r *MongoRepository) SaveMinimap(ctx context.Context, minimap *models.Minimap) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("minimaps")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": minimap.GameID, "playerId": minimap.PlayerID},
+		minimap,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// SaveBorders inserts or replaces the territory borders a player knows of
+func (r *MongoRepository) SaveBorders(ctx context.Context, borders *models.Borders) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("borders")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": borders.GameID, "playerId": borders.PlayerID},
+		borders,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
 // GetStartingPosition retrieves a player's starting position
 func (r *MongoRepository) GetStartingPosition(ctx context.Context, gameID string, playerID string) (*models.StartingPosition, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("startingPositions")
 
 	var position models.StartingPosition
@@ -197,8 +629,11 @@ func (r *MongoRepository) GetStartingPosition(ctx context.Context, gameID string
 		"gameId":   gameID,
 		"playerId": playerID,
 	}).Decode(&position)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return &position, nil
@@ -206,24 +641,28 @@ func (r *MongoRepository) GetStartingPosition(ctx context.Context, gameID string
 
 // CreateUnit creates a new unit
 func (r *MongoRepository) CreateUnit(ctx context.Context, unit *models.Unit) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("units")
 	_, err := collection.InsertOne(ctx, unit)
-	return err
+	return wrapError(err)
 }
 
 // GetUnits retrieves units for a game
 func (r *MongoRepository) GetUnits(ctx context.Context, gameID string) ([]*models.Unit, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("units")
 
 	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer cursor.Close(ctx)
 
 	var units []*models.Unit
 	if err := cursor.All(ctx, &units); err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return units, nil
@@ -231,6 +670,8 @@ func (r *MongoRepository) GetUnits(ctx context.Context, gameID string) ([]*model
 
 // GetUnitsByPlayer retrieves units for a specific player
 func (r *MongoRepository) GetUnitsByPlayer(ctx context.Context, gameID string, playerID string) ([]*models.Unit, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("units")
 
 	cursor, err := collection.Find(ctx, bson.M{
@@ -238,13 +679,13 @@ func (r *MongoRepository) GetUnitsByPlayer(ctx context.Context, gameID string, p
 		"playerId": playerID,
 	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer cursor.Close(ctx)
 
 	var units []*models.Unit
 	if err := cursor.All(ctx, &units); err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return units, nil
@@ -252,6 +693,8 @@ func (r *MongoRepository) GetUnitsByPlayer(ctx context.Context, gameID string, p
 
 // UpdateUnit updates a unit
 func (r *MongoRepository) UpdateUnit(ctx context.Context, unit *models.Unit) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("units")
 
 	_, err := collection.UpdateOne(
@@ -260,36 +703,42 @@ func (r *MongoRepository) UpdateUnit(ctx context.Context, unit *models.Unit) err
 		bson.M{"$set": unit},
 	)
 
-	return err
+	return wrapError(err)
 }
 
 // DeleteUnit deletes a unit
 func (r *MongoRepository) DeleteUnit(ctx context.Context, unitID string) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("units")
 	_, err := collection.DeleteOne(ctx, bson.M{"unitId": unitID})
-	return err
+	return wrapError(err)
 }
 
 // CreateSettlement creates a new settlement
 func (r *MongoRepository) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("settlements")
 	_, err := collection.InsertOne(ctx, settlement)
-	return err
+	return wrapError(err)
 }
 
 // GetSettlements retrieves settlements for a game
 func (r *MongoRepository) GetSettlements(ctx context.Context, gameID string) ([]*models.Settlement, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("settlements")
 
 	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer cursor.Close(ctx)
 
 	var settlements []*models.Settlement
 	if err := cursor.All(ctx, &settlements); err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return settlements, nil
@@ -297,6 +746,8 @@ func (r *MongoRepository) GetSettlements(ctx context.Context, gameID string) ([]
 
 // GetSettlementsByPlayer retrieves settlements for a specific player
 func (r *MongoRepository) GetSettlementsByPlayer(ctx context.Context, gameID string, playerID string) ([]*models.Settlement, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("settlements")
 
 	cursor, err := collection.Find(ctx, bson.M{
@@ -304,13 +755,13 @@ func (r *MongoRepository) GetSettlementsByPlayer(ctx context.Context, gameID str
 		"playerId": playerID,
 	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer cursor.Close(ctx)
 
 	var settlements []*models.Settlement
 	if err := cursor.All(ctx, &settlements); err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return settlements, nil
@@ -318,6 +769,8 @@ func (r *MongoRepository) GetSettlementsByPlayer(ctx context.Context, gameID str
 
 // UpdateSettlement updates a settlement
 func (r *MongoRepository) UpdateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 	collection := r.db.Collection("settlements")
 
 	_, err := collection.UpdateOne(
@@ -326,30 +779,879 @@ func (r *MongoRepository) UpdateSettlement(ctx context.Context, settlement *mode
 		bson.M{"$set": settlement},
 	)
 
-	return err
+	return wrapError(err)
 }
 
-// GetMapTile retrieves a specific tile by coordinates
-func (r *MongoRepository) GetMapTile(ctx context.Context, gameID string, x int, y int) (*models.MapTile, error) {
-	collection := r.db.Collection("mapTiles")
+// GetSettlementSimulation retrieves a settlement's saved human simulation
+func (r *MongoRepository) GetSettlementSimulation(ctx context.Context, settlementID string) (*models.SettlementSimulation, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("settlementSimulations")
 
-	var tile models.MapTile
-	err := collection.FindOne(ctx, bson.M{
-		"gameId": gameID,
-		"x":      x,
-		"y":      y,
-	}).Decode(&tile)
+	var saved models.SettlementSimulation
+	err := collection.FindOne(ctx, bson.M{"settlementId": settlementID}).Decode(&saved)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
-	return &tile, nil
+	return &saved, nil
 }
 
-// Close closes the MongoDB connection
-func (r *MongoRepository) Close(ctx context.Context) error {
-	if r.client != nil {
-		return r.client.Disconnect(ctx)
+// SaveSettlementSimulation inserts or replaces a settlement's saved human simulation
+func (r *MongoRepository) SaveSettlementSimulation(ctx context.Context, saved *models.SettlementSimulation) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("settlementSimulations")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"settlementId": saved.SettlementID},
+		saved,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// AssignTiles assigns unowned (or already player-owned) tiles at the given
+// locations to a settlement and its player
+func (r *MongoRepository) AssignTiles(ctx context.Context, gameID string, settlementID string, playerID string, locations []models.Location, tick int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	if len(locations) == 0 {
+		return nil
+	}
+
+	collection := r.db.Collection("mapTiles")
+
+	coords := make(bson.A, 0, len(locations))
+	for _, loc := range locations {
+		coords = append(coords, bson.M{"x": loc.X, "y": loc.Y})
+	}
+
+	_, err := collection.UpdateMany(
+		ctx,
+		bson.M{
+			"gameId": gameID,
+			"$and": bson.A{
+				bson.M{"$or": coords},
+				bson.M{"$or": bson.A{bson.M{"ownerId": nil}, bson.M{"ownerId": playerID}}},
+			},
+		},
+		bson.M{"$set": bson.M{"ownerId": playerID, "settlementId": settlementID, "lastModifiedTick": tick}},
+	)
+
+	return wrapError(err)
+}
+
+// MergeSettlements atomically saves the surviving settlement, re-parents the
+// absorbed settlement's suburbs to it, and either saves the absorbed
+// settlement as its suburb or reassigns its tiles and deletes it
+func (r *MongoRepository) MergeSettlements(ctx context.Context, survivor *models.Settlement, absorbed *models.Settlement, asSuburb bool, tick int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	session, err := r.client.StartSession()
+	if err != nil {
+		return wrapError(err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		settlements := r.db.Collection("settlements")
+		if err := saveSettlement(sc, settlements, survivor); err != nil {
+			return nil, err
+		}
+
+		if _, err := settlements.UpdateMany(sc,
+			bson.M{"gameId": absorbed.GameID, "parentId": absorbed.SettlementID, "settlementId": bson.M{"$ne": survivor.SettlementID}},
+			bson.M{"$set": bson.M{"parentId": survivor.SettlementID}},
+		); err != nil {
+			return nil, err
+		}
+
+		if asSuburb {
+			return nil, saveSettlement(sc, settlements, absorbed)
+		}
+
+		if _, err := r.db.Collection("mapTiles").UpdateMany(sc,
+			bson.M{"gameId": absorbed.GameID, "settlementId": absorbed.SettlementID},
+			bson.M{"$set": bson.M{"settlementId": survivor.SettlementID, "lastModifiedTick": tick}},
+		); err != nil {
+			return nil, err
+		}
+
+		_, err := settlements.DeleteOne(sc, bson.M{"settlementId": absorbed.SettlementID})
+		return nil, err
+	})
+
+	return wrapError(err)
+}
+
+// saveSettlement replaces a settlement's fields, removing its parent when it
+// has none: the parent is left out of the document when empty
+func saveSettlement(ctx context.Context, settlements *mongo.Collection, settlement *models.Settlement) error {
+	update := bson.M{"$set": settlement}
+	if settlement.ParentID == "" {
+		update["$unset"] = bson.M{"parentId": ""}
+	}
+	_, err := settlements.UpdateOne(ctx, bson.M{"settlementId": settlement.SettlementID}, update)
+	return err
+}
+
+// EliminatePlayer marks a player eliminated, releases their tiles and
+// visibility, disbands their units and hands their settlements to the
+// neutral player, all in one transaction
+func (r *MongoRepository) EliminatePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	session, err := r.client.StartSession()
+	if err != nil {
+		return wrapError(err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := r.db.Collection("games").UpdateOne(sc,
+			bson.M{"gameId": gameID},
+			bson.M{"$addToSet": bson.M{"eliminatedPlayers": playerID}},
+		); err != nil {
+			return nil, err
+		}
+
+		tiles := r.db.Collection("mapTiles")
+		if _, err := tiles.UpdateMany(sc,
+			bson.M{"gameId": gameID, "ownerId": playerID},
+			bson.M{
+				"$set":   bson.M{"ownerId": nil, "lastModifiedTick": tick},
+				"$unset": bson.M{"settlementId": ""},
+			},
+		); err != nil {
+			return nil, err
+		}
+		if _, err := tiles.UpdateMany(sc,
+			bson.M{"gameId": gameID, "visibleTo": playerID},
+			bson.M{"$pull": bson.M{"visibleTo": playerID}},
+		); err != nil {
+			return nil, err
+		}
+
+		if _, err := r.db.Collection("units").DeleteMany(sc, bson.M{"gameId": gameID, "playerId": playerID}); err != nil {
+			return nil, err
+		}
+
+		_, err := r.db.Collection("settlements").UpdateMany(sc,
+			bson.M{"gameId": gameID, "playerId": playerID},
+			bson.M{"$set": bson.M{"playerId": models.NeutralPlayerID}},
+		)
+		return nil, err
+	})
+
+	return wrapError(err)
+}
+
+// ReleasePlayer removes a player from a game as if they had never joined
+func (r *MongoRepository) ReleasePlayer(ctx context.Context, gameID string, playerID string, tick int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	session, err := r.client.StartSession()
+	if err != nil {
+		return wrapError(err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := r.db.Collection("games").UpdateOne(sc,
+			bson.M{"gameId": gameID, "playerList": playerID},
+			bson.M{
+				"$pull":  bson.M{"playerList": playerID},
+				"$inc":   bson.M{"currentPlayers": -1},
+				"$unset": bson.M{"teams." + playerID: ""},
+			},
+		); err != nil {
+			return nil, err
+		}
+
+		tiles := r.db.Collection("mapTiles")
+		if _, err := tiles.UpdateMany(sc,
+			bson.M{"gameId": gameID, "ownerId": playerID},
+			bson.M{
+				"$set":   bson.M{"ownerId": nil, "lastModifiedTick": tick},
+				"$unset": bson.M{"settlementId": ""},
+			},
+		); err != nil {
+			return nil, err
+		}
+		if _, err := tiles.UpdateMany(sc,
+			bson.M{"gameId": gameID, "visibleTo": playerID},
+			bson.M{"$pull": bson.M{"visibleTo": playerID}},
+		); err != nil {
+			return nil, err
+		}
+
+		// Saved simulations are keyed by settlement, so go before their settlements
+		filter := bson.M{"gameId": gameID, "playerId": playerID}
+		settlementIDs, err := r.db.Collection("settlements").Distinct(sc, "settlementId", filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(settlementIDs) > 0 {
+			if _, err := r.db.Collection("settlementSimulations").DeleteMany(sc,
+				bson.M{"settlementId": bson.M{"$in": settlementIDs}},
+			); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, collection := range []string{"units", "settlements", "startingPositions", "exploredTiles", "minimaps", "borders"} {
+			if _, err := r.db.Collection(collection).DeleteMany(sc, filter); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	return wrapError(err)
+}
+
+// GetDiplomacyStates retrieves the standing of every pair of players in a
+// game that has had dealings
+func (r *MongoRepository) GetDiplomacyStates(ctx context.Context, gameID string) ([]*models.DiplomacyState, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("diplomacy")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID},
+		options.Find().SetSort(bson.D{{Key: "playerA", Value: 1}, {Key: "playerB", Value: 1}}))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var states []*models.DiplomacyState
+	if err := cursor.All(ctx, &states); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return states, nil
+}
+
+// SaveDiplomacyState inserts or replaces the standing between a pair of players
+func (r *MongoRepository) SaveDiplomacyState(ctx context.Context, state *models.DiplomacyState) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("diplomacy")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": state.GameID, "playerA": state.PlayerA, "playerB": state.PlayerB},
+		state,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// GetMinorCivs retrieves a game's minor civilizations, ordered by ID
+func (r *MongoRepository) GetMinorCivs(ctx context.Context, gameID string) ([]*models.MinorCiv, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("minorCivs")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID},
+		options.Find().SetSort(bson.D{{Key: "minorCivId", Value: 1}}))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var civs []*models.MinorCiv
+	if err := cursor.All(ctx, &civs); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return civs, nil
+}
+
+// SaveMinorCiv inserts or replaces a minor civilization
+func (r *MongoRepository) SaveMinorCiv(ctx context.Context, civ *models.MinorCiv) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("minorCivs")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": civ.GameID, "minorCivId": civ.MinorCivID},
+		civ,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// CaptureSettlement hands a settlement and the tiles it owns to another
+// player, stamping the tiles modified at tick
+func (r *MongoRepository) CaptureSettlement(ctx context.Context, settlement *models.Settlement, playerID string, tick int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	session, err := r.client.StartSession()
+	if err != nil {
+		return wrapError(err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := r.db.Collection("settlements").UpdateOne(sc,
+			bson.M{"settlementId": settlement.SettlementID},
+			bson.M{"$set": bson.M{"playerId": playerID}},
+		); err != nil {
+			return nil, err
+		}
+		_, err := r.db.Collection("mapTiles").UpdateMany(sc,
+			bson.M{"gameId": settlement.GameID, "settlementId": settlement.SettlementID},
+			bson.M{"$set": bson.M{"ownerId": playerID, "lastModifiedTick": tick}},
+		)
+		return nil, err
+	})
+
+	return wrapError(err)
+}
+
+// GetObjectives retrieves every objective set in a game, ordered by ID
+func (r *MongoRepository) GetObjectives(ctx context.Context, gameID string) ([]*models.Objective, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("objectives")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID},
+		options.Find().SetSort(bson.D{{Key: "objectiveId", Value: 1}}))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var objectives []*models.Objective
+	if err := cursor.All(ctx, &objectives); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return objectives, nil
+}
+
+// SaveObjective inserts or replaces an objective
+func (r *MongoRepository) SaveObjective(ctx context.Context, objective *models.Objective) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("objectives")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": objective.GameID, "objectiveId": objective.ObjectiveID},
+		objective,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// RecordGameResult folds a user's game result into their statistics. The
+// filter only matches stats that have not yet counted the game, so a repeat
+// falls through to an upsert that collides with the existing user and is
+// ignored.
+func (r *MongoRepository) RecordGameResult(ctx context.Context, result *models.GameResult) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("userStats")
+
+	inc := bson.M{"gamesPlayed": 1}
+	if result.VictoryType != "" {
+		inc["wins."+result.VictoryType] = 1
+	}
+	update := bson.M{
+		"$inc":  inc,
+		"$push": bson.M{"gameIds": result.GameID},
+		"$set":  bson.M{"lastUpdated": time.Now()},
+	}
+	if result.FireMasteryYears > 0 {
+		update["$min"] = bson.M{"fastestFireMastery": result.FireMasteryYears}
+	}
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"userId": result.UserID, "gameIds": bson.M{"$ne": result.GameID}},
+		update,
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+
+	return wrapError(err)
+}
+
+// CreateEvent records a game event
+func (r *MongoRepository) CreateEvent(ctx context.Context, event *models.GameEvent) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	_, err := r.db.Collection("gameEvents").InsertOne(ctx, event)
+	return wrapError(err)
+}
+
+// GetEvents returns a game's events, oldest first
+func (r *MongoRepository) GetEvents(ctx context.Context, gameID string) ([]*models.GameEvent, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("gameEvents")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID},
+		options.Find().SetSort(bson.D{{Key: "year", Value: 1}, {Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []*models.GameEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return events, nil
+}
+
+// GetGameHistory returns the standings sampled over a game, oldest first
+func (r *MongoRepository) GetGameHistory(ctx context.Context, gameID string) ([]*models.GameHistoryPoint, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("gameHistory")
+
+	cursor, err := collection.Find(ctx, bson.M{"gameId": gameID},
+		options.Find().SetSort(bson.D{{Key: "year", Value: 1}}))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var history []*models.GameHistoryPoint
+	if err := cursor.All(ctx, &history); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return history, nil
+}
+
+// SaveGameHistory inserts or replaces a game's standings for a year
+func (r *MongoRepository) SaveGameHistory(ctx context.Context, point *models.GameHistoryPoint) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("gameHistory")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": point.GameID, "year": point.Year},
+		point,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// GetGameSummary retrieves the summary written when a game finished
+func (r *MongoRepository) GetGameSummary(ctx context.Context, gameID string) (*models.GameSummary, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("gameSummaries")
+
+	var summary models.GameSummary
+	err := collection.FindOne(ctx, bson.M{"gameId": gameID}).Decode(&summary)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &summary, nil
+}
+
+// SaveGameSummary inserts or replaces a finished game's summary
+func (r *MongoRepository) SaveGameSummary(ctx context.Context, summary *models.GameSummary) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("gameSummaries")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"gameId": summary.GameID},
+		summary,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// SaveBalanceReport stores a balance report
+func (r *MongoRepository) SaveBalanceReport(ctx context.Context, report *models.BalanceReport) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("balanceReports")
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"reportId": report.ReportID},
+		report,
+		options.Replace().SetUpsert(true),
+	)
+
+	return wrapError(err)
+}
+
+// GetLatestBalanceReport returns the most recently generated balance report
+func (r *MongoRepository) GetLatestBalanceReport(ctx context.Context) (*models.BalanceReport, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("balanceReports")
+
+	var report models.BalanceReport
+	err := collection.FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.D{{Key: "generatedAt", Value: -1}})).Decode(&report)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &report, nil
+}
+
+// GetMapTile retrieves a specific tile by coordinates
+func (r *MongoRepository) GetMapTile(ctx context.Context, gameID string, x int, y int) (*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	var tile models.MapTile
+	err := collection.FindOne(ctx, bson.M{
+		"gameId": gameID,
+		"x":      x,
+		"y":      y,
+	}).Decode(&tile)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &tile, nil
+}
+
+// GetResourceTiles retrieves tiles with tracked resource quantities
+func (r *MongoRepository) GetResourceTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"gameId":             gameID,
+		"resourceQuantities": bson.M{"$exists": true},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var tiles []*models.MapTile
+	if err := cursor.All(ctx, &tiles); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return tiles, nil
+}
+
+// UpdateTileResources persists a tile's resources, remaining quantities and last-modified tick
+func (r *MongoRepository) UpdateTileResources(ctx context.Context, tile *models.MapTile) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": tile.GameID, "x": tile.X, "y": tile.Y},
+		bson.M{"$set": bson.M{
+			"resources":          tile.Resources,
+			"resourceQuantities": tile.ResourceQuantities,
+			"lastModifiedTick":   tile.LastModifiedTick,
+		}},
+	)
+
+	return wrapError(err)
+}
+
+// GetRiverTiles retrieves tiles on a river system
+func (r *MongoRepository) GetRiverTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"gameId":  gameID,
+		"riverId": bson.M{"$gt": 0},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var tiles []*models.MapTile
+	if err := cursor.All(ctx, &tiles); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return tiles, nil
+}
+
+// GetSoilTiles retrieves farmed tiles and tiles whose soil is still recovering
+func (r *MongoRepository) GetSoilTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"gameId":           gameID,
+		"naturalFertility": bson.M{"$gt": 0},
+		"$or": []bson.M{
+			{"improvements": models.ImprovementFarm},
+			{"$expr": bson.M{"$lt": []string{"$fertility", "$naturalFertility"}}},
+		},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var tiles []*models.MapTile
+	if err := cursor.All(ctx, &tiles); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return tiles, nil
+}
+
+// UpdateTileFertility persists a tile's soil fertility and last-modified tick
+func (r *MongoRepository) UpdateTileFertility(ctx context.Context, tile *models.MapTile) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": tile.GameID, "x": tile.X, "y": tile.Y},
+		bson.M{"$set": bson.M{
+			"fertility":        tile.Fertility,
+			"lastModifiedTick": tile.LastModifiedTick,
+		}},
+	)
+
+	return wrapError(err)
+}
+
+// GetPollutedTiles retrieves tiles with any pollution
+func (r *MongoRepository) GetPollutedTiles(ctx context.Context, gameID string) ([]*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"gameId":    gameID,
+		"pollution": bson.M{"$gt": 0},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var tiles []*models.MapTile
+	if err := cursor.All(ctx, &tiles); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return tiles, nil
+}
+
+// UpdateTilePollution persists a tile's pollution and last-modified tick
+func (r *MongoRepository) UpdateTilePollution(ctx context.Context, tile *models.MapTile) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": tile.GameID, "x": tile.X, "y": tile.Y},
+		bson.M{"$set": bson.M{
+			"pollution":        tile.Pollution,
+			"lastModifiedTick": tile.LastModifiedTick,
+		}},
+	)
+
+	return wrapError(err)
+}
+
+// UpdateTileTerrain persists a tile's terrain, resources, soil and
+// last-modified tick after the land itself has changed
+func (r *MongoRepository) UpdateTileTerrain(ctx context.Context, tile *models.MapTile) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": tile.GameID, "x": tile.X, "y": tile.Y},
+		bson.M{"$set": bson.M{
+			"terrainType":        tile.TerrainType,
+			"resources":          tile.Resources,
+			"resourceQuantities": tile.ResourceQuantities,
+			"fertility":          tile.Fertility,
+			"naturalFertility":   tile.NaturalFertility,
+			"lastModifiedTick":   tile.LastModifiedTick,
+		}},
+	)
+
+	return wrapError(err)
+}
+
+// AddTileImprovement adds an improvement to a tile, stamping it modified at tick
+func (r *MongoRepository) AddTileImprovement(ctx context.Context, gameID string, x int, y int, improvement string, tick int) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": gameID, "x": x, "y": y},
+		bson.M{
+			"$addToSet": bson.M{"improvements": improvement},
+			"$set":      bson.M{"lastModifiedTick": tick},
+		},
+	)
+
+	return wrapError(err)
+}
+
+// UpdateTileImprovements persists a tile's improvements, damaged
+// improvements and last-modified tick
+func (r *MongoRepository) UpdateTileImprovements(ctx context.Context, tile *models.MapTile) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"gameId": tile.GameID, "x": tile.X, "y": tile.Y},
+		bson.M{"$set": bson.M{
+			"improvements":     tile.Improvements,
+			"damaged":          tile.Damaged,
+			"lastModifiedTick": tile.LastModifiedTick,
+		}},
+	)
+
+	return wrapError(err)
+}
+
+// GetTilesWithImprovement retrieves tiles carrying the given improvement
+func (r *MongoRepository) GetTilesWithImprovement(ctx context.Context, gameID string, improvement string) ([]*models.MapTile, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("mapTiles")
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"gameId":       gameID,
+		"improvements": improvement,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var tiles []*models.MapTile
+	if err := cursor.All(ctx, &tiles); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return tiles, nil
+}
+
+// CreateOrder queues a player order
+func (r *MongoRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("orders")
+	_, err := collection.InsertOne(ctx, order)
+	return wrapError(err)
+}
+
+// GetPendingOrders retrieves a game's pending orders in submission order
+func (r *MongoRepository) GetPendingOrders(ctx context.Context, gameID string) ([]*models.Order, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("orders")
+
+	cursor, err := collection.Find(ctx,
+		bson.M{"gameId": gameID, "status": models.OrderStatusPending},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}),
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*models.Order
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return orders, nil
+}
+
+// UpdateOrder updates an order's status
+func (r *MongoRepository) UpdateOrder(ctx context.Context, order *models.Order) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	collection := r.db.Collection("orders")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"orderId": order.OrderID},
+		bson.M{"$set": bson.M{
+			"status":      order.Status,
+			"reason":      order.Reason,
+			"path":        order.Path,
+			"processedAt": order.ProcessedAt,
+		}},
+	)
+
+	return wrapError(err)
+}
+
+// Close closes the MongoDB connection
+func (r *MongoRepository) Close(ctx context.Context) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+	if r.client != nil {
+		return r.client.Disconnect(ctx)
 	}
 	return nil
 }
+
+// wrapError classifies a driver error as ErrNotFound, ErrConflict or
+// ErrUnavailable, keeping the driver error in the chain. Errors that fit
+// none, and errors already classified, are returned unchanged.
+func wrapError(err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrNotFound), errors.Is(err, ErrConflict), errors.Is(err, ErrUnavailable):
+		return err
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case mongo.IsTimeout(err), mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	case mongo.IsDuplicateKeyError(err), isWriteConflict(err):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
+
+// isWriteConflict reports whether a transaction was aborted by a concurrent write
+func isWriteConflict(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && (serverErr.HasErrorCode(112) || serverErr.HasErrorLabel("TransientTransactionError"))
+}
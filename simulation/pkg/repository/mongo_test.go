@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWrapError(t *testing.T) {
+	if wrapError(nil) != nil {
+		t.Error("Expected no error to stay nil")
+	}
+
+	notFound := wrapError(mongo.ErrNoDocuments)
+	if !errors.Is(notFound, ErrNotFound) || !errors.Is(notFound, mongo.ErrNoDocuments) {
+		t.Errorf("Expected ErrNotFound wrapping the driver error, got %v", notFound)
+	}
+	if unavailable := wrapError(context.DeadlineExceeded); !errors.Is(unavailable, ErrUnavailable) {
+		t.Errorf("Expected a timeout to be ErrUnavailable, got %v", unavailable)
+	}
+	duplicate := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key"}}}
+	if conflict := wrapError(duplicate); !errors.Is(conflict, ErrConflict) {
+		t.Errorf("Expected a duplicate key to be ErrConflict, got %v", conflict)
+	}
+
+	// Already classified errors and unknown ones pass through unchanged
+	if wrapError(ErrConcurrentTick) != ErrConcurrentTick || !errors.Is(ErrConcurrentTick, ErrConflict) {
+		t.Error("Expected ErrConcurrentTick to be a conflict, unchanged")
+	}
+	other := errors.New("bad query")
+	if wrapError(other) != other {
+		t.Error("Expected an unclassified error to pass through")
+	}
+}
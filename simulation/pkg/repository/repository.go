@@ -2,20 +2,95 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/anicolao/simciv/simulation/pkg/models"
 )
 
+// Errors every repository reports failures as, so callers can tell a
+// missing document from a lost race from an unreachable store. Underlying
+// driver errors are wrapped, not replaced.
+var (
+	// ErrNotFound is returned when a lookup matches no document
+	ErrNotFound = errors.New("document not found")
+	// ErrConflict is returned when a write lost a race with a concurrent change
+	ErrConflict = errors.New("write conflicted with a concurrent change")
+	// ErrUnavailable is returned when the store cannot be reached in time
+	ErrUnavailable = errors.New("store unavailable")
+)
+
+// ErrConcurrentTick is returned by ClaimGameTick and UpdateGameTick when
+// another engine instance has claimed or advanced the game's year
+var ErrConcurrentTick = fmt.Errorf("game was advanced concurrently: %w", ErrConflict)
+
+// ErrStateConflict is returned by TransitionGameState and EndGame when the
+// game is no longer in the state the transition started from
+var ErrStateConflict = fmt.Errorf("game state changed concurrently: %w", ErrConflict)
+
 // GameRepository defines the interface for game data access
 type GameRepository interface {
-	// GetStartedGames returns all games in "started" state
+	// GetStartedGames returns all games the engine runs: those starting or started
 	GetStartedGames(ctx context.Context) ([]*models.Game, error)
 
-	// GetGame returns a specific game by ID
+	// GetGame returns a specific game by ID, or ErrNotFound
 	GetGame(ctx context.Context, gameID string) (*models.Game, error)
 
-	// UpdateGameTick updates the game's current year and last tick time
-	UpdateGameTick(ctx context.Context, gameID string, newYear int, tickTime context.Context) error
+	// CreateGame stores a new game document, returning ErrConflict if a game
+	// with its ID already exists
+	CreateGame(ctx context.Context, game *models.Game) error
+
+	// ClaimGameTick takes the tick of year for the caller before it writes
+	// anything else. It returns ErrConcurrentTick, writing nothing, if the
+	// stored year is no longer year or another claim on it is younger than
+	// lease.
+	ClaimGameTick(ctx context.Context, gameID string, year int, claimedAt time.Time, lease time.Duration) error
+
+	// UpdateGameTick advances the game from expectedYear to newYear, stamping
+	// it with tickTime and releasing the tick's claim. It returns
+	// ErrConcurrentTick, writing nothing, if the stored year is no longer
+	// expectedYear.
+	UpdateGameTick(ctx context.Context, gameID string, expectedYear, newYear int, tickTime time.Time) error
+
+	// UpdateGameSeeds persists the game's RNG seed registry and stream positions
+	UpdateGameSeeds(ctx context.Context, gameID string, seeds *models.GameSeeds) error
+
+	// UpdateGameRules pins the ruleset a game is played under
+	UpdateGameRules(ctx context.Context, gameID string, rules *models.Ruleset) error
+
+	// TransitionGameState moves a game from one lifecycle state to another,
+	// returning ErrStateConflict, writing nothing, if it is no longer in from
+	TransitionGameState(ctx context.Context, gameID string, from, to models.GameState) error
+
+	// EndGame marks a game ended with its winners, returning
+	// ErrStateConflict, writing nothing, if it is no longer in from
+	EndGame(ctx context.Context, gameID string, from models.GameState, winners []string) error
+
+	// RecordFireMastery notes the year a player mastered fire, keeping the
+	// earliest if one is already recorded
+	RecordFireMastery(ctx context.Context, gameID string, playerID string, year int) error
+
+	// RecordClockAdjustment appends a skipped-downtime record to a game
+	RecordClockAdjustment(ctx context.Context, gameID string, adjustment models.ClockAdjustment) error
+
+	// GetPlayerActivity retrieves the last-active records of a game's players
+	GetPlayerActivity(ctx context.Context, gameID string) ([]*models.PlayerActivity, error)
+
+	// SavePlayerActivity inserts or replaces a player's last-active record
+	SavePlayerActivity(ctx context.Context, record *models.PlayerActivity) error
+
+	// GetPlayerPolicies retrieves the policies a game's players have set
+	GetPlayerPolicies(ctx context.Context, gameID string) ([]*models.PlayerPolicy, error)
+
+	// SavePlayerPolicy inserts or replaces a player's policies
+	SavePlayerPolicy(ctx context.Context, policy *models.PlayerPolicy) error
+
+	// GetPlayerSettings retrieves the settings a game's players have saved
+	GetPlayerSettings(ctx context.Context, gameID string) ([]*models.PlayerSettings, error)
+
+	// SavePlayerSettings inserts or replaces a player's settings
+	SavePlayerSettings(ctx context.Context, settings *models.PlayerSettings) error
 
 	// SaveMapMetadata saves map generation metadata
 	SaveMapMetadata(ctx context.Context, metadata *models.MapMetadata) error
@@ -29,10 +104,31 @@ type GameRepository interface {
 	// GetMapMetadata retrieves map metadata for a game
 	GetMapMetadata(ctx context.Context, gameID string) (*models.MapMetadata, error)
 
-	// GetMapTiles retrieves map tiles for a game (with optional filtering)
+	// GetMapTiles retrieves map tiles for a game, optionally only those a
+	// player can see (visible to or owned by the player)
 	GetMapTiles(ctx context.Context, gameID string, playerID *string) ([]*models.MapTile, error)
 
-	// GetStartingPosition retrieves a player's starting position
+	// GetSeenTiles retrieves tiles currently visible to or owned by any player
+	GetSeenTiles(ctx context.Context, gameID string) ([]*models.MapTile, error)
+
+	// GetExploredTiles retrieves every player's last-seen tile records for a game
+	GetExploredTiles(ctx context.Context, gameID string) ([]*models.ExploredTile, error)
+
+	// SaveExploredTiles inserts or replaces last-seen tile records
+	SaveExploredTiles(ctx context.Context, tiles []*models.ExploredTile) error
+
+	// SaveMinimap inserts or replaces a player's minimap
+	SaveMinimap(ctx context.Context, minimap *models.Minimap) error
+
+	// SaveBorders inserts or replaces the territory borders a player knows of
+	SaveBorders(ctx context.Context, borders *models.Borders) error
+
+	// RevealTiles adds a player to the visibleTo list of the given tiles,
+	// stamping those newly revealed with the tick
+	RevealTiles(ctx context.Context, gameID string, playerID string, locations []models.Location, tick int) error
+
+	// GetStartingPosition retrieves a player's starting position, or nil if
+	// the player has not been given a region
 	GetStartingPosition(ctx context.Context, gameID string, playerID string) (*models.StartingPosition, error)
 
 	// CreateUnit creates a new unit
@@ -62,9 +158,136 @@ type GameRepository interface {
 	// UpdateSettlement updates a settlement
 	UpdateSettlement(ctx context.Context, settlement *models.Settlement) error
 
+	// GetSettlementSimulation retrieves a settlement's saved human simulation
+	GetSettlementSimulation(ctx context.Context, settlementID string) (*models.SettlementSimulation, error)
+
+	// SaveSettlementSimulation inserts or replaces a settlement's saved human simulation
+	SaveSettlementSimulation(ctx context.Context, saved *models.SettlementSimulation) error
+
+	// AssignTiles assigns unowned (or already player-owned) tiles at the given
+	// locations to a settlement and its player, stamping them modified at tick
+	AssignTiles(ctx context.Context, gameID string, settlementID string, playerID string, locations []models.Location, tick int) error
+
+	// MergeSettlements atomically saves the surviving settlement and
+	// re-parents the absorbed settlement's suburbs to it. With asSuburb the
+	// absorbed settlement is saved as it is, the survivor's suburb; otherwise
+	// its tiles are reassigned to the survivor (stamped modified at tick) and
+	// it is deleted.
+	MergeSettlements(ctx context.Context, survivor *models.Settlement, absorbed *models.Settlement, asSuburb bool, tick int) error
+
 	// GetMapTile retrieves a specific tile by coordinates
 	GetMapTile(ctx context.Context, gameID string, x int, y int) (*models.MapTile, error)
 
+	// GetResourceTiles retrieves tiles with tracked resource quantities
+	GetResourceTiles(ctx context.Context, gameID string) ([]*models.MapTile, error)
+
+	// UpdateTileResources persists a tile's resources, remaining quantities and last-modified tick
+	UpdateTileResources(ctx context.Context, tile *models.MapTile) error
+
+	// GetRiverTiles retrieves tiles on a river system
+	GetRiverTiles(ctx context.Context, gameID string) ([]*models.MapTile, error)
+
+	// GetSoilTiles retrieves farmed tiles and tiles whose soil is still recovering
+	GetSoilTiles(ctx context.Context, gameID string) ([]*models.MapTile, error)
+
+	// UpdateTileFertility persists a tile's soil fertility and last-modified tick
+	UpdateTileFertility(ctx context.Context, tile *models.MapTile) error
+
+	// GetPollutedTiles retrieves tiles with any pollution
+	GetPollutedTiles(ctx context.Context, gameID string) ([]*models.MapTile, error)
+
+	// UpdateTilePollution persists a tile's pollution and last-modified tick
+	UpdateTilePollution(ctx context.Context, tile *models.MapTile) error
+
+	// UpdateTileTerrain persists a tile's terrain, resources, soil and
+	// last-modified tick after the land itself has changed
+	UpdateTileTerrain(ctx context.Context, tile *models.MapTile) error
+
+	// AddTileImprovement adds an improvement to a tile, stamping it modified at tick
+	AddTileImprovement(ctx context.Context, gameID string, x int, y int, improvement string, tick int) error
+
+	// UpdateTileImprovements persists a tile's improvements, damaged
+	// improvements and last-modified tick
+	UpdateTileImprovements(ctx context.Context, tile *models.MapTile) error
+
+	// GetTilesWithImprovement retrieves tiles carrying the given improvement
+	GetTilesWithImprovement(ctx context.Context, gameID string, improvement string) ([]*models.MapTile, error)
+
+	// CreateOrder queues a player order
+	CreateOrder(ctx context.Context, order *models.Order) error
+
+	// GetPendingOrders retrieves a game's pending orders in submission order
+	GetPendingOrders(ctx context.Context, gameID string) ([]*models.Order, error)
+
+	// UpdateOrder updates an order's status
+	UpdateOrder(ctx context.Context, order *models.Order) error
+
+	// EliminatePlayer marks a player eliminated, releases their tiles and
+	// visibility, disbands their units and hands their settlements to the
+	// neutral player
+	EliminatePlayer(ctx context.Context, gameID string, playerID string, tick int) error
+
+	// ReleasePlayer removes a player from a game as if they had never
+	// joined: their seat, region, tiles, visibility, units and settlements
+	// are all released, along with the settlements' saved simulations
+	ReleasePlayer(ctx context.Context, gameID string, playerID string, tick int) error
+
+	// GetDiplomacyStates retrieves the standing of every pair of players in a
+	// game that has had dealings
+	GetDiplomacyStates(ctx context.Context, gameID string) ([]*models.DiplomacyState, error)
+
+	// SaveDiplomacyState inserts or replaces the standing between a pair of players
+	SaveDiplomacyState(ctx context.Context, state *models.DiplomacyState) error
+
+	// GetMinorCivs retrieves a game's minor civilizations, ordered by ID
+	GetMinorCivs(ctx context.Context, gameID string) ([]*models.MinorCiv, error)
+
+	// SaveMinorCiv inserts or replaces a minor civilization
+	SaveMinorCiv(ctx context.Context, civ *models.MinorCiv) error
+
+	// CaptureSettlement hands a settlement and the tiles it owns to another
+	// player, stamping the tiles modified at tick
+	CaptureSettlement(ctx context.Context, settlement *models.Settlement, playerID string, tick int) error
+
+	// GetObjectives retrieves every objective set in a game, ordered by ID
+	GetObjectives(ctx context.Context, gameID string) ([]*models.Objective, error)
+
+	// SaveObjective inserts or replaces an objective
+	SaveObjective(ctx context.Context, objective *models.Objective) error
+
+	// RecordGameResult folds a user's result in a finished game into their
+	// cross-game statistics. A game already counted for the user is ignored.
+	RecordGameResult(ctx context.Context, result *models.GameResult) error
+
+	// CreateEvent records a game event
+	CreateEvent(ctx context.Context, event *models.GameEvent) error
+
+	// GetEvents returns a game's events, oldest first
+	GetEvents(ctx context.Context, gameID string) ([]*models.GameEvent, error)
+
+	// GetGameHistory returns the standings sampled over a game, oldest first
+	GetGameHistory(ctx context.Context, gameID string) ([]*models.GameHistoryPoint, error)
+
+	// SaveGameHistory inserts or replaces a game's standings for a year
+	SaveGameHistory(ctx context.Context, point *models.GameHistoryPoint) error
+
+	// GetGameSummary retrieves the summary written when a game finished
+	GetGameSummary(ctx context.Context, gameID string) (*models.GameSummary, error)
+
+	// SaveGameSummary inserts or replaces a finished game's summary
+	SaveGameSummary(ctx context.Context, summary *models.GameSummary) error
+
 	// Close closes the repository connection
 	Close(ctx context.Context) error
 }
+
+// BalanceReportStore keeps the periodic balance reports, so each report can
+// be compared with the one before it
+type BalanceReportStore interface {
+	// SaveBalanceReport stores a report
+	SaveBalanceReport(ctx context.Context, report *models.BalanceReport) error
+
+	// GetLatestBalanceReport returns the most recently generated report, or
+	// ErrNotFound if none has been stored
+	GetLatestBalanceReport(ctx context.Context) (*models.BalanceReport, error)
+}
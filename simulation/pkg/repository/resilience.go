@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HealthChecker is implemented by repositories that can report whether
+// their backing store is reachable
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// MongoOptions configures MongoDB timeouts and the connection retry policy
+type MongoOptions struct {
+	ConnectTimeout         time.Duration // Per dial
+	ServerSelectionTimeout time.Duration // How long an operation waits for a usable server
+	OperationTimeout       time.Duration // Upper bound on any single operation
+	QueryTimeout           time.Duration // Deadline of each repository call, even within a longer-lived context
+	MaxConnectAttempts     int           // 0 retries until the context is cancelled
+	InitialBackoff         time.Duration
+	MaxBackoff             time.Duration
+}
+
+// DefaultMongoOptions returns the timeouts and retry policy used in production
+func DefaultMongoOptions() MongoOptions {
+	return MongoOptions{
+		ConnectTimeout:         10 * time.Second,
+		ServerSelectionTimeout: 5 * time.Second,
+		OperationTimeout:       30 * time.Second,
+		QueryTimeout:           10 * time.Second,
+		MaxConnectAttempts:     0,
+		InitialBackoff:         500 * time.Millisecond,
+		MaxBackoff:             30 * time.Second,
+	}
+}
+
+// MongoOptionsFromEnv overrides the defaults with MONGO_CONNECT_TIMEOUT,
+// MONGO_SERVER_SELECTION_TIMEOUT, MONGO_OPERATION_TIMEOUT, MONGO_QUERY_TIMEOUT,
+// MONGO_MAX_BACKOFF (Go durations such as "5s") and MONGO_CONNECT_ATTEMPTS
+func MongoOptionsFromEnv() MongoOptions {
+	opts := DefaultMongoOptions()
+	durations := map[string]*time.Duration{
+		"MONGO_CONNECT_TIMEOUT":          &opts.ConnectTimeout,
+		"MONGO_SERVER_SELECTION_TIMEOUT": &opts.ServerSelectionTimeout,
+		"MONGO_OPERATION_TIMEOUT":        &opts.OperationTimeout,
+		"MONGO_QUERY_TIMEOUT":            &opts.QueryTimeout,
+		"MONGO_MAX_BACKOFF":              &opts.MaxBackoff,
+	}
+	for name, target := range durations {
+		if value := os.Getenv(name); value != "" {
+			if d, err := time.ParseDuration(value); err == nil && d > 0 {
+				*target = d
+			} else {
+				log.Printf("Ignoring invalid %s %q", name, value)
+			}
+		}
+	}
+	if value := os.Getenv("MONGO_CONNECT_ATTEMPTS"); value != "" {
+		if attempts, err := strconv.Atoi(value); err == nil && attempts >= 0 {
+			opts.MaxConnectAttempts = attempts
+		} else {
+			log.Printf("Ignoring invalid MONGO_CONNECT_ATTEMPTS %q", value)
+		}
+	}
+	return opts
+}
+
+// Backoff returns the delay before retry number attempt (from 1), doubling
+// from initial up to max
+func Backoff(attempt int, initial, max time.Duration) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// NewMongoRepositoryWithOptions connects to MongoDB, retrying with
+// exponential backoff until the server answers a ping, the attempts run out
+// or ctx is cancelled
+func NewMongoRepositoryWithOptions(ctx context.Context, uri string, dbName string, opts MongoOptions) (*MongoRepository, error) {
+	clientOpts := options.Client().
+		ApplyURI(uri).
+		SetConnectTimeout(opts.ConnectTimeout).
+		SetServerSelectionTimeout(opts.ServerSelectionTimeout).
+		SetTimeout(opts.OperationTimeout)
+
+	for attempt := 1; ; attempt++ {
+		client, err := mongo.Connect(ctx, clientOpts)
+		if err == nil {
+			if err = client.Ping(ctx, nil); err == nil {
+				return &MongoRepository{client: client, db: client.Database(dbName), queryTimeout: opts.QueryTimeout}, nil
+			}
+			client.Disconnect(ctx)
+		}
+
+		if opts.MaxConnectAttempts > 0 && attempt >= opts.MaxConnectAttempts {
+			return nil, err
+		}
+		delay := Backoff(attempt, opts.InitialBackoff, opts.MaxBackoff)
+		log.Printf("MongoDB connection attempt %d failed: %v; retrying in %v", attempt, err, delay)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Ping checks that MongoDB is reachable
+func (r *MongoRepository) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx, nil)
+}
+
+// withDeadline bounds a single repository call by the query timeout. The
+// driver's operation timeout gives way to any deadline already on ctx, so
+// without this a call made under a long tick deadline could hang as long as
+// the whole tick.
+func (r *MongoRepository) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.queryTimeout)
+}
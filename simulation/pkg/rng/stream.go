@@ -0,0 +1,61 @@
+package rng
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// splitMixGamma is the SplitMix64 increment (golden ratio scaled to 64 bits)
+const splitMixGamma = 0x9E3779B97F4A7C15
+
+// Stream is a counter-based random number stream (SplitMix64).
+// Its complete state is the seed plus the number of values drawn so far,
+// so it can be persisted in a document and resumed exactly where it left off.
+type Stream struct {
+	Seed     int64 `bson:"seed"`
+	Position int64 `bson:"position"`
+}
+
+// NewStream creates a stream positioned at the start of the given seed
+func NewStream(seed int64) *Stream {
+	return &Stream{Seed: seed}
+}
+
+// DeriveSeed derives an independent seed for a named stream from a master seed string
+func DeriveSeed(master string, name string) int64 {
+	h := sha256.Sum256([]byte(master + ":" + name))
+	return int64(binary.BigEndian.Uint64(h[:8]))
+}
+
+// Uint64 returns the next pseudo-random 64-bit value and advances the stream
+func (s *Stream) Uint64() uint64 {
+	s.Position++
+	z := uint64(s.Seed) + uint64(s.Position)*splitMixGamma
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer (implements rand.Source)
+func (s *Stream) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Float64 returns a pseudo-random float64 in [0, 1)
+func (s *Stream) Float64() float64 {
+	return float64(s.Uint64()>>11) / (1 << 53)
+}
+
+// Intn returns a pseudo-random integer in [0, n). It panics if n <= 0.
+func (s *Stream) Intn(n int) int {
+	if n <= 0 {
+		panic("rng: invalid argument to Intn")
+	}
+	return int(s.Uint64() % uint64(n))
+}
+
+// Clone returns an independent copy of the stream at its current position
+func (s *Stream) Clone() *Stream {
+	c := *s
+	return &c
+}
@@ -0,0 +1,278 @@
+// Package savegame exports a complete game to a single portable file and
+// imports it again, for backups, bug-report attachments and moving games
+// between environments.
+//
+// A savegame is MongoDB Extended JSON, so every document keeps exactly the
+// fields and types it is stored with. Each file carries a format version;
+// files written by a newer version than this build understands are refused.
+package savegame
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// Format identifies a savegame file
+const Format = "simciv-savegame"
+
+// Version is the savegame format this build writes. Version 2 added the
+// settlements' saved simulations.
+const Version = 2
+
+// Savegame is everything stored for one game. Technologies travel with the
+// settlements whose people discovered them, and each settlement's saved
+// simulation with it, so an imported game resumes the same people. Minimaps
+// and the engine's other in-memory state are left out; the engine rebuilds
+// them as the game ticks.
+type Savegame struct {
+	Format     string    `bson:"format"`
+	Version    int       `bson:"version"`
+	ExportedAt time.Time `bson:"exportedAt"`
+
+	Game              *models.Game                   `bson:"game"`
+	MapMetadata       *models.MapMetadata            `bson:"mapMetadata,omitempty"` // Absent while a game is still starting
+	Tiles             []*models.MapTile              `bson:"tiles"`
+	StartingPositions []*models.StartingPosition     `bson:"startingPositions"`
+	ExploredTiles     []*models.ExploredTile         `bson:"exploredTiles"`
+	Units             []*models.Unit                 `bson:"units"`
+	Settlements       []*models.Settlement           `bson:"settlements"`
+	Simulations       []*models.SettlementSimulation `bson:"simulations,omitempty"` // Absent from version 1 savegames; those settlements start fresh simulations
+	Orders            []*models.Order                `bson:"orders"`                // Pending orders; executed ones have left their mark on the game
+	Activity          []*models.PlayerActivity       `bson:"activity"`
+	Policies          []*models.PlayerPolicy         `bson:"policies,omitempty"` // Absent from savegames written before policies existed
+	Settings          []*models.PlayerSettings       `bson:"settings,omitempty"` // Absent from savegames written before player settings existed
+	Diplomacy         []*models.DiplomacyState       `bson:"diplomacy"`
+	MinorCivs         []*models.MinorCiv             `bson:"minorCivs"`
+	Objectives        []*models.Objective            `bson:"objectives"`
+	Events            []*models.GameEvent            `bson:"events"`
+	History           []*models.GameHistoryPoint     `bson:"history,omitempty"` // Absent from savegames written before standings were sampled
+	Summary           *models.GameSummary            `bson:"summary,omitempty"` // Present once the game has finished
+}
+
+// Export reads a game and everything stored for it from repo
+func Export(ctx context.Context, repo repository.GameRepository, gameID string) (*Savegame, error) {
+	game, err := repo.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	gameID = game.GameID
+	save := &Savegame{Format: Format, Version: Version, ExportedAt: time.Now().UTC(), Game: game}
+
+	save.MapMetadata, err = repo.GetMapMetadata(ctx, gameID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if save.Tiles, err = repo.GetMapTiles(ctx, gameID, nil); err != nil {
+		return nil, err
+	}
+	for _, playerID := range game.PlayerList {
+		position, err := repo.GetStartingPosition(ctx, gameID, playerID)
+		if err != nil {
+			return nil, err
+		}
+		if position != nil {
+			save.StartingPositions = append(save.StartingPositions, position)
+		}
+	}
+	if save.ExploredTiles, err = repo.GetExploredTiles(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Units, err = repo.GetUnits(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Settlements, err = repo.GetSettlements(ctx, gameID); err != nil {
+		return nil, err
+	}
+	for _, settlement := range save.Settlements {
+		saved, err := repo.GetSettlementSimulation(ctx, settlement.SettlementID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		save.Simulations = append(save.Simulations, saved)
+	}
+	if save.Orders, err = repo.GetPendingOrders(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Activity, err = repo.GetPlayerActivity(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Policies, err = repo.GetPlayerPolicies(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Settings, err = repo.GetPlayerSettings(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Diplomacy, err = repo.GetDiplomacyStates(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.MinorCivs, err = repo.GetMinorCivs(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Objectives, err = repo.GetObjectives(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.Events, err = repo.GetEvents(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if save.History, err = repo.GetGameHistory(ctx, gameID); err != nil {
+		return nil, err
+	}
+	save.Summary, err = repo.GetGameSummary(ctx, gameID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	return save, nil
+}
+
+// Import writes a savegame into repo. It refuses, writing nothing, if the
+// game already exists there. The game document is written last, so an
+// engine watching repo never picks up a half-imported game.
+func Import(ctx context.Context, repo repository.GameRepository, save *Savegame) error {
+	if err := save.validate(); err != nil {
+		return err
+	}
+	gameID := save.Game.GameID
+	if _, err := repo.GetGame(ctx, gameID); err == nil {
+		return fmt.Errorf("%w: game %s already exists", repository.ErrConflict, gameID)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	if save.MapMetadata != nil {
+		if err := repo.SaveMapMetadata(ctx, save.MapMetadata); err != nil {
+			return fmt.Errorf("importing map metadata: %w", err)
+		}
+	}
+	if len(save.Tiles) > 0 {
+		if err := repo.SaveMapTiles(ctx, save.Tiles); err != nil {
+			return fmt.Errorf("importing tiles: %w", err)
+		}
+	}
+	if len(save.StartingPositions) > 0 {
+		if err := repo.SaveStartingPositions(ctx, save.StartingPositions); err != nil {
+			return fmt.Errorf("importing starting positions: %w", err)
+		}
+	}
+	if len(save.ExploredTiles) > 0 {
+		if err := repo.SaveExploredTiles(ctx, save.ExploredTiles); err != nil {
+			return fmt.Errorf("importing explored tiles: %w", err)
+		}
+	}
+	for _, unit := range save.Units {
+		if err := repo.CreateUnit(ctx, unit); err != nil {
+			return fmt.Errorf("importing unit %s: %w", unit.UnitID, err)
+		}
+	}
+	for _, settlement := range save.Settlements {
+		if err := repo.CreateSettlement(ctx, settlement); err != nil {
+			return fmt.Errorf("importing settlement %s: %w", settlement.SettlementID, err)
+		}
+	}
+	for _, saved := range save.Simulations {
+		if err := repo.SaveSettlementSimulation(ctx, saved); err != nil {
+			return fmt.Errorf("importing simulation of settlement %s: %w", saved.SettlementID, err)
+		}
+	}
+	for _, order := range save.Orders {
+		if err := repo.CreateOrder(ctx, order); err != nil {
+			return fmt.Errorf("importing order %s: %w", order.OrderID, err)
+		}
+	}
+	for _, record := range save.Activity {
+		if err := repo.SavePlayerActivity(ctx, record); err != nil {
+			return fmt.Errorf("importing activity of %s: %w", record.PlayerID, err)
+		}
+	}
+	for _, policy := range save.Policies {
+		if err := repo.SavePlayerPolicy(ctx, policy); err != nil {
+			return fmt.Errorf("importing policies of %s: %w", policy.PlayerID, err)
+		}
+	}
+	for _, settings := range save.Settings {
+		if err := repo.SavePlayerSettings(ctx, settings); err != nil {
+			return fmt.Errorf("importing settings of %s: %w", settings.PlayerID, err)
+		}
+	}
+	for _, state := range save.Diplomacy {
+		if err := repo.SaveDiplomacyState(ctx, state); err != nil {
+			return fmt.Errorf("importing diplomacy: %w", err)
+		}
+	}
+	for _, civ := range save.MinorCivs {
+		if err := repo.SaveMinorCiv(ctx, civ); err != nil {
+			return fmt.Errorf("importing minor civ %s: %w", civ.MinorCivID, err)
+		}
+	}
+	for _, objective := range save.Objectives {
+		if err := repo.SaveObjective(ctx, objective); err != nil {
+			return fmt.Errorf("importing objective %s: %w", objective.ObjectiveID, err)
+		}
+	}
+	for _, event := range save.Events {
+		if err := repo.CreateEvent(ctx, event); err != nil {
+			return fmt.Errorf("importing event %s: %w", event.EventID, err)
+		}
+	}
+	for _, point := range save.History {
+		if err := repo.SaveGameHistory(ctx, point); err != nil {
+			return fmt.Errorf("importing standings of year %d: %w", point.Year, err)
+		}
+	}
+	if save.Summary != nil {
+		if err := repo.SaveGameSummary(ctx, save.Summary); err != nil {
+			return fmt.Errorf("importing summary: %w", err)
+		}
+	}
+
+	return repo.CreateGame(ctx, save.Game)
+}
+
+// Write encodes a savegame to w
+func Write(w io.Writer, save *Savegame) error {
+	data, err := bson.MarshalExtJSONIndent(save, false, false, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Read decodes a savegame from r, refusing files of another format or of a
+// newer version than this build
+func Read(r io.Reader) (*Savegame, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var save Savegame
+	if err := bson.UnmarshalExtJSON(data, false, &save); err != nil {
+		return nil, fmt.Errorf("not a savegame: %w", err)
+	}
+	if err := save.validate(); err != nil {
+		return nil, err
+	}
+	return &save, nil
+}
+
+// validate checks a savegame can be imported by this build
+func (s *Savegame) validate() error {
+	switch {
+	case s.Format != Format:
+		return fmt.Errorf("not a savegame: format %q", s.Format)
+	case s.Version < 1 || s.Version > Version:
+		return fmt.Errorf("savegame version %d is not supported (this build reads up to %d)", s.Version, Version)
+	case s.Game == nil || s.Game.GameID == "":
+		return errors.New("savegame has no game")
+	}
+	return nil
+}
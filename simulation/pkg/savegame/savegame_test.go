@@ -0,0 +1,127 @@
+package savegame
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/mapgen"
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+func TestSavegame_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := repository.NewMemoryRepository()
+	players := []string{"alice", "bob"}
+	metadata, tiles, positions, err := mapgen.NewGenerator("savegame", len(players)).GenerateMap(ctx, "game1", len(players))
+	if err != nil {
+		t.Fatalf("GenerateMap failed: %v", err)
+	}
+	for i, position := range positions {
+		position.PlayerID = players[i]
+	}
+	source.InsertGame(&models.Game{GameID: "game1", State: models.GameStateActive, CurrentYear: -4200,
+		PlayerList: players, FireMastery: map[string]int{"alice": -4500}})
+	source.SaveMapMetadata(ctx, metadata)
+	source.SaveMapTiles(ctx, tiles)
+	source.SaveStartingPositions(ctx, positions)
+	source.CreateUnit(ctx, &models.Unit{UnitID: "u1", GameID: "game1", PlayerID: "bob", UnitType: "settlers", Location: models.Location{X: positions[1].CenterX, Y: positions[1].CenterY}})
+	source.CreateSettlement(ctx, &models.Settlement{SettlementID: "s1", GameID: "game1", PlayerID: "alice", Name: "Home",
+		Population: 300, Location: models.Location{X: positions[0].StartingCityX, Y: positions[0].StartingCityY}, Technologies: []string{"fire"}})
+	source.SaveSettlementSimulation(ctx, &models.SettlementSimulation{SettlementID: "s1", GameID: "game1", Year: -4201,
+		Population: 300, Snapshot: []byte(`{"humans":[]}`), UpdatedAt: time.Now().UTC().Truncate(time.Millisecond)})
+	source.SavePlayerActivity(ctx, &models.PlayerActivity{GameID: "game1", PlayerID: "alice", LastActiveTick: -4201, LastActiveAt: time.Now()})
+	source.SavePlayerPolicy(ctx, &models.PlayerPolicy{GameID: "game1", PlayerID: "alice", Infrastructure: models.PolicyHigh})
+	source.SavePlayerSettings(ctx, &models.PlayerSettings{GameID: "game1", PlayerID: "bob", AutoEndTurn: true, GovernorAfterTicks: 7,
+		MutedEvents: []string{models.EventSettlementGrew}, UpdatedAt: time.Now().UTC().Truncate(time.Millisecond)})
+	source.SaveObjective(ctx, &models.Objective{GameID: "game1", ObjectiveID: "o1", PlayerID: "alice", Type: models.ObjectivePopulation, Target: 500, Status: models.ObjectiveActive})
+	source.CreateEvent(ctx, &models.GameEvent{EventID: "e1", GameID: "game1", Year: -4300, Type: models.EventSettlementGrew, PlayerID: "alice"})
+	source.SaveGameHistory(ctx, &models.GameHistoryPoint{GameID: "game1", Year: -4300, Players: []models.PlayerStanding{{PlayerID: "alice", Population: 300, Settlements: 1}}})
+
+	save, err := Export(ctx, source, "game1")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var file bytes.Buffer
+	if err := Write(&file, save); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	written := file.String()
+
+	// A fresh database receives the game exactly as it was stored
+	loaded, err := Read(strings.NewReader(written))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	target := repository.NewMemoryRepository()
+	if err := Import(ctx, target, loaded); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	again, err := Export(ctx, target, "game1")
+	if err != nil {
+		t.Fatalf("Export of the imported game failed: %v", err)
+	}
+	again.ExportedAt = save.ExportedAt
+	var rewritten bytes.Buffer
+	Write(&rewritten, again)
+	var original, roundTripped any
+	json.Unmarshal(file.Bytes(), &original)
+	json.Unmarshal(rewritten.Bytes(), &roundTripped)
+	if !reflect.DeepEqual(original, roundTripped) {
+		t.Error("Expected the imported game to export identically to the original")
+	}
+	if len(again.Tiles) != len(tiles) || len(again.Settlements) != 1 || again.Settlements[0].Technologies[0] != "fire" {
+		t.Errorf("Expected every tile and the settlement's technologies, got %d tiles and %+v", len(again.Tiles), again.Settlements)
+	}
+	if len(again.Simulations) != 1 || again.Simulations[0].Year != -4201 || string(again.Simulations[0].Snapshot) != `{"humans":[]}` {
+		t.Errorf("Expected the settlement's saved simulation to survive, got %+v", again.Simulations)
+	}
+	if again.Game.FireMastery["alice"] != -4500 || len(again.Events) != 1 {
+		t.Errorf("Expected fire mastery and events to survive, got %+v and %d events", again.Game.FireMastery, len(again.Events))
+	}
+	if len(again.Policies) != 1 || again.Policies[0].InfrastructureLevel() != models.PolicyHigh {
+		t.Errorf("Expected the player's policies to survive, got %+v", again.Policies)
+	}
+	if len(again.Settings) != 1 || !again.Settings[0].AutoEndTurn || again.Settings[0].GovernorAfterTicks != 7 ||
+		len(again.Settings[0].MutedEvents) != 1 || again.Settings[0].MutedEvents[0] != models.EventSettlementGrew {
+		t.Errorf("Expected the player's settings to survive, got %+v", again.Settings)
+	}
+	if len(again.History) != 1 || again.History[0].Players[0].Population != 300 || again.Summary != nil {
+		t.Errorf("Expected the sampled standings and no summary for a running game, got %+v and %+v", again.History, again.Summary)
+	}
+
+	// Importing over an existing game writes nothing
+	before := target.OpCounts()
+	if err := Import(ctx, target, loaded); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Expected ErrConflict importing a game twice, got %v", err)
+	}
+	if target.OpCounts()["CreateUnit"] != before["CreateUnit"] {
+		t.Error("Expected a refused import to write nothing")
+	}
+
+	// Version 1 files, written before simulations were saved, still import
+	older := strings.Replace(written, `"version": 2`, `"version": 1`, 1)
+	if old, err := Read(strings.NewReader(older)); err != nil {
+		t.Errorf("Expected a version 1 savegame to be read, got %v", err)
+	} else {
+		old.Simulations = nil
+		if err := Import(ctx, repository.NewMemoryRepository(), old); err != nil {
+			t.Errorf("Expected a version 1 savegame without simulations to import, got %v", err)
+		}
+	}
+
+	// Files from a newer build, or that aren't savegames, are refused
+	newer := strings.Replace(written, `"version": 2`, `"version": 3`, 1)
+	if _, err := Read(strings.NewReader(newer)); err == nil || !strings.Contains(err.Error(), "version 3") {
+		t.Errorf("Expected a newer savegame to be refused, got %v", err)
+	}
+	if _, err := Read(strings.NewReader(`{"game": {}}`)); err == nil {
+		t.Error("Expected a file without the savegame format to be refused")
+	}
+}
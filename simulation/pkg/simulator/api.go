@@ -0,0 +1,49 @@
+package simulator
+
+import (
+	"runtime"
+	"sync"
+)
+
+// APIVersion is the semantic version of the package's exported API
+const APIVersion = "1.5.0"
+
+// Params configures a run
+type Params = SimulationConfig
+
+// Result is the outcome of a run
+type Result = ViabilityResult
+
+// Run simulates a civilization from its starting conditions, or from
+// Params.Resume, until it masters fire, dies out, stops growing or reaches
+// Params.MaxDays
+func Run(params Params) Result {
+	return RunSimulation(params)
+}
+
+// Sweep runs params once per seed, in parallel, and returns the results in
+// seed order. Every run keeps only its summary, so Params.MetricsSink is
+// ignored; use Run to keep daily metrics.
+func Sweep(params Params, seeds []int) []Result {
+	results := make([]Result, len(seeds))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(seeds)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				run := params
+				run.Seed = seeds[i]
+				run.MetricsSink = SummaryOnlySink{}
+				results[i] = RunSimulation(run)
+			}
+		}()
+	}
+	for i := range seeds {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
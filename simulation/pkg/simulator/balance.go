@@ -0,0 +1,171 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Balance metrics measured over a scenario's runs
+const (
+	MetricSurvivalRate            = "survival_rate"               // Share of runs not extinct at the end
+	MetricViabilityRate           = "viability_rate"              // Share of runs assessed viable
+	MetricFireMasteryRate         = "fire_mastery_rate"           // Share of runs that mastered fire
+	MetricMedianDaysToFireMastery = "median_days_to_fire_mastery" // Over the runs that mastered fire (-1 if none did)
+	MetricMedianFinalPopulation   = "median_final_population"
+	MetricMedianPeakPopulation    = "median_peak_population"
+)
+
+// BalanceSeeds are the standard seeds balance is measured over: the same
+// starting conditions with different random outcomes
+var BalanceSeeds = []int{
+	12345, 67890, 11111, 22222, 33333, 44444, 55555,
+	66666, 77777, 88888, 99999, 10101, 20202, 30303,
+	40404, 50505, 60606, 70707, 80808, 90909, 12121,
+	23232, 34343, 45454, 56565, 67676, 78787, 89898,
+	13579, 24680, 98765, 87654, 76543, 65432, 54321,
+	43210, 31415, 27182, 16180, 14142, 17320, 26457,
+	32103, 41231, 51234, 61234, 71234, 81234, 91234,
+	10203, // 50 seeds total
+}
+
+// BalanceScenario is a starting position whose outcomes are pinned by a
+// balance golden
+type BalanceScenario struct {
+	Name       string
+	Conditions StartingConditions
+	MaxDays    int
+	Policy     Policy // Decides the labor allocation as runs go (nil keeps the starting ratio)
+}
+
+// BalanceScenarios returns the standard starting positions whose outcomes
+// are pinned by the balance goldens and tracked by balance reports
+func BalanceScenarios() []BalanceScenario {
+	scholarly := DefaultStartingConditions()
+	scholarly.FoodAllocationRatio = 0.5
+	crowded := DefaultStartingConditions()
+	crowded.CarryingCapacity = 60
+	return []BalanceScenario{
+		{Name: "default", Conditions: DefaultStartingConditions(), MaxDays: 10 * DaysPerYear},
+		{Name: "science_focus", Conditions: scholarly, MaxDays: 10 * DaysPerYear},
+		{Name: "overcrowded", Conditions: crowded, MaxDays: 10 * DaysPerYear},
+	}
+}
+
+// BalanceBand is a metric's expected value and how far it may drift
+type BalanceBand struct {
+	Value     float64 `json:"value"`
+	Tolerance float64 `json:"tolerance"` // Largest accepted absolute difference
+}
+
+// BalanceGolden is the recorded outcome distribution of a scenario. Goldens
+// change only when a balance change is made on purpose.
+type BalanceGolden struct {
+	Scenario string                 `json:"scenario"`
+	Seeds    int                    `json:"seeds"`
+	MaxDays  int                    `json:"maxDays"`
+	Metrics  map[string]BalanceBand `json:"metrics"`
+}
+
+// MeasureBalance runs a scenario once per seed and returns its balance metrics
+func MeasureBalance(scenario BalanceScenario, seeds []int) map[string]float64 {
+	var survived, viable, mastered int
+	var daysToFire, finalPopulations, peakPopulations []float64
+	for _, result := range Sweep(Params{StartingConditions: scenario.Conditions, MaxDays: scenario.MaxDays, Policy: scenario.Policy}, seeds) {
+		if result.FinalPopulation > 0 {
+			survived++
+		}
+		if result.IsViable {
+			viable++
+		}
+		if result.FireMasteryUnlocked {
+			mastered++
+			daysToFire = append(daysToFire, float64(result.DaysToFireMastery))
+		}
+		finalPopulations = append(finalPopulations, float64(result.FinalPopulation))
+		peakPopulations = append(peakPopulations, float64(result.PeakPopulation))
+	}
+
+	runs := float64(len(seeds))
+	return map[string]float64{
+		MetricSurvivalRate:            float64(survived) / runs,
+		MetricViabilityRate:           float64(viable) / runs,
+		MetricFireMasteryRate:         float64(mastered) / runs,
+		MetricMedianDaysToFireMastery: median(daysToFire),
+		MetricMedianFinalPopulation:   median(finalPopulations),
+		MetricMedianPeakPopulation:    median(peakPopulations),
+	}
+}
+
+// NewBalanceGolden records measured metrics as a golden, keeping the
+// tolerances of a previous golden and otherwise allowing 10 percentage
+// points on rates and 10% on everything else
+func NewBalanceGolden(scenario BalanceScenario, seeds int, measured map[string]float64, previous *BalanceGolden) *BalanceGolden {
+	golden := &BalanceGolden{Scenario: scenario.Name, Seeds: seeds, MaxDays: scenario.MaxDays, Metrics: map[string]BalanceBand{}}
+	for name, value := range measured {
+		tolerance := 0.1
+		if band, ok := previous.band(name); ok {
+			tolerance = band.Tolerance
+		} else if !isRate(name) {
+			tolerance = math.Max(1, math.Abs(value)*0.1)
+		}
+		golden.Metrics[name] = BalanceBand{Value: value, Tolerance: tolerance}
+	}
+	return golden
+}
+
+// CheckBalance returns a description of every metric that drifted outside
+// its golden band, in metric order
+func CheckBalance(golden *BalanceGolden, measured map[string]float64) []string {
+	names := make([]string, 0, len(golden.Metrics))
+	for name := range golden.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var drifts []string
+	for _, name := range names {
+		band := golden.Metrics[name]
+		value, ok := measured[name]
+		if !ok {
+			drifts = append(drifts, fmt.Sprintf("%s: %s not measured", golden.Scenario, name))
+			continue
+		}
+		if math.Abs(value-band.Value) > band.Tolerance {
+			drifts = append(drifts, fmt.Sprintf("%s: %s is %.4g, expected %.4g ± %.4g", golden.Scenario, name, value, band.Value, band.Tolerance))
+		}
+	}
+	return drifts
+}
+
+// band returns a golden's band for a metric; a nil golden has none
+func (g *BalanceGolden) band(name string) (BalanceBand, bool) {
+	if g == nil {
+		return BalanceBand{}, false
+	}
+	band, ok := g.Metrics[name]
+	return band, ok
+}
+
+// isRate reports whether a balance metric is a share of runs
+func isRate(name string) bool {
+	switch name {
+	case MetricSurvivalRate, MetricViabilityRate, MetricFireMasteryRate:
+		return true
+	}
+	return false
+}
+
+// median returns the middle of the values, -1 if there are none
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return -1
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,80 @@
+package simulator
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateBalance = flag.Bool("update-balance", false, "rewrite the balance goldens in testdata/balance from the current simulator")
+
+// TestBalanceRegression compares each scenario's outcomes across the
+// viability seeds with its golden. After an intentional balance change,
+// review the new numbers and rewrite the goldens with
+//
+//	go test ./pkg/simulator -run TestBalanceRegression -update-balance
+func TestBalanceRegression(t *testing.T) {
+	for _, scenario := range BalanceScenarios() {
+		t.Run(scenario.Name, func(t *testing.T) {
+			path := filepath.Join("testdata", "balance", scenario.Name+".json")
+			measured := MeasureBalance(scenario, VIABILITY_TEST_SEEDS)
+
+			var previous *BalanceGolden
+			if data, err := os.ReadFile(path); err == nil {
+				previous = &BalanceGolden{}
+				if err := json.Unmarshal(data, previous); err != nil {
+					t.Fatalf("Reading %s: %v", path, err)
+				}
+			} else if !*updateBalance {
+				t.Fatalf("No golden for scenario %s; create it with -update-balance: %v", scenario.Name, err)
+			}
+
+			if *updateBalance {
+				golden := NewBalanceGolden(scenario, len(VIABILITY_TEST_SEEDS), measured, previous)
+				data, err := json.MarshalIndent(golden, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				t.Logf("Updated %s: %v", path, measured)
+				return
+			}
+
+			if previous.Seeds != len(VIABILITY_TEST_SEEDS) || previous.MaxDays != scenario.MaxDays {
+				t.Fatalf("Golden %s was recorded with %d seeds over %d days; rewrite it with -update-balance", path, previous.Seeds, previous.MaxDays)
+			}
+			for _, drift := range CheckBalance(previous, measured) {
+				t.Errorf("Balance drifted: %s (if intended, rewrite the golden with -update-balance)", drift)
+			}
+		})
+	}
+}
+
+func TestCheckBalance(t *testing.T) {
+	scenario := BalanceScenario{Name: "test", MaxDays: 100}
+	measured := map[string]float64{MetricSurvivalRate: 0.9, MetricMedianDaysToFireMastery: 400}
+	golden := NewBalanceGolden(scenario, 10, measured, nil)
+	if band := golden.Metrics[MetricSurvivalRate]; band.Tolerance != 0.1 {
+		t.Errorf("Expected rates to tolerate 10 points, got %+v", band)
+	}
+	if band := golden.Metrics[MetricMedianDaysToFireMastery]; band.Tolerance != 40 {
+		t.Errorf("Expected other metrics to tolerate 10%%, got %+v", band)
+	}
+
+	if drifts := CheckBalance(golden, map[string]float64{MetricSurvivalRate: 0.85, MetricMedianDaysToFireMastery: 430}); len(drifts) != 0 {
+		t.Errorf("Expected drift within the bands to pass, got %v", drifts)
+	}
+	if drifts := CheckBalance(golden, map[string]float64{MetricSurvivalRate: 0.7}); len(drifts) != 2 {
+		t.Errorf("Expected a drifted and a missing metric, got %v", drifts)
+	}
+
+	// Hand-tuned tolerances survive an update
+	golden.Metrics[MetricSurvivalRate] = BalanceBand{Value: 0.9, Tolerance: 0.02}
+	if updated := NewBalanceGolden(scenario, 10, measured, golden); updated.Metrics[MetricSurvivalRate].Tolerance != 0.02 {
+		t.Errorf("Expected the previous tolerance kept, got %+v", updated.Metrics[MetricSurvivalRate])
+	}
+}
@@ -0,0 +1,153 @@
+package simulator
+
+import (
+	"math"
+	"sort"
+)
+
+// cohort is the living humans of one gender and whole year of age, stepped
+// through a period together as if each were their average member
+type cohort struct {
+	members []*MinimalHuman
+	average MinimalHuman // Mean age and health of the members, never pregnant
+}
+
+// groupCohorts gathers the living humans into cohorts, in the order their
+// first member appears
+func groupCohorts(humans []*MinimalHuman) []*cohort {
+	type cohortKey struct {
+		age    int
+		gender string
+	}
+	byKey := make(map[cohortKey]*cohort)
+	var cohorts []*cohort
+	for _, human := range humans {
+		if !human.IsAlive {
+			continue
+		}
+		key := cohortKey{int(human.Age), human.Gender}
+		c := byKey[key]
+		if c == nil {
+			c = &cohort{average: MinimalHuman{Gender: human.Gender, IsAlive: true}}
+			byKey[key] = c
+			cohorts = append(cohorts, c)
+		}
+		c.members = append(c.members, human)
+		c.average.Age += human.Age
+		c.average.Health += human.Health
+	}
+	for _, c := range cohorts {
+		c.average.Age /= float64(len(c.members))
+		c.average.Health /= float64(len(c.members))
+	}
+	return cohorts
+}
+
+// drawCount returns how many of n trials succeed at the given chance: the
+// expected number, rounded up or down at random in proportion. A whole
+// expectation leaves the random stream untouched.
+func (s *Simulation) drawCount(n int, chance float64) int {
+	expected := float64(n) * chance
+	count := math.Floor(expected)
+	if fraction := expected - count; fraction > 0 && s.rng.NextBool(fraction) {
+		count++
+	}
+	return min(int(count), n)
+}
+
+// AdvanceCohorts advances the simulation by the given number of days like
+// AdvanceAggregated, but steps each cohort of humans of one gender and year
+// of age as its average member: health evolves once for the cohort and
+// moves each member as far, and deaths and conceptions are drawn as counts
+// rather than rolled for each human or couple. The outcome is written back
+// to the humans, which remain the simulation's state, so a settlement can
+// switch between cohorts and the full model at any period. Its cost grows
+// with the number of cohorts rather than with the population's square.
+func (s *Simulation) AdvanceCohorts(days int) *DailyMetrics {
+	state := s.State
+	if days <= 0 {
+		return s.AdvanceDays(0)
+	}
+	state.CurrentDay += days
+	yield := s.producePeriod(days)
+
+	// Health and ageing evolve day by day for each cohort's average member
+	firstDay := state.CurrentDay - days + 1
+	cohorts := groupCohorts(state.Humans)
+	for _, c := range cohorts {
+		before := c.average.Health
+		strain := childLaborHealth(&c.average, s.Conditions.ChildLabor)
+		for d := 0; d < days; d++ {
+			updateHealth(&c.average, yield.foodPerPerson)
+			adjustHealth(&c.average, environmentHealth(firstDay+d, yield.population, s.Conditions)+strain)
+		}
+		change := c.average.Health - before
+		for _, human := range c.members {
+			adjustHealth(human, change)
+			human.Age += AgeIncrementPerDay * float64(days)
+		}
+		c.average.Age += AgeIncrementPerDay * float64(days)
+	}
+
+	// Mortality: each cohort loses its expected share, the frailest first
+	var mortality Mortality
+	for _, c := range cohorts {
+		deaths := s.drawCount(len(c.members), compoundProbability(dailyMortalityChance(&c.average, state), days))
+		accidents := 0
+		if chance := childLaborAccidentChance(&c.average, s.Conditions.ChildLabor, state); chance > 0 {
+			accidents = s.drawCount(len(c.members)-deaths, compoundProbability(chance, days))
+		}
+		if deaths+accidents == 0 {
+			continue
+		}
+		sort.SliceStable(c.members, func(i, j int) bool { return c.members[i].Health < c.members[j].Health })
+		for _, human := range c.members[:deaths] {
+			human.IsAlive = false
+			s.Trace.death(state.CurrentDay, human, mortality.recordDeath(human, yield.foodPerPerson))
+		}
+		for _, human := range c.members[deaths : deaths+accidents] {
+			human.IsAlive = false
+			mortality.Accident++
+			s.Trace.death(state.CurrentDay, human, CauseAccident)
+		}
+	}
+
+	crisis, migrants := s.checkCrisis(days, &mortality)
+	delivered := s.deliverDue(days)
+
+	// Conceptions: each cohort of women faces every cohort of men as its
+	// average couple, and the healthiest women who may conceive do so first
+	aliveCount := countAlive(state.Humans)
+	fertility := state.settlementRates().Fertility
+	cohorts = groupCohorts(state.Humans)
+	for _, women := range cohorts {
+		if women.average.Gender != "female" {
+			continue
+		}
+		dailyNoConception := 1.0
+		for _, men := range cohorts {
+			if men.average.Gender != "male" {
+				continue
+			}
+			if chance, eligible := dailyConceptionChance(&men.average, &women.average, aliveCount, fertility); eligible {
+				dailyNoConception *= math.Pow(1-chance, float64(len(men.members)))
+			}
+		}
+		if dailyNoConception >= 1 {
+			continue
+		}
+
+		var open []*MinimalHuman
+		for _, human := range women.members {
+			if human.PregnancyDaysRemaining == 0 {
+				open = append(open, human)
+			}
+		}
+		sort.SliceStable(open, func(i, j int) bool { return open[i].Health > open[j].Health })
+		periodChance := 1 - math.Pow(dailyNoConception, float64(days))
+		for _, female := range open[:s.drawCount(len(open), periodChance)] {
+			s.conceive(female, s.rng.Next()*periodChance, dailyNoConception, days, delivered)
+		}
+	}
+	return s.closePeriod(days, yield, mortality, delivered, crisis, migrants)
+}
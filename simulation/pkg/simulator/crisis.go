@@ -0,0 +1,95 @@
+package simulator
+
+import "math"
+
+// Overcrowding crises strike when a population outgrows the carrying
+// capacity of its region. Rather than starving smoothly, an overcrowded
+// people suffer sudden epidemics, fighting or an exodus.
+const (
+	CrisisChance       = 0.02 // Daily chance of a crisis at double the carrying capacity, proportional to the overshoot
+	CrisisCooldownDays = 180  // Days a region recovers before another crisis can strike
+	EpidemicMortality  = 0.15 // Chance each person dies in an epidemic, doubled for the weak
+	ConflictMortality  = 0.08 // Chance each adult dies when crowding turns to fighting
+)
+
+// Crisis kinds
+const (
+	CrisisEpidemic  = "epidemic"
+	CrisisConflict  = "conflict"
+	CrisisMigration = "migration"
+)
+
+// CrisisRecord describes a crisis that struck during a simulation
+type CrisisRecord struct {
+	Day        int    // Day the crisis struck
+	Kind       string // One of the Crisis* kinds
+	Population int    // Population once it passed
+	Deaths     int    // Deaths on the day it struck, of every cause
+	Migrants   int    // People who left the region
+}
+
+// checkCrisis rolls for an overcrowding crisis over the given number of
+// days and resolves one if it strikes, counting its deaths in mortality. It
+// returns the crisis kind ("" if none) and the number of people who left.
+// Without a carrying capacity the random stream is untouched.
+func (s *Simulation) checkCrisis(days int, mortality *Mortality) (string, int) {
+	state := s.State
+	capacity := s.Conditions.CarryingCapacity
+	population := countAlive(state.Humans)
+	if capacity <= 0 || population <= capacity {
+		return "", 0
+	}
+	if state.LastCrisisDay > 0 && state.CurrentDay-state.LastCrisisDay < CrisisCooldownDays {
+		return "", 0
+	}
+
+	overshoot := float64(population-capacity) / float64(capacity)
+	if !s.rng.NextBool(compoundProbability(math.Min(1, CrisisChance*overshoot), days)) {
+		return "", 0
+	}
+	state.LastCrisisDay = state.CurrentDay
+
+	switch roll := s.rng.Next(); {
+	case roll < 1.0/3:
+		for _, human := range state.Humans {
+			chance := EpidemicMortality
+			if human.Health < HealthPoor {
+				chance *= 2
+			}
+			if human.IsAlive && s.rng.NextBool(chance) {
+				human.IsAlive = false
+				mortality.Disease++
+				s.Trace.death(state.CurrentDay, human, CauseDisease)
+			}
+		}
+		return CrisisEpidemic, 0
+	case roll < 2.0/3:
+		for _, human := range state.Humans {
+			if human.IsAlive && human.Age >= AgeAdult && s.rng.NextBool(ConflictMortality) {
+				human.IsAlive = false
+				mortality.Combat++
+				s.Trace.death(state.CurrentDay, human, CauseCombat)
+			}
+		}
+		return CrisisConflict, 0
+	default:
+		return CrisisMigration, s.emigrate(population - capacity)
+	}
+}
+
+// emigrate sends up to n working-age adults away to seek land elsewhere,
+// leaving expectant mothers behind, and returns how many left
+func (s *Simulation) emigrate(n int) int {
+	remaining := s.State.Humans[:0]
+	left := 0
+	for _, human := range s.State.Humans {
+		if left < n && human.IsAlive && human.Age >= AgeAdult && human.Age < AgeElder && human.PregnancyDaysRemaining == 0 {
+			left++
+			s.Trace.record(s.State.CurrentDay, human, TraceEmigration, "")
+			continue
+		}
+		remaining = append(remaining, human)
+	}
+	s.State.Humans = remaining
+	return left
+}
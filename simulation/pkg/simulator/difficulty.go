@@ -0,0 +1,95 @@
+package simulator
+
+import "math"
+
+// DifficultyKnob is a starting condition the difficulty search turns.
+// Viability is assumed to change monotonically between Min and Max.
+type DifficultyKnob struct {
+	Name  string
+	Min   float64
+	Max   float64
+	Apply func(conditions *StartingConditions, value float64)
+}
+
+// TerrainKnob scales the food the land yields. It is the knob the map
+// generator controls, as the food multiplier of the region a player spawns
+// in (see terrain.AreaMultiplier).
+var TerrainKnob = DifficultyKnob{
+	Name:  "terrain_multiplier",
+	Min:   0.3,
+	Max:   2.0,
+	Apply: func(conditions *StartingConditions, value float64) { conditions.TerrainMultiplier = value },
+}
+
+// Difficulty presets, as the share of seeds that should be viable
+var DifficultyPresets = map[string]float64{
+	"easy":   0.9,
+	"normal": 0.7,
+	"hard":   0.4,
+}
+
+// DifficultySearch describes a search for starting conditions that are
+// viable for a target share of seeds
+type DifficultySearch struct {
+	Base            StartingConditions // Conditions the knob is applied to
+	Knob            DifficultyKnob
+	TargetViability float64 // Share of seeds that should be viable (0-1)
+	Seeds           []int
+	MaxDays         int // Days each run may take (default 10 years)
+	Iterations      int // Bisection steps (default 8)
+}
+
+// DifficultyResult is the best setting a difficulty search found
+type DifficultyResult struct {
+	Conditions StartingConditions
+	Value      float64 // Knob setting
+	Viability  float64 // Share of seeds viable at that setting
+}
+
+// FindDifficulty bisects the knob's range for the setting whose viability
+// rate is closest to the target, returning the closest setting it measured
+func FindDifficulty(search DifficultySearch) DifficultyResult {
+	if search.MaxDays == 0 {
+		search.MaxDays = 10 * DaysPerYear
+	}
+	if search.Iterations == 0 {
+		search.Iterations = 8
+	}
+
+	var best DifficultyResult
+	measured := false
+	measure := func(value float64) float64 {
+		conditions := search.Base
+		search.Knob.Apply(&conditions, value)
+		viability := MeasureBalance(BalanceScenario{Conditions: conditions, MaxDays: search.MaxDays}, search.Seeds)[MetricViabilityRate]
+		if !measured || math.Abs(viability-search.TargetViability) < math.Abs(best.Viability-search.TargetViability) {
+			best = DifficultyResult{Conditions: conditions, Value: value, Viability: viability}
+		}
+		measured = true
+		return viability
+	}
+
+	low, high := search.Knob.Min, search.Knob.Max
+	lowViability, highViability := measure(low), measure(high)
+	rising := highViability >= lowViability
+	for i := 0; i < search.Iterations && best.Viability != search.TargetViability; i++ {
+		middle := (low + high) / 2
+		if (measure(middle) < search.TargetViability) == rising {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return best
+}
+
+// FindDifficultyPresets runs the search once per difficulty preset, each
+// aiming at the preset's viability rate
+func FindDifficultyPresets(search DifficultySearch) map[string]DifficultyResult {
+	presets := make(map[string]DifficultyResult, len(DifficultyPresets))
+	for name, target := range DifficultyPresets {
+		search.TargetViability = target
+		presets[name] = FindDifficulty(search)
+	}
+	return presets
+}
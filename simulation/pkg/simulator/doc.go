@@ -0,0 +1,30 @@
+// Package simulator models a small band of humans day by day (food, health,
+// births, deaths and research) to judge whether a starting position can
+// grow into a civilization. The engine runs one per settlement.
+//
+// # Stable API
+//
+// External tools should use the facade:
+//
+//	result := simulator.Run(simulator.Params{Seed: 1, StartingConditions: simulator.DefaultStartingConditions()})
+//	results := simulator.Sweep(params, seeds)
+//
+// Params and Result are the configuration and outcome of a run. The
+// incremental API the engine uses (NewSimulation, Simulation.StepDay,
+// AdvanceDays, AdvanceAggregated, AdvanceCohorts, Snapshot and Resume) is
+// stable too, as are the metrics sinks, the labor policies, tech trees,
+// Trace and the balance and difficulty tools.
+//
+// # Compatibility
+//
+// APIVersion follows semantic versioning. Within a major version exported
+// identifiers are neither removed nor changed incompatibly; new fields and
+// functions arrive in minor versions. Struct fields are added, so build
+// Params and StartingConditions with field names.
+//
+// Outcomes are not part of the guarantee. The tuning constants (such as
+// FoodRequiredPerPerson) and the mechanics change as the game is balanced,
+// so a seed may play out differently between versions; such changes are
+// pinned by the balance goldens in testdata/balance. Snapshots carry their
+// own SnapshotVersion and only resume in a matching build.
+package simulator
@@ -0,0 +1,453 @@
+package simulator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// DaysPerYear is the number of simulator days in one engine year tick
+const DaysPerYear = models.DaysPerYear
+
+// Simulation is a civilization that can be advanced incrementally, either one
+// day at a time (full fidelity) or in aggregated multi-day steps (fast path).
+// The engine keeps one Simulation per settlement and advances it each year tick.
+type Simulation struct {
+	State      *MinimalCivilizationState
+	Conditions StartingConditions
+	Trace      *Trace // Life events of each human, recorded when set
+	Policy     Policy // Decides the labor allocation as the run goes, when set
+	rng        *randomGenerator
+}
+
+// NewSimulation creates a simulation with a freshly initialized population
+func NewSimulation(conditions StartingConditions, seed int) *Simulation {
+	rng := newRandomGenerator(seed)
+
+	// Initialize population
+	humans := initializePopulation(conditions, rng)
+
+	return &Simulation{
+		State: &MinimalCivilizationState{
+			Humans:              humans,
+			FoodStockpile:       conditions.FoodStockpile,
+			SciencePoints:       0,
+			FoodAllocationRatio: conditions.FoodAllocationRatio,
+			HasFireMastery:      false,
+			CurrentDay:          0,
+		},
+		Conditions: conditions,
+		rng:        rng,
+	}
+}
+
+// Population returns the number of living humans
+func (s *Simulation) Population() int {
+	return countAlive(s.State.Humans)
+}
+
+// Technologies lists the technologies the civilization has unlocked
+func (s *Simulation) Technologies() []string {
+	return technologies(s.State)
+}
+
+// SetTechTree has the civilization research the technologies of tree
+// instead of the default tree. A tree is not saved in snapshots, so set it
+// again on a resumed simulation.
+func (s *Simulation) SetTechTree(tree *TechTree) {
+	s.State.tree = tree
+}
+
+// SetRates has the civilization live by the design rates scaled by rates,
+// as a game's ruleset sets them. Rates are not saved in snapshots, so set
+// them again on a resumed simulation.
+func (s *Simulation) SetRates(rates *models.SettlementRates) {
+	s.State.rates = rates
+}
+
+// ResearchGoals lists the technologies the civilization can research next
+// and the science each needs
+func (s *Simulation) ResearchGoals() []ResearchGoal {
+	return researchGoals(s.State, s.Conditions)
+}
+
+// AddScience grants science points at once, unlocking every technology
+// they complete, as when a great scientist shares their learning
+func (s *Simulation) AddScience(points float64) {
+	s.State.SciencePoints += points
+	for checkTechnologyUnlock(s.State, s.Conditions) {
+	}
+}
+
+// RestoreTechnologies marks previously unlocked technologies as known, so a
+// simulation rebuilt from a persisted settlement keeps what its people learned
+func (s *Simulation) RestoreTechnologies(techs []string) {
+	for _, tech := range techs {
+		unlockTechnology(s.State, tech)
+	}
+}
+
+// foodMultiplier combines the terrain's food yield with any tamed herds and
+// trade, less what unrest skims off
+func (s *Simulation) foodMultiplier() float64 {
+	return s.Conditions.TerrainMultiplier * herdFoodMultiplier(s.State, s.Conditions.Livestock) * (1 + s.Conditions.Trade) * (1 - s.Conditions.Unrest)
+}
+
+// availableLabor totals the work hours of adults and any children put to work
+func (s *Simulation) availableLabor() float64 {
+	return calculateAvailableLabor(s.State.Humans) + calculateChildLabor(s.State.Humans, s.Conditions.ChildLabor, s.State)
+}
+
+// draftLabor splits work hours into those left to the settlement and those
+// drafted for public works
+func (s *Simulation) draftLabor(hours float64) (float64, float64) {
+	drafted := hours * s.Conditions.Corvee
+	return hours - drafted, drafted
+}
+
+// taxFood splits food produced into what the settlement keeps and what is
+// taken as tax
+func (s *Simulation) taxFood(food float64) (float64, float64) {
+	taxed := food * s.Conditions.Tax
+	return food - taxed, taxed
+}
+
+// checkAccident rolls for a working child dying in an accident over the
+// given number of days, leaving the random stream untouched when no children work
+func (s *Simulation) checkAccident(human *MinimalHuman, days int) bool {
+	chance := childLaborAccidentChance(human, s.Conditions.ChildLabor, s.State)
+	if chance <= 0 {
+		return false
+	}
+	if s.rng.NextBool(compoundProbability(chance, days)) {
+		human.IsAlive = false
+		return true
+	}
+	return false
+}
+
+// StepDay advances the simulation by a single day and returns that day's metrics
+func (s *Simulation) StepDay() *DailyMetrics {
+	state := s.State
+	rng := s.rng
+	state.CurrentDay++
+
+	// Step 1: Calculate available labor, less any drafted for public works
+	totalWorkHours, corveeHours := s.draftLabor(s.availableLabor())
+
+	// Step 2: Allocate labor to food/science
+	foodHours, scienceHours := allocateLabor(totalWorkHours, s.allocate())
+
+	// Step 3: Produce food and science
+	avgHealth := calculateAverageHealth(state.Humans)
+	population := countAlive(state.Humans)
+
+	foodProduced, foodTaxed := s.taxFood(produceFood(foodHours, knownEffects(state).food, s.foodMultiplier()))
+	scienceProduced := produceScience(scienceHours, population, avgHealth) * elderWisdomMultiplier(state.Humans) * knownEffects(state).science
+
+	state.FoodStockpile += foodProduced
+	state.SciencePoints += scienceProduced
+
+	// Step 4: Consume food
+	remainingFood, foodPerPerson := consumeFood(state.Humans, state.FoodStockpile)
+	state.FoodStockpile = remainingFood
+
+	// Step 5: Update health based on nutrition
+	environment := environmentHealth(state.CurrentDay, population, s.Conditions)
+	for _, human := range state.Humans {
+		updateHealth(human, foodPerPerson)
+		adjustHealth(human, environment+childLaborHealth(human, s.Conditions.ChildLabor))
+	}
+
+	// Step 6: Age all humans
+	ageHumans(state.Humans)
+
+	// Step 7: Process mortality checks
+	var mortality Mortality
+	for _, human := range state.Humans {
+		if checkMortality(human, state, rng) {
+			s.Trace.death(state.CurrentDay, human, mortality.recordDeath(human, foodPerPerson))
+		} else if s.checkAccident(human, 1) {
+			mortality.Accident++
+			s.Trace.death(state.CurrentDay, human, CauseAccident)
+		}
+	}
+
+	// Step 7b: Overcrowding may bring a crisis
+	crisis, migrants := s.checkCrisis(1, &mortality)
+
+	// Step 8: Process pregnancies (decrement counters and handle births)
+	delivered := processPregnancies(state.Humans, state, rng)
+	births := len(delivered.newborns)
+	mortality.Childbirth += len(delivered.mothersLost)
+	state.Humans = append(state.Humans, delivered.newborns...)
+	s.Trace.deliveries(state.CurrentDay, delivered)
+
+	// Step 9: Attempt new conceptions
+	attemptReproduction(state.Humans, state.settlementRates().Fertility, rng)
+	s.Trace.observe(state.CurrentDay, state.Humans)
+
+	// Step 10: Check for technology unlocks
+	checkTechnologyUnlock(state, s.Conditions)
+
+	// Step 11: Record metrics
+	return &DailyMetrics{
+		Day:               state.CurrentDay,
+		Population:        countAlive(state.Humans),
+		AverageHealth:     calculateAverageHealth(state.Humans),
+		FoodStockpile:     state.FoodStockpile,
+		SciencePoints:     state.SciencePoints,
+		FoodProduction:    foodProduced,
+		ScienceProduction: scienceProduced,
+		FoodTaxed:         foodTaxed,
+		CorveeHours:       corveeHours,
+		Births:            births,
+		Twins:             delivered.twins,
+		Crisis:            crisis,
+		Migrants:          migrants,
+		Deaths:            mortality.Total(),
+		Mortality:         mortality,
+		HasFireMastery:    state.HasFireMastery,
+	}
+}
+
+// KillInCombat kills up to n adults defending the settlement, the fittest
+// first as they stand in the front line, and returns how many died
+func (s *Simulation) KillInCombat(n int) int {
+	var defenders []*MinimalHuman
+	for _, human := range s.State.Humans {
+		if human.IsAlive && human.Age >= AgeAdult {
+			defenders = append(defenders, human)
+		}
+	}
+	sort.SliceStable(defenders, func(i, j int) bool {
+		return defenders[i].Health > defenders[j].Health
+	})
+	killed := min(n, len(defenders))
+	for _, human := range defenders[:max(killed, 0)] {
+		human.IsAlive = false
+		s.Trace.death(s.State.CurrentDay, human, CauseCombat)
+	}
+	return max(killed, 0)
+}
+
+// AdvanceDays runs the given number of daily steps and returns the summed period metrics
+func (s *Simulation) AdvanceDays(days int) *DailyMetrics {
+	period := &DailyMetrics{}
+	for i := 0; i < days; i++ {
+		day := s.StepDay()
+		period.FoodProduction += day.FoodProduction
+		period.ScienceProduction += day.ScienceProduction
+		period.FoodTaxed += day.FoodTaxed
+		period.CorveeHours += day.CorveeHours
+		period.Births += day.Births
+		period.Twins += day.Twins
+		period.Migrants += day.Migrants
+		if day.Crisis != "" {
+			period.Crisis = day.Crisis
+		}
+		period.Deaths += day.Deaths
+		period.Mortality.Add(day.Mortality)
+	}
+	s.fillPeriodSnapshot(period)
+	return period
+}
+
+// AdvanceAggregated advances the simulation by the given number of days in a
+// single aggregated step. Production and consumption use the period's
+// starting conditions, and each human rolls once per period for death and
+// conception using the compounded daily probabilities. This is roughly
+// `days` times cheaper than AdvanceDays at the cost of intra-period feedback.
+func (s *Simulation) AdvanceAggregated(days int) *DailyMetrics {
+	state := s.State
+	rng := s.rng
+	if days <= 0 {
+		return s.AdvanceDays(0)
+	}
+	state.CurrentDay += days
+	yield := s.producePeriod(days)
+
+	// Health and ageing evolve day by day (cheap, no randomness)
+	firstDay := state.CurrentDay - days + 1
+	for _, human := range state.Humans {
+		strain := childLaborHealth(human, s.Conditions.ChildLabor)
+		for d := 0; d < days; d++ {
+			updateHealth(human, yield.foodPerPerson)
+			adjustHealth(human, environmentHealth(firstDay+d, yield.population, s.Conditions)+strain)
+		}
+		if human.IsAlive {
+			human.Age += AgeIncrementPerDay * float64(days)
+		}
+	}
+
+	// Mortality: one roll per human for the whole period
+	var mortality Mortality
+	for _, human := range state.Humans {
+		if !human.IsAlive {
+			continue
+		}
+		if rng.NextBool(compoundProbability(dailyMortalityChance(human, state), days)) {
+			human.IsAlive = false
+			s.Trace.death(state.CurrentDay, human, mortality.recordDeath(human, yield.foodPerPerson))
+		} else if s.checkAccident(human, days) {
+			mortality.Accident++
+			s.Trace.death(state.CurrentDay, human, CauseAccident)
+		}
+	}
+
+	crisis, migrants := s.checkCrisis(days, &mortality)
+	delivered := s.deliverDue(days)
+
+	// Conceptions: the daily model lets each female roll against every
+	// eligible male, so the daily no-conception chance is the product over
+	// all partners, compounded across the period
+	aliveCount := countAlive(state.Humans)
+	fertility := state.settlementRates().Fertility
+	var males, females []*MinimalHuman
+	for _, h := range state.Humans {
+		if !h.IsAlive {
+			continue
+		}
+		if h.Gender == "male" {
+			males = append(males, h)
+		} else {
+			females = append(females, h)
+		}
+	}
+	for _, female := range females {
+		dailyNoConception := 1.0
+		for _, male := range males {
+			if chance, eligible := dailyConceptionChance(male, female, aliveCount, fertility); eligible {
+				dailyNoConception *= 1 - chance
+			}
+		}
+		if dailyNoConception >= 1 {
+			continue
+		}
+
+		periodChance := 1 - math.Pow(dailyNoConception, float64(days))
+		u := rng.Next()
+		if u >= periodChance {
+			continue
+		}
+		s.conceive(female, u, dailyNoConception, days, delivered)
+	}
+	return s.closePeriod(days, yield, mortality, delivered, crisis, migrants)
+}
+
+// periodYield is what a settlement's people produce and eat over a period
+type periodYield struct {
+	food, taxed, science float64 // Food kept, food taxed and science produced
+	corveeHours          float64 // Work hours drafted for public works
+	foodPerPerson        float64 // Daily ration of each food share
+	population           int     // Living humans at the period's start
+}
+
+// producePeriod produces and rations a period's food and science at the
+// period's starting labor force
+func (s *Simulation) producePeriod(days int) periodYield {
+	state := s.State
+	totalWorkHours, corveeHours := s.draftLabor(s.availableLabor())
+	foodHours, scienceHours := allocateLabor(totalWorkHours, s.allocate())
+	avgHealth := calculateAverageHealth(state.Humans)
+	yield := periodYield{corveeHours: corveeHours * float64(days), population: countAlive(state.Humans)}
+
+	yield.food, yield.taxed = s.taxFood(produceFood(foodHours, knownEffects(state).food, s.foodMultiplier()) * float64(days))
+	yield.science = produceScience(scienceHours, yield.population, avgHealth) * elderWisdomMultiplier(state.Humans) * knownEffects(state).science * float64(days)
+	state.FoodStockpile += yield.food
+	state.SciencePoints += yield.science
+
+	// Consumption: ration the period's food evenly across days
+	if shares := foodShares(state.Humans); shares > 0 {
+		required := shares * FoodRequiredPerPerson * float64(days)
+		consumed := math.Min(state.FoodStockpile, required)
+		state.FoodStockpile -= consumed
+		yield.foodPerPerson = consumed / shares / float64(days)
+	}
+	return yield
+}
+
+// deliverDue delivers the pregnancies that come to term within a period
+func (s *Simulation) deliverDue(days int) *deliveries {
+	delivered := &deliveries{}
+	for _, human := range s.State.Humans {
+		if !human.IsAlive || human.Gender != "female" || human.PregnancyDaysRemaining <= 0 {
+			continue
+		}
+		human.PregnancyDaysRemaining -= days
+		if human.PregnancyDaysRemaining <= 0 {
+			human.PregnancyDaysRemaining = 0
+			delivered.deliverBirth(human, s.State, s.rng)
+		}
+	}
+	return delivered
+}
+
+// conceive starts a female's pregnancy within a period, given the roll u
+// under the period's conception chance that made it. The conception day
+// follows a geometric distribution truncated to the period; early
+// conceptions in a long period deliver before it ends.
+func (s *Simulation) conceive(female *MinimalHuman, u, dailyNoConception float64, days int, delivered *deliveries) {
+	conceivedDay := 0
+	if dailyNoConception > 0 {
+		conceivedDay = int(math.Log(1-u) / math.Log(dailyNoConception))
+		if conceivedDay >= days {
+			conceivedDay = days - 1
+		}
+	}
+	female.PregnancyDaysRemaining = GestationPeriod - (days - conceivedDay)
+	if female.PregnancyDaysRemaining <= 0 {
+		female.PregnancyDaysRemaining = 0
+		delivered.deliverBirth(female, s.State, s.rng)
+	}
+}
+
+// closePeriod adds a period's newborns, checks for new technologies and
+// returns the period's metrics
+func (s *Simulation) closePeriod(days int, yield periodYield, mortality Mortality, delivered *deliveries, crisis string, migrants int) *DailyMetrics {
+	state := s.State
+	mortality.Childbirth += len(delivered.mothersLost)
+	state.Humans = append(state.Humans, delivered.newborns...)
+	s.Trace.deliveries(state.CurrentDay, delivered)
+	s.Trace.observe(state.CurrentDay, state.Humans)
+
+	checkTechnologyUnlock(state, s.Conditions)
+
+	period := &DailyMetrics{
+		FoodProduction:    yield.food,
+		ScienceProduction: yield.science,
+		FoodTaxed:         yield.taxed,
+		CorveeHours:       yield.corveeHours,
+		Births:            len(delivered.newborns),
+		Twins:             delivered.twins,
+		Crisis:            crisis,
+		Migrants:          migrants,
+		Deaths:            mortality.Total(),
+		Mortality:         mortality,
+	}
+	s.fillPeriodSnapshot(period)
+	return period
+}
+
+// fillPeriodSnapshot records end-of-period state on period metrics
+func (s *Simulation) fillPeriodSnapshot(period *DailyMetrics) {
+	period.Day = s.State.CurrentDay
+	period.Population = countAlive(s.State.Humans)
+	period.AverageHealth = calculateAverageHealth(s.State.Humans)
+	period.FoodStockpile = s.State.FoodStockpile
+	period.SciencePoints = s.State.SciencePoints
+	period.HasFireMastery = s.State.HasFireMastery
+}
+
+// compoundProbability converts a daily probability into the probability of at
+// least one occurrence over the given number of days
+func compoundProbability(daily float64, days int) float64 {
+	if daily <= 0 {
+		return 0
+	}
+	if daily >= 1 {
+		return 1
+	}
+	return 1 - math.Pow(1-daily, float64(days))
+}
@@ -3,6 +3,8 @@ package simulator
 import (
 	"fmt"
 	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
 )
 
 // Constants from the design document
@@ -12,6 +14,7 @@ const (
 	AgeAdult  = 15.0
 	AgeFertileMin = 13.0  // Per design doc (HUMAN_ATTRIBUTES.md line 611)
 	AgeFertileMax = 45.0
+	AgeElder = 45.0 // Elders work half days and teach instead
 
 	// Work capacity
 	WorkHoursFull = 8.0
@@ -19,6 +22,20 @@ const (
 	HealthFullWork = 50.0
 	HealthHalfWork = 30.0
 
+	// Child labor: children old enough to gather or apprentice help a little,
+	// at a cost to their health and a risk of accidents
+	AgeChildLaborMin = 10.0
+	WorkHoursChild = 2.0 // Gathering and apprenticeship
+	WorkHoursChildHerding = 3.0 // Tending tamed herds once domesticated
+	ChildLaborHealthCost = 0.3 // Daily health a working child loses to strain
+	ChildLaborAccidentRisk = 0.004 / DaysPerMonth // Extra daily death chance for a working child
+	HusbandryAccidentFactor = 0.5 // Managed pastures halve the accident risk
+
+	// Elder wisdom: healthy elders pass on what they know, raising science
+	// in proportion to their share of the population
+	ElderWisdomBonus = 2.0 // Science multiplier per unit share of teaching elders (10% elders = +20%)
+	ElderTeachingHealth = 50.0 // Elders below this health are too frail to teach
+
 	// Food production
 	FoodBaseRate = 1.0 // Food units per hour (viability threshold found via testing)
 	FireMasteryFoodBonus = 1.15 // +15% from cooking
@@ -37,6 +54,13 @@ const (
 	HealthAgeDivisor = 30.0
 	HealthAgeMultiplier = 5.0
 
+	// Winter shelter
+	WinterDays = models.WinterDays // The first days of each year are winter
+	WinterShelterHealth = 0.5 // Daily health gained in winter by those sheltered
+
+	// Environment
+	PollutionHealthDamage = 1.0 // Daily health lost in fully polluted surroundings
+
 	// Age progression
 	AgeIncrementPerDay = 1.0 / 365.0 // 1 year / 365 days
 
@@ -62,8 +86,41 @@ const (
 	InfantSurvivalRate = 0.7 // 70% survival at birth
 	GestationPeriod = 280 // Approximately 9 months in days
 
+	// Pregnancy and childbirth
+	LateTermDays = 90 // Mothers in the last trimester work half days
+	PregnancyFoodShare = 0.25 // Extra share of a person's food a pregnant mother needs
+	MaternalMortality = 0.01 // Chance a healthy mother dies giving birth
+	MidwiferyMaternalFactor = 0.5 // Midwives halve the risk
+	TwinsChance = 0.015 // Chance a birth brings twins
+
 	// Technology unlock
 	FireMasteryScienceRequired = 100.0
+	DomesticationScienceRequired = 250.0
+	HusbandryScienceRequired = 500.0
+	MidwiferyScienceRequired = 300.0
+	ShelterBuildingScienceRequired = 350.0
+	HerbalMedicineScienceRequired = 700.0
+
+	// Childhood: built shelters and herbal remedies save young lives
+	ShelterInfantSurvival = 0.1 // Added to the infant survival rate at birth
+	HerbalInfantSurvival = 0.1 // Added again once herbal medicine is known
+	ShelterChildMortalityFactor = 0.75 // Mortality of children under 15 in built shelters
+	HerbalChildMortalityFactor = 0.5 // Mortality of children under 15 with herbal medicine
+
+	// Livestock
+	HerdFoodBonus = 0.03 // Food per nearby herd once domesticated
+	PastureFoodBonus = 0.06 // Food per nearby herd once husbandry manages it
+	MaxHerds = 5 // Herds a settlement can tend
+)
+
+// Technology names, in the order they unlock
+const (
+	TechFireMastery     = "fire_mastery"
+	TechDomestication   = "domestication"
+	TechHusbandry       = "husbandry"
+	TechMidwifery       = "midwifery"
+	TechShelterBuilding = "shelter_building"
+	TechHerbalMedicine  = "herbal_medicine"
 )
 
 // calculateAvailableLabor calculates total work hours available from the population
@@ -80,8 +137,9 @@ func calculateAvailableLabor(humans []*MinimalHuman) float64 {
 			continue
 		}
 
-		// Work capacity based on health
-		if human.Health >= HealthFullWork {
+		// Work capacity based on health; elders keep to half days and
+		// teach, and mothers near term rest
+		if human.Health >= HealthFullWork && human.Age < AgeElder && !isLateTerm(human) {
 			totalWorkHours += WorkHoursFull // Full day of work
 		} else if human.Health >= HealthHalfWork {
 			totalWorkHours += WorkHoursHalf // Half day (weakened)
@@ -92,6 +150,74 @@ func calculateAvailableLabor(humans []*MinimalHuman) float64 {
 	return totalWorkHours
 }
 
+// isLateTerm reports whether a human is in the last trimester of pregnancy
+func isLateTerm(human *MinimalHuman) bool {
+	return human.PregnancyDaysRemaining > 0 && human.PregnancyDaysRemaining <= LateTermDays
+}
+
+// elderWisdomMultiplier returns the science multiplier from healthy elders
+// teaching the young, so populations whose elders survive research faster
+func elderWisdomMultiplier(humans []*MinimalHuman) float64 {
+	alive, teachers := 0, 0
+	for _, human := range humans {
+		if !human.IsAlive {
+			continue
+		}
+		alive++
+		if human.Age >= AgeElder && human.Health >= ElderTeachingHealth {
+			teachers++
+		}
+	}
+	if alive == 0 {
+		return 1.0
+	}
+	return 1 + ElderWisdomBonus*float64(teachers)/float64(alive)
+}
+
+// isLaborChild reports whether a living human is old enough to be put to work
+// as a child
+func isLaborChild(human *MinimalHuman) bool {
+	return human.IsAlive && human.Age >= AgeChildLaborMin && human.Age < AgeAdult
+}
+
+// childWorkHours returns a working child's day: longer once there are herds to tend
+func childWorkHours(state *MinimalCivilizationState) float64 {
+	return knownEffects(state).childWorkHours
+}
+
+// calculateChildLabor calculates work hours from children aged 10-15 when a
+// share of them (0-1) are put to work. Children too weak for half a day's
+// work stay home.
+func calculateChildLabor(humans []*MinimalHuman, share float64, state *MinimalCivilizationState) float64 {
+	if share <= 0 {
+		return 0
+	}
+	workers := 0
+	for _, human := range humans {
+		if isLaborChild(human) && human.Health >= HealthHalfWork {
+			workers++
+		}
+	}
+	return float64(workers) * childWorkHours(state) * math.Min(1, share)
+}
+
+// childLaborAccidentChance returns the extra daily chance a child put to work
+// with the given share dies in an accident
+func childLaborAccidentChance(human *MinimalHuman, share float64, state *MinimalCivilizationState) float64 {
+	if share <= 0 || !isLaborChild(human) {
+		return 0
+	}
+	return ChildLaborAccidentRisk * math.Min(1, share) * knownEffects(state).childAccident
+}
+
+// childLaborHealth returns the daily health a working child loses to strain
+func childLaborHealth(human *MinimalHuman, share float64) float64 {
+	if share <= 0 || !isLaborChild(human) {
+		return 0
+	}
+	return -ChildLaborHealthCost * math.Min(1, share)
+}
+
 // allocateLabor divides labor between food and science production
 func allocateLabor(totalWorkHours, foodRatio float64) (foodHours, scienceHours float64) {
 	foodHours = totalWorkHours * foodRatio
@@ -99,14 +225,10 @@ func allocateLabor(totalWorkHours, foodRatio float64) (foodHours, scienceHours f
 	return
 }
 
-// produceFood calculates food production for the day
-func produceFood(foodHours float64, hasFireMastery bool, terrainMultiplier float64) float64 {
-	multiplier := 1.0
-	if hasFireMastery {
-		multiplier = FireMasteryFoodBonus
-	}
-
-	return foodHours * FoodBaseRate * multiplier * terrainMultiplier
+// produceFood calculates food production for the day, multiplied by the
+// known technologies and the terrain
+func produceFood(foodHours, techMultiplier, terrainMultiplier float64) float64 {
+	return foodHours * FoodBaseRate * techMultiplier * terrainMultiplier
 }
 
 // produceScience calculates science production for the day
@@ -132,25 +254,37 @@ func produceScience(scienceHours float64, population int, averageHealth float64)
 	return scienceHours * ScienceBaseRate * multiplier
 }
 // consumeFood distributes available food among the population
+// Pregnant mothers need a larger share, so foodPerPerson is what a person who
+// is not pregnant eats; mothers get the same fraction of their greater need.
 func consumeFood(humans []*MinimalHuman, foodStockpile float64) (remainingFood, foodPerPerson float64) {
-	aliveHumans := 0
-	for _, h := range humans {
-		if h.IsAlive {
-			aliveHumans++
-		}
-	}
-
-	if aliveHumans == 0 {
+	shares := foodShares(humans)
+	if shares == 0 {
 		return foodStockpile, 0
 	}
 
-	totalRequired := float64(aliveHumans) * FoodRequiredPerPerson
+	totalRequired := shares * FoodRequiredPerPerson
 	actualConsumption := math.Min(foodStockpile, totalRequired)
-	foodPerPerson = actualConsumption / float64(aliveHumans)
+	foodPerPerson = actualConsumption / shares
 
 	return foodStockpile - actualConsumption, foodPerPerson
 }
 
+// foodShares counts the living in person-sized food shares, with each
+// pregnant mother needing extra
+func foodShares(humans []*MinimalHuman) float64 {
+	shares := 0.0
+	for _, h := range humans {
+		if !h.IsAlive {
+			continue
+		}
+		shares++
+		if h.PregnancyDaysRemaining > 0 {
+			shares += PregnancyFoodShare
+		}
+	}
+	return shares
+}
+
 // updateHealth updates a human's health based on nutrition
 func updateHealth(human *MinimalHuman, foodPerPerson float64) {
 	if !human.IsAlive {
@@ -173,6 +307,34 @@ func updateHealth(human *MinimalHuman, foodPerPerson float64) {
 	human.Health = math.Max(0, math.Min(100, human.Health+healthChange))
 }
 
+// isWinterDay reports whether a simulation day (counted from 1) falls in
+// winter by the game calendar
+func isWinterDay(day int) bool {
+	return models.CalendarAt(0, day).IsWinter()
+}
+
+// shelterHealth returns the health each living human gains on the given day
+// from shelter for capacity people, shared out across the population
+func shelterHealth(day, population, capacity int) float64 {
+	if capacity <= 0 || population == 0 || !isWinterDay(day) {
+		return 0
+	}
+	return WinterShelterHealth * math.Min(1, float64(capacity)/float64(population))
+}
+
+// environmentHealth returns the day's net health change from the
+// surroundings: winter shelter less pollution damage
+func environmentHealth(day, population int, conditions StartingConditions) float64 {
+	return shelterHealth(day, population, conditions.ShelterCapacity) - conditions.Pollution*PollutionHealthDamage
+}
+
+// adjustHealth applies a health change to a living human, within [0, 100]
+func adjustHealth(human *MinimalHuman, change float64) {
+	if human.IsAlive && change != 0 {
+		human.Health = math.Max(0, math.Min(100, human.Health+change))
+	}
+}
+
 // ageHumans increments the age of all living humans
 func ageHumans(humans []*MinimalHuman) {
 	for _, human := range humans {
@@ -183,11 +345,23 @@ func ageHumans(humans []*MinimalHuman) {
 }
 
 // checkMortality checks if a human dies this day
-func checkMortality(human *MinimalHuman, rng *RandomGenerator) bool {
+func checkMortality(human *MinimalHuman, state *MinimalCivilizationState, rng *randomGenerator) bool {
 	if !human.IsAlive {
 		return false
 	}
 
+	// Roll for death
+	if rng.NextBool(dailyMortalityChance(human, state)) {
+		human.IsAlive = false
+		return true
+	}
+
+	return false
+}
+
+// dailyMortalityChance returns a human's chance of dying on a single day,
+// lower for children once their people know how to protect them
+func dailyMortalityChance(human *MinimalHuman, state *MinimalCivilizationState) float64 {
 	// Base mortality rate by age (daily)
 	var dailyDeathChance float64
 	switch {
@@ -207,53 +381,102 @@ func checkMortality(human *MinimalHuman, rng *RandomGenerator) bool {
 		dailyDeathChance = MortalityElder
 	}
 
-	// Health modifiers
+	if human.Age < AgeChild {
+		dailyDeathChance *= childMortalityFactor(state)
+	}
+
+	return dailyDeathChance * healthMortalityModifier(human.Health) * state.settlementRates().Mortality
+}
+
+// childMortalityFactor scales the mortality of children under 15 by the
+// practices that protect them
+func childMortalityFactor(state *MinimalCivilizationState) float64 {
+	return knownEffects(state).childMortality
+}
+
+// infantSurvivalRate returns the chance a newborn survives birth
+func infantSurvivalRate(state *MinimalCivilizationState) float64 {
+	return knownEffects(state).infantSurvival
+}
+
+// healthMortalityModifier scales a death chance by how healthy a human is
+func healthMortalityModifier(health float64) float64 {
 	switch {
-	case human.Health > HealthExcellent:
-		dailyDeathChance *= 0.5
-	case human.Health < HealthGood && human.Health >= HealthPoor:
-		dailyDeathChance *= 1.5
-	case human.Health < HealthPoor && human.Health >= HealthCritical:
-		dailyDeathChance *= 3.0
-	case human.Health < HealthCritical:
-		dailyDeathChance *= 10.0
+	case health > HealthExcellent:
+		return 0.5
+	case health < HealthGood && health >= HealthPoor:
+		return 1.5
+	case health < HealthPoor && health >= HealthCritical:
+		return 3.0
+	case health < HealthCritical:
+		return 10.0
 	}
+	return 1.0
+}
 
-	// Roll for death
-	if rng.NextBool(dailyDeathChance) {
-		human.IsAlive = false
+// recordDeath counts a death against the cause that most plausibly took the
+// human, and returns it: hunger if they were starving and weak, old age for
+// elders, and otherwise disease
+func (m *Mortality) recordDeath(human *MinimalHuman, foodPerPerson float64) string {
+	switch {
+	case foodPerPerson < FoodRequiredPerPerson && human.Health < HealthPoor:
+		m.Starvation++
+		return CauseStarvation
+	case human.Age >= AgeElder:
+		m.Age++
+		return CauseAge
+	default:
+		m.Disease++
+		return CauseDisease
+	}
+}
+
+// checkReproduction checks if a male and female can conceive a child, at
+// the given scale of the design fertility
+// Returns true if conception occurred (pregnancy started)
+func checkReproduction(male, female *MinimalHuman, population int, fertility float64, rng *randomGenerator) bool {
+	finalChance, eligible := dailyConceptionChance(male, female, population, fertility)
+	if !eligible {
+		return false
+	}
+
+	// Roll for conception
+	if rng.NextBool(finalChance) {
+		// Start pregnancy
+		female.PregnancyDaysRemaining = GestationPeriod
 		return true
 	}
 
 	return false
 }
 
-// checkReproduction checks if a male and female can conceive a child
-// Returns true if conception occurred (pregnancy started)
-func checkReproduction(male, female *MinimalHuman, population int, rng *RandomGenerator) bool {
+// dailyConceptionChance returns the daily conception chance for a couple at
+// the given scale of the design fertility, and whether the couple is
+// eligible to conceive at all
+func dailyConceptionChance(male, female *MinimalHuman, population int, fertility float64) (float64, bool) {
 	// Prerequisites
 	if !male.IsAlive || !female.IsAlive {
-		return false
+		return 0, false
 	}
 	if male.Age < AgeFertileMin || male.Age > AgeFertileMax {
-		return false
+		return 0, false
 	}
 	if female.Age < AgeFertileMin || female.Age > AgeFertileMax {
-		return false
+		return 0, false
 	}
 	if male.Health < HealthFullWork || female.Health < HealthFullWork {
-		return false
+		return 0, false
 	}
 	
 	// Check if female is already pregnant
 	if female.PregnancyDaysRemaining > 0 {
-		return false
+		return 0, false
 	}
 
 	// Calculate simplified belonging
 	belonging := math.Min(50.0, float64(population)/2.0)
 	if belonging < BelongingThreshold {
-		return false
+		return 0, false
 	}
 
 	// Calculate conception chance
@@ -276,20 +499,12 @@ func checkReproduction(male, female *MinimalHuman, population int, rng *RandomGe
 		modifiers *= 0.2
 	}
 
-	finalChance := MonthlyConceptionBase * math.Max(0, modifiers)
-
-	// Roll for conception
-	if rng.NextBool(finalChance) {
-		// Start pregnancy
-		female.PregnancyDaysRemaining = GestationPeriod
-		return true
-	}
-
-	return false
+	return MonthlyConceptionBase * fertility * math.Max(0, modifiers), true
 }
 
-// attemptReproduction tries to start pregnancies for eligible females
-func attemptReproduction(humans []*MinimalHuman, rng *RandomGenerator) int {
+// attemptReproduction tries to start pregnancies for eligible females, at
+// the given scale of the design fertility
+func attemptReproduction(humans []*MinimalHuman, fertility float64, rng *randomGenerator) int {
 	conceptions := 0
 
 	// Count alive population
@@ -316,7 +531,7 @@ func attemptReproduction(humans []*MinimalHuman, rng *RandomGenerator) int {
 	// Try to pair each eligible female with an eligible male
 	for _, female := range females {
 		for _, male := range males {
-			if checkReproduction(male, female, aliveCount, rng) {
+			if checkReproduction(male, female, aliveCount, fertility, rng) {
 				conceptions++
 				break // Each female can only conceive once per check
 			}
@@ -326,9 +541,18 @@ func attemptReproduction(humans []*MinimalHuman, rng *RandomGenerator) int {
 	return conceptions
 }
 
-// processPregnancies decrements pregnancy counters and creates babies when pregnancy completes
-func processPregnancies(humans []*MinimalHuman, rng *RandomGenerator) []*MinimalHuman {
-	newborns := []*MinimalHuman{}
+// deliveries records the outcome of the pregnancies completed in a period
+type deliveries struct {
+	newborns       []*MinimalHuman
+	newbornMothers []*MinimalHuman // Mother of each newborn
+	mothers        []*MinimalHuman // Mothers who gave birth, stillbirths included
+	twins          int             // Births that brought twins
+	mothersLost    []*MinimalHuman // Mothers who died giving birth
+}
+
+// processPregnancies decrements pregnancy counters and delivers babies when pregnancy completes
+func processPregnancies(humans []*MinimalHuman, state *MinimalCivilizationState, rng *randomGenerator) *deliveries {
+	delivered := &deliveries{newborns: []*MinimalHuman{}}
 
 	for _, human := range humans {
 		if !human.IsAlive || human.Gender != "female" {
@@ -340,41 +564,131 @@ func processPregnancies(humans []*MinimalHuman, rng *RandomGenerator) []*Minimal
 
 			// Check if pregnancy completed
 			if human.PregnancyDaysRemaining == 0 {
-				// Birth occurs
-				childHealth := human.Health * 0.8 // Child starts at 80% of mother's health
-
-				// 70% infant survival rate at birth
-				if rng.NextBool(InfantSurvivalRate) {
-					child := &MinimalHuman{
-						ID:                     generateID(rng),
-						Age:                    0,
-						Gender:                 "male",
-						Health:                 childHealth,
-						IsAlive:                true,
-						PregnancyDaysRemaining: 0,
-					}
-					if rng.NextBool(0.5) {
-						child.Gender = "female"
-					}
-					newborns = append(newborns, child)
-				}
-				// If not successful, it's stillborn/infant mortality
+				delivered.deliverBirth(human, state, rng)
 			}
 		}
 	}
 
-	return newborns
+	return delivered
 }
 
-// checkTechnologyUnlock checks if Fire Mastery should be unlocked
-func checkTechnologyUnlock(state *MinimalCivilizationState) bool {
-	if !state.HasFireMastery && state.SciencePoints >= FireMasteryScienceRequired {
-		state.HasFireMastery = true
-		return true
+// deliverBirth resolves a completed pregnancy: one baby or sometimes twins,
+// each of whom may not survive, and a risk to the mother that midwives reduce
+func (d *deliveries) deliverBirth(mother *MinimalHuman, state *MinimalCivilizationState, rng *randomGenerator) {
+	childHealth := mother.Health * 0.8 // Child starts at 80% of mother's health
+	d.mothers = append(d.mothers, mother)
+
+	babies := 1
+	if rng.NextBool(TwinsChance) {
+		babies = 2
+		d.twins++
+	}
+	for i := 0; i < babies; i++ {
+		// 70% infant survival rate at birth, better with shelter and medicine
+		if !rng.NextBool(infantSurvivalRate(state)) {
+			// Stillborn/infant mortality
+			continue
+		}
+
+		child := &MinimalHuman{
+			ID:                     generateID(rng),
+			Age:                    0,
+			Gender:                 "male",
+			Health:                 childHealth,
+			IsAlive:                true,
+			PregnancyDaysRemaining: 0,
+		}
+		if rng.NextBool(0.5) {
+			child.Gender = "female"
+		}
+		d.newborns = append(d.newborns, child)
+		d.newbornMothers = append(d.newbornMothers, mother)
+	}
+
+	if rng.NextBool(maternalMortalityChance(mother, knownEffects(state).maternalMortality)) {
+		mother.IsAlive = false
+		d.mothersLost = append(d.mothersLost, mother)
+	}
+}
+
+// maternalMortalityChance returns the chance a mother dies giving birth,
+// higher the weaker she is and scaled by what is known of childbirth, as
+// midwives halve it
+func maternalMortalityChance(mother *MinimalHuman, factor float64) float64 {
+	return MaternalMortality * healthMortalityModifier(mother.Health) * factor
+}
+
+// ResearchGoal is a technology a civilization can work toward next
+type ResearchGoal struct {
+	Technology      string
+	ScienceRequired float64
+}
+
+// researchGoals lists the technologies within reach, in the order they are
+// unlocked when science allows several at once: those of the tech tree not
+// yet known whose prerequisites are, skipping those needing herds where
+// none roam. In the standard tree everything follows Fire Mastery.
+func researchGoals(state *MinimalCivilizationState, conditions StartingConditions) []ResearchGoal {
+	var goals []ResearchGoal
+	for _, tech := range state.techTree().Technologies {
+		if knows(state, tech.Name) || (tech.NeedsLivestock && conditions.Livestock <= 0) {
+			continue
+		}
+		ready := true
+		for _, prerequisite := range tech.Prerequisites {
+			ready = ready && knows(state, prerequisite)
+		}
+		if ready {
+			goals = append(goals, ResearchGoal{tech.Name, tech.ScienceRequired})
+		}
+	}
+	return goals
+}
+
+// checkTechnologyUnlock unlocks the first research goal the civilization's
+// science has reached, reporting whether one was
+func checkTechnologyUnlock(state *MinimalCivilizationState, conditions StartingConditions) bool {
+	for _, goal := range researchGoals(state, conditions) {
+		if state.SciencePoints >= goal.ScienceRequired {
+			unlockTechnology(state, goal.Technology)
+			return true
+		}
 	}
 	return false
 }
 
+// unlockTechnology marks a technology as known; names not in the
+// civilization's tech tree are ignored
+func unlockTechnology(state *MinimalCivilizationState, tech string) {
+	if _, ok := state.techTree().Technology(tech); !ok || knows(state, tech) {
+		return
+	}
+	if flag := techFlag(state, tech); flag != nil {
+		*flag = true
+		return
+	}
+	state.Discoveries = append(state.Discoveries, tech)
+}
+
+// herdFoodMultiplier returns the food multiplier tamed herds give: none
+// before Domestication, and twice as much once Husbandry manages them
+func herdFoodMultiplier(state *MinimalCivilizationState, livestock int) float64 {
+	herds := float64(min(livestock, MaxHerds))
+	return 1 + knownEffects(state).herdFood*herds
+}
+
+// technologies lists the technologies a civilization has unlocked, in tech
+// tree order
+func technologies(state *MinimalCivilizationState) []string {
+	var techs []string
+	for _, tech := range state.techTree().Technologies {
+		if knows(state, tech.Name) {
+			techs = append(techs, tech.Name)
+		}
+	}
+	return techs
+}
+
 // calculateAverageHealth calculates the average health of alive humans
 func calculateAverageHealth(humans []*MinimalHuman) float64 {
 	total := 0.0
@@ -403,6 +717,6 @@ func countAlive(humans []*MinimalHuman) int {
 }
 
 // generateID creates a unique ID for a human
-func generateID(rng *RandomGenerator) string {
+func generateID(rng *randomGenerator) string {
 	return fmt.Sprintf("human-%d", int(rng.Next()*1000000000))
 }
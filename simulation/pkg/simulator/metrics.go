@@ -0,0 +1,139 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// MetricsSink receives each day's metrics during a simulation run and decides
+// what to retain. Long multi-seed sweeps can use a sampling or streaming sink
+// so they don't hold millions of DailyMetrics structs in memory.
+type MetricsSink interface {
+	// Record is called once per simulated day
+	Record(m *DailyMetrics)
+
+	// Metrics returns the retained metrics, reported as ViabilityResult.AllMetrics
+	Metrics() []*DailyMetrics
+}
+
+// KeepAllSink retains every day's metrics (the default)
+type KeepAllSink struct {
+	metrics []*DailyMetrics
+}
+
+// NewKeepAllSink creates a sink that keeps every day, preallocating for the expected days
+func NewKeepAllSink(capacity int) *KeepAllSink {
+	return &KeepAllSink{metrics: make([]*DailyMetrics, 0, capacity)}
+}
+
+// Record retains the day's metrics
+func (s *KeepAllSink) Record(m *DailyMetrics) {
+	s.metrics = append(s.metrics, m)
+}
+
+// Metrics returns every recorded day
+func (s *KeepAllSink) Metrics() []*DailyMetrics {
+	return s.metrics
+}
+
+// SampleEveryNSink retains every Nth day plus the final day of the run
+type SampleEveryNSink struct {
+	n       int
+	metrics []*DailyMetrics
+	last    *DailyMetrics
+}
+
+// NewSampleEveryNSink creates a sink keeping days N, 2N, 3N... (N < 1 is treated as 1)
+func NewSampleEveryNSink(n int) *SampleEveryNSink {
+	if n < 1 {
+		n = 1
+	}
+	return &SampleEveryNSink{n: n}
+}
+
+// Record retains the day's metrics if it falls on the sampling interval
+func (s *SampleEveryNSink) Record(m *DailyMetrics) {
+	s.last = m
+	if m.Day%s.n == 0 {
+		s.metrics = append(s.metrics, m)
+	}
+}
+
+// Metrics returns the sampled days, always ending with the final day
+func (s *SampleEveryNSink) Metrics() []*DailyMetrics {
+	if s.last != nil && (len(s.metrics) == 0 || s.metrics[len(s.metrics)-1] != s.last) {
+		return append(s.metrics, s.last)
+	}
+	return s.metrics
+}
+
+// SummaryOnlySink retains nothing; only the ViabilityResult summary is kept
+type SummaryOnlySink struct{}
+
+// Record discards the day's metrics
+func (SummaryOnlySink) Record(m *DailyMetrics) {}
+
+// Metrics returns nil
+func (SummaryOnlySink) Metrics() []*DailyMetrics {
+	return nil
+}
+
+// StreamingCSVSink writes each day as a CSV row and retains nothing
+type StreamingCSVSink struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewStreamingCSVSink creates a sink writing CSV rows to w
+func NewStreamingCSVSink(w io.Writer) *StreamingCSVSink {
+	return &StreamingCSVSink{w: csv.NewWriter(w)}
+}
+
+// csvHeader lists the DailyMetrics columns in output order
+var csvHeader = []string{
+	"day", "population", "average_health", "food_stockpile", "science_points",
+	"food_production", "science_production", "births", "deaths", "has_fire_mastery",
+	"starvation_deaths", "age_deaths", "disease_deaths", "accident_deaths", "combat_deaths",
+	"childbirth_deaths", "twins", "crisis", "migrants",
+}
+
+// Record writes the day's metrics as a CSV row
+func (s *StreamingCSVSink) Record(m *DailyMetrics) {
+	if !s.wroteHeader {
+		s.w.Write(csvHeader)
+		s.wroteHeader = true
+	}
+	s.w.Write([]string{
+		strconv.Itoa(m.Day),
+		strconv.Itoa(m.Population),
+		strconv.FormatFloat(m.AverageHealth, 'f', 2, 64),
+		strconv.FormatFloat(m.FoodStockpile, 'f', 2, 64),
+		strconv.FormatFloat(m.SciencePoints, 'f', 4, 64),
+		strconv.FormatFloat(m.FoodProduction, 'f', 2, 64),
+		strconv.FormatFloat(m.ScienceProduction, 'f', 6, 64),
+		strconv.Itoa(m.Births),
+		strconv.Itoa(m.Deaths),
+		strconv.FormatBool(m.HasFireMastery),
+		strconv.Itoa(m.Mortality.Starvation),
+		strconv.Itoa(m.Mortality.Age),
+		strconv.Itoa(m.Mortality.Disease),
+		strconv.Itoa(m.Mortality.Accident),
+		strconv.Itoa(m.Mortality.Combat),
+		strconv.Itoa(m.Mortality.Childbirth),
+		strconv.Itoa(m.Twins),
+		m.Crisis,
+		strconv.Itoa(m.Migrants),
+	})
+}
+
+// Metrics returns nil; rows were streamed to the writer
+func (s *StreamingCSVSink) Metrics() []*DailyMetrics {
+	return nil
+}
+
+// Flush writes any buffered rows and returns the first write error, if any
+func (s *StreamingCSVSink) Flush() error {
+	s.w.Flush()
+	return s.w.Error()
+}
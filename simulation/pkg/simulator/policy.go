@@ -0,0 +1,99 @@
+package simulator
+
+import "math"
+
+// Allocation is how a civilization divides its work hours
+type Allocation struct {
+	FoodRatio float64 // Share of work hours spent on food, the rest on science (0-1)
+}
+
+// Policy decides a civilization's labor allocation as it goes. It is
+// consulted at the start of every simulated day, or of every aggregated
+// period, with the current state, which it must not modify. Policies are not
+// saved in snapshots, so a resumed run needs its policy set again, and one
+// policy shared by Sweep's parallel runs must be safe for concurrent use.
+type Policy interface {
+	Allocate(state *MinimalCivilizationState) Allocation
+}
+
+// PolicyFunc adapts a function to a Policy, for quick experiments
+type PolicyFunc func(state *MinimalCivilizationState) Allocation
+
+// Allocate calls f
+func (f PolicyFunc) Allocate(state *MinimalCivilizationState) Allocation {
+	return f(state)
+}
+
+// FixedRatioPolicy always spends the same share of work on food, as
+// StartingConditions.FoodAllocationRatio does without a policy
+type FixedRatioPolicy struct {
+	FoodRatio float64
+}
+
+// Allocate returns the fixed ratio
+func (p FixedRatioPolicy) Allocate(state *MinimalCivilizationState) Allocation {
+	return Allocation{FoodRatio: p.FoodRatio}
+}
+
+// StarvationAvoidancePolicy spends more work on food the fewer days the
+// stockpile would feed everyone: MaxFoodRatio with an empty store, easing
+// down to MinFoodRatio once ReserveDays of food are put by
+type StarvationAvoidancePolicy struct {
+	MinFoodRatio float64
+	MaxFoodRatio float64
+	ReserveDays  float64
+}
+
+// NewStarvationAvoidancePolicy creates a policy that keeps a month of food
+// in store
+func NewStarvationAvoidancePolicy() StarvationAvoidancePolicy {
+	return StarvationAvoidancePolicy{MinFoodRatio: 0.6, MaxFoodRatio: 0.95, ReserveDays: 30}
+}
+
+// Allocate weighs the ratio by how many days of food are stored
+func (p StarvationAvoidancePolicy) Allocate(state *MinimalCivilizationState) Allocation {
+	stocked := math.Min(1, daysOfFood(state)/math.Max(p.ReserveDays, 1))
+	return Allocation{FoodRatio: p.MaxFoodRatio - (p.MaxFoodRatio-p.MinFoodRatio)*stocked}
+}
+
+// ScienceRushPolicy puts as much work as it dares into research until Fire
+// Mastery: RushFoodRatio while ReserveDays of food are stored, FoodRatio
+// when stores run lower, and FoodRatio once fire is mastered
+type ScienceRushPolicy struct {
+	RushFoodRatio float64
+	FoodRatio     float64
+	ReserveDays   float64
+}
+
+// NewScienceRushPolicy creates a policy that rushes research while a
+// fortnight of food is stored
+func NewScienceRushPolicy() ScienceRushPolicy {
+	return ScienceRushPolicy{RushFoodRatio: 0.4, FoodRatio: 0.7, ReserveDays: 14}
+}
+
+// Allocate rushes research while it is safe to
+func (p ScienceRushPolicy) Allocate(state *MinimalCivilizationState) Allocation {
+	if state.HasFireMastery || daysOfFood(state) < p.ReserveDays {
+		return Allocation{FoodRatio: p.FoodRatio}
+	}
+	return Allocation{FoodRatio: p.RushFoodRatio}
+}
+
+// daysOfFood returns how many days the stockpile would feed everyone
+func daysOfFood(state *MinimalCivilizationState) float64 {
+	shares := foodShares(state.Humans)
+	if shares <= 0 {
+		return math.Inf(1)
+	}
+	return state.FoodStockpile / (shares * FoodRequiredPerPerson)
+}
+
+// allocate consults the simulation's policy, if any, recording the ratio it
+// chose on the state, and returns the food ratio to work by
+func (s *Simulation) allocate() float64 {
+	if s.Policy != nil {
+		ratio := s.Policy.Allocate(s.State).FoodRatio
+		s.State.FoodAllocationRatio = math.Max(0, math.Min(1, ratio))
+	}
+	return s.State.FoodAllocationRatio
+}
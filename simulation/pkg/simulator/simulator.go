@@ -18,7 +18,7 @@ func DefaultStartingConditions() StartingConditions {
 }
 
 // initializePopulation creates the initial population with age and gender distribution
-func initializePopulation(conditions StartingConditions, rng *RandomGenerator) []*MinimalHuman {
+func initializePopulation(conditions StartingConditions, rng *randomGenerator) []*MinimalHuman {
 	humans := make([]*MinimalHuman, 0, conditions.Population)
 
 	// Age distribution tuned for viability (design updated to match implementation)
@@ -83,95 +83,45 @@ func initializePopulation(conditions StartingConditions, rng *RandomGenerator) [
 
 // RunSimulation executes the minimal simulator until Fire Mastery or failure
 func RunSimulation(config SimulationConfig) ViabilityResult {
-	// Initialize RNG
-	rng := NewRandomGenerator(config.Seed)
-
 	// Set defaults
 	if config.MaxDays == 0 {
 		config.MaxDays = 1825 // 5 years
 	}
+	sink := config.MetricsSink
+	if sink == nil {
+		sink = NewKeepAllSink(config.MaxDays)
+	}
 
-	// Initialize population
-	humans := initializePopulation(config.StartingConditions, rng)
-
-	// Initialize state
-	state := &MinimalCivilizationState{
-		Humans:              humans,
-		FoodStockpile:       config.StartingConditions.FoodStockpile,
-		SciencePoints:       0,
-		FoodAllocationRatio: config.StartingConditions.FoodAllocationRatio,
-		HasFireMastery:      false,
-		CurrentDay:          0,
+	var sim *Simulation
+	if config.Resume != nil {
+		resumed, err := Resume(config.Resume)
+		if err != nil {
+			return ViabilityResult{FailureReasons: []string{err.Error()}, DaysToNonViable: -1}
+		}
+		sim = resumed
+	} else {
+		sim = NewSimulation(config.StartingConditions, config.Seed)
+	}
+	if config.Trace {
+		sim.Trace = &Trace{}
+	}
+	sim.Policy = config.Policy
+	if config.TechTree != nil {
+		sim.SetTechTree(config.TechTree)
+	}
+	if config.Rates != nil {
+		sim.SetRates(config.Rates)
 	}
+	state := sim.State
 
-	// Track metrics
-	allMetrics := make([]*DailyMetrics, 0, config.MaxDays)
+	// Viability is assessed incrementally so sinks are free to discard metrics
+	tracker := newViabilityTracker(sim.Population())
 
 	// Simulation loop
 	for state.CurrentDay < config.MaxDays {
-		state.CurrentDay++
-
-		// Step 1: Calculate available labor
-		totalWorkHours := calculateAvailableLabor(state.Humans)
-
-		// Step 2: Allocate labor to food/science
-		foodHours, scienceHours := allocateLabor(totalWorkHours, state.FoodAllocationRatio)
-
-		// Step 3: Produce food and science
-		avgHealth := calculateAverageHealth(state.Humans)
-		population := countAlive(state.Humans)
-
-		foodProduced := produceFood(foodHours, state.HasFireMastery, config.StartingConditions.TerrainMultiplier)
-		scienceProduced := produceScience(scienceHours, population, avgHealth)
-
-		state.FoodStockpile += foodProduced
-		state.SciencePoints += scienceProduced
-
-		// Step 4: Consume food
-		remainingFood, foodPerPerson := consumeFood(state.Humans, state.FoodStockpile)
-		state.FoodStockpile = remainingFood
-
-		// Step 5: Update health based on nutrition
-		for _, human := range state.Humans {
-			updateHealth(human, foodPerPerson)
-		}
-
-		// Step 6: Age all humans
-		ageHumans(state.Humans)
-
-		// Step 7: Process mortality checks
-		deaths := 0
-		for _, human := range state.Humans {
-			if checkMortality(human, rng) {
-				deaths++
-			}
-		}
-
-		// Step 8: Process pregnancies (decrement counters and handle births)
-		newborns := processPregnancies(state.Humans, rng)
-		births := len(newborns)
-		state.Humans = append(state.Humans, newborns...)
-
-		// Step 9: Attempt new conceptions
-		attemptReproduction(state.Humans, rng)
-
-		// Step 10: Check for Fire Mastery unlock
-		checkTechnologyUnlock(state)
-
-		// Step 11: Record metrics
-		metrics := &DailyMetrics{
-			Day:               state.CurrentDay,
-			Population:        countAlive(state.Humans),
-			AverageHealth:     calculateAverageHealth(state.Humans),
-			FoodStockpile:     state.FoodStockpile,
-			SciencePoints:     state.SciencePoints,
-			FoodProduction:    foodProduced,
-			ScienceProduction: scienceProduced,
-			Births:            births,
-			Deaths:            deaths,
-			HasFireMastery:    state.HasFireMastery,
-		}
-		allMetrics = append(allMetrics, metrics)
+		metrics := sim.StepDay()
+		sink.Record(metrics)
+		declined := tracker.record(metrics)
 
 		// Check for termination conditions
 		if state.HasFireMastery {
@@ -182,88 +132,130 @@ func RunSimulation(config SimulationConfig) ViabilityResult {
 			// Extinction
 			break
 		}
-		
-		// Check for population decline over past year (365 days)
-		// If population has declined or stayed same, halt as non-viable
-		if state.CurrentDay >= 365 {
-			yearAgoIdx := state.CurrentDay - 365 - 1 // -1 for 0-indexing
-			if yearAgoIdx >= 0 && yearAgoIdx < len(allMetrics) {
-				yearAgoPop := allMetrics[yearAgoIdx].Population
-				currentPop := metrics.Population
-				if currentPop <= yearAgoPop {
-					// Population not growing - halt simulation
-					break
-				}
-			}
+
+		// If population has declined or stayed same over the past year
+		// (365 days), halt as non-viable
+		if declined {
+			break
 		}
 	}
 
+	if flusher, ok := sink.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+
 	// Assess viability
-	return assessViability(config.StartingConditions.Population, allMetrics, config.MaxDays)
+	result := tracker.result(config.MaxDays)
+	result.AllMetrics = sink.Metrics()
+	result.Final = sim.Snapshot()
+	result.Trace = sim.Trace
+	return result
 }
 
-// assessViability evaluates whether a starting position is viable
-func assessViability(startingPopulation int, allMetrics []*DailyMetrics, maxDays int) ViabilityResult {
-	if len(allMetrics) == 0 {
-		return ViabilityResult{
-			IsViable:         false,
-			FailureReasons:   []string{"No metrics recorded"},
-			DaysToNonViable:  -1,
-			AllMetrics:       allMetrics,
-		}
+// viabilityTracker accumulates everything needed to assess viability from a
+// stream of daily metrics, holding only the last year of populations
+type viabilityTracker struct {
+	startingPopulation int
+	days               int
+	last               *DailyMetrics
+
+	fireMasteryDay    int
+	firstExtinctDay   int
+	daysToNonViable   int
+	peakPopulation    int
+	minimumPopulation int
+	totalBirths       int
+	totalTwins        int
+	crises            []CrisisRecord
+	mortality         Mortality
+	totalHealth       float64
+	failures          []string
+
+	// Ring buffer of the last 365 days for the 1-year decline check
+	yearAgo [365]*DailyMetrics
+}
+
+// newViabilityTracker creates a tracker for a run with the given starting population
+func newViabilityTracker(startingPopulation int) *viabilityTracker {
+	return &viabilityTracker{
+		startingPopulation: startingPopulation,
+		fireMasteryDay:     -1,
+		firstExtinctDay:    -1,
+		daysToNonViable:    -1,
+		minimumPopulation:  startingPopulation,
+		failures:           []string{},
 	}
+}
 
-	failures := []string{}
-	lastDay := allMetrics[len(allMetrics)-1]
-	daysToNonViable := -1
+// record consumes one day of metrics and reports whether this day is the
+// first to show population decline/stagnation over a 1-year (365-day) period
+func (v *viabilityTracker) record(m *DailyMetrics) bool {
+	i := v.days
+	v.days++
+	v.last = m
 
-	// Find day when Fire Mastery was unlocked (if ever)
-	fireMasteryDay := -1
-	for _, m := range allMetrics {
-		if m.HasFireMastery {
-			fireMasteryDay = m.Day
-			break
+	if m.HasFireMastery && v.fireMasteryDay == -1 {
+		v.fireMasteryDay = m.Day
+	}
+	if m.Population == 0 && v.firstExtinctDay == -1 {
+		v.firstExtinctDay = m.Day
+	}
+	if m.Population > v.peakPopulation {
+		v.peakPopulation = m.Population
+	}
+	if m.Population < v.minimumPopulation {
+		v.minimumPopulation = m.Population
+	}
+	v.totalBirths += m.Births
+	v.totalTwins += m.Twins
+	if m.Crisis != "" {
+		v.crises = append(v.crises, CrisisRecord{Day: m.Day, Kind: m.Crisis, Population: m.Population, Deaths: m.Deaths, Migrants: m.Migrants})
+	}
+	v.mortality.Add(m.Mortality)
+	v.totalHealth += m.AverageHealth
+
+	declined := false
+	slot := i % len(v.yearAgo)
+	// Check if we have a full year of data from this point
+	if i >= len(v.yearAgo) {
+		yearAgo := v.yearAgo[slot]
+
+		// If population declined or stayed same over the past year, mark as non-viable
+		if m.Population <= yearAgo.Population && v.daysToNonViable == -1 {
+			v.daysToNonViable = m.Day
+			v.failures = append(v.failures, fmt.Sprintf("Population declined/stagnated over 1-year period (day %d: %d -> day %d: %d)", 
+				yearAgo.Day, yearAgo.Population, m.Day, m.Population))
+			declined = true
 		}
 	}
+	v.yearAgo[slot] = m
 
-	// Calculate population metrics and check for 1-year decline
-	peakPopulation := 0
-	minimumPopulation := startingPopulation
-	totalBirths := 0
-	
-	// Check for population decline in any 1-year (365-day) period
-	for i, m := range allMetrics {
-		if m.Population > peakPopulation {
-			peakPopulation = m.Population
-		}
-		if m.Population < minimumPopulation {
-			minimumPopulation = m.Population
-		}
-		totalBirths += m.Births
-		
-		// Check if we have a full year of data from this point
-		if i >= 365 {
-			yearAgoPop := allMetrics[i-365].Population
-			currentPop := m.Population
-			
-			// If population declined or stayed same over the past year, mark as non-viable
-			if currentPop <= yearAgoPop && daysToNonViable == -1 {
-				daysToNonViable = m.Day
-				failures = append(failures, fmt.Sprintf("Population declined/stagnated over 1-year period (day %d: %d -> day %d: %d)", 
-					allMetrics[i-365].Day, yearAgoPop, m.Day, currentPop))
-			}
+	return declined
+}
+
+// result evaluates whether the starting position was viable
+func (v *viabilityTracker) result(maxDays int) ViabilityResult {
+	if v.days == 0 {
+		return ViabilityResult{
+			IsViable:         false,
+			FailureReasons:   []string{"No metrics recorded"},
+			DaysToNonViable:  -1,
 		}
 	}
 
+	failures := v.failures
+	lastDay := v.last
+	daysToNonViable := v.daysToNonViable
+
 	// Criterion 1: Fire Mastery must be unlocked
 	if !lastDay.HasFireMastery {
 		failures = append(failures, "Fire Mastery not unlocked")
 	}
 
 	// Criterion 2: Fire Mastery must be unlocked in reasonable time
-	if fireMasteryDay < 0 {
+	if v.fireMasteryDay < 0 {
 		failures = append(failures, "Fire Mastery never unlocked")
-	} else if fireMasteryDay > maxDays {
+	} else if v.fireMasteryDay > maxDays {
 		failures = append(failures, "Fire Mastery took too long")
 	}
 
@@ -271,22 +263,12 @@ func assessViability(startingPopulation int, allMetrics []*DailyMetrics, maxDays
 	if lastDay.Population == 0 {
 		failures = append(failures, "Population extinct")
 		if daysToNonViable == -1 {
-			// Find when extinction happened
-			for _, m := range allMetrics {
-				if m.Population == 0 {
-					daysToNonViable = m.Day
-					break
-				}
-			}
+			daysToNonViable = v.firstExtinctDay
 		}
 	}
 
 	// Criterion 4: Average health must remain viable
-	totalHealth := 0.0
-	for _, m := range allMetrics {
-		totalHealth += m.AverageHealth
-	}
-	avgHealthOverTime := totalHealth / float64(len(allMetrics))
+	avgHealthOverTime := v.totalHealth / float64(v.days)
 	if avgHealthOverTime < 30 {
 		failures = append(failures, "Average health too low over time")
 	}
@@ -297,15 +279,17 @@ func assessViability(startingPopulation int, allMetrics []*DailyMetrics, maxDays
 		FinalPopulation:     lastDay.Population,
 		FinalScience:        lastDay.SciencePoints,
 		AverageHealth:       avgHealthOverTime,
-		DaysToFireMastery:   fireMasteryDay,
+		DaysToFireMastery:   v.fireMasteryDay,
 		DaysToNonViable:     daysToNonViable,
 		FinalAverageHealth:  lastDay.AverageHealth,
-		PeakPopulation:      peakPopulation,
-		MinimumPopulation:   minimumPopulation,
+		PeakPopulation:      v.peakPopulation,
+		MinimumPopulation:   v.minimumPopulation,
 		FireMasteryUnlocked: lastDay.HasFireMastery,
-		TotalBirths:         totalBirths,
+		TotalBirths:         v.totalBirths,
+		TotalTwins:          v.totalTwins,
+		Crises:              v.crises,
+		Mortality:           v.mortality,
 		HasFireMastery:      lastDay.HasFireMastery,
-		AllMetrics:          allMetrics,
 	}
 }
 
@@ -1,29 +1,26 @@
 package simulator
 
 import (
+	"bytes"
+	"encoding/gob"
 	"fmt"
+	"math"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
 )
 
 // VIABILITY_TEST_SEEDS contains hardcoded random seeds for reproducible testing
 // These seeds are used to test the same starting conditions with different RNG outcomes
-var VIABILITY_TEST_SEEDS = []int{
-	12345, 67890, 11111, 22222, 33333, 44444, 55555,
-	66666, 77777, 88888, 99999, 10101, 20202, 30303,
-	40404, 50505, 60606, 70707, 80808, 90909, 12121,
-	23232, 34343, 45454, 56565, 67676, 78787, 89898,
-	13579, 24680, 98765, 87654, 76543, 65432, 54321,
-	43210, 31415, 27182, 16180, 14142, 17320, 26457,
-	32103, 41231, 51234, 61234, 71234, 81234, 91234,
-	10203, // 50 seeds total
-}
+var VIABILITY_TEST_SEEDS = BalanceSeeds
 
 // TestRandomGenerator_Determinism verifies that the RNG is deterministic
 func TestRandomGenerator_Determinism(t *testing.T) {
 	seed := 12345
-	rng1 := NewRandomGenerator(seed)
-	rng2 := NewRandomGenerator(seed)
+	rng1 := newRandomGenerator(seed)
+	rng2 := newRandomGenerator(seed)
 
 	// Generate 100 random numbers and verify they match
 	for i := 0; i < 100; i++ {
@@ -37,7 +34,7 @@ func TestRandomGenerator_Determinism(t *testing.T) {
 
 // TestRandomGenerator_Range verifies random number generation is in correct range
 func TestRandomGenerator_Range(t *testing.T) {
-	rng := NewRandomGenerator(12345)
+	rng := newRandomGenerator(12345)
 
 	for i := 0; i < 1000; i++ {
 		v := rng.Next()
@@ -49,7 +46,7 @@ func TestRandomGenerator_Range(t *testing.T) {
 
 // TestRandomGenerator_NextInRange verifies range-bounded random generation
 func TestRandomGenerator_NextInRange(t *testing.T) {
-	rng := NewRandomGenerator(12345)
+	rng := newRandomGenerator(12345)
 
 	for i := 0; i < 1000; i++ {
 		v := rng.NextInRange(10, 20)
@@ -62,7 +59,7 @@ func TestRandomGenerator_NextInRange(t *testing.T) {
 // TestInitializePopulation verifies population initialization
 func TestInitializePopulation(t *testing.T) {
 	conditions := DefaultStartingConditions()
-	rng := NewRandomGenerator(12345)
+	rng := newRandomGenerator(12345)
 
 	humans := initializePopulation(conditions, rng)
 
@@ -138,6 +135,15 @@ func TestCalculateAvailableLabor(t *testing.T) {
 			},
 			expected: 0.0,
 		},
+		{
+			name: "Elders work half days",
+			humans: []*MinimalHuman{
+				{Age: 50, Health: 80, IsAlive: true},
+				{Age: 60, Health: 40, IsAlive: true},
+				{Age: 70, Health: 20, IsAlive: true},
+			},
+			expected: 8.0, // 4 + 4 + 0
+		},
 		{
 			name: "Dead humans do not work",
 			humans: []*MinimalHuman{
@@ -168,6 +174,27 @@ func TestCalculateAvailableLabor(t *testing.T) {
 	}
 }
 
+// TestElderWisdomMultiplier verifies healthy elders raise science in
+// proportion to their share of the living
+func TestElderWisdomMultiplier(t *testing.T) {
+	humans := []*MinimalHuman{
+		{Age: 50, Health: 80, IsAlive: true}, // teaches
+		{Age: 55, Health: 30, IsAlive: true}, // too frail to teach
+		{Age: 60, Health: 80, IsAlive: false},
+		{Age: 20, Health: 80, IsAlive: true},
+		{Age: 25, Health: 80, IsAlive: true},
+	}
+	if got, want := elderWisdomMultiplier(humans), 1+ElderWisdomBonus/4; got != want {
+		t.Errorf("Expected multiplier %.2f from one teacher in four, got %.2f", want, got)
+	}
+	if got := elderWisdomMultiplier(humans[3:]); got != 1.0 {
+		t.Errorf("Expected no bonus without elders, got %.2f", got)
+	}
+	if got := elderWisdomMultiplier(nil); got != 1.0 {
+		t.Errorf("Expected no bonus for an empty population, got %.2f", got)
+	}
+}
+
 // TestAllocateLabor tests labor allocation
 func TestAllocateLabor(t *testing.T) {
 	tests := []struct {
@@ -214,7 +241,8 @@ func TestProduceFood(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := produceFood(tt.foodHours, tt.hasFireMastery, tt.terrainMultiplier)
+			state := &MinimalCivilizationState{HasFireMastery: tt.hasFireMastery}
+			result := produceFood(tt.foodHours, knownEffects(state).food, tt.terrainMultiplier)
 			epsilon := 0.0001
 			if result < tt.expected-epsilon || result > tt.expected+epsilon {
 				t.Errorf("Expected %f food, got %f", tt.expected, result)
@@ -339,7 +367,7 @@ func TestUpdateHealth(t *testing.T) {
 // TestCheckMortality tests mortality mechanics
 func TestCheckMortality(t *testing.T) {
 	// Test with a fixed seed for reproducibility
-	rng := NewRandomGenerator(12345)
+	rng := newRandomGenerator(12345)
 
 	// Test various age and health combinations
 	// We can't test exact outcomes due to randomness, but we can verify:
@@ -348,7 +376,7 @@ func TestCheckMortality(t *testing.T) {
 	// 3. Function returns correct boolean
 
 	dead := &MinimalHuman{Age: 30, Health: 50, IsAlive: false}
-	if checkMortality(dead, rng) {
+	if checkMortality(dead, &MinimalCivilizationState{}, rng) {
 		t.Error("Dead human should not die again")
 	}
 	if dead.IsAlive {
@@ -359,7 +387,7 @@ func TestCheckMortality(t *testing.T) {
 	deathOccurred := false
 	for i := 0; i < 1000; i++ {
 		testHuman := &MinimalHuman{Age: 30, Health: 5, IsAlive: true}
-		if checkMortality(testHuman, NewRandomGenerator(i)) {
+		if checkMortality(testHuman, &MinimalCivilizationState{}, newRandomGenerator(i)) {
 			deathOccurred = true
 			break
 		}
@@ -372,7 +400,7 @@ func TestCheckMortality(t *testing.T) {
 	healthyDeaths := 0
 	for i := 0; i < 1000; i++ {
 		testHuman := &MinimalHuman{Age: 20, Health: 90, IsAlive: true}
-		if checkMortality(testHuman, NewRandomGenerator(i)) {
+		if checkMortality(testHuman, &MinimalCivilizationState{}, newRandomGenerator(i)) {
 			healthyDeaths++
 		}
 	}
@@ -385,11 +413,11 @@ func TestCheckMortality(t *testing.T) {
 // TestCheckReproduction tests reproduction mechanics
 func TestCheckReproduction(t *testing.T) {
 	// First do a single manual test to see what's happening
-	rng := NewRandomGenerator(12345)
+	rng := newRandomGenerator(12345)
 	male := &MinimalHuman{Age: 25, Health: 80, IsAlive: true, Gender: "male"}
 	female := &MinimalHuman{Age: 25, Health: 80, IsAlive: true, Gender: "female"}
 	
-	conceived := checkReproduction(male, female, 20, rng)
+	conceived := checkReproduction(male, female, 20, 1, rng)
 	
 	avgHealth := (male.Health + female.Health) / 2.0
 	healthMod := (avgHealth - 50.0) / 50.0
@@ -456,7 +484,7 @@ func TestCheckReproduction(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			conceived := checkReproduction(tt.male, tt.female, tt.population, rng)
+			conceived := checkReproduction(tt.male, tt.female, tt.population, 1, rng)
 			if tt.shouldSucceed && !conceived {
 				t.Error("Expected reproduction to succeed")
 			}
@@ -474,7 +502,7 @@ func TestCheckReproduction(t *testing.T) {
 	for i := 0; i < 10000; i++ {
 		male := &MinimalHuman{Age: 25, Health: 80, IsAlive: true, Gender: "male"}
 		female := &MinimalHuman{Age: 25, Health: 80, IsAlive: true, Gender: "female"}
-		if checkReproduction(male, female, 20, NewRandomGenerator(i)) {
+		if checkReproduction(male, female, 20, 1, newRandomGenerator(i)) {
 			successCount++
 		}
 	}
@@ -815,3 +843,797 @@ func TestFoodAllocationComparison(t *testing.T) {
 	t.Log("Science accumulation: ~10-12 points after 10 years.")
 	t.Log("See designs/FIRE_MASTERY_CLAIMS_ANALYSIS.md for details.")
 }
+
+// TestSimulation_IncrementalMatchesRun verifies stepping day by day reproduces RunSimulation
+func TestSimulation_IncrementalMatchesRun(t *testing.T) {
+	config := SimulationConfig{
+		Seed:               12345,
+		StartingConditions: DefaultStartingConditions(),
+		MaxDays:            200,
+	}
+	result := RunSimulation(config)
+
+	sim := NewSimulation(config.StartingConditions, config.Seed)
+	for i := 0; i < len(result.AllMetrics); i++ {
+		m := sim.StepDay()
+		if m.Population != result.AllMetrics[i].Population {
+			t.Fatalf("Day %d: incremental population %d != RunSimulation %d", m.Day, m.Population, result.AllMetrics[i].Population)
+		}
+	}
+}
+
+// TestSimulation_AggregatedYear verifies the fast path stays close to daily fidelity
+func TestSimulation_AggregatedYear(t *testing.T) {
+	daily := NewSimulation(DefaultStartingConditions(), 12345)
+	aggregated := NewSimulation(DefaultStartingConditions(), 12345)
+
+	dailyYear := daily.AdvanceDays(DaysPerYear)
+	aggregatedYear := aggregated.AdvanceAggregated(DaysPerYear)
+
+	if aggregatedYear.Day != DaysPerYear || dailyYear.Day != DaysPerYear {
+		t.Errorf("Expected both simulations at day %d, got daily=%d aggregated=%d", DaysPerYear, dailyYear.Day, aggregatedYear.Day)
+	}
+	if aggregatedYear.Population <= 0 {
+		t.Fatal("Aggregated year should not go extinct from default conditions")
+	}
+
+	// Populations should agree within 20%
+	diff := float64(aggregatedYear.Population-dailyYear.Population) / float64(dailyYear.Population)
+	if diff > 0.2 || diff < -0.2 {
+		t.Errorf("Aggregated population %d diverges from daily %d by %.0f%%", aggregatedYear.Population, dailyYear.Population, diff*100)
+	}
+	t.Logf("Daily: pop=%d health=%.1f | Aggregated: pop=%d health=%.1f",
+		dailyYear.Population, dailyYear.AverageHealth, aggregatedYear.Population, aggregatedYear.AverageHealth)
+}
+
+// TestSimulation_CohortYears verifies cohorts track the full model over a
+// decade and that a simulation switches between the two at will
+func TestSimulation_CohortYears(t *testing.T) {
+	for _, seed := range []int{12345, 777, 31} {
+		aggregated := NewSimulation(DefaultStartingConditions(), seed)
+		cohorts := NewSimulation(DefaultStartingConditions(), seed)
+		mixed := NewSimulation(DefaultStartingConditions(), seed)
+		for year := 0; year < 10; year++ {
+			aggregated.AdvanceAggregated(DaysPerYear)
+			period := cohorts.AdvanceCohorts(DaysPerYear)
+			if period.Deaths != period.Mortality.Total() {
+				t.Fatalf("Seed %d: expected every cohort death to have a cause, got %d of %+v", seed, period.Deaths, period.Mortality)
+			}
+			if year%2 == 0 {
+				mixed.AdvanceCohorts(DaysPerYear)
+			} else {
+				mixed.AdvanceDays(DaysPerYear)
+			}
+		}
+
+		// Populations should agree within 25%
+		want := aggregated.Population()
+		for name, sim := range map[string]*Simulation{"cohort": cohorts, "mixed": mixed} {
+			diff := float64(sim.Population()-want) / float64(want)
+			if diff > 0.25 || diff < -0.25 {
+				t.Errorf("Seed %d: %s population %d diverges from aggregated %d by %.0f%%", seed, name, sim.Population(), want, diff*100)
+			}
+		}
+		if cohorts.State.CurrentDay != 10*DaysPerYear || mixed.State.CurrentDay != 10*DaysPerYear {
+			t.Errorf("Seed %d: expected ten years to pass, got days %d and %d", seed, cohorts.State.CurrentDay, mixed.State.CurrentDay)
+		}
+	}
+
+	// Counts are drawn as the expectation, rounded at random
+	sim := NewSimulation(DefaultStartingConditions(), 1)
+	if n := sim.drawCount(10, 0.5); n != 5 {
+		t.Errorf("Expected half of 10 to be 5, got %d", n)
+	}
+	if n := sim.drawCount(10, 0.25); n != 2 && n != 3 {
+		t.Errorf("Expected a quarter of 10 to round to 2 or 3, got %d", n)
+	}
+}
+
+// TestSimulation_WinterShelter verifies caves ease winter for a hungry settlement
+func TestSimulation_WinterShelter(t *testing.T) {
+	if !isWinterDay(1) || !isWinterDay(WinterDays) || isWinterDay(WinterDays+1) || !isWinterDay(DaysPerYear+1) {
+		t.Error("Expected winter to open every year")
+	}
+	if bonus := shelterHealth(1, 200, 50); bonus != WinterShelterHealth/4 {
+		t.Errorf("Expected shelter for a quarter of the population to give a quarter bonus, got %.3f", bonus)
+	}
+	if bonus := shelterHealth(WinterDays+1, 10, 50); bonus != 0 {
+		t.Errorf("Expected no shelter bonus outside winter, got %.3f", bonus)
+	}
+
+	conditions := DefaultStartingConditions()
+	conditions.FoodStockpile = 0
+	conditions.TerrainMultiplier = 0.1
+	exposed := NewSimulation(conditions, 12345)
+	conditions.ShelterCapacity = 1000
+	sheltered := NewSimulation(conditions, 12345)
+
+	exposedWinter := exposed.AdvanceDays(30)
+	shelteredWinter := sheltered.AdvanceDays(30)
+	if shelteredWinter.AverageHealth <= exposedWinter.AverageHealth {
+		t.Errorf("Expected sheltered health %.1f above exposed %.1f", shelteredWinter.AverageHealth, exposedWinter.AverageHealth)
+	}
+}
+
+// TestSimulation_Pollution verifies polluted surroundings wear down health
+func TestSimulation_Pollution(t *testing.T) {
+	conditions := DefaultStartingConditions()
+	conditions.FoodStockpile = 0
+	conditions.TerrainMultiplier = 0.1
+	clean := NewSimulation(conditions, 12345)
+	conditions.Pollution = 1
+	polluted := NewSimulation(conditions, 12345)
+
+	cleanMonth := clean.AdvanceDays(30)
+	pollutedMonth := polluted.AdvanceDays(30)
+	if pollutedMonth.AverageHealth >= cleanMonth.AverageHealth {
+		t.Errorf("Expected polluted health %.1f below clean %.1f", pollutedMonth.AverageHealth, cleanMonth.AverageHealth)
+	}
+}
+
+// TestSimulation_Domestication verifies the domestication line unlocks in
+// order, needs herds nearby, and turns them into food
+func TestSimulation_Domestication(t *testing.T) {
+	state := &MinimalCivilizationState{SciencePoints: DomesticationScienceRequired}
+	if !checkTechnologyUnlock(state, StartingConditions{Livestock: 2}) || !state.HasFireMastery || state.HasDomestication {
+		t.Fatal("Expected Fire Mastery to unlock before domestication")
+	}
+	if checkTechnologyUnlock(state, StartingConditions{}) {
+		t.Error("Expected domestication to need herds nearby")
+	}
+	if !checkTechnologyUnlock(state, StartingConditions{Livestock: 2}) || !state.HasDomestication {
+		t.Fatal("Expected domestication to unlock where herds roam")
+	}
+	if checkTechnologyUnlock(state, StartingConditions{Livestock: 2}) {
+		t.Error("Expected husbandry to need more science")
+	}
+	if got := herdFoodMultiplier(state, 2); got != 1+2*HerdFoodBonus {
+		t.Errorf("Expected tamed herds to add food, got %.3f", got)
+	}
+
+	state.SciencePoints = HusbandryScienceRequired
+	if !checkTechnologyUnlock(state, StartingConditions{Livestock: 2}) || !state.HasHusbandry {
+		t.Fatal("Expected husbandry to follow domestication")
+	}
+	if got := herdFoodMultiplier(state, 2*MaxHerds); got != 1+MaxHerds*PastureFoodBonus {
+		t.Errorf("Expected managed herds to add more food, capped at %d herds, got %.3f", MaxHerds, got)
+	}
+	if techs := technologies(state); len(techs) != 3 || techs[2] != TechHusbandry {
+		t.Errorf("Expected the whole line unlocked, got %v", techs)
+	}
+
+	restored := NewSimulation(DefaultStartingConditions(), 1)
+	restored.RestoreTechnologies([]string{TechFireMastery, TechDomestication})
+	if !restored.State.HasDomestication || restored.State.HasHusbandry {
+		t.Errorf("Expected restored technologies only, got %v", restored.Technologies())
+	}
+}
+
+// TestSimulation_ChildLabor verifies children put to work add labor at a
+// cost to their health and safety, and that herding changes both
+func TestSimulation_ChildLabor(t *testing.T) {
+	humans := []*MinimalHuman{
+		{Age: 5, Health: 80, IsAlive: true},  // too young
+		{Age: 12, Health: 80, IsAlive: true}, // can gather
+		{Age: 14, Health: 20, IsAlive: true}, // too weak
+		{Age: 20, Health: 80, IsAlive: true}, // adult, counted separately
+	}
+	state := &MinimalCivilizationState{}
+	if got := calculateChildLabor(humans, 0, state); got != 0 {
+		t.Errorf("Expected no child labor without the policy, got %.1f", got)
+	}
+	if got := calculateChildLabor(humans, 1, state); got != WorkHoursChild {
+		t.Errorf("Expected one working child, got %.1f hours", got)
+	}
+	if got := calculateChildLabor(humans, 0.5, state); got != WorkHoursChild/2 {
+		t.Errorf("Expected half the children to work, got %.1f hours", got)
+	}
+	state.HasDomestication = true
+	if got := calculateChildLabor(humans, 1, state); got != WorkHoursChildHerding {
+		t.Errorf("Expected herding to lengthen a child's day, got %.1f hours", got)
+	}
+	risk := childLaborAccidentChance(humans[1], 1, state)
+	state.HasHusbandry = true
+	if safer := childLaborAccidentChance(humans[1], 1, state); safer >= risk || safer <= 0 {
+		t.Errorf("Expected husbandry to reduce accidents, got %g vs %g", safer, risk)
+	}
+	if childLaborAccidentChance(humans[3], 1, state) != 0 || childLaborHealth(humans[3], 1) != 0 {
+		t.Error("Expected adults to bear no child labor cost")
+	}
+
+	conditions := DefaultStartingConditions()
+	idle := NewSimulation(conditions, 12345)
+	conditions.ChildLabor = 1
+	working := NewSimulation(conditions, 12345)
+	if working.availableLabor() <= idle.availableLabor() {
+		t.Errorf("Expected working children to add labor: %.1f vs %.1f", working.availableLabor(), idle.availableLabor())
+	}
+
+	// Without the policy the random stream is untouched
+	baseline := NewSimulation(DefaultStartingConditions(), 12345).AdvanceDays(DaysPerYear)
+	if year := idle.AdvanceDays(DaysPerYear); *year != *baseline || year.Mortality.Accident != 0 {
+		t.Errorf("Expected no child labor to match the baseline, got %+v vs %+v", year, baseline)
+	}
+	year := working.AdvanceAggregated(DaysPerYear)
+	if year.Mortality.Accident > year.Deaths {
+		t.Errorf("Expected accidents to be counted among deaths, got %d of %d", year.Mortality.Accident, year.Deaths)
+	}
+}
+
+// TestSimulation_TaxAndCorvee verifies taxes take their share of food and
+// corvée drafts its share of work hours away from farming
+func TestSimulation_TaxAndCorvee(t *testing.T) {
+	baseline := NewSimulation(DefaultStartingConditions(), 12345).AdvanceAggregated(DaysPerYear)
+	if baseline.FoodTaxed != 0 || baseline.CorveeHours != 0 {
+		t.Errorf("Expected no tax or corvée by default, got %+v", baseline)
+	}
+
+	conditions := DefaultStartingConditions()
+	conditions.Tax = 0.2
+	taxed := NewSimulation(conditions, 12345).AdvanceAggregated(DaysPerYear)
+	if share := taxed.FoodTaxed / (taxed.FoodProduction + taxed.FoodTaxed); math.Abs(share-0.2) > 1e-9 {
+		t.Errorf("Expected a fifth of the food taxed, got %.3f", share)
+	}
+	if taxed.FoodProduction >= baseline.FoodProduction {
+		t.Errorf("Expected taxes to leave less food, got %.0f vs %.0f", taxed.FoodProduction, baseline.FoodProduction)
+	}
+
+	conditions = DefaultStartingConditions()
+	conditions.Corvee = 0.2
+	sim := NewSimulation(conditions, 12345)
+	labor := sim.availableLabor()
+	drafted := sim.AdvanceAggregated(DaysPerYear)
+	if math.Abs(drafted.CorveeHours-0.2*labor*DaysPerYear) > 1e-6 {
+		t.Errorf("Expected a fifth of %.0f hours a day drafted, got %.0f a year", labor, drafted.CorveeHours)
+	}
+	if drafted.FoodProduction >= baseline.FoodProduction || drafted.FoodTaxed != 0 {
+		t.Errorf("Expected corvée to leave less food grown, got %.0f vs %.0f", drafted.FoodProduction, baseline.FoodProduction)
+	}
+
+	daily := NewSimulation(conditions, 12345).AdvanceDays(30)
+	if daily.CorveeHours <= 0 {
+		t.Errorf("Expected daily steps to draft labor too, got %+v", daily)
+	}
+}
+
+// TestSimulation_CauseOfDeath verifies every death is counted against a cause
+func TestSimulation_CauseOfDeath(t *testing.T) {
+	var mortality Mortality
+	mortality.recordDeath(&MinimalHuman{Age: 30, Health: 10}, 0)
+	mortality.recordDeath(&MinimalHuman{Age: 60, Health: 70}, FoodRequiredPerPerson)
+	mortality.recordDeath(&MinimalHuman{Age: 20, Health: 10}, FoodRequiredPerPerson)
+	if mortality != (Mortality{Starvation: 1, Age: 1, Disease: 1}) {
+		t.Errorf("Expected one death of each natural cause, got %+v", mortality)
+	}
+
+	conditions := DefaultStartingConditions()
+	conditions.FoodStockpile = 0
+	conditions.TerrainMultiplier = 0.1
+	conditions.ChildLabor = 1
+	result := RunSimulation(SimulationConfig{Seed: 12345, StartingConditions: conditions, MaxDays: 3 * DaysPerYear})
+	deaths := 0
+	for _, day := range result.AllMetrics {
+		if day.Mortality.Total() != day.Deaths {
+			t.Fatalf("Expected day %d's causes to cover its %d deaths, got %+v", day.Day, day.Deaths, day.Mortality)
+		}
+		deaths += day.Deaths
+	}
+	if result.Mortality.Total() != deaths || result.Mortality.Starvation == 0 {
+		t.Errorf("Expected a famine's %d deaths, mostly from starvation, got %+v", deaths, result.Mortality)
+	}
+
+	sim := NewSimulation(DefaultStartingConditions(), 1)
+	before := sim.Population()
+	if killed := sim.KillInCombat(5); killed != 5 || sim.Population() != before-5 {
+		t.Errorf("Expected five defenders killed, got %d (population %d -> %d)", killed, before, sim.Population())
+	}
+	if killed := sim.KillInCombat(1000); sim.Population() == 0 || killed == 0 {
+		t.Errorf("Expected combat to spare the children, killed %d leaving %d", killed, sim.Population())
+	}
+}
+
+// TestSimulation_Pregnancy verifies mothers near term rest and eat more,
+// and that childbirth brings twins and risks the mother, less with midwives
+func TestSimulation_Pregnancy(t *testing.T) {
+	mother := &MinimalHuman{Age: 25, Health: 70, IsAlive: true, Gender: "female", PregnancyDaysRemaining: LateTermDays}
+	early := &MinimalHuman{Age: 25, Health: 70, IsAlive: true, Gender: "female", PregnancyDaysRemaining: GestationPeriod}
+	if got := calculateAvailableLabor([]*MinimalHuman{mother, early}); got != WorkHoursHalf+WorkHoursFull {
+		t.Errorf("Expected the mother near term to work half a day, got %.1f hours", got)
+	}
+	remaining, perPerson := consumeFood([]*MinimalHuman{mother, {Age: 30, Health: 70, IsAlive: true}}, 100)
+	if want := 100 - (2+PregnancyFoodShare)*FoodRequiredPerPerson; remaining != want || perPerson != FoodRequiredPerPerson {
+		t.Errorf("Expected the mother to eat her extra share, got %.2f left and %.2f each", remaining, perPerson)
+	}
+
+	weak := &MinimalHuman{Health: 30}
+	untrained := knownEffects(&MinimalCivilizationState{}).maternalMortality
+	trained := knownEffects(&MinimalCivilizationState{HasMidwifery: true}).maternalMortality
+	if maternalMortalityChance(weak, untrained) <= maternalMortalityChance(mother, untrained) {
+		t.Error("Expected childbirth to be riskier for a weak mother")
+	}
+	if got := maternalMortalityChance(mother, trained); got != maternalMortalityChance(mother, untrained)*MidwiferyMaternalFactor {
+		t.Errorf("Expected midwives to reduce the risk, got %g", got)
+	}
+
+	state := &MinimalCivilizationState{HasFireMastery: true, SciencePoints: MidwiferyScienceRequired}
+	if !checkTechnologyUnlock(state, StartingConditions{}) || !state.HasMidwifery {
+		t.Fatal("Expected midwifery to follow fire mastery without herds")
+	}
+	restored := NewSimulation(DefaultStartingConditions(), 1)
+	restored.RestoreTechnologies(technologies(state))
+	if !restored.State.HasMidwifery {
+		t.Error("Expected midwifery to be restored")
+	}
+
+	delivered := &deliveries{}
+	rng := newRandomGenerator(7)
+	for i := 0; i < 2000; i++ {
+		delivered.deliverBirth(&MinimalHuman{Health: 30, IsAlive: true, Gender: "female"}, &MinimalCivilizationState{}, rng)
+	}
+	if delivered.twins == 0 || len(delivered.mothersLost) == 0 || len(delivered.newborns) <= 2000*InfantSurvivalRate*0.9 {
+		t.Errorf("Expected twins, lost mothers and surviving babies over 2000 births, got %d twins, %d mothers lost, %d babies",
+			delivered.twins, len(delivered.mothersLost), len(delivered.newborns))
+	}
+}
+
+// TestSimulation_ChildhoodTechnologies verifies shelter building and herbal
+// medicine are researched in turn and save young lives
+func TestSimulation_ChildhoodTechnologies(t *testing.T) {
+	state := &MinimalCivilizationState{HasFireMastery: true}
+	goals := researchGoals(state, StartingConditions{})
+	if len(goals) != 2 || goals[1].Technology != TechShelterBuilding {
+		t.Fatalf("Expected midwifery and shelter building within reach, got %+v", goals)
+	}
+	state.SciencePoints = HerbalMedicineScienceRequired
+	for checkTechnologyUnlock(state, StartingConditions{}) {
+	}
+	if !state.HasShelterBuilding || !state.HasHerbalMedicine || len(researchGoals(state, StartingConditions{})) != 0 {
+		t.Errorf("Expected the childhood line researched, got %v", technologies(state))
+	}
+
+	infant := &MinimalHuman{Age: 0.5, Health: 70, IsAlive: true}
+	adult := &MinimalHuman{Age: 25, Health: 70, IsAlive: true}
+	untaught := &MinimalCivilizationState{}
+	if got := dailyMortalityChance(infant, state); got != dailyMortalityChance(infant, untaught)*HerbalChildMortalityFactor {
+		t.Errorf("Expected herbal medicine to halve infant mortality, got %g", got)
+	}
+	if dailyMortalityChance(adult, state) != dailyMortalityChance(adult, untaught) {
+		t.Error("Expected adult mortality unchanged")
+	}
+	if got, want := infantSurvivalRate(state), InfantSurvivalRate+ShelterInfantSurvival+HerbalInfantSurvival; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected better infant survival, got %.2f", got)
+	}
+
+	// The whole line changes how a population grows
+	untreated := NewSimulation(DefaultStartingConditions(), 12345)
+	untreated.RestoreTechnologies([]string{TechFireMastery})
+	treated := NewSimulation(DefaultStartingConditions(), 12345)
+	treated.RestoreTechnologies(technologies(state))
+	for year := 0; year < 10; year++ {
+		untreated.AdvanceAggregated(DaysPerYear)
+		treated.AdvanceAggregated(DaysPerYear)
+	}
+	if treated.Population() <= untreated.Population() {
+		t.Errorf("Expected childhood technologies to grow the population: %d vs %d", treated.Population(), untreated.Population())
+	}
+}
+
+// TestSimulation_OvercrowdingCrises verifies a population past its region's
+// carrying capacity suffers crises, spaced out, of every kind
+func TestSimulation_OvercrowdingCrises(t *testing.T) {
+	conditions := DefaultStartingConditions()
+	conditions.CarryingCapacity = 50
+	kinds := map[string]bool{}
+	for _, seed := range VIABILITY_TEST_SEEDS[:10] {
+		result := RunSimulation(SimulationConfig{Seed: seed, StartingConditions: conditions, MaxDays: 3 * DaysPerYear})
+		for i, crisis := range result.Crises {
+			kinds[crisis.Kind] = true
+			if i > 0 && crisis.Day-result.Crises[i-1].Day < CrisisCooldownDays {
+				t.Errorf("Seed %d: expected crises at least %d days apart, got days %d and %d", seed, CrisisCooldownDays, result.Crises[i-1].Day, crisis.Day)
+			}
+			if crisis.Kind == CrisisMigration && crisis.Migrants == 0 {
+				t.Errorf("Seed %d: expected a migration to send people away", seed)
+			}
+			if crisis.Kind != CrisisMigration && crisis.Deaths == 0 {
+				t.Errorf("Seed %d: expected a %s to kill", seed, crisis.Kind)
+			}
+		}
+	}
+	if len(kinds) != 3 {
+		t.Errorf("Expected epidemics, conflict and migration, got %v", kinds)
+	}
+
+	// Within its capacity, or without one, a population is left alone
+	for _, capacity := range []int{0, 1000} {
+		conditions.CarryingCapacity = capacity
+		if result := RunSimulation(SimulationConfig{Seed: 12345, StartingConditions: conditions, MaxDays: DaysPerYear}); len(result.Crises) != 0 {
+			t.Errorf("Expected no crises with capacity %d, got %+v", capacity, result.Crises)
+		}
+	}
+}
+
+func TestSimulation_Snapshot(t *testing.T) {
+	conditions := DefaultStartingConditions()
+	conditions.CarryingCapacity = 60
+	whole := NewSimulation(conditions, 12345)
+	whole.AdvanceDays(200)
+	snapshot := whole.Snapshot()
+	whole.AdvanceDays(300)
+
+	var encoded bytes.Buffer
+	if err := snapshot.Encode(&encoded); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	decoded, err := DecodeSnapshot(&encoded)
+	if err != nil {
+		t.Fatalf("DecodeSnapshot: %v", err)
+	}
+	var gobbed bytes.Buffer
+	if err := gob.NewEncoder(&gobbed).Encode(snapshot); err != nil {
+		t.Fatalf("gob encode: %v", err)
+	}
+	var fromGob Snapshot
+	if err := gob.NewDecoder(&gobbed).Decode(&fromGob); err != nil {
+		t.Fatalf("gob decode: %v", err)
+	}
+
+	// A snapshot is untouched by later steps and resumes exactly where it left off
+	if snapshot.State.CurrentDay != 200 {
+		t.Errorf("Expected the snapshot to stay at day 200, got %d", snapshot.State.CurrentDay)
+	}
+	for name, saved := range map[string]*Snapshot{"json": decoded, "gob": &fromGob} {
+		resumed, err := Resume(saved)
+		if err != nil {
+			t.Fatalf("%s: Resume: %v", name, err)
+		}
+		resumed.AdvanceDays(300)
+		if !reflect.DeepEqual(resumed.Snapshot(), whole.Snapshot()) {
+			t.Errorf("%s: expected a resumed run to match an uninterrupted one", name)
+		}
+	}
+
+	snapshot.Version = SnapshotVersion + 1
+	if _, err := Resume(snapshot); err == nil {
+		t.Error("Expected a snapshot from another version to be refused")
+	}
+}
+
+func TestRunSimulation_Resume(t *testing.T) {
+	conditions := DefaultStartingConditions()
+	first := RunSimulation(SimulationConfig{Seed: 12345, StartingConditions: conditions, MaxDays: 100})
+	if first.Final == nil || first.Final.State.CurrentDay != 100 {
+		t.Fatalf("Expected the first segment to end at day 100, got %+v", first.Final)
+	}
+	second := RunSimulation(SimulationConfig{Resume: first.Final, MaxDays: 300})
+
+	whole := NewSimulation(conditions, 12345)
+	whole.AdvanceDays(300)
+	if !reflect.DeepEqual(second.Final, whole.Snapshot()) {
+		t.Errorf("Expected two segments to match a single run to day 300")
+	}
+	if second.AllMetrics[0].Day != 101 || len(second.AllMetrics) != 200 {
+		t.Errorf("Expected the second segment to record days 101-300, got %d days from day %d", len(second.AllMetrics), second.AllMetrics[0].Day)
+	}
+}
+
+func TestSimulation_Trace(t *testing.T) {
+	// A thriving run and a famine between them show every kind of event
+	kinds := map[string]int{}
+	for _, multiplier := range []float64{1, 0.3} {
+		conditions := DefaultStartingConditions()
+		conditions.ChildLabor = 1
+		conditions.CarryingCapacity = 60
+		conditions.TerrainMultiplier = multiplier
+		config := SimulationConfig{Seed: 12345, StartingConditions: conditions, MaxDays: 2 * DaysPerYear}
+		untraced := RunSimulation(config)
+		config.Trace = true
+		result := RunSimulation(config)
+		if !reflect.DeepEqual(result.Final, untraced.Final) {
+			t.Fatalf("Multiplier %v: expected tracing to leave the run unchanged", multiplier)
+		}
+
+		// Every birth, death and emigrant is traced, each death with its cause
+		counts := map[string]int{}
+		causes := map[string]int{}
+		died := map[string]bool{}
+		for _, event := range result.Trace.Events {
+			counts[event.Kind]++
+			kinds[event.Kind]++
+			if died[event.HumanID] {
+				t.Fatalf("Multiplier %v: expected nothing after %s died, got %+v", multiplier, event.HumanID, event)
+			}
+			if event.Kind == TraceDeath {
+				causes[event.Detail]++
+				died[event.HumanID] = true
+			}
+		}
+		deaths := result.Mortality
+		want := map[string]int{CauseStarvation: deaths.Starvation, CauseAge: deaths.Age, CauseDisease: deaths.Disease,
+			CauseAccident: deaths.Accident, CauseCombat: deaths.Combat, CauseChildbirth: deaths.Childbirth}
+		for cause, n := range want {
+			if causes[cause] != n {
+				t.Errorf("Multiplier %v: expected %d %s deaths traced, got %d", multiplier, n, cause, causes[cause])
+			}
+		}
+		migrants := 0
+		for _, crisis := range result.Crises {
+			migrants += crisis.Migrants
+		}
+		if counts[TraceBirth] != result.TotalBirths || counts[TraceEmigration] != migrants {
+			t.Errorf("Multiplier %v: expected %d births and %d emigrants traced, got %v", multiplier, result.TotalBirths, migrants, counts)
+		}
+
+		// A newborn's life starts with its birth, naming its mother
+		for _, event := range result.Trace.Events {
+			if event.Kind != TraceBirth {
+				continue
+			}
+			life := result.Trace.ForHuman(event.HumanID)
+			if life[0] != event || !strings.HasPrefix(event.Detail, "mother ") {
+				t.Errorf("Expected %s's life to open with a birth to its mother, got %+v", event.HumanID, life)
+			}
+			break
+		}
+
+		var log strings.Builder
+		if err := result.Trace.Write(&log); err != nil || strings.Count(log.String(), "\n") != len(result.Trace.Events) {
+			t.Errorf("Expected one line per event, got %d lines for %d events: %v", strings.Count(log.String(), "\n"), len(result.Trace.Events), err)
+		}
+	}
+	for _, kind := range []string{TraceBirth, TraceConception, TraceHealthCrisis, TraceDeath, TraceEmigration} {
+		if kinds[kind] == 0 {
+			t.Errorf("Expected %s events traced, got %v", kind, kinds)
+		}
+	}
+
+	// Aggregated years trace their deaths too
+	sim := NewSimulation(DefaultStartingConditions(), 12345)
+	sim.Trace = &Trace{}
+	var mortality Mortality
+	for year := 0; year < 3; year++ {
+		mortality.Add(sim.AdvanceAggregated(DaysPerYear).Mortality)
+	}
+	traced := 0
+	for _, event := range sim.Trace.Events {
+		if event.Kind == TraceDeath {
+			traced++
+		}
+	}
+	if traced != mortality.Total() {
+		t.Errorf("Expected %d aggregated deaths traced, got %d", mortality.Total(), traced)
+	}
+}
+
+// TestMetricsSinks verifies retention policies without changing the viability assessment
+func TestMetricsSinks(t *testing.T) {
+	base := SimulationConfig{
+		Seed:               12345,
+		StartingConditions: DefaultStartingConditions(),
+		MaxDays:            400,
+	}
+	keepAll := RunSimulation(base)
+	days := len(keepAll.AllMetrics)
+
+	sampled := base
+	sampled.MetricsSink = NewSampleEveryNSink(30)
+	sampledResult := RunSimulation(sampled)
+	if len(sampledResult.AllMetrics) != days/30+1 && len(sampledResult.AllMetrics) != days/30 {
+		t.Errorf("Expected about %d sampled days, got %d", days/30+1, len(sampledResult.AllMetrics))
+	}
+	if last := sampledResult.AllMetrics[len(sampledResult.AllMetrics)-1]; last.Day != days {
+		t.Errorf("Sampled metrics should end with the final day %d, got %d", days, last.Day)
+	}
+
+	summary := base
+	summary.MetricsSink = SummaryOnlySink{}
+	summaryResult := RunSimulation(summary)
+	if summaryResult.AllMetrics != nil {
+		t.Errorf("SummaryOnly should retain no metrics, got %d", len(summaryResult.AllMetrics))
+	}
+
+	var buf strings.Builder
+	streaming := base
+	streaming.MetricsSink = NewStreamingCSVSink(&buf)
+	streamingResult := RunSimulation(streaming)
+	if rows := strings.Count(buf.String(), "\n"); rows != days+1 {
+		t.Errorf("Expected %d CSV rows (header + days), got %d", days+1, rows)
+	}
+
+	// The summary must not depend on the sink
+	for _, r := range []ViabilityResult{sampledResult, summaryResult, streamingResult} {
+		if r.FinalPopulation != keepAll.FinalPopulation || r.PeakPopulation != keepAll.PeakPopulation ||
+			r.TotalBirths != keepAll.TotalBirths || r.AverageHealth != keepAll.AverageHealth {
+			t.Errorf("Viability summary differs between sinks: %+v vs %+v", r, keepAll)
+		}
+	}
+}
+
+func TestFindDifficulty(t *testing.T) {
+	base := DefaultStartingConditions()
+	base.FoodAllocationRatio = 0.5
+	search := DifficultySearch{Base: base, Knob: TerrainKnob, TargetViability: 0.7, Seeds: VIABILITY_TEST_SEEDS[:10], Iterations: 5}
+	result := FindDifficulty(search)
+	if math.Abs(result.Viability-0.7) > 0.15 || result.Conditions.TerrainMultiplier != result.Value {
+		t.Errorf("Expected terrain near 70%% viability, got %.3f at %.0f%%", result.Value, result.Viability*100)
+	}
+	if result.Value <= TerrainKnob.Min || result.Value >= TerrainKnob.Max {
+		t.Errorf("Expected the search to settle inside the knob's range, got %.3f", result.Value)
+	}
+
+	// Easier presets need kinder land
+	search.Seeds = VIABILITY_TEST_SEEDS[:5]
+	search.Iterations = 3
+	presets := FindDifficultyPresets(search)
+	if !(presets["easy"].Value >= presets["normal"].Value && presets["normal"].Value >= presets["hard"].Value) {
+		t.Errorf("Expected easy >= normal >= hard terrain, got %+v", presets)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	params := Params{StartingConditions: DefaultStartingConditions(), MaxDays: DaysPerYear}
+	seeds := VIABILITY_TEST_SEEDS[:8]
+	results := Sweep(params, seeds)
+	if len(results) != len(seeds) {
+		t.Fatalf("Expected %d results, got %d", len(seeds), len(results))
+	}
+	for i, seed := range seeds {
+		params.Seed = seed
+		want := Run(params)
+		if !reflect.DeepEqual(results[i].Final, want.Final) || results[i].FinalPopulation != want.FinalPopulation {
+			t.Errorf("Seed %d: expected the sweep to match a single run", seed)
+		}
+		if results[i].AllMetrics != nil {
+			t.Errorf("Seed %d: expected a sweep to keep only summaries", seed)
+		}
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	conditions := DefaultStartingConditions()
+
+	// A fixed policy at the starting ratio plays out as no policy at all
+	plain := Run(Params{Seed: 7, StartingConditions: conditions, MaxDays: 400})
+	fixed := Run(Params{Seed: 7, StartingConditions: conditions, MaxDays: 400, Policy: FixedRatioPolicy{FoodRatio: conditions.FoodAllocationRatio}})
+	if plain.FinalPopulation != fixed.FinalPopulation || plain.FinalScience != fixed.FinalScience {
+		t.Errorf("Expected a fixed policy to match no policy, got %d people and %.1f science vs %d and %.1f",
+			fixed.FinalPopulation, fixed.FinalScience, plain.FinalPopulation, plain.FinalScience)
+	}
+
+	// A custom policy is consulted every day and its ratio is worked by
+	consulted := 0
+	idle := PolicyFunc(func(state *MinimalCivilizationState) Allocation {
+		consulted++
+		return Allocation{FoodRatio: 1.5} // Clamped to all food
+	})
+	result := Run(Params{Seed: 7, StartingConditions: conditions, MaxDays: 30, Policy: idle})
+	if consulted != 30 || result.FinalScience != 0 || result.Final.State.FoodAllocationRatio != 1 {
+		t.Errorf("Expected 30 consultations and no science, got %d and %.2f at ratio %.2f", consulted, result.FinalScience, result.Final.State.FoodAllocationRatio)
+	}
+
+	// Aggregated periods consult the policy once each
+	sim := NewSimulation(conditions, 7)
+	consulted = 0
+	sim.Policy = idle
+	sim.AdvanceAggregated(DaysPerYear)
+	if consulted != 1 {
+		t.Errorf("Expected one consultation for an aggregated year, got %d", consulted)
+	}
+
+	// Starvation avoidance works harder for food the emptier the stores
+	state := NewSimulation(conditions, 7).State
+	avoid := NewStarvationAvoidancePolicy()
+	state.FoodStockpile = 0
+	if got := avoid.Allocate(state).FoodRatio; got != avoid.MaxFoodRatio {
+		t.Errorf("Expected an empty store to allocate %.2f to food, got %.2f", avoid.MaxFoodRatio, got)
+	}
+	state.FoodStockpile = 1e6
+	if got := avoid.Allocate(state).FoodRatio; got != avoid.MinFoodRatio {
+		t.Errorf("Expected a full store to allocate %.2f to food, got %.2f", avoid.MinFoodRatio, got)
+	}
+
+	// A science rush backs off when stores run low and once fire is mastered
+	rush := NewScienceRushPolicy()
+	if got := rush.Allocate(state).FoodRatio; got != rush.RushFoodRatio {
+		t.Errorf("Expected a rush with full stores, got %.2f to food", got)
+	}
+	state.HasFireMastery = true
+	if got := rush.Allocate(state).FoodRatio; got != rush.FoodRatio {
+		t.Errorf("Expected the rush to end with Fire Mastery, got %.2f to food", got)
+	}
+	state.HasFireMastery, state.FoodStockpile = false, 0
+	if got := rush.Allocate(state).FoodRatio; got != rush.FoodRatio {
+		t.Errorf("Expected the rush to pause with empty stores, got %.2f to food", got)
+	}
+}
+
+func TestTechTree(t *testing.T) {
+	if err := DefaultTechTree().Validate(); err != nil {
+		t.Fatalf("Expected the default tree to be valid, got %v", err)
+	}
+
+	// A tree loaded from JSON can add technologies beyond the standard ones
+	tree, err := LoadTechTree(strings.NewReader(`[
+		{"Name": "fire_mastery", "ScienceRequired": 10, "Effects": {"FoodMultiplier": 1.5}},
+		{"Name": "writing", "Prerequisites": ["fire_mastery"], "ScienceRequired": 20, "Effects": {"ScienceMultiplier": 2}}
+	]`))
+	if err != nil {
+		t.Fatalf("Expected the tree to load, got %v", err)
+	}
+	sim := NewSimulation(DefaultStartingConditions(), 7)
+	sim.SetTechTree(tree)
+	state := sim.State
+	if goals := sim.ResearchGoals(); len(goals) != 1 || goals[0].Technology != TechFireMastery || goals[0].ScienceRequired != 10 {
+		t.Errorf("Expected fire mastery at 10 science to be the only goal, got %v", goals)
+	}
+	state.SciencePoints = 25
+	checkTechnologyUnlock(state, sim.Conditions)
+	checkTechnologyUnlock(state, sim.Conditions)
+	if !state.HasFireMastery || !reflect.DeepEqual(state.Discoveries, []string{"writing"}) {
+		t.Errorf("Expected fire mastery flagged and writing discovered, got %v and %v", state.HasFireMastery, state.Discoveries)
+	}
+	if got := sim.Technologies(); !reflect.DeepEqual(got, []string{TechFireMastery, "writing"}) {
+		t.Errorf("Expected both technologies known, got %v", got)
+	}
+	if effects := knownEffects(state); effects.food != 1.5 || effects.science != 2 {
+		t.Errorf("Expected the tree's multipliers to apply, got food %.2f and science %.2f", effects.food, effects.science)
+	}
+
+	// Technologies outside the tree are never unlocked
+	unlockTechnology(state, TechMidwifery)
+	if state.HasMidwifery {
+		t.Error("Expected midwifery to stay unknown outside the tree")
+	}
+
+	for _, bad := range []string{
+		`[{"Name": "writing", "Prerequisites": ["fire_mastery"]}]`,
+		`[{"Name": "writing", "Prerequisites": ["fire_mastery"]}, {"Name": "fire_mastery"}]`,
+		`[{"Name": "fire_mastery"}, {"Name": "fire_mastery"}]`,
+		`[{"Name": "fire_mastery", "ScienceRequired": -1}]`,
+	} {
+		if _, err := LoadTechTree(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestDailyMetrics_Add(t *testing.T) {
+	conditions := DefaultStartingConditions()
+	whole := NewSimulation(conditions, 7).AdvanceDays(2 * DaysPerYear)
+
+	halves := NewSimulation(conditions, 7)
+	period := halves.AdvanceDays(DaysPerYear)
+	period.Add(halves.AdvanceDays(DaysPerYear))
+	if period.Day != whole.Day || period.Population != whole.Population || period.Births != whole.Births ||
+		period.Deaths != whole.Deaths || period.Mortality != whole.Mortality || period.SciencePoints != whole.SciencePoints {
+		t.Errorf("Expected two added years to match the two years run at once, got %+v and %+v", period, whole)
+	}
+	if math.Abs(period.FoodProduction-whole.FoodProduction) > 1e-6*whole.FoodProduction {
+		t.Errorf("Expected the years' food to add up to %.2f, got %.2f", whole.FoodProduction, period.FoodProduction)
+	}
+}
+
+func TestSetRates(t *testing.T) {
+	sim := NewSimulation(DefaultStartingConditions(), 1)
+	adult := &MinimalHuman{Age: 30, Gender: "male", Health: 70, IsAlive: true}
+	designed := dailyMortalityChance(adult, sim.State)
+	designedFood := knownEffects(sim.State).food
+
+	sim.SetRates(&models.SettlementRates{FoodYield: 1.5, ScienceYield: 2, Fertility: 0, Mortality: 2})
+	if got := dailyMortalityChance(adult, sim.State); math.Abs(got-2*designed) > 1e-12 {
+		t.Errorf("Expected mortality doubled to %g, got %g", 2*designed, got)
+	}
+	if got := knownEffects(sim.State).food; got != 1.5*designedFood {
+		t.Errorf("Expected food yield scaled to %g, got %g", 1.5*designedFood, got)
+	}
+	if got := knownEffects(sim.State).science; got != 2 {
+		t.Errorf("Expected science yield scaled to 2, got %g", got)
+	}
+
+	// Without fertility no one conceives
+	sim.AdvanceDays(DaysPerYear)
+	for _, human := range sim.State.Humans {
+		if human.PregnancyDaysRemaining > 0 {
+			t.Fatal("Expected no pregnancies without fertility")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SnapshotVersion is the snapshot format, bumped whenever the simulation
+// state changes in a way older snapshots cannot be resumed from
+const SnapshotVersion = 1
+
+// Snapshot is a paused simulation: its people, resources and conditions and
+// its position in the random stream. Resuming a snapshot continues the run
+// exactly as if it had never stopped. Snapshots encode with encoding/json
+// and encoding/gob.
+type Snapshot struct {
+	Version    int
+	State      MinimalCivilizationState
+	Conditions StartingConditions
+	RNG        int64 // Random generator state
+}
+
+// Snapshot captures the simulation's current state; later steps do not
+// change it
+func (s *Simulation) Snapshot() *Snapshot {
+	return &Snapshot{
+		Version:    SnapshotVersion,
+		State:      copyState(s.State),
+		Conditions: s.Conditions,
+		RNG:        s.rng.seed,
+	}
+}
+
+// Resume creates a simulation that continues from a snapshot, which is left
+// unchanged
+func Resume(snapshot *Snapshot) (*Simulation, error) {
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d, want %d", snapshot.Version, SnapshotVersion)
+	}
+	state := copyState(&snapshot.State)
+	return &Simulation{
+		State:      &state,
+		Conditions: snapshot.Conditions,
+		rng:        &randomGenerator{seed: snapshot.RNG},
+	}, nil
+}
+
+// Encode writes the snapshot as JSON
+func (snapshot *Snapshot) Encode(w io.Writer) error {
+	return json.NewEncoder(w).Encode(snapshot)
+}
+
+// DecodeSnapshot reads a snapshot written by Encode
+func DecodeSnapshot(r io.Reader) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d, want %d", snapshot.Version, SnapshotVersion)
+	}
+	return &snapshot, nil
+}
+
+// copyState returns a deep copy of a civilization's state
+func copyState(state *MinimalCivilizationState) MinimalCivilizationState {
+	copied := *state
+	copied.Humans = make([]*MinimalHuman, len(state.Humans))
+	for i, human := range state.Humans {
+		h := *human
+		copied.Humans[i] = &h
+	}
+	copied.Discoveries = append([]string(nil), state.Discoveries...)
+	return copied
+}
@@ -0,0 +1,203 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+// TechTree is the technologies a civilization can research, in the order
+// they unlock when its science reaches several at once. Technologies are
+// researched once their prerequisites are known and the civilization's
+// total science reaches their cost; their effects apply from then on, so
+// new technologies need only a new entry.
+type TechTree struct {
+	Technologies []models.Technology
+}
+
+// defaultTechTree is the tree simulations research unless given another
+var defaultTechTree = &TechTree{Technologies: []models.Technology{
+	{
+		Name:            TechFireMastery,
+		Description:     "Cooking makes food go further",
+		ScienceRequired: FireMasteryScienceRequired,
+		Effects:         models.TechEffects{FoodMultiplier: FireMasteryFoodBonus},
+	},
+	{
+		Name:            TechDomestication,
+		Description:     "Tamed herds feed the settlement and children tend them",
+		Prerequisites:   []string{TechFireMastery},
+		ScienceRequired: DomesticationScienceRequired,
+		NeedsLivestock:  true,
+		Effects:         models.TechEffects{HerdFood: HerdFoodBonus, ChildWorkHours: WorkHoursChildHerding},
+	},
+	{
+		Name:            TechHusbandry,
+		Description:     "Managed pastures feed more and are safer to work",
+		Prerequisites:   []string{TechDomestication},
+		ScienceRequired: HusbandryScienceRequired,
+		Effects:         models.TechEffects{HerdFood: PastureFoodBonus, ChildAccidentFactor: HusbandryAccidentFactor},
+	},
+	{
+		Name:            TechMidwifery,
+		Description:     "Midwives attend births",
+		Prerequisites:   []string{TechFireMastery},
+		ScienceRequired: MidwiferyScienceRequired,
+		Effects:         models.TechEffects{MaternalMortalityFactor: MidwiferyMaternalFactor},
+	},
+	{
+		Name:            TechShelterBuilding,
+		Description:     "Built shelters protect the young",
+		Prerequisites:   []string{TechFireMastery},
+		ScienceRequired: ShelterBuildingScienceRequired,
+		Effects:         models.TechEffects{InfantSurvival: ShelterInfantSurvival, ChildMortalityFactor: ShelterChildMortalityFactor},
+	},
+	{
+		Name:            TechHerbalMedicine,
+		Description:     "Remedies for childhood illness",
+		Prerequisites:   []string{TechShelterBuilding},
+		ScienceRequired: HerbalMedicineScienceRequired,
+		Effects:         models.TechEffects{InfantSurvival: HerbalInfantSurvival, ChildMortalityFactor: HerbalChildMortalityFactor},
+	},
+}}
+
+// DefaultTechTree returns a copy of the standard technology tree
+func DefaultTechTree() *TechTree {
+	tree := &TechTree{Technologies: make([]models.Technology, len(defaultTechTree.Technologies))}
+	for i, tech := range defaultTechTree.Technologies {
+		tech.Prerequisites = append([]string(nil), tech.Prerequisites...)
+		tree.Technologies[i] = tech
+	}
+	return tree
+}
+
+// LoadTechTree reads a technology tree written as a JSON array of
+// technologies, checking it is well formed
+func LoadTechTree(r io.Reader) (*TechTree, error) {
+	tree := &TechTree{}
+	if err := json.NewDecoder(r).Decode(&tree.Technologies); err != nil {
+		return nil, fmt.Errorf("reading tech tree: %w", err)
+	}
+	if err := tree.Validate(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Validate checks that every technology is named once, costs no negative
+// science and only needs technologies listed before it
+func (t *TechTree) Validate() error {
+	seen := make(map[string]bool, len(t.Technologies))
+	for _, tech := range t.Technologies {
+		if tech.Name == "" {
+			return fmt.Errorf("tech tree has a technology without a name")
+		}
+		if seen[tech.Name] {
+			return fmt.Errorf("tech tree lists %s twice", tech.Name)
+		}
+		if tech.ScienceRequired < 0 || math.IsNaN(tech.ScienceRequired) {
+			return fmt.Errorf("%s costs %g science", tech.Name, tech.ScienceRequired)
+		}
+		for _, prerequisite := range tech.Prerequisites {
+			if !seen[prerequisite] {
+				return fmt.Errorf("%s needs %s, which is not listed before it", tech.Name, prerequisite)
+			}
+		}
+		seen[tech.Name] = true
+	}
+	return nil
+}
+
+// Technology looks up a technology by name
+func (t *TechTree) Technology(name string) (models.Technology, bool) {
+	for _, tech := range t.Technologies {
+		if tech.Name == name {
+			return tech, true
+		}
+	}
+	return models.Technology{}, false
+}
+
+// techEffects are the combined effects of the technologies a civilization
+// knows
+type techEffects struct {
+	food, science     float64
+	herdFood          float64
+	childWorkHours    float64
+	childAccident     float64
+	childMortality    float64
+	infantSurvival    float64 // Chance a newborn survives birth
+	maternalMortality float64
+}
+
+// knownEffects combines the effects of the technologies a civilization
+// knows: multipliers compound on the yields its rates set, herds and
+// children's days take the best known, the lowest child mortality applies
+// and infant survival adds up. The standard technologies are known by their
+// flags on the state, any others by Discoveries.
+func knownEffects(state *MinimalCivilizationState) techEffects {
+	rates := state.settlementRates()
+	effects := techEffects{food: rates.FoodYield, science: rates.ScienceYield, childWorkHours: WorkHoursChild, childAccident: 1, childMortality: 1,
+		infantSurvival: InfantSurvivalRate, maternalMortality: 1}
+	for _, tech := range state.techTree().Technologies {
+		if !knows(state, tech.Name) {
+			continue
+		}
+		e := tech.Effects
+		if e.FoodMultiplier != 0 {
+			effects.food *= e.FoodMultiplier
+		}
+		if e.ScienceMultiplier != 0 {
+			effects.science *= e.ScienceMultiplier
+		}
+		effects.herdFood = math.Max(effects.herdFood, e.HerdFood)
+		effects.childWorkHours = math.Max(effects.childWorkHours, e.ChildWorkHours)
+		if e.ChildAccidentFactor != 0 {
+			effects.childAccident *= e.ChildAccidentFactor
+		}
+		if e.ChildMortalityFactor != 0 {
+			effects.childMortality = math.Min(effects.childMortality, e.ChildMortalityFactor)
+		}
+		effects.infantSurvival += e.InfantSurvival
+		if e.MaternalMortalityFactor != 0 {
+			effects.maternalMortality *= e.MaternalMortalityFactor
+		}
+	}
+	return effects
+}
+
+// techFlag returns the state's flag for a standard technology, or nil for
+// any other
+func techFlag(state *MinimalCivilizationState, tech string) *bool {
+	switch tech {
+	case TechFireMastery:
+		return &state.HasFireMastery
+	case TechDomestication:
+		return &state.HasDomestication
+	case TechHusbandry:
+		return &state.HasHusbandry
+	case TechMidwifery:
+		return &state.HasMidwifery
+	case TechShelterBuilding:
+		return &state.HasShelterBuilding
+	case TechHerbalMedicine:
+		return &state.HasHerbalMedicine
+	}
+	return nil
+}
+
+// knows reports whether a civilization knows a technology
+func knows(state *MinimalCivilizationState, tech string) bool {
+	if flag := techFlag(state, tech); flag != nil {
+		return *flag
+	}
+	for _, discovery := range state.Discoveries {
+		if discovery == tech {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Trace event kinds
+const (
+	TraceBirth        = "birth"
+	TraceConception   = "conception"
+	TraceHealthCrisis = "health_crisis"
+	TraceDeath        = "death"
+	TraceEmigration   = "emigration"
+)
+
+// Causes of death, see Mortality
+const (
+	CauseStarvation = "starvation"
+	CauseAge        = "age"
+	CauseDisease    = "disease"
+	CauseAccident   = "accident"
+	CauseCombat     = "combat"
+	CauseChildbirth = "childbirth"
+)
+
+// TraceEvent is one event in a human's life
+type TraceEvent struct {
+	Day     int
+	HumanID string
+	Kind    string // One of the Trace* kinds
+	Detail  string // Cause of a death, mother of a newborn or health at a crisis
+}
+
+// Trace logs the life events of every human in a simulation so strange
+// outcomes can be followed person by person. Tracing is off unless a
+// Simulation is given a Trace; the zero value is ready to use. Aggregated
+// steps date their events to the end of the period, and a conception
+// delivered within the same period is logged alongside the birth.
+type Trace struct {
+	Events []TraceEvent
+
+	pregnant map[string]bool // Mothers whose conception is logged
+	critical map[string]bool // Humans whose health crisis is logged
+}
+
+// ForHuman returns the events in one human's life, oldest first
+func (t *Trace) ForHuman(id string) []TraceEvent {
+	var events []TraceEvent
+	for _, event := range t.Events {
+		if event.HumanID == id {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Write writes the trace as one tab-separated line per event
+func (t *Trace) Write(w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	for _, event := range t.Events {
+		fmt.Fprintf(buffered, "%d\t%s\t%s\t%s\n", event.Day, event.HumanID, event.Kind, event.Detail)
+	}
+	return buffered.Flush()
+}
+
+// record appends an event; a nil trace records nothing
+func (t *Trace) record(day int, human *MinimalHuman, kind, detail string) {
+	if t == nil {
+		return
+	}
+	t.Events = append(t.Events, TraceEvent{Day: day, HumanID: human.ID, Kind: kind, Detail: detail})
+}
+
+// death records a human's death and its cause
+func (t *Trace) death(day int, human *MinimalHuman, cause string) {
+	t.record(day, human, TraceDeath, cause)
+}
+
+// deliveries records the births of a period and the mothers lost to them
+func (t *Trace) deliveries(day int, delivered *deliveries) {
+	if t == nil {
+		return
+	}
+	for _, mother := range delivered.mothers {
+		if !t.pregnant[mother.ID] {
+			t.record(day, mother, TraceConception, "")
+		}
+		delete(t.pregnant, mother.ID)
+	}
+	for i, child := range delivered.newborns {
+		t.record(day, child, TraceBirth, "mother "+delivered.newbornMothers[i].ID)
+	}
+	for _, mother := range delivered.mothersLost {
+		t.death(day, mother, CauseChildbirth)
+	}
+}
+
+// observe records the conceptions and health crises visible at the end of
+// a step, each once until the pregnancy or crisis is over
+func (t *Trace) observe(day int, humans []*MinimalHuman) {
+	if t == nil {
+		return
+	}
+	if t.pregnant == nil {
+		t.pregnant = make(map[string]bool)
+		t.critical = make(map[string]bool)
+	}
+	for _, human := range humans {
+		if !human.IsAlive {
+			delete(t.pregnant, human.ID)
+			delete(t.critical, human.ID)
+			continue
+		}
+		switch {
+		case human.PregnancyDaysRemaining > 0 && !t.pregnant[human.ID]:
+			t.pregnant[human.ID] = true
+			t.record(day, human, TraceConception, "")
+		case human.PregnancyDaysRemaining == 0:
+			delete(t.pregnant, human.ID)
+		}
+		switch {
+		case human.Health < HealthCritical && !t.critical[human.ID]:
+			t.critical[human.ID] = true
+			t.record(day, human, TraceHealthCrisis, fmt.Sprintf("health %.1f", human.Health))
+		case human.Health >= HealthCritical:
+			delete(t.critical, human.ID)
+		}
+	}
+}
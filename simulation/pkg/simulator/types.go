@@ -1,5 +1,7 @@
 package simulator
 
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
 // MinimalHuman represents a single human in the minimal simulation
 type MinimalHuman struct {
 	ID                     string  // Unique identifier
@@ -23,10 +25,40 @@ type MinimalCivilizationState struct {
 	FoodAllocationRatio float64 // 0.0 to 1.0 (default 0.8 = 80%)
 
 	// Technology
-	HasFireMastery bool // Research goal (unlocks at 100 science)
+	HasFireMastery     bool // Research goal (unlocks at 100 science)
+	HasDomestication   bool // Tamed herds (follows Fire Mastery where livestock roams)
+	HasHusbandry       bool // Managed pastures (follows Domestication)
+	HasMidwifery       bool // Safer childbirth (follows Fire Mastery)
+	HasShelterBuilding bool // Built shelters protect the young (follows Fire Mastery)
+	HasHerbalMedicine  bool // Remedies for childhood illness (follows Shelter Building)
+
+	// Discoveries are the known technologies of the tech tree beyond the
+	// standard ones flagged above
+	Discoveries []string
 
 	// Simulation State
-	CurrentDay int // Day counter (increments until completion or failure)
+	CurrentDay    int // Day counter (increments until completion or failure)
+	LastCrisisDay int // Day the last overcrowding crisis struck (0 if none)
+
+	tree  *TechTree               // Technologies the civilization can research (nil for the default tree)
+	rates *models.SettlementRates // Scales of the design rates it lives by (nil plays them as designed)
+}
+
+// techTree returns the technologies the civilization can research
+func (s *MinimalCivilizationState) techTree() *TechTree {
+	if s.tree != nil {
+		return s.tree
+	}
+	return defaultTechTree
+}
+
+// settlementRates returns the scales of the design rates the civilization
+// lives by
+func (s *MinimalCivilizationState) settlementRates() models.SettlementRates {
+	if s.rates != nil {
+		return *s.rates
+	}
+	return models.DesignedSettlementRates
 }
 
 // StartingConditions defines the initial conditions for a simulation
@@ -36,21 +68,84 @@ type StartingConditions struct {
 	StartingHealthMax     float64 // Maximum starting health
 	FoodStockpile         float64 // Starting food units
 	FoodAllocationRatio   float64 // Default food allocation ratio
-	TerrainMultiplier     float64 // Terrain food production multiplier (1.0 = normal)
+	TerrainMultiplier     float64 // Terrain food production multiplier (1.0 = normal), see terrain.AreaMultiplier
+	ShelterCapacity       int     // People natural shelter protects in winter, see terrain.ShelterCapacity
+	Pollution             float64 // Average pollution of the surroundings (0-1), see terrain.AveragePollution
+	Livestock             int     // Nearby tiles with herds to domesticate, see terrain.LivestockTiles
+	Trade                 float64 // Extra share of food from trade with connected settlements, see terrain.RiverTrade
+	ChildLabor            float64 // Share of children aged 10-15 put to work (0-1), a policy choice
+	CarryingCapacity      int     // People the region supports before overcrowding crises strike (0 = no limit), see terrain.CarryingCapacity
+	Unrest                float64 // Share of food lost to crime and disorder (0-1)
+	Tax                   float64 // Share of food taken as tax (0-1), a policy choice
+	Corvee                float64 // Share of work hours drafted for public works (0-1), a policy choice
 }
 
 // DailyMetrics tracks statistics for a single day
 type DailyMetrics struct {
-	Day               int     // Day number
-	Population        int     // Number of alive humans
-	AverageHealth     float64 // Average health of alive humans
-	FoodStockpile     float64 // Current food stockpile
-	SciencePoints     float64 // Current science points
-	FoodProduction    float64 // Food produced this day
-	ScienceProduction float64 // Science produced this day
-	Births            int     // Number of births this day
-	Deaths            int     // Number of deaths this day
-	HasFireMastery    bool    // Whether Fire Mastery is unlocked
+	Day               int       // Day number
+	Population        int       // Number of alive humans
+	AverageHealth     float64   // Average health of alive humans
+	FoodStockpile     float64   // Current food stockpile
+	SciencePoints     float64   // Current science points
+	FoodProduction    float64   // Food produced this day
+	ScienceProduction float64   // Science produced this day
+	FoodTaxed         float64   // Food taken as tax this day
+	CorveeHours       float64   // Work hours drafted for public works this day
+	Births            int       // Number of births this day
+	Twins             int       // Births this day that brought twins
+	Crisis            string    // Overcrowding crisis that struck this day, see Crisis* ("" if none)
+	Migrants          int       // People who left in a migration crisis
+	Deaths            int       // Number of deaths this day
+	Mortality         Mortality // Deaths this day by cause
+	HasFireMastery    bool      // Whether Fire Mastery is unlocked
+}
+
+// Mortality counts deaths by cause
+type Mortality struct {
+	Starvation int // Died weakened by hunger
+	Age        int // Died of old age
+	Disease    int // Died of illness, the fate of the young and healthy
+	Accident   int // Working children killed in accidents
+	Combat     int // Killed in fighting, defending their settlement or in overcrowded strife
+	Childbirth int // Mothers who died giving birth
+}
+
+// Add counts other's deaths in m
+func (m *Mortality) Add(other Mortality) {
+	m.Starvation += other.Starvation
+	m.Age += other.Age
+	m.Disease += other.Disease
+	m.Accident += other.Accident
+	m.Combat += other.Combat
+	m.Childbirth += other.Childbirth
+}
+
+// Total returns the number of deaths of every cause
+func (m Mortality) Total() int {
+	return m.Starvation + m.Age + m.Disease + m.Accident + m.Combat + m.Childbirth
+}
+
+// Add extends the period m covers with the period after it: the flows are
+// summed, the later period's crisis is kept and its closing state is taken
+func (m *DailyMetrics) Add(later *DailyMetrics) {
+	m.Day = later.Day
+	m.Population = later.Population
+	m.AverageHealth = later.AverageHealth
+	m.FoodStockpile = later.FoodStockpile
+	m.SciencePoints = later.SciencePoints
+	m.FoodProduction += later.FoodProduction
+	m.ScienceProduction += later.ScienceProduction
+	m.FoodTaxed += later.FoodTaxed
+	m.CorveeHours += later.CorveeHours
+	m.Births += later.Births
+	m.Twins += later.Twins
+	if later.Crisis != "" {
+		m.Crisis = later.Crisis
+	}
+	m.Migrants += later.Migrants
+	m.Deaths += later.Deaths
+	m.Mortality.Add(later.Mortality)
+	m.HasFireMastery = later.HasFireMastery
 }
 
 // ViabilityResult contains the results of a viability assessment
@@ -59,19 +154,24 @@ type ViabilityResult struct {
 	FailureReasons []string // List of failure reasons if not viable
 
 	// Metrics
-	FinalPopulation      int     // Final population
-	FinalScience         float64 // Final science points
-	AverageHealth        float64 // Average health across entire simulation
-	DaysToFireMastery    int     // Days until Fire Mastery was unlocked (-1 if never)
-	DaysToNonViable      int     // Days until population became non-viable (-1 if never)
-	FinalAverageHealth   float64 // Final average health
-	PeakPopulation       int     // Peak population during simulation
-	MinimumPopulation    int     // Minimum population during simulation
-	FireMasteryUnlocked  bool    // Whether Fire Mastery was unlocked
-	TotalBirths          int     // Total births during simulation
-	HasFireMastery       bool    // Final Fire Mastery status
-
-	// All daily metrics for analysis
+	FinalPopulation     int            // Final population
+	FinalScience        float64        // Final science points
+	AverageHealth       float64        // Average health across entire simulation
+	DaysToFireMastery   int            // Days until Fire Mastery was unlocked (-1 if never)
+	DaysToNonViable     int            // Days until population became non-viable (-1 if never)
+	FinalAverageHealth  float64        // Final average health
+	PeakPopulation      int            // Peak population during simulation
+	MinimumPopulation   int            // Minimum population during simulation
+	FireMasteryUnlocked bool           // Whether Fire Mastery was unlocked
+	TotalBirths         int            // Total births during simulation
+	TotalTwins          int            // Births that brought twins during simulation
+	Crises              []CrisisRecord // Overcrowding crises, in the order they struck
+	Mortality           Mortality      // Deaths during simulation by cause
+	HasFireMastery      bool           // Final Fire Mastery status
+	Final               *Snapshot      // State at the end of the run, to resume a later segment from
+	Trace               *Trace         // Each human's life events, when SimulationConfig.Trace is set
+
+	// Daily metrics retained by the configured MetricsSink
 	AllMetrics []*DailyMetrics
 }
 
@@ -79,5 +179,11 @@ type ViabilityResult struct {
 type SimulationConfig struct {
 	Seed                int                 // Random seed for deterministic simulation
 	StartingConditions  StartingConditions  // Initial conditions
-	MaxDays             int                 // Maximum days to simulate (default 1825 = 5 years)
+	MaxDays             int                 // Day to simulate until (default 1825 = 5 years), counted from the snapshot's start when resuming
+	MetricsSink         MetricsSink         // Daily metrics retention (default keeps every day)
+	Resume              *Snapshot           // Continue a saved run instead of creating a population; Seed and StartingConditions are ignored
+	Trace               bool                // Record each human's life events in ViabilityResult.Trace
+	Policy              Policy              // Decides the labor allocation each day (default keeps the starting FoodAllocationRatio)
+	TechTree            *TechTree           // Technologies the civilization can research (default DefaultTechTree)
+	Rates               *models.SettlementRates // Scales of the design rates the civilization lives by (default models.DesignedSettlementRates)
 }
@@ -0,0 +1,18 @@
+package terrain
+
+// DefenseModifiers multiply the strength of a unit defending on each terrain;
+// terrain not listed defends at 1.0
+var DefenseModifiers = map[string]float64{
+	Forest:   1.25,
+	Jungle:   1.25,
+	Hills:    1.5,
+	Mountain: 2.0,
+}
+
+// DefenseModifier returns the strength multiplier for defending on a terrain
+func DefenseModifier(terrainType string) float64 {
+	if modifier, ok := DefenseModifiers[terrainType]; ok {
+		return modifier
+	}
+	return 1.0
+}
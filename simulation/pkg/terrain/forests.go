@@ -0,0 +1,97 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+const (
+	ChopProduction       = 30   // Production a felled forest yields before its timber is counted
+	WoodPerProduction    = 10   // Remaining WOOD units per extra point of chop production
+	ForestRegrowthChance = 0.05 // Chance per forested neighbor that grassland reverts each environment tick
+	ForestMoistureRadius = 2    // How far a forest's moisture reaches into the surrounding soil
+	ForestMoisture       = 0.02 // Fertility a forest lends each tile within its moisture radius
+
+	minFertility = 0.1 // Floor for soil dried out by deforestation
+	maxFertility = 1.5 // Ceiling for soil moistened by regrowth
+)
+
+// IsForest reports whether a terrain type is woodland workers can fell
+func IsForest(terrainType string) bool {
+	return terrainType == Forest || terrainType == Jungle
+}
+
+// ChopYield returns the one-time production felling a forest tile gives,
+// more for tiles with timber left on them
+func ChopYield(tile *models.MapTile) int {
+	if !IsForest(tile.TerrainType) {
+		return 0
+	}
+	return ChopProduction + tile.ResourceQuantities["WOOD"]/WoodPerProduction
+}
+
+// ClearForest turns a forest tile into grassland, taking its timber and game
+// with it. It reports whether the tile was forested.
+func ClearForest(tile *models.MapTile) bool {
+	if !IsForest(tile.TerrainType) {
+		return false
+	}
+	tile.TerrainType = Grassland
+	setWoodland(tile, false)
+	return true
+}
+
+// RegrowForest turns grassland back into forest of the given type, stocked
+// with fresh timber. It reports whether the tile changed.
+func RegrowForest(tile *models.MapTile, forestType string) bool {
+	if tile.TerrainType != Grassland || !IsForest(forestType) || len(tile.Improvements) > 0 {
+		return false
+	}
+	tile.TerrainType = forestType
+	setWoodland(tile, true)
+	return true
+}
+
+// setWoodland adds or removes the WOOD resource a forest carries; GAME only
+// leaves with the trees
+func setWoodland(tile *models.MapTile, wooded bool) {
+	resources := tile.Resources[:0:0]
+	for _, resource := range tile.Resources {
+		if resource != "WOOD" && (wooded || resource != "GAME") {
+			resources = append(resources, resource)
+		}
+	}
+	if wooded {
+		resources = append(resources, "WOOD")
+		if tile.ResourceQuantities == nil {
+			tile.ResourceQuantities = make(map[string]int)
+		}
+		tile.ResourceQuantities["WOOD"] = ResourceInfos["WOOD"].Quantity
+	} else {
+		delete(tile.ResourceQuantities, "WOOD")
+		delete(tile.ResourceQuantities, "GAME")
+	}
+	if len(resources) == 0 {
+		resources = nil
+	}
+	tile.Resources = resources
+}
+
+// ShiftMoisture moves a tile's natural and current fertility by delta as the
+// forests around it are cleared or regrow. Tiles without tracked soil are
+// left alone. It reports whether the tile changed.
+func ShiftMoisture(tile *models.MapTile, delta float64) bool {
+	if tile.NaturalFertility <= 0 || delta == 0 {
+		return false
+	}
+	natural := roundFertility(min(max(tile.NaturalFertility+delta, minFertility), maxFertility))
+	current := roundFertility(min(max(tile.Fertility+delta, minFertility), natural))
+	if natural == tile.NaturalFertility && current == tile.Fertility {
+		return false
+	}
+	tile.NaturalFertility = natural
+	tile.Fertility = current
+	return true
+}
+
+// roundFertility rounds to the hundredths fertility is generated at
+func roundFertility(value float64) float64 {
+	return float64(int(value*100+0.5)) / 100
+}
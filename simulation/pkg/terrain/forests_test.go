@@ -0,0 +1,56 @@
+package terrain
+
+import (
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestForestClearingAndRegrowth(t *testing.T) {
+	tile := &models.MapTile{
+		TerrainType:        Forest,
+		Resources:          []string{"WOOD", "GAME"},
+		ResourceQuantities: map[string]int{"WOOD": 100, "GAME": 40},
+	}
+	if yield := ChopYield(tile); yield != ChopProduction+100/WoodPerProduction {
+		t.Errorf("Expected remaining timber to add to the chop yield, got %d", yield)
+	}
+	if !ClearForest(tile) || tile.TerrainType != Grassland {
+		t.Fatalf("Expected the forest cleared to grassland, got %s", tile.TerrainType)
+	}
+	if len(tile.Resources) != 0 || len(tile.ResourceQuantities) != 0 {
+		t.Errorf("Expected timber and game to go with the trees, got %v %v", tile.Resources, tile.ResourceQuantities)
+	}
+	if ClearForest(tile) || ChopYield(tile) != 0 {
+		t.Error("Grassland has no forest to clear")
+	}
+
+	if !RegrowForest(tile, Jungle) || tile.TerrainType != Jungle {
+		t.Fatalf("Expected grassland to regrow as jungle, got %s", tile.TerrainType)
+	}
+	if tile.ResourceQuantities["WOOD"] != ResourceInfos["WOOD"].Quantity {
+		t.Errorf("Expected regrown forest to carry fresh timber, got %v", tile.ResourceQuantities)
+	}
+
+	farmed := &models.MapTile{TerrainType: Grassland, Improvements: []string{models.ImprovementFarm}}
+	if RegrowForest(farmed, Forest) {
+		t.Error("Forest should not reclaim improved land")
+	}
+}
+
+func TestShiftMoisture(t *testing.T) {
+	if ShiftMoisture(&models.MapTile{TerrainType: Ocean}, -ForestMoisture) {
+		t.Error("Tiles without tracked soil should be unchanged")
+	}
+
+	tile := &models.MapTile{TerrainType: Plains, Fertility: 0.5, NaturalFertility: 0.8}
+	if !ShiftMoisture(tile, -ForestMoisture) || tile.NaturalFertility != 0.78 || tile.Fertility != 0.48 {
+		t.Fatalf("Expected deforestation to dry the soil, got %.2f/%.2f", tile.Fertility, tile.NaturalFertility)
+	}
+	for i := 0; i < 100; i++ {
+		ShiftMoisture(tile, -ForestMoisture)
+	}
+	if tile.NaturalFertility != minFertility || tile.Fertility != minFertility {
+		t.Errorf("Expected dried soil to bottom out at %.2f, got %.2f/%.2f", minFertility, tile.Fertility, tile.NaturalFertility)
+	}
+}
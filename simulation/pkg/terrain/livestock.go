@@ -0,0 +1,43 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+// PastureModifier is the yield a pasture adds to a tile with managed herds
+var PastureModifier = Yield{Food: 1.2, Production: 1.05, Science: 1.0}
+
+// IsLivestock reports whether a resource is a herd that can be domesticated
+func IsLivestock(resource string) bool {
+	return resource == "CATTLE" || resource == "GAME"
+}
+
+// HasLivestock reports whether a tile still carries herds
+func HasLivestock(tile *models.MapTile) bool {
+	for _, resource := range ActiveResources(tile) {
+		if IsLivestock(resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// LivestockTiles counts the tiles with herds among the given tiles; it is
+// the simulator's Livestock for a settlement working those tiles
+func LivestockTiles(tiles []*models.MapTile) int {
+	count := 0
+	for _, tile := range tiles {
+		if HasLivestock(tile) {
+			count++
+		}
+	}
+	return count
+}
+
+// isPasture reports whether a tile has a pasture on it
+func isPasture(tile *models.MapTile) bool {
+	for _, improvement := range tile.Improvements {
+		if improvement == models.ImprovementPasture {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package terrain
+
+import (
+	"math"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+const (
+	MinePollution             = 0.02 // Pollution a mine adds to its tile each year
+	DenseSettlementPopulation = 500  // Settlements this large pollute their work area
+	SettlementPollution       = 0.01 // Yearly pollution per DenseSettlementPopulation people
+	PollutionRecovery         = 0.01 // Pollution that fades from every tile each year
+	PollutionYieldLoss        = 0.5  // Share of a fully polluted tile's yields lost
+)
+
+// SettlementEmissions returns the pollution a settlement of the given size
+// adds to each tile it works per year; small settlements add none
+func SettlementEmissions(population int) float64 {
+	if population < DenseSettlementPopulation {
+		return 0
+	}
+	return SettlementPollution * float64(population) / DenseSettlementPopulation
+}
+
+// PollutionFactor returns the multiplier pollution leaves on a tile's yields
+func PollutionFactor(tile *models.MapTile) float64 {
+	return 1 - PollutionYieldLoss*tile.Pollution
+}
+
+// AveragePollution returns the mean pollution of the given tiles; it is the
+// simulator's Pollution for a settlement working those tiles
+func AveragePollution(tiles []*models.MapTile) float64 {
+	if len(tiles) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, tile := range tiles {
+		total += tile.Pollution
+	}
+	return total / float64(len(tiles))
+}
+
+// AdvancePollution advances a tile's pollution by one year: emitted is added
+// and PollutionRecovery fades, keeping the value within [0, 1]. It reports
+// whether the tile changed.
+func AdvancePollution(tile *models.MapTile, emitted float64) bool {
+	next := math.Min(math.Max(tile.Pollution+emitted-PollutionRecovery, 0), 1)
+	// Round away floating point drift so clean tiles settle at exactly zero
+	next = math.Round(next*10000) / 10000
+	if next == tile.Pollution {
+		return false
+	}
+	tile.Pollution = next
+	return true
+}
@@ -0,0 +1,38 @@
+package terrain
+
+import (
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestAdvancePollution(t *testing.T) {
+	if SettlementEmissions(DenseSettlementPopulation-1) != 0 {
+		t.Error("Small settlements should not pollute")
+	}
+	if SettlementEmissions(2*DenseSettlementPopulation) != 2*SettlementPollution {
+		t.Error("Emissions should scale with population")
+	}
+
+	tile := &models.MapTile{TerrainType: Grassland}
+	if AdvancePollution(tile, 0) {
+		t.Error("A clean tile with no emissions should not change")
+	}
+
+	for i := 0; i < 1000; i++ {
+		AdvancePollution(tile, MinePollution)
+	}
+	if tile.Pollution != 1 {
+		t.Errorf("Expected pollution to saturate at 1, got %.4f", tile.Pollution)
+	}
+	if factor := PollutionFactor(tile); factor != 1-PollutionYieldLoss {
+		t.Errorf("Expected a ruined tile to lose %.0f%% of its yields, got factor %.2f", PollutionYieldLoss*100, factor)
+	}
+
+	for i := 0; i < 1000; i++ {
+		AdvancePollution(tile, 0)
+	}
+	if tile.Pollution != 0 {
+		t.Errorf("Expected abandoned land to recover fully, got %.4f", tile.Pollution)
+	}
+}
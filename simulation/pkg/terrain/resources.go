@@ -0,0 +1,131 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+// ResourceInfo describes how a resource is consumed when a tile is exploited.
+// Quantities are abstract units; one engine tick is one year.
+type ResourceInfo struct {
+	Strategic  bool // Gates advanced units and buildings, see engine build requirements
+	Renewable  bool
+	Quantity   int    // Starting quantity (and the regrowth cap for renewables)
+	Extraction int    // Units consumed per year while the tile has the extractor
+	Regrowth   int    // Units restored per year (renewables only)
+	Extractor  string // Improvement that exploits the resource; none leaves it untouched
+}
+
+// ResourceInfos is the canonical depletion table for each resource type
+var ResourceInfos = map[string]ResourceInfo{
+	// Finite deposits are removed from the tile once exhausted
+	"IRON":   {Strategic: true, Quantity: 500, Extraction: 1, Extractor: models.ImprovementMine},
+	"COPPER": {Strategic: true, Quantity: 400, Extraction: 1, Extractor: models.ImprovementMine},
+	"COAL":   {Strategic: true, Quantity: 600, Extraction: 1, Extractor: models.ImprovementMine},
+	"GOLD":   {Strategic: true, Quantity: 200, Extraction: 1, Extractor: models.ImprovementMine},
+	"STONE":  {Quantity: 1000, Extraction: 1, Extractor: models.ImprovementMine},
+
+	// Toolstone, found only in caves
+	"FLINT":    {Quantity: 300, Extraction: 1, Extractor: models.ImprovementMine},
+	"OBSIDIAN": {Quantity: 150, Extraction: 1, Extractor: models.ImprovementMine},
+
+	// Renewables regrow every year and only run dry when over-exploited.
+	// Herds are hunted off farmland (a pasture breeds them instead), and
+	// farms clear the timber of the land they take; no improvement fishes
+	"WHEAT":  {Renewable: true, Quantity: 100, Extraction: 2, Regrowth: 1, Extractor: models.ImprovementFarm},
+	"CATTLE": {Renewable: true, Quantity: 100, Extraction: 2, Regrowth: 1, Extractor: models.ImprovementFarm},
+	"FISH":   {Renewable: true, Quantity: 100, Extraction: 3, Regrowth: 2},
+	"GAME":   {Renewable: true, Quantity: 50, Extraction: 2, Regrowth: 1, Extractor: models.ImprovementFarm},
+	"WOOD":   {Renewable: true, Quantity: 200, Extraction: 2, Regrowth: 1, Extractor: models.ImprovementFarm},
+}
+
+// IsStrategic reports whether a resource gates units or buildings
+func IsStrategic(resource string) bool {
+	return ResourceInfos[resource].Strategic
+}
+
+// InitialQuantities returns the starting quantity of each known resource
+func InitialQuantities(resources []string) map[string]int {
+	quantities := make(map[string]int)
+	for _, resource := range resources {
+		if info, ok := ResourceInfos[resource]; ok {
+			quantities[resource] = info.Quantity
+		}
+	}
+	if len(quantities) == 0 {
+		return nil
+	}
+	return quantities
+}
+
+// ActiveResources returns the tile's resources that still yield output.
+// Resources without a tracked quantity (legacy tiles) are always active.
+func ActiveResources(tile *models.MapTile) []string {
+	active := make([]string, 0, len(tile.Resources))
+	for _, resource := range tile.Resources {
+		if quantity, tracked := tile.ResourceQuantities[resource]; tracked && quantity <= 0 {
+			continue
+		}
+		active = append(active, resource)
+	}
+	return active
+}
+
+// DepleteResources advances a tile's resource quantities by one year.
+// Resources are exploited only by their extractor (a road never extracts),
+// except herds on a pasture, which are bred rather than hunted; renewables
+// regrow up to their starting quantity and exhausted finite deposits are
+// removed from the tile.
+// It reports whether the tile changed.
+func DepleteResources(tile *models.MapTile) bool {
+	pastured := isPasture(tile)
+	changed := false
+	remaining := tile.Resources[:0:0]
+
+	for _, resource := range tile.Resources {
+		info, known := ResourceInfos[resource]
+		quantity, tracked := tile.ResourceQuantities[resource]
+		if !known || !tracked {
+			remaining = append(remaining, resource)
+			continue
+		}
+
+		next := quantity
+		if exploited(tile, info) && !(pastured && IsLivestock(resource)) {
+			next -= info.Extraction
+		}
+		if info.Renewable {
+			next += info.Regrowth
+			if next > info.Quantity {
+				next = info.Quantity
+			}
+		}
+		if next < 0 {
+			next = 0
+		}
+
+		if next == 0 && !info.Renewable {
+			delete(tile.ResourceQuantities, resource)
+			changed = true
+			continue
+		}
+		if next != quantity {
+			tile.ResourceQuantities[resource] = next
+			changed = true
+		}
+		remaining = append(remaining, resource)
+	}
+
+	tile.Resources = remaining
+	return changed
+}
+
+// exploited reports whether a tile carries the improvement that extracts a resource
+func exploited(tile *models.MapTile, info ResourceInfo) bool {
+	if info.Extractor == "" {
+		return false
+	}
+	for _, improvement := range tile.Improvements {
+		if improvement == info.Extractor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package terrain
+
+import (
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestDepleteResources(t *testing.T) {
+	tile := &models.MapTile{
+		Resources:          []string{"IRON", "WHEAT", "GOLD"},
+		ResourceQuantities: InitialQuantities([]string{"IRON", "WHEAT", "GOLD"}),
+	}
+
+	// Unexploited tiles stay at full quantity
+	if DepleteResources(tile) {
+		t.Error("Unimproved tile at full quantity should not change")
+	}
+
+	tile.Improvements = []string{models.ImprovementMine, models.ImprovementFarm}
+	tile.ResourceQuantities["GOLD"] = 1
+	if !DepleteResources(tile) {
+		t.Fatal("Improved tile should change")
+	}
+	if tile.ResourceQuantities["IRON"] != ResourceInfos["IRON"].Quantity-1 {
+		t.Errorf("IRON should lose one unit, got %d", tile.ResourceQuantities["IRON"])
+	}
+	want := ResourceInfos["WHEAT"].Quantity - ResourceInfos["WHEAT"].Extraction + ResourceInfos["WHEAT"].Regrowth
+	if tile.ResourceQuantities["WHEAT"] != want {
+		t.Errorf("WHEAT should net extraction minus regrowth, got %d want %d", tile.ResourceQuantities["WHEAT"], want)
+	}
+	for _, r := range tile.Resources {
+		if r == "GOLD" {
+			t.Error("Exhausted GOLD should be removed from the tile")
+		}
+	}
+
+	// A dry renewable yields nothing until it regrows
+	tile.ResourceQuantities["WHEAT"] = 0
+	if len(ActiveResources(tile)) != 1 {
+		t.Errorf("Only IRON should be active, got %v", ActiveResources(tile))
+	}
+	tile.Improvements = nil
+	DepleteResources(tile)
+	if tile.ResourceQuantities["WHEAT"] != ResourceInfos["WHEAT"].Regrowth {
+		t.Errorf("Idle WHEAT should regrow, got %d", tile.ResourceQuantities["WHEAT"])
+	}
+}
+
+func TestDepleteResources_OnlyExtractorsExploit(t *testing.T) {
+	tile := &models.MapTile{
+		Resources:          []string{"IRON", "WHEAT"},
+		ResourceQuantities: InitialQuantities([]string{"IRON", "WHEAT"}),
+		Improvements:       []string{models.ImprovementRoad},
+	}
+	if DepleteResources(tile) {
+		t.Errorf("Expected a road to leave the resources beneath it untouched, got %v", tile.ResourceQuantities)
+	}
+
+	// A farm works the wheat but leaves the iron in the ground
+	tile.Improvements = append(tile.Improvements, models.ImprovementFarm)
+	if !DepleteResources(tile) {
+		t.Fatal("Expected the farm to work the wheat")
+	}
+	if tile.ResourceQuantities["IRON"] != ResourceInfos["IRON"].Quantity {
+		t.Errorf("Expected the iron untouched without a mine, got %d", tile.ResourceQuantities["IRON"])
+	}
+}
+
+func TestPasture(t *testing.T) {
+	tile := &models.MapTile{
+		TerrainType:        Grassland,
+		Resources:          []string{"CATTLE"},
+		ResourceQuantities: map[string]int{"CATTLE": 100},
+		Improvements:       []string{models.ImprovementPasture},
+	}
+	if DepleteResources(tile) || tile.ResourceQuantities["CATTLE"] != 100 {
+		t.Errorf("Expected pastured herds bred rather than hunted, got %d", tile.ResourceQuantities["CATTLE"])
+	}
+	pastured := TileMultiplier(tile).Food
+
+	tile.Improvements = []string{models.ImprovementFarm}
+	if !DepleteResources(tile) || tile.ResourceQuantities["CATTLE"] != 99 {
+		t.Errorf("Expected a farm to hunt the herd down, got %d", tile.ResourceQuantities["CATTLE"])
+	}
+	if farmed := TileMultiplier(tile).Food; pastured != farmed*PastureModifier.Food {
+		t.Errorf("Expected the pasture to add to the herd's food, got %.3f vs %.3f", pastured, farmed)
+	}
+	if LivestockTiles([]*models.MapTile{tile, {TerrainType: Plains}}) != 1 {
+		t.Error("Expected one tile with livestock")
+	}
+}
@@ -0,0 +1,59 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+// RoadMoveCost is the cost of a step between two tiles with roads: a unit
+// covers three road tiles for the cost of one plain step
+const RoadMoveCost = 1.0 / 3
+
+const (
+	RiverMoveCost    = 0.5 // Cost of a step between two tiles of one river system; a plain step costs 1
+	MarshMoveCost    = 2.0 // Cost of a step into or out of a marsh off the river
+	MaxRiverPartners = 4   // Most river trading partners a settlement profits from
+)
+
+// SameRiver reports whether two tiles lie on the same river system
+func SameRiver(a, b *models.MapTile) bool {
+	return a != nil && b != nil && a.RiverID != 0 && a.RiverID == b.RiverID
+}
+
+// MoveCost returns the cost of stepping between two adjacent tiles: units
+// travel by road for least, along a river for less and wade through
+// marshes for more. A step costs the same either way, which route planning
+// relies on. Unknown tiles cost a plain step.
+func MoveCost(from, to *models.MapTile) float64 {
+	if hasRoad(from) && hasRoad(to) {
+		return RoadMoveCost
+	}
+	if SameRiver(from, to) {
+		return RiverMoveCost
+	}
+	if isMarsh(from) || isMarsh(to) {
+		return MarshMoveCost
+	}
+	return 1.0
+}
+
+// RiverTrade returns the extra share of food a settlement earns under the
+// rules from the settlements it shares a river system with
+func RiverTrade(rules *models.Ruleset, partners int) float64 {
+	return rules.RiverTradeBonus * float64(min(partners, MaxRiverPartners))
+}
+
+// hasRoad reports whether a known tile has a road
+func hasRoad(tile *models.MapTile) bool {
+	if tile == nil {
+		return false
+	}
+	for _, improvement := range tile.Improvements {
+		if improvement == models.ImprovementRoad {
+			return true
+		}
+	}
+	return false
+}
+
+// isMarsh reports whether a tile is known marsh
+func isMarsh(tile *models.MapTile) bool {
+	return tile != nil && tile.TerrainType == Marsh
+}
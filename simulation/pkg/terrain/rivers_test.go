@@ -0,0 +1,36 @@
+package terrain
+
+import (
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestMoveCost(t *testing.T) {
+	river := func(terrain string) *models.MapTile {
+		return &models.MapTile{TerrainType: terrain, HasRiver: true, RiverID: 1}
+	}
+	plain := &models.MapTile{TerrainType: Grassland}
+	marsh := &models.MapTile{TerrainType: Marsh}
+	road := &models.MapTile{TerrainType: Marsh, Improvements: []string{models.ImprovementRoad}}
+
+	tests := []struct {
+		name     string
+		from, to *models.MapTile
+		want     float64
+	}{
+		{"plain step", plain, plain, 1.0},
+		{"unknown tile", plain, nil, 1.0},
+		{"along a river", river(Grassland), river(Grassland), RiverMoveCost},
+		{"along a river through marsh", river(Grassland), river(Marsh), RiverMoveCost},
+		{"into a marsh", plain, marsh, MarshMoveCost},
+		{"out of a marsh", marsh, plain, MarshMoveCost},
+		{"by road through marsh", road, road, RoadMoveCost},
+		{"off the road", road, plain, MarshMoveCost},
+	}
+	for _, tt := range tests {
+		if got := MoveCost(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: expected %.1f, got %.1f", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+// moderateElevation is the highest elevation rewarded as easy to settle
+const moderateElevation = 800
+
+// SiteScore is the evaluation of a candidate settlement region
+type SiteScore struct {
+	Score        float64
+	LandTiles    int
+	CoastalTiles int
+	Resources    int
+}
+
+// ScoreSite evaluates the tiles of a candidate settlement region, rewarding
+// productive terrain, moderate elevation, coastal access, resources and
+// terrain diversity. It is shared by map generation (starting regions) and
+// the engine (auto-settle), so both agree on what a good site is.
+func ScoreSite(region []*models.MapTile) SiteScore {
+	site := SiteScore{}
+	terrainTypes := make(map[string]bool)
+
+	for _, tile := range region {
+		if IsWater(tile.TerrainType) {
+			continue
+		}
+		site.LandTiles++
+		if tile.TerrainType != Beach { // A beach is coastal access, not another terrain to work
+			terrainTypes[tile.TerrainType] = true
+		}
+
+		// Prefer productive terrain (canonical yield table)
+		site.Score += SettleValue(tile.TerrainType)
+
+		// Count coastal access
+		if tile.IsCoastal {
+			site.CoastalTiles++
+		}
+
+		// Count resources
+		site.Resources += len(tile.Resources)
+
+		// Prefer moderate elevation
+		if tile.Elevation <= moderateElevation {
+			site.Score += 1.0
+		}
+	}
+
+	if site.CoastalTiles < 3 { // Need some coastal access
+		site.Score -= 10
+	}
+
+	if site.Resources < 2 { // Need some resources
+		site.Score -= 10
+	}
+
+	// Reward terrain diversity (2-4 types ideal)
+	terrainDiversity := len(terrainTypes)
+	if terrainDiversity >= 2 && terrainDiversity <= 4 {
+		site.Score += float64(terrainDiversity) * 5
+	}
+
+	// Add base score for land tiles
+	site.Score += float64(site.LandTiles) / 2.0
+
+	return site
+}
@@ -0,0 +1,56 @@
+package terrain
+
+import (
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestScoreSite(t *testing.T) {
+	region := func(terrainType string) []*models.MapTile {
+		var tiles []*models.MapTile
+		for i := 0; i < 9; i++ {
+			tiles = append(tiles, &models.MapTile{TerrainType: terrainType})
+		}
+		return tiles
+	}
+
+	grassland := ScoreSite(region(Grassland))
+	tundra := ScoreSite(region(Tundra))
+	if grassland.Score <= tundra.Score {
+		t.Errorf("Grassland (%f) should score above tundra (%f)", grassland.Score, tundra.Score)
+	}
+
+	water := ScoreSite(region(Ocean))
+	if water.LandTiles != 0 {
+		t.Errorf("Water region should have no land tiles, got %d", water.LandTiles)
+	}
+
+	coastal := region(Grassland)
+	for _, tile := range coastal[:3] {
+		tile.IsCoastal = true
+	}
+	coastal[0].Resources = []string{"WHEAT", "FISH"}
+	if site := ScoreSite(coastal); site.Score <= grassland.Score || site.CoastalTiles != 3 || site.Resources != 2 {
+		t.Errorf("Coastal access and resources should be rewarded, got %+v vs %+v", site, grassland)
+	}
+
+	// Turning the low coast to beach, as map generation does, keeps the
+	// site as good a start as it was
+	shore := []*models.MapTile{
+		{TerrainType: Grassland, IsCoastal: true, Resources: []string{"FISH"}},
+		{TerrainType: Plains, IsCoastal: true},
+		{TerrainType: Forest, IsCoastal: true, Resources: []string{"WOOD"}},
+		{TerrainType: Desert, IsCoastal: true},
+		{TerrainType: Grassland}, {TerrainType: Plains}, {TerrainType: Forest}, {TerrainType: Desert}, {TerrainType: Hills},
+	}
+	before := ScoreSite(shore)
+	for _, tile := range shore {
+		if tile.IsCoastal {
+			tile.TerrainType = Beach
+		}
+	}
+	if after := ScoreSite(shore); after.Score < before.Score || after.CoastalTiles != 4 {
+		t.Errorf("Beaches should not make a coastal site score lower, got %+v after vs %+v before", after, before)
+	}
+}
@@ -0,0 +1,47 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+const (
+	SoilDepletion   = 0.01  // Fertility a farm exhausts each year
+	SoilRecovery    = 0.005 // Fertility fallow soil regains each year
+	MinSoilFraction = 0.3   // Share of its natural fertility over-farmed soil keeps
+)
+
+// SoilFactor returns a tile's fertility as a food multiplier. Tiles without
+// tracked soil (water, legacy maps) are neutral.
+func SoilFactor(tile *models.MapTile) float64 {
+	if tile.Fertility <= 0 {
+		return 1.0
+	}
+	return tile.Fertility
+}
+
+// DepleteSoil advances a tile's soil by one year: farming exhausts it down to
+// MinSoilFraction of its natural fertility, and soil left fallow recovers.
+// It reports whether the tile changed.
+func DepleteSoil(tile *models.MapTile) bool {
+	if tile.NaturalFertility <= 0 {
+		return false
+	}
+
+	next := tile.Fertility
+	farmed := false
+	for _, improvement := range tile.Improvements {
+		if improvement == models.ImprovementFarm {
+			farmed = true
+			break
+		}
+	}
+	if farmed {
+		next = max(next-SoilDepletion, tile.NaturalFertility*MinSoilFraction)
+	} else {
+		next = min(next+SoilRecovery, tile.NaturalFertility)
+	}
+
+	if next == tile.Fertility {
+		return false
+	}
+	tile.Fertility = next
+	return true
+}
@@ -0,0 +1,44 @@
+package terrain
+
+import (
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestDepleteSoil(t *testing.T) {
+	legacy := &models.MapTile{TerrainType: Grassland, Improvements: []string{models.ImprovementFarm}}
+	if DepleteSoil(legacy) || SoilFactor(legacy) != 1.0 {
+		t.Error("Tiles without tracked soil should be neutral and unchanged")
+	}
+
+	tile := &models.MapTile{
+		TerrainType:      Grassland,
+		Fertility:        1.2,
+		NaturalFertility: 1.2,
+		Improvements:     []string{models.ImprovementFarm},
+	}
+	if !DepleteSoil(tile) || tile.Fertility != 1.2-SoilDepletion {
+		t.Fatalf("Farming should wear the soil down, got %.3f", tile.Fertility)
+	}
+	if food := TileMultiplier(tile).Food; food != BaseYield(Grassland).Food*tile.Fertility {
+		t.Errorf("Expected soil to scale the food yield, got %.3f", food)
+	}
+
+	// Over-farming bottoms out at a share of the natural fertility
+	for i := 0; i < 1000; i++ {
+		DepleteSoil(tile)
+	}
+	if floor := 1.2 * MinSoilFraction; tile.Fertility != floor || DepleteSoil(tile) {
+		t.Errorf("Expected exhausted soil to hold at %.2f, got %.3f", floor, tile.Fertility)
+	}
+
+	// Left fallow, it recovers to its natural fertility and no further
+	tile.Improvements = nil
+	for i := 0; i < 1000; i++ {
+		DepleteSoil(tile)
+	}
+	if tile.Fertility != tile.NaturalFertility || DepleteSoil(tile) {
+		t.Errorf("Expected fallow soil to recover to %.2f, got %.3f", tile.NaturalFertility, tile.Fertility)
+	}
+}
@@ -0,0 +1,22 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+const (
+	SeaTradeRange    = 40   // Longest sea route a harbor runs, in tiles of water
+	MaxSeaRoutes     = 3    // Sea routes a harbor runs at once
+	PiracyRange      = 2    // How close to a route a hostile galley threatens it
+	PiracyRaidChance = 0.25 // Chance each threatening galley takes a route's yearly cargo
+)
+
+// SeaTrade returns the extra share of food a sea route of the given length
+// earns under the rules: distant partners trade rarer goods
+func SeaTrade(rules *models.Ruleset, distance int) float64 {
+	return min(rules.SeaTradeBase+rules.SeaTradePerTile*float64(distance), rules.MaxSeaTrade)
+}
+
+// RaidChance returns the chance a route threatened by the given number of
+// hostile galleys is raided in a year
+func RaidChance(galleys int) float64 {
+	return min(PiracyRaidChance*float64(galleys), 1.0)
+}
@@ -0,0 +1,41 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+// Terrain transformations workers can carry out
+const (
+	TransformIrrigation = "irrigation" // Channels from a river turn desert into farmland
+	TransformDrainage   = "drainage"   // Ditches drain a marsh into grassland
+)
+
+// Transformation is a long worker project that turns one terrain into another
+type Transformation struct {
+	Name       string
+	From       string  // Terrain the project starts on
+	To         string  // Terrain it leaves behind
+	NeedsRiver bool    // Only tiles a river flows through can be transformed
+	Years      int     // Years a workers unit spends on the project
+	Moisture   float64 // Fertility the soil gains, see ShiftMoisture
+}
+
+// Transformations are the projects workers know, by name
+var Transformations = map[string]Transformation{
+	TransformIrrigation: {Name: TransformIrrigation, From: Desert, To: Plains, NeedsRiver: true, Years: 20, Moisture: 0.3},
+	TransformDrainage:   {Name: TransformDrainage, From: Marsh, To: Grassland, Years: 15, Moisture: -0.1},
+}
+
+// CanTransform reports whether a transformation can be carried out on a tile
+func CanTransform(tile *models.MapTile, transformation Transformation) bool {
+	return tile.TerrainType == transformation.From && (!transformation.NeedsRiver || tile.HasRiver)
+}
+
+// Transform carries out a finished transformation on a tile, changing its
+// terrain and moistening its soil. It reports whether the tile changed.
+func Transform(tile *models.MapTile, transformation Transformation) bool {
+	if !CanTransform(tile, transformation) {
+		return false
+	}
+	tile.TerrainType = transformation.To
+	ShiftMoisture(tile, transformation.Moisture)
+	return true
+}
@@ -0,0 +1,42 @@
+package terrain
+
+import (
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestIrrigation(t *testing.T) {
+	irrigation := Transformations[TransformIrrigation]
+
+	dry := &models.MapTile{TerrainType: Desert, Fertility: 0.2, NaturalFertility: 0.2}
+	if CanTransform(dry, irrigation) || Transform(dry, irrigation) {
+		t.Error("Desert without a river should not be irrigated")
+	}
+
+	tile := &models.MapTile{TerrainType: Desert, HasRiver: true, Fertility: 0.2, NaturalFertility: 0.2}
+	if !Transform(tile, irrigation) || tile.TerrainType != Plains {
+		t.Fatalf("Expected irrigated desert to become plains, got %s", tile.TerrainType)
+	}
+	if tile.NaturalFertility != 0.5 || tile.Fertility != 0.5 {
+		t.Errorf("Expected irrigation to moisten the soil, got %.2f/%.2f", tile.Fertility, tile.NaturalFertility)
+	}
+	if Transform(tile, irrigation) {
+		t.Error("Plains should not be irrigated again")
+	}
+}
+
+func TestDrainage(t *testing.T) {
+	drainage := Transformations[TransformDrainage]
+
+	tile := &models.MapTile{TerrainType: Marsh, Fertility: 0.9, NaturalFertility: 0.9}
+	if !Transform(tile, drainage) || tile.TerrainType != Grassland {
+		t.Fatalf("Expected drained marsh to become grassland, got %s", tile.TerrainType)
+	}
+	if tile.NaturalFertility != 0.8 || tile.Fertility != 0.8 {
+		t.Errorf("Expected drainage to dry the soil a little, got %.2f/%.2f", tile.Fertility, tile.NaturalFertility)
+	}
+	if Transform(tile, drainage) {
+		t.Error("Grassland should not be drained")
+	}
+}
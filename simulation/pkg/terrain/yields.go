@@ -0,0 +1,190 @@
+package terrain
+
+import "github.com/anicolao/simciv/simulation/pkg/models"
+
+// Terrain types as stored in MapTile.TerrainType
+const (
+	Ocean        = "OCEAN"
+	ShallowWater = "SHALLOW_WATER"
+	Grassland    = "GRASSLAND"
+	Plains       = "PLAINS"
+	Forest       = "FOREST"
+	Jungle       = "JUNGLE"
+	Hills        = "HILLS"
+	Mountain     = "MOUNTAIN"
+	Tundra       = "TUNDRA"
+	Desert       = "DESERT"
+	Beach        = "BEACH"
+	Savanna      = "SAVANNA"
+	Taiga        = "TAIGA"
+	Marsh        = "MARSH" // Waterlogged lowland by rivers and their mouths
+	Oasis        = "OASIS" // Spring-fed ground in arid country
+)
+
+// Yield holds food/production/science multipliers (1.0 = normal output).
+// The simulator's TerrainMultiplier is the Food component.
+type Yield struct {
+	Food       float64
+	Production float64
+	Science    float64
+}
+
+// Neutral is the identity yield
+var Neutral = Yield{Food: 1.0, Production: 1.0, Science: 1.0}
+
+// Mul combines two yields multiplicatively
+func (y Yield) Mul(o Yield) Yield {
+	return Yield{
+		Food:       y.Food * o.Food,
+		Production: y.Production * o.Production,
+		Science:    y.Science * o.Science,
+	}
+}
+
+// TerrainYields is the canonical base yield for each terrain type
+var TerrainYields = map[string]Yield{
+	Grassland:    {Food: 1.0, Production: 0.6, Science: 1.0},
+	Plains:       {Food: 0.9, Production: 0.8, Science: 1.0},
+	Forest:       {Food: 0.7, Production: 1.2, Science: 1.0},
+	Jungle:       {Food: 0.6, Production: 0.6, Science: 1.1},
+	Hills:        {Food: 0.5, Production: 1.3, Science: 1.0},
+	Mountain:     {Food: 0.1, Production: 1.0, Science: 1.1},
+	Tundra:       {Food: 0.4, Production: 0.5, Science: 0.9},
+	Desert:       {Food: 0.2, Production: 0.5, Science: 0.9},
+	Beach:        {Food: 0.6, Production: 0.3, Science: 1.0},
+	Savanna:      {Food: 0.7, Production: 0.6, Science: 1.0},
+	Taiga:        {Food: 0.5, Production: 1.0, Science: 0.9},
+	Marsh:        {Food: 0.6, Production: 0.3, Science: 1.0},
+	Oasis:        {Food: 1.2, Production: 0.4, Science: 1.0},
+	ShallowWater: {Food: 0.8, Production: 0.1, Science: 1.0},
+	Ocean:        {Food: 0.5, Production: 0.0, Science: 1.0},
+}
+
+// Modifiers applied on top of the base terrain yield
+var (
+	RiverModifier   = Yield{Food: 1.25, Production: 1.0, Science: 1.05}
+	CoastalModifier = Yield{Food: 1.1, Production: 1.0, Science: 1.0}
+)
+
+// ResourceModifiers maps resource types to the yield multiplier they grant
+var ResourceModifiers = map[string]Yield{
+	"WHEAT":    {Food: 1.3, Production: 1.0, Science: 1.0},
+	"CATTLE":   {Food: 1.2, Production: 1.1, Science: 1.0},
+	"FISH":     {Food: 1.3, Production: 1.0, Science: 1.0},
+	"GAME":     {Food: 1.15, Production: 1.0, Science: 1.0},
+	"WOOD":     {Food: 1.0, Production: 1.2, Science: 1.0},
+	"STONE":    {Food: 1.0, Production: 1.2, Science: 1.0},
+	"IRON":     {Food: 1.0, Production: 1.3, Science: 1.0},
+	"COPPER":   {Food: 1.0, Production: 1.2, Science: 1.0},
+	"COAL":     {Food: 1.0, Production: 1.3, Science: 1.0},
+	"GOLD":     {Food: 1.0, Production: 1.0, Science: 1.1},
+	"FLINT":    {Food: 1.0, Production: 1.15, Science: 1.0},
+	"OBSIDIAN": {Food: 1.0, Production: 1.2, Science: 1.0},
+}
+
+// IsWater reports whether the terrain type is water
+func IsWater(terrainType string) bool {
+	return terrainType == Ocean || terrainType == ShallowWater
+}
+
+// BaseYield returns the table yield for a terrain type (Neutral if unknown)
+func BaseYield(terrainType string) Yield {
+	if y, ok := TerrainYields[terrainType]; ok {
+		return y
+	}
+	return Neutral
+}
+
+// Multiplier derives the yield multipliers for a terrain type and its modifiers
+func Multiplier(terrainType string, hasRiver bool, isCoastal bool, resources []string) Yield {
+	y := BaseYield(terrainType)
+	if hasRiver {
+		y = y.Mul(RiverModifier)
+	}
+	if isCoastal {
+		y = y.Mul(CoastalModifier)
+	}
+	for _, resource := range resources {
+		if mod, ok := ResourceModifiers[resource]; ok {
+			y = y.Mul(mod)
+		}
+	}
+	return y
+}
+
+// TileMultiplier derives the yield multipliers for a map tile, ignoring
+// depleted resources; a pasture adds to the herds it manages, its soil scales
+// the food it grows and pollution cuts every yield
+func TileMultiplier(tile *models.MapTile) Yield {
+	y := Multiplier(tile.TerrainType, tile.HasRiver, tile.IsCoastal, ActiveResources(tile))
+	if isPasture(tile) && HasLivestock(tile) {
+		y = y.Mul(PastureModifier)
+	}
+	y.Food *= SoilFactor(tile)
+	if tile.Pollution > 0 {
+		factor := PollutionFactor(tile)
+		y = y.Mul(Yield{Food: factor, Production: factor, Science: factor})
+	}
+	return y
+}
+
+// AreaMultiplier averages the yield multipliers of the given tiles.
+// Its Food component is the simulator's TerrainMultiplier for a settlement
+// working those tiles.
+func AreaMultiplier(tiles []*models.MapTile) Yield {
+	if len(tiles) == 0 {
+		return Neutral
+	}
+	total := Yield{}
+	for _, tile := range tiles {
+		y := TileMultiplier(tile)
+		total.Food += y.Food
+		total.Production += y.Production
+		total.Science += y.Science
+	}
+	n := float64(len(tiles))
+	return Yield{Food: total.Food / n, Production: total.Production / n, Science: total.Science / n}
+}
+
+// CaveShelterCapacity is how many people one cave shelters through winter
+const CaveShelterCapacity = 50
+
+// ShelterCapacity returns how many people the caves among the given tiles
+// shelter; it is the simulator's ShelterCapacity for a settlement working
+// those tiles
+func ShelterCapacity(tiles []*models.MapTile) int {
+	capacity := 0
+	for _, tile := range tiles {
+		if tile.HasCave {
+			capacity += CaveShelterCapacity
+		}
+	}
+	return capacity
+}
+
+// SettleValue scores a terrain type's desirability for settlement.
+// Food matters most for an early settlement, production second; the offset
+// makes poor terrain (tundra, mountains, desert) count against a site. A
+// beach's thin soil is made up for by the fishing and landing it gives a
+// settlement, so it is valued as the coastal lowland it replaced at its best.
+func SettleValue(terrainType string) float64 {
+	if terrainType == Beach {
+		terrainType = Grassland
+	}
+	y := BaseYield(terrainType)
+	return 4*y.Food + y.Production - 3
+}
+
+// PeoplePerFoodYield is how many people a tile of normal food yield supports
+const PeoplePerFoodYield = 30
+
+// CarryingCapacity returns how many people the given tiles can feed before
+// overcrowding; it is the simulator's CarryingCapacity for a settlement
+// working those tiles
+func CarryingCapacity(tiles []*models.MapTile) int {
+	food := 0.0
+	for _, tile := range tiles {
+		food += TileMultiplier(tile).Food
+	}
+	return int(food * PeoplePerFoodYield)
+}
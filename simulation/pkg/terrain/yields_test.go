@@ -0,0 +1,71 @@
+package terrain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+)
+
+func TestMultiplier_Modifiers(t *testing.T) {
+	base := Multiplier(Grassland, false, false, nil)
+	if base != TerrainYields[Grassland] {
+		t.Errorf("Plain grassland should match the table, got %+v", base)
+	}
+
+	river := Multiplier(Grassland, true, false, nil)
+	if river.Food <= base.Food {
+		t.Errorf("River should increase food: %f <= %f", river.Food, base.Food)
+	}
+
+	wheat := Multiplier(Grassland, false, false, []string{"WHEAT"})
+	if math.Abs(wheat.Food-base.Food*1.3) > 1e-9 {
+		t.Errorf("WHEAT should multiply food by 1.3, got %f", wheat.Food)
+	}
+
+	unknown := Multiplier("UNKNOWN", false, false, []string{"UNOBTAINIUM"})
+	if unknown != Neutral {
+		t.Errorf("Unknown terrain and resources should be neutral, got %+v", unknown)
+	}
+}
+
+func TestSettleValue_Ordering(t *testing.T) {
+	order := []string{Grassland, Plains, Forest, Hills, Tundra, Desert}
+	for i := 1; i < len(order); i++ {
+		if SettleValue(order[i-1]) <= SettleValue(order[i]) {
+			t.Errorf("%s (%f) should score above %s (%f)", order[i-1], SettleValue(order[i-1]), order[i], SettleValue(order[i]))
+		}
+	}
+	if SettleValue(Tundra) >= 0 || SettleValue(Mountain) >= 0 {
+		t.Error("Tundra and mountains should count against a site")
+	}
+}
+
+func TestAreaMultiplier(t *testing.T) {
+	tiles := []*models.MapTile{
+		{TerrainType: Grassland},
+		{TerrainType: Desert},
+	}
+	area := AreaMultiplier(tiles)
+	want := (TerrainYields[Grassland].Food + TerrainYields[Desert].Food) / 2
+	if math.Abs(area.Food-want) > 1e-9 {
+		t.Errorf("AreaMultiplier food = %f, want %f", area.Food, want)
+	}
+	if AreaMultiplier(nil) != Neutral {
+		t.Error("Empty area should be neutral")
+	}
+}
+
+func TestCarryingCapacity(t *testing.T) {
+	tiles := []*models.MapTile{
+		{TerrainType: Grassland},
+		{TerrainType: Desert},
+		{TerrainType: Grassland, HasRiver: true},
+	}
+	if got, want := CarryingCapacity(tiles), 73; got != want { // (1.0 + 0.2 + 1.25) * 30
+		t.Errorf("Expected fertile land to support more people: got %d, want %d", got, want)
+	}
+	if got := CarryingCapacity(nil); got != 0 {
+		t.Errorf("Expected no land to support no one, got %d", got)
+	}
+}
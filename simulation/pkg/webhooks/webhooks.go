@@ -0,0 +1,262 @@
+// Package webhooks posts game lifecycle events to external services, such as
+// Discord bots, so they can react to games without polling.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+// Headers sent with each delivery. The signature is "sha256=" and the hex
+// HMAC-SHA256, keyed by the shared secret, of the timestamp, a dot and the
+// body.
+const (
+	HeaderEvent     = "X-SimCiv-Event"
+	HeaderDelivery  = "X-SimCiv-Delivery" // The event's ID, the same on every retry
+	HeaderTimestamp = "X-SimCiv-Timestamp"
+	HeaderSignature = "X-SimCiv-Signature"
+)
+
+const (
+	queueSize      = 256              // Deliveries each webhook may have waiting before new ones are dropped
+	requestTimeout = 10 * time.Second // How long a webhook has to answer each attempt
+)
+
+// DefaultEvents are the event types posted unless WEBHOOK_EVENTS says otherwise
+var DefaultEvents = []string{models.EventGameStarted, models.EventPlayerEliminated, models.EventVictory}
+
+// Config says where and what to post
+type Config struct {
+	URLs           []string
+	Secret         string   // Signs each delivery; empty sends them unsigned
+	Events         []string // Event types to post
+	MaxAttempts    int      // Tries per delivery before it is given up
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultConfig returns the retry policy and events used in production,
+// with no webhooks
+func DefaultConfig() Config {
+	return Config{
+		Events:         DefaultEvents,
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+// ConfigFromEnv reads WEBHOOK_URLS and WEBHOOK_EVENTS (comma-separated),
+// WEBHOOK_SECRET and WEBHOOK_MAX_ATTEMPTS over the defaults
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+	config.URLs = splitList(os.Getenv("WEBHOOK_URLS"))
+	config.Secret = os.Getenv("WEBHOOK_SECRET")
+	if events := splitList(os.Getenv("WEBHOOK_EVENTS")); len(events) > 0 {
+		config.Events = events
+	}
+	if value := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); value != "" {
+		if attempts, err := strconv.Atoi(value); err == nil && attempts > 0 {
+			config.MaxAttempts = attempts
+		} else {
+			log.Printf("Ignoring invalid WEBHOOK_MAX_ATTEMPTS %q", value)
+		}
+	}
+	return config
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Payload is the JSON body of a delivery. Content is a readable line, which
+// Discord webhooks post as the message.
+type Payload struct {
+	EventID   string    `json:"eventId"`
+	Type      string    `json:"type"`
+	GameID    string    `json:"gameId"`
+	Year      int       `json:"year"`
+	PlayerID  string    `json:"playerId,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewPayload describes an event for delivery
+func NewPayload(event *models.GameEvent) Payload {
+	payload := Payload{
+		EventID:   event.EventID,
+		Type:      event.Type,
+		GameID:    event.GameID,
+		Year:      event.Year,
+		PlayerID:  event.PlayerID,
+		Detail:    event.Detail,
+		CreatedAt: event.CreatedAt,
+	}
+	switch event.Type {
+	case models.EventGameStarted:
+		payload.Content = fmt.Sprintf("Game %s has started with %s", event.GameID, event.Detail)
+	case models.EventPlayerEliminated:
+		payload.Content = fmt.Sprintf("%s was eliminated from game %s in year %d", event.PlayerID, event.GameID, event.Year)
+	case models.EventVictory:
+		payload.Content = fmt.Sprintf("Game %s was won by %s in year %d", event.GameID, event.Detail, event.Year)
+	default:
+		payload.Content = fmt.Sprintf("Game %s, year %d: %s %s", event.GameID, event.Year, event.Type, event.Detail)
+	}
+	return payload
+}
+
+// Sign returns the signature of a delivery's body sent at timestamp
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery is one event waiting to be posted to a webhook
+type delivery struct {
+	payload Payload
+	body    []byte
+}
+
+// Dispatcher posts events to the configured webhooks. Each webhook has its
+// own queue, delivered in order, so one that is down holds up only itself.
+type Dispatcher struct {
+	config Config
+	events map[string]bool
+	client *http.Client
+	queues map[string]chan delivery
+}
+
+// NewDispatcher creates a dispatcher; Run delivers what it is notified of
+func NewDispatcher(config Config) *Dispatcher {
+	d := &Dispatcher{
+		config: config,
+		events: make(map[string]bool, len(config.Events)),
+		client: &http.Client{Timeout: requestTimeout},
+		queues: make(map[string]chan delivery, len(config.URLs)),
+	}
+	for _, eventType := range config.Events {
+		d.events[eventType] = true
+	}
+	for _, url := range config.URLs {
+		d.queues[url] = make(chan delivery, queueSize)
+	}
+	return d
+}
+
+// Notify queues an event for every webhook if its type is one to post. It
+// never blocks: a delivery is dropped if a webhook's queue is full.
+func (d *Dispatcher) Notify(event *models.GameEvent) {
+	if !d.events[event.Type] {
+		return
+	}
+	payload := NewPayload(event)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding webhook payload for event %s: %v", event.EventID, err)
+		return
+	}
+	for url, queue := range d.queues {
+		select {
+		case queue <- delivery{payload: payload, body: body}:
+		default:
+			log.Printf("Webhook %s is backed up; dropping %s event %s", url, event.Type, event.EventID)
+		}
+	}
+}
+
+// Run delivers queued events to each webhook until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	done := make(chan struct{})
+	for url, queue := range d.queues {
+		go func(url string, queue chan delivery) {
+			defer func() { done <- struct{}{} }()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case next := <-queue:
+					d.deliver(ctx, url, next)
+				}
+			}
+		}(url, queue)
+	}
+	for range d.queues {
+		<-done
+	}
+}
+
+// deliver posts a delivery, retrying with backoff while the webhook fails
+// or answers 429 or 5xx, until it succeeds or the attempts run out
+func (d *Dispatcher) deliver(ctx context.Context, url string, next delivery) {
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(ctx, url, next)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= d.config.MaxAttempts {
+			log.Printf("Giving up on %s event %s for webhook %s after %d attempts: %v", next.payload.Type, next.payload.EventID, url, attempt, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(repository.Backoff(attempt, d.config.InitialBackoff, d.config.MaxBackoff)):
+		}
+	}
+}
+
+// post makes one attempt at a delivery, reporting whether a failure is worth
+// retrying
+func (d *Dispatcher) post(ctx context.Context, url string, next delivery) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(next.body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, next.payload.Type)
+	request.Header.Set(HeaderDelivery, next.payload.EventID)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	if d.config.Secret != "" {
+		request.Header.Set(HeaderSignature, Sign(d.config.Secret, timestamp, next.body))
+	}
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	response.Body.Close()
+	switch {
+	case response.StatusCode < 300:
+		return false, nil
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+		return true, fmt.Errorf("webhook answered %s", response.Status)
+	default:
+		return false, fmt.Errorf("webhook answered %s", response.Status)
+	}
+}
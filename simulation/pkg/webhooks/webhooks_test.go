@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/repository"
+)
+
+func TestDispatcher(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	var received []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if r.Header.Get(HeaderSignature) != Sign("secret", r.Header.Get(HeaderTimestamp), body) {
+			t.Errorf("Expected a valid signature, got %q", r.Header.Get(HeaderSignature))
+		}
+		// The first attempt fails and must be retried
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload Payload
+		json.Unmarshal(body, &payload)
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.URLs = []string{server.URL}
+	config.Secret = "secret"
+	config.InitialBackoff = time.Millisecond
+	dispatcher := NewDispatcher(config)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+
+	// Lifecycle events recorded through the hooked repository are posted,
+	// the rest are not
+	repo := repository.NewHookedRepository(repository.NewMemoryRepository(), dispatcher.Notify)
+	repo.CreateEvent(ctx, &models.GameEvent{EventID: "e1", GameID: "game1", Year: -4900, Type: models.EventUnitMoved})
+	repo.CreateEvent(ctx, &models.GameEvent{EventID: "e2", GameID: "game1", Year: -4800, Type: models.EventPlayerEliminated, PlayerID: "p2"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(received) > 0
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 || len(received) != 1 {
+		t.Fatalf("Expected one delivery on the second attempt, got %d attempts and %+v", attempts, received)
+	}
+	if got := received[0]; got.EventID != "e2" || got.PlayerID != "p2" || got.Content != "p2 was eliminated from game game1 in year -4800" {
+		t.Errorf("Expected the elimination of p2, got %+v", got)
+	}
+}
@@ -0,0 +1,53 @@
+// Package yields computes what a tile yields with a given set of
+// improvements and technologies. The engine rates tiles with it and serves
+// the same numbers as tile previews, so a tooltip always matches what the
+// engine applies.
+package yields
+
+import (
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+// TileYields is what a tile yields, as multipliers of normal output
+type TileYields struct {
+	Food       float64 `json:"food"`
+	Production float64 `json:"production"`
+	Science    float64 `json:"science"`
+	HerdBonus  float64 `json:"herdBonus"` // Extra share of its settlement's food from the tile's herds, for up to simulator.MaxHerds herds
+}
+
+// ComputeTileYields returns what a tile yields with the given improvements
+// in place of its own, to a settlement that knows the given technologies.
+// Cooking (Fire Mastery) raises the food of every tile; herds feed a
+// settlement that has domesticated them, more so under husbandry.
+func ComputeTileYields(tile *models.MapTile, improvements []string, techs []string) TileYields {
+	improved := *tile
+	improved.Improvements = improvements
+	y := terrain.TileMultiplier(&improved)
+
+	yields := TileYields{Food: y.Food, Production: y.Production, Science: y.Science}
+	if contains(techs, simulator.TechFireMastery) {
+		yields.Food *= simulator.FireMasteryFoodBonus
+	}
+	if terrain.HasLivestock(tile) {
+		switch {
+		case contains(techs, simulator.TechHusbandry):
+			yields.HerdBonus = simulator.PastureFoodBonus
+		case contains(techs, simulator.TechDomestication):
+			yields.HerdBonus = simulator.HerdFoodBonus
+		}
+	}
+	return yields
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
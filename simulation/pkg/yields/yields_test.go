@@ -0,0 +1,37 @@
+package yields
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anicolao/simciv/simulation/pkg/models"
+	"github.com/anicolao/simciv/simulation/pkg/simulator"
+	"github.com/anicolao/simciv/simulation/pkg/terrain"
+)
+
+func TestComputeTileYields(t *testing.T) {
+	tile := &models.MapTile{TerrainType: terrain.Grassland, Resources: []string{"CATTLE"}}
+	base := terrain.TileMultiplier(tile)
+
+	bare := ComputeTileYields(tile, nil, nil)
+	if bare.Food != base.Food || bare.Production != base.Production || bare.HerdBonus != 0 {
+		t.Errorf("Expected the terrain's own yield without improvements or techs, got %+v", bare)
+	}
+
+	// A planned pasture previews its yield without touching the tile
+	pasture := ComputeTileYields(tile, []string{models.ImprovementPasture}, nil)
+	if math.Abs(pasture.Food-base.Food*terrain.PastureModifier.Food) > 1e-9 || len(tile.Improvements) != 0 {
+		t.Errorf("Expected a pasture to add its modifier, got %+v", pasture)
+	}
+
+	cooked := ComputeTileYields(tile, nil, []string{simulator.TechFireMastery, simulator.TechDomestication})
+	if math.Abs(cooked.Food-base.Food*simulator.FireMasteryFoodBonus) > 1e-9 || cooked.HerdBonus != simulator.HerdFoodBonus {
+		t.Errorf("Expected cooking and tamed herds to add food, got %+v", cooked)
+	}
+	if managed := ComputeTileYields(tile, nil, []string{simulator.TechDomestication, simulator.TechHusbandry}); managed.HerdBonus != simulator.PastureFoodBonus {
+		t.Errorf("Expected husbandry to manage the herds, got %+v", managed)
+	}
+	if barren := ComputeTileYields(&models.MapTile{TerrainType: terrain.Desert}, nil, []string{simulator.TechHusbandry}); barren.HerdBonus != 0 {
+		t.Errorf("Expected no herd bonus without herds, got %+v", barren)
+	}
+}
@@ -2,7 +2,7 @@ import { describe, it, expect, beforeEach, afterEach } from 'vitest';
 import request from 'supertest';
 import express, { Express } from 'express';
 import cookieParser from 'cookie-parser';
-import { connectToDatabase, closeDatabase, getUsersCollection, getSessionsCollection, getGamesCollection } from '../../db/connection';
+import { connectToDatabase, closeDatabase, getUsersCollection, getSessionsCollection, getGamesCollection, getGameSummariesCollection } from '../../db/connection';
 import { sessionMiddleware } from '../../middleware/session';
 import authRoutes from '../../routes/auth';
 import gamesRoutes from '../../routes/games';
@@ -190,7 +190,7 @@ describe('Game Integration Tests', () => {
 
     expect(joinResponse.body.success).toBe(true);
     expect(joinResponse.body.game.currentPlayers).toBe(2);
-    expect(joinResponse.body.game.state).toBe('started'); // Should auto-start when full
+    expect(joinResponse.body.game.state).toBe('starting'); // Should auto-start when full
     expect(joinResponse.body.game.startedAt).toBeDefined();
   });
 
@@ -215,12 +215,12 @@ describe('Game Integration Tests', () => {
       .post(`/api/games/${gameId}/join`)
       .expect(200);
 
-    // Verify game is started
+    // Verify game is starting; the engine generates its map and starts it
     gameResponse = await agent1
       .get(`/api/games/${gameId}`)
       .expect(200);
 
-    expect(gameResponse.body.game.state).toBe('started');
+    expect(gameResponse.body.game.state).toBe('starting');
     expect(gameResponse.body.game.currentPlayers).toBe(2);
     expect(gameResponse.body.game.startedAt).toBeDefined();
     // lastTickAt should be undefined initially - simulation engine will set it on first tick
@@ -249,6 +249,164 @@ describe('Game Integration Tests', () => {
     expect(response.body.error).toBe('Game has already started');
   });
 
+  it('should assign joining players to the smallest team', async () => {
+    const createResponse = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 4, teamCount: 2 })
+      .expect(201);
+
+    const gameId = createResponse.body.game.gameId;
+
+    await agent2.post(`/api/games/${gameId}/join`).expect(200);
+    await agent3.post(`/api/games/${gameId}/join`).send({ team: 0 }).expect(200);
+
+    const response = await agent1
+      .get(`/api/games/${gameId}`)
+      .expect(200);
+
+    expect(response.body.game.teams).toEqual({ player1: 0, player2: 1, player3: 0 });
+  });
+
+  it('should reject a team outside teamCount', async () => {
+    const response = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 4, teamCount: 2, team: 2 })
+      .expect(400);
+
+    expect(response.body.error).toBe('team must be an index below teamCount');
+  });
+
+  it('should store a tick schedule', async () => {
+    const createResponse = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 2, schedule: { windowStart: '08:00', windowEnd: '23:00', pauseWeekends: true } })
+      .expect(201);
+
+    const response = await agent1
+      .get(`/api/games/${createResponse.body.game.gameId}`)
+      .expect(200);
+
+    expect(response.body.game.schedule).toEqual({ windowStart: '08:00', windowEnd: '23:00', pauseWeekends: true });
+  });
+
+  it('should reject a malformed tick schedule', async () => {
+    const response = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 2, schedule: { windowStart: '8am', windowEnd: '23:00' } })
+      .expect(400);
+
+    expect(response.body.error).toBe('schedule windows must be HH:MM');
+  });
+
+  it('should store a year pacing schedule', async () => {
+    const createResponse = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 2, pacing: 'eras' })
+      .expect(201);
+
+    const response = await agent1
+      .get(`/api/games/${createResponse.body.game.gameId}`)
+      .expect(200);
+
+    expect(response.body.game.pacing).toBe('eras');
+  });
+
+  it('should reject an unknown year pacing schedule', async () => {
+    const response = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 2, pacing: 'warp' })
+      .expect(400);
+
+    expect(response.body.error).toBe("pacing must be 'constant' or 'eras'");
+  });
+
+  it('should issue a player key only to an authenticated session', async () => {
+    const created = await agent1.post('/api/games').send({ maxPlayers: 2 }).expect(201);
+    const gameId = created.body.game.gameId;
+
+    const response = await agent1.post(`/api/games/${gameId}/player-key`).expect(200);
+    expect(response.body.playerKey).toBeDefined();
+
+    // A session that has not finished signing in is refused
+    await getSessionsCollection().updateOne({ userId: 'player1' }, { $set: { state: 'authenticating' } });
+    await agent1.post(`/api/games/${gameId}/player-key`).expect(401);
+  });
+
+  it('should rank players on the scoreboard', async () => {
+    const createResponse = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 2, teamCount: 2 })
+      .expect(201);
+
+    const gameId = createResponse.body.game.gameId;
+    await agent2.post(`/api/games/${gameId}/join`).expect(200);
+
+    const response = await agent1
+      .get(`/api/games/${gameId}/scoreboard`)
+      .expect(200);
+
+    expect(response.body.players).toHaveLength(2);
+    expect(response.body.teams).toHaveLength(2);
+    expect(response.body.teams[1].players).toEqual(['player2']);
+  });
+
+  it('should serve the summary once the game has finished', async () => {
+    const createResponse = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 2 })
+      .expect(201);
+
+    const gameId = createResponse.body.game.gameId;
+    await agent1.get(`/api/games/${gameId}/summary`).expect(404);
+
+    await getGameSummariesCollection().insertOne({
+      gameId,
+      startYear: -5000,
+      endYear: -4200,
+      winners: ['player1'],
+      victoryType: 'conquest',
+      standings: [{ playerId: 'player1', population: 700, science: 40, territory: 12, settlements: 2 }],
+      timeline: [],
+      history: [],
+      superlatives: [{ title: 'Largest city', playerId: 'player1', detail: 'Home, 500 people', value: 500, year: -4200 }],
+      createdAt: new Date(),
+    });
+    const response = await agent1
+      .get(`/api/games/${gameId}/summary`)
+      .expect(200);
+
+    expect(response.body.summary.winners).toEqual(['player1']);
+    expect(response.body.summary.superlatives[0].title).toBe('Largest city');
+    await getGameSummariesCollection().deleteMany({ gameId });
+  });
+
+  it('should let players join a started persistent game', async () => {
+    const createResponse = await agent1
+      .post('/api/games')
+      .send({ maxPlayers: 2, persistent: true })
+      .expect(201);
+
+    const gameId = createResponse.body.game.gameId;
+    expect(createResponse.body.game.persistent).toBe(true);
+
+    await agent2
+      .post(`/api/games/${gameId}/join`)
+      .expect(200);
+
+    // Late joiners wait until the engine has started the game
+    await agent3
+      .post(`/api/games/${gameId}/join`)
+      .expect(400);
+    await getGamesCollection().updateOne({ gameId }, { $set: { state: 'started' } });
+
+    const response = await agent3
+      .post(`/api/games/${gameId}/join`)
+      .expect(200);
+
+    expect(response.body.game.state).toBe('started');
+    expect(response.body.game.currentPlayers).toBe(3);
+  });
+
   it('should prevent duplicate player in same game', async () => {
     // Create a game
     const createResponse = await agent1
@@ -2,10 +2,12 @@ import { describe, it, expect, beforeEach, afterEach } from 'vitest';
 import request from 'supertest';
 import express from 'express';
 import cookieParser from 'cookie-parser';
-import { connectToDatabase, closeDatabase, getGamesCollection, getUsersCollection, getSessionsCollection, getMapTilesCollection, getStartingPositionsCollection, getMapMetadataCollection } from '../../db/connection';
+import { connectToDatabase, closeDatabase, getGamesCollection, getUsersCollection, getSessionsCollection, getMapTilesCollection, getStartingPositionsCollection, getMapMetadataCollection, getExploredTilesCollection, getMinimapsCollection, getBordersCollection, getPlayerActivityCollection } from '../../db/connection';
 import { Game, User, Session, MapTile, StartingPosition, MapMetadata } from '../../models/types';
 import { sessionMiddleware } from '../../middleware/session';
 import mapRoutes from '../../routes/map';
+import { signPlayerKey } from '../../utils/crypto';
+import { config } from '../../config';
 
 describe('Map API Integration Tests', () => {
   let app: express.Application;
@@ -28,6 +30,10 @@ describe('Map API Integration Tests', () => {
     await getMapTilesCollection().deleteMany({});
     await getStartingPositionsCollection().deleteMany({});
     await getMapMetadataCollection().deleteMany({});
+    await getExploredTilesCollection().deleteMany({});
+    await getMinimapsCollection().deleteMany({});
+    await getBordersCollection().deleteMany({});
+    await getPlayerActivityCollection().deleteMany({});
 
     // Create test user
     testUserId = 'testuser';
@@ -164,7 +170,9 @@ describe('Map API Integration Tests', () => {
   });
 
   describe('GET /api/map/:gameId/tiles', () => {
-    it('should return ALL tiles for authenticated user (no fog of war on server)', async () => {
+    it('should return only tiles the player can see (server-side fog of war)', async () => {
+      await getMapTilesCollection().updateOne({ gameId: testGameId, x: 0, y: 0 }, { $set: { visibleTo: ['otheruser'] } });
+
       const response = await request(app)
         .get(`/api/map/${testGameId}/tiles`)
         .set('Cookie', [`simciv_session=${testSessionGuid}`]);
@@ -172,14 +180,13 @@ describe('Map API Integration Tests', () => {
       expect(response.status).toBe(200);
       expect(response.body.tiles).toBeDefined();
       expect(Array.isArray(response.body.tiles)).toBe(true);
-      expect(response.body.tiles.length).toBe(100); // 10x10 tiles we created - ALL tiles, not just visible ones
-      
+      expect(response.body.tiles.length).toBe(99); // 10x10 tiles we created, less the one only otheruser sees
+
       // Check tile properties
       const firstTile = response.body.tiles[0];
       expect(firstTile.gameId).toBe(testGameId);
       expect(firstTile.terrainType).toBe('GRASSLAND');
-      // Tiles should have visibleTo property but server doesn't filter by it
-      expect(firstTile.visibleTo).toBeDefined();
+      expect(firstTile.visibleTo).toContain(testUserId);
     });
 
     it('should return tiles with valid terrain data (not blank/invisible)', async () => {
@@ -202,7 +209,8 @@ describe('Map API Integration Tests', () => {
       const validTerrainTypes = [
         'OCEAN', 'SHALLOW_WATER', 'MOUNTAIN', 'HILLS', 
         'GRASSLAND', 'PLAINS', 'FOREST', 'JUNGLE', 
-        'DESERT', 'TUNDRA', 'ICE'
+        'DESERT', 'TUNDRA', 'ICE', 'BEACH',
+        'SAVANNA', 'TAIGA', 'MARSH', 'OASIS'
       ];
       
       response.body.tiles.forEach((tile: any) => {
@@ -242,18 +250,11 @@ describe('Map API Integration Tests', () => {
       expect(response.status).toBe(401);
     });
 
-    it('should return all tiles even for users not in visibleTo list (no server-side fog of war)', async () => {
-      // Create another user with no visible tiles in the visibleTo array
+    it('should hide tiles from players who cannot see them', async () => {
+      // Create another player in the game with no tiles in sight
       const otherUserId = 'otheruser';
       const otherSessionGuid = '22345678-2234-4223-8223-223456789abc';
-      
-      await getUsersCollection().insertOne({
-        alias: otherUserId,
-        publicKey: 'other-public-key',
-        accountStatus: 'active',
-        createdAt: new Date(),
-      });
-      
+
       await getSessionsCollection().insertOne({
         sessionGuid: otherSessionGuid,
         userId: otherUserId,
@@ -263,14 +264,29 @@ describe('Map API Integration Tests', () => {
         expiresAt: new Date(Date.now() + 3600000),
       });
 
+      // Not in the game yet
+      const outsider = await request(app)
+        .get(`/api/map/${testGameId}/tiles`)
+        .set('Cookie', [`simciv_session=${otherSessionGuid}`]);
+      expect(outsider.status).toBe(403);
+
+      await getGamesCollection().updateOne({ gameId: testGameId }, { $push: { playerList: otherUserId } });
       const response = await request(app)
         .get(`/api/map/${testGameId}/tiles`)
         .set('Cookie', [`simciv_session=${otherSessionGuid}`]);
 
       expect(response.status).toBe(200);
-      expect(response.body.tiles).toBeDefined();
-      // Server returns ALL tiles, not filtered by visibleTo
-      expect(response.body.tiles.length).toBe(100);
+      expect(response.body.tiles).toHaveLength(0);
+    });
+  });
+
+  describe('GET /api/map/:gameId/tiles/all', () => {
+    it('should not be served unless debug routes are enabled', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/all`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(404);
     });
   });
 
@@ -324,4 +340,265 @@ describe('Map API Integration Tests', () => {
       expect(response.status).toBe(404);
     });
   });
+
+  describe('GET /api/map/:gameId/starting-position/report', () => {
+    it('should summarize the guaranteed footprint', async () => {
+      await getMapTilesCollection().insertMany([
+        {
+          gameId: testGameId, x: 40, y: 40, elevation: 600, terrainType: 'PLAINS', climateZone: 'TEMPERATE',
+          hasRiver: true, isCoastal: false, resources: ['WHEAT'], improvements: [], visibleTo: [testUserId], createdAt: new Date(),
+        },
+        {
+          gameId: testGameId, x: 41, y: 40, elevation: 600, terrainType: 'BEACH', climateZone: 'TEMPERATE',
+          hasRiver: false, isCoastal: true, resources: [], improvements: [], visibleTo: [testUserId], createdAt: new Date(),
+        },
+      ]);
+
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/starting-position/report`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      expect(response.body.settled).toBe(false);
+      expect(response.body.report.tileCount).toBe(2);
+      expect(response.body.report.terrain).toEqual({ PLAINS: 1, BEACH: 1 });
+      expect(response.body.report.resources).toEqual({ WHEAT: 1 });
+      expect(response.body.report.hasRiver).toBe(true);
+      expect(response.body.report.hasCoast).toBe(true);
+    });
+
+    it('should return 401 for unauthenticated user', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/starting-position/report`);
+
+      expect(response.status).toBe(401);
+    });
+  });
+
+  describe('GET /api/map/:gameId/tiles/visible', () => {
+    beforeEach(async () => {
+      await getMapTilesCollection().updateOne({ gameId: testGameId, x: 0, y: 0 }, { $set: { visibleTo: ['otheruser'] } });
+    });
+
+    it('should return only tiles visible to the session player', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/visible`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      expect(response.body.tiles.length).toBe(99);
+    });
+
+    it('should include owned tiles and last-seen records for tiles out of sight', async () => {
+      await getMapTilesCollection().updateOne({ gameId: testGameId, x: 0, y: 0 }, { $set: { ownerId: testUserId } });
+      await getMapTilesCollection().updateOne({ gameId: testGameId, x: 1, y: 0 }, { $set: { visibleTo: [] } });
+      await getExploredTilesCollection().insertMany([
+        { gameId: testGameId, playerId: testUserId, x: 1, y: 0, terrainType: 'FOREST', resources: [], improvements: [], seenYear: -4010 },
+        { gameId: testGameId, playerId: testUserId, x: 2, y: 0, terrainType: 'GRASSLAND', resources: [], improvements: [], seenYear: -4001 },
+        { gameId: testGameId, playerId: 'otheruser', x: 1, y: 0, terrainType: 'PLAINS', resources: [], improvements: [], seenYear: -4000 },
+      ]);
+
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/visible`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      // 98 visible + the owned tile at (0, 0)
+      expect(response.body.tiles.length).toBe(99);
+      // Only the player's own record for the tile now out of sight
+      expect(response.body.explored).toHaveLength(1);
+      expect(response.body.explored[0]).toMatchObject({ x: 1, y: 0, terrainType: 'FOREST', seenYear: -4010 });
+    });
+
+    it('should accept a player key without a session', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/visible`)
+        .set('X-Player-Key', signPlayerKey(testGameId, testUserId, config.playerKeySecret));
+
+      expect(response.status).toBe(200);
+      expect(response.body.tiles.length).toBe(99);
+    });
+
+    it('should reject a forged player key', async () => {
+      const forged = `${Buffer.from('otheruser').toString('base64url')}.${'0'.repeat(64)}`;
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/visible`)
+        .set('X-Player-Key', forged);
+
+      expect(response.status).toBe(401);
+    });
+
+    it('should reject a key for a player who is not in the game', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/visible`)
+        .set('X-Player-Key', signPlayerKey(testGameId, 'otheruser', config.playerKeySecret));
+
+      expect(response.status).toBe(403);
+    });
+
+    it('should reject a key that does not match the session player', async () => {
+      await getGamesCollection().updateOne({ gameId: testGameId }, { $push: { playerList: 'otheruser' } });
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/visible`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`])
+        .set('X-Player-Key', signPlayerKey(testGameId, 'otheruser', config.playerKeySecret));
+
+      expect(response.status).toBe(403);
+    });
+
+    it('should record the player as active at the current tick', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/visible`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      const game = await getGamesCollection().findOne({ gameId: testGameId });
+      const activity = await getPlayerActivityCollection().findOne({ gameId: testGameId, playerId: testUserId });
+      expect(activity?.lastActiveTick).toBe(game?.currentYear);
+    });
+  });
+
+  describe('GET /api/map/:gameId/minimap', () => {
+    it('should return the player\'s own minimap', async () => {
+      await getMinimapsCollection().insertMany([
+        { gameId: testGameId, playerId: testUserId, width: 2, height: 1, terrain: 'G?', owners: '0?', legend: { G: 'GRASSLAND' }, year: -4000, generatedAt: new Date() },
+        { gameId: testGameId, playerId: 'otheruser', width: 2, height: 1, terrain: 'GO', owners: '..', legend: { G: 'GRASSLAND', O: 'OCEAN' }, year: -4000, generatedAt: new Date() },
+      ]);
+
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/minimap`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      expect(response.body.minimap).toMatchObject({ playerId: testUserId, terrain: 'G?', owners: '0?' });
+    });
+
+    it('should return 404 before the engine has generated one', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/minimap`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(404);
+    });
+
+    it('should return 401 for unauthenticated user', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/minimap`);
+
+      expect(response.status).toBe(401);
+    });
+  });
+
+  describe('GET /api/map/:gameId/borders', () => {
+    it('should return the borders the player knows of', async () => {
+      const square = [{ x: 1, y: 1 }, { x: 2, y: 1 }, { x: 2, y: 2 }, { x: 1, y: 2 }, { x: 1, y: 1 }];
+      await getBordersCollection().insertMany([
+        { gameId: testGameId, playerId: testUserId, territories: [{ ownerId: testUserId, lines: [square] }], year: -4000, generatedAt: new Date() },
+        { gameId: testGameId, playerId: 'otheruser', territories: [], year: -4000, generatedAt: new Date() },
+      ]);
+
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/borders`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      expect(response.body.borders).toMatchObject({ playerId: testUserId, territories: [{ ownerId: testUserId, lines: [square] }] });
+    });
+
+    it('should return 404 before the engine has generated them', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/borders`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(404);
+    });
+  });
+
+  describe('GET /api/map/:gameId/tiles/changes', () => {
+    beforeEach(async () => {
+      await getMapTilesCollection().updateMany({ gameId: testGameId }, { $set: { lastModifiedTick: -5000 } });
+      await getMapTilesCollection().updateMany(
+        { gameId: testGameId, x: { $lt: 2 }, y: 0 },
+        { $set: { lastModifiedTick: -4001, ownerId: testUserId } }
+      );
+    });
+
+    it('should return only tiles modified after sinceTick', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=-4500`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      expect(response.body.tiles).toHaveLength(2);
+      expect(response.body.currentTick).toBe(-4001);
+    });
+
+    it('should leave out changed tiles the player cannot see', async () => {
+      await getMapTilesCollection().updateOne(
+        { gameId: testGameId, x: 2, y: 0 },
+        { $set: { lastModifiedTick: -4001, ownerId: 'otheruser', visibleTo: ['otheruser'] } }
+      );
+
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=-4500`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      expect(response.body.tiles).toHaveLength(2);
+      expect(response.body.tiles.every((tile: any) => tile.ownerId === testUserId)).toBe(true);
+    });
+
+    it('should return nothing when polled with the current tick', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=-4001`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(200);
+      expect(response.body.tiles).toHaveLength(0);
+    });
+
+    it('should return changes made during the next tick to a client polling with the returned cursor', async () => {
+      const first = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=-4500`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+      expect(first.body.tiles).toHaveLength(2);
+
+      // The engine stamps a tile with the year it is processing, then advances the year
+      await getMapTilesCollection().updateOne(
+        { gameId: testGameId, x: 2, y: 0 },
+        { $set: { lastModifiedTick: -4000, ownerId: testUserId } }
+      );
+      await getGamesCollection().updateOne({ gameId: testGameId }, { $set: { currentYear: -3980 } });
+
+      const second = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=${first.body.currentTick}`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(second.status).toBe(200);
+      expect(second.body.tiles).toHaveLength(1);
+      expect(second.body.tiles[0]).toMatchObject({ x: 2, y: 0 });
+      expect(second.body.currentTick).toBe(-3981);
+
+      const third = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=${second.body.currentTick}`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(third.body.tiles).toHaveLength(0);
+    });
+
+    it('should return 400 without a valid sinceTick', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=abc`)
+        .set('Cookie', [`simciv_session=${testSessionGuid}`]);
+
+      expect(response.status).toBe(400);
+    });
+
+    it('should return 401 for unauthenticated user', async () => {
+      const response = await request(app)
+        .get(`/api/map/${testGameId}/tiles/changes?sinceTick=0`);
+
+      expect(response.status).toBe(401);
+    });
+  });
 });
@@ -4,6 +4,8 @@ import {
   encryptChallenge,
   validatePublicKey,
   isValidGuid,
+  signPlayerKey,
+  verifyPlayerKey,
 } from '../../utils/crypto';
 import crypto from 'crypto';
 
@@ -169,4 +171,30 @@ describe('Crypto Utils', () => {
       }).toThrow();
     });
   });
+
+  describe('signPlayerKey / verifyPlayerKey', () => {
+    const secret = 'test-secret';
+
+    it('should verify a key for the game it was issued for', () => {
+      const key = signPlayerKey('game-1', 'alice', secret);
+      expect(verifyPlayerKey('game-1', key, secret)).toBe('alice');
+    });
+
+    it('should reject a key used for another game', () => {
+      const key = signPlayerKey('game-1', 'alice', secret);
+      expect(verifyPlayerKey('game-2', key, secret)).toBeNull();
+    });
+
+    it('should reject a key with a spoofed player', () => {
+      const key = signPlayerKey('game-1', 'alice', secret);
+      const signature = key.split('.')[1];
+      const spoofed = `${Buffer.from('bob').toString('base64url')}.${signature}`;
+      expect(verifyPlayerKey('game-1', spoofed, secret)).toBeNull();
+    });
+
+    it('should reject malformed keys and keys signed with another secret', () => {
+      expect(verifyPlayerKey('game-1', 'not-a-key', secret)).toBeNull();
+      expect(verifyPlayerKey('game-1', signPlayerKey('game-1', 'alice', 'other'), secret)).toBeNull();
+    });
+  });
 });
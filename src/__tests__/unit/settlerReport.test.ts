@@ -0,0 +1,62 @@
+import { describe, it, expect } from 'vitest';
+import { buildSettlerReport } from '../../utils/settlerReport';
+import { MapTile, StartingPosition } from '../../models/types';
+
+function tile(x: number, y: number, overrides: Partial<MapTile> = {}): MapTile {
+  return {
+    gameId: 'game',
+    x,
+    y,
+    elevation: 600,
+    terrainType: 'GRASSLAND',
+    climateZone: 'TEMPERATE',
+    hasRiver: false,
+    isCoastal: false,
+    resources: [],
+    improvements: [],
+    visibleTo: [],
+    createdAt: new Date(),
+    ...overrides,
+  };
+}
+
+const position: StartingPosition = {
+  gameId: 'game',
+  playerId: 'player',
+  centerX: 1,
+  centerY: 1,
+  startingCityX: 1,
+  startingCityY: 1,
+  regionScore: 10,
+  revealedTiles: 9,
+  guaranteedFootprint: { minX: 0, maxX: 2, minY: 0, maxY: 2 },
+  createdAt: new Date(),
+};
+
+describe('buildSettlerReport', () => {
+  it('summarizes terrain, resources, rivers and coast inside the footprint', () => {
+    const report = buildSettlerReport(position, [
+      tile(0, 0),
+      tile(1, 0, { terrainType: 'FOREST', resources: ['WOOD'] }),
+      tile(2, 2, { hasRiver: true, resources: ['WHEAT'] }),
+      tile(2, 1, { isCoastal: true, terrainType: 'BEACH' }),
+      tile(5, 5, { terrainType: 'MOUNTAIN', resources: ['IRON'] }),
+    ]);
+
+    expect(report.tileCount).toBe(4);
+    expect(report.terrain).toEqual({ GRASSLAND: 2, FOREST: 1, BEACH: 1 });
+    expect(report.resources).toEqual({ WOOD: 1, WHEAT: 1 });
+    expect(report.riverTiles).toBe(1);
+    expect(report.hasRiver).toBe(true);
+    expect(report.coastalTiles).toBe(1);
+    expect(report.hasCoast).toBe(true);
+  });
+
+  it('reports no river or coast for a landlocked dry footprint', () => {
+    const report = buildSettlerReport(position, [tile(0, 0), tile(1, 1)]);
+
+    expect(report.hasRiver).toBe(false);
+    expect(report.hasCoast).toBe(false);
+    expect(report.resources).toEqual({});
+  });
+});
@@ -0,0 +1,37 @@
+import { describe, it, expect } from 'vitest';
+import { parseShareCode } from '../../utils/shareCode';
+
+// Codes made by the engine's encoder (go run ./cmd/sharecode)
+const ENGINE_SEED_CODE = 'AEAQI-AUKCA-ASGRL-HRGV4-33YBE-NCWPC-NLZXX-T2KQ';
+const SCENARIO_CODE = 'AECAE-AMQBN-AXEY3-INFYG-K3DBM-5XQUY-TSN5X-HUZJN-MFTWL-5P6';
+
+describe('parseShareCode', () => {
+  it('should decode a code with a packed hex seed', () => {
+    expect(parseShareCode(ENGINE_SEED_CODE)).toEqual({
+      seed: '0123456789abcdef0123456789abcdef',
+      players: 4,
+      landRatio: 0.65,
+    });
+  });
+
+  it('should decode map options and a scenario', () => {
+    expect(parseShareCode(SCENARIO_CODE)).toEqual({
+      seed: 'Archipelago',
+      players: 2,
+      landRatio: 0.4,
+      scenario: 'bronze-age',
+    });
+  });
+
+  it('should ignore case, spaces and dashes', () => {
+    const typed = ENGINE_SEED_CODE.toLowerCase().replace(/-/g, ' ');
+    expect(parseShareCode(typed)).toEqual(parseShareCode(ENGINE_SEED_CODE));
+  });
+
+  it('should reject mistyped and foreign codes', () => {
+    expect(parseShareCode(ENGINE_SEED_CODE.replace('AUKCA', 'AUKCB'))).toBeNull();
+    expect(parseShareCode(ENGINE_SEED_CODE.slice(0, -5))).toBeNull();
+    expect(parseShareCode('hello world')).toBeNull();
+    expect(parseShareCode('')).toBeNull();
+  });
+});
@@ -0,0 +1,50 @@
+import { describe, it, expect } from 'vitest';
+import { teammatesOf, pickTeam, visibleTilesFilter } from '../../utils/teams';
+import { Game } from '../../models/types';
+
+function game(overrides: Partial<Game> = {}): Game {
+  return {
+    gameId: 'game',
+    creatorUserId: 'a',
+    maxPlayers: 4,
+    currentPlayers: 3,
+    playerList: ['a', 'b', 'c'],
+    state: 'started',
+    currentYear: -5000,
+    createdAt: new Date(),
+    ...overrides,
+  };
+}
+
+describe('teammatesOf', () => {
+  it('should return the whole team', () => {
+    const g = game({ teamCount: 2, teams: { a: 0, b: 1, c: 0 } });
+    expect(teammatesOf(g, 'a')).toEqual(['a', 'c']);
+    expect(teammatesOf(g, 'b')).toEqual(['b']);
+  });
+
+  it('should return only the player in a free-for-all', () => {
+    expect(teammatesOf(game(), 'a')).toEqual(['a']);
+  });
+});
+
+describe('visibleTilesFilter', () => {
+  it('should match tiles the team sees or owns', () => {
+    const g = game({ teamCount: 2, teams: { a: 0, b: 1, c: 0 } });
+    expect(visibleTilesFilter(g, 'a')).toEqual({
+      $or: [{ visibleTo: { $in: ['a', 'c'] } }, { ownerId: { $in: ['a', 'c'] } }],
+    });
+  });
+});
+
+describe('pickTeam', () => {
+  it('should balance teams, lowest index first', () => {
+    expect(pickTeam(game({ teamCount: 2, teams: { a: 0 } }))).toBe(1);
+    expect(pickTeam(game({ teamCount: 3, teams: { a: 0, b: 2 } }))).toBe(1);
+    expect(pickTeam(game({ teamCount: 2, teams: { a: 0, b: 1 } }))).toBe(0);
+  });
+
+  it('should honour a requested team', () => {
+    expect(pickTeam(game({ teamCount: 2, teams: { a: 0 } }), 0)).toBe(0);
+  });
+});
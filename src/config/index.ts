@@ -1,3 +1,5 @@
+import crypto from 'crypto';
+
 export interface Config {
   port: number;
   mongoUri: string;
@@ -9,6 +11,9 @@ export interface Config {
   cookieSecure: boolean;
   cookieHttpOnly: boolean;
   cookieSameSite: 'strict' | 'lax' | 'none';
+  playerKeySecret: string;
+  engineQueryUrl: string;
+  debugRoutes: boolean;
 }
 
 export const config: Config = {
@@ -22,4 +27,10 @@ export const config: Config = {
   cookieSecure: process.env.NODE_ENV === 'production',
   cookieHttpOnly: true,
   cookieSameSite: 'lax',
+  // Signs per-player API keys; keys issued with a random secret stop working on restart
+  playerKeySecret: process.env.PLAYER_KEY_SECRET || crypto.randomBytes(32).toString('hex'),
+  // Simulation engine's read-only query server, e.g. for combat previews
+  engineQueryUrl: process.env.ENGINE_QUERY_URL || 'http://localhost:3002',
+  // Serves debugging routes that bypass fog of war, such as /api/map/:gameId/tiles/all
+  debugRoutes: process.env.ENABLE_DEBUG_ROUTES === 'true',
 };
@@ -1,5 +1,5 @@
 import { MongoClient, Db, Collection } from 'mongodb';
-import { User, Session, Challenge, Game, MapTile, StartingPosition, MapMetadata, Unit, Settlement } from '../models/types';
+import { User, Session, Challenge, Game, MapTile, StartingPosition, MapMetadata, Unit, Settlement, Order, ExploredTile, Minimap, Borders, PlayerActivity, PlayerPolicy, PlayerSettings, GameEvent, DiplomacyState, MinorCiv, Objective, UserStats, GameHistoryPoint, GameSummary } from '../models/types';
 
 let client: MongoClient | null = null;
 let db: Db | null = null;
@@ -27,6 +27,20 @@ export async function connectToDatabase(uri: string, dbName: string): Promise<Db
   await db.collection<MapTile>('mapTiles').createIndex({ gameId: 1, x: 1, y: 1 }, { unique: true });
   await db.collection<MapTile>('mapTiles').createIndex({ gameId: 1 });
   await db.collection<MapTile>('mapTiles').createIndex({ gameId: 1, visibleTo: 1 });
+  await db.collection<MapTile>('mapTiles').createIndex({ gameId: 1, ownerId: 1 });
+  await db.collection<MapTile>('mapTiles').createIndex({ gameId: 1, lastModifiedTick: 1 });
+  await db.collection<ExploredTile>('exploredTiles').createIndex({ gameId: 1, playerId: 1, x: 1, y: 1 }, { unique: true });
+  await db.collection<Minimap>('minimaps').createIndex({ gameId: 1, playerId: 1 }, { unique: true });
+  await db.collection<Borders>('borders').createIndex({ gameId: 1, playerId: 1 }, { unique: true });
+  await db.collection<GameEvent>('gameEvents').createIndex({ gameId: 1, year: 1 });
+  // Movement events only drive animation, so they expire after an hour
+  await db.collection<GameEvent>('gameEvents').createIndex(
+    { createdAt: 1 },
+    { expireAfterSeconds: 3600, partialFilterExpression: { type: 'unit_moved' } }
+  );
+  await db.collection<PlayerActivity>('playerActivity').createIndex({ gameId: 1, playerId: 1 }, { unique: true });
+  await db.collection<PlayerPolicy>('playerPolicies').createIndex({ gameId: 1, playerId: 1 }, { unique: true });
+  await db.collection<PlayerSettings>('playerSettings').createIndex({ gameId: 1, playerId: 1 }, { unique: true });
   await db.collection<StartingPosition>('startingPositions').createIndex({ gameId: 1, playerId: 1 }, { unique: true });
   await db.collection<StartingPosition>('startingPositions').createIndex({ gameId: 1 });
   await db.collection<MapMetadata>('mapMetadata').createIndex({ gameId: 1 }, { unique: true });
@@ -38,6 +52,13 @@ export async function connectToDatabase(uri: string, dbName: string): Promise<Db
   await db.collection<Settlement>('settlements').createIndex({ settlementId: 1 }, { unique: true });
   await db.collection<Settlement>('settlements').createIndex({ gameId: 1 });
   await db.collection<Settlement>('settlements').createIndex({ gameId: 1, playerId: 1 });
+  await db.collection<DiplomacyState>('diplomacy').createIndex({ gameId: 1, playerA: 1, playerB: 1 }, { unique: true });
+  await db.collection<MinorCiv>('minorCivs').createIndex({ gameId: 1, minorCivId: 1 }, { unique: true });
+  await db.collection<Objective>('objectives').createIndex({ gameId: 1, objectiveId: 1 }, { unique: true });
+  await db.collection<Objective>('objectives').createIndex({ gameId: 1, playerId: 1, status: 1 });
+  await db.collection<UserStats>('userStats').createIndex({ userId: 1 }, { unique: true });
+  await db.collection<GameHistoryPoint>('gameHistory').createIndex({ gameId: 1, year: 1 }, { unique: true });
+  await db.collection<GameSummary>('gameSummaries').createIndex({ gameId: 1 }, { unique: true });
 
   return db;
 }
@@ -85,6 +106,58 @@ export function getSettlementsCollection(): Collection<Settlement> {
   return getDatabase().collection<Settlement>('settlements');
 }
 
+export function getOrdersCollection(): Collection<Order> {
+  return getDatabase().collection<Order>('orders');
+}
+
+export function getExploredTilesCollection(): Collection<ExploredTile> {
+  return getDatabase().collection<ExploredTile>('exploredTiles');
+}
+
+export function getMinimapsCollection(): Collection<Minimap> {
+  return getDatabase().collection<Minimap>('minimaps');
+}
+
+export function getBordersCollection(): Collection<Borders> {
+  return getDatabase().collection<Borders>('borders');
+}
+
+export function getPlayerActivityCollection(): Collection<PlayerActivity> {
+  return getDatabase().collection<PlayerActivity>('playerActivity');
+}
+
+export function getPlayerPoliciesCollection(): Collection<PlayerPolicy> {
+  return getDatabase().collection<PlayerPolicy>('playerPolicies');
+}
+
+export function getPlayerSettingsCollection(): Collection<PlayerSettings> {
+  return getDatabase().collection<PlayerSettings>('playerSettings');
+}
+
+export function getGameEventsCollection(): Collection<GameEvent> {
+  return getDatabase().collection<GameEvent>('gameEvents');
+}
+
+export function getDiplomacyCollection(): Collection<DiplomacyState> {
+  return getDatabase().collection<DiplomacyState>('diplomacy');
+}
+
+export function getMinorCivsCollection(): Collection<MinorCiv> {
+  return getDatabase().collection<MinorCiv>('minorCivs');
+}
+
+export function getObjectivesCollection(): Collection<Objective> {
+  return getDatabase().collection<Objective>('objectives');
+}
+
+export function getUserStatsCollection(): Collection<UserStats> {
+  return getDatabase().collection<UserStats>('userStats');
+}
+
+export function getGameSummariesCollection(): Collection<GameSummary> {
+  return getDatabase().collection<GameSummary>('gameSummaries');
+}
+
 export async function closeDatabase(): Promise<void> {
   if (client) {
     await client.close();
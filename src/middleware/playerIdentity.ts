@@ -0,0 +1,69 @@
+import { Request, Response, NextFunction } from 'express';
+import { getGamesCollection, getPlayerActivityCollection } from '../db/connection';
+import { verifyPlayerKey } from '../utils/crypto';
+import { config } from '../config';
+
+declare global {
+  namespace Express {
+    interface Request {
+      playerId?: string;
+    }
+  }
+}
+
+export const PLAYER_KEY_HEADER = 'x-player-key';
+
+/**
+ * Middleware to resolve the acting player for a game-scoped request.
+ * A player API key in the X-Player-Key header takes precedence over the
+ * session; when both are present they must name the same player. The player
+ * must be in the game's player list. Sets req.playerId and records the
+ * player as active at the game's current tick.
+ */
+export async function requirePlayer(req: Request, res: Response, next: NextFunction): Promise<void> {
+  try {
+    const { gameId } = req.params;
+    const sessionUserId = req.session?.state === 'authenticated' ? req.session.userId : undefined;
+    const key = req.get(PLAYER_KEY_HEADER);
+
+    let playerId = sessionUserId;
+    if (key) {
+      const keyPlayerId = verifyPlayerKey(gameId, key, config.playerKeySecret);
+      if (!keyPlayerId) {
+        res.status(401).json({ error: 'Invalid player key' });
+        return;
+      }
+      if (sessionUserId && sessionUserId !== keyPlayerId) {
+        res.status(403).json({ error: 'Player key does not match session' });
+        return;
+      }
+      playerId = keyPlayerId;
+    }
+
+    if (!playerId) {
+      res.status(401).json({ error: 'Authentication required' });
+      return;
+    }
+
+    const game = await getGamesCollection().findOne({ gameId });
+    if (!game) {
+      res.status(404).json({ error: 'Game not found' });
+      return;
+    }
+    if (!game.playerList.includes(playerId)) {
+      res.status(403).json({ error: 'Not a player in this game' });
+      return;
+    }
+
+    await getPlayerActivityCollection().updateOne(
+      { gameId, playerId },
+      { $set: { lastActiveTick: game.currentYear, lastActiveAt: new Date() } },
+      { upsert: true }
+    );
+
+    req.playerId = playerId;
+    next();
+  } catch (error) {
+    next(error);
+  }
+}
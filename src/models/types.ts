@@ -27,17 +27,99 @@ export interface Challenge {
   used: boolean;
 }
 
+// Game lifecycle: waiting -> starting -> started (active) <-> paused ->
+// ended (finished) -> archived. The simulation engine validates transitions.
+export type GameState = 'waiting' | 'starting' | 'started' | 'paused' | 'ended' | 'archived';
+
 export interface Game {
   gameId: string;
   creatorUserId: string;
   maxPlayers: number;
   currentPlayers: number;
   playerList: string[];
-  state: 'waiting' | 'started';
+  state: GameState;
   currentYear: number;
   createdAt: Date;
   startedAt?: Date;
   lastTickAt?: Date;
+  startMode?: 'auto' | 'player';
+  settleTimeLimitSeconds?: number;
+  settlementMergeRule?: 'merge' | 'suburb';
+  governorAfterTicks?: number; // Ticks of absence before the governor plays for a player
+  persistent?: boolean; // Keeps accepting players after it starts
+  noShowGraceTicks?: number; // Ticks a player who never connects has before their civ is reclaimed
+  noShowPolicy?: 'governor' | 'release'; // Reclaim no-shows for the governor, or free their region
+  eliminatedPlayers?: string[]; // Players who lost everything or surrendered
+  teamCount?: number; // Number of teams; absent for free-for-all
+  teams?: Record<string, number>; // playerId -> team index
+  winners?: string[]; // Set when the game ends
+  fireMastery?: Record<string, number>; // playerId -> year they first mastered fire
+  schedule?: TickSchedule; // Restricts when the game ticks
+  rules?: Ruleset; // Balance constants pinned by the engine when the game starts
+  shareCode?: string; // Shared world the engine generates the map from
+  pacing?: 'constant' | 'eras'; // Years each tick advances: one, or many in early eras tapering to one
+  calendar?: Calendar; // The current year's calendar, set by the engine each tick
+}
+
+// A date in the game calendar, derived by the engine from the year and day.
+// The year starts in January; winter is January to March.
+export interface Calendar {
+  year: number;
+  // The day within the year; absent from the year calendars games and events carry
+  day?: number; // Day of the year, from 1
+  month?: string;
+  dayOfMonth?: number;
+  season?: 'winter' | 'spring' | 'summer' | 'autumn';
+  daylight?: number; // Hours of daylight in the day
+  era: 'ancient' | 'classical' | 'medieval' | 'renaissance' | 'industrial' | 'modern';
+  newEra?: boolean; // The year is the first of its era
+  newCentury?: boolean;
+  newMillennium?: boolean;
+}
+
+// When a game may tick, in server local time. A window ("HH:MM") that ends
+// before it starts runs past midnight.
+export interface TickSchedule {
+  windowStart?: string;
+  windowEnd?: string;
+  pauseWeekends?: boolean;
+}
+
+export interface Ruleset {
+  version: number;
+  settlersPopulationCost: number;
+  settlersWalkSteps: number;
+  startingVisionRange: number;
+  settleOrderRange: number;
+  autoSettleRadius: number;
+  settlementWorkRadius: number;
+  settlementGrowthPerRing: number;
+  maxSettlementExtent: number;
+  // Added in version 2
+  earthquakeChance?: number;
+  heavyRainChance?: number;
+  unrestAdjustment?: number;
+  revoltChance?: number;
+  territoryHealRate?: number;
+  garrisonHealRate?: number;
+  greatPersonThreshold?: number;
+  // Added in version 3
+  settlement?: SettlementRates;
+  buildingCosts?: Record<string, number>;
+  unitStrengths?: Record<string, number>;
+  settlementStrength?: number;
+  riverTradeBonus?: number;
+  seaTradeBase?: number;
+  seaTradePerTile?: number;
+  maxSeaTrade?: number;
+}
+
+// Multipliers on the settlement simulation's designed rates
+export interface SettlementRates {
+  foodYield: number;
+  scienceYield: number;
+  fertility: number;
+  mortality: number;
 }
 
 export interface MapTile {
@@ -48,14 +130,103 @@ export interface MapTile {
   terrainType: string;
   climateZone: string;
   hasRiver: boolean;
+  riverId?: number; // River system the tile's river belongs to
+  hasCave?: boolean;
+  fertility?: number;
+  naturalFertility?: number;
+  pollution?: number;
   isCoastal: boolean;
   resources: string[];
+  resourceQuantities?: Record<string, number>;
   improvements: string[];
+  damaged?: string[]; // Improvements a flood or earthquake wrecked, awaiting repair by workers
   ownerId?: string;
+  settlementId?: string;
   visibleTo: string[];
+  lastModifiedTick?: number; // Game year of the last ownership, improvement, resource or visibility change
   createdAt: Date;
 }
 
+// A player's last-seen record of a tile, maintained by the simulation engine
+export interface ExploredTile {
+  gameId: string;
+  playerId: string;
+  x: number;
+  y: number;
+  terrainType: string;
+  resources: string[];
+  improvements: string[];
+  ownerId?: string;
+  seenYear: number;
+}
+
+// Downsampled, fogged map overview for one player, refreshed by the engine every few years.
+// Last tick at which a player used the game's API; the engine's governor
+// takes over players who stay away longer than the game's threshold
+export interface PlayerActivity {
+  gameId: string;
+  playerId: string;
+  lastActiveTick: number;
+  lastActiveAt: Date;
+}
+
+export type PolicyLevel = 'low' | 'normal' | 'high';
+
+// How a player directs their civ as a whole; unset policies are 'normal'
+export interface PlayerPolicy {
+  gameId: string;
+  playerId: string;
+  infrastructure?: PolicyLevel; // Busy routes get roads proposed when normal, planned outright when high
+  taxRate?: number; // Share of food taken as tax for gold, 0 to 1; capped by each settlement's technologies
+  corvee?: number; // Share of work hours drafted for production, 0 to 1; capped by each settlement's technologies
+  updatedAt: Date;
+}
+
+// A player's preferences the engine and API act on; unset settings are off
+export interface PlayerSettings {
+  gameId: string;
+  playerId: string;
+  autoEndTurn?: boolean; // Settle and approve proposals at once rather than wait on the player
+  governorAfterTicks?: number; // Ticks of absence before the governor plays for the player; 0 is the game's threshold
+  governorOff?: boolean; // Never hand the player's civ to the governor
+  mutedEvents?: string[]; // Event types left out of the player's notifications
+  updatedAt: Date;
+}
+
+// terrain and owners are row-major strings with one character per cell:
+// terrain codes are listed in legend; owners hold the owner's index in the
+// game's player list (0-9, then A-Z), '*' for minor civilizations, '.' for
+// unowned and '?' for unexplored.
+export interface Minimap {
+  gameId: string;
+  playerId: string;
+  width: number;
+  height: number;
+  terrain: string;
+  owners: string;
+  legend: Record<string, string>;
+  year: number;
+  generatedAt: Date;
+}
+
+// Outline of one owner's territory as a player knows it. Lines run along tile
+// edges between tile corners, tile (x, y) spanning (x, y) to (x + 1, y + 1),
+// clockwise around the territory with y growing down. A line is closed when
+// its ends meet and stops short where the player has not explored.
+export interface TerritoryBorder {
+  ownerId: string;
+  lines: { x: number; y: number }[][];
+}
+
+// Territory borders a player knows of, refreshed with their minimap
+export interface Borders {
+  gameId: string;
+  playerId: string;
+  territories: TerritoryBorder[];
+  year: number;
+  generatedAt: Date;
+}
+
 export interface StartingPosition {
   gameId: string;
   playerId: string;
@@ -81,35 +252,313 @@ export interface MapMetadata {
   height: number;
   playerCount: number;
   seaLevel: number;
+  landRatio?: number;
+  stats?: MapStats;
+  shareCode?: string; // Generates this world again in a new game
   generatedAt: Date;
   generationTimeMs: number;
 }
 
+export interface MapStats {
+  landPercentage: number;
+  terrainCounts: Record<string, number>;
+  resourceCounts: Record<string, number>;
+  riverTiles: number;
+  riverSystems: number;
+  continentCount: number;
+  largestContinent: number;
+}
+
+export type WorkerAutomation = 'improve_nearest' | 'connect_cities' | 'focus_food';
+
+export const WORKER_AUTOMATION_MODES: WorkerAutomation[] = ['improve_nearest', 'connect_cities', 'focus_food'];
+
+// Great people are born in settlements and spent through an 'activate' order
+export type GreatPersonType = 'great_scientist' | 'great_builder' | 'great_general';
+
+// A long project transforming the tile a workers unit stands on
+export interface TerrainProject {
+  transformation: 'irrigation' | 'drainage';
+  location: { x: number; y: number };
+  startedYear: number;
+  yearsLeft: number;
+}
+
 export interface Unit {
   unitId: string;
   gameId: string;
   playerId: string;
-  unitType: 'settlers';
+  unitType: 'settlers' | 'workers' | 'galley' | 'rebels' | GreatPersonType;
   location: {
     x: number;
     y: number;
   };
   stepsTaken: number;
-  populationCost: 100;
+  populationCost: number;
+  automation?: WorkerAutomation; // Workers only; unset when manually controlled
+  damage?: number; // Health lost in combat, out of 100; healed in friendly territory
+  name?: string; // Great people are known by name
+  aura?: number; // Years left fighting inspired by a great general
+  homeId?: string; // Rebels only; the settlement they rose up in
+  project?: TerrainProject | null; // Workers only; the terrain transformation they are carrying out
+  destination?: { x: number; y: number } | null; // Where a move order sent the unit; null once it arrives
+  movesLeft?: number; // Movement saved toward a step dearer than what the unit has left this year
   createdAt: Date;
   lastUpdated: Date;
 }
 
+// Settlements grow from camps into villages, towns and cities
+export type SettlementType = 'nomadic_camp' | 'village' | 'town' | 'city';
+
+// What a settlement's people come to want while their conditions leave it unmet
+export type SettlementDesire = 'roads' | 'trade' | 'security' | 'knowledge';
+
 export interface Settlement {
   settlementId: string;
   gameId: string;
   playerId: string;
   name: string;
-  type: 'nomadic_camp';
+  type: SettlementType;
   location: {
     x: number;
     y: number;
   };
+  population?: number;
+  parentId?: string;
+  buildings?: string[];
+  damaged?: string[]; // Buildings an earthquake wrecked, awaiting repair by workers
+  production?: number; // Production banked from windfalls such as felled forests and from corvée labor
+  gold?: number; // Gold banked from taxes
+  technologies?: Array<
+    'fire_mastery' | 'domestication' | 'husbandry' | 'midwifery' | 'shelter_building' | 'herbal_medicine'
+  >;
+  seaRoutes?: SeaRoute[]; // Sea trade routes its harbor runs
+  garrison?: number; // Defense bonus from the units garrisoned in it
+  greatPoints?: Partial<Record<GreatPersonType, number>>; // Points toward each type of great person
+  greatPeople?: number; // Great people born here; each makes the next costlier
+  deaths?: Mortality; // Deaths of its people by cause since it was founded
+  desires?: Partial<Record<SettlementDesire, number>>; // Strength of each want of its people, 0 to 1
+  unhappiness?: number; // Mean strength of its people's desires; 0 is content
+  unrest?: number; // Crime and disorder, 0 to 1; skims its food and may break into revolt
+  proposals?: Record<string, number>; // Year each building was last proposed for its build queue
+  traffic?: Record<string, number>; // Recent trips and trade with each of the player's other settlements, by settlement ID
+  roadPlan?: { x: number; y: number }[]; // Tiles workers are to pave for its planned roads
   founded: Date;
   lastUpdated: Date;
 }
+
+// Tribute or vassalage arrangement between two players, in force or demanded
+export interface Agreement {
+  type: 'tribute' | 'vassalage';
+  from: string; // Payer, or vassal
+  to: string; // Recipient, or overlord
+  amount?: number; // Production paid each year under tribute
+  year: number; // Year demanded or accepted
+}
+
+// Standing between two players, stored once per pair
+export interface DiplomacyState {
+  gameId: string;
+  playerA: string; // Lower player ID of the pair
+  playerB: string;
+  stance: 'allied' | 'neutral' | 'hostile';
+  agreement: Agreement | null;
+  demand: Agreement | null;
+  brokenBy?: string; // Player who last broke an agreement
+  grievanceUntil?: number; // Year until which no new agreement can be made
+  lastUpdated: Date;
+}
+
+// Favor a minor civ asks of every player; the first to do it earns its friendship
+export interface MinorQuest {
+  type: 'gift' | 'visit';
+  amount?: number; // Production a gift quest asks for
+  expires: number; // Year the quest lapses
+}
+
+// AI-run city-state with a single settlement, owned under its minorCivId
+export interface MinorCiv {
+  gameId: string;
+  minorCivId: string; // Starts with 'minor:'
+  name: string;
+  settlementId: string;
+  friendship: Record<string, number>; // Goodwill toward each player
+  ally?: string; // Player it is allied with, who defends it
+  quest: MinorQuest | null;
+  conqueredBy?: string; // Player who took its settlement
+  lastUpdated: Date;
+}
+
+// Short-term goal the engine sets a player, rewarded with production if met by its deadline
+export interface Objective {
+  gameId: string;
+  objectiveId: string;
+  playerId: string;
+  type: 'coastal_settlement' | 'connect_settlements' | 'population' | 'settlements' | 'building';
+  target?: number; // Population or settlement count to reach
+  item?: string; // Building to construct
+  deadline: number; // Year by which it must be met
+  reward: number; // Production granted on completion
+  status: 'active' | 'completed' | 'failed';
+  assigned: number; // Year it was set
+  resolved: number; // Year it was completed or failed
+  lastUpdated: Date;
+}
+
+// Outcome distribution of a combat, previewed by the engine without fighting it.
+// Health maps are keyed by remaining health (0-100) with their chances.
+export interface CombatOdds {
+  attackerStrength: number;
+  defenderStrength: number;
+  attackerWins: number;
+  defenderWins: number;
+  stalemate: number;
+  attackerHealth: Record<string, number>;
+  defenderHealth: Record<string, number>;
+  expectedAttackerHealth: number;
+  expectedDefenderHealth: number;
+}
+
+// Deaths by cause, tallied by the engine's human simulation
+export interface Mortality {
+  starvation: number;
+  age: number;
+  disease: number;
+  accident: number; // Working children killed in accidents
+  combat: number; // Killed in fighting, defending their settlement or in overcrowded strife
+  childbirth: number; // Mothers who died giving birth
+}
+
+// A settlement's deaths by cause, reported by the engine
+export interface MortalityReport extends Mortality {
+  settlementId: string;
+  playerId: string;
+  name: string;
+  population: number;
+  total: number;
+}
+
+// What a tile yields with a set of improvements and technologies, computed by
+// the engine's shared yields package so tooltips match what the engine applies.
+// Yields are multipliers of normal output.
+export interface TileYieldsPreview {
+  x: number;
+  y: number;
+  improvements: string[];
+  technologies: string[];
+  food: number;
+  production: number;
+  science: number;
+  herdBonus: number; // Extra share of the settlement's food from the tile's herds
+}
+
+// Sea trade route between two harbor settlements
+export interface SeaRoute {
+  partnerId: string;
+  distance: number; // Tiles of water crossed
+  raided?: boolean; // Pirates took this year's cargo
+}
+
+export interface Order {
+  orderId: string;
+  gameId: string;
+  playerId: string;
+  unitId?: string; // Absent for surrender, build, diplomatic and gift orders
+  orderType: 'settle' | 'surrender' | 'chop' | 'build' | 'activate' | 'demand' | 'accept' | 'break' | 'gift' | 'attack' | 'road' | 'transform' | 'move';
+  target?: {
+    x: number;
+    y: number;
+  };
+  settlementId?: string; // Settlement a build, gift or road order is for, or an attack targets
+  item?: string; // Building a build order constructs, agreement type demanded, or transformation to carry out
+  targetPlayer?: string; // Other player of a diplomatic order, or minor civ of a gift
+  amount?: number; // Yearly production a tribute demand asks for, or production gifted
+
+  status: 'pending' | 'executed' | 'rejected' | 'proposed'; // Proposed by a settlement's people until the player approves it
+  reason?: string;
+  path?: { x: number; y: number }[]; // Tiles the unit crossed carrying out the order
+  createdAt: Date;
+  processedAt?: Date;
+}
+
+export type GameEventType = 'game_started' | 'player_eliminated' | 'player_surrendered' | 'player_released' | 'victory' | 'unit_moved' | 'agreement_made' | 'agreement_broken' | 'settlement_grew'
+  | 'minor_civ_allied' | 'minor_quest_done' | 'settlement_taken' | 'settlement_crisis'
+  | 'objective_assigned' | 'objective_done' | 'objective_failed' | 'project_proposed' | 'revolt'
+  | 'land_transformed' | 'heavy_rain' | 'flood' | 'earthquake' | 'new_era';
+
+// Events every player in a game may see; the rest are only shown to the player they concern and their teammates
+export const PUBLIC_EVENT_TYPES: GameEventType[] = [
+  'game_started', 'player_eliminated', 'player_surrendered', 'player_released', 'victory',
+  'agreement_made', 'agreement_broken', 'minor_civ_allied', 'settlement_taken', 'new_era',
+];
+
+// Notable game occurrence recorded by the engine
+export interface GameEvent {
+  eventId: string;
+  gameId: string;
+  year: number;
+  type: GameEventType;
+  playerId?: string;
+  detail?: string;
+  movement?: UnitMovement;
+  calendar?: Calendar; // The calendar of the event's year
+  createdAt: Date;
+}
+
+// One tick of a unit's travel: the tiles it crosses, starting at its origin,
+// and when it reaches each in milliseconds from the start of the move
+export interface UnitMovement {
+  unitId: string;
+  keyframes: { x: number; y: number; atMs: number }[];
+  durationMs: number;
+}
+
+// A user's achievements across every game they finished, aggregated by the engine
+export interface UserStats {
+  userId: string;
+  gamesPlayed: number;
+  wins?: Record<string, number>; // Victory type -> games won that way
+  fastestFireMastery?: number; // Fewest years from a game's start to mastering fire
+  gameIds: string[]; // Games already counted
+  lastUpdated: Date;
+}
+
+// How far one player's civilization had come at a point in a game
+export interface PlayerStanding {
+  playerId: string;
+  population: number;
+  science: number; // Science their settlements have produced
+  territory: number; // Tiles they own
+  settlements: number;
+}
+
+// Every player's standing in one year, sampled by the engine as a game ticks
+export interface GameHistoryPoint {
+  gameId: string;
+  year: number;
+  players: PlayerStanding[];
+}
+
+// A record a player set over a game, such as its largest city
+export interface Superlative {
+  title: string;
+  playerId: string;
+  settlementId?: string; // For records set by a settlement
+  detail: string;
+  value: number;
+  year: number;
+}
+
+// The report the engine writes when a game finishes, for its results screen
+export interface GameSummary {
+  gameId: string;
+  startYear: number;
+  endYear: number;
+  winners: string[];
+  victoryType: string;
+  standings: PlayerStanding[]; // Final standings, the largest civilization first
+  timeline: GameEvent[]; // Key events, oldest first
+  history: GameHistoryPoint[]; // Standings over the game, oldest first
+  superlatives: Superlative[];
+  createdAt: Date;
+}
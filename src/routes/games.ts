@@ -1,17 +1,47 @@
 import { Router, Request, Response } from 'express';
-import { getGamesCollection } from '../db/connection';
+import { getGamesCollection, getSettlementsCollection, getMapTilesCollection, getGameSummariesCollection } from '../db/connection';
 import { Game } from '../models/types';
 import crypto from 'crypto';
-import { generateUuid } from '../utils/crypto';
+import { generateUuid, signPlayerKey } from '../utils/crypto';
+import { config } from '../config';
+import { pickTeam } from '../utils/teams';
+import { parseShareCode } from '../utils/shareCode';
 
 const router = Router();
 
+// Upper bound on players in a persistent game, including late joiners
+const MAX_PERSISTENT_PLAYERS = 16;
+
+const CLOCK_PATTERN = /^([01]\d|2[0-3]):[0-5]\d$/;
+
+/**
+ * Validate a tick schedule from a request body, returning an error message
+ * or null when it is acceptable
+ */
+function validateSchedule(schedule: any): string | null {
+  if (typeof schedule !== 'object' || schedule === null) {
+    return 'schedule must be an object';
+  }
+  const { windowStart, windowEnd, pauseWeekends } = schedule;
+  if ((windowStart === undefined) !== (windowEnd === undefined)) {
+    return 'schedule needs both windowStart and windowEnd';
+  }
+  if (windowStart !== undefined && (!CLOCK_PATTERN.test(windowStart) || !CLOCK_PATTERN.test(windowEnd))) {
+    return 'schedule windows must be HH:MM';
+  }
+  if (pauseWeekends !== undefined && typeof pauseWeekends !== 'boolean') {
+    return 'pauseWeekends must be a boolean';
+  }
+  return null;
+}
+
 /**
- * POST /api/games - Create a new game
+ * POST /api/games - Create a new game. Given a shareCode, the game is played
+ * on the shared world, and maxPlayers defaults to the code's player count.
  */
 router.post('/', async (req: Request, res: Response): Promise<void> => {
   try {
-    const { maxPlayers } = req.body;
+    const { shareCode, startMode, settleTimeLimitSeconds, settlementMergeRule, governorAfterTicks, noShowGraceTicks, noShowPolicy, persistent, teamCount, team, schedule, pacing } = req.body;
     const userId = req.session?.userId;
 
     // Validate authentication
@@ -20,12 +50,88 @@ router.post('/', async (req: Request, res: Response): Promise<void> => {
       return;
     }
 
+    // A shared world fixes the player count its map was sized for
+    const share = typeof shareCode === 'string' ? parseShareCode(shareCode) : null;
+    if (shareCode !== undefined && !share) {
+      res.status(400).json({ error: 'shareCode is not a valid share code' });
+      return;
+    }
+    const maxPlayers = req.body.maxPlayers ?? share?.players;
+    if (share && maxPlayers !== share.players) {
+      res.status(400).json({ error: `This shared world is made for ${share.players} players` });
+      return;
+    }
+
     // Validate maxPlayers
     if (!maxPlayers || typeof maxPlayers !== 'number' || maxPlayers < 2 || maxPlayers > 8) {
       res.status(400).json({ error: 'maxPlayers must be a number between 2 and 8' });
       return;
     }
 
+    // Validate optional start mode
+    if (startMode !== undefined && startMode !== 'auto' && startMode !== 'player') {
+      res.status(400).json({ error: "startMode must be 'auto' or 'player'" });
+      return;
+    }
+    if (settleTimeLimitSeconds !== undefined &&
+        (typeof settleTimeLimitSeconds !== 'number' || settleTimeLimitSeconds < 10 || settleTimeLimitSeconds > 600)) {
+      res.status(400).json({ error: 'settleTimeLimitSeconds must be a number between 10 and 600' });
+      return;
+    }
+
+    if (settlementMergeRule !== undefined && settlementMergeRule !== 'merge' && settlementMergeRule !== 'suburb') {
+      res.status(400).json({ error: "settlementMergeRule must be 'merge' or 'suburb'" });
+      return;
+    }
+
+    if (governorAfterTicks !== undefined &&
+        (typeof governorAfterTicks !== 'number' || !Number.isInteger(governorAfterTicks) || governorAfterTicks < 1)) {
+      res.status(400).json({ error: 'governorAfterTicks must be a positive integer' });
+      return;
+    }
+
+    if (persistent !== undefined && typeof persistent !== 'boolean') {
+      res.status(400).json({ error: 'persistent must be a boolean' });
+      return;
+    }
+
+    if (noShowGraceTicks !== undefined &&
+        (typeof noShowGraceTicks !== 'number' || !Number.isInteger(noShowGraceTicks) || noShowGraceTicks < 1)) {
+      res.status(400).json({ error: 'noShowGraceTicks must be a positive integer' });
+      return;
+    }
+    if (noShowPolicy !== undefined && noShowPolicy !== 'governor' && noShowPolicy !== 'release') {
+      res.status(400).json({ error: "noShowPolicy must be 'governor' or 'release'" });
+      return;
+    }
+    if (noShowPolicy === 'release' && !persistent) {
+      res.status(400).json({ error: 'Only persistent games can release no-show regions to late joiners' });
+      return;
+    }
+
+    if (teamCount !== undefined &&
+        (!Number.isInteger(teamCount) || teamCount < 2 || teamCount > maxPlayers)) {
+      res.status(400).json({ error: 'teamCount must be an integer between 2 and maxPlayers' });
+      return;
+    }
+    if (schedule !== undefined) {
+      const scheduleError = validateSchedule(schedule);
+      if (scheduleError) {
+        res.status(400).json({ error: scheduleError });
+        return;
+      }
+    }
+
+    if (pacing !== undefined && pacing !== 'constant' && pacing !== 'eras') {
+      res.status(400).json({ error: "pacing must be 'constant' or 'eras'" });
+      return;
+    }
+
+    if (team !== undefined && (!teamCount || !Number.isInteger(team) || team < 0 || team >= teamCount)) {
+      res.status(400).json({ error: 'team must be an index below teamCount' });
+      return;
+    }
+
     // Create game
     const gameId = generateUuid('game');
     const game: Game = {
@@ -37,6 +143,22 @@ router.post('/', async (req: Request, res: Response): Promise<void> => {
       state: 'waiting',
       currentYear: -5000, // 5000 BC
       createdAt: new Date(),
+      ...(startMode && { startMode }),
+      ...(settleTimeLimitSeconds && { settleTimeLimitSeconds }),
+      ...(settlementMergeRule && { settlementMergeRule }),
+      ...(governorAfterTicks && { governorAfterTicks }),
+      ...(persistent && { persistent }),
+      ...(noShowGraceTicks && { noShowGraceTicks }),
+      ...(noShowPolicy && { noShowPolicy }),
+      ...(teamCount && { teamCount, teams: { [userId]: team ?? 0 } }),
+      ...(share && { shareCode: shareCode.trim().toUpperCase() }),
+      ...(pacing && { pacing }),
+      ...(schedule && {
+        schedule: {
+          ...(schedule.windowStart && { windowStart: schedule.windowStart, windowEnd: schedule.windowEnd }),
+          ...(schedule.pauseWeekends && { pauseWeekends: true }),
+        },
+      }),
     };
 
     await getGamesCollection().insertOne(game);
@@ -48,6 +170,10 @@ router.post('/', async (req: Request, res: Response): Promise<void> => {
         maxPlayers: game.maxPlayers,
         currentPlayers: game.currentPlayers,
         state: game.state,
+        startMode: game.startMode || 'auto',
+        persistent: game.persistent || false,
+        teamCount: game.teamCount,
+        ...(share && { shareCode: game.shareCode, scenario: share.scenario }),
         createdAt: game.createdAt,
       },
     });
@@ -75,6 +201,7 @@ router.get('/', async (req: Request, res: Response): Promise<void> => {
         maxPlayers: game.maxPlayers,
         currentPlayers: game.currentPlayers,
         state: game.state,
+        persistent: game.persistent || false,
         currentYear: game.currentYear,
         createdAt: game.createdAt,
         startedAt: game.startedAt,
@@ -108,11 +235,18 @@ router.get('/:gameId', async (req: Request, res: Response): Promise<void> => {
         maxPlayers: game.maxPlayers,
         currentPlayers: game.currentPlayers,
         playerList: game.playerList,
+        eliminatedPlayers: game.eliminatedPlayers || [],
+        teamCount: game.teamCount,
+        teams: game.teams,
+        winners: game.winners,
+        schedule: game.schedule,
+        pacing: game.pacing,
         state: game.state,
         currentYear: game.currentYear,
         createdAt: game.createdAt,
         startedAt: game.startedAt,
         lastTickAt: game.lastTickAt,
+        calendar: game.calendar,
       },
     });
   } catch (error) {
@@ -127,6 +261,7 @@ router.get('/:gameId', async (req: Request, res: Response): Promise<void> => {
 router.post('/:gameId/join', async (req: Request, res: Response): Promise<void> => {
   try {
     const { gameId } = req.params;
+    const { team } = req.body;
     const userId = req.session?.userId;
 
     // Validate authentication
@@ -145,8 +280,10 @@ router.post('/:gameId/join', async (req: Request, res: Response): Promise<void>
       return;
     }
 
-    // Validate join eligibility
-    if (game.state !== 'waiting') {
+    // Validate join eligibility; persistent games take late joiners, whom
+    // the engine gives a fresh region and a civ on its next tick
+    const lateJoin = game.state === 'started' && game.persistent === true;
+    if (game.state !== 'waiting' && !lateJoin) {
       res.status(400).json({ error: 'Game has already started' });
       return;
     }
@@ -156,28 +293,35 @@ router.post('/:gameId/join', async (req: Request, res: Response): Promise<void>
       return;
     }
 
-    if (game.currentPlayers >= game.maxPlayers) {
+    if (game.currentPlayers >= (lateJoin ? MAX_PERSISTENT_PLAYERS : game.maxPlayers)) {
       res.status(400).json({ error: 'Game is full' });
       return;
     }
 
+    if (team !== undefined && (!game.teamCount || !Number.isInteger(team) || team < 0 || team >= game.teamCount)) {
+      res.status(400).json({ error: 'team must be an index below teamCount' });
+      return;
+    }
+
     // Add player to game
     const newPlayerCount = game.currentPlayers + 1;
     const update: Partial<Game> = {
       currentPlayers: newPlayerCount,
       playerList: [...game.playerList, userId],
     };
+    if (game.teamCount) {
+      update.teams = { ...game.teams, [userId]: pickTeam(game, team) };
+    }
 
-    // If game is now full, start it
-    if (newPlayerCount >= game.maxPlayers) {
-      update.state = 'started';
+    // If the lobby is now full, hand it to the engine to generate the map;
+    // the engine moves it on to started
+    if (!lateJoin && newPlayerCount >= game.maxPlayers) {
+      update.state = 'starting';
       update.startedAt = new Date();
-      // Don't set lastTickAt - let it remain undefined so simulation engine
-      // recognizes this as a new game and generates the map
     }
 
     const result = await gamesCollection.updateOne(
-      { gameId, currentPlayers: game.currentPlayers }, // Optimistic locking
+      { gameId, state: game.state, currentPlayers: game.currentPlayers }, // Optimistic locking
       { $set: update }
     );
 
@@ -205,6 +349,126 @@ router.post('/:gameId/join', async (req: Request, res: Response): Promise<void>
   }
 });
 
+/**
+ * POST /api/games/:gameId/player-key - Issue an API key identifying the current player in a game
+ * Send it as the X-Player-Key header to game-scoped endpoints that check player identity.
+ */
+router.post('/:gameId/player-key', async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    // Keys stand in for a signed-in player, so only an authenticated session may issue one
+    const userId = req.session?.state === 'authenticated' ? req.session.userId : undefined;
+
+    // Validate authentication
+    if (!userId) {
+      res.status(401).json({ error: 'Authentication required' });
+      return;
+    }
+
+    const game = await getGamesCollection().findOne({ gameId });
+    if (!game) {
+      res.status(404).json({ error: 'Game not found' });
+      return;
+    }
+    if (!game.playerList.includes(userId)) {
+      res.status(403).json({ error: 'Not a player in this game' });
+      return;
+    }
+
+    res.json({
+      success: true,
+      playerKey: signPlayerKey(gameId, userId, config.playerKeySecret),
+    });
+  } catch (error) {
+    console.error('Error issuing player key:', error);
+    res.status(500).json({ error: 'Failed to issue player key' });
+  }
+});
+
+/**
+ * GET /api/games/:gameId/scoreboard - Standings per player and per team
+ * Players are ranked by population, then territory.
+ */
+router.get('/:gameId/scoreboard', async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+
+    const game = await getGamesCollection().findOne({ gameId });
+    if (!game) {
+      res.status(404).json({ error: 'Game not found' });
+      return;
+    }
+
+    const settlements = await getSettlementsCollection().find({ gameId }).toArray();
+    const territory = await getMapTilesCollection()
+      .aggregate<{ _id: string; tiles: number }>([
+        { $match: { gameId, ownerId: { $in: game.playerList } } },
+        { $group: { _id: '$ownerId', tiles: { $sum: 1 } } },
+      ])
+      .toArray();
+    const tilesByPlayer = new Map(territory.map((entry) => [entry._id, entry.tiles]));
+
+    const players = game.playerList.map((playerId) => {
+      const owned = settlements.filter((settlement) => settlement.playerId === playerId);
+      return {
+        playerId,
+        team: game.teams?.[playerId],
+        eliminated: (game.eliminatedPlayers || []).includes(playerId),
+        settlements: owned.length,
+        population: owned.reduce((sum, settlement) => sum + (settlement.population || 0), 0),
+        territory: tilesByPlayer.get(playerId) || 0,
+      };
+    });
+    players.sort((a, b) => b.population - a.population || b.territory - a.territory);
+
+    const teams = game.teamCount
+      ? Array.from({ length: game.teamCount }, (_, team) => {
+          const members = players.filter((player) => player.team === team);
+          return {
+            team,
+            players: members.map((player) => player.playerId),
+            eliminated: members.length > 0 && members.every((player) => player.eliminated),
+            population: members.reduce((sum, player) => sum + player.population, 0),
+            territory: members.reduce((sum, player) => sum + player.territory, 0),
+          };
+        })
+      : undefined;
+
+    res.json({
+      success: true,
+      state: game.state,
+      winners: game.winners || [],
+      players,
+      ...(teams && { teams }),
+    });
+  } catch (error) {
+    console.error('Error building scoreboard:', error);
+    res.status(500).json({ error: 'Failed to build scoreboard' });
+  }
+});
+
+/**
+ * GET /api/games/:gameId/summary - The report written when the game finished
+ * Holds the key events, each player's standings over the years for graphs,
+ * and the records set. 404 until the game has finished.
+ */
+router.get('/:gameId/summary', async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+
+    const summary = await getGameSummariesCollection().findOne({ gameId }, { projection: { _id: 0 } });
+    if (!summary) {
+      res.status(404).json({ error: 'No summary until the game has finished' });
+      return;
+    }
+
+    res.json({ success: true, summary });
+  } catch (error) {
+    console.error('Error fetching game summary:', error);
+    res.status(500).json({ error: 'Failed to fetch game summary' });
+  }
+});
+
 /**
  * GET /api/games/my-games - Get current user's games
  */
@@ -1,5 +1,10 @@
 import { Router, Request, Response } from 'express';
-import { getMapTilesCollection, getStartingPositionsCollection, getMapMetadataCollection } from '../db/connection';
+import { getGamesCollection, getMapTilesCollection, getStartingPositionsCollection, getMapMetadataCollection, getSettlementsCollection, getExploredTilesCollection, getMinimapsCollection, getBordersCollection } from '../db/connection';
+import { buildSettlerReport } from '../utils/settlerReport';
+import { requirePlayer } from '../middleware/playerIdentity';
+import { visibleTilesFilter } from '../utils/teams';
+import { config } from '../config';
+import { TileYieldsPreview } from '../models/types';
 
 const router = Router();
 
@@ -26,20 +31,14 @@ router.get('/:gameId/metadata', async (req: Request, res: Response) => {
   }
 });
 
-// Get map tiles for a game (no server-side fog of war)
-router.get('/:gameId/tiles', async (req: Request, res: Response) => {
+// Get the map tiles the verified player can see (server-side fog of war)
+router.get('/:gameId/tiles', requirePlayer, async (req: Request, res: Response) => {
   try {
     const { gameId } = req.params;
-    const userId = (req as any).session?.userId;
-
-    if (!userId) {
-      return res.status(401).json({ error: 'Not authenticated' });
-    }
 
-    // Return ALL tiles for the game - no server-side fog of war
-    // Client will handle visibility/fog of war if needed
+    const game = await getGamesCollection().findOne({ gameId });
     const tiles = await getMapTilesCollection()
-      .find({ gameId })
+      .find({ gameId, ...visibleTilesFilter(game!, req.playerId!) })
       .toArray();
 
     res.json({ tiles });
@@ -49,12 +48,41 @@ router.get('/:gameId/tiles', async (req: Request, res: Response) => {
   }
 });
 
-// Get all tiles (no visibility filter) - for debugging/admin
+// Get tiles the verified player can see that were modified after a tick (game
+// year), so clients can poll for deltas instead of refetching their whole view.
+// Pass the returned currentTick as sinceTick on the next poll. The engine stamps
+// changes with the year it is processing, which is the current year until the
+// tick advances it, so the cursor stops just short of the current year.
+router.get('/:gameId/tiles/changes', requirePlayer, async (req: Request, res: Response) => {
+  try {
+    const { gameId } = req.params;
+
+    const sinceTick = Number(req.query.sinceTick);
+    if (req.query.sinceTick === undefined || !Number.isInteger(sinceTick)) {
+      return res.status(400).json({ error: 'sinceTick must be an integer' });
+    }
+
+    const game = await getGamesCollection().findOne({ gameId });
+    const tiles = await getMapTilesCollection()
+      .find({ gameId, lastModifiedTick: { $gt: sinceTick }, ...visibleTilesFilter(game!, req.playerId!) })
+      .toArray();
+
+    res.json({ tiles, currentTick: game!.currentYear - 1 });
+  } catch (error) {
+    console.error('Error fetching map tile changes:', error);
+    res.status(500).json({ error: 'Failed to fetch map tile changes' });
+  }
+});
+
+// Get all tiles (no visibility filter) - for debugging, only when debug routes are enabled
 router.get('/:gameId/tiles/all', async (req: Request, res: Response) => {
   try {
     const { gameId } = req.params;
-    const userId = (req as any).session?.userId;
+    const userId = req.session?.state === 'authenticated' ? req.session.userId : undefined;
 
+    if (!config.debugRoutes) {
+      return res.status(404).json({ error: 'Not found' });
+    }
     if (!userId) {
       return res.status(401).json({ error: 'Not authenticated' });
     }
@@ -70,6 +98,75 @@ router.get('/:gameId/tiles/all', async (req: Request, res: Response) => {
   }
 });
 
+// Get the verified player's view of the map (server-side fog of war).
+// `tiles` are live: currently visible or inside the borders of the player or a teammate.
+// `explored` are last-seen records of tiles the player has seen before but cannot see now.
+router.get('/:gameId/tiles/visible', requirePlayer, async (req: Request, res: Response) => {
+  try {
+    const { gameId } = req.params;
+    const playerId = req.playerId!;
+
+    const game = await getGamesCollection().findOne({ gameId });
+    const tiles = await getMapTilesCollection()
+      .find({ gameId, ...visibleTilesFilter(game!, playerId) })
+      .toArray();
+
+    const live = new Set(tiles.map((tile) => `${tile.x},${tile.y}`));
+    const explored = (await getExploredTilesCollection()
+      .find({ gameId, playerId })
+      .toArray())
+      .filter((record) => !live.has(`${record.x},${record.y}`));
+
+    res.json({ tiles, explored });
+  } catch (error) {
+    console.error('Error fetching visible map tiles:', error);
+    res.status(500).json({ error: 'Failed to fetch visible map tiles' });
+  }
+});
+
+// Get the verified player's cached minimap (see Minimap for the encoding)
+router.get('/:gameId/minimap', requirePlayer, async (req: Request, res: Response) => {
+  try {
+    const { gameId } = req.params;
+
+    const minimap = await getMinimapsCollection().findOne(
+      { gameId, playerId: req.playerId },
+      { projection: { _id: 0 } }
+    );
+
+    if (!minimap) {
+      return res.status(404).json({ error: 'Minimap not generated yet' });
+    }
+
+    res.json({ minimap });
+  } catch (error) {
+    console.error('Error fetching minimap:', error);
+    res.status(500).json({ error: 'Failed to fetch minimap' });
+  }
+});
+
+// Get the territory borders the verified player knows of, cached by the
+// engine with their minimap (see Borders for the encoding)
+router.get('/:gameId/borders', requirePlayer, async (req: Request, res: Response) => {
+  try {
+    const { gameId } = req.params;
+
+    const borders = await getBordersCollection().findOne(
+      { gameId, playerId: req.playerId },
+      { projection: { _id: 0 } }
+    );
+
+    if (!borders) {
+      return res.status(404).json({ error: 'Borders not generated yet' });
+    }
+
+    res.json({ borders });
+  } catch (error) {
+    console.error('Error fetching borders:', error);
+    res.status(500).json({ error: 'Failed to fetch borders' });
+  }
+});
+
 // Get player's starting position
 router.get('/:gameId/starting-position', async (req: Request, res: Response) => {
   try {
@@ -96,4 +193,78 @@ router.get('/:gameId/starting-position', async (req: Request, res: Response) =>
   }
 });
 
+// Get a settler's report summarizing the player's guaranteed starting footprint
+router.get('/:gameId/starting-position/report', async (req: Request, res: Response) => {
+  try {
+    const { gameId } = req.params;
+    const userId = (req as any).session?.userId;
+
+    if (!userId) {
+      return res.status(401).json({ error: 'Not authenticated' });
+    }
+
+    const position = await getStartingPositionsCollection().findOne({
+      gameId,
+      playerId: userId
+    });
+
+    if (!position) {
+      return res.status(404).json({ error: 'Starting position not found' });
+    }
+
+    const { minX, maxX, minY, maxY } = position.guaranteedFootprint;
+    const tiles = await getMapTilesCollection()
+      .find({
+        gameId,
+        x: { $gte: minX, $lte: maxX },
+        y: { $gte: minY, $lte: maxY },
+      })
+      .toArray();
+
+    const settled = (await getSettlementsCollection().countDocuments({ gameId, playerId: userId })) > 0;
+
+    res.json({ report: buildSettlerReport(position, tiles), settled });
+  } catch (error) {
+    console.error('Error building settler report:', error);
+    res.status(500).json({ error: 'Failed to build settler report' });
+  }
+});
+
+/**
+ * GET /api/map/:gameId/tiles/:x/:y/yields - What a tile yields, as the engine computes it
+ * Query: improvements and techs, comma-separated, to preview the tile with other
+ * improvements or technologies; by default the tile's own improvements and the
+ * technologies of the player's settlements are used.
+ */
+router.get('/:gameId/tiles/:x/:y/yields', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId, x, y } = req.params;
+    const { improvements, techs } = req.query;
+
+    if (!/^-?\d+$/.test(x) || !/^-?\d+$/.test(y)) {
+      res.status(400).json({ error: 'x and y must be integers' });
+      return;
+    }
+
+    const params = new URLSearchParams({ gameId, x, y, playerId: req.playerId! });
+    if (typeof improvements === 'string') {
+      params.set('improvements', improvements);
+    }
+    if (typeof techs === 'string') {
+      params.set('techs', techs);
+    }
+    const engineRes = await fetch(`${config.engineQueryUrl}/yields?${params}`);
+    const result = await engineRes.json();
+    if (!engineRes.ok) {
+      res.status(engineRes.status).json(result);
+      return;
+    }
+
+    res.json({ success: true, yields: result as TileYieldsPreview });
+  } catch (error) {
+    console.error('Error previewing tile yields:', error);
+    res.status(500).json({ error: 'Failed to preview tile yields' });
+  }
+});
+
 export default router;
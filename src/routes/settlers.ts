@@ -1,5 +1,10 @@
 import { Router, Request, Response } from 'express';
-import { getUnitsCollection, getSettlementsCollection } from '../db/connection';
+import { getGamesCollection, getMapTilesCollection, getUnitsCollection, getSettlementsCollection, getOrdersCollection, getGameEventsCollection, getDiplomacyCollection, getMinorCivsCollection, getObjectivesCollection, getPlayerPoliciesCollection, getPlayerSettingsCollection } from '../db/connection';
+import { CombatOdds, MortalityReport, Order, PlayerPolicy, PlayerSettings, PolicyLevel, PUBLIC_EVENT_TYPES, WORKER_AUTOMATION_MODES } from '../models/types';
+import { config } from '../config';
+import { generateUuid } from '../utils/crypto';
+import { requirePlayer } from '../middleware/playerIdentity';
+import { teammatesOf, visibleTilesFilter } from '../utils/teams';
 
 const router = Router();
 
@@ -30,6 +35,7 @@ router.get('/:gameId/units', async (req: Request, res: Response): Promise<void>
         location: unit.location,
         stepsTaken: unit.stepsTaken,
         populationCost: unit.populationCost,
+        automation: unit.automation,
       })),
     });
   } catch (error) {
@@ -64,6 +70,12 @@ router.get('/:gameId/settlements', async (req: Request, res: Response): Promise<
         name: settlement.name,
         type: settlement.type,
         location: settlement.location,
+        population: settlement.population,
+        parentId: settlement.parentId,
+        desires: settlement.desires,
+        unhappiness: settlement.unhappiness,
+        unrest: settlement.unrest,
+        gold: settlement.gold,
       })),
     });
   } catch (error) {
@@ -72,4 +84,742 @@ router.get('/:gameId/settlements', async (req: Request, res: Response): Promise<
   }
 });
 
+/**
+ * GET /api/game/:gameId/settlements/:settlementId - One of the player's settlements in full
+ * Looking at a settlement tells the engine a player's attention is on it, so
+ * under attention fidelity it simulates with the full human model for a while.
+ */
+router.get('/:gameId/settlements/:settlementId', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId, settlementId } = req.params;
+
+    const settlement = await getSettlementsCollection().findOne(
+      { gameId, settlementId, playerId: req.playerId },
+      { projection: { _id: 0 } }
+    );
+    if (!settlement) {
+      res.status(404).json({ error: 'Settlement not found' });
+      return;
+    }
+
+    const params = new URLSearchParams({ settlementId });
+    fetch(`${config.engineQueryUrl}/attention?${params}`, { method: 'POST' }).catch((error) => {
+      console.error('Error noting attention:', error);
+    });
+
+    res.json({ success: true, settlement });
+  } catch (error) {
+    console.error('Error getting settlement:', error);
+    res.status(500).json({ error: 'Failed to get settlement' });
+  }
+});
+
+/**
+ * POST /api/game/:gameId/orders - Queue an order for one of the player's units
+ * Body: { unitId, orderType: 'settle' | 'chop' | 'activate', target?: { x, y } }
+ *    or { unitId, orderType: 'move', target: { x, y } }
+ *    or { unitId, orderType: 'attack', settlementId }
+ *    or { unitId, orderType: 'transform', item: 'irrigation' }
+ *    or { settlementId, orderType: 'build', item }
+ *    or { settlementId, orderType: 'road', target: { x, y } }
+ * Settlers settle at the target; units travel to the target over as many
+ * years as their movement points and the terrain take; workers chop the
+ * forest they stand on, or begin a long project transforming its terrain;
+ * great people are spent on their ability; units attack an adjacent minor
+ * civ settlement; settlements spend banked production on a building such as
+ * a harbor, or plan a road to the target for automated workers to pave.
+ * The player is identified by X-Player-Key or the session and must be in the game.
+ * The engine validates and executes pending orders on the next tick.
+ */
+router.post('/:gameId/orders', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const userId = req.playerId!;
+    const { unitId, orderType, target, settlementId, item } = req.body;
+
+    if (orderType === 'build' || orderType === 'road') {
+      if (typeof settlementId !== 'string' || (orderType === 'build' && typeof item !== 'string')) {
+        res.status(400).json({ error: orderType === 'build' ? 'settlementId and item are required' : 'settlementId is required' });
+        return;
+      }
+      if (orderType === 'road' && (typeof target?.x !== 'number' || typeof target?.y !== 'number')) {
+        res.status(400).json({ error: 'target must have numeric x and y' });
+        return;
+      }
+      const settlement = await getSettlementsCollection().findOne({ gameId, settlementId, playerId: userId });
+      if (!settlement) {
+        res.status(404).json({ error: 'Settlement not found' });
+        return;
+      }
+
+      const order: Order = {
+        orderId: generateUuid('order'),
+        gameId,
+        playerId: userId,
+        orderType,
+        settlementId,
+        ...(orderType === 'build' ? { item } : { target: { x: target.x, y: target.y } }),
+        status: 'pending',
+        createdAt: new Date(),
+      };
+      await getOrdersCollection().insertOne(order);
+      res.status(202).json({ success: true, order: { orderId: order.orderId, status: order.status } });
+      return;
+    }
+
+    if (orderType !== 'settle' && orderType !== 'chop' && orderType !== 'activate' && orderType !== 'attack' && orderType !== 'transform' && orderType !== 'move') {
+      res.status(400).json({ error: "orderType must be 'settle', 'chop', 'activate', 'attack', 'transform', 'move', 'build' or 'road'" });
+      return;
+    }
+    if (orderType === 'move' && target === undefined) {
+      res.status(400).json({ error: 'target is required' });
+      return;
+    }
+    if (orderType === 'transform' && typeof item !== 'string') {
+      res.status(400).json({ error: 'item is required' });
+      return;
+    }
+    if (orderType === 'attack' && typeof settlementId !== 'string') {
+      res.status(400).json({ error: 'settlementId is required' });
+      return;
+    }
+    if (target !== undefined && (typeof target?.x !== 'number' || typeof target?.y !== 'number')) {
+      res.status(400).json({ error: 'target must have numeric x and y' });
+      return;
+    }
+
+    const unit = await getUnitsCollection().findOne({ gameId, unitId, playerId: userId });
+    if (!unit) {
+      res.status(404).json({ error: 'Unit not found' });
+      return;
+    }
+
+    const order: Order = {
+      orderId: generateUuid('order'),
+      gameId,
+      playerId: userId,
+      unitId,
+      orderType,
+      ...(target && { target: { x: target.x, y: target.y } }),
+      ...(orderType === 'attack' && { settlementId }),
+      ...(orderType === 'transform' && { item }),
+      status: 'pending',
+      createdAt: new Date(),
+    };
+    await getOrdersCollection().insertOne(order);
+
+    res.status(202).json({
+      success: true,
+      order: {
+        orderId: order.orderId,
+        status: order.status,
+      },
+    });
+  } catch (error) {
+    console.error('Error creating order:', error);
+    res.status(500).json({ error: 'Failed to create order' });
+  }
+});
+
+/**
+ * POST /api/game/:gameId/orders/:orderId/approve - Approve a project one of
+ * the player's settlements proposed
+ * Settlements whose people want security, trade or learning propose the
+ * building that would meet the want, and settlements joined by a busy route
+ * propose a road along it; once approved the proposal is queued like any
+ * order and executes on the next tick.
+ */
+router.post('/:gameId/orders/:orderId/approve', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId, orderId } = req.params;
+
+    const result = await getOrdersCollection().updateOne(
+      { gameId, orderId, playerId: req.playerId!, status: 'proposed' },
+      { $set: { status: 'pending' } }
+    );
+    if (result.matchedCount === 0) {
+      res.status(404).json({ error: 'Proposal not found' });
+      return;
+    }
+
+    res.status(202).json({ success: true, order: { orderId, status: 'pending' } });
+  } catch (error) {
+    console.error('Error approving proposal:', error);
+    res.status(500).json({ error: 'Failed to approve proposal' });
+  }
+});
+
+const POLICY_LEVELS: PolicyLevel[] = ['low', 'normal', 'high'];
+
+/**
+ * GET /api/game/:gameId/policy - Get the player's policies; unset levels are
+ * 'normal' and unset levies 0
+ */
+router.get('/:gameId/policy', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const policy = await getPlayerPoliciesCollection().findOne({ gameId, playerId: req.playerId! });
+    res.json({
+      success: true,
+      policy: {
+        infrastructure: policy?.infrastructure ?? 'normal',
+        taxRate: policy?.taxRate ?? 0,
+        corvee: policy?.corvee ?? 0,
+      },
+    });
+  } catch (error) {
+    console.error('Error fetching policy:', error);
+    res.status(500).json({ error: 'Failed to fetch policy' });
+  }
+});
+
+/**
+ * PUT /api/game/:gameId/policy - Set some or all of the player's policies
+ * Body: { infrastructure?: 'low' | 'normal' | 'high', taxRate?: number, corvee?: number }
+ * Routes between the player's settlements that see enough travel and trade
+ * get roads: proposed for approval when normal, planned for automated
+ * workers at once when high, and neither when low. The tax rate and corvée
+ * are shares, 0 to 1, of each settlement's food and work hours banked there
+ * as gold and production; they make its people unhappier and grow slower,
+ * and each settlement bears no more than its technologies allow.
+ */
+router.put('/:gameId/policy', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const { infrastructure, taxRate, corvee } = req.body;
+
+    if (infrastructure !== undefined && !POLICY_LEVELS.includes(infrastructure)) {
+      res.status(400).json({ error: "infrastructure must be 'low', 'normal' or 'high'" });
+      return;
+    }
+    for (const [name, share] of Object.entries({ taxRate, corvee })) {
+      if (share !== undefined && (typeof share !== 'number' || share < 0 || share > 1)) {
+        res.status(400).json({ error: `${name} must be a number from 0 to 1` });
+        return;
+      }
+    }
+
+    const changes: Partial<PlayerPolicy> = { updatedAt: new Date() };
+    if (infrastructure !== undefined) changes.infrastructure = infrastructure;
+    if (taxRate !== undefined) changes.taxRate = taxRate;
+    if (corvee !== undefined) changes.corvee = corvee;
+    const policy = await getPlayerPoliciesCollection().findOneAndUpdate(
+      { gameId, playerId: req.playerId! },
+      { $set: changes },
+      { upsert: true, returnDocument: 'after' }
+    );
+
+    res.json({
+      success: true,
+      policy: {
+        infrastructure: policy?.infrastructure ?? 'normal',
+        taxRate: policy?.taxRate ?? 0,
+        corvee: policy?.corvee ?? 0,
+      },
+    });
+  } catch (error) {
+    console.error('Error setting policy:', error);
+    res.status(500).json({ error: 'Failed to set policy' });
+  }
+});
+
+// settingsView fills in the defaults of a player's unset settings
+function settingsView(settings: PlayerSettings | null) {
+  return {
+    autoEndTurn: settings?.autoEndTurn ?? false,
+    governorAfterTicks: settings?.governorAfterTicks ?? 0,
+    governorOff: settings?.governorOff ?? false,
+    mutedEvents: settings?.mutedEvents ?? [],
+  };
+}
+
+/**
+ * GET /api/game/:gameId/settings - Get the player's settings; unset flags
+ * are off and a governor threshold of 0 is the game's
+ */
+router.get('/:gameId/settings', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const settings = await getPlayerSettingsCollection().findOne({ gameId, playerId: req.playerId! });
+    res.json({ success: true, settings: settingsView(settings) });
+  } catch (error) {
+    console.error('Error fetching settings:', error);
+    res.status(500).json({ error: 'Failed to fetch settings' });
+  }
+});
+
+/**
+ * PUT /api/game/:gameId/settings - Change some or all of the player's settings
+ * Body: { autoEndTurn?: boolean, governorAfterTicks?: number, governorOff?: boolean, mutedEvents?: string[] }
+ * With autoEndTurn the engine settles the player's settlers and approves
+ * their people's proposals without waiting on them. governorAfterTicks sets
+ * how long the player may stay away before the governor plays for them, 0
+ * keeping the game's threshold; governorOff keeps the governor away
+ * entirely. Events of the muted types are left out of notifications.
+ */
+router.put('/:gameId/settings', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const { autoEndTurn, governorAfterTicks, governorOff, mutedEvents } = req.body;
+
+    for (const [name, flag] of Object.entries({ autoEndTurn, governorOff })) {
+      if (flag !== undefined && typeof flag !== 'boolean') {
+        res.status(400).json({ error: `${name} must be a boolean` });
+        return;
+      }
+    }
+    if (governorAfterTicks !== undefined && (!Number.isInteger(governorAfterTicks) || governorAfterTicks < 0)) {
+      res.status(400).json({ error: 'governorAfterTicks must be a whole number of ticks, 0 for the game\'s threshold' });
+      return;
+    }
+    if (mutedEvents !== undefined && (!Array.isArray(mutedEvents) || mutedEvents.some((type) => typeof type !== 'string'))) {
+      res.status(400).json({ error: 'mutedEvents must be a list of event types' });
+      return;
+    }
+
+    const changes: Partial<PlayerSettings> = { updatedAt: new Date() };
+    if (autoEndTurn !== undefined) changes.autoEndTurn = autoEndTurn;
+    if (governorAfterTicks !== undefined) changes.governorAfterTicks = governorAfterTicks;
+    if (governorOff !== undefined) changes.governorOff = governorOff;
+    if (mutedEvents !== undefined) changes.mutedEvents = [...new Set<string>(mutedEvents)];
+    const settings = await getPlayerSettingsCollection().findOneAndUpdate(
+      { gameId, playerId: req.playerId! },
+      { $set: changes },
+      { upsert: true, returnDocument: 'after' }
+    );
+
+    res.json({ success: true, settings: settingsView(settings) });
+  } catch (error) {
+    console.error('Error setting settings:', error);
+    res.status(500).json({ error: 'Failed to set settings' });
+  }
+});
+
+/**
+ * DELETE /api/game/:gameId/settings - Reset the player's settings to their defaults
+ */
+router.delete('/:gameId/settings', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    await getPlayerSettingsCollection().deleteOne({ gameId, playerId: req.playerId! });
+    res.json({ success: true, settings: settingsView(null) });
+  } catch (error) {
+    console.error('Error resetting settings:', error);
+    res.status(500).json({ error: 'Failed to reset settings' });
+  }
+});
+
+/**
+ * POST /api/game/:gameId/surrender - Concede the game
+ * The engine eliminates the player on its next tick: their territory is
+ * released, units disbanded and settlements left to the neutral player.
+ */
+router.post('/:gameId/surrender', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+
+    const order: Order = {
+      orderId: generateUuid('order'),
+      gameId,
+      playerId: req.playerId!,
+      orderType: 'surrender',
+      status: 'pending',
+      createdAt: new Date(),
+    };
+    await getOrdersCollection().insertOne(order);
+
+    res.status(202).json({
+      success: true,
+      order: {
+        orderId: order.orderId,
+        status: order.status,
+      },
+    });
+  } catch (error) {
+    console.error('Error surrendering:', error);
+    res.status(500).json({ error: 'Failed to surrender' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/diplomacy - Get the player's standing with every
+ * player they have had dealings with, including agreements and demands
+ */
+router.get('/:gameId/diplomacy', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+
+    const diplomacy = await getDiplomacyCollection()
+      .find({ gameId, $or: [{ playerA: req.playerId }, { playerB: req.playerId }] }, { projection: { _id: 0 } })
+      .toArray();
+
+    res.json({ success: true, diplomacy });
+  } catch (error) {
+    console.error('Error fetching diplomacy:', error);
+    res.status(500).json({ error: 'Failed to fetch diplomacy' });
+  }
+});
+
+/**
+ * POST /api/game/:gameId/diplomacy - Queue a diplomatic order
+ * Body: { orderType: 'demand', targetPlayer, item: 'tribute' | 'vassalage', amount? }
+ *    or { orderType: 'accept' | 'break', targetPlayer }
+ * Demands stand until the other player accepts them or they lapse; tribute
+ * needs a yearly amount of production. Breaking an agreement sours relations.
+ */
+router.post('/:gameId/diplomacy', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const { orderType, targetPlayer, item, amount } = req.body;
+
+    if (orderType !== 'demand' && orderType !== 'accept' && orderType !== 'break') {
+      res.status(400).json({ error: "orderType must be 'demand', 'accept' or 'break'" });
+      return;
+    }
+    if (typeof targetPlayer !== 'string' || targetPlayer === req.playerId) {
+      res.status(400).json({ error: 'targetPlayer must be another player' });
+      return;
+    }
+    if (orderType === 'demand') {
+      if (item !== 'tribute' && item !== 'vassalage') {
+        res.status(400).json({ error: "item must be 'tribute' or 'vassalage'" });
+        return;
+      }
+      if (item === 'tribute' && (!Number.isInteger(amount) || amount <= 0)) {
+        res.status(400).json({ error: 'amount must be a positive integer' });
+        return;
+      }
+    }
+
+    const order: Order = {
+      orderId: generateUuid('order'),
+      gameId,
+      playerId: req.playerId!,
+      orderType,
+      targetPlayer,
+      ...(orderType === 'demand' && { item, ...(item === 'tribute' && { amount }) }),
+      status: 'pending',
+      createdAt: new Date(),
+    };
+    await getOrdersCollection().insertOne(order);
+
+    res.status(202).json({ success: true, order: { orderId: order.orderId, status: order.status } });
+  } catch (error) {
+    console.error('Error creating diplomatic order:', error);
+    res.status(500).json({ error: 'Failed to create diplomatic order' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/minor-civs - Get the game's minor civilizations:
+ * their friendship toward each player, ally and open quest
+ */
+router.get('/:gameId/minor-civs', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const minorCivs = await getMinorCivsCollection()
+      .find({ gameId }, { projection: { _id: 0 } })
+      .sort({ minorCivId: 1 })
+      .toArray();
+    res.json({ success: true, minorCivs });
+  } catch (error) {
+    console.error('Error fetching minor civs:', error);
+    res.status(500).json({ error: 'Failed to fetch minor civs' });
+  }
+});
+
+/**
+ * POST /api/game/:gameId/minor-civs/:minorCivId/gift - Queue a gift of
+ * production to a minor civ
+ * Body: { settlementId, amount }
+ * The production comes out of one of the player's settlements and buys the
+ * minor civ's friendship; a large enough gift completes its gift quest.
+ */
+router.post('/:gameId/minor-civs/:minorCivId/gift', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId, minorCivId } = req.params;
+    const { settlementId, amount } = req.body;
+
+    if (typeof settlementId !== 'string') {
+      res.status(400).json({ error: 'settlementId is required' });
+      return;
+    }
+    if (!Number.isInteger(amount) || amount <= 0) {
+      res.status(400).json({ error: 'amount must be a positive integer' });
+      return;
+    }
+    const minorCiv = await getMinorCivsCollection().findOne({ gameId, minorCivId });
+    if (!minorCiv || minorCiv.conqueredBy) {
+      res.status(404).json({ error: 'Minor civ not found' });
+      return;
+    }
+    const settlement = await getSettlementsCollection().findOne({ gameId, settlementId, playerId: req.playerId! });
+    if (!settlement) {
+      res.status(404).json({ error: 'Settlement not found' });
+      return;
+    }
+
+    const order: Order = {
+      orderId: generateUuid('order'),
+      gameId,
+      playerId: req.playerId!,
+      orderType: 'gift',
+      targetPlayer: minorCivId,
+      settlementId,
+      amount,
+      status: 'pending',
+      createdAt: new Date(),
+    };
+    await getOrdersCollection().insertOne(order);
+
+    res.status(202).json({ success: true, order: { orderId: order.orderId, status: order.status } });
+  } catch (error) {
+    console.error('Error creating gift order:', error);
+    res.status(500).json({ error: 'Failed to create gift order' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/events - Get the events the verified player may see,
+ * oldest first: public events and those of the player or a teammate.
+ * Unit movements are served separately by the movements route.
+ */
+router.get('/:gameId/events', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+
+    const game = await getGamesCollection().findOne({ gameId });
+    const team = teammatesOf(game!, req.playerId!);
+    const events = await getGameEventsCollection()
+      .find(
+        { gameId, type: { $ne: 'unit_moved' }, $or: [{ type: { $in: PUBLIC_EVENT_TYPES } }, { playerId: { $in: team } }] },
+        { projection: { _id: 0 } }
+      )
+      .sort({ year: 1, createdAt: 1 })
+      .toArray();
+
+    res.json({
+      success: true,
+      events,
+    });
+  } catch (error) {
+    console.error('Error fetching events:', error);
+    res.status(500).json({ error: 'Failed to fetch events' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/movements?sinceYear=N - Get the player's unit
+ * movements with their path keyframes, oldest first, for animation
+ */
+router.get('/:gameId/movements', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const sinceYear = req.query.sinceYear !== undefined ? Number(req.query.sinceYear) : undefined;
+
+    if (sinceYear !== undefined && !Number.isInteger(sinceYear)) {
+      res.status(400).json({ error: 'sinceYear must be an integer' });
+      return;
+    }
+
+    const movements = await getGameEventsCollection()
+      .find(
+        {
+          gameId,
+          type: 'unit_moved',
+          playerId: req.playerId,
+          ...(sinceYear !== undefined && { year: { $gte: sinceYear } }),
+        },
+        { projection: { _id: 0 } }
+      )
+      .sort({ year: 1, createdAt: 1 })
+      .toArray();
+
+    res.json({
+      success: true,
+      movements: movements.map((event) => ({ year: event.year, ...event.movement })),
+    });
+  } catch (error) {
+    console.error('Error fetching movements:', error);
+    res.status(500).json({ error: 'Failed to fetch movements' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/objectives - Get the player's objectives, open ones
+ * first, then the most recently resolved
+ */
+router.get('/:gameId/objectives', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const objectives = await getObjectivesCollection()
+      .find({ gameId, playerId: req.playerId }, { projection: { _id: 0 } })
+      .toArray();
+    const open = objectives.filter((objective) => objective.status === 'active').sort((a, b) => a.deadline - b.deadline);
+    const resolved = objectives.filter((objective) => objective.status !== 'active').sort((a, b) => b.resolved - a.resolved);
+
+    res.json({ success: true, objectives: [...open, ...resolved] });
+  } catch (error) {
+    console.error('Error fetching objectives:', error);
+    res.status(500).json({ error: 'Failed to fetch objectives' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/notifications?sinceYear=N - Get the events addressed
+ * to the player, such as objectives being set, met or failed, oldest first;
+ * events of the types the player muted are left out
+ */
+router.get('/:gameId/notifications', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const sinceYear = req.query.sinceYear !== undefined ? Number(req.query.sinceYear) : undefined;
+
+    if (sinceYear !== undefined && !Number.isInteger(sinceYear)) {
+      res.status(400).json({ error: 'sinceYear must be an integer' });
+      return;
+    }
+
+    const settings = await getPlayerSettingsCollection().findOne({ gameId, playerId: req.playerId! });
+    const notifications = await getGameEventsCollection()
+      .find(
+        {
+          gameId,
+          type: { $nin: ['unit_moved', ...(settings?.mutedEvents ?? [])] },
+          playerId: req.playerId,
+          ...(sinceYear !== undefined && { year: { $gte: sinceYear } }),
+        },
+        { projection: { _id: 0, movement: 0 } }
+      )
+      .sort({ year: 1, createdAt: 1 })
+      .toArray();
+
+    res.json({ success: true, notifications });
+  } catch (error) {
+    console.error('Error fetching notifications:', error);
+    res.status(500).json({ error: 'Failed to fetch notifications' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/combat/preview?attackerId=&defenderId= - Preview the odds
+ * of one of the player's units attacking a unit or settlement the player can see.
+ * The engine runs its combat resolver in preview mode; nothing changes until an
+ * attack is ordered.
+ */
+router.get('/:gameId/combat/preview', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId } = req.params;
+    const { attackerId, defenderId } = req.query;
+
+    if (typeof attackerId !== 'string' || typeof defenderId !== 'string') {
+      res.status(400).json({ error: 'attackerId and defenderId are required' });
+      return;
+    }
+
+    const attacker = await getUnitsCollection().findOne({ gameId, unitId: attackerId, playerId: req.playerId });
+    if (!attacker) {
+      res.status(404).json({ error: 'Unit not found' });
+      return;
+    }
+
+    // Defenders hidden by fog of war are reported as missing, so previews
+    // cannot be used to probe for them
+    const defender = (await getUnitsCollection().findOne({ gameId, unitId: defenderId }))
+      ?? (await getSettlementsCollection().findOne({ gameId, settlementId: defenderId }));
+    const game = await getGamesCollection().findOne({ gameId });
+    const visible = defender && (await getMapTilesCollection().countDocuments({
+      gameId,
+      x: defender.location.x,
+      y: defender.location.y,
+      ...visibleTilesFilter(game!, req.playerId!),
+    })) > 0;
+    if (!visible) {
+      res.status(404).json({ error: 'Defender not found' });
+      return;
+    }
+
+    const params = new URLSearchParams({ gameId, attackerId, defenderId });
+    const engineRes = await fetch(`${config.engineQueryUrl}/combat/preview?${params}`);
+    const result = await engineRes.json();
+    if (!engineRes.ok) {
+      res.status(engineRes.status).json(result);
+      return;
+    }
+
+    res.json({ success: true, odds: result as CombatOdds });
+  } catch (error) {
+    console.error('Error previewing combat:', error);
+    res.status(500).json({ error: 'Failed to preview combat' });
+  }
+});
+
+/**
+ * GET /api/game/:gameId/settlements/:settlementId/mortality - Deaths in one of the
+ * player's settlements by cause (starvation, age, disease, accident, combat, childbirth)
+ */
+router.get('/:gameId/settlements/:settlementId/mortality', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId, settlementId } = req.params;
+
+    const settlement = await getSettlementsCollection().findOne({ gameId, settlementId, playerId: req.playerId });
+    if (!settlement) {
+      res.status(404).json({ error: 'Settlement not found' });
+      return;
+    }
+
+    const params = new URLSearchParams({ gameId, settlementId });
+    const engineRes = await fetch(`${config.engineQueryUrl}/mortality?${params}`);
+    const result = await engineRes.json();
+    if (!engineRes.ok) {
+      res.status(engineRes.status).json(result);
+      return;
+    }
+
+    res.json({ success: true, mortality: (result as MortalityReport[])[0] });
+  } catch (error) {
+    console.error('Error fetching mortality:', error);
+    res.status(500).json({ error: 'Failed to fetch mortality' });
+  }
+});
+
+/**
+ * PUT /api/game/:gameId/units/:unitId/automation - Set or clear a worker's automation mode
+ * Body: { mode: 'improve_nearest' | 'connect_cities' | 'focus_food' | null }
+ * Automated workers are driven by the engine each tick; null returns the worker to manual control.
+ */
+router.put('/:gameId/units/:unitId/automation', requirePlayer, async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { gameId, unitId } = req.params;
+    const { mode } = req.body;
+
+    if (mode !== null && !WORKER_AUTOMATION_MODES.includes(mode)) {
+      res.status(400).json({ error: `mode must be one of ${WORKER_AUTOMATION_MODES.join(', ')} or null` });
+      return;
+    }
+
+    const unit = await getUnitsCollection().findOne({ gameId, unitId, playerId: req.playerId });
+    if (!unit) {
+      res.status(404).json({ error: 'Unit not found' });
+      return;
+    }
+    if (unit.unitType !== 'workers') {
+      res.status(400).json({ error: 'Only workers can be automated' });
+      return;
+    }
+
+    await getUnitsCollection().updateOne(
+      { unitId },
+      mode === null
+        ? { $unset: { automation: '' }, $set: { lastUpdated: new Date() } }
+        : { $set: { automation: mode, lastUpdated: new Date() } }
+    );
+
+    res.json({ success: true, unitId, automation: mode });
+  } catch (error) {
+    console.error('Error setting worker automation:', error);
+    res.status(500).json({ error: 'Failed to set worker automation' });
+  }
+});
+
 export default router;
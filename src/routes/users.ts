@@ -0,0 +1,32 @@
+import { Router, Request, Response } from 'express';
+import { getUserStatsCollection } from '../db/connection';
+
+const router = Router();
+
+/**
+ * GET /api/users/:userId/stats
+ * Get a user's statistics across every game they finished, for their profile page
+ */
+router.get('/:userId/stats', async (req: Request, res: Response): Promise<void> => {
+  try {
+    const { userId } = req.params;
+
+    const stats = await getUserStatsCollection().findOne({ userId });
+
+    res.json({
+      success: true,
+      stats: {
+        userId,
+        gamesPlayed: stats?.gamesPlayed ?? 0,
+        wins: stats?.wins ?? {},
+        fastestFireMastery: stats?.fastestFireMastery ?? null,
+        lastUpdated: stats?.lastUpdated ?? null,
+      },
+    });
+  } catch (error) {
+    console.error('Error getting user stats:', error);
+    res.status(500).json({ error: 'Failed to get user stats' });
+  }
+});
+
+export default router;
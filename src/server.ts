@@ -9,6 +9,7 @@ import sessionRoutes from './routes/session';
 import gamesRoutes from './routes/games';
 import mapRoutes from './routes/map';
 import settlersRoutes from './routes/settlers';
+import usersRoutes from './routes/users';
 import testUtilsRoutes from './routes/test-utils';
 
 const app = express();
@@ -60,6 +61,7 @@ async function startServer() {
     app.use('/api/games', gamesRoutes);
     app.use('/api/map', mapRoutes);
     app.use('/api/game', settlersRoutes);
+    app.use('/api/users', usersRoutes);
     app.use('/api/test', testUtilsRoutes); // Test utilities for E2E testing
 
     // Error handler
@@ -124,3 +124,30 @@ export function isValidGuid(guid: string): boolean {
   const guidRegex = /^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$/i;
   return guidRegex.test(guid);
 }
+
+/**
+ * Sign a per-player API key binding a player to a game.
+ * Format: base64url(playerId).hex(HMAC-SHA256(secret, gameId:playerId))
+ */
+export function signPlayerKey(gameId: string, playerId: string, secret: string): string {
+  const signature = crypto.createHmac('sha256', secret).update(`${gameId}:${playerId}`).digest('hex');
+  return `${Buffer.from(playerId, 'utf-8').toString('base64url')}.${signature}`;
+}
+
+/**
+ * Verify a per-player API key for a game, returning the player it identifies or null
+ */
+export function verifyPlayerKey(gameId: string, key: string, secret: string): string | null {
+  const [encodedPlayerId, signature] = key.split('.');
+  if (!encodedPlayerId || !signature) {
+    return null;
+  }
+
+  const playerId = Buffer.from(encodedPlayerId, 'base64url').toString('utf-8');
+  const expected = Buffer.from(signPlayerKey(gameId, playerId, secret).split('.')[1], 'hex');
+  const actual = Buffer.from(signature, 'hex');
+  if (actual.length !== expected.length || !crypto.timingSafeEqual(actual, expected)) {
+    return null;
+  }
+  return playerId;
+}
@@ -0,0 +1,56 @@
+import { MapTile, StartingPosition } from '../models/types';
+
+/**
+ * Summary of a player's guaranteed starting footprint, shown to the player
+ * as a "settler's report" before the first settlement is placed
+ */
+export interface SettlerReport {
+  footprint: StartingPosition['guaranteedFootprint'];
+  tileCount: number;
+  terrain: Record<string, number>;
+  resources: Record<string, number>;
+  riverTiles: number;
+  coastalTiles: number;
+  hasRiver: boolean;
+  hasCoast: boolean;
+}
+
+/**
+ * Build a settler's report from the tiles inside a starting position's footprint.
+ * Tiles outside the footprint are ignored.
+ */
+export function buildSettlerReport(position: StartingPosition, tiles: MapTile[]): SettlerReport {
+  const footprint = position.guaranteedFootprint;
+  const report: SettlerReport = {
+    footprint,
+    tileCount: 0,
+    terrain: {},
+    resources: {},
+    riverTiles: 0,
+    coastalTiles: 0,
+    hasRiver: false,
+    hasCoast: false,
+  };
+
+  for (const tile of tiles) {
+    if (tile.x < footprint.minX || tile.x > footprint.maxX || tile.y < footprint.minY || tile.y > footprint.maxY) {
+      continue;
+    }
+
+    report.tileCount++;
+    report.terrain[tile.terrainType] = (report.terrain[tile.terrainType] || 0) + 1;
+    for (const resource of tile.resources || []) {
+      report.resources[resource] = (report.resources[resource] || 0) + 1;
+    }
+    if (tile.hasRiver) {
+      report.riverTiles++;
+    }
+    if (tile.isCoastal) {
+      report.coastalTiles++;
+    }
+  }
+
+  report.hasRiver = report.riverTiles > 0;
+  report.hasCoast = report.coastalTiles > 0;
+  return report;
+}
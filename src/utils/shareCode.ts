@@ -0,0 +1,110 @@
+/**
+ * Share codes name a world players can swap to play the same map. They are
+ * made by the engine (simulation/pkg/mapgen/sharecode.go), which also
+ * generates a game's map from its code; the server only reads them to
+ * create games.
+ */
+
+// What a share code holds. Only the fields the server needs are decoded in
+// full; the engine reads the map options itself.
+export interface ShareCode {
+  seed: string;
+  players: number;
+  landRatio: number;
+  scenario?: string;
+}
+
+const SHARE_CODE_VERSION = 1;
+const HEX_SEED = 1;
+const TUNING = 2;
+const SCENARIO = 4;
+const BASE32_ALPHABET = 'ABCDEFGHIJKLMNOPQRSTUVWXYZ234567';
+
+function decodeBase32(text: string): Buffer | null {
+  const bytes: number[] = [];
+  let buffer = 0;
+  let bits = 0;
+  for (const char of text) {
+    const value = BASE32_ALPHABET.indexOf(char);
+    if (value < 0) {
+      return null;
+    }
+    buffer = (buffer << 5) | value;
+    bits += 5;
+    if (bits >= 8) {
+      bits -= 8;
+      bytes.push((buffer >> bits) & 0xff);
+    }
+  }
+  return Buffer.from(bytes);
+}
+
+// Fletcher-16, as the engine computes it
+function checksum(data: Buffer): number {
+  let a = 0;
+  let b = 0;
+  for (const byte of data) {
+    a = (a + byte) % 255;
+    b = (b + a) % 255;
+  }
+  return (b << 8) | a;
+}
+
+/**
+ * Decode a share code, ignoring case, spaces and dashes. Returns null for
+ * anything that is not a valid code.
+ */
+export function parseShareCode(code: string): ShareCode | null {
+  const data = decodeBase32(code.toUpperCase().replace(/[\s-]/g, ''));
+  if (!data || data.length < 8) {
+    return null;
+  }
+  const body = data.subarray(0, data.length - 2);
+  if (checksum(body) !== data.readUInt16BE(data.length - 2) || body[0] !== SHARE_CODE_VERSION) {
+    return null;
+  }
+
+  let offset = 1;
+  const take = (n: number): Buffer | null => {
+    if (offset + n > body.length) {
+      return null;
+    }
+    const read = body.subarray(offset, offset + n);
+    offset += n;
+    return read;
+  };
+  const header = take(4);
+  if (!header) {
+    return null;
+  }
+  const flags = header[0];
+  const players = header[1];
+  const landRatio = header.readUInt16BE(2) / 1000;
+  if (flags & TUNING && !take(3)) {
+    return null;
+  }
+  const seedLength = take(1);
+  const seed = seedLength && take(seedLength[0]);
+  if (!seed || seed.length === 0 || players < 1) {
+    return null;
+  }
+  let scenario: string | undefined;
+  if (flags & SCENARIO) {
+    const scenarioLength = take(1);
+    const scenarioBytes = scenarioLength && take(scenarioLength[0]);
+    if (!scenarioBytes) {
+      return null;
+    }
+    scenario = scenarioBytes.toString('utf8');
+  }
+  if (offset !== body.length) {
+    return null;
+  }
+
+  return {
+    seed: flags & HEX_SEED ? seed.toString('hex') : seed.toString('utf8'),
+    players,
+    landRatio,
+    ...(scenario && { scenario }),
+  };
+}
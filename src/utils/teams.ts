@@ -0,0 +1,38 @@
+import { Game } from '../models/types';
+
+/**
+ * Players sharing a player's team, the player included. In a free-for-all
+ * (no teamCount) a player is only on their own side.
+ */
+export function teammatesOf(game: Game, playerId: string): string[] {
+  const team = game.teams?.[playerId];
+  if (!game.teamCount || team === undefined) {
+    return [playerId];
+  }
+  return game.playerList.filter((id) => game.teams?.[id] === team);
+}
+
+/**
+ * Filter for the map tiles a player can see: those currently visible to, or
+ * inside the borders of, the player or a teammate (teammates share their fog
+ * of war).
+ */
+export function visibleTilesFilter(game: Game, playerId: string) {
+  const team = teammatesOf(game, playerId);
+  return { $or: [{ visibleTo: { $in: team } }, { ownerId: { $in: team } }] };
+}
+
+/**
+ * The team a new player is placed on: the requested team when given,
+ * otherwise the smallest team (lowest index on ties).
+ */
+export function pickTeam(game: Game, requested?: number): number {
+  if (requested !== undefined) {
+    return requested;
+  }
+  const sizes = new Array(game.teamCount!).fill(0);
+  for (const team of Object.values(game.teams || {})) {
+    sizes[team]++;
+  }
+  return sizes.indexOf(Math.min(...sizes));
+}